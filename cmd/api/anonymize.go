@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"github.com/locolive/backend/internal/auth"
+	"github.com/locolive/backend/internal/config"
+)
+
+// anonymizeOptions controls how a restored snapshot is scrubbed
+type anonymizeOptions struct {
+	targetURL string
+	password  string
+	jitterKm  float64
+	mediaURL  string
+}
+
+// anonymizeStep rewrites or removes one kind of personal data. setup and
+// teardown lift and restore a guard around query, e.g. an append-only trigger.
+type anonymizeStep struct {
+	name     string
+	setup    string
+	query    string
+	args     []any
+	teardown string
+}
+
+// runAnonymize scrubs personal data from a restored production snapshot so it
+// can back a staging environment. Emails, phones, names and message bodies are
+// replaced with generated values, locations are moved by up to -jitter-km, and
+// tokens, OTPs and login history are dropped. Everything runs in one
+// transaction, so a failure leaves the snapshot untouched.
+func runAnonymize(ctx context.Context, cfg *config.Config, logger *zap.Logger, args []string) error {
+	opts := anonymizeOptions{}
+	fs := flag.NewFlagSet("anonymize", flag.ContinueOnError)
+	fs.StringVar(&opts.targetURL, "target-url", "", "restored database to anonymize; must not be DATABASE_URL")
+	fs.StringVar(&opts.password, "password", "", "password for every account that had one (default clears passwords)")
+	fs.Float64Var(&opts.jitterKm, "jitter-km", 2, "how far locations are moved at random")
+	fs.StringVar(&opts.mediaURL, "media-url", "", "placeholder for story media and avatars (default keeps story media, clears avatars)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if opts.targetURL == "" {
+		return errors.New("-target-url is required")
+	}
+	if opts.jitterKm < 0 {
+		return errors.New("-jitter-km must not be negative")
+	}
+	same, err := sameDatabase(cfg.Database.URL, opts.targetURL)
+	if err != nil {
+		return err
+	}
+	if same {
+		return errors.New("-target-url points at DATABASE_URL; anonymize a restored copy instead")
+	}
+
+	var passwordHash *string
+	if opts.password != "" {
+		hash, err := auth.HashPassword(opts.password)
+		if err != nil {
+			return err
+		}
+		passwordHash = &hash
+	}
+	var mediaURL *string
+	if opts.mediaURL != "" {
+		mediaURL = &opts.mediaURL
+	}
+
+	target, err := pgxpool.New(ctx, opts.targetURL)
+	if err != nil {
+		return fmt.Errorf("connecting to target database: %w", err)
+	}
+	defer target.Close()
+
+	start := time.Now()
+	tx, err := target.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, step := range anonymizeSteps(opts, passwordHash, mediaURL) {
+		stepStart := time.Now()
+		if step.setup != "" {
+			if _, err := tx.Exec(ctx, step.setup); err != nil {
+				return fmt.Errorf("anonymizing %s: %w", step.name, err)
+			}
+		}
+		tag, err := tx.Exec(ctx, step.query, step.args...)
+		if err != nil {
+			return fmt.Errorf("anonymizing %s: %w", step.name, err)
+		}
+		if step.teardown != "" {
+			if _, err := tx.Exec(ctx, step.teardown); err != nil {
+				return fmt.Errorf("anonymizing %s: %w", step.name, err)
+			}
+		}
+		logger.Info("Anonymized "+step.name, zap.Int64("rows", tag.RowsAffected()), zap.Duration("duration", time.Since(stepStart)))
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	logger.Info("Anonymization complete", zap.Duration("duration", time.Since(start)))
+	return nil
+}
+
+// pickSQL picks a random element of the text[] parameter at position param
+func pickSQL(param int) string {
+	arr := fmt.Sprintf("$%d::text[]", param)
+	return fmt.Sprintf("(%s)[1 + floor(random() * cardinality(%s))::int]", arr, arr)
+}
+
+// jitterSQL moves the lat/lng columns by up to $1 km in each direction,
+// keeping latitude in range
+func jitterSQL(lat, lng string) string {
+	return fmt.Sprintf(`%[1]s = GREATEST(-90, LEAST(90, %[1]s + (random() * 2 - 1) * $1::float8 / 111.0)),
+		%[2]s = %[2]s + (random() * 2 - 1) * $1::float8 / (111.0 * GREATEST(cos(radians(%[1]s)), 0.01))`, lat, lng)
+}
+
+func anonymizeSteps(opts anonymizeOptions, passwordHash, mediaURL *string) []anonymizeStep {
+	jitter := []any{opts.jitterKm}
+
+	return []anonymizeStep{
+		{
+			name: "users",
+			query: `
+				UPDATE users SET
+					name = ` + pickSQL(1) + ` || ' ' || ` + pickSQL(2) + `,
+					email = CASE WHEN email IS NULL THEN NULL ELSE 'user-' || id || '@example.com' END,
+					username = CASE WHEN username IS NULL THEN NULL ELSE 'user_' || left(replace(id::text, '-', ''), 24) END,
+					password_hash = CASE WHEN password_hash IS NULL THEN NULL ELSE $3 END,
+					avatar_url = CASE WHEN avatar_url IS NULL THEN NULL ELSE $4 END,
+					google_id = NULL,
+					apple_id = NULL,
+					bio = CASE WHEN bio IS NULL THEN NULL ELSE ` + pickSQL(5) + ` END,
+					date_of_birth = date_of_birth + (floor(random() * 365) - 182)::int
+			`,
+			args: []any{seedFirstNames, seedLastNames, passwordHash, mediaURL, seedCaptions},
+		},
+		{
+			// Numbered, so phones stay unique and phone login can be tested
+			name: "phones",
+			query: `
+				UPDATE users u SET phone = '+1555' || lpad(p.n::text, 7, '0')
+				FROM (SELECT id, row_number() OVER (ORDER BY id) AS n FROM users WHERE phone IS NOT NULL) p
+				WHERE u.id = p.id
+			`,
+		},
+		{
+			// Push tokens would reach real devices from staging
+			name:  "sessions",
+			query: `UPDATE sessions SET device_info = NULL, ip_address = NULL, fcm_token = NULL`,
+		},
+		{name: "refresh tokens", query: `DELETE FROM refresh_tokens`},
+		{name: "password reset tokens", query: `DELETE FROM password_reset_tokens`},
+		{name: "email verification tokens", query: `DELETE FROM email_verification_tokens`},
+		{name: "phone OTPs", query: `DELETE FROM phone_otps`},
+		{name: "login attempts", query: `DELETE FROM login_attempts`},
+		{name: "security events", query: `UPDATE security_events SET ip_address = NULL, user_agent = NULL`},
+		{
+			name: "stories",
+			query: `
+				UPDATE stories SET
+					caption = CASE WHEN caption IS NULL THEN NULL ELSE ` + pickSQL(2) + ` END,
+					media_url = COALESCE($3, media_url),
+					` + jitterSQL("location_lat", "location_lng") + `
+			`,
+			args: []any{opts.jitterKm, seedCaptions, mediaURL},
+		},
+		{
+			name: "story archive",
+			query: `
+				UPDATE story_archive SET
+					caption = CASE WHEN caption IS NULL THEN NULL ELSE ` + pickSQL(2) + ` END,
+					media_url = COALESCE($3, media_url),
+					` + jitterSQL("location_lat", "location_lng") + `
+			`,
+			args: []any{opts.jitterKm, seedCaptions, mediaURL},
+		},
+		{
+			name:  "live sessions",
+			query: `UPDATE live_sessions SET ` + jitterSQL("location_lat", "location_lng"),
+			args:  jitter,
+		},
+		{
+			name: "story collections",
+			query: `
+				UPDATE story_collections SET description = NULL,
+					` + jitterSQL("location_lat", "location_lng") + `
+			`,
+			args: jitter,
+		},
+		{
+			name:  "saved places",
+			query: `UPDATE saved_places SET name = initcap(kind), ` + jitterSQL("lat", "lng"),
+			args:  jitter,
+		},
+		{
+			name:  "daily activity",
+			query: `UPDATE user_activity_daily SET ` + jitterSQL("last_lat", "last_lng"),
+			args:  jitter,
+		},
+		{name: "weekly recaps", query: `DELETE FROM weekly_recaps`},
+		{
+			// Staging doesn't hold the production master keys, so encrypted
+			// messages become plaintext and the chat keys go
+			name: "messages",
+			query: `
+				UPDATE messages SET content = ` + pickSQL(1) + `, content_ciphertext = NULL, key_version = NULL
+			`,
+			args: []any{seedMessages},
+		},
+		{name: "chat keys", query: `DELETE FROM chat_keys`},
+		{
+			name: "notifications",
+			query: `
+				UPDATE notifications SET title = initcap(replace(type, '_', ' ')), body = 'Anonymized notification'
+			`,
+		},
+		{name: "reports", query: `UPDATE reports SET details = NULL WHERE details IS NOT NULL`},
+		{name: "abuse signals", query: `UPDATE abuse_signals SET details = '{}'`},
+		{
+			name: "copyright claims",
+			query: `
+				UPDATE copyright_claims SET
+					claimant_name = ` + pickSQL(1) + ` || ' ' || ` + pickSQL(2) + `,
+					claimant_email = 'claimant-' || id || '@example.com',
+					work_description = 'Anonymized work description',
+					signature = 'Anonymized signature',
+					counter_notice = CASE WHEN counter_notice IS NULL THEN NULL ELSE 'Anonymized counter-notice' END,
+					counter_signature = CASE WHEN counter_signature IS NULL THEN NULL ELSE 'Anonymized signature' END
+			`,
+			args: []any{seedFirstNames, seedLastNames},
+		},
+		{
+			// The audit log trigger only allows rewriting details while pii_redaction is on
+			name:  "audit log",
+			setup: `SELECT set_config('locolive.pii_redaction', 'on', true)`,
+			query: `UPDATE admin_audit_log SET details = '{}'`,
+		},
+		{
+			// Snapshots are append-only copies of held accounts' data
+			name:     "preservation snapshots",
+			setup:    `ALTER TABLE preservation_snapshots DISABLE TRIGGER preservation_snapshots_immutable`,
+			query:    `DELETE FROM preservation_snapshots`,
+			teardown: `ALTER TABLE preservation_snapshots ENABLE TRIGGER preservation_snapshots_immutable`,
+		},
+	}
+}
+
+// sameDatabase reports whether two connection strings name the same database
+// on the same server
+func sameDatabase(a, b string) (bool, error) {
+	ca, err := pgx.ParseConfig(a)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse database URL: %w", err)
+	}
+	cb, err := pgx.ParseConfig(b)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse -target-url: %w", err)
+	}
+	return ca.Host == cb.Host && ca.Port == cb.Port && ca.Database == cb.Database, nil
+}
@@ -6,19 +6,32 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgconn/ctxwatch"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
 	"go.uber.org/zap"
 
 	"github.com/locolive/backend/internal/api"
 	"github.com/locolive/backend/internal/auth"
+	"github.com/locolive/backend/internal/cache"
+	"github.com/locolive/backend/internal/captcha"
 	"github.com/locolive/backend/internal/config"
 	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/email"
+	"github.com/locolive/backend/internal/encryption"
 	"github.com/locolive/backend/internal/fcm"
+	"github.com/locolive/backend/internal/graph"
+	"github.com/locolive/backend/internal/metrics"
+	"github.com/locolive/backend/internal/middleware"
+	"github.com/locolive/backend/internal/ratelimit"
 	"github.com/locolive/backend/internal/repository"
+	"github.com/locolive/backend/internal/slo"
+	"github.com/locolive/backend/internal/sms"
 	"github.com/locolive/backend/internal/storage"
 )
 
@@ -45,9 +58,13 @@ func main() {
 		zap.String("port", cfg.Server.Port),
 	)
 
+	// Initialize metrics
+	metricsRegistry := metrics.NewRegistry()
+
 	// Initialize database
 	ctx := context.Background()
-	db, err := initDatabase(ctx, cfg.Database.URL)
+	tracer := repository.NewQueryTracer(cfg.Database.QueryTimeout, cfg.Database.SlowQueryThreshold, metricsRegistry, logger)
+	db, err := initDatabase(ctx, cfg.Database, tracer)
 	if err != nil {
 		logger.Fatal("Failed to connect to database", zap.Error(err))
 	}
@@ -57,7 +74,59 @@ func main() {
 
 	// Initialize dependencies
 	repo := repository.NewPostgresRepository(db)
-	jwtManager := auth.NewJWTManager(cfg.JWT.Secret, cfg.JWT.AccessExpiry, cfg.JWT.RefreshExpiry)
+	var messageKeys encryption.KeyProvider
+	if len(cfg.Messages.MasterKeys) > 0 {
+		keyProvider, err := encryption.NewStaticKeyProvider(cfg.Messages.CurrentKeyID, cfg.Messages.MasterKeys)
+		if err != nil {
+			logger.Fatal("Invalid message encryption keys", zap.Error(err))
+		}
+		messageKeys = keyProvider
+		repo.EnableMessageEncryption(messageKeys, cfg.Messages.KeyRotation)
+		logger.Info("Message encryption at rest enabled", zap.String("master_key_id", cfg.Messages.CurrentKeyID))
+	}
+
+	// `api seed [flags]` generates load-test data and exits
+	if len(os.Args) > 1 && os.Args[1] == "seed" {
+		if err := runSeed(ctx, repo, logger, os.Args[2:]); err != nil {
+			logger.Fatal("Seeding failed", zap.Error(err))
+		}
+		return
+	}
+
+	// `api verify-backup [flags]` restores a backup into a scratch database, checks it and exits
+	if len(os.Args) > 1 && os.Args[1] == "verify-backup" {
+		if err := runVerifyBackup(ctx, db, cfg, messageKeys, logger, os.Args[2:]); err != nil {
+			logger.Fatal("Backup verification failed", zap.Error(err))
+		}
+		return
+	}
+
+	// `api anonymize [flags]` scrubs personal data from a restored snapshot for staging and exits
+	if len(os.Args) > 1 && os.Args[1] == "anonymize" {
+		if err := runAnonymize(ctx, cfg, logger, os.Args[2:]); err != nil {
+			logger.Fatal("Anonymization failed", zap.Error(err))
+		}
+		return
+	}
+
+	// The active key signs; the others stay listed so tokens they signed
+	// still verify during a rotation
+	var jwtKeys []*auth.SigningKey
+	for _, k := range cfg.JWT.SigningKeys {
+		key, err := auth.LoadSigningKey(k.ID, k.Path)
+		if err != nil {
+			logger.Fatal("Failed to load JWT signing key", zap.Error(err))
+		}
+		if k.ID == cfg.JWT.ActiveKeyID {
+			jwtKeys = append([]*auth.SigningKey{key}, jwtKeys...)
+		} else {
+			jwtKeys = append(jwtKeys, key)
+		}
+	}
+	if len(jwtKeys) > 0 {
+		logger.Info("Signing JWTs with asymmetric key", zap.String("kid", jwtKeys[0].ID), zap.String("alg", jwtKeys[0].Method.Alg()))
+	}
+	jwtManager := auth.NewJWTManager(cfg.JWT.Secret, jwtKeys, cfg.JWT.SecretAcceptedUntil, cfg.JWT.AccessExpiry, cfg.JWT.RefreshExpiry, cfg.JWT.ClockSkew)
 	googleAuth := auth.NewGoogleAuthVerifier(cfg.Google.ClientIDs)
 
 	// Log Google OAuth status
@@ -67,6 +136,13 @@ func main() {
 		logger.Warn("Google OAuth is NOT configured - set GOOGLE_CLIENT_ID to enable")
 	}
 
+	appleAuth := auth.NewAppleAuthVerifier(cfg.Apple.ClientIDs)
+	if appleAuth.IsConfigured() {
+		logger.Info("Sign in with Apple is configured")
+	} else {
+		logger.Warn("Sign in with Apple is NOT configured - set APPLE_CLIENT_ID to enable")
+	}
+
 	// Initialize Firebase
 	fcmClient, err := fcm.NewClient(ctx, logger, os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"))
 	if err != nil {
@@ -75,9 +151,9 @@ func main() {
 		logger.Info("Firebase client initialized")
 	}
 
-	// Initialize storage
-	// Initialize storage
+	// Initialize storage. Local uploads live in uploadDir.
 	var fileStorage storage.FileStorage
+	uploadDir := "./uploads"
 
 	if cfg.Storage.Type == "s3" {
 		logger.Info("Initializing S3/R2 storage", zap.String("bucket", cfg.Storage.Bucket))
@@ -86,9 +162,32 @@ func main() {
 			logger.Fatal("Failed to initialize S3 storage", zap.Error(err))
 		}
 		fileStorage = s3Store
+
+		// Region-pinned buckets take uploads from the countries they serve
+		if len(cfg.Storage.Regions) > 0 {
+			var buckets []storage.RegionBucket
+			for _, region := range cfg.Storage.Regions {
+				regionCfg := cfg.Storage
+				regionCfg.Bucket = region.Bucket
+				regionCfg.Region = region.Region
+				regionCfg.Endpoint = region.Endpoint
+				regionCfg.PublicURL = region.PublicURL
+				regionStore, err := storage.NewS3Storage(ctx, regionCfg)
+				if err != nil {
+					logger.Fatal("Failed to initialize regional storage", zap.String("region", region.Name), zap.Error(err))
+				}
+				buckets = append(buckets, storage.RegionBucket{
+					Name:      region.Name,
+					Store:     regionStore,
+					PublicURL: region.PublicURL,
+					Countries: region.Countries,
+				})
+				logger.Info("Initialized regional storage", zap.String("region", region.Name), zap.String("bucket", region.Bucket))
+			}
+			fileStorage = storage.NewRegionalStorage(s3Store, buckets, domain.RegionFromContext)
+		}
 	} else {
 		// Ensure upload directory exists
-		uploadDir := "./uploads"
 		baseURL := fmt.Sprintf("http://localhost:%s/uploads", cfg.Server.Port)
 		if cfg.Server.Env == "production" {
 			// In production, might be different or use S3, but for now local
@@ -103,33 +202,267 @@ func main() {
 		logger.Info("Initialized Local file storage", zap.String("dir", uploadDir))
 	}
 
+	// Redis backs the user/session cache and rate limiting; both are optional
+	var redisClient *cache.RedisClient
+	if cfg.Cache.Enabled || cfg.RateLimit.Enabled {
+		redisClient, err = cache.NewRedisClient(cfg.Redis.URL, cfg.Redis.PoolSize)
+		if err != nil {
+			logger.Fatal("Invalid Redis configuration", zap.Error(err))
+		}
+		defer redisClient.Close()
+		if err := redisClient.Ping(ctx); err != nil {
+			logger.Warn("Redis is unreachable - caching and rate limiting will degrade", zap.Error(err))
+		}
+	}
+
+	// Front user and session lookups, nearby story queries and connection
+	// checks with Redis when enabled
+	var authRepo domain.AuthRepository = repo
+	var adminRepo domain.AdminRepository = repo
+	var storyRepo domain.StoryRepository = repo
+	var connRepo domain.ConnectionRepository = repo
+	var privacyRepo domain.PrivacyRepository = repo
+	if cfg.Cache.Enabled {
+		cached := repository.NewCachedRepository(repo, redisClient, redisClient, cfg.Cache, metricsRegistry, logger)
+		authRepo, adminRepo, storyRepo, connRepo, privacyRepo = cached, cached, cached, cached, cached
+		logger.Info("User, session, nearby story and connection cache enabled")
+	}
+
+	var rateLimiter *ratelimit.Limiter
+	if cfg.RateLimit.Enabled {
+		rateLimiter = ratelimit.NewLimiter(redisClient, authRepo, cfg.RateLimit, metricsRegistry, logger)
+		logger.Info("Fair-use rate limiting enabled")
+	}
+
+	// Public stats are shared across instances through Redis when it's available
+	var statsCache cache.Cache = cache.NewMemoryCache(10000)
+	if redisClient != nil {
+		statsCache = redisClient
+	}
+
+	// Google OAuth state must reach whichever instance serves the callback, so
+	// the in-memory fallback only suits a single instance
+	var oauthStore cache.Cache = cache.NewMemoryCache(10000)
+	if redisClient != nil {
+		oauthStore = redisClient
+	}
+
+	// Outbound budgets are global when Redis is available, else per instance
+	var outboundCounter cache.Counter = cache.NewMemoryCache(1000)
+	if redisClient != nil {
+		outboundCounter = redisClient
+	}
+	outboundBudget := domain.NewOutboundBudget(outboundCounter, map[domain.OutboundChannel]domain.OutboundLimits{
+		domain.OutboundPush:  {PerMinute: cfg.Outbound.PushPerMinute, PerDay: cfg.Outbound.PushPerDay},
+		domain.OutboundEmail: {PerMinute: cfg.Outbound.EmailPerMinute, PerDay: cfg.Outbound.EmailPerDay},
+	}, cfg.Outbound.MarketingShare, metricsRegistry)
+
 	// Initialize services
-	notificationService := domain.NewNotificationService(repo, fcmClient)
-	authService := domain.NewAuthService(repo, jwtManager, googleAuth)
-	storyService := domain.NewStoryService(repo, fileStorage)
-	chatService := domain.NewChatService(repo, notificationService)
-	connectionService := domain.NewConnectionService(repo, notificationService)
+	notificationService := domain.NewNotificationService(repo, fcmClient, outboundBudget)
+	activityService := domain.NewActivityService(repo)
+	var emailSender domain.EmailSender
+	switch cfg.Email.Provider {
+	case "smtp":
+		if cfg.Email.SMTPHost != "" {
+			emailSender = email.NewSMTPSender(cfg.Email.SMTPHost, cfg.Email.SMTPPort, cfg.Email.SMTPUsername, cfg.Email.SMTPPassword, cfg.Email.From)
+		}
+	case "ses":
+		sender, err := email.NewSESSender(ctx, cfg.Email.SESRegion, cfg.Email.From)
+		if err != nil {
+			logger.Fatal("Failed to initialize SES", zap.Error(err))
+		}
+		emailSender = sender
+	case "sendgrid":
+		if cfg.Email.SendGridKey == "" {
+			logger.Fatal("SENDGRID_API_KEY is required for the sendgrid email provider")
+		}
+		sender, err := email.NewSendGridSender(cfg.Email.SendGridKey, cfg.Email.From)
+		if err != nil {
+			logger.Fatal("Failed to initialize SendGrid", zap.Error(err))
+		}
+		emailSender = sender
+	default:
+		logger.Fatal("Unknown EMAIL_PROVIDER", zap.String("provider", cfg.Email.Provider))
+	}
+	if emailSender == nil && cfg.IsProduction() {
+		logger.Warn("Email is disabled; verification and password reset emails won't be sent")
+	}
+	var smsProvider sms.Provider
+	switch cfg.SMS.Provider {
+	case "twilio":
+		smsProvider = sms.NewTwilioProvider(cfg.SMS.TwilioAccountSID, cfg.SMS.TwilioAuthToken, cfg.SMS.TwilioFrom)
+	case "log":
+		if cfg.IsProduction() {
+			logger.Warn("SMS_PROVIDER is log; phone codes will only be logged")
+		}
+		smsProvider = sms.NewLogProvider(logger)
+	default:
+		logger.Fatal("Unknown SMS_PROVIDER", zap.String("provider", cfg.SMS.Provider))
+	}
+	var captchaVerifier captcha.Verifier
+	if cfg.Captcha.Provider != "none" {
+		verifier, err := captcha.NewProvider(cfg.Captcha.Provider, cfg.Captcha.Secret, cfg.Captcha.MinScore)
+		if err != nil {
+			logger.Fatal("Unknown CAPTCHA_PROVIDER", zap.String("provider", cfg.Captcha.Provider))
+		}
+		captchaVerifier = verifier
+	} else if cfg.IsProduction() {
+		logger.Warn("CAPTCHA_PROVIDER is none; registration and password reset aren't protected from bots")
+	}
+	textPolicy := domain.TextPolicy{
+		MaxNameLength:    cfg.Text.MaxNameLength,
+		MaxBioLength:     cfg.Text.MaxBioLength,
+		MaxCaptionLength: cfg.Text.MaxCaptionLength,
+		MaxMessageLength: cfg.Text.MaxMessageLength,
+		MaxCommentLength: cfg.Text.MaxCommentLength,
+	}
+	authService := domain.NewAuthService(authRepo, connRepo, jwtManager, googleAuth, appleAuth, fileStorage, outboundBudget.EmailSender(emailSender, domain.PriorityTransactional),
+		domain.EmailLinkSettings{VerifyURL: cfg.Email.VerifyURL, ResetURL: cfg.Email.ResetURL}, smsProvider, domain.LockoutPolicy{
+			MaxFailures:   cfg.Lockout.MaxFailures,
+			LockDuration:  cfg.Lockout.Duration,
+			MaxIPFailures: cfg.Lockout.MaxIPFailures,
+			IPWindow:      cfg.Lockout.IPWindow,
+			CaptchaAfter:  cfg.Captcha.LoginAfterFailures,
+		}, domain.SessionPolicy{MaxPerUser: cfg.Session.MaxPerUser}, textPolicy, notificationService, activityService, logger)
+	locationPolicy := domain.LocationPolicy{MaxTravelSpeedKmh: cfg.Geo.MaxTravelSpeedKmh}
+	if b := cfg.Geo.ServiceArea; b != nil {
+		locationPolicy.ServiceArea = &domain.GeoBounds{MinLat: b[0], MinLng: b[1], MaxLat: b[2], MaxLng: b[3]}
+	}
+	storyService := domain.NewStoryService(storyRepo, connRepo, repo, repo, notificationService, activityService, fileStorage, locationPolicy, textPolicy, domain.NearbyFeedPolicy{
+		HotCells: cfg.NearbyFeed.HotCells,
+		Stories:  cfg.NearbyFeed.Stories,
+	})
+	chatService := domain.NewChatService(repo, authRepo, connRepo, notificationService, fileStorage, textPolicy, domain.VoiceNotePolicy{
+		MaxBytes:    cfg.VoiceNote.MaxBytes,
+		MaxDuration: cfg.VoiceNote.MaxDuration,
+	})
+	connectionService := domain.NewConnectionService(connRepo, notificationService)
+	waveService := domain.NewWaveService(repo, repo, authRepo, connectionService, chatService, notificationService)
+	commentService := domain.NewCommentService(repo, authRepo, notificationService, textPolicy)
+	featureGate := domain.NewFeatureGate(cfg.Region.DisabledFeatures, cfg.App.KillSwitches)
+	placeService := domain.NewPlaceService(repo, featureGate)
+	appConfigService := domain.NewAppConfigService(domain.ClientPolicy{
+		MinVersion:          cfg.App.MinVersion,
+		BlockedVersions:     cfg.App.BlockedVersions,
+		UpdateURL:           cfg.App.UpdateURL,
+		RequireClientHeader: cfg.App.RequireClientHeader,
+	}, featureGate, repo)
+	recapService := domain.NewRecapService(repo, notificationService)
+	statsService := domain.NewStatsService(repo, statsCache, cfg.Stats.CacheTTL)
+	legalHoldService := domain.NewLegalHoldService(repo, repo)
+	privacyService := domain.NewPrivacyService(privacyRepo)
+	exportService := domain.NewExportService(repo, repo, fileStorage, notificationService)
+	supportService := domain.NewSupportAccessService(repo, repo, jwtManager, cfg.Support.MaxGrant, cfg.Support.TokenTTL)
+	cardService := domain.NewCardService(repo)
+	collectionService := domain.NewCollectionService(repo)
+	moderationService := domain.NewModerationService(repo, repo, repo, notificationService, domain.TakedownPolicy{
+		Story:   domain.TakedownRule{Threshold: cfg.Moderation.StoryReportThreshold, Window: cfg.Moderation.ReportWindow},
+		Chat:    domain.TakedownRule{Threshold: cfg.Moderation.ChatReportThreshold, Window: cfg.Moderation.ReportWindow},
+		Comment: domain.TakedownRule{Threshold: cfg.Moderation.CommentReportThreshold, Window: cfg.Moderation.ReportWindow},
+	})
+	copyrightService := domain.NewCopyrightService(repo, repo, notificationService)
+	adminService := domain.NewAdminService(adminRepo, repo, moderationService, notificationService)
+	campaignService := domain.NewCampaignService(repo, notificationService, outboundBudget.EmailSender(emailSender, domain.PriorityMarketing), domain.CampaignSettings{
+		FrequencyCap: cfg.Campaign.FrequencyCap,
+		Cooldown:     cfg.Campaign.Cooldown,
+	}, metricsRegistry)
+
+	// Initialize SLO tracking
+	sloTracker := slo.NewTracker(cfg.SLO, slo.NewAlerter(cfg.SLO), logger)
+	if cfg.Metrics.ScrapeToken == "" {
+		logger.Warn("/metrics and /health/slo are disabled - set METRICS_SCRAPE_TOKEN to enable")
+	}
 
 	// Initialize WebSocket manager
 	wsManager := api.NewWebSocketManager(logger)
 	go wsManager.Run()
+	liveService := domain.NewLiveService(repo, wsManager)
 
 	// Initialize handlers
-	authHandler := api.NewAuthHandler(authService, repo, logger)
-	googleOAuthHandler := api.NewGoogleOAuthHandler(cfg, authService, googleAuth, logger)
-	storyHandler := api.NewStoryHandler(storyService, logger)
-	chatHandler := api.NewChatHandler(chatService, wsManager, logger)
+	authHandler := api.NewAuthHandler(authService, authRepo, captchaVerifier, cfg.Captcha.FailOpen, metricsRegistry, logger)
+	googleOAuthHandler := api.NewGoogleOAuthHandler(cfg, authService, googleAuth, oauthStore, logger)
+	storyHandler := api.NewStoryHandler(storyService, liveService, logger)
+	chatHandler := api.NewChatHandler(chatService, wsManager, rateLimiter, logger)
 	connectionHandler := api.NewConnectionHandler(connectionService, logger)
 	notificationHandler := api.NewNotificationHandler(notificationService, logger)
 	healthHandler := api.NewHealthHandler()
+	sloHandler := api.NewSLOHandler(sloTracker)
+	adminHandler := api.NewAdminHandler(storyService, campaignService, moderationService, adminService, logger)
+	usageHandler := api.NewUsageHandler(rateLimiter, logger)
+	placeHandler := api.NewPlaceHandler(placeService, logger)
+	recapHandler := api.NewRecapHandler(recapService, logger)
+	campaignHandler := api.NewCampaignHandler(campaignService, logger)
+	statsHandler := api.NewStatsHandler(statsService, int(cfg.Stats.CacheTTL.Seconds()), logger)
+	moderationHandler := api.NewModerationHandler(moderationService, logger)
+	copyrightHandler := api.NewCopyrightHandler(copyrightService, logger)
+	waveHandler := api.NewWaveHandler(waveService, logger)
+	commentHandler := api.NewCommentHandler(commentService, logger)
+	activityHandler := api.NewActivityHandler(activityService, logger)
+	mediaSigner, _ := fileStorage.(storage.URLSigner)
+	mediaHandler := api.NewMediaHandler(domain.NewMediaService(repo), http.Dir(uploadDir), mediaSigner, cfg.Storage.SignedURLTTL, logger)
+	exportHandler := api.NewExportHandler(exportService, logger)
+	publicProfileHandler := api.NewPublicProfileHandler(authService, mediaHandler, logger)
+	legalHoldHandler := api.NewLegalHoldHandler(legalHoldService, logger)
+	privacyHandler := api.NewPrivacyHandler(privacyService, logger)
+	supportHandler := api.NewSupportHandler(supportService, logger)
+	cardHandler := api.NewCardHandler(cardService, logger)
+	collectionHandler := api.NewCollectionHandler(collectionService, logger)
+	appConfigHandler := api.NewAppConfigHandler(appConfigService, logger)
+
+	var graphQLHandler http.Handler
+	if cfg.GraphQL.Enabled {
+		resolver := graph.NewResolver(authService, storyService, chatService, connectionService, featureGate, logger)
+		graphQLHandler = graph.NewHandler(resolver, cfg.GraphQL, logger)
+	}
+
+	versionPolicy := middleware.VersionPolicy{
+		Latest:       api.LatestAPIVersion,
+		Deprecations: make(map[int]middleware.Deprecation, len(cfg.API.Deprecations)),
+		Link:         cfg.API.DeprecationLink,
+	}
+	for _, d := range cfg.API.Deprecations {
+		versionPolicy.Deprecations[d.Version] = middleware.Deprecation{DeprecatedAt: d.DeprecatedAt, SunsetAt: d.SunsetAt}
+	}
 
 	// Initialize router
-	router := api.NewRouter(authHandler, googleOAuthHandler, storyHandler, chatHandler, connectionHandler, notificationHandler, healthHandler, jwtManager, logger)
+	router := api.NewRouter(authHandler, googleOAuthHandler, storyHandler, chatHandler, connectionHandler, notificationHandler, healthHandler, sloHandler, adminHandler, cfg.Admin.UserIDs, repo, usageHandler, placeHandler, recapHandler, campaignHandler, statsHandler, moderationHandler, legalHoldHandler, privacyHandler, supportHandler, cardHandler, collectionHandler, copyrightHandler, waveHandler, commentHandler, activityHandler, mediaHandler, exportHandler, publicProfileHandler, graphQLHandler, featureGate, cfg.Region.Header, repo, versionPolicy, appConfigHandler, appConfigService.Policy(), rateLimiter, jwtManager, authRepo, metricsRegistry, sloTracker, cfg.Metrics.ScrapeToken, cfg.Server.TrustedProxies, logger)
 	r := router.Setup()
 
-	// Start cleanup worker
+	// Start background jobs, recording each run in job_runs and metrics
 	cleanupCtx, cleanupCancel := context.WithCancel(ctx)
-	repo.StartCleanupWorker(cleanupCtx, 1*time.Hour)
+	jobs := domain.NewJobTracker(repo, metricsRegistry)
+	repo.StartCleanupWorker(cleanupCtx, 1*time.Hour, jobs)
+	repo.StartPartitionWorker(cleanupCtx, cfg.Partition, jobs, logger)
+	repo.StartStoryCleanupWorker(cleanupCtx, cfg.Stories, jobs, logger)
+	repo.StartAccountPurgeWorker(cleanupCtx, cfg.Accounts, jobs, logger)
+	repo.StartMessageEncryptionWorker(cleanupCtx, cfg.Messages.Interval, jobs, logger)
+	if cfg.Recap.Enabled {
+		go recapService.Run(cleanupCtx, cfg.Recap.SendHour, jobs)
+	}
+	if cfg.Campaign.Enabled {
+		go campaignService.Run(cleanupCtx, cfg.Campaign.Interval, jobs)
+	}
+	go liveService.Run(cleanupCtx, time.Minute, jobs)
+	go exportService.Run(cleanupCtx, time.Minute, jobs)
+	go chatService.RunRetention(cleanupCtx, time.Hour, jobs)
+	go storyService.RunNearbyFeeds(cleanupCtx, time.Minute, jobs)
+
+	// Start SLO evaluation
+	go sloTracker.Run(cleanupCtx, cfg.SLO.EvaluationInterval)
+
+	// Prime caches while the server starts; /health/ready reports 503 until done
+	warmupSteps := []warmupStep{
+		{name: "statements", required: true, run: repo.PrepareHotStatements},
+		{name: "app config", run: func(ctx context.Context) error {
+			_, err := appConfigService.GetConfig(ctx, "", domain.PlatformAll)
+			return err
+		}},
+		{name: "story areas", run: warmStoryAreas(repo, statsService, storyService, cfg.Warmup.Areas)},
+	}
+	if appleAuth.IsConfigured() {
+		warmupSteps = append(warmupSteps, warmupStep{name: "Apple keys", run: appleAuth.PrefetchKeys})
+	}
+	go runWarmup(cleanupCtx, cfg.Warmup, warmupSteps, healthHandler, logger)
 
 	// Create server
 	srv := &http.Server{
@@ -177,8 +510,8 @@ func initLogger() (*zap.Logger, error) {
 	return zap.NewDevelopment()
 }
 
-func initDatabase(ctx context.Context, databaseURL string) (*pgxpool.Pool, error) {
-	config, err := pgxpool.ParseConfig(databaseURL)
+func initDatabase(ctx context.Context, dbCfg config.DatabaseConfig, tracer *repository.QueryTracer) (*pgxpool.Pool, error) {
+	config, err := pgxpool.ParseConfig(dbCfg.URL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse database URL: %w", err)
 	}
@@ -190,6 +523,19 @@ func initDatabase(ctx context.Context, databaseURL string) (*pgxpool.Pool, error
 	config.MaxConnIdleTime = 30 * time.Minute
 	config.HealthCheckPeriod = 1 * time.Minute
 
+	// Statement timeout and slow-query logging
+	config.ConnConfig.Tracer = tracer
+	if dbCfg.StatementTimeout > 0 {
+		config.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(dbCfg.StatementTimeout.Milliseconds(), 10)
+	}
+
+	// A cancelled context, from the tracer's timeout or a client that hung up,
+	// sends Postgres a cancel request. The default only drops the connection,
+	// leaving the query running on the server.
+	config.ConnConfig.BuildContextWatcherHandler = func(conn *pgconn.PgConn) ctxwatch.Handler {
+		return &pgconn.CancelRequestContextWatcherHandler{Conn: conn, DeadlineDelay: time.Second}
+	}
+
 	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create connection pool: %w", err)
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/locolive/backend/internal/auth"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/repository"
+)
+
+// seedOptions controls the volume and shape of generated load-test data
+type seedOptions struct {
+	users              int
+	connectionsPerUser int
+	storiesPerUser     int
+	chatsPerUser       int
+	messagesPerChat    int
+	radiusKm           float64
+	workers            int
+	randSeed           int64
+	password           string
+}
+
+// seedCity is a hotspot that generated users and stories cluster around
+type seedCity struct {
+	name string
+	lat  float64
+	lng  float64
+}
+
+var seedCities = []seedCity{
+	{"Bengaluru", 12.9716, 77.5946},
+	{"Mumbai", 19.0760, 72.8777},
+	{"Delhi", 28.6139, 77.2090},
+	{"Hyderabad", 17.3850, 78.4867},
+	{"Pune", 18.5204, 73.8567},
+	{"Chennai", 13.0827, 80.2707},
+}
+
+var (
+	seedFirstNames = []string{"Aarav", "Vivaan", "Aditya", "Diya", "Ananya", "Ishaan", "Kavya", "Rohan", "Saanvi", "Arjun", "Meera", "Kabir", "Nisha", "Dev", "Tara", "Zoya"}
+	seedLastNames  = []string{"Sharma", "Patel", "Reddy", "Iyer", "Khan", "Gupta", "Singh", "Das", "Nair", "Mehta", "Rao", "Joshi"}
+	seedCaptions   = []string{"Sunset vibes", "Coffee run", "Live music tonight!", "Traffic is wild", "Best dosa in town", "Rainy day", "Weekend market", "Gym time", "Street food crawl", "New cafe just opened"}
+	seedMessages   = []string{"Hey!", "Are you around?", "Saw your story, where is that?", "Let's meet up", "On my way", "Haha nice", "That place looks great", "See you there", "Running late", "👍"}
+)
+
+// runSeed parses seed flags and generates data through the repository layer
+func runSeed(ctx context.Context, repo *repository.PostgresRepository, logger *zap.Logger, args []string) error {
+	opts := seedOptions{}
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	fs.IntVar(&opts.users, "users", 1000, "number of users to create")
+	fs.IntVar(&opts.connectionsPerUser, "connections-per-user", 10, "accepted connections to create per user")
+	fs.IntVar(&opts.storiesPerUser, "stories-per-user", 3, "active stories to create per user")
+	fs.IntVar(&opts.chatsPerUser, "chats-per-user", 2, "chats to start per user")
+	fs.IntVar(&opts.messagesPerChat, "messages-per-chat", 20, "messages to create per chat")
+	fs.Float64Var(&opts.radiusKm, "radius-km", 15, "spread of users around each city center")
+	fs.IntVar(&opts.workers, "workers", 8, "number of concurrent workers")
+	fs.Int64Var(&opts.randSeed, "seed", time.Now().UnixNano(), "random seed for reproducible data")
+	fs.StringVar(&opts.password, "password", "Password123", "password for all generated users")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if opts.users < 2 {
+		return fmt.Errorf("at least 2 users are required")
+	}
+	if opts.workers < 1 {
+		opts.workers = 1
+	}
+
+	s := &seeder{
+		repo:   repo,
+		logger: logger,
+		opts:   opts,
+		runID:  uuid.New().String()[:8],
+	}
+	return s.run(ctx)
+}
+
+type seeder struct {
+	repo   *repository.PostgresRepository
+	logger *zap.Logger
+	opts   seedOptions
+	runID  string
+
+	users []seededUser
+}
+
+type seededUser struct {
+	id   uuid.UUID
+	city seedCity
+}
+
+func (s *seeder) run(ctx context.Context) error {
+	start := time.Now()
+	s.logger.Info("Seeding load-test data",
+		zap.Int("users", s.opts.users),
+		zap.Int64("seed", s.opts.randSeed),
+		zap.String("run_id", s.runID),
+	)
+
+	steps := []struct {
+		name string
+		fn   func(context.Context) (int64, error)
+	}{
+		{"users", s.seedUsers},
+		{"connections", s.seedConnections},
+		{"stories", s.seedStories},
+		{"chats", s.seedChats},
+	}
+
+	for _, step := range steps {
+		stepStart := time.Now()
+		n, err := step.fn(ctx)
+		if err != nil {
+			return fmt.Errorf("seeding %s: %w", step.name, err)
+		}
+		s.logger.Info("Seeded "+step.name, zap.Int64("count", n), zap.Duration("duration", time.Since(stepStart)))
+	}
+
+	s.logger.Info("Seeding complete", zap.Duration("duration", time.Since(start)))
+	return nil
+}
+
+func (s *seeder) seedUsers(ctx context.Context) (int64, error) {
+	// bcrypt is deliberately slow, so hash once and share it across all users
+	passwordHash, err := auth.HashPassword(s.opts.password)
+	if err != nil {
+		return 0, err
+	}
+
+	s.users = make([]seededUser, s.opts.users)
+	var created int64
+	err = s.parallel(ctx, "users", s.opts.users, func(i int, rng *rand.Rand) error {
+		city := seedCities[i%len(seedCities)]
+		email := fmt.Sprintf("seed-%s-%d@locolive.test", s.runID, i)
+		name := pick(rng, seedFirstNames) + " " + pick(rng, seedLastNames)
+
+		user, err := s.repo.CreateUser(ctx, domain.CreateUserParams{
+			Email:         &email,
+			PasswordHash:  &passwordHash,
+			Name:          name,
+			EmailVerified: true,
+		})
+		if err != nil {
+			return err
+		}
+		s.users[i] = seededUser{id: user.ID, city: city}
+		atomic.AddInt64(&created, 1)
+		return nil
+	})
+	return created, err
+}
+
+func (s *seeder) seedConnections(ctx context.Context) (int64, error) {
+	var created int64
+	err := s.parallel(ctx, "connections", len(s.users), func(i int, rng *rand.Rand) error {
+		// Only connect "forward", to distinct users, so each pair is created
+		// at most once
+		for _, k := range sampleDistinct(rng, len(s.users)-i-1, s.opts.connectionsPerUser) {
+			a, b := s.users[i].id, s.users[i+1+k].id
+			_, err := s.repo.TransitionConnection(ctx, a, b, func(*domain.Connection) (*domain.ConnectionUpdate, error) {
+				return &domain.ConnectionUpdate{RequesterID: a, ReceiverID: b, Status: domain.ConnectionStatusAccepted}, nil
+			})
+			if err != nil {
+				return err
+			}
+			atomic.AddInt64(&created, 1)
+		}
+		return nil
+	})
+	return created, err
+}
+
+func (s *seeder) seedStories(ctx context.Context) (int64, error) {
+	var created int64
+	err := s.parallel(ctx, "stories", len(s.users), func(i int, rng *rand.Rand) error {
+		u := s.users[i]
+		for j := 0; j < s.opts.storiesPerUser; j++ {
+			lat, lng := s.scatter(rng, u.city.lat, u.city.lng)
+			caption := pick(rng, seedCaptions) + " @ " + u.city.name
+			mediaType := "image"
+			if rng.Intn(5) == 0 {
+				mediaType = "video"
+			}
+
+			_, err := s.repo.CreateStory(ctx, domain.CreateStoryParams{
+				UserID:      u.id,
+				MediaURL:    fmt.Sprintf("https://picsum.photos/seed/%s-%d-%d/1080/1920", s.runID, i, j),
+				MediaType:   mediaType,
+				Caption:     &caption,
+				LocationLat: &lat,
+				LocationLng: &lng,
+				ExpiresAt:   time.Now().Add(time.Duration(1+rng.Intn(23)) * time.Hour),
+			})
+			if err != nil {
+				return err
+			}
+			atomic.AddInt64(&created, 1)
+		}
+		return nil
+	})
+	return created, err
+}
+
+func (s *seeder) seedChats(ctx context.Context) (int64, error) {
+	var messages int64
+	err := s.parallel(ctx, "chats", len(s.users), func(i int, rng *rand.Rand) error {
+		for _, k := range sampleDistinct(rng, len(s.users)-1, s.opts.chatsPerUser) {
+			// Skip over the user themselves
+			if k >= i {
+				k++
+			}
+			a, b := s.users[i].id, s.users[k].id
+
+			chat, err := s.repo.CreateChat(ctx, a, b)
+			if err != nil {
+				return err
+			}
+			for m := 0; m < s.opts.messagesPerChat; m++ {
+				sender := a
+				if rng.Intn(2) == 0 {
+					sender = b
+				}
+				if _, err := s.repo.CreateMessage(ctx, chat.ID, sender, pick(rng, seedMessages)); err != nil {
+					return err
+				}
+				atomic.AddInt64(&messages, 1)
+			}
+		}
+		return nil
+	})
+	return messages, err
+}
+
+// parallel runs fn for each index in [0, n) across the configured number of workers,
+// stopping at the first error. Each call gets its own rng derived from the seed,
+// the step and the index, so the data doesn't depend on how workers interleave.
+func (s *seeder) parallel(ctx context.Context, step string, n int, fn func(i int, rng *rand.Rand) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+
+	for w := 0; w < s.opts.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := fn(i, s.itemRand(step, i)); err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// itemRand returns the rng for item i of step
+func (s *seeder) itemRand(step string, i int) *rand.Rand {
+	h := fnv.New64a()
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], uint64(s.opts.randSeed))
+	h.Write(buf[:])
+	h.Write([]byte(step))
+	binary.LittleEndian.PutUint64(buf[:], uint64(i))
+	h.Write(buf[:])
+	return rand.New(rand.NewSource(int64(h.Sum64())))
+}
+
+// scatter returns a point normally distributed around the center, within roughly radiusKm
+func (s *seeder) scatter(rng *rand.Rand, lat, lng float64) (float64, float64) {
+	const kmPerDegree = 111.0
+	spread := s.opts.radiusKm / 2 / kmPerDegree
+	dLat := rng.NormFloat64() * spread
+	dLng := rng.NormFloat64() * spread / math.Cos(lat*math.Pi/180)
+	return lat + dLat, lng + dLng
+}
+
+// sampleDistinct returns up to want distinct indexes in [0, n), each equally
+// likely, using Floyd's algorithm
+func sampleDistinct(rng *rand.Rand, n, want int) []int {
+	if want > n {
+		want = n
+	}
+	if want <= 0 {
+		return nil
+	}
+	picked := make([]int, 0, want)
+	seen := make(map[int]bool, want)
+	for j := n - want; j < n; j++ {
+		k := rng.Intn(j + 1)
+		if seen[k] {
+			k = j
+		}
+		seen[k] = true
+		picked = append(picked, k)
+	}
+	return picked
+}
+
+func pick(rng *rand.Rand, values []string) string {
+	return values[rng.Intn(len(values))]
+}
@@ -0,0 +1,410 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"github.com/locolive/backend/internal/config"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/encryption"
+	"github.com/locolive/backend/internal/repository"
+)
+
+// verifyBackupOptions controls what is restored and how strictly it is checked
+type verifyBackupOptions struct {
+	file      string
+	targetURL string
+	keep      bool
+	samples   int
+	maxAge    time.Duration
+}
+
+// backupCheck is the outcome of one integrity check on the restored database
+type backupCheck struct {
+	name   string
+	ok     bool
+	detail string
+}
+
+// runVerifyBackup restores a pg_dump backup into a scratch database, or
+// connects to an already restored one such as a point-in-time recovery clone,
+// and checks it is complete and usable by this build of the API
+func runVerifyBackup(ctx context.Context, live *pgxpool.Pool, cfg *config.Config, messageKeys encryption.KeyProvider, logger *zap.Logger, args []string) error {
+	opts := verifyBackupOptions{}
+	fs := flag.NewFlagSet("verify-backup", flag.ContinueOnError)
+	fs.StringVar(&opts.file, "file", "", "backup to restore: a pg_dump archive, or a plain .sql dump")
+	fs.StringVar(&opts.targetURL, "target-url", "", "verify an already restored database (e.g. a point-in-time recovery clone) instead of restoring -file")
+	fs.BoolVar(&opts.keep, "keep", false, "keep the scratch database after verifying")
+	fs.IntVar(&opts.samples, "samples", 20, "users to run sample API queries for")
+	fs.DurationVar(&opts.maxAge, "max-age", 0, "fail if the newest restored row is older than this (0 disables)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if (opts.file == "") == (opts.targetURL == "") {
+		return errors.New("exactly one of -file or -target-url is required")
+	}
+
+	targetURL := opts.targetURL
+	if opts.file != "" {
+		scratch := fmt.Sprintf("locolive_verify_%s", time.Now().UTC().Format("20060102_150405"))
+		var err error
+		targetURL, err = withDatabase(cfg.Database.URL, scratch)
+		if err != nil {
+			return err
+		}
+
+		if _, err := live.Exec(ctx, `CREATE DATABASE `+pgx.Identifier{scratch}.Sanitize()); err != nil {
+			return fmt.Errorf("creating scratch database: %w", err)
+		}
+		logger.Info("Created scratch database", zap.String("database", scratch))
+		if !opts.keep {
+			defer func() {
+				// The restored pool is closed by now; WITH (FORCE) drops any stray connections
+				if _, err := live.Exec(context.Background(), `DROP DATABASE IF EXISTS `+pgx.Identifier{scratch}.Sanitize()+` WITH (FORCE)`); err != nil {
+					logger.Error("Failed to drop scratch database", zap.String("database", scratch), zap.Error(err))
+				}
+			}()
+		}
+
+		start := time.Now()
+		if err := restoreBackup(ctx, opts.file, targetURL); err != nil {
+			return err
+		}
+		logger.Info("Restored backup", zap.String("file", opts.file), zap.Duration("duration", time.Since(start)))
+	}
+
+	restored, err := pgxpool.New(ctx, targetURL)
+	if err != nil {
+		return fmt.Errorf("connecting to restored database: %w", err)
+	}
+	defer restored.Close()
+	if err := restored.Ping(ctx); err != nil {
+		return fmt.Errorf("connecting to restored database: %w", err)
+	}
+
+	v := &backupVerifier{live: live, restored: restored, opts: opts}
+	v.repo = repository.NewPostgresRepository(restored)
+	if messageKeys != nil {
+		v.repo.EnableMessageEncryption(messageKeys, cfg.Messages.KeyRotation)
+	}
+
+	checks := []func(context.Context) backupCheck{
+		v.checkMigrations,
+		v.checkRowCounts,
+		v.checkForeignKeys,
+		v.checkSampleQueries,
+		v.checkFreshness,
+	}
+
+	failed := 0
+	for _, check := range checks {
+		result := check(ctx)
+		if result.ok {
+			logger.Info("PASS "+result.name, zap.String("detail", result.detail))
+		} else {
+			failed++
+			logger.Error("FAIL "+result.name, zap.String("detail", result.detail))
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	logger.Info("Backup verified", zap.Int("checks", len(checks)))
+	return nil
+}
+
+// withDatabase returns databaseURL pointing at another database on the same server
+func withDatabase(databaseURL, database string) (string, error) {
+	u, err := url.Parse(databaseURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse database URL: %w", err)
+	}
+	u.Path = "/" + database
+	return u.String(), nil
+}
+
+// restoreBackup loads a backup with psql for plain .sql dumps or pg_restore otherwise
+func restoreBackup(ctx context.Context, file, databaseURL string) error {
+	var cmd *exec.Cmd
+	if strings.HasSuffix(file, ".sql") {
+		cmd = exec.CommandContext(ctx, "psql", "--quiet", "--no-psqlrc", "-v", "ON_ERROR_STOP=1", "-d", databaseURL, "-f", file)
+	} else {
+		cmd = exec.CommandContext(ctx, "pg_restore", "--no-owner", "--no-privileges", "--exit-on-error", "-d", databaseURL, file)
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		detail := strings.TrimSpace(string(output))
+		if len(detail) > 2000 {
+			detail = "..." + detail[len(detail)-2000:]
+		}
+		return fmt.Errorf("restoring %s with %s: %w: %s", file, cmd.Args[0], err, detail)
+	}
+	return nil
+}
+
+type backupVerifier struct {
+	live     *pgxpool.Pool
+	restored *pgxpool.Pool
+	repo     *repository.PostgresRepository
+	opts     verifyBackupOptions
+}
+
+// checkMigrations compares the restored schema version with the live database
+func (v *backupVerifier) checkMigrations(ctx context.Context) backupCheck {
+	check := backupCheck{name: "migrations"}
+
+	var restoredVersion int64
+	var dirty bool
+	err := v.restored.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations`).Scan(&restoredVersion, &dirty)
+	if err != nil {
+		check.detail = "reading schema_migrations: " + err.Error()
+		return check
+	}
+	if dirty {
+		check.detail = fmt.Sprintf("version %d is marked dirty; a migration was interrupted", restoredVersion)
+		return check
+	}
+
+	var liveVersion int64
+	if err := v.live.QueryRow(ctx, `SELECT version FROM schema_migrations`).Scan(&liveVersion); err != nil {
+		check.detail = "reading live schema_migrations: " + err.Error()
+		return check
+	}
+
+	check.ok = true
+	check.detail = fmt.Sprintf("version %d", restoredVersion)
+	if restoredVersion != liveVersion {
+		check.detail += fmt.Sprintf(" (live is at %d; run migrations after restoring)", liveVersion)
+	}
+	return check
+}
+
+// tableCountsQuery lists top-level tables; partitions are counted through their parent
+const tableCountsQuery = `
+	SELECT c.oid::regclass::text
+	FROM pg_class c
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = 'public' AND c.relkind IN ('r', 'p') AND NOT c.relispartition
+	ORDER BY 1
+`
+
+// checkRowCounts fails if a live table is missing from the backup, or is
+// empty in the backup while holding rows live
+func (v *backupVerifier) checkRowCounts(ctx context.Context) backupCheck {
+	check := backupCheck{name: "row counts"}
+
+	liveTables, err := queryStrings(ctx, v.live, tableCountsQuery)
+	if err != nil {
+		check.detail = "listing live tables: " + err.Error()
+		return check
+	}
+	restoredTables, err := queryStrings(ctx, v.restored, tableCountsQuery)
+	if err != nil {
+		check.detail = "listing restored tables: " + err.Error()
+		return check
+	}
+	present := make(map[string]bool, len(restoredTables))
+	for _, t := range restoredTables {
+		present[t] = true
+	}
+
+	var problems, counts []string
+	for _, table := range liveTables {
+		if !present[table] {
+			problems = append(problems, table+" missing")
+			continue
+		}
+		var restoredRows, liveRows int64
+		if err := v.restored.QueryRow(ctx, `SELECT COUNT(*) FROM `+table).Scan(&restoredRows); err != nil {
+			problems = append(problems, table+": "+err.Error())
+			continue
+		}
+		if err := v.live.QueryRow(ctx, `SELECT COUNT(*) FROM `+table).Scan(&liveRows); err != nil {
+			problems = append(problems, table+": "+err.Error())
+			continue
+		}
+		if restoredRows == 0 && liveRows > 0 {
+			problems = append(problems, fmt.Sprintf("%s empty (live has %d)", table, liveRows))
+		}
+		counts = append(counts, fmt.Sprintf("%s=%d/%d", table, restoredRows, liveRows))
+	}
+
+	if len(problems) > 0 {
+		check.detail = strings.Join(problems, "; ")
+		return check
+	}
+	check.ok = true
+	check.detail = "restored/live: " + strings.Join(counts, ", ")
+	return check
+}
+
+// foreignKeysQuery lists every foreign key once, skipping the copies on partitions
+const foreignKeysQuery = `
+	SELECT c.conname, c.conrelid::regclass::text, c.confrelid::regclass::text,
+		ARRAY(
+			SELECT a.attname FROM unnest(c.conkey) WITH ORDINALITY AS k(attnum, n)
+			JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum
+			ORDER BY k.n
+		)::text[],
+		ARRAY(
+			SELECT a.attname FROM unnest(c.confkey) WITH ORDINALITY AS k(attnum, n)
+			JOIN pg_attribute a ON a.attrelid = c.confrelid AND a.attnum = k.attnum
+			ORDER BY k.n
+		)::text[]
+	FROM pg_constraint c
+	JOIN pg_namespace n ON n.oid = c.connamespace
+	WHERE c.contype = 'f' AND n.nspname = 'public' AND c.conparentid = 0
+	ORDER BY 1
+`
+
+// checkForeignKeys counts rows whose foreign keys point at missing parents
+func (v *backupVerifier) checkForeignKeys(ctx context.Context) backupCheck {
+	check := backupCheck{name: "foreign keys"}
+
+	rows, err := v.restored.Query(ctx, foreignKeysQuery)
+	if err != nil {
+		check.detail = "listing foreign keys: " + err.Error()
+		return check
+	}
+	type foreignKey struct {
+		name, child, parent   string
+		childCols, parentCols []string
+	}
+	var keys []foreignKey
+	for rows.Next() {
+		var fk foreignKey
+		if err := rows.Scan(&fk.name, &fk.child, &fk.parent, &fk.childCols, &fk.parentCols); err != nil {
+			rows.Close()
+			check.detail = err.Error()
+			return check
+		}
+		keys = append(keys, fk)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		check.detail = err.Error()
+		return check
+	}
+
+	var problems []string
+	for _, fk := range keys {
+		var notNull, matches []string
+		for i, col := range fk.childCols {
+			childCol := "c." + pgx.Identifier{col}.Sanitize()
+			notNull = append(notNull, childCol+" IS NOT NULL")
+			matches = append(matches, "p."+pgx.Identifier{fk.parentCols[i]}.Sanitize()+" = "+childCol)
+		}
+		query := `SELECT COUNT(*) FROM ` + fk.child + ` c WHERE ` + strings.Join(notNull, " AND ") +
+			` AND NOT EXISTS (SELECT 1 FROM ` + fk.parent + ` p WHERE ` + strings.Join(matches, " AND ") + `)`
+
+		var orphans int64
+		if err := v.restored.QueryRow(ctx, query).Scan(&orphans); err != nil {
+			problems = append(problems, fk.name+": "+err.Error())
+		} else if orphans > 0 {
+			problems = append(problems, fmt.Sprintf("%s: %d orphaned rows in %s", fk.name, orphans, fk.child))
+		}
+	}
+
+	if len(problems) > 0 {
+		check.detail = strings.Join(problems, "; ")
+		return check
+	}
+	check.ok = true
+	check.detail = fmt.Sprintf("%d foreign keys consistent", len(keys))
+	return check
+}
+
+// checkSampleQueries runs the API's own reads for a sample of users, which
+// also decrypts their messages when encryption at rest is enabled
+func (v *backupVerifier) checkSampleQueries(ctx context.Context) backupCheck {
+	check := backupCheck{name: "sample queries"}
+
+	rows, err := v.restored.Query(ctx, `SELECT id FROM users WHERE is_active = TRUE ORDER BY random() LIMIT $1`, v.opts.samples)
+	if err != nil {
+		check.detail = "sampling users: " + err.Error()
+		return check
+	}
+	userIDs, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		check.detail = "sampling users: " + err.Error()
+		return check
+	}
+
+	chats, messages := 0, 0
+	for _, userID := range userIDs {
+		if _, err := v.repo.GetUserByID(ctx, userID); err != nil {
+			check.detail = fmt.Sprintf("loading user %s: %v", userID, err)
+			return check
+		}
+		userChats, err := v.repo.GetChatsByUserID(ctx, userID)
+		if err != nil {
+			check.detail = fmt.Sprintf("loading chats of %s: %v", userID, err)
+			return check
+		}
+		for _, chat := range userChats {
+			msgs, err := v.repo.GetMessages(ctx, chat.ID, domain.MessagePage{Limit: 20})
+			if err != nil {
+				check.detail = fmt.Sprintf("reading messages of chat %s: %v", chat.ID, err)
+				return check
+			}
+			chats++
+			messages += len(msgs)
+		}
+	}
+
+	check.ok = true
+	check.detail = fmt.Sprintf("%d users, %d chats, %d messages read", len(userIDs), chats, messages)
+	return check
+}
+
+// checkFreshness reports the newest restored row and enforces -max-age
+func (v *backupVerifier) checkFreshness(ctx context.Context) backupCheck {
+	check := backupCheck{name: "freshness"}
+
+	var newest *time.Time
+	err := v.restored.QueryRow(ctx, `
+		SELECT GREATEST(
+			(SELECT MAX(created_at) FROM users),
+			(SELECT MAX(created_at) FROM stories),
+			(SELECT MAX(created_at) FROM messages)
+		)
+	`).Scan(&newest)
+	if err != nil {
+		check.detail = err.Error()
+		return check
+	}
+	if newest == nil {
+		check.ok = v.opts.maxAge == 0
+		check.detail = "backup has no users, stories or messages"
+		return check
+	}
+
+	age := time.Since(*newest).Round(time.Minute)
+	check.detail = fmt.Sprintf("newest row %s (%s old)", newest.UTC().Format(time.RFC3339), age)
+	check.ok = v.opts.maxAge == 0 || age <= v.opts.maxAge
+	if !check.ok {
+		check.detail += fmt.Sprintf(", older than -max-age %s", v.opts.maxAge)
+	}
+	return check
+}
+
+func queryStrings(ctx context.Context, db *pgxpool.Pool, query string) ([]string, error) {
+	rows, err := db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/locolive/backend/internal/api"
+	"github.com/locolive/backend/internal/config"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/repository"
+)
+
+// warmupAreaRadius matches the feed's default radius
+const warmupAreaRadius = 5000.0
+
+// warmupStep primes one cache. A required step failing points at a broken
+// deploy rather than a cold cache, so it keeps the instance out of rotation.
+type warmupStep struct {
+	name     string
+	required bool
+	run      func(ctx context.Context) error
+}
+
+// runWarmup runs the steps in order and then marks the instance ready, so the
+// first users after a deploy don't pay for cold caches. Priming is best
+// effort: failed optional steps are logged, and whatever is left when the
+// timeout hits is skipped.
+func runWarmup(ctx context.Context, cfg config.WarmupConfig, steps []warmupStep, health *api.HealthHandler, logger *zap.Logger) {
+	if !cfg.Enabled {
+		health.SetReady(true)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	for _, step := range steps {
+		if ctx.Err() != nil {
+			logger.Warn("Warm-up timed out", zap.String("skipped_from", step.name), zap.Duration("timeout", cfg.Timeout))
+			break
+		}
+		stepStart := time.Now()
+		if err := step.run(ctx); err != nil {
+			if step.required {
+				logger.Error("Warm-up failed; staying unready", zap.String("step", step.name), zap.Error(err))
+				return
+			}
+			logger.Warn("Warm-up step failed", zap.String("step", step.name), zap.Error(err))
+			continue
+		}
+		logger.Info("Warmed up "+step.name, zap.Duration("duration", time.Since(stepStart)))
+	}
+
+	health.SetReady(true)
+	logger.Info("Warm-up complete; ready for traffic", zap.Duration("duration", time.Since(start)))
+}
+
+// warmStoryAreas primes the public stats cache and the database's buffer cache
+// for the busiest story areas, the ones the first app opens will ask for
+func warmStoryAreas(repo *repository.PostgresRepository, stats *domain.StatsService, stories *domain.StoryService, limit int) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		areas, err := repo.GetBusiestStoryAreas(ctx, limit)
+		if err != nil {
+			return err
+		}
+		for _, area := range areas {
+			lat, lng, radius := area.Lat, area.Lng, warmupAreaRadius
+			if _, err := stats.GetPublicStats(ctx, &lat, &lng); err != nil {
+				return err
+			}
+			if _, err := stories.GetFeed(ctx, uuid.Nil, 1, domain.DefaultFeedLimit, &lat, &lng, &radius); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_chats_direct_pair;
+ALTER TABLE chats DROP CONSTRAINT IF EXISTS chats_direct_pair_ordered;
+ALTER TABLE chats
+DROP COLUMN IF EXISTS direct_user_high,
+DROP COLUMN IF EXISTS direct_user_low;
@@ -0,0 +1,46 @@
+-- Canonical pair key for 1:1 chats so the same two users can never end up
+-- with more than one chat. Group chats leave both columns NULL.
+ALTER TABLE chats
+ADD COLUMN direct_user_low UUID REFERENCES users(id) ON DELETE CASCADE,
+ADD COLUMN direct_user_high UUID REFERENCES users(id) ON DELETE CASCADE;
+
+-- Find existing 1:1 chats and pick the oldest chat for each pair as canonical
+CREATE TEMP TABLE direct_chat_pairs AS
+SELECT p.chat_id,
+       p.user_low,
+       p.user_high,
+       FIRST_VALUE(p.chat_id) OVER (PARTITION BY p.user_low, p.user_high ORDER BY c.created_at, c.id) AS canonical_id
+FROM (
+    SELECT chat_id,
+           (ARRAY_AGG(user_id ORDER BY user_id))[1] AS user_low,
+           (ARRAY_AGG(user_id ORDER BY user_id))[2] AS user_high
+    FROM chat_participants
+    GROUP BY chat_id
+    HAVING COUNT(*) = 2
+) p
+JOIN chats c ON c.id = p.chat_id;
+
+-- Merge messages from duplicate chats into the canonical one, then drop the duplicates
+UPDATE messages m
+SET chat_id = d.canonical_id
+FROM direct_chat_pairs d
+WHERE m.chat_id = d.chat_id AND d.chat_id != d.canonical_id;
+
+DELETE FROM chats c
+USING direct_chat_pairs d
+WHERE c.id = d.chat_id AND d.chat_id != d.canonical_id;
+
+UPDATE chats c
+SET direct_user_low = d.user_low,
+    direct_user_high = d.user_high,
+    updated_at = GREATEST(c.updated_at, COALESCE((SELECT MAX(created_at) FROM messages WHERE chat_id = c.id), c.updated_at))
+FROM direct_chat_pairs d
+WHERE c.id = d.chat_id AND d.chat_id = d.canonical_id;
+
+DROP TABLE direct_chat_pairs;
+
+ALTER TABLE chats
+ADD CONSTRAINT chats_direct_pair_ordered CHECK (direct_user_low < direct_user_high);
+
+CREATE UNIQUE INDEX idx_chats_direct_pair ON chats(direct_user_low, direct_user_high)
+WHERE direct_user_low IS NOT NULL;
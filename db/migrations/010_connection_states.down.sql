@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_connections_pair;
+ALTER TABLE connections ADD CONSTRAINT unique_connection UNIQUE (requester_id, receiver_id);
+
+-- Enum values cannot be dropped; remove blocks so the remaining rows use the original states
+DELETE FROM connections WHERE status = 'blocked';
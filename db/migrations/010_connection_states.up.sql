@@ -0,0 +1,19 @@
+-- Allow users to block each other
+ALTER TYPE connection_status ADD VALUE IF NOT EXISTS 'blocked';
+
+-- Collapse reverse-direction duplicates (A->B and B->A) into a single row per pair,
+-- preferring an accepted row and then the most recently updated one
+DELETE FROM connections c
+USING (
+    SELECT id,
+           ROW_NUMBER() OVER (
+               PARTITION BY LEAST(requester_id, receiver_id), GREATEST(requester_id, receiver_id)
+               ORDER BY (status = 'accepted') DESC, updated_at DESC
+           ) AS rn
+    FROM connections
+) d
+WHERE c.id = d.id AND d.rn > 1;
+
+-- One row per unordered pair; direction is carried by requester_id/receiver_id
+ALTER TABLE connections DROP CONSTRAINT IF EXISTS unique_connection;
+CREATE UNIQUE INDEX idx_connections_pair ON connections (LEAST(requester_id, receiver_id), GREATEST(requester_id, receiver_id));
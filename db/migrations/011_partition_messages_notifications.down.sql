@@ -0,0 +1,46 @@
+-- Messages
+ALTER TABLE messages RENAME TO messages_partitioned;
+ALTER TABLE messages_partitioned RENAME CONSTRAINT messages_pkey TO messages_partitioned_pkey;
+
+CREATE TABLE messages (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    chat_id UUID NOT NULL REFERENCES chats(id) ON DELETE CASCADE,
+    sender_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    read_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO messages (id, chat_id, sender_id, content, read_at, created_at)
+SELECT id, chat_id, sender_id, content, read_at, created_at FROM messages_partitioned;
+
+DROP TABLE messages_partitioned;
+
+CREATE INDEX idx_messages_chat_id ON messages(chat_id);
+CREATE INDEX idx_messages_sender_id ON messages(sender_id);
+
+-- Notifications
+ALTER TABLE notifications RENAME TO notifications_partitioned;
+ALTER TABLE notifications_partitioned RENAME CONSTRAINT notifications_pkey TO notifications_partitioned_pkey;
+
+CREATE TABLE notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    data JSONB DEFAULT '{}'::JSONB,
+    is_read BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO notifications (id, user_id, type, title, body, data, is_read, created_at)
+SELECT id, user_id, type, title, body, data, is_read, created_at FROM notifications_partitioned;
+
+DROP TABLE notifications_partitioned;
+
+CREATE INDEX idx_notifications_user_id ON notifications(user_id);
+CREATE INDEX idx_notifications_is_read ON notifications(is_read);
+
+DROP FUNCTION IF EXISTS drop_partitions_before(TEXT, TIMESTAMPTZ);
+DROP FUNCTION IF EXISTS create_monthly_partitions(TEXT, DATE, INT);
@@ -0,0 +1,105 @@
+-- Monthly range partitioning on created_at for messages and notifications so
+-- that queries only touch recent partitions and retention is a cheap DROP TABLE.
+-- Partitions are named <table>_pYYYY_MM and kept ahead of time by the
+-- maintenance job in the API, which calls the functions below.
+
+CREATE OR REPLACE FUNCTION create_monthly_partitions(parent TEXT, from_month DATE, months_ahead INT)
+RETURNS INT
+LANGUAGE plpgsql AS $$
+DECLARE
+    month_start DATE := date_trunc('month', from_month)::DATE;
+    last_month DATE := (date_trunc('month', NOW()) + make_interval(months => months_ahead))::DATE;
+    partition_name TEXT;
+    created INT := 0;
+BEGIN
+    WHILE month_start <= last_month LOOP
+        partition_name := format('%s_p%s', parent, to_char(month_start, 'YYYY_MM'));
+        IF to_regclass(partition_name) IS NULL THEN
+            EXECUTE format('CREATE TABLE %I PARTITION OF %I FOR VALUES FROM (%L) TO (%L)',
+                partition_name, parent, month_start, (month_start + INTERVAL '1 month')::DATE);
+            created := created + 1;
+        END IF;
+        month_start := (month_start + INTERVAL '1 month')::DATE;
+    END LOOP;
+    RETURN created;
+END;
+$$;
+
+-- Drops partitions whose entire range ends at or before cutoff
+CREATE OR REPLACE FUNCTION drop_partitions_before(parent TEXT, cutoff TIMESTAMPTZ)
+RETURNS INT
+LANGUAGE plpgsql AS $$
+DECLARE
+    part RECORD;
+    dropped INT := 0;
+BEGIN
+    FOR part IN
+        SELECT c.relname
+        FROM pg_inherits i
+        JOIN pg_class c ON c.oid = i.inhrelid
+        WHERE i.inhparent = parent::REGCLASS
+          AND c.relname ~ ('^' || parent || '_p\d{4}_\d{2}$')
+    LOOP
+        IF to_date(right(part.relname, 7), 'YYYY_MM') + INTERVAL '1 month' <= cutoff THEN
+            EXECUTE format('DROP TABLE %I', part.relname);
+            dropped := dropped + 1;
+        END IF;
+    END LOOP;
+    RETURN dropped;
+END;
+$$;
+
+-- Messages
+ALTER TABLE messages RENAME TO messages_unpartitioned;
+ALTER TABLE messages_unpartitioned RENAME CONSTRAINT messages_pkey TO messages_unpartitioned_pkey;
+ALTER INDEX idx_messages_chat_id RENAME TO idx_messages_unpartitioned_chat_id;
+ALTER INDEX idx_messages_sender_id RENAME TO idx_messages_unpartitioned_sender_id;
+
+-- The partition key must be part of the primary key
+CREATE TABLE messages (
+    id UUID NOT NULL DEFAULT gen_random_uuid(),
+    chat_id UUID NOT NULL REFERENCES chats(id) ON DELETE CASCADE,
+    sender_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    read_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+
+CREATE INDEX idx_messages_chat_created ON messages(chat_id, created_at DESC);
+CREATE INDEX idx_messages_sender_id ON messages(sender_id);
+
+SELECT create_monthly_partitions('messages', COALESCE((SELECT MIN(created_at) FROM messages_unpartitioned), NOW())::DATE, 3);
+
+INSERT INTO messages (id, chat_id, sender_id, content, read_at, created_at)
+SELECT id, chat_id, sender_id, content, read_at, created_at FROM messages_unpartitioned;
+
+DROP TABLE messages_unpartitioned;
+
+-- Notifications
+ALTER TABLE notifications RENAME TO notifications_unpartitioned;
+ALTER TABLE notifications_unpartitioned RENAME CONSTRAINT notifications_pkey TO notifications_unpartitioned_pkey;
+ALTER INDEX idx_notifications_user_id RENAME TO idx_notifications_unpartitioned_user_id;
+ALTER INDEX idx_notifications_is_read RENAME TO idx_notifications_unpartitioned_is_read;
+
+CREATE TABLE notifications (
+    id UUID NOT NULL DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    data JSONB DEFAULT '{}'::JSONB,
+    is_read BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+
+CREATE INDEX idx_notifications_user_created ON notifications(user_id, created_at DESC);
+CREATE INDEX idx_notifications_is_read ON notifications(is_read);
+
+SELECT create_monthly_partitions('notifications', COALESCE((SELECT MIN(created_at) FROM notifications_unpartitioned), NOW())::DATE, 3);
+
+INSERT INTO notifications (id, user_id, type, title, body, data, is_read, created_at)
+SELECT id, user_id, type, title, body, data, is_read, created_at FROM notifications_unpartitioned;
+
+DROP TABLE notifications_unpartitioned;
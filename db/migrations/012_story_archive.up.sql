@@ -0,0 +1,17 @@
+-- Metadata of expired stories kept for analytics and abuse investigations.
+-- No foreign keys: archived rows must outlive the story and, if needed, the user.
+CREATE TABLE story_archive (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    media_url TEXT NOT NULL,
+    media_type VARCHAR(50) NOT NULL,
+    caption TEXT,
+    location_lat DOUBLE PRECISION,
+    location_lng DOUBLE PRECISION,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    archived_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_story_archive_user_id ON story_archive(user_id, created_at DESC);
+CREATE INDEX idx_story_archive_archived_at ON story_archive(archived_at);
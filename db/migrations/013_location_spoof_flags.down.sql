@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS abuse_signals;
+ALTER TABLE stories DROP COLUMN IF EXISTS location_flags;
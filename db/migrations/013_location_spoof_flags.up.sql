@@ -0,0 +1,16 @@
+-- Flags raised by server-side location sanity checks; flagged stories are
+-- down-ranked in the nearby feed
+ALTER TABLE stories ADD COLUMN location_flags TEXT[] NOT NULL DEFAULT '{}';
+
+-- Abuse signals from automated checks, summed per user into an abuse score
+CREATE TABLE abuse_signals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(50) NOT NULL,
+    weight INT NOT NULL,
+    subject_id UUID,
+    details JSONB NOT NULL DEFAULT '{}'::JSONB,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_abuse_signals_user_created ON abuse_signals(user_id, created_at DESC);
@@ -0,0 +1,2 @@
+ALTER TABLE story_archive DROP COLUMN IF EXISTS location_fuzzed;
+ALTER TABLE stories DROP COLUMN IF EXISTS location_fuzzed;
@@ -0,0 +1,4 @@
+-- Stories whose displayed location is snapped to a coarse grid. The exact
+-- coordinates stay in location_lat/location_lng for radius queries.
+ALTER TABLE stories ADD COLUMN location_fuzzed BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE story_archive ADD COLUMN location_fuzzed BOOLEAN NOT NULL DEFAULT FALSE;
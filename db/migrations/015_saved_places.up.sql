@@ -0,0 +1,16 @@
+-- Saved places (home, work, ...) inside which story locations are hidden or
+-- fuzzed and live location sharing is blocked
+CREATE TABLE saved_places (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('home', 'work', 'other')),
+    lat DOUBLE PRECISION NOT NULL,
+    lng DOUBLE PRECISION NOT NULL,
+    radius_meters INT NOT NULL CHECK (radius_meters BETWEEN 50 AND 2000),
+    story_privacy VARCHAR(10) NOT NULL CHECK (story_privacy IN ('hide', 'fuzz')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_saved_places_user ON saved_places(user_id);
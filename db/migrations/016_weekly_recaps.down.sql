@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS weekly_recaps;
+DROP TRIGGER IF EXISTS stories_activity_rollup ON stories;
+DROP FUNCTION IF EXISTS rollup_story_activity();
+DROP TABLE IF EXISTS user_activity_daily;
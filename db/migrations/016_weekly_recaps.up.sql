@@ -0,0 +1,46 @@
+-- Daily per-user activity rollup. Stories are deleted when they expire, so
+-- weekly aggregates are maintained here as they are posted.
+CREATE TABLE user_activity_daily (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    stories_posted INT NOT NULL DEFAULT 0,
+    last_lat DOUBLE PRECISION,
+    last_lng DOUBLE PRECISION,
+    PRIMARY KEY (user_id, day)
+);
+
+CREATE INDEX idx_user_activity_daily_day ON user_activity_daily(day);
+
+CREATE OR REPLACE FUNCTION rollup_story_activity() RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO user_activity_daily (user_id, day, stories_posted, last_lat, last_lng)
+    VALUES (NEW.user_id, (NEW.created_at AT TIME ZONE 'UTC')::date, 1, NEW.location_lat, NEW.location_lng)
+    ON CONFLICT (user_id, day) DO UPDATE SET
+        stories_posted = user_activity_daily.stories_posted + 1,
+        last_lat = COALESCE(EXCLUDED.last_lat, user_activity_daily.last_lat),
+        last_lng = COALESCE(EXCLUDED.last_lng, user_activity_daily.last_lng);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER stories_activity_rollup
+    AFTER INSERT ON stories
+    FOR EACH ROW EXECUTE FUNCTION rollup_story_activity();
+
+INSERT INTO user_activity_daily (user_id, day, stories_posted, last_lat, last_lng)
+SELECT DISTINCT ON (user_id, day)
+    user_id, day, COUNT(*) OVER (PARTITION BY user_id, day), location_lat, location_lng
+FROM (
+    SELECT user_id, (created_at AT TIME ZONE 'UTC')::date AS day, location_lat, location_lng, created_at
+    FROM stories
+) s
+ORDER BY user_id, day, (location_lat IS NULL), created_at DESC;
+
+-- One recap per user per week; the row doubles as the in-app card
+CREATE TABLE weekly_recaps (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    week_start DATE NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, week_start)
+);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_sessions_user_activity;
+DROP TABLE IF EXISTS campaign_opt_outs;
+DROP TABLE IF EXISTS campaign_sends;
@@ -0,0 +1,22 @@
+-- Lifecycle campaign messages; drives frequency caps and campaign stats
+CREATE TABLE campaign_sends (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    campaign VARCHAR(50) NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    channel VARCHAR(10) NOT NULL CHECK (channel IN ('push', 'email')),
+    sent_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_campaign_sends_user ON campaign_sends(user_id, sent_at DESC);
+CREATE INDEX idx_campaign_sends_campaign ON campaign_sends(campaign, sent_at);
+
+-- campaign '*' opts the user out of every lifecycle campaign
+CREATE TABLE campaign_opt_outs (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    campaign VARCHAR(50) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, campaign)
+);
+
+-- Inactivity is measured from session activity, which is now touched on refresh
+CREATE INDEX idx_sessions_user_activity ON sessions(user_id, last_activity_at DESC);
@@ -0,0 +1,9 @@
+-- Lightweight seen log for feed items; rows go away with their story
+CREATE TABLE story_views (
+    story_id UUID NOT NULL REFERENCES stories(id) ON DELETE CASCADE,
+    viewer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (viewer_id, story_id)
+);
+
+CREATE INDEX idx_story_views_story ON story_views(story_id);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS moderation_actions;
+DROP TABLE IF EXISTS reports;
+ALTER TABLE chats DROP COLUMN IF EXISTS frozen_at;
+ALTER TABLE stories DROP COLUMN IF EXISTS hidden_at;
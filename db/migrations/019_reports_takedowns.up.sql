@@ -0,0 +1,34 @@
+ALTER TABLE stories ADD COLUMN hidden_at TIMESTAMPTZ;
+ALTER TABLE chats ADD COLUMN frozen_at TIMESTAMPTZ;
+
+-- One report per reporter per target, so counts are of distinct reporters
+CREATE TABLE reports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    reporter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    target_type VARCHAR(10) NOT NULL CHECK (target_type IN ('story', 'chat')),
+    target_id UUID NOT NULL,
+    reason VARCHAR(20) NOT NULL,
+    details TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (reporter_id, target_type, target_id)
+);
+
+CREATE INDEX idx_reports_target ON reports(target_type, target_id, created_at);
+
+-- Automatic takedowns awaiting or after admin review
+CREATE TABLE moderation_actions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    target_type VARCHAR(10) NOT NULL CHECK (target_type IN ('story', 'chat')),
+    target_id UUID NOT NULL,
+    owner_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    report_count INT NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'upheld', 'reversed')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMPTZ
+);
+
+-- At most one open or upheld takedown per target
+CREATE UNIQUE INDEX idx_moderation_actions_active
+    ON moderation_actions(target_type, target_id) WHERE status IN ('pending', 'upheld');
+CREATE INDEX idx_moderation_actions_status ON moderation_actions(status, created_at);
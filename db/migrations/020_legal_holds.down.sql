@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS admin_audit_log;
+DROP TABLE IF EXISTS preservation_snapshots;
+DROP TABLE IF EXISTS legal_holds;
+DROP FUNCTION IF EXISTS reject_modification();
@@ -0,0 +1,52 @@
+-- Accounts under legal hold are skipped by every retention and cleanup job.
+-- The FK has no ON DELETE action, so a held user can't be hard-deleted either.
+CREATE TABLE legal_holds (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    reason TEXT NOT NULL,
+    case_reference VARCHAR(255),
+    placed_by UUID NOT NULL,
+    placed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    released_by UUID,
+    released_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_legal_holds_active ON legal_holds(user_id) WHERE released_at IS NULL;
+
+-- Data captured when a hold is placed; checksum is the SHA-256 of data as stored
+CREATE TABLE preservation_snapshots (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    hold_id UUID NOT NULL REFERENCES legal_holds(id),
+    user_id UUID NOT NULL,
+    data JSONB NOT NULL,
+    checksum CHAR(64) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_preservation_snapshots_hold ON preservation_snapshots(hold_id);
+
+-- Append-only record of admin actions on user data
+CREATE TABLE admin_audit_log (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    actor_id UUID NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    target_user_id UUID,
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_admin_audit_log_target ON admin_audit_log(target_user_id, created_at DESC);
+
+CREATE OR REPLACE FUNCTION reject_modification() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION '% is append-only', TG_TABLE_NAME;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER preservation_snapshots_immutable
+    BEFORE UPDATE OR DELETE ON preservation_snapshots
+    FOR EACH ROW EXECUTE FUNCTION reject_modification();
+
+CREATE TRIGGER admin_audit_log_immutable
+    BEFORE UPDATE OR DELETE ON admin_audit_log
+    FOR EACH ROW EXECUTE FUNCTION reject_modification();
@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS admin_audit_log_immutable ON admin_audit_log;
+
+CREATE TRIGGER admin_audit_log_immutable
+    BEFORE UPDATE OR DELETE ON admin_audit_log
+    FOR EACH ROW EXECUTE FUNCTION reject_modification();
+
+DROP FUNCTION IF EXISTS guard_audit_log();
@@ -0,0 +1,20 @@
+-- The audit log stays append-only, except that a pseudonymization request may
+-- rewrite details within a transaction that sets locolive.pii_redaction
+CREATE OR REPLACE FUNCTION guard_audit_log() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND current_setting('locolive.pii_redaction', true) = 'on'
+        AND (NEW.id, NEW.actor_id, NEW.action, NEW.target_user_id, NEW.created_at)
+            IS NOT DISTINCT FROM (OLD.id, OLD.actor_id, OLD.action, OLD.target_user_id, OLD.created_at)
+    THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION '% is append-only', TG_TABLE_NAME;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER admin_audit_log_immutable ON admin_audit_log;
+
+CREATE TRIGGER admin_audit_log_immutable
+    BEFORE UPDATE OR DELETE ON admin_audit_log
+    FOR EACH ROW EXECUTE FUNCTION guard_audit_log();
@@ -0,0 +1,3 @@
+-- ISO 3166-1 alpha-2 country from the user's profile; used for region gating
+-- when the edge doesn't supply one
+ALTER TABLE users ADD COLUMN country_code VARCHAR(2);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS remote_config;
+DROP TABLE IF EXISTS remote_config_history;
@@ -0,0 +1,42 @@
+-- Every change to remote config is appended here; the highest id is the
+-- current config version
+CREATE TABLE remote_config_history (
+    id BIGSERIAL PRIMARY KEY,
+    key VARCHAR(100) NOT NULL,
+    platform VARCHAR(10) NOT NULL,
+    value JSONB,
+    deleted BOOLEAN NOT NULL DEFAULT FALSE,
+    changed_by UUID,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_remote_config_history_key ON remote_config_history(key, id DESC);
+
+-- Current client config. platform '' applies to every platform; a row for a
+-- specific platform overrides it there.
+CREATE TABLE remote_config (
+    key VARCHAR(100) NOT NULL,
+    platform VARCHAR(10) NOT NULL DEFAULT '',
+    value JSONB NOT NULL,
+    description TEXT,
+    version BIGINT NOT NULL REFERENCES remote_config_history(id),
+    updated_by UUID,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (key, platform)
+);
+
+WITH seeded AS (
+    INSERT INTO remote_config_history (key, platform, value)
+    VALUES
+        ('feed.default_radius_meters', '', '5000'),
+        ('upload.max_story_mb', '', '10')
+    RETURNING id, key, platform, value
+)
+INSERT INTO remote_config (key, platform, value, description, version)
+SELECT key, platform, value,
+    CASE key
+        WHEN 'feed.default_radius_meters' THEN 'Nearby feed radius when the user has not picked one'
+        WHEN 'upload.max_story_mb' THEN 'Largest story upload the server accepts'
+    END,
+    id
+FROM seeded;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS card_dismissals;
+DROP TABLE IF EXISTS cards;
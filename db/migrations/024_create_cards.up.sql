@@ -0,0 +1,27 @@
+-- Server-driven in-app cards (what's new, tips, prompts) shown in priority order
+CREATE TABLE cards (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    kind VARCHAR(20) NOT NULL,
+    title VARCHAR(100) NOT NULL,
+    body TEXT NOT NULL,
+    image_url TEXT,
+    action_label VARCHAR(50),
+    action_url TEXT,
+    segment VARCHAR(30) NOT NULL DEFAULT 'all',
+    platform VARCHAR(10) NOT NULL DEFAULT '',
+    priority INTEGER NOT NULL DEFAULT 0,
+    starts_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ends_at TIMESTAMPTZ,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_cards_live ON cards(priority DESC, created_at DESC) WHERE active;
+
+CREATE TABLE card_dismissals (
+    card_id UUID NOT NULL REFERENCES cards(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    dismissed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, card_id)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE stories DROP COLUMN IF EXISTS live_session_id;
+DROP TABLE IF EXISTS live_sessions;
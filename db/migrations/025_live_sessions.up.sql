@@ -0,0 +1,18 @@
+-- A live session groups the stories an author posts in quick succession from
+-- one spot (e.g. a concert). While it is live its stories are pinned to the
+-- top of the nearby feed.
+CREATE TABLE live_sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    location_lat DOUBLE PRECISION NOT NULL,
+    location_lng DOUBLE PRECISION NOT NULL,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_post_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ended_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_live_sessions_active ON live_sessions(user_id) WHERE ended_at IS NULL;
+
+ALTER TABLE stories ADD COLUMN live_session_id UUID REFERENCES live_sessions(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_stories_live_session ON stories(live_session_id) WHERE live_session_id IS NOT NULL;
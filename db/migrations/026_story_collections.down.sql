@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS collection_stories;
+DROP TABLE IF EXISTS collection_contributors;
+DROP TABLE IF EXISTS story_collections;
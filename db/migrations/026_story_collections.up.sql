@@ -0,0 +1,35 @@
+-- Shared story reels for an event or place that several users contribute to
+CREATE TABLE story_collections (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(10) NOT NULL,
+    title VARCHAR(100) NOT NULL,
+    description TEXT,
+    location_lat DOUBLE PRECISION,
+    location_lng DOUBLE PRECISION,
+    -- open: anyone may contribute; approval: the owner approves each contributor
+    contribution_policy VARCHAR(10) NOT NULL DEFAULT 'approval',
+    starts_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ends_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_story_collections_owner ON story_collections(owner_id);
+
+CREATE TABLE collection_contributors (
+    collection_id UUID NOT NULL REFERENCES story_collections(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending',
+    requested_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    decided_at TIMESTAMPTZ,
+    PRIMARY KEY (collection_id, user_id)
+);
+
+CREATE TABLE collection_stories (
+    collection_id UUID NOT NULL REFERENCES story_collections(id) ON DELETE CASCADE,
+    story_id UUID NOT NULL REFERENCES stories(id) ON DELETE CASCADE,
+    added_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (collection_id, story_id)
+);
+
+CREATE INDEX idx_collection_stories_story ON collection_stories(story_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_strikes;
+DROP TABLE IF EXISTS copyright_claims;
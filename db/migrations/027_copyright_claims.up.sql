@@ -0,0 +1,35 @@
+-- DMCA-style copyright claims against stories. The story is hidden as soon as
+-- a claim is filed and stays hidden unless the claim is rejected or the
+-- content is restored after a counter-notice.
+CREATE TABLE copyright_claims (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    story_id UUID NOT NULL,
+    owner_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    claimant_name VARCHAR(200) NOT NULL,
+    claimant_email VARCHAR(255) NOT NULL,
+    work_description TEXT NOT NULL,
+    signature VARCHAR(200) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'upheld', 'rejected', 'counter_noticed', 'restored')),
+    counter_notice TEXT,
+    counter_signature VARCHAR(200),
+    counter_noticed_at TIMESTAMPTZ,
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_copyright_claims_status ON copyright_claims(status, created_at);
+CREATE INDEX idx_copyright_claims_story ON copyright_claims(story_id);
+
+-- One strike per upheld claim; restoring the content revokes it
+CREATE TABLE user_strikes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL,
+    claim_id UUID NOT NULL UNIQUE REFERENCES copyright_claims(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_user_strikes_user ON user_strikes(user_id, created_at DESC);
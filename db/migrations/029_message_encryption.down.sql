@@ -0,0 +1,7 @@
+-- Encrypted rows can't be decrypted in SQL; this fails while any remain
+DROP INDEX IF EXISTS idx_messages_plaintext;
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS messages_content_present;
+ALTER TABLE messages ALTER COLUMN content SET NOT NULL;
+ALTER TABLE messages DROP COLUMN IF EXISTS key_version;
+ALTER TABLE messages DROP COLUMN IF EXISTS content_ciphertext;
+DROP TABLE IF EXISTS chat_keys;
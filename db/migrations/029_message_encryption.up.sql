@@ -0,0 +1,25 @@
+-- Per-chat data keys for encrypting messages at rest. Keys are stored wrapped
+-- by a master key that lives outside the database (config or KMS), so a
+-- database backup alone can't be used to read messages. A chat starts a new
+-- version periodically; old versions stay to decrypt older messages.
+CREATE TABLE chat_keys (
+    chat_id UUID NOT NULL REFERENCES chats(id) ON DELETE CASCADE,
+    version INT NOT NULL,
+    master_key_id VARCHAR(64) NOT NULL,
+    wrapped_key BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (chat_id, version)
+);
+
+CREATE INDEX idx_chat_keys_master_key ON chat_keys(master_key_id);
+
+-- Encrypted messages keep content NULL and store content_ciphertext under the
+-- chat key version in key_version. Existing plaintext rows are encrypted in
+-- batches by the API's message encryption worker.
+ALTER TABLE messages ALTER COLUMN content DROP NOT NULL;
+ALTER TABLE messages ADD COLUMN content_ciphertext BYTEA;
+ALTER TABLE messages ADD COLUMN key_version INT;
+ALTER TABLE messages ADD CONSTRAINT messages_content_present
+    CHECK ((content IS NULL) <> (content_ciphertext IS NULL));
+
+CREATE INDEX idx_messages_plaintext ON messages(created_at) WHERE content_ciphertext IS NULL;
@@ -0,0 +1,13 @@
+-- One-time codes sent by SMS for phone login and verification. Only a hash
+-- of the code is stored.
+CREATE TABLE phone_otps (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    phone VARCHAR(20) NOT NULL,
+    code_hash VARCHAR(255) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ NOT NULL,
+    consumed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_phone_otps_phone_created ON phone_otps(phone, created_at DESC);
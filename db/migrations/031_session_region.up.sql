@@ -0,0 +1,3 @@
+-- Country the session signed in from, carried in its tokens so requests can be
+-- routed to regional storage (and later read replicas) without a lookup
+ALTER TABLE sessions ADD COLUMN region VARCHAR(2);
@@ -0,0 +1,2 @@
+-- Apple's stable user identifier (the identity token's sub) for Sign in with Apple
+ALTER TABLE users ADD COLUMN apple_id VARCHAR(255) UNIQUE;
@@ -0,0 +1,6 @@
+-- Every refresh token descends from one login; reuse of a rotated token revokes only its family
+ALTER TABLE refresh_tokens ADD COLUMN family_id UUID;
+UPDATE refresh_tokens SET family_id = COALESCE(session_id, id);
+ALTER TABLE refresh_tokens ALTER COLUMN family_id SET NOT NULL;
+
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens(family_id);
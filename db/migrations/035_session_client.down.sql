@@ -0,0 +1,2 @@
+ALTER TABLE sessions DROP COLUMN IF EXISTS app_version;
+ALTER TABLE sessions DROP COLUMN IF EXISTS platform;
@@ -0,0 +1,3 @@
+-- The app build a session signed in from, from the X-Client header
+ALTER TABLE sessions ADD COLUMN platform VARCHAR(16);
+ALTER TABLE sessions ADD COLUMN app_version VARCHAR(32);
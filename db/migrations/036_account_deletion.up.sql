@@ -0,0 +1,3 @@
+-- When the user deleted their account; the row is purged after a grace period
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMPTZ;
+CREATE INDEX idx_users_deleted_at ON users(deleted_at) WHERE deleted_at IS NOT NULL;
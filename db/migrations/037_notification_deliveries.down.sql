@@ -0,0 +1,2 @@
+ALTER TABLE notifications DROP COLUMN IF EXISTS opened_at;
+DROP TABLE IF EXISTS notification_deliveries;
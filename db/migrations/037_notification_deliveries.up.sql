@@ -0,0 +1,17 @@
+-- Outcome of every push, one row per device. notifications is partitioned, so
+-- notification_id can't be a foreign key.
+CREATE TABLE notification_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    notification_id UUID NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    error_class VARCHAR(32),
+    sent_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_notification_deliveries_sent_at ON notification_deliveries(sent_at);
+CREATE INDEX idx_notification_deliveries_notification ON notification_deliveries(notification_id);
+
+-- Set when the user opens the notification from a push
+ALTER TABLE notifications ADD COLUMN opened_at TIMESTAMPTZ;
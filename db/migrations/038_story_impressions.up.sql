@@ -0,0 +1,8 @@
+-- Stories a viewer's app rendered, whether or not they opened them. One row per
+-- viewer, so counts are reach; rows go away with their story.
+CREATE TABLE story_impressions (
+    story_id UUID NOT NULL REFERENCES stories(id) ON DELETE CASCADE,
+    viewer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    first_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (story_id, viewer_id)
+);
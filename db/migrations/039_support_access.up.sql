@@ -0,0 +1,10 @@
+-- A user's consent for support agents to view their account read-only
+CREATE TABLE support_access_grants (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_support_access_grants_user ON support_access_grants(user_id) WHERE revoked_at IS NULL;
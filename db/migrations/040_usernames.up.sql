@@ -0,0 +1,3 @@
+-- Public handle, stored lowercase so uniqueness is case-insensitive
+ALTER TABLE users ADD COLUMN username VARCHAR(30);
+ALTER TABLE users ADD CONSTRAINT users_username_key UNIQUE (username);
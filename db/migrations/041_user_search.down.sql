@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_users_bio_search;
+DROP INDEX IF EXISTS idx_users_username_trgm;
+DROP INDEX IF EXISTS idx_users_name_trgm;
+DROP EXTENSION IF EXISTS pg_trgm;
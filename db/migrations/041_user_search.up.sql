@@ -0,0 +1,7 @@
+-- Trigram indexes back substring and fuzzy matching of names and handles in
+-- user search; bios match by word
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_users_name_trgm ON users USING GIN (name gin_trgm_ops) WHERE is_active = TRUE;
+CREATE INDEX idx_users_username_trgm ON users USING GIN (username gin_trgm_ops) WHERE is_active = TRUE;
+CREATE INDEX idx_users_bio_search ON users USING GIN (to_tsvector('simple', COALESCE(bio, ''))) WHERE is_active = TRUE;
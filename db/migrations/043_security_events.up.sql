@@ -0,0 +1,12 @@
+-- Security-sensitive account activity, shown to the user and to admins
+CREATE TABLE security_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event VARCHAR(40) NOT NULL,
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_security_events_user ON security_events(user_id, created_at DESC);
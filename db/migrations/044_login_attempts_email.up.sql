@@ -0,0 +1,2 @@
+-- Recent failures per email decide when logins need a captcha
+CREATE INDEX idx_login_attempts_email ON login_attempts(email, created_at DESC);
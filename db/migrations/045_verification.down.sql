@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS verification_requests;
+ALTER TABLE users DROP COLUMN IF EXISTS verified_at;
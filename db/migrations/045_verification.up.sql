@@ -0,0 +1,21 @@
+-- Verified badges for public figures. verified_at is set when an admin
+-- approves a request and cleared if the badge is revoked.
+ALTER TABLE users ADD COLUMN verified_at TIMESTAMPTZ;
+
+CREATE TABLE verification_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    category VARCHAR(20) NOT NULL,
+    details TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'rejected')),
+    review_note TEXT,
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- One request awaiting review per user
+CREATE UNIQUE INDEX idx_verification_requests_pending ON verification_requests(user_id) WHERE status = 'pending';
+CREATE INDEX idx_verification_requests_status ON verification_requests(status, created_at);
+CREATE INDEX idx_verification_requests_user ON verification_requests(user_id, created_at DESC);
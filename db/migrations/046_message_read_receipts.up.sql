@@ -0,0 +1,2 @@
+-- Unread counts and mark-as-read only touch a chat's unread messages
+CREATE INDEX idx_messages_unread ON messages(chat_id, sender_id) WHERE read_at IS NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_stories_media_hash_recent;
+ALTER TABLE stories DROP COLUMN IF EXISTS duplicate_of;
+ALTER TABLE stories DROP COLUMN IF EXISTS media_hash;
@@ -0,0 +1,6 @@
+-- Perceptual hash of image stories, and the earlier story an upload repeats
+ALTER TABLE stories ADD COLUMN media_hash BIGINT;
+ALTER TABLE stories ADD COLUMN duplicate_of UUID;
+
+-- Uploads are compared against the last day of hashed stories
+CREATE INDEX idx_stories_media_hash_recent ON stories(created_at) WHERE media_hash IS NOT NULL;
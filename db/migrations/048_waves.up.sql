@@ -0,0 +1,14 @@
+-- Waves are a lightweight hello to someone nearby. A user can wave at the
+-- same person once per UTC day; waves in both directions connect the pair.
+CREATE TABLE waves (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    sender_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    receiver_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    day DATE NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC')::date,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (sender_id, receiver_id, day),
+    CHECK (sender_id <> receiver_id)
+);
+
+CREATE INDEX idx_waves_receiver ON waves(receiver_id, created_at DESC);
+CREATE INDEX idx_waves_pair ON waves(sender_id, receiver_id, created_at DESC);
@@ -0,0 +1,6 @@
+ALTER TABLE chat_participants DROP COLUMN IF EXISTS role;
+ALTER TABLE chats DROP CONSTRAINT IF EXISTS chats_group_not_direct;
+ALTER TABLE chats DROP COLUMN IF EXISTS created_by;
+ALTER TABLE chats DROP COLUMN IF EXISTS avatar_url;
+ALTER TABLE chats DROP COLUMN IF EXISTS name;
+ALTER TABLE chats DROP COLUMN IF EXISTS is_group;
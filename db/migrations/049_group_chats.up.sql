@@ -0,0 +1,12 @@
+-- Group chats have a name, an optional avatar and per-member roles. 1:1 chats
+-- keep using the direct pair key and leave these NULL.
+ALTER TABLE chats ADD COLUMN is_group BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE chats ADD COLUMN name VARCHAR(100);
+ALTER TABLE chats ADD COLUMN avatar_url TEXT;
+ALTER TABLE chats ADD COLUMN created_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE chats
+ADD CONSTRAINT chats_group_not_direct CHECK (NOT is_group OR direct_user_low IS NULL);
+
+ALTER TABLE chat_participants ADD COLUMN role VARCHAR(10) NOT NULL DEFAULT 'member'
+    CHECK (role IN ('admin', 'member'));
@@ -0,0 +1,11 @@
+DELETE FROM moderation_actions WHERE target_type = 'comment';
+DELETE FROM reports WHERE target_type = 'comment';
+ALTER TABLE moderation_actions DROP CONSTRAINT moderation_actions_target_type_check;
+ALTER TABLE moderation_actions ADD CONSTRAINT moderation_actions_target_type_check
+    CHECK (target_type IN ('story', 'chat'));
+ALTER TABLE reports DROP CONSTRAINT reports_target_type_check;
+ALTER TABLE reports ADD CONSTRAINT reports_target_type_check
+    CHECK (target_type IN ('story', 'chat'));
+
+DROP TABLE IF EXISTS story_comments;
+ALTER TABLE stories DROP COLUMN IF EXISTS comments_disabled;
@@ -0,0 +1,25 @@
+-- Comments on stories. Authors can turn commenting off per story; comments
+-- go when their story expires and is deleted.
+ALTER TABLE stories ADD COLUMN comments_disabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE story_comments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    story_id UUID NOT NULL REFERENCES stories(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    -- Users @mentioned in the comment who could see the story
+    mention_ids UUID[] NOT NULL DEFAULT '{}',
+    hidden_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_story_comments_story ON story_comments(story_id, created_at);
+CREATE INDEX idx_story_comments_user ON story_comments(user_id);
+
+-- Comments can be reported and taken down like stories and chats
+ALTER TABLE reports DROP CONSTRAINT reports_target_type_check;
+ALTER TABLE reports ADD CONSTRAINT reports_target_type_check
+    CHECK (target_type IN ('story', 'chat', 'comment'));
+ALTER TABLE moderation_actions DROP CONSTRAINT moderation_actions_target_type_check;
+ALTER TABLE moderation_actions ADD CONSTRAINT moderation_actions_target_type_check
+    CHECK (target_type IN ('story', 'chat', 'comment'));
@@ -0,0 +1,11 @@
+-- Stories a user has bookmarked. A saved story lives only as long as the
+-- story: when cleanup deletes or archives it, the bookmark goes with it.
+CREATE TABLE saved_stories (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    story_id UUID NOT NULL REFERENCES stories(id) ON DELETE CASCADE,
+    saved_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, story_id)
+);
+
+CREATE INDEX idx_saved_stories_user ON saved_stories(user_id, saved_at DESC);
+CREATE INDEX idx_saved_stories_story ON saved_stories(story_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_messages_shared_story;
+ALTER TABLE messages DROP COLUMN IF EXISTS shared_story;
+ALTER TABLE messages DROP COLUMN IF EXISTS shared_story_id;
@@ -0,0 +1,9 @@
+-- A message can share a story. shared_story snapshots what the card shows
+-- (media, author, expiry) at share time, so the message still renders once
+-- the story is gone; shared_story_id has no foreign key for the same reason
+-- and is what share counts are taken from. The snapshot isn't encrypted with
+-- the message content: it holds nothing the story didn't already show.
+ALTER TABLE messages ADD COLUMN shared_story_id UUID;
+ALTER TABLE messages ADD COLUMN shared_story JSONB;
+
+CREATE INDEX idx_messages_shared_story ON messages(shared_story_id, created_at) WHERE shared_story_id IS NOT NULL;
@@ -0,0 +1,18 @@
+-- The activity stream: low-signal events about a user (profile views, saves
+-- of their stories) that are listed in the app but never pushed, keeping
+-- notifications for things that need attention.
+CREATE TABLE activities (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    actor_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(30) NOT NULL,
+    target_id UUID,
+    read_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (user_id <> actor_id)
+);
+
+CREATE INDEX idx_activities_user ON activities(user_id, created_at DESC);
+CREATE INDEX idx_activities_unread ON activities(user_id) WHERE read_at IS NULL;
+CREATE INDEX idx_activities_actor ON activities(actor_id);
+CREATE INDEX idx_activities_created_at ON activities(created_at);
@@ -0,0 +1,19 @@
+-- Per-recipient delivery state of a message, one row for every other member
+-- of the chat when it was sent. messages is partitioned, so there is no
+-- foreign key to it; rows are purged with the messages' retention instead.
+-- Messages from before this table only have messages.read_at.
+CREATE TABLE message_receipts (
+    message_id UUID NOT NULL,
+    chat_id UUID NOT NULL REFERENCES chats(id) ON DELETE CASCADE,
+    sender_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    delivered_at TIMESTAMPTZ,
+    read_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (message_id, user_id)
+);
+
+CREATE INDEX idx_message_receipts_unread ON message_receipts(chat_id, user_id) WHERE read_at IS NULL;
+CREATE INDEX idx_message_receipts_user ON message_receipts(user_id);
+CREATE INDEX idx_message_receipts_sender ON message_receipts(sender_id);
+CREATE INDEX idx_message_receipts_created_at ON message_receipts(created_at);
@@ -0,0 +1,6 @@
+-- Each member's own settings for a chat. muted_until is 'infinity' for a
+-- chat muted until it is unmuted.
+ALTER TABLE chat_participants
+    ADD COLUMN muted_until TIMESTAMPTZ,
+    ADD COLUMN archived BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN pinned BOOLEAN NOT NULL DEFAULT FALSE;
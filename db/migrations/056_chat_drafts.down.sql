@@ -0,0 +1,5 @@
+ALTER TABLE chat_participants
+    DROP COLUMN IF EXISTS draft_updated_at,
+    DROP COLUMN IF EXISTS draft_key_version,
+    DROP COLUMN IF EXISTS draft_ciphertext,
+    DROP COLUMN IF EXISTS draft;
@@ -0,0 +1,7 @@
+-- Each member's unsent draft for a chat, synced across their devices. Like
+-- message content, only the ciphertext is stored with encryption enabled.
+ALTER TABLE chat_participants
+    ADD COLUMN draft TEXT,
+    ADD COLUMN draft_ciphertext BYTEA,
+    ADD COLUMN draft_key_version INT,
+    ADD COLUMN draft_updated_at TIMESTAMPTZ;
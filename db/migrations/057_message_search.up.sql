@@ -0,0 +1,3 @@
+-- Full-text search within a chat. Matches by word, without stemming, as
+-- chats mix languages. Encrypted messages have no content to index.
+CREATE INDEX idx_messages_content_search ON messages USING GIN (to_tsvector('simple', COALESCE(content, '')));
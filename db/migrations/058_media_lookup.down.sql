@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_chats_avatar_name;
+DROP INDEX IF EXISTS idx_users_avatar_name;
+DROP INDEX IF EXISTS idx_story_archive_media_name;
+DROP INDEX IF EXISTS idx_stories_media_name;
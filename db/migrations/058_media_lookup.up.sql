@@ -0,0 +1,5 @@
+-- /uploads looks files up by name to decide who may fetch them
+CREATE INDEX idx_stories_media_name ON stories ((substring(media_url from '[^/]+$')));
+CREATE INDEX idx_story_archive_media_name ON story_archive ((substring(media_url from '[^/]+$')));
+CREATE INDEX idx_users_avatar_name ON users ((substring(avatar_url from '[^/]+$'))) WHERE avatar_url IS NOT NULL;
+CREATE INDEX idx_chats_avatar_name ON chats ((substring(avatar_url from '[^/]+$'))) WHERE avatar_url IS NOT NULL;
@@ -0,0 +1,20 @@
+-- Archives of their own data users ask for. A user has at most one export
+-- waiting or being built; a built archive is kept until expires_at.
+CREATE TABLE data_exports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'running', 'ready', 'failed')),
+    file_url TEXT,
+    size_bytes BIGINT,
+    requested_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_data_exports_user ON data_exports(user_id, requested_at DESC);
+CREATE UNIQUE INDEX idx_data_exports_active ON data_exports(user_id) WHERE status IN ('pending', 'running');
+CREATE INDEX idx_data_exports_queue ON data_exports(requested_at) WHERE status IN ('pending', 'running');
+CREATE INDEX idx_data_exports_expires ON data_exports(expires_at) WHERE expires_at IS NOT NULL;
+CREATE INDEX idx_data_exports_file_name ON data_exports ((substring(file_url from '[^/]+$'))) WHERE file_url IS NOT NULL;
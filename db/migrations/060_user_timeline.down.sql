@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_moderation_actions_owner;
+DROP INDEX IF EXISTS idx_reports_reporter;
+DROP INDEX IF EXISTS idx_stories_user_created;
+DROP INDEX IF EXISTS idx_messages_sender_created;
@@ -0,0 +1,5 @@
+-- The admin user timeline reads each of these newest first per user
+CREATE INDEX idx_messages_sender_created ON messages(sender_id, created_at DESC);
+CREATE INDEX idx_stories_user_created ON stories(user_id, created_at DESC);
+CREATE INDEX idx_reports_reporter ON reports(reporter_id, created_at DESC);
+CREATE INDEX idx_moderation_actions_owner ON moderation_actions(owner_id, created_at DESC) WHERE owner_id IS NOT NULL;
@@ -0,0 +1,13 @@
+-- One row per run of a background job, so operators can see when each job
+-- last ran, how long it took, how much it did and why it failed
+CREATE TABLE job_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    job VARCHAR(50) NOT NULL,
+    started_at TIMESTAMPTZ NOT NULL,
+    duration_ms BIGINT NOT NULL,
+    rows_affected BIGINT NOT NULL DEFAULT 0,
+    error TEXT
+);
+
+CREATE INDEX idx_job_runs_job ON job_runs(job, started_at DESC);
+CREATE INDEX idx_job_runs_started ON job_runs(started_at);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_messages_media_name;
+ALTER TABLE messages DROP COLUMN IF EXISTS media_size_bytes;
+ALTER TABLE messages DROP COLUMN IF EXISTS media_duration_ms;
+ALTER TABLE messages DROP COLUMN IF EXISTS media_type;
+ALTER TABLE messages DROP COLUMN IF EXISTS media_url;
@@ -0,0 +1,11 @@
+-- A message can carry a voice note. The audio is stored like other uploads
+-- and, like shared_story, isn't encrypted with the message content; its
+-- duration is read from the file when it is uploaded.
+ALTER TABLE messages ADD COLUMN media_url TEXT;
+ALTER TABLE messages ADD COLUMN media_type VARCHAR(20)
+    CHECK (media_type IN ('audio/mp4', 'audio/ogg'));
+ALTER TABLE messages ADD COLUMN media_duration_ms INTEGER;
+ALTER TABLE messages ADD COLUMN media_size_bytes BIGINT;
+
+-- /uploads looks voice notes up by name, as in migration 058
+CREATE INDEX idx_messages_media_name ON messages ((substring(media_url from '[^/]+$'))) WHERE media_url IS NOT NULL;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_messages_unwiped;
+DROP INDEX IF EXISTS idx_chats_message_retention;
+ALTER TABLE messages DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE chat_participants DROP COLUMN IF EXISTS cleared_at;
+ALTER TABLE chats DROP COLUMN IF EXISTS message_retention_seconds;
@@ -0,0 +1,15 @@
+-- A member can clear a chat's history for themselves (cleared_at hides what
+-- came before it), and a chat can expire its messages after
+-- message_retention_seconds. Messages cleared for everyone or expired are
+-- tombstoned rather than deleted: deleted_at is set and their content and
+-- media are wiped, unless someone in the chat is on legal hold, in which case
+-- the wipe waits for the hold to be released.
+ALTER TABLE chats ADD COLUMN message_retention_seconds INTEGER
+    CHECK (message_retention_seconds > 0);
+ALTER TABLE chat_participants ADD COLUMN cleared_at TIMESTAMPTZ;
+ALTER TABLE messages ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX idx_chats_message_retention ON chats(id) WHERE message_retention_seconds IS NOT NULL;
+CREATE INDEX idx_messages_unwiped ON messages(chat_id)
+    WHERE deleted_at IS NOT NULL
+    AND (content <> '' OR content_ciphertext IS NOT NULL OR shared_story_id IS NOT NULL OR media_url IS NOT NULL);
@@ -0,0 +1,12 @@
+-- Ranked story lists for the busiest nearby feed areas, rebuilt every minute
+-- so hot areas are served without running the distance query. An area is a
+-- geohash cell and a radius bucket in meters; story_ids are in feed order.
+CREATE TABLE nearby_feeds (
+    cell TEXT NOT NULL,
+    radius INTEGER NOT NULL,
+    story_ids UUID[] NOT NULL,
+    built_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (cell, radius)
+);
+
+CREATE INDEX idx_nearby_feeds_built ON nearby_feeds(built_at);
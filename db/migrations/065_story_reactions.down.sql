@@ -0,0 +1,2 @@
+ALTER TABLE messages DROP COLUMN IF EXISTS story_reply;
+DROP TABLE IF EXISTS story_reactions;
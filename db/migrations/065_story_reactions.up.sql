@@ -0,0 +1,16 @@
+-- Viewers can react to a story with an emoji, one reaction each, which they
+-- can change or take back. Reactions go when their story is deleted.
+CREATE TABLE story_reactions (
+    story_id UUID NOT NULL REFERENCES stories(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    emoji TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (story_id, user_id)
+);
+
+CREATE INDEX idx_story_reactions_user ON story_reactions(user_id);
+
+-- A reply to a story is a message in the 1:1 chat with its author, linked to
+-- the story through shared_story_id and its snapshot like a share
+ALTER TABLE messages ADD COLUMN story_reply BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/middleware"
+	"github.com/locolive/backend/pkg/response"
+	"go.uber.org/zap"
+)
+
+// ActivityHandler serves the user's activity stream
+type ActivityHandler struct {
+	service *domain.ActivityService
+	logger  *zap.Logger
+}
+
+// NewActivityHandler creates a new activity handler
+func NewActivityHandler(service *domain.ActivityService, logger *zap.Logger) *ActivityHandler {
+	return &ActivityHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetActivity handles GET /me/activity
+func (h *ActivityHandler) GetActivity(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	limit, offset := listParams(r, domain.ActivityPageLimits)
+
+	activities, err := h.service.GetActivities(r.Context(), userID, limit, offset)
+	if err != nil {
+		h.logger.Error("failed to get activity", zap.Error(err))
+		response.InternalError(w, "failed to get activity")
+		return
+	}
+
+	response.List(w, activities, response.Meta{Limit: limit, Offset: offset})
+}
+
+// GetUnreadCount handles GET /me/activity/unread-count
+func (h *ActivityHandler) GetUnreadCount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	count, err := h.service.CountUnread(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("failed to count unread activity", zap.Error(err))
+		response.InternalError(w, "failed to count unread activity")
+		return
+	}
+
+	response.OK(w, map[string]int{"unread": count})
+}
+
+// MarkRead handles POST /me/activity/read, marking the whole stream read
+func (h *ActivityHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	read, err := h.service.MarkAllRead(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("failed to mark activity read", zap.Error(err))
+		response.InternalError(w, "failed to mark activity read")
+		return
+	}
+
+	response.OK(w, map[string]int{"read": read})
+}
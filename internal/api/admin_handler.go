@@ -0,0 +1,563 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/middleware"
+	"github.com/locolive/backend/pkg/response"
+	"go.uber.org/zap"
+)
+
+// AdminHandler handles operator-only endpoints
+type AdminHandler struct {
+	storyService      *domain.StoryService
+	campaignService   *domain.CampaignService
+	moderationService *domain.ModerationService
+	adminService      *domain.AdminService
+	logger            *zap.Logger
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(storyService *domain.StoryService, campaignService *domain.CampaignService, moderationService *domain.ModerationService, adminService *domain.AdminService, logger *zap.Logger) *AdminHandler {
+	return &AdminHandler{
+		storyService:      storyService,
+		campaignService:   campaignService,
+		moderationService: moderationService,
+		adminService:      adminService,
+		logger:            logger,
+	}
+}
+
+// GetArchivedStory returns the archived metadata of an expired story
+func (h *AdminHandler) GetArchivedStory(w http.ResponseWriter, r *http.Request) {
+	storyID, err := uuid.Parse(chi.URLParam(r, "storyId"))
+	if err != nil {
+		response.BadRequest(w, "invalid story id")
+		return
+	}
+
+	story, err := h.storyService.GetArchivedStory(r.Context(), storyID)
+	if err != nil {
+		if err == domain.ErrStoryNotFound {
+			response.NotFound(w, "archived story not found")
+			return
+		}
+		h.logger.Error("get archived story failed", zap.Error(err))
+		response.InternalError(w, "failed to get archived story")
+		return
+	}
+
+	response.OK(w, story)
+}
+
+// ListArchivedStories returns a user's archived stories
+func (h *AdminHandler) ListArchivedStories(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.URL.Query().Get("user_id"))
+	if err != nil {
+		response.BadRequest(w, "user_id is required")
+		return
+	}
+
+	limit, offset := listParams(r, domain.AdminPageLimits)
+
+	stories, err := h.storyService.GetArchivedStories(r.Context(), userID, limit, offset)
+	if err != nil {
+		h.logger.Error("list archived stories failed", zap.Error(err))
+		response.InternalError(w, "failed to list archived stories")
+		return
+	}
+
+	response.List(w, stories, response.Meta{Limit: limit, Offset: offset})
+}
+
+// GetCampaignStats returns lifecycle campaign performance over the last ?days= (default 30)
+func (h *AdminHandler) GetCampaignStats(w http.ResponseWriter, r *http.Request) {
+	days, _ := strconv.Atoi(r.URL.Query().Get("days"))
+	if days <= 0 {
+		days = 30
+	}
+
+	stats, err := h.campaignService.GetStats(r.Context(), time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		h.logger.Error("get campaign stats failed", zap.Error(err))
+		response.InternalError(w, "failed to get campaign stats")
+		return
+	}
+
+	response.OK(w, stats)
+}
+
+// ListJobs returns the last run of each background job
+func (h *AdminHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	runs, err := h.adminService.GetJobs(r.Context())
+	if err != nil {
+		h.logger.Error("list jobs failed", zap.Error(err))
+		response.InternalError(w, "failed to list jobs")
+		return
+	}
+
+	response.OK(w, runs)
+}
+
+// ListJobRuns returns the runs of the job in the URL, newest first
+func (h *AdminHandler) ListJobRuns(w http.ResponseWriter, r *http.Request) {
+	limit, offset := listParams(r, domain.AdminPageLimits)
+
+	runs, err := h.adminService.GetJobRuns(r.Context(), chi.URLParam(r, "job"), limit, offset)
+	if err != nil {
+		h.logger.Error("list job runs failed", zap.Error(err))
+		response.InternalError(w, "failed to list job runs")
+		return
+	}
+
+	response.List(w, runs, response.Meta{Limit: limit, Offset: offset})
+}
+
+// ListModerationActions returns automatic takedowns with ?status= (default pending)
+func (h *AdminHandler) ListModerationActions(w http.ResponseWriter, r *http.Request) {
+	status := domain.ModerationStatus(r.URL.Query().Get("status"))
+	switch status {
+	case "":
+		status = domain.ModerationPending
+	case domain.ModerationPending, domain.ModerationUpheld, domain.ModerationReversed:
+	default:
+		response.BadRequest(w, "status must be pending, upheld or reversed")
+		return
+	}
+
+	limit, offset := listParams(r, domain.AdminPageLimits)
+
+	actions, err := h.moderationService.GetActions(r.Context(), status, limit, offset)
+	if err != nil {
+		h.logger.Error("list moderation actions failed", zap.Error(err))
+		response.InternalError(w, "failed to list moderation actions")
+		return
+	}
+
+	response.List(w, actions, response.Meta{Limit: limit, Offset: offset})
+}
+
+// UpholdModerationAction keeps a takedown in place after review
+func (h *AdminHandler) UpholdModerationAction(w http.ResponseWriter, r *http.Request) {
+	h.resolveModerationAction(w, r, true)
+}
+
+// ReverseModerationAction restores content taken down by reports
+func (h *AdminHandler) ReverseModerationAction(w http.ResponseWriter, r *http.Request) {
+	h.resolveModerationAction(w, r, false)
+}
+
+func (h *AdminHandler) resolveModerationAction(w http.ResponseWriter, r *http.Request, uphold bool) {
+	reviewerID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	actionID, err := uuid.Parse(chi.URLParam(r, "actionId"))
+	if err != nil {
+		response.BadRequest(w, "invalid action id")
+		return
+	}
+
+	action, err := h.moderationService.ResolveAction(r.Context(), actionID, reviewerID, uphold)
+	if err != nil {
+		switch err {
+		case domain.ErrModerationActionNotFound:
+			response.NotFound(w, err.Error())
+		case domain.ErrModerationActionResolved:
+			response.Conflict(w, err.Error())
+		default:
+			h.logger.Error("resolve moderation action failed", zap.Error(err))
+			response.InternalError(w, "failed to resolve moderation action")
+		}
+		return
+	}
+
+	response.OK(w, action)
+}
+
+// LookupUser finds a user by ?email=, ?phone= or ?username=
+func (h *AdminHandler) LookupUser(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	q := r.URL.Query()
+	user, err := h.adminService.LookupUser(r.Context(), adminID, domain.UserLookup{
+		Email:    q.Get("email"),
+		Phone:    q.Get("phone"),
+		Username: q.Get("username"),
+	})
+	if err != nil {
+		h.writeAdminError(w, "lookup user", err)
+		return
+	}
+
+	response.OK(w, user)
+}
+
+// GetUser returns a user with their role and status
+func (h *AdminHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		response.BadRequest(w, "invalid user id")
+		return
+	}
+
+	user, err := h.adminService.GetUser(r.Context(), adminID, userID)
+	if err != nil {
+		h.writeAdminError(w, "get user", err)
+		return
+	}
+
+	response.OK(w, user)
+}
+
+// DeactivateUser disables an account and ends its sessions
+func (h *AdminHandler) DeactivateUser(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		response.BadRequest(w, "invalid user id")
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	if err := h.adminService.DeactivateUser(r.Context(), adminID, userID, req.Reason); err != nil {
+		h.writeAdminError(w, "deactivate user", err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// ReactivateUser re-enables a deactivated account
+func (h *AdminHandler) ReactivateUser(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		response.BadRequest(w, "invalid user id")
+		return
+	}
+
+	if err := h.adminService.ReactivateUser(r.Context(), adminID, userID); err != nil {
+		h.writeAdminError(w, "reactivate user", err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// SetUserRole grants or revokes the moderator and admin roles
+func (h *AdminHandler) SetUserRole(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		response.BadRequest(w, "invalid user id")
+		return
+	}
+
+	var req struct {
+		Role domain.Role `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	if err := h.adminService.SetRole(r.Context(), adminID, userID, req.Role); err != nil {
+		h.writeAdminError(w, "set user role", err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// TakedownStory hides a story and records an upheld moderation action
+func (h *AdminHandler) TakedownStory(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	storyID, err := uuid.Parse(chi.URLParam(r, "storyId"))
+	if err != nil {
+		response.BadRequest(w, "invalid story id")
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	action, err := h.adminService.TakedownStory(r.Context(), adminID, storyID, req.Reason)
+	if err != nil {
+		h.writeAdminError(w, "takedown story", err)
+		return
+	}
+
+	response.OK(w, action)
+}
+
+// Broadcast sends a notification to every active user in the background
+func (h *AdminHandler) Broadcast(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	var req struct {
+		Title string `json:"title"`
+		Body  string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	if err := h.adminService.Broadcast(r.Context(), adminID, req.Title, req.Body); err != nil {
+		h.writeAdminError(w, "broadcast", err)
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, map[string]string{"status": "queued"})
+}
+
+// GetSecurityEvents returns a user's sign-ins and account changes
+func (h *AdminHandler) GetSecurityEvents(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		response.BadRequest(w, "invalid user id")
+		return
+	}
+
+	limit, offset := listParams(r, domain.AdminPageLimits)
+
+	events, err := h.adminService.GetSecurityEvents(r.Context(), adminID, userID, limit, offset)
+	if err != nil {
+		h.writeAdminError(w, "get security events", err)
+		return
+	}
+
+	response.List(w, events, response.Meta{Limit: limit, Offset: offset})
+}
+
+// GetUserTimeline returns a user's activity and enforcement history in one
+// chronological list
+func (h *AdminHandler) GetUserTimeline(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		response.BadRequest(w, "invalid user id")
+		return
+	}
+
+	limit, offset := listParams(r, domain.AdminPageLimits)
+
+	entries, err := h.adminService.GetUserTimeline(r.Context(), adminID, userID, limit, offset)
+	if err != nil {
+		h.writeAdminError(w, "get user timeline", err)
+		return
+	}
+
+	response.List(w, entries, response.Meta{Limit: limit, Offset: offset})
+}
+
+// MergeAccounts folds the account in the URL into target_user_id
+func (h *AdminHandler) MergeAccounts(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	sourceID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		response.BadRequest(w, "invalid user id")
+		return
+	}
+
+	var req struct {
+		TargetUserID uuid.UUID `json:"target_user_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.TargetUserID == uuid.Nil {
+		response.BadRequest(w, "target_user_id is required")
+		return
+	}
+
+	result, err := h.adminService.MergeAccounts(r.Context(), adminID, sourceID, req.TargetUserID)
+	if err != nil {
+		h.writeAdminError(w, "merge accounts", err)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// ListVerificationRequests returns badge requests with ?status= (default pending)
+func (h *AdminHandler) ListVerificationRequests(w http.ResponseWriter, r *http.Request) {
+	status := domain.VerificationStatus(r.URL.Query().Get("status"))
+	switch status {
+	case "":
+		status = domain.VerificationPending
+	case domain.VerificationPending, domain.VerificationApproved, domain.VerificationRejected:
+	default:
+		response.BadRequest(w, "status must be pending, approved or rejected")
+		return
+	}
+
+	limit, offset := listParams(r, domain.AdminPageLimits)
+
+	requests, err := h.adminService.GetVerificationRequests(r.Context(), status, limit, offset)
+	if err != nil {
+		h.writeAdminError(w, "list verification requests", err)
+		return
+	}
+
+	response.List(w, requests, response.Meta{Limit: limit, Offset: offset})
+}
+
+// ApproveVerification grants the verified badge
+func (h *AdminHandler) ApproveVerification(w http.ResponseWriter, r *http.Request) {
+	h.resolveVerification(w, r, true)
+}
+
+// RejectVerification turns a badge request down
+func (h *AdminHandler) RejectVerification(w http.ResponseWriter, r *http.Request) {
+	h.resolveVerification(w, r, false)
+}
+
+func (h *AdminHandler) resolveVerification(w http.ResponseWriter, r *http.Request, approve bool) {
+	adminID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	requestID, err := uuid.Parse(chi.URLParam(r, "requestId"))
+	if err != nil {
+		response.BadRequest(w, "invalid request id")
+		return
+	}
+
+	// The note is optional, so an empty body is fine
+	var req struct {
+		Note string `json:"note"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.BadRequest(w, "invalid request body")
+			return
+		}
+	}
+
+	verification, err := h.adminService.ResolveVerification(r.Context(), adminID, requestID, approve, req.Note)
+	if err != nil {
+		h.writeAdminError(w, "resolve verification request", err)
+		return
+	}
+
+	response.OK(w, verification)
+}
+
+// RevokeVerification removes a user's verified badge
+func (h *AdminHandler) RevokeVerification(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		response.BadRequest(w, "invalid user id")
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	if err := h.adminService.RevokeVerification(r.Context(), adminID, userID, req.Reason); err != nil {
+		h.writeAdminError(w, "revoke verification", err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+func (h *AdminHandler) writeAdminError(w http.ResponseWriter, op string, err error) {
+	switch err {
+	case domain.ErrUserNotFound:
+		response.NotFound(w, "user not found")
+	case domain.ErrStoryNotFound:
+		response.NotFound(w, "story not found")
+	case domain.ErrVerificationRequestNotFound:
+		response.NotFound(w, err.Error())
+	case domain.ErrAlreadyTakenDown, domain.ErrUserOnLegalHold, domain.ErrVerificationRequestResolved, domain.ErrNotVerified:
+		response.Conflict(w, err.Error())
+	case domain.ErrInvalidRole, domain.ErrCannotChangeOwn, domain.ErrLookupKeyRequired, domain.ErrInvalidBroadcast, domain.ErrMergeSameUser:
+		response.BadRequest(w, err.Error())
+	default:
+		h.logger.Error(op+" failed", zap.Error(err))
+		response.InternalError(w, "failed to "+op)
+	}
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/middleware"
+	"github.com/locolive/backend/pkg/response"
+	"go.uber.org/zap"
+)
+
+// AppConfigHandler serves client startup configuration and its admin endpoints
+type AppConfigHandler struct {
+	service *domain.AppConfigService
+	logger  *zap.Logger
+}
+
+// NewAppConfigHandler creates a new app config handler
+func NewAppConfigHandler(service *domain.AppConfigService, logger *zap.Logger) *AppConfigHandler {
+	return &AppConfigHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Get returns the minimum supported app version, feature flags for the
+// caller's region, kill switches and remote config for the caller's platform
+// (X-Platform or ?platform=). It stays reachable for blocked clients so they
+// can learn that they must upgrade. Responses carry an ETag; a matching
+// If-None-Match gets a 304.
+func (h *AppConfigHandler) Get(w http.ResponseWriter, r *http.Request) {
+	platform, err := requestPlatform(r)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	version := middleware.AppVersion(r)
+	if version == middleware.UnknownAppVersion {
+		version = ""
+	}
+
+	config, err := h.service.GetConfig(r.Context(), version, platform)
+	if err != nil {
+		h.logger.Error("get app config failed", zap.Error(err))
+		response.InternalError(w, "failed to get app config")
+		return
+	}
+
+	body, err := json.Marshal(config)
+	if err != nil {
+		h.logger.Error("encode app config failed", zap.Error(err))
+		response.InternalError(w, "failed to get app config")
+		return
+	}
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	// Feature flags depend on the caller's region, so only the client may cache
+	w.Header().Set("Cache-Control", "private, no-cache")
+	w.Header().Set("ETag", etag)
+	w.Header().Add("Vary", middleware.PlatformHeader)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	response.OK(w, json.RawMessage(body))
+}
+
+// requestPlatform reads the client platform from ?platform= or X-Platform
+func requestPlatform(r *http.Request) (domain.Platform, error) {
+	name := r.URL.Query().Get("platform")
+	if name == "" {
+		name = r.Header.Get(middleware.PlatformHeader)
+	}
+	return domain.ParsePlatform(strings.ToLower(strings.TrimSpace(name)))
+}
+
+// etagMatches reports whether an If-None-Match header matches etag
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// ListRemoteConfig returns every remote config entry and the current version
+func (h *AppConfigHandler) ListRemoteConfig(w http.ResponseWriter, r *http.Request) {
+	entries, version, err := h.service.ListRemoteConfig(r.Context())
+	if err != nil {
+		h.logger.Error("list remote config failed", zap.Error(err))
+		response.InternalError(w, "failed to list remote config")
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"version": version,
+		"entries": entries,
+	})
+}
+
+// SetRemoteConfig creates or replaces a value; ?platform= scopes it to one platform
+func (h *AppConfigHandler) SetRemoteConfig(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	platform, err := domain.ParsePlatform(r.URL.Query().Get("platform"))
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	var req struct {
+		Value       json.RawMessage `json:"value"`
+		Description *string         `json:"description"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	entry, err := h.service.SetRemoteConfig(r.Context(), adminID, chi.URLParam(r, "key"), platform, req.Value, req.Description)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidRemoteConfigKey, domain.ErrInvalidPlatform, domain.ErrInvalidRemoteConfig:
+			response.BadRequest(w, err.Error())
+		default:
+			h.logger.Error("set remote config failed", zap.Error(err))
+			response.InternalError(w, "failed to set remote config")
+		}
+		return
+	}
+
+	response.OK(w, entry)
+}
+
+// DeleteRemoteConfig removes a value; ?platform= selects a platform override
+func (h *AppConfigHandler) DeleteRemoteConfig(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	platform, err := domain.ParsePlatform(r.URL.Query().Get("platform"))
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	if err := h.service.DeleteRemoteConfig(r.Context(), adminID, chi.URLParam(r, "key"), platform); err != nil {
+		if err == domain.ErrRemoteConfigNotFound {
+			response.NotFound(w, err.Error())
+			return
+		}
+		h.logger.Error("delete remote config failed", zap.Error(err))
+		response.InternalError(w, "failed to delete remote config")
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// GetRemoteConfigHistory lists past changes, optionally for ?key=
+func (h *AppConfigHandler) GetRemoteConfigHistory(w http.ResponseWriter, r *http.Request) {
+	limit, offset := listParams(r, domain.AdminPageLimits)
+
+	changes, err := h.service.GetRemoteConfigHistory(r.Context(), r.URL.Query().Get("key"), limit, offset)
+	if err != nil {
+		h.logger.Error("get remote config history failed", zap.Error(err))
+		response.InternalError(w, "failed to get remote config history")
+		return
+	}
+
+	response.List(w, changes, response.Meta{Limit: limit, Offset: offset})
+}
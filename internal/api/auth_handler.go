@@ -4,11 +4,14 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/locolive/backend/internal/auth"
+	"github.com/locolive/backend/internal/captcha"
 	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/metrics"
 	"github.com/locolive/backend/internal/middleware"
 	"github.com/locolive/backend/pkg/response"
 	"github.com/locolive/backend/pkg/validator"
@@ -19,15 +22,23 @@ import (
 type AuthHandler struct {
 	authService *domain.AuthService
 	authRepo    domain.AuthRepository
-	logger      *zap.Logger
+	captcha     captcha.Verifier
+	// captchaFailOpen lets requests through while the captcha provider is down
+	captchaFailOpen    bool
+	captchaUnavailable *metrics.CounterVec
+	logger             *zap.Logger
 }
 
-// NewAuthHandler creates a new auth handler
-func NewAuthHandler(authService *domain.AuthService, authRepo domain.AuthRepository, logger *zap.Logger) *AuthHandler {
+// NewAuthHandler creates a new auth handler. captchaVerifier may be nil to
+// turn captchas off.
+func NewAuthHandler(authService *domain.AuthService, authRepo domain.AuthRepository, captchaVerifier captcha.Verifier, captchaFailOpen bool, registry *metrics.Registry, logger *zap.Logger) *AuthHandler {
 	return &AuthHandler{
-		authService: authService,
-		authRepo:    authRepo,
-		logger:      logger,
+		authService:        authService,
+		authRepo:           authRepo,
+		captcha:            captchaVerifier,
+		captchaFailOpen:    captchaFailOpen,
+		captchaUnavailable: registry.Counter("captcha_unavailable_total", "Captcha checks the provider couldn't answer, by whether the request was let through", "decision"),
+		logger:             logger,
 	}
 }
 
@@ -37,12 +48,16 @@ type RegisterRequest struct {
 	Password string `json:"password"`
 	Name     string `json:"name"`
 	Phone    string `json:"phone,omitempty"`
+	// CaptchaToken is required when captchas are enabled
+	CaptchaToken string `json:"captcha_token,omitempty"`
 }
 
 // LoginRequest represents the login request body
 type LoginRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	// CaptchaToken is required after repeated failed logins
+	CaptchaToken string `json:"captcha_token,omitempty"`
 }
 
 // RefreshRequest represents the token refresh request body
@@ -60,6 +75,15 @@ type GoogleLoginRequest struct {
 	IDToken string `json:"id_token"`
 }
 
+// AppleLoginRequest carries an Apple identity token. Nonce is the raw value
+// whose SHA-256 the app passed to Apple, and Name is what Apple returned to
+// the app on first authorization.
+type AppleLoginRequest struct {
+	IDToken string `json:"id_token"`
+	Nonce   string `json:"nonce"`
+	Name    string `json:"name"`
+}
+
 // Register handles user registration
 func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	var req RegisterRequest
@@ -81,20 +105,21 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate name
-	req.Name = validator.SanitizeString(req.Name, 100)
-	if !validator.ValidateName(req.Name) {
-		response.BadRequest(w, "name must be 2-100 characters")
+	if !h.checkCaptcha(w, r, req.CaptchaToken) {
 		return
 	}
 
 	// Register user
 	result, err := h.authService.Register(r.Context(), req.Email, req.Password, req.Name)
 	if err != nil {
-		if err == domain.ErrUserAlreadyExists {
+		if errors.Is(err, domain.ErrUserAlreadyExists) {
 			response.Conflict(w, "user with this email already exists")
 			return
 		}
+		if errors.Is(err, domain.ErrInvalidName) {
+			response.BadRequest(w, err.Error())
+			return
+		}
 		h.logger.Error("registration failed", zap.Error(err))
 		response.InternalError(w, "registration failed")
 		return
@@ -123,29 +148,35 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get user with password hash for verification
-	user, err := h.authRepo.GetUserByEmail(r.Context(), req.Email)
-	if err != nil {
-		response.Unauthorized(w, "invalid email or password")
-		return
+	if h.captcha != nil {
+		needed, err := h.authService.LoginNeedsCaptcha(r.Context(), req.Email, middleware.ClientIP(r))
+		if err != nil {
+			// Without the failure counts, assume the worst rather than drop
+			// the brute-force check
+			h.logger.Warn("failed to check whether login needs a captcha; requiring one", zap.Error(err))
+			needed = true
+		}
+		if needed && !h.checkCaptcha(w, r, req.CaptchaToken) {
+			return
+		}
 	}
 
-	// Verify password - we need to get the hash from DB
-	// The repository will handle password verification
-	result, err := h.authService.Login(r.Context(), req.Email, req.Password)
+	result, err := h.authService.Login(r.Context(), req.Email, req.Password, middleware.ClientIP(r))
 	if err != nil {
-		if err == domain.ErrInvalidCredentials {
+		switch err {
+		case domain.ErrInvalidCredentials:
 			response.Unauthorized(w, "invalid email or password")
-			return
+		case domain.ErrAccountLocked:
+			response.Error(w, http.StatusLocked, "ACCOUNT_LOCKED", "account is temporarily locked after too many failed logins; try again later or reset your password")
+		case domain.ErrTooManyLoginAttempts:
+			response.TooManyRequests(w, "too many failed login attempts, try again later")
+		default:
+			h.logger.Error("login failed", zap.Error(err), zap.String("email", req.Email))
+			response.InternalError(w, "login failed")
 		}
-		h.logger.Error("login failed", zap.Error(err), zap.String("email", req.Email))
-		response.InternalError(w, "login failed")
 		return
 	}
 
-	// Suppress unused variable warning
-	_ = user
-
 	response.OK(w, result)
 }
 
@@ -218,6 +249,131 @@ func (h *AuthHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
 	response.NoContent(w)
 }
 
+// GetSessions lists the current user's signed-in devices
+func (h *AuthHandler) GetSessions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+	sessionID, _ := middleware.GetSessionID(r.Context())
+
+	sessions, err := h.authService.GetSessions(r.Context(), userID, sessionID)
+	if err != nil {
+		h.logger.Error("get sessions failed", zap.Error(err))
+		response.InternalError(w, "failed to get sessions")
+		return
+	}
+
+	response.OK(w, sessions)
+}
+
+// GetSecurityEvents returns the current user's recent sign-ins and account
+// changes, newest first
+func (h *AuthHandler) GetSecurityEvents(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	limit, offset := listParams(r, domain.SecurityEventPageLimits)
+
+	events, err := h.authService.GetSecurityEvents(r.Context(), userID, limit, offset)
+	if err != nil {
+		h.logger.Error("get security events failed", zap.Error(err))
+		response.InternalError(w, "failed to get security events")
+		return
+	}
+
+	response.List(w, events, response.Meta{Limit: limit, Offset: offset})
+}
+
+type verificationRequest struct {
+	Category string `json:"category"`
+	Details  string `json:"details"`
+}
+
+// RequestVerification applies for the verified badge
+func (h *AuthHandler) RequestVerification(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	var req verificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	verification, err := h.authService.RequestVerification(r.Context(), userID, req.Category, req.Details)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidVerificationRequest:
+			response.BadRequest(w, err.Error())
+		case domain.ErrVerificationRequestPending, domain.ErrAlreadyVerified:
+			response.Conflict(w, err.Error())
+		default:
+			h.logger.Error("request verification failed", zap.Error(err))
+			response.InternalError(w, "failed to request verification")
+		}
+		return
+	}
+
+	response.Created(w, verification)
+}
+
+// GetVerification returns the current user's latest badge request
+func (h *AuthHandler) GetVerification(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	verification, err := h.authService.GetVerificationRequest(r.Context(), userID)
+	if err != nil {
+		if err == domain.ErrVerificationRequestNotFound {
+			response.NotFound(w, err.Error())
+			return
+		}
+		h.logger.Error("get verification failed", zap.Error(err))
+		response.InternalError(w, "failed to get verification request")
+		return
+	}
+
+	response.OK(w, verification)
+}
+
+// RevokeSession signs out one of the current user's devices
+func (h *AuthHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	sessionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.BadRequest(w, "invalid session id")
+		return
+	}
+
+	if err := h.authService.RevokeSession(r.Context(), userID, sessionID); err != nil {
+		if err == domain.ErrSessionNotFound {
+			response.NotFound(w, err.Error())
+			return
+		}
+		h.logger.Error("revoke session failed", zap.Error(err))
+		response.InternalError(w, "failed to revoke session")
+		return
+	}
+
+	response.NoContent(w)
+}
+
 // GoogleLogin handles Google OAuth token exchange
 func (h *AuthHandler) GoogleLogin(w http.ResponseWriter, r *http.Request) {
 	var req GoogleLoginRequest
@@ -241,6 +397,15 @@ func (h *AuthHandler) GoogleLogin(w http.ResponseWriter, r *http.Request) {
 			response.BadRequest(w, "email not available from Google account")
 			return
 		}
+		if errors.Is(err, domain.ErrGoogleLinkRequired) {
+			response.Error(w, http.StatusConflict, "ACCOUNT_LINK_REQUIRED",
+				"an account with this email already exists; sign in with your password and link Google from your settings")
+			return
+		}
+		if errors.Is(err, domain.ErrUserAlreadyExists) {
+			response.Conflict(w, "user with this email already exists")
+			return
+		}
 		h.logger.Error("Google login failed", zap.Error(err))
 		response.InternalError(w, "Google login failed")
 		return
@@ -249,6 +414,132 @@ func (h *AuthHandler) GoogleLogin(w http.ResponseWriter, r *http.Request) {
 	response.OK(w, result)
 }
 
+// LinkGoogle links a Google account to the authenticated user
+func (h *AuthHandler) LinkGoogle(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	var req GoogleLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	if req.IDToken == "" {
+		response.BadRequest(w, "id_token is required")
+		return
+	}
+
+	user, err := h.authService.LinkGoogle(r.Context(), userID, req.IDToken)
+	if err != nil {
+		if err == auth.ErrInvalidGoogleToken {
+			response.Unauthorized(w, "invalid Google token")
+			return
+		}
+		if err == auth.ErrGoogleEmailMissing {
+			response.BadRequest(w, "email not available from Google account")
+			return
+		}
+		if errors.Is(err, domain.ErrGoogleAccountAlreadyLinked) {
+			response.Conflict(w, "Google account is already linked to another user")
+			return
+		}
+		if err == domain.ErrUserNotFound {
+			response.NotFound(w, "user not found")
+			return
+		}
+		h.logger.Error("Google link failed", zap.Error(err))
+		response.InternalError(w, "Google link failed")
+		return
+	}
+
+	response.OK(w, user)
+}
+
+// AppleLogin handles Sign in with Apple token exchange
+func (h *AuthHandler) AppleLogin(w http.ResponseWriter, r *http.Request) {
+	var req AppleLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	if req.IDToken == "" {
+		response.BadRequest(w, "id_token is required")
+		return
+	}
+
+	result, err := h.authService.AppleLogin(r.Context(), req.IDToken, req.Nonce, req.Name)
+	if err != nil {
+		if err == auth.ErrInvalidAppleToken {
+			response.Unauthorized(w, "invalid Apple token")
+			return
+		}
+		if err == auth.ErrAppleEmailMissing {
+			response.BadRequest(w, "email not available from Apple account")
+			return
+		}
+		if errors.Is(err, domain.ErrAppleLinkRequired) {
+			response.Error(w, http.StatusConflict, "ACCOUNT_LINK_REQUIRED",
+				"an account with this email already exists; sign in with your password and link Apple from your settings")
+			return
+		}
+		if errors.Is(err, domain.ErrUserAlreadyExists) {
+			response.Conflict(w, "user with this email already exists")
+			return
+		}
+		h.logger.Error("Apple login failed", zap.Error(err))
+		response.InternalError(w, "Apple login failed")
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// LinkApple links an Apple account to the authenticated user
+func (h *AuthHandler) LinkApple(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	var req AppleLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	if req.IDToken == "" {
+		response.BadRequest(w, "id_token is required")
+		return
+	}
+
+	user, err := h.authService.LinkApple(r.Context(), userID, req.IDToken, req.Nonce)
+	if err != nil {
+		if err == auth.ErrInvalidAppleToken {
+			response.Unauthorized(w, "invalid Apple token")
+			return
+		}
+		if err == auth.ErrAppleEmailMissing {
+			response.BadRequest(w, "email not available from Apple account")
+			return
+		}
+		if errors.Is(err, domain.ErrAppleAccountAlreadyLinked) {
+			response.Conflict(w, "Apple account is already linked to another user")
+			return
+		}
+		h.logger.Error("Apple link failed", zap.Error(err))
+		response.InternalError(w, "Apple link failed")
+		return
+	}
+
+	response.OK(w, user)
+}
+
 // Me returns the current authenticated user
 func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserID(r.Context())
@@ -271,9 +562,32 @@ func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
 	response.OK(w, user.ToResponse())
 }
 
+// DeleteAccount deletes the current user's account
+func (h *AuthHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	if err := h.authService.DeleteAccount(r.Context(), userID); err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			response.NotFound(w, "user not found")
+			return
+		}
+		h.logger.Error("delete account failed", zap.Error(err))
+		response.InternalError(w, "failed to delete account")
+		return
+	}
+
+	response.NoContent(w)
+}
+
 // ForgotPasswordRequest represents forgot password request
 type ForgotPasswordRequest struct {
 	Email string `json:"email"`
+	// CaptchaToken is required when captchas are enabled
+	CaptchaToken string `json:"captcha_token,omitempty"`
 }
 
 // ForgotPassword initiates password reset flow
@@ -290,23 +604,20 @@ func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := h.authService.InitiatePasswordReset(r.Context(), req.Email)
-	if err != nil {
-		if err == domain.ErrUserNotFound {
-			// Don't reveal if user exists - security best practice
-			response.OK(w, map[string]string{"message": "If the email exists, a reset link has been sent"})
-			return
-		}
+	if !h.checkCaptcha(w, r, req.CaptchaToken) {
+		return
+	}
+
+	// Unknown addresses get the same response, so it doesn't reveal whether
+	// an account exists
+	err := h.authService.InitiatePasswordReset(r.Context(), req.Email)
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
 		h.logger.Error("forgot password failed", zap.Error(err))
 		response.InternalError(w, "failed to process request")
 		return
 	}
 
-	// In production, send email with token. For now, return token (dev only)
-	response.OK(w, map[string]string{
-		"message": "Password reset initiated",
-		"token":   token, // Remove in production - send via email instead
-	})
+	response.OK(w, map[string]string{"message": "If the email exists, a reset link has been sent"})
 }
 
 // ResetPasswordRequest represents password reset request
@@ -347,6 +658,181 @@ func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 	response.OK(w, map[string]string{"message": "Password reset successfully"})
 }
 
+// VerifyEmailRequest represents an email verification request
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// VerifyEmail confirms the address a verification email was sent to
+func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	if req.Token == "" {
+		response.BadRequest(w, "token is required")
+		return
+	}
+
+	err := h.authService.VerifyEmail(r.Context(), req.Token)
+	if err != nil {
+		if err == domain.ErrInvalidToken || err == domain.ErrTokenExpired {
+			response.BadRequest(w, "invalid or expired token")
+			return
+		}
+		h.logger.Error("verify email failed", zap.Error(err))
+		response.InternalError(w, "failed to verify email")
+		return
+	}
+
+	response.OK(w, map[string]string{"message": "Email verified"})
+}
+
+// ResendVerification sends a new verification email to the current user
+func (h *AuthHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	err := h.authService.ResendVerification(r.Context(), userID)
+	if err != nil {
+		switch err {
+		case domain.ErrEmailAlreadyVerified:
+			response.Conflict(w, err.Error())
+		case domain.ErrNoEmailToVerify:
+			response.BadRequest(w, err.Error())
+		case domain.ErrVerificationRecentlySent:
+			response.TooManyRequests(w, err.Error())
+		case domain.ErrUserNotFound:
+			response.NotFound(w, err.Error())
+		default:
+			h.logger.Error("resend verification failed", zap.Error(err))
+			response.InternalError(w, "failed to send verification email")
+		}
+		return
+	}
+
+	response.OK(w, map[string]string{"message": "Verification email sent"})
+}
+
+// OTPRequest represents a request for a phone code
+type OTPRequest struct {
+	Phone string `json:"phone"`
+	// CaptchaToken is required when captchas are enabled
+	CaptchaToken string `json:"captcha_token,omitempty"`
+}
+
+// RequestOTP texts a one-time login code to a phone number
+func (h *AuthHandler) RequestOTP(w http.ResponseWriter, r *http.Request) {
+	var req OTPRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	if !h.checkCaptcha(w, r, req.CaptchaToken) {
+		return
+	}
+
+	err := h.authService.RequestPhoneOTP(r.Context(), req.Phone)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidPhone:
+			response.BadRequest(w, err.Error())
+		case domain.ErrOTPRecentlySent, domain.ErrOTPLimitReached:
+			response.TooManyRequests(w, err.Error())
+		default:
+			h.logger.Error("request otp failed", zap.Error(err))
+			response.InternalError(w, "failed to send code")
+		}
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"message":    "Code sent",
+		"expires_in": int(domain.OTPTTL.Seconds()),
+	})
+}
+
+// OTPVerifyRequest represents a phone code check
+type OTPVerifyRequest struct {
+	Phone string `json:"phone"`
+	Code  string `json:"code"`
+	Name  string `json:"name,omitempty"`
+}
+
+// VerifyOTP signs in with a phone code, registering new numbers
+func (h *AuthHandler) VerifyOTP(w http.ResponseWriter, r *http.Request) {
+	var req OTPVerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	if req.Code == "" {
+		response.BadRequest(w, "code is required")
+		return
+	}
+
+	result, err := h.authService.PhoneLogin(r.Context(), req.Phone, req.Code, req.Name)
+	if err != nil {
+		h.handleOTPError(w, "phone login", err)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// VerifyPhone adds a phone number to the current user's account with a code sent to it
+func (h *AuthHandler) VerifyPhone(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	var req OTPVerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	if req.Code == "" {
+		response.BadRequest(w, "code is required")
+		return
+	}
+
+	user, err := h.authService.VerifyPhone(r.Context(), userID, req.Phone, req.Code)
+	if err != nil {
+		h.handleOTPError(w, "verify phone", err)
+		return
+	}
+
+	response.OK(w, user)
+}
+
+func (h *AuthHandler) handleOTPError(w http.ResponseWriter, op string, err error) {
+	switch {
+	case err == domain.ErrInvalidPhone, err == domain.ErrInvalidOTP, errors.Is(err, domain.ErrInvalidName):
+		response.BadRequest(w, err.Error())
+	case err == domain.ErrPhoneSignupNeedsName:
+		response.Error(w, http.StatusBadRequest, "NAME_REQUIRED", err.Error())
+	case err == domain.ErrOTPAttemptsExceeded:
+		response.TooManyRequests(w, err.Error())
+	case err == domain.ErrPhoneAlreadyVerified, errors.Is(err, domain.ErrUserAlreadyExists):
+		response.Conflict(w, err.Error())
+	case err == domain.ErrUserNotFound:
+		response.NotFound(w, err.Error())
+	default:
+		h.logger.Error(op+" failed", zap.Error(err))
+		response.InternalError(w, "failed to "+op)
+	}
+}
+
 // UpdatePasswordRequest represents password update request
 type UpdatePasswordRequest struct {
 	CurrentPassword string `json:"current_password"`
@@ -418,7 +904,7 @@ func (h *AuthHandler) UpdateEmail(w http.ResponseWriter, r *http.Request) {
 			response.BadRequest(w, "password is incorrect")
 			return
 		}
-		if err == domain.ErrUserAlreadyExists {
+		if errors.Is(err, domain.ErrUserAlreadyExists) {
 			response.BadRequest(w, "email already in use")
 			return
 		}
@@ -446,6 +932,15 @@ func (h *AuthHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 
 	user, err := h.authService.UpdateProfile(r.Context(), userID, req)
 	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidCountryCode), errors.Is(err, domain.ErrInvalidUsername), errors.Is(err, domain.ErrReservedUsername),
+			errors.Is(err, domain.ErrInvalidVisibility), errors.Is(err, domain.ErrInvalidName), errors.Is(err, domain.ErrBioTooLong):
+			response.BadRequest(w, err.Error())
+			return
+		case errors.Is(err, domain.ErrUsernameTaken):
+			response.Conflict(w, "username is already taken")
+			return
+		}
 		h.logger.Error("update profile failed", zap.Error(err))
 		response.InternalError(w, "failed to update profile")
 		return
@@ -454,21 +949,88 @@ func (h *AuthHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 	response.OK(w, user)
 }
 
-// GetProfile handles getting a user profile by ID
-func (h *AuthHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
-	userIDStr := chi.URLParam(r, "userId")
-	userID, err := uuid.Parse(userIDStr)
+// CheckUsername reports whether ?u= is a valid, unclaimed username
+func (h *AuthHandler) CheckUsername(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	username := r.URL.Query().Get("u")
+	if username == "" {
+		response.BadRequest(w, "u is required")
+		return
+	}
+
+	result, err := h.authService.CheckUsername(r.Context(), userID, username)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidUsername) {
+			response.BadRequest(w, err.Error())
+			return
+		}
+		h.logger.Error("check username failed", zap.Error(err))
+		response.InternalError(w, "failed to check username")
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// SearchUsers finds users by name, username or bio (?q=), optionally in one
+// country (?country=)
+func (h *AuthHandler) SearchUsers(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	q := r.URL.Query()
+	page, limit, offset := pageParams(r, domain.UserSearchPageLimits)
+
+	results, err := h.authService.SearchUsers(r.Context(), domain.UserSearchParams{
+		ViewerID:    userID,
+		Query:       q.Get("q"),
+		CountryCode: q.Get("country"),
+		Limit:       limit,
+		Offset:      offset,
+	})
 	if err != nil {
-		response.BadRequest(w, "invalid user id")
+		if errors.Is(err, domain.ErrInvalidSearchQuery) || errors.Is(err, domain.ErrInvalidCountryCode) {
+			response.BadRequest(w, err.Error())
+			return
+		}
+		h.logger.Error("search users failed", zap.Error(err))
+		response.InternalError(w, "failed to search users")
 		return
 	}
 
-	// Just use GetUserByID from repository directly via service if exposed,
-	// or create a service method if needed.
-	// Since AuthService has repo, we can use it.
-	// But AuthService usually encapsulates logic.
-	// Let's add GetUser to AuthService.
-	user, err := h.authService.GetUser(r.Context(), userID)
+	response.Paginated(w, results, response.Page{Number: page, Limit: limit, HasMore: len(results) == limit})
+}
+
+// GetProfile handles getting a user profile by ID, or by handle as /users/@handle.
+// Private profiles are limited unless the caller is connected to the user.
+func (h *AuthHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	viewerID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+	userIDStr := chi.URLParam(r, "userId")
+
+	var user *domain.UserResponse
+	var err error
+	if handle, ok := strings.CutPrefix(userIDStr, "@"); ok {
+		user, err = h.authService.GetUserByUsername(r.Context(), viewerID, handle)
+	} else {
+		userID, parseErr := uuid.Parse(userIDStr)
+		if parseErr != nil {
+			response.BadRequest(w, "invalid user id")
+			return
+		}
+		user, err = h.authService.GetUser(r.Context(), viewerID, userID)
+	}
 	if err != nil {
 		if errors.Is(err, domain.ErrUserNotFound) {
 			response.NotFound(w, "user not found")
@@ -481,3 +1043,30 @@ func (h *AuthHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 
 	response.OK(w, user)
 }
+
+// checkCaptcha verifies the request's captcha token, answering
+// 400 CAPTCHA_REQUIRED and returning false if it wasn't solved. Requests go
+// through when captchas are off. If the provider can't be reached they are
+// rejected with 503 CAPTCHA_UNAVAILABLE, unless the handler fails open.
+func (h *AuthHandler) checkCaptcha(w http.ResponseWriter, r *http.Request, token string) bool {
+	if h.captcha == nil {
+		return true
+	}
+	ok, err := h.captcha.Verify(r.Context(), token, middleware.ClientIP(r))
+	if err != nil {
+		if h.captchaFailOpen {
+			h.captchaUnavailable.Inc("allowed")
+			h.logger.Warn("captcha verification unavailable, letting request through", zap.Error(err))
+			return true
+		}
+		h.captchaUnavailable.Inc("rejected")
+		h.logger.Error("captcha verification unavailable", zap.Error(err))
+		response.Error(w, http.StatusServiceUnavailable, "CAPTCHA_UNAVAILABLE", "captcha verification is unavailable, try again shortly")
+		return false
+	}
+	if !ok {
+		response.Error(w, http.StatusBadRequest, "CAPTCHA_REQUIRED", "complete the captcha and try again")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/middleware"
+	"github.com/locolive/backend/pkg/response"
+	"go.uber.org/zap"
+)
+
+// CampaignHandler manages a user's lifecycle message preferences
+type CampaignHandler struct {
+	service *domain.CampaignService
+	logger  *zap.Logger
+}
+
+// NewCampaignHandler creates a new campaign preference handler
+func NewCampaignHandler(service *domain.CampaignService, logger *zap.Logger) *CampaignHandler {
+	return &CampaignHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetPreferences lists the lifecycle campaigns and the user's opt-outs
+func (h *CampaignHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	prefs, err := h.service.GetPreferences(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("failed to get campaign preferences", zap.Error(err))
+		response.InternalError(w, "failed to fetch preferences")
+		return
+	}
+
+	response.OK(w, prefs)
+}
+
+// SetOptOutRequest represents a campaign opt-out change
+type SetOptOutRequest struct {
+	OptedOut bool `json:"opted_out"`
+}
+
+// SetOptOut opts the user in or out of a campaign
+func (h *CampaignHandler) SetOptOut(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	var req SetOptOutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	campaign := domain.CampaignID(chi.URLParam(r, "campaign"))
+	if err := h.service.SetOptOut(r.Context(), userID, campaign, req.OptedOut); err != nil {
+		if err == domain.ErrUnknownCampaign {
+			response.NotFound(w, "unknown campaign")
+			return
+		}
+		h.logger.Error("failed to update campaign opt-out", zap.Error(err))
+		response.InternalError(w, "failed to update preferences")
+		return
+	}
+
+	response.OK(w, domain.CampaignPreference{Campaign: campaign, OptedOut: req.OptedOut})
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/metrics"
+	"go.uber.org/zap"
+)
+
+type fakeVerifier struct {
+	ok  bool
+	err error
+}
+
+func (v fakeVerifier) Verify(ctx context.Context, token, remoteIP string) (bool, error) {
+	return v.ok, v.err
+}
+
+func TestCheckCaptcha(t *testing.T) {
+	outage := errors.New("provider unreachable")
+	tests := []struct {
+		name     string
+		verifier fakeVerifier
+		failOpen bool
+		pass     bool
+		status   int
+		metric   string
+	}{
+		{"solved", fakeVerifier{ok: true}, false, true, http.StatusOK, ""},
+		{"not solved", fakeVerifier{ok: false}, false, false, http.StatusBadRequest, ""},
+		{"outage fails closed", fakeVerifier{err: outage}, false, false, http.StatusServiceUnavailable, `decision="rejected"`},
+		{"outage fails open when configured", fakeVerifier{err: outage}, true, true, http.StatusOK, `decision="allowed"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := metrics.NewRegistry()
+			h := NewAuthHandler(nil, nil, tt.verifier, tt.failOpen, registry, zap.NewNop())
+
+			rec := httptest.NewRecorder()
+			pass := h.checkCaptcha(rec, httptest.NewRequest(http.MethodPost, "/auth/register", nil), "token")
+			if pass != tt.pass || rec.Code != tt.status {
+				t.Fatalf("got pass %v status %d, want %v %d", pass, rec.Code, tt.pass, tt.status)
+			}
+
+			var out strings.Builder
+			if err := registry.Write(&out); err != nil {
+				t.Fatalf("write metrics: %v", err)
+			}
+			counted := strings.Contains(out.String(), "captcha_unavailable_total{"+tt.metric+"} 1")
+			if tt.metric != "" && !counted {
+				t.Fatalf("metric %s not counted in:\n%s", tt.metric, out.String())
+			}
+		})
+	}
+}
+
+// failingAttempts can't count login failures
+type failingAttempts struct {
+	domain.AuthRepository
+}
+
+func (failingAttempts) CountFailedLoginsForEmail(ctx context.Context, email string, since time.Time) (int, error) {
+	return 0, errors.New("database unavailable")
+}
+
+func TestLoginRequiresCaptchaWhenFailuresCantBeCounted(t *testing.T) {
+	logger := zap.NewNop()
+	authService := domain.NewAuthService(failingAttempts{}, nil, nil, nil, nil, nil, nil, domain.EmailLinkSettings{}, nil,
+		domain.LockoutPolicy{CaptchaAfter: 3, IPWindow: time.Hour}, domain.SessionPolicy{}, domain.TextPolicy{}, nil, nil, logger)
+	h := NewAuthHandler(authService, nil, fakeVerifier{ok: false}, false, metrics.NewRegistry(), logger)
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{"email":"asha@example.com","password":"secret"}`))
+	rec := httptest.NewRecorder()
+	h.Login(rec, req)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "CAPTCHA_REQUIRED") {
+		t.Fatalf("got %d %s, want CAPTCHA_REQUIRED", rec.Code, rec.Body.String())
+	}
+}
+
+func TestRequestOTPRequiresCaptcha(t *testing.T) {
+	h := NewAuthHandler(nil, nil, fakeVerifier{ok: false}, false, metrics.NewRegistry(), zap.NewNop())
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/otp/request", strings.NewReader(`{"phone":"+14155550123"}`))
+	rec := httptest.NewRecorder()
+	h.RequestOTP(rec, req)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "CAPTCHA_REQUIRED") {
+		t.Fatalf("got %d %s, want CAPTCHA_REQUIRED", rec.Code, rec.Body.String())
+	}
+}
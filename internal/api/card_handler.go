@@ -0,0 +1,143 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/middleware"
+	"github.com/locolive/backend/pkg/response"
+	"go.uber.org/zap"
+)
+
+// CardHandler serves onboarding and announcement cards
+type CardHandler struct {
+	service *domain.CardService
+	logger  *zap.Logger
+}
+
+// NewCardHandler creates a new card handler
+func NewCardHandler(service *domain.CardService, logger *zap.Logger) *CardHandler {
+	return &CardHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetCards returns the cards to show the user, in display order
+func (h *CardHandler) GetCards(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	platform, err := requestPlatform(r)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	cards, err := h.service.GetCards(r.Context(), userID, platform)
+	if err != nil {
+		h.logger.Error("get cards failed", zap.Error(err))
+		response.InternalError(w, "failed to get cards")
+		return
+	}
+
+	response.OK(w, cards)
+}
+
+// DismissCard hides a card for the user
+func (h *CardHandler) DismissCard(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	cardID, err := uuid.Parse(chi.URLParam(r, "cardId"))
+	if err != nil {
+		response.BadRequest(w, "invalid card id")
+		return
+	}
+
+	if err := h.service.DismissCard(r.Context(), userID, cardID); err != nil {
+		if err == domain.ErrCardNotFound {
+			response.NotFound(w, err.Error())
+			return
+		}
+		h.logger.Error("dismiss card failed", zap.Error(err))
+		response.InternalError(w, "failed to dismiss card")
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// CreateCard publishes a card
+func (h *CardHandler) CreateCard(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	var params domain.CreateCardParams
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+	params.CreatedBy = adminID
+
+	card, err := h.service.CreateCard(r.Context(), params)
+	if err != nil {
+		if err == domain.ErrInvalidCard {
+			response.BadRequest(w, "kind, segment, platform, title (max 100), body and a valid schedule are required")
+			return
+		}
+		h.logger.Error("create card failed", zap.Error(err))
+		response.InternalError(w, "failed to create card")
+		return
+	}
+
+	response.Created(w, card)
+}
+
+// ListCards lists cards; ?active=false includes deactivated ones
+func (h *CardHandler) ListCards(w http.ResponseWriter, r *http.Request) {
+	activeOnly := r.URL.Query().Get("active") != "false"
+	limit, offset := listParams(r, domain.AdminPageLimits)
+
+	cards, err := h.service.ListCards(r.Context(), activeOnly, limit, offset)
+	if err != nil {
+		h.logger.Error("list cards failed", zap.Error(err))
+		response.InternalError(w, "failed to list cards")
+		return
+	}
+
+	response.List(w, cards, response.Meta{Limit: limit, Offset: offset})
+}
+
+// DeactivateCard stops serving a card
+func (h *CardHandler) DeactivateCard(w http.ResponseWriter, r *http.Request) {
+	cardID, err := uuid.Parse(chi.URLParam(r, "cardId"))
+	if err != nil {
+		response.BadRequest(w, "invalid card id")
+		return
+	}
+
+	if err := h.service.DeactivateCard(r.Context(), cardID); err != nil {
+		if err == domain.ErrCardNotFound {
+			response.NotFound(w, err.Error())
+			return
+		}
+		h.logger.Error("deactivate card failed", zap.Error(err))
+		response.InternalError(w, "failed to deactivate card")
+		return
+	}
+
+	response.NoContent(w)
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/pkg/response"
+	"go.uber.org/zap"
+)
+
+// SaveDraftRequest is the draft text; blank clears it
+type SaveDraftRequest struct {
+	Content string `json:"content"`
+}
+
+// SaveDraft handles PUT /chats/{chatId}/draft. The user's other devices get
+// the draft as a draft_updated event.
+func (h *ChatHandler) SaveDraft(w http.ResponseWriter, r *http.Request) {
+	userID, chatID, ok := h.chatRequestParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req SaveDraftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	draft, err := h.chatService.SaveDraft(r.Context(), chatID, userID, req.Content)
+	if err != nil {
+		if !h.writeChatRequestError(w, err) {
+			h.logger.Error("failed to save draft", zap.Error(err))
+			response.InternalError(w, "failed to save draft")
+		}
+		return
+	}
+
+	h.wsManager.SendToUser(userID, WSEvent{Type: "draft_updated", Payload: draft})
+	response.OK(w, draft)
+}
+
+// GetSuggestedReplies handles GET /chats/{chatId}/suggested-replies
+func (h *ChatHandler) GetSuggestedReplies(w http.ResponseWriter, r *http.Request) {
+	userID, chatID, ok := h.chatRequestParams(w, r)
+	if !ok {
+		return
+	}
+
+	replies, err := h.chatService.SuggestReplies(r.Context(), chatID, userID)
+	if err != nil {
+		if !h.writeChatRequestError(w, err) {
+			h.logger.Error("failed to suggest replies", zap.Error(err))
+			response.InternalError(w, "failed to suggest replies")
+		}
+		return
+	}
+
+	response.OK(w, map[string][]string{"replies": replies})
+}
+
+// writeChatRequestError writes the response for errors common to chat
+// requests, reporting whether it did
+func (h *ChatHandler) writeChatRequestError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, domain.ErrMessageTooLong):
+		response.BadRequest(w, err.Error())
+	case errors.Is(err, domain.ErrChatNotFound):
+		response.NotFound(w, err.Error())
+	case errors.Is(err, domain.ErrNotChatParticipant):
+		response.Forbidden(w, err.Error())
+	default:
+		return false
+	}
+	return true
+}
@@ -2,27 +2,37 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
-	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/locolive/backend/internal/domain"
 	"github.com/locolive/backend/internal/middleware"
+	"github.com/locolive/backend/internal/ratelimit"
 	"github.com/locolive/backend/pkg/response"
 	"go.uber.org/zap"
 )
 
+// voiceNoteFormOverhead is how much a voice note request may exceed the
+// file size cap by, for the multipart headers and boundaries
+const voiceNoteFormOverhead = 64 << 10
+
 type ChatHandler struct {
 	chatService *domain.ChatService
 	wsManager   *WebSocketManager
+	// rateLimiter limits messages sent over the WebSocket; nil when rate
+	// limiting is off
+	rateLimiter *ratelimit.Limiter
 	logger      *zap.Logger
 }
 
-func NewChatHandler(chatService *domain.ChatService, wsManager *WebSocketManager, logger *zap.Logger) *ChatHandler {
+func NewChatHandler(chatService *domain.ChatService, wsManager *WebSocketManager, rateLimiter *ratelimit.Limiter, logger *zap.Logger) *ChatHandler {
 	return &ChatHandler{
 		chatService: chatService,
 		wsManager:   wsManager,
+		rateLimiter: rateLimiter,
 		logger:      logger,
 	}
 }
@@ -44,10 +54,12 @@ func (h *ChatHandler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 	}
 
 	client := &Client{
-		ID:     uuid.New(),
-		Conn:   conn,
-		Send:   make(chan []byte, 256),
-		UserID: userID,
+		ID:          uuid.New(),
+		Conn:        conn,
+		Send:        make(chan []byte, 256),
+		UserID:      userID,
+		chatService: h.chatService,
+		rateLimiter: h.rateLimiter,
 	}
 
 	h.wsManager.register <- client
@@ -80,6 +92,17 @@ func (h *ChatHandler) CreateChat(w http.ResponseWriter, r *http.Request) {
 
 	chat, err := h.chatService.CreateChat(r.Context(), userID, targetID)
 	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrCannotChatSelf):
+			response.BadRequest(w, err.Error())
+			return
+		case errors.Is(err, domain.ErrChatTargetNotFound):
+			response.NotFound(w, err.Error())
+			return
+		case errors.Is(err, domain.ErrChatBlocked):
+			response.Forbidden(w, err.Error())
+			return
+		}
 		h.logger.Error("failed to create chat", zap.Error(err))
 		response.InternalError(w, "failed to create chat")
 		return
@@ -88,7 +111,8 @@ func (h *ChatHandler) CreateChat(w http.ResponseWriter, r *http.Request) {
 	response.OK(w, chat)
 }
 
-// GetChats returns list of user's chats
+// GetChats returns list of user's chats, pinned first. ?archived=true lists
+// the archived ones instead.
 func (h *ChatHandler) GetChats(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserID(r.Context())
 	if !ok {
@@ -96,7 +120,8 @@ func (h *ChatHandler) GetChats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	chats, err := h.chatService.GetUserChats(r.Context(), userID)
+	archived := r.URL.Query().Get("archived") == "true"
+	chats, err := h.chatService.GetUserChats(r.Context(), userID, archived)
 	if err != nil {
 		h.logger.Error("failed to get chats", zap.Error(err))
 		response.InternalError(w, "failed to get chats")
@@ -106,8 +131,43 @@ func (h *ChatHandler) GetChats(w http.ResponseWriter, r *http.Request) {
 	response.OK(w, chats)
 }
 
-// GetMessages returns messages for a chat
+// SearchMessages handles GET /chats/{chatId}/messages/search?q=, best
+// matches first
+func (h *ChatHandler) SearchMessages(w http.ResponseWriter, r *http.Request) {
+	userID, chatID, ok := h.chatRequestParams(w, r)
+	if !ok {
+		return
+	}
+
+	limit, offset := listParams(r, domain.MessageSearchPageLimits)
+	messages, err := h.chatService.SearchMessages(r.Context(), chatID, userID, r.URL.Query().Get("q"), limit, offset)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidMessageSearchQuery):
+			response.BadRequest(w, err.Error())
+		case errors.Is(err, domain.ErrMessageSearchUnavailable):
+			response.Error(w, http.StatusServiceUnavailable, "UNAVAILABLE", err.Error())
+		default:
+			if !h.writeChatRequestError(w, err) {
+				h.logger.Error("failed to search messages", zap.Error(err))
+				response.InternalError(w, "failed to search messages")
+			}
+		}
+		return
+	}
+
+	response.List(w, messages, response.Meta{Limit: limit, Offset: offset})
+}
+
+// GetMessages returns messages for a chat, newest first. ?before= and
+// ?after= take a message ID and page by cursor; otherwise ?page= is used.
 func (h *ChatHandler) GetMessages(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
 	chatIDStr := chi.URLParam(r, "chatId")
 	chatID, err := uuid.Parse(chatIDStr)
 	if err != nil {
@@ -115,21 +175,156 @@ func (h *ChatHandler) GetMessages(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-	if page < 1 {
-		page = 1
+	_, limit, offset := pageParams(r, domain.MessagePageLimits)
+	page := domain.MessagePage{Limit: limit, Offset: offset}
+	if page.Before, err = messageCursor(r, "before"); err != nil {
+		response.BadRequest(w, "invalid before cursor")
+		return
+	}
+	if page.After, err = messageCursor(r, "after"); err != nil {
+		response.BadRequest(w, "invalid after cursor")
+		return
+	}
+	if page.Before != nil && page.After != nil {
+		response.BadRequest(w, "use either before or after, not both")
+		return
+	}
+	if page.Before != nil || page.After != nil {
+		page.Offset = 0
 	}
-	offset := (page - 1) * limit
 
-	messages, err := h.chatService.GetMessages(r.Context(), chatID, limit, offset)
+	messages, next, err := h.chatService.GetMessages(r.Context(), chatID, userID, page)
 	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrChatNotFound):
+			response.NotFound(w, err.Error())
+			return
+		case errors.Is(err, domain.ErrNotChatParticipant):
+			response.Forbidden(w, err.Error())
+			return
+		case errors.Is(err, domain.ErrInvalidMessageCursor):
+			response.BadRequest(w, err.Error())
+			return
+		}
 		h.logger.Error("failed to get messages", zap.Error(err))
 		response.InternalError(w, "failed to get messages")
 		return
 	}
 
-	response.OK(w, messages)
+	meta := response.Meta{Limit: page.Limit, Offset: page.Offset}
+	if next != nil {
+		meta.NextCursor = next.String()
+	}
+	response.List(w, messages, meta)
+}
+
+// messageCursor reads a message ID cursor from the query, or nil if it is missing
+func messageCursor(r *http.Request, param string) (*uuid.UUID, error) {
+	v := r.URL.Query().Get(param)
+	if v == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(v)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// MarkRead marks the chat's messages to the user as read and tells their
+// senders with message_read and delivery_update events
+func (h *ChatHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	chatID, err := uuid.Parse(chi.URLParam(r, "chatId"))
+	if err != nil {
+		response.BadRequest(w, "invalid chat id")
+		return
+	}
+
+	receipts, err := h.chatService.MarkRead(r.Context(), chatID, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrChatNotFound):
+			response.NotFound(w, err.Error())
+			return
+		case errors.Is(err, domain.ErrNotChatParticipant):
+			response.Forbidden(w, err.Error())
+			return
+		}
+		h.logger.Error("failed to mark chat read", zap.Error(err))
+		response.InternalError(w, "failed to mark chat read")
+		return
+	}
+
+	read := 0
+	for _, receipt := range receipts {
+		read += len(receipt.MessageIDs)
+		h.wsManager.SendToUser(receipt.SenderID, WSEvent{
+			Type:    "message_read",
+			Payload: receipt,
+		})
+		h.wsManager.sendDeliveryUpdates([]*domain.DeliveryUpdate{receipt.DeliveryUpdate()})
+	}
+
+	response.OK(w, map[string]int{"read": read})
+}
+
+// MarkDeliveredRequest lists messages the app received, typically by push
+type MarkDeliveredRequest struct {
+	MessageIDs []uuid.UUID `json:"message_ids"`
+}
+
+// MarkDelivered records that messages in a chat reached the user's device
+// and tells their senders with delivery_update events
+func (h *ChatHandler) MarkDelivered(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	chatID, err := uuid.Parse(chi.URLParam(r, "chatId"))
+	if err != nil {
+		response.BadRequest(w, "invalid chat id")
+		return
+	}
+
+	var req MarkDeliveredRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	updates, err := h.chatService.MarkDelivered(r.Context(), chatID, userID, req.MessageIDs)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrTooManyDeliveredMessages):
+			response.BadRequest(w, fmt.Sprintf("at most %d message ids per request", domain.MaxDeliveredBatch))
+			return
+		case errors.Is(err, domain.ErrChatNotFound):
+			response.NotFound(w, err.Error())
+			return
+		case errors.Is(err, domain.ErrNotChatParticipant):
+			response.Forbidden(w, err.Error())
+			return
+		}
+		h.logger.Error("failed to mark messages delivered", zap.Error(err))
+		response.InternalError(w, "failed to mark messages delivered")
+		return
+	}
+
+	delivered := 0
+	for _, update := range updates {
+		delivered += len(update.MessageIDs)
+	}
+	h.wsManager.sendDeliveryUpdates(updates)
+
+	response.OK(w, map[string]int{"delivered": delivered})
 }
 
 // SendMessage sends a message to a chat (HTTP fallback + WebSocket broadcast)
@@ -157,23 +352,387 @@ func (h *ChatHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 
 	msg, err := h.chatService.SendMessage(r.Context(), chatID, userID, req.Content)
 	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrChatNotFound):
+			response.NotFound(w, err.Error())
+			return
+		case errors.Is(err, domain.ErrChatFrozen), errors.Is(err, domain.ErrNotChatParticipant):
+			response.Forbidden(w, err.Error())
+			return
+		case errors.Is(err, domain.ErrMessageTooLong), errors.Is(err, domain.ErrEmptyMessage):
+			response.BadRequest(w, err.Error())
+			return
+		}
 		h.logger.Error("failed to send message", zap.Error(err))
 		response.InternalError(w, "failed to send message")
 		return
 	}
 
-	// Broadcast via WebSocket
-	// 1. Get chat participants to know who to notify
-	chat, err := h.chatService.GetChat(r.Context(), chatID)
-	if err == nil {
-		event := WSEvent{
-			Type:    "new_message",
-			Payload: msg,
+	h.wsManager.broadcastMessage(r.Context(), h.chatService, msg, nil)
+
+	response.OK(w, msg)
+}
+
+// ShareStory shares a story into the chat as a story_share message
+func (h *ChatHandler) ShareStory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	chatID, err := uuid.Parse(chi.URLParam(r, "chatId"))
+	if err != nil {
+		response.BadRequest(w, "invalid chat id")
+		return
+	}
+
+	var req struct {
+		StoryID uuid.UUID `json:"story_id"`
+		Content string    `json:"content"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.StoryID == uuid.Nil {
+		response.BadRequest(w, "story_id is required")
+		return
+	}
+
+	msg, err := h.chatService.ShareStory(r.Context(), chatID, userID, req.StoryID, req.Content)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrChatNotFound), errors.Is(err, domain.ErrStoryNotFound):
+			response.NotFound(w, err.Error())
+			return
+		case errors.Is(err, domain.ErrChatFrozen), errors.Is(err, domain.ErrNotChatParticipant), errors.Is(err, domain.ErrStoryNotShareable):
+			response.Forbidden(w, err.Error())
+			return
+		case errors.Is(err, domain.ErrMessageTooLong):
+			response.BadRequest(w, err.Error())
+			return
+		}
+		h.logger.Error("failed to share story", zap.Error(err))
+		response.InternalError(w, "failed to share story")
+		return
+	}
+
+	h.wsManager.broadcastMessage(r.Context(), h.chatService, msg, nil)
+
+	response.OK(w, msg)
+}
+
+// StoryReplyResponse is the chat a story reply went to, which may have just
+// been opened, and the reply
+type StoryReplyResponse struct {
+	Chat    *domain.Chat    `json:"chat"`
+	Message *domain.Message `json:"message"`
+}
+
+// ReplyToStory handles POST /stories/{storyId}/replies, sending the reply to
+// the 1:1 chat with the story's author
+func (h *ChatHandler) ReplyToStory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	storyID, err := uuid.Parse(chi.URLParam(r, "storyId"))
+	if err != nil {
+		response.BadRequest(w, "invalid story id")
+		return
+	}
+
+	var req struct {
+		Content string `json:"content"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	chat, msg, err := h.chatService.ReplyToStory(r.Context(), userID, storyID, req.Content)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrStoryNotFound):
+			response.NotFound(w, err.Error())
+			return
+		case errors.Is(err, domain.ErrChatFrozen), errors.Is(err, domain.ErrChatBlocked):
+			response.Forbidden(w, err.Error())
+			return
+		case errors.Is(err, domain.ErrCannotReplyOwn), errors.Is(err, domain.ErrEmptyMessage), errors.Is(err, domain.ErrMessageTooLong):
+			response.BadRequest(w, err.Error())
+			return
+		}
+		h.logger.Error("failed to reply to story", zap.Error(err))
+		response.InternalError(w, "failed to reply to story")
+		return
+	}
+
+	h.wsManager.broadcastMessage(r.Context(), h.chatService, msg, nil)
+
+	response.Created(w, StoryReplyResponse{Chat: chat, Message: msg})
+}
+
+// SendVoiceNote posts an uploaded m4a or ogg file ("file") into the chat as
+// a voice message
+func (h *ChatHandler) SendVoiceNote(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	chatID, err := uuid.Parse(chi.URLParam(r, "chatId"))
+	if err != nil {
+		response.BadRequest(w, "invalid chat id")
+		return
+	}
+
+	// Leave room for the multipart framing around the file
+	r.Body = http.MaxBytesReader(w, r.Body, h.chatService.VoiceNotePolicy().MaxBytes+voiceNoteFormOverhead)
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.Error(w, http.StatusRequestEntityTooLarge, "VOICE_NOTE_TOO_LARGE", domain.ErrVoiceNoteTooLarge.Error())
+			return
 		}
-		for _, u := range chat.Users {
-			h.wsManager.SendToUser(u.ID, event)
+		response.BadRequest(w, "invalid form data")
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		response.BadRequest(w, "missing file")
+		return
+	}
+	defer file.Close()
+
+	msg, err := h.chatService.SendVoiceNote(r.Context(), chatID, userID, file, header.Size)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrChatNotFound):
+			response.NotFound(w, err.Error())
+			return
+		case errors.Is(err, domain.ErrChatFrozen), errors.Is(err, domain.ErrNotChatParticipant):
+			response.Forbidden(w, err.Error())
+			return
+		case errors.Is(err, domain.ErrVoiceNoteTooLarge):
+			response.Error(w, http.StatusRequestEntityTooLarge, "VOICE_NOTE_TOO_LARGE", err.Error())
+			return
+		case errors.Is(err, domain.ErrInvalidVoiceNote), errors.Is(err, domain.ErrVoiceNoteTooLong):
+			response.BadRequest(w, err.Error())
+			return
 		}
+		h.logger.Error("failed to send voice note", zap.Error(err))
+		response.InternalError(w, "failed to send voice note")
+		return
 	}
 
+	h.wsManager.broadcastMessage(r.Context(), h.chatService, msg, nil)
+
 	response.OK(w, msg)
 }
+
+// CreateGroupChat starts a group chat with some of the user's connections
+func (h *ChatHandler) CreateGroupChat(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	var params domain.CreateGroupChatParams
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		response.BadRequest(w, "invalid request")
+		return
+	}
+
+	chat, err := h.chatService.CreateGroupChat(r.Context(), userID, params)
+	if err != nil {
+		if h.writeGroupChatError(w, err) {
+			return
+		}
+		h.logger.Error("failed to create group chat", zap.Error(err))
+		response.InternalError(w, "failed to create group chat")
+		return
+	}
+
+	h.notifyChatUpdated(chat)
+	response.Created(w, chat)
+}
+
+// UpdateGroupChat renames a group or changes its avatar
+func (h *ChatHandler) UpdateGroupChat(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	chatID, err := uuid.Parse(chi.URLParam(r, "chatId"))
+	if err != nil {
+		response.BadRequest(w, "invalid chat id")
+		return
+	}
+
+	var params domain.UpdateGroupChatParams
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		response.BadRequest(w, "invalid request")
+		return
+	}
+
+	chat, err := h.chatService.UpdateGroupChat(r.Context(), chatID, userID, params)
+	if err != nil {
+		if h.writeGroupChatError(w, err) {
+			return
+		}
+		h.logger.Error("failed to update group chat", zap.Error(err))
+		response.InternalError(w, "failed to update group chat")
+		return
+	}
+
+	h.notifyChatUpdated(chat)
+	response.OK(w, chat)
+}
+
+// AddParticipants adds members to a group chat
+func (h *ChatHandler) AddParticipants(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	chatID, err := uuid.Parse(chi.URLParam(r, "chatId"))
+	if err != nil {
+		response.BadRequest(w, "invalid chat id")
+		return
+	}
+
+	var req struct {
+		UserIDs []uuid.UUID `json:"user_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request")
+		return
+	}
+
+	chat, err := h.chatService.AddParticipants(r.Context(), chatID, userID, req.UserIDs)
+	if err != nil {
+		if h.writeGroupChatError(w, err) {
+			return
+		}
+		h.logger.Error("failed to add chat participants", zap.Error(err))
+		response.InternalError(w, "failed to add participants")
+		return
+	}
+
+	h.notifyChatUpdated(chat)
+	response.OK(w, chat)
+}
+
+// RemoveParticipant removes a member from a group chat, or leaves it when
+// the user removes themselves
+func (h *ChatHandler) RemoveParticipant(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	chatID, err := uuid.Parse(chi.URLParam(r, "chatId"))
+	if err != nil {
+		response.BadRequest(w, "invalid chat id")
+		return
+	}
+	memberID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		response.BadRequest(w, "invalid user id")
+		return
+	}
+
+	chat, err := h.chatService.RemoveParticipant(r.Context(), chatID, userID, memberID)
+	if err != nil {
+		if h.writeGroupChatError(w, err) {
+			return
+		}
+		h.logger.Error("failed to remove chat participant", zap.Error(err))
+		response.InternalError(w, "failed to remove participant")
+		return
+	}
+
+	h.wsManager.SendToUser(memberID, WSEvent{
+		Type:    "chat_removed",
+		Payload: map[string]uuid.UUID{"chat_id": chatID},
+	})
+	if chat != nil {
+		h.notifyChatUpdated(chat)
+	}
+	response.OK(w, chat)
+}
+
+// SetParticipantRole makes a group member an admin or a plain member
+func (h *ChatHandler) SetParticipantRole(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	chatID, err := uuid.Parse(chi.URLParam(r, "chatId"))
+	if err != nil {
+		response.BadRequest(w, "invalid chat id")
+		return
+	}
+	memberID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		response.BadRequest(w, "invalid user id")
+		return
+	}
+
+	var req struct {
+		Role domain.ChatRole `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request")
+		return
+	}
+
+	chat, err := h.chatService.SetParticipantRole(r.Context(), chatID, userID, memberID, req.Role)
+	if err != nil {
+		if h.writeGroupChatError(w, err) {
+			return
+		}
+		h.logger.Error("failed to set chat participant role", zap.Error(err))
+		response.InternalError(w, "failed to set role")
+		return
+	}
+
+	h.notifyChatUpdated(chat)
+	response.OK(w, chat)
+}
+
+// notifyChatUpdated sends the chat to all its members as a chat_updated event
+func (h *ChatHandler) notifyChatUpdated(chat *domain.Chat) {
+	event := WSEvent{Type: "chat_updated", Payload: chat}
+	for _, u := range chat.Users {
+		h.wsManager.SendToUser(u.ID, event)
+	}
+}
+
+func (h *ChatHandler) writeGroupChatError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, domain.ErrInvalidName), errors.Is(err, domain.ErrInvalidChatAvatar),
+		errors.Is(err, domain.ErrInvalidChatRole), errors.Is(err, domain.ErrInvalidGroupMembers),
+		errors.Is(err, domain.ErrNotGroupChat):
+		response.BadRequest(w, err.Error())
+	case errors.Is(err, domain.ErrChatNotFound), errors.Is(err, domain.ErrChatMemberNotFound):
+		response.NotFound(w, err.Error())
+	case errors.Is(err, domain.ErrNotChatParticipant), errors.Is(err, domain.ErrNotChatAdmin):
+		response.Forbidden(w, err.Error())
+	case errors.Is(err, domain.ErrGroupChatFull), errors.Is(err, domain.ErrLastChatAdmin):
+		response.Conflict(w, err.Error())
+	default:
+		return false
+	}
+	return true
+}
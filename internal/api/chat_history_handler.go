@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/pkg/response"
+	"go.uber.org/zap"
+)
+
+// SetRetentionRequest is how long the chat keeps messages; 0 keeps them
+type SetRetentionRequest struct {
+	MessageRetentionSeconds int `json:"message_retention_seconds"`
+}
+
+// ClearHistory handles DELETE /chats/{chatId}/messages. ?for=me (the
+// default) hides the history from the user; ?for=everyone deletes it. Those
+// it was cleared for get a chat_cleared event.
+func (h *ChatHandler) ClearHistory(w http.ResponseWriter, r *http.Request) {
+	userID, chatID, ok := h.chatRequestParams(w, r)
+	if !ok {
+		return
+	}
+
+	scope := r.URL.Query().Get("for")
+	if scope == "" {
+		scope = domain.ClearForMe
+	}
+
+	chat, err := h.chatService.ClearHistory(r.Context(), chatID, userID, scope)
+	if err != nil {
+		if !h.writeChatHistoryError(w, err) {
+			h.logger.Error("failed to clear chat history", zap.Error(err))
+			response.InternalError(w, "failed to clear history")
+		}
+		return
+	}
+
+	event := WSEvent{Type: "chat_cleared", Payload: map[string]interface{}{"chat_id": chatID, "for": scope}}
+	if scope == domain.ClearForMe {
+		h.wsManager.SendToUser(userID, event)
+	} else {
+		for _, u := range chat.Users {
+			h.wsManager.SendToUser(u.ID, event)
+		}
+	}
+	response.NoContent(w)
+}
+
+// SetRetention handles PUT /chats/{chatId}/retention
+func (h *ChatHandler) SetRetention(w http.ResponseWriter, r *http.Request) {
+	userID, chatID, ok := h.chatRequestParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req SetRetentionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	retention := time.Duration(req.MessageRetentionSeconds) * time.Second
+	chat, err := h.chatService.SetMessageRetention(r.Context(), chatID, userID, retention)
+	if err != nil {
+		if !h.writeChatHistoryError(w, err) {
+			h.logger.Error("failed to set message retention", zap.Error(err))
+			response.InternalError(w, "failed to set retention")
+		}
+		return
+	}
+
+	h.notifyChatUpdated(chat)
+	response.OK(w, chat)
+}
+
+func (h *ChatHandler) writeChatHistoryError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, domain.ErrInvalidClearScope), errors.Is(err, domain.ErrInvalidRetention):
+		response.BadRequest(w, err.Error())
+	case errors.Is(err, domain.ErrChatNotFound):
+		response.NotFound(w, err.Error())
+	case errors.Is(err, domain.ErrNotChatParticipant), errors.Is(err, domain.ErrNotChatAdmin),
+		errors.Is(err, domain.ErrChatFrozen):
+		response.Forbidden(w, err.Error())
+	default:
+		return false
+	}
+	return true
+}
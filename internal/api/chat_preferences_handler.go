@@ -0,0 +1,120 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/middleware"
+	"github.com/locolive/backend/pkg/response"
+	"go.uber.org/zap"
+)
+
+// MuteChatRequest mutes a chat until MutedUntil, or until unmuted if it is left out
+type MuteChatRequest struct {
+	MutedUntil *time.Time `json:"muted_until"`
+}
+
+// MuteChat handles POST /chats/{chatId}/mute
+func (h *ChatHandler) MuteChat(w http.ResponseWriter, r *http.Request) {
+	userID, chatID, ok := h.chatRequestParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req MuteChatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	prefs, err := h.chatService.MuteChat(r.Context(), chatID, userID, req.MutedUntil)
+	h.writeChatPreferences(w, prefs, err)
+}
+
+// UnmuteChat handles DELETE /chats/{chatId}/mute
+func (h *ChatHandler) UnmuteChat(w http.ResponseWriter, r *http.Request) {
+	userID, chatID, ok := h.chatRequestParams(w, r)
+	if !ok {
+		return
+	}
+	prefs, err := h.chatService.UnmuteChat(r.Context(), chatID, userID)
+	h.writeChatPreferences(w, prefs, err)
+}
+
+// ArchiveChat handles POST /chats/{chatId}/archive
+func (h *ChatHandler) ArchiveChat(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, true)
+}
+
+// UnarchiveChat handles DELETE /chats/{chatId}/archive
+func (h *ChatHandler) UnarchiveChat(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, false)
+}
+
+func (h *ChatHandler) setArchived(w http.ResponseWriter, r *http.Request, archived bool) {
+	userID, chatID, ok := h.chatRequestParams(w, r)
+	if !ok {
+		return
+	}
+	prefs, err := h.chatService.SetChatArchived(r.Context(), chatID, userID, archived)
+	h.writeChatPreferences(w, prefs, err)
+}
+
+// PinChat handles POST /chats/{chatId}/pin
+func (h *ChatHandler) PinChat(w http.ResponseWriter, r *http.Request) {
+	h.setPinned(w, r, true)
+}
+
+// UnpinChat handles DELETE /chats/{chatId}/pin
+func (h *ChatHandler) UnpinChat(w http.ResponseWriter, r *http.Request) {
+	h.setPinned(w, r, false)
+}
+
+func (h *ChatHandler) setPinned(w http.ResponseWriter, r *http.Request, pinned bool) {
+	userID, chatID, ok := h.chatRequestParams(w, r)
+	if !ok {
+		return
+	}
+	prefs, err := h.chatService.SetChatPinned(r.Context(), chatID, userID, pinned)
+	h.writeChatPreferences(w, prefs, err)
+}
+
+func (h *ChatHandler) chatRequestParams(w http.ResponseWriter, r *http.Request) (userID, chatID uuid.UUID, ok bool) {
+	userID, ok = middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	chatID, err := uuid.Parse(chi.URLParam(r, "chatId"))
+	if err != nil {
+		response.BadRequest(w, "invalid chat id")
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, chatID, true
+}
+
+func (h *ChatHandler) writeChatPreferences(w http.ResponseWriter, prefs *domain.ChatPreferences, err error) {
+	switch {
+	case err == nil:
+		response.OK(w, prefs)
+	case errors.Is(err, domain.ErrInvalidMuteUntil):
+		response.BadRequest(w, err.Error())
+	case errors.Is(err, domain.ErrChatNotFound):
+		response.NotFound(w, err.Error())
+	case errors.Is(err, domain.ErrNotChatParticipant):
+		response.Forbidden(w, err.Error())
+	case errors.Is(err, domain.ErrTooManyPinnedChats):
+		response.Conflict(w, fmt.Sprintf("at most %d chats can be pinned", domain.MaxPinnedChats))
+	default:
+		h.logger.Error("failed to update chat preferences", zap.Error(err))
+		response.InternalError(w, "failed to update chat preferences")
+	}
+}
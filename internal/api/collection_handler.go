@@ -0,0 +1,259 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/middleware"
+	"github.com/locolive/backend/pkg/response"
+	"go.uber.org/zap"
+)
+
+// CollectionHandler handles shared story collections
+type CollectionHandler struct {
+	service *domain.CollectionService
+	logger  *zap.Logger
+}
+
+// NewCollectionHandler creates a new collection handler
+func NewCollectionHandler(service *domain.CollectionService, logger *zap.Logger) *CollectionHandler {
+	return &CollectionHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// CreateCollection starts a shared story reel for an event or place
+func (h *CollectionHandler) CreateCollection(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	var params domain.CreateCollectionParams
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+	params.OwnerID = userID
+
+	collection, err := h.service.CreateCollection(r.Context(), params)
+	if err != nil {
+		if err == domain.ErrInvalidCollection {
+			response.BadRequest(w, "kind (event or place), title (max 100), contribution_policy (open or approval), a valid location and schedule are required")
+			return
+		}
+		h.logger.Error("create collection failed", zap.Error(err))
+		response.InternalError(w, "failed to create collection")
+		return
+	}
+
+	response.Created(w, collection)
+}
+
+// GetCollection returns a collection
+func (h *CollectionHandler) GetCollection(w http.ResponseWriter, r *http.Request) {
+	collectionID, err := uuid.Parse(chi.URLParam(r, "collectionId"))
+	if err != nil {
+		response.BadRequest(w, "invalid collection id")
+		return
+	}
+
+	collection, err := h.service.GetCollection(r.Context(), collectionID)
+	if err != nil {
+		h.handleError(w, "get collection", err)
+		return
+	}
+
+	response.OK(w, collection)
+}
+
+// GetStories returns the combined reel of every contributor's stories
+func (h *CollectionHandler) GetStories(w http.ResponseWriter, r *http.Request) {
+	collectionID, err := uuid.Parse(chi.URLParam(r, "collectionId"))
+	if err != nil {
+		response.BadRequest(w, "invalid collection id")
+		return
+	}
+
+	page, limit, offset := pageParams(r, domain.FeedPageLimits)
+
+	stories, err := h.service.GetStories(r.Context(), collectionID, page, limit)
+	if err != nil {
+		h.handleError(w, "get collection stories", err)
+		return
+	}
+
+	writeFeed(w, r, stories, page, limit, offset)
+}
+
+// Join asks to contribute to a collection
+func (h *CollectionHandler) Join(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	collectionID, err := uuid.Parse(chi.URLParam(r, "collectionId"))
+	if err != nil {
+		response.BadRequest(w, "invalid collection id")
+		return
+	}
+
+	contributor, err := h.service.Join(r.Context(), userID, collectionID)
+	if err != nil {
+		h.handleError(w, "join collection", err)
+		return
+	}
+
+	response.OK(w, contributor)
+}
+
+// GetContributors lists contributors with ?status= (default pending); owner only
+func (h *CollectionHandler) GetContributors(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	collectionID, err := uuid.Parse(chi.URLParam(r, "collectionId"))
+	if err != nil {
+		response.BadRequest(w, "invalid collection id")
+		return
+	}
+
+	status := domain.ContributorStatus(r.URL.Query().Get("status"))
+	switch status {
+	case "":
+		status = domain.ContributorPending
+	case domain.ContributorPending, domain.ContributorApproved, domain.ContributorRejected:
+	default:
+		response.BadRequest(w, "status must be pending, approved or rejected")
+		return
+	}
+
+	contributors, err := h.service.GetContributors(r.Context(), userID, collectionID, status)
+	if err != nil {
+		h.handleError(w, "get collection contributors", err)
+		return
+	}
+
+	response.OK(w, contributors)
+}
+
+// ApproveContributor lets a user add stories to the collection
+func (h *CollectionHandler) ApproveContributor(w http.ResponseWriter, r *http.Request) {
+	h.decideContributor(w, r, true)
+}
+
+// RejectContributor refuses or revokes a user's contributions
+func (h *CollectionHandler) RejectContributor(w http.ResponseWriter, r *http.Request) {
+	h.decideContributor(w, r, false)
+}
+
+func (h *CollectionHandler) decideContributor(w http.ResponseWriter, r *http.Request, approve bool) {
+	ownerID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	collectionID, err := uuid.Parse(chi.URLParam(r, "collectionId"))
+	if err != nil {
+		response.BadRequest(w, "invalid collection id")
+		return
+	}
+	userID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		response.BadRequest(w, "invalid user id")
+		return
+	}
+
+	contributor, err := h.service.DecideContributor(r.Context(), ownerID, collectionID, userID, approve)
+	if err != nil {
+		h.handleError(w, "decide collection contributor", err)
+		return
+	}
+
+	response.OK(w, contributor)
+}
+
+// AddStory adds one of the user's stories to the collection
+func (h *CollectionHandler) AddStory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	collectionID, err := uuid.Parse(chi.URLParam(r, "collectionId"))
+	if err != nil {
+		response.BadRequest(w, "invalid collection id")
+		return
+	}
+
+	var req struct {
+		StoryID uuid.UUID `json:"story_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.StoryID == uuid.Nil {
+		response.BadRequest(w, "story_id is required")
+		return
+	}
+
+	if err := h.service.AddStory(r.Context(), userID, collectionID, req.StoryID); err != nil {
+		h.handleError(w, "add collection story", err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// RemoveStory takes a story out of the collection
+func (h *CollectionHandler) RemoveStory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	collectionID, err := uuid.Parse(chi.URLParam(r, "collectionId"))
+	if err != nil {
+		response.BadRequest(w, "invalid collection id")
+		return
+	}
+	storyID, err := uuid.Parse(chi.URLParam(r, "storyId"))
+	if err != nil {
+		response.BadRequest(w, "invalid story id")
+		return
+	}
+
+	if err := h.service.RemoveStory(r.Context(), userID, collectionID, storyID); err != nil {
+		h.handleError(w, "remove collection story", err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// handleError maps collection errors to responses
+func (h *CollectionHandler) handleError(w http.ResponseWriter, op string, err error) {
+	switch err {
+	case domain.ErrCollectionNotFound, domain.ErrContributorNotFound, domain.ErrStoryNotInCollection, domain.ErrStoryNotFound:
+		response.NotFound(w, err.Error())
+	case domain.ErrNotCollectionOwner, domain.ErrNotContributor:
+		response.Forbidden(w, err.Error())
+	case domain.ErrCollectionClosed:
+		response.Conflict(w, err.Error())
+	case domain.ErrStoryNotContributable:
+		response.BadRequest(w, err.Error())
+	default:
+		h.logger.Error(op+" failed", zap.Error(err))
+		response.InternalError(w, "failed to "+op)
+	}
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/middleware"
+	"github.com/locolive/backend/pkg/response"
+	"go.uber.org/zap"
+)
+
+// CommentHandler serves comment threads on stories
+type CommentHandler struct {
+	service *domain.CommentService
+	logger  *zap.Logger
+}
+
+// NewCommentHandler creates a new comment handler
+func NewCommentHandler(service *domain.CommentService, logger *zap.Logger) *CommentHandler {
+	return &CommentHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetComments handles GET /stories/{storyId}/comments
+func (h *CommentHandler) GetComments(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	storyID, err := uuid.Parse(chi.URLParam(r, "storyId"))
+	if err != nil {
+		response.BadRequest(w, "invalid story id")
+		return
+	}
+
+	limit, offset := listParams(r, domain.CommentPageLimits)
+
+	comments, err := h.service.GetComments(r.Context(), userID, storyID, limit, offset)
+	if err != nil {
+		h.writeCommentError(w, err, "failed to get comments")
+		return
+	}
+
+	response.List(w, comments, response.Meta{Limit: limit, Offset: offset})
+}
+
+// AddComment handles POST /stories/{storyId}/comments
+func (h *CommentHandler) AddComment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	storyID, err := uuid.Parse(chi.URLParam(r, "storyId"))
+	if err != nil {
+		response.BadRequest(w, "invalid story id")
+		return
+	}
+
+	var req struct {
+		Content string `json:"content"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	comment, err := h.service.AddComment(r.Context(), userID, storyID, req.Content)
+	if err != nil {
+		h.writeCommentError(w, err, "failed to add comment")
+		return
+	}
+
+	response.Created(w, comment)
+}
+
+// DeleteComment handles DELETE /stories/{storyId}/comments/{commentId}
+func (h *CommentHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	storyID, err := uuid.Parse(chi.URLParam(r, "storyId"))
+	if err != nil {
+		response.BadRequest(w, "invalid story id")
+		return
+	}
+	commentID, err := uuid.Parse(chi.URLParam(r, "commentId"))
+	if err != nil {
+		response.BadRequest(w, "invalid comment id")
+		return
+	}
+
+	if err := h.service.DeleteComment(r.Context(), userID, storyID, commentID); err != nil {
+		h.writeCommentError(w, err, "failed to delete comment")
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// SetCommentSettings handles PUT /stories/{storyId}/comment-settings
+func (h *CommentHandler) SetCommentSettings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	storyID, err := uuid.Parse(chi.URLParam(r, "storyId"))
+	if err != nil {
+		response.BadRequest(w, "invalid story id")
+		return
+	}
+
+	var req struct {
+		CommentsEnabled *bool `json:"comments_enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.CommentsEnabled == nil {
+		response.BadRequest(w, "comments_enabled is required")
+		return
+	}
+
+	if err := h.service.SetCommentsEnabled(r.Context(), userID, storyID, *req.CommentsEnabled); err != nil {
+		h.writeCommentError(w, err, "failed to update comment settings")
+		return
+	}
+
+	response.OK(w, map[string]bool{"comments_enabled": *req.CommentsEnabled})
+}
+
+func (h *CommentHandler) writeCommentError(w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, domain.ErrStoryNotFound), errors.Is(err, domain.ErrCommentNotFound):
+		response.NotFound(w, err.Error())
+	case errors.Is(err, domain.ErrCommentsDisabled), errors.Is(err, domain.ErrCannotDeleteComment):
+		response.Forbidden(w, err.Error())
+	case errors.Is(err, domain.ErrEmptyComment), errors.Is(err, domain.ErrCommentTooLong):
+		response.BadRequest(w, err.Error())
+	default:
+		h.logger.Error(msg, zap.Error(err))
+		response.InternalError(w, msg)
+	}
+}
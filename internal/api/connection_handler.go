@@ -2,9 +2,10 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
-	"strconv"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/locolive/backend/internal/domain"
 	"github.com/locolive/backend/internal/middleware"
@@ -48,6 +49,9 @@ func (h *ConnectionHandler) SendRequest(w http.ResponseWriter, r *http.Request)
 
 	conn, err := h.connService.SendRequest(r.Context(), userID, targetID)
 	if err != nil {
+		if h.writeConnectionError(w, err) {
+			return
+		}
 		h.logger.Error("failed to send connection request", zap.Error(err))
 		response.InternalError(w, "failed to send request")
 		return
@@ -81,6 +85,9 @@ func (h *ConnectionHandler) RespondRequest(w http.ResponseWriter, r *http.Reques
 
 	conn, err := h.connService.RespondToRequest(r.Context(), userID, connID, req.Accept)
 	if err != nil {
+		if h.writeConnectionError(w, err) {
+			return
+		}
 		h.logger.Error("failed to respond to request", zap.Error(err))
 		response.InternalError(w, "failed to respond")
 		return
@@ -97,12 +104,7 @@ func (h *ConnectionHandler) GetConnections(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-	if page < 1 {
-		page = 1
-	}
-	offset := (page - 1) * limit
+	_, limit, offset := pageParams(r, domain.ConnectionPageLimits)
 
 	conns, err := h.connService.GetConnections(r.Context(), userID, limit, offset)
 	if err != nil {
@@ -111,7 +113,7 @@ func (h *ConnectionHandler) GetConnections(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	response.OK(w, conns)
+	response.List(w, conns, response.Meta{Limit: limit, Offset: offset})
 }
 
 // GetRequests handles GET /connections/requests
@@ -122,12 +124,7 @@ func (h *ConnectionHandler) GetRequests(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-	if page < 1 {
-		page = 1
-	}
-	offset := (page - 1) * limit
+	_, limit, offset := pageParams(r, domain.ConnectionPageLimits)
 
 	conns, err := h.connService.GetPendingRequests(r.Context(), userID, limit, offset)
 	if err != nil {
@@ -136,5 +133,135 @@ func (h *ConnectionHandler) GetRequests(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	response.OK(w, conns)
+	response.List(w, conns, response.Meta{Limit: limit, Offset: offset})
+}
+
+// GetStatus handles GET /connections/status/{userId}
+func (h *ConnectionHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	otherID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		response.BadRequest(w, "invalid user id")
+		return
+	}
+
+	state, err := h.connService.GetState(r.Context(), userID, otherID)
+	if err != nil {
+		if h.writeConnectionError(w, err) {
+			return
+		}
+		h.logger.Error("failed to get connection status", zap.Error(err))
+		response.InternalError(w, "failed to get connection status")
+		return
+	}
+
+	response.OK(w, map[string]domain.ConnectionState{"state": state})
+}
+
+// RemoveConnection handles DELETE /connections/{userId} (cancel request or disconnect)
+func (h *ConnectionHandler) RemoveConnection(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	otherID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		response.BadRequest(w, "invalid user id")
+		return
+	}
+
+	if err := h.connService.RemoveConnection(r.Context(), userID, otherID); err != nil {
+		if h.writeConnectionError(w, err) {
+			return
+		}
+		h.logger.Error("failed to remove connection", zap.Error(err))
+		response.InternalError(w, "failed to remove connection")
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// BlockUser handles POST /connections/block
+func (h *ConnectionHandler) BlockUser(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	var req struct {
+		TargetUserID string `json:"target_user_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request")
+		return
+	}
+
+	targetID, err := uuid.Parse(req.TargetUserID)
+	if err != nil {
+		response.BadRequest(w, "invalid target user id")
+		return
+	}
+
+	if err := h.connService.BlockUser(r.Context(), userID, targetID); err != nil {
+		if h.writeConnectionError(w, err) {
+			return
+		}
+		h.logger.Error("failed to block user", zap.Error(err))
+		response.InternalError(w, "failed to block user")
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// UnblockUser handles DELETE /connections/block/{userId}
+func (h *ConnectionHandler) UnblockUser(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	otherID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		response.BadRequest(w, "invalid user id")
+		return
+	}
+
+	if err := h.connService.UnblockUser(r.Context(), userID, otherID); err != nil {
+		if h.writeConnectionError(w, err) {
+			return
+		}
+		h.logger.Error("failed to unblock user", zap.Error(err))
+		response.InternalError(w, "failed to unblock user")
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// writeConnectionError maps connection domain errors to responses, returning false if unhandled
+func (h *ConnectionHandler) writeConnectionError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, domain.ErrCannotConnectSelf):
+		response.BadRequest(w, err.Error())
+	case errors.Is(err, domain.ErrConnectionNotFound):
+		response.NotFound(w, err.Error())
+	case errors.Is(err, domain.ErrNotConnectionReceiver), errors.Is(err, domain.ErrConnectionBlocked):
+		response.Forbidden(w, err.Error())
+	case errors.Is(err, domain.ErrAlreadyConnected), errors.Is(err, domain.ErrConnectionNotPending), errors.Is(err, domain.ErrConnectionCooldown):
+		response.Conflict(w, err.Error())
+	default:
+		return false
+	}
+	return true
 }
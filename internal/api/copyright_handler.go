@@ -0,0 +1,197 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/middleware"
+	"github.com/locolive/backend/pkg/response"
+	"go.uber.org/zap"
+)
+
+// CopyrightHandler handles DMCA-style takedown claims and copyright strikes
+type CopyrightHandler struct {
+	service *domain.CopyrightService
+	logger  *zap.Logger
+}
+
+// NewCopyrightHandler creates a new copyright handler
+func NewCopyrightHandler(service *domain.CopyrightService, logger *zap.Logger) *CopyrightHandler {
+	return &CopyrightHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// FileClaim accepts a takedown notice from a rights holder; no account is needed
+func (h *CopyrightHandler) FileClaim(w http.ResponseWriter, r *http.Request) {
+	var params domain.FileCopyrightClaimParams
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	claim, err := h.service.FileClaim(r.Context(), params)
+	if err != nil {
+		if err == domain.ErrInvalidCopyrightClaim {
+			response.BadRequest(w, "story_id, claimant_name, a valid claimant_email, work_description, signature, good_faith and accurate are required")
+			return
+		}
+		h.handleError(w, "file copyright claim", err)
+		return
+	}
+
+	response.Created(w, map[string]interface{}{
+		"id":     claim.ID,
+		"status": claim.Status,
+	})
+}
+
+type counterNoticeRequest struct {
+	Statement string `json:"statement"`
+	Signature string `json:"signature"`
+}
+
+// FileCounterNotice lets the story owner dispute a claim
+func (h *CopyrightHandler) FileCounterNotice(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	claimID, err := uuid.Parse(chi.URLParam(r, "claimId"))
+	if err != nil {
+		response.BadRequest(w, "invalid claim id")
+		return
+	}
+
+	var req counterNoticeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	claim, err := h.service.FileCounterNotice(r.Context(), userID, claimID, req.Statement, req.Signature)
+	if err != nil {
+		if err == domain.ErrInvalidCopyrightClaim {
+			response.BadRequest(w, "statement and signature are required")
+			return
+		}
+		h.handleError(w, "file counter-notice", err)
+		return
+	}
+
+	response.OK(w, claim)
+}
+
+// GetMyStrikes returns the caller's strikes
+func (h *CopyrightHandler) GetMyStrikes(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+	h.writeStrikes(w, r, userID)
+}
+
+// GetUserStrikes returns a user's strikes for admins
+func (h *CopyrightHandler) GetUserStrikes(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		response.BadRequest(w, "invalid user id")
+		return
+	}
+	h.writeStrikes(w, r, userID)
+}
+
+func (h *CopyrightHandler) writeStrikes(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	strikes, err := h.service.GetStrikes(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("get strikes failed", zap.Error(err))
+		response.InternalError(w, "failed to get strikes")
+		return
+	}
+	response.OK(w, strikes)
+}
+
+// ListClaims returns claims with ?status= (default pending)
+func (h *CopyrightHandler) ListClaims(w http.ResponseWriter, r *http.Request) {
+	status := domain.CopyrightClaimStatus(r.URL.Query().Get("status"))
+	switch status {
+	case "":
+		status = domain.CopyrightPending
+	case domain.CopyrightPending, domain.CopyrightUpheld, domain.CopyrightRejected, domain.CopyrightCounterNoticed, domain.CopyrightRestored:
+	default:
+		response.BadRequest(w, "status must be pending, upheld, rejected, counter_noticed or restored")
+		return
+	}
+
+	limit, offset := listParams(r, domain.AdminPageLimits)
+
+	claims, err := h.service.GetClaims(r.Context(), status, limit, offset)
+	if err != nil {
+		h.logger.Error("list copyright claims failed", zap.Error(err))
+		response.InternalError(w, "failed to list copyright claims")
+		return
+	}
+
+	response.List(w, claims, response.Meta{Limit: limit, Offset: offset})
+}
+
+// UpholdClaim keeps the content down and strikes its owner
+func (h *CopyrightHandler) UpholdClaim(w http.ResponseWriter, r *http.Request) {
+	h.resolveClaim(w, r, "uphold copyright claim", h.service.Uphold)
+}
+
+// RejectClaim dismisses an invalid claim and restores the content
+func (h *CopyrightHandler) RejectClaim(w http.ResponseWriter, r *http.Request) {
+	h.resolveClaim(w, r, "reject copyright claim", h.service.Reject)
+}
+
+// RestoreClaim restores counter-noticed content and revokes the strike
+func (h *CopyrightHandler) RestoreClaim(w http.ResponseWriter, r *http.Request) {
+	h.resolveClaim(w, r, "restore copyright claim", h.service.Restore)
+}
+
+func (h *CopyrightHandler) resolveClaim(w http.ResponseWriter, r *http.Request, op string,
+	resolve func(ctx context.Context, claimID, reviewerID uuid.UUID) (*domain.CopyrightClaim, error)) {
+	reviewerID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	claimID, err := uuid.Parse(chi.URLParam(r, "claimId"))
+	if err != nil {
+		response.BadRequest(w, "invalid claim id")
+		return
+	}
+
+	claim, err := resolve(r.Context(), claimID, reviewerID)
+	if err != nil {
+		h.handleError(w, op, err)
+		return
+	}
+
+	response.OK(w, claim)
+}
+
+// handleError maps copyright errors to responses
+func (h *CopyrightHandler) handleError(w http.ResponseWriter, op string, err error) {
+	switch err {
+	case domain.ErrCopyrightClaimNotFound, domain.ErrStoryNotFound:
+		response.NotFound(w, err.Error())
+	case domain.ErrNotClaimedContentOwner:
+		response.Forbidden(w, err.Error())
+	case domain.ErrCopyrightClaimState:
+		response.Conflict(w, err.Error())
+	default:
+		h.logger.Error(op+" failed", zap.Error(err))
+		response.InternalError(w, "failed to "+op)
+	}
+}
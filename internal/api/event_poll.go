@@ -0,0 +1,171 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/middleware"
+	"github.com/locolive/backend/pkg/response"
+	"go.uber.org/zap"
+)
+
+const (
+	// PollWait is how long a long-poll blocks before returning empty
+	PollWait = 25 * time.Second
+	// pollLogSize is how many recent events are kept per polling user
+	pollLogSize = 100
+	// pollLogIdle drops a user's event log once they stop polling; it spans
+	// a full PollWait plus time to reconnect
+	pollLogIdle = 2 * time.Minute
+)
+
+// pollEvent is a user event with its position in the user's event log
+type pollEvent struct {
+	seq  int64
+	data json.RawMessage
+}
+
+// userEventLog buffers a polling user's recent events. Cursors are the seq of
+// the last event a client received.
+type userEventLog struct {
+	events   []pollEvent
+	seq      int64
+	lastPoll time.Time
+	// wake is closed and replaced whenever an event is appended
+	wake chan struct{}
+}
+
+// eventLogs holds the event logs of users that are long-polling. Only users
+// that polled recently get one, so WebSocket-only users cost nothing.
+type eventLogs struct {
+	mu   sync.Mutex
+	logs map[uuid.UUID]*userEventLog
+}
+
+func newEventLogs() *eventLogs {
+	return &eventLogs{logs: make(map[uuid.UUID]*userEventLog)}
+}
+
+// append records an event for userID if they are polling
+func (l *eventLogs) append(userID uuid.UUID, data []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	log, ok := l.logs[userID]
+	if !ok {
+		return
+	}
+	log.seq++
+	log.events = append(log.events, pollEvent{seq: log.seq, data: data})
+	if len(log.events) > pollLogSize {
+		log.events = log.events[len(log.events)-pollLogSize:]
+	}
+	close(log.wake)
+	log.wake = make(chan struct{})
+}
+
+// since returns userID's events after cursor, creating their log on first
+// poll. A nil cursor only starts the log. gap reports that events between
+// cursor and the returned ones were lost, e.g. to a restart or a full buffer.
+// When there is nothing new, wake is closed on the next event.
+func (l *eventLogs) since(userID uuid.UUID, cursor *int64) (events []json.RawMessage, next int64, gap bool, wake <-chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	log, ok := l.logs[userID]
+	if !ok {
+		log = &userEventLog{wake: make(chan struct{})}
+		l.logs[userID] = log
+	}
+	log.lastPoll = time.Now()
+
+	if cursor == nil {
+		return nil, log.seq, false, log.wake
+	}
+	if *cursor > log.seq {
+		// Cursor from before a restart
+		return nil, log.seq, true, log.wake
+	}
+
+	oldest := log.seq - int64(len(log.events)) + 1
+	gap = *cursor+1 < oldest
+	for _, e := range log.events {
+		if e.seq > *cursor {
+			events = append(events, e.data)
+		}
+	}
+	return events, log.seq, gap, log.wake
+}
+
+// prune drops the logs of users who stopped polling
+func (l *eventLogs) prune() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for userID, log := range l.logs {
+		if time.Since(log.lastPoll) > pollLogIdle {
+			delete(l.logs, userID)
+		}
+	}
+}
+
+// PollResponse is the long-poll result. Cursor is passed back on the next
+// poll. Gap means events were missed and the client should refetch state.
+type PollResponse struct {
+	Events []json.RawMessage `json:"events"`
+	Cursor string            `json:"cursor"`
+	Gap    bool              `json:"gap"`
+}
+
+// PollEvents is the long-poll fallback for clients that can't hold a
+// WebSocket. It returns the same events as /ws/chat, except area
+// subscriptions, waiting up to PollWait for one to arrive.
+func (h *ChatHandler) PollEvents(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	var cursor *int64
+	if c := r.URL.Query().Get("cursor"); c != "" {
+		n, err := strconv.ParseInt(c, 10, 64)
+		if err != nil || n < 0 {
+			response.BadRequest(w, "invalid cursor")
+			return
+		}
+		cursor = &n
+	}
+
+	// The server's write timeout is shorter than a poll
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(PollWait + 10*time.Second)); err != nil {
+		h.logger.Debug("failed to extend poll write deadline", zap.Error(err))
+	}
+
+	timer := time.NewTimer(PollWait)
+	defer timer.Stop()
+
+	for {
+		events, next, gap, wake := h.wsManager.events.since(userID, cursor)
+		if len(events) > 0 || gap {
+			response.OK(w, PollResponse{Events: events, Cursor: strconv.FormatInt(next, 10), Gap: gap})
+			return
+		}
+		if cursor == nil {
+			cursor = &next
+		}
+
+		select {
+		case <-wake:
+		case <-timer.C:
+			response.OK(w, PollResponse{Events: []json.RawMessage{}, Cursor: strconv.FormatInt(next, 10)})
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
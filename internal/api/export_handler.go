@@ -0,0 +1,75 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/middleware"
+	"github.com/locolive/backend/pkg/response"
+	"go.uber.org/zap"
+)
+
+// ExportHandler serves users' exports of their own data
+type ExportHandler struct {
+	service *domain.ExportService
+	logger  *zap.Logger
+}
+
+// NewExportHandler creates a new export handler
+func NewExportHandler(service *domain.ExportService, logger *zap.Logger) *ExportHandler {
+	return &ExportHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RequestExport handles POST /me/export. A new export is accepted with 202;
+// one already in progress is returned with 200.
+func (h *ExportHandler) RequestExport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	export, created, err := h.service.RequestExport(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrDataExportTooSoon) {
+			response.TooManyRequests(w, err.Error())
+			return
+		}
+		h.logger.Error("failed to request data export", zap.Error(err))
+		response.InternalError(w, "failed to request data export")
+		return
+	}
+
+	if created {
+		response.JSON(w, http.StatusAccepted, export)
+		return
+	}
+	response.OK(w, export)
+}
+
+// GetExport handles GET /me/export, returning the user's latest export and,
+// once it is ready, where to download it
+func (h *ExportHandler) GetExport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	export, err := h.service.GetLatestExport(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrDataExportNotFound) {
+			response.NotFound(w, err.Error())
+			return
+		}
+		h.logger.Error("failed to get data export", zap.Error(err))
+		response.InternalError(w, "failed to get data export")
+		return
+	}
+
+	response.OK(w, export)
+}
@@ -1,25 +1,66 @@
 package api
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 
 	"github.com/locolive/backend/internal/auth"
+	"github.com/locolive/backend/internal/cache"
 	"github.com/locolive/backend/internal/config"
 	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/pkg/response"
 	"go.uber.org/zap"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 )
 
+const (
+	// oauthStateTTL is how long the user has to finish signing in with Google
+	oauthStateTTL = 10 * time.Minute
+	// oauthCodeTTL is how long the app has to redeem a PKCE login code
+	oauthCodeTTL = time.Minute
+	// oauthStateCookie binds a login attempt to the browser that started it
+	oauthStateCookie = "oauth_state"
+)
+
+// oauthState is kept server-side for each login attempt, keyed by the state
+// parameter
+type oauthState struct {
+	// Verifier is our PKCE verifier for the code exchange with Google
+	Verifier string `json:"verifier"`
+	// CodeChallenge is the app's S256 challenge when it uses PKCE
+	CodeChallenge string `json:"code_challenge,omitempty"`
+	// RedirectURL is the callback Google was sent, which the exchange repeats
+	RedirectURL string `json:"redirect_url,omitempty"`
+	// AppScheme is the deep link scheme to return to
+	AppScheme string `json:"app_scheme,omitempty"`
+}
+
+// oauthCode is a signed-in result waiting for the app to redeem it with its
+// PKCE verifier
+type oauthCode struct {
+	CodeChallenge string                    `json:"code_challenge"`
+	Result        *domain.GoogleLoginResult `json:"result"`
+}
+
 // GoogleOAuthHandler handles browser-based Google OAuth flow
 type GoogleOAuthHandler struct {
-	config      *oauth2.Config
-	authService *domain.AuthService
-	verifier    *auth.GoogleAuthVerifier
-	logger      *zap.Logger
-	appScheme   string // App deep link scheme (e.g., "locoliveapp")
+	config       *oauth2.Config
+	authService  *domain.AuthService
+	verifier     *auth.GoogleAuthVerifier
+	store        cache.Cache // login state and PKCE codes; must be shared across instances
+	logger       *zap.Logger
+	redirectURLs []string // Allowed callbacks; the first is the default
+	appSchemes   []string // Allowed deep link schemes (e.g., "locoliveapp"); the first is the default
 }
 
 // NewGoogleOAuthHandler creates a new Google OAuth handler
@@ -27,6 +68,7 @@ func NewGoogleOAuthHandler(
 	cfg *config.Config,
 	authService *domain.AuthService,
 	verifier *auth.GoogleAuthVerifier,
+	store cache.Cache,
 	logger *zap.Logger,
 ) *GoogleOAuthHandler {
 
@@ -39,7 +81,7 @@ func NewGoogleOAuthHandler(
 	conf := &oauth2.Config{
 		ClientID:     clientID,
 		ClientSecret: cfg.Google.ClientSecret,
-		RedirectURL:  "https://launchit.co.in/auth/google/callback", // TODO: Make configurable
+		RedirectURL:  cfg.Google.RedirectURLs[0],
 		Scopes: []string{
 			"https://www.googleapis.com/auth/userinfo.email",
 			"https://www.googleapis.com/auth/userinfo.profile",
@@ -48,23 +90,67 @@ func NewGoogleOAuthHandler(
 	}
 
 	return &GoogleOAuthHandler{
-		config:      conf,
-		authService: authService,
-		verifier:    verifier,
-		logger:      logger,
-		appScheme:   "locoliveapp",
+		config:       conf,
+		authService:  authService,
+		verifier:     verifier,
+		store:        store,
+		logger:       logger,
+		redirectURLs: cfg.Google.RedirectURLs,
+		appSchemes:   cfg.App.DeepLinkSchemes,
 	}
 }
 
-// GoogleOAuthLogin initiates the Google OAuth flow by redirecting to Google
+// GoogleOAuthLogin initiates the Google OAuth flow by redirecting to Google.
+// Apps that pass ?code_challenge= (S256) get a one-time code in the deep link
+// instead of tokens, and redeem it at POST /auth/google/token. Builds for
+// other environments pick a configured ?redirect_uri= and ?app_scheme=.
 func (h *GoogleOAuthHandler) GoogleOAuthLogin(w http.ResponseWriter, r *http.Request) {
-	// Generate state for CSRF protection (in production, store this in session)
-	state := "random-state-string" // TODO: Generate and store proper state
+	st := oauthState{Verifier: oauth2.GenerateVerifier()}
+
+	var ok bool
+	if st.AppScheme, ok = pickAllowed(h.appSchemes, r.URL.Query().Get("app_scheme")); !ok {
+		h.redirectWithError(w, r, h.appSchemes[0], "invalid_app_scheme")
+		return
+	}
+	if st.RedirectURL, ok = pickAllowed(h.redirectURLs, r.URL.Query().Get("redirect_uri")); !ok {
+		h.redirectWithError(w, r, st.AppScheme, "invalid_redirect_uri")
+		return
+	}
+
+	if challenge := r.URL.Query().Get("code_challenge"); challenge != "" {
+		if method := r.URL.Query().Get("code_challenge_method"); method != "" && method != "S256" {
+			h.redirectWithError(w, r, st.AppScheme, "unsupported_code_challenge_method")
+			return
+		}
+		if !validCodeChallenge(challenge) {
+			h.redirectWithError(w, r, st.AppScheme, "invalid_code_challenge")
+			return
+		}
+		st.CodeChallenge = challenge
+	}
+
+	// Generate state for CSRF protection
+	state, err := randomToken()
+	if err != nil {
+		h.logger.Error("Failed to generate OAuth state", zap.Error(err))
+		h.redirectWithError(w, r, st.AppScheme, "Failed to start Google sign-in")
+		return
+	}
+	data, _ := json.Marshal(st)
+	if err := h.store.Set(r.Context(), oauthStateKey(state), data, oauthStateTTL); err != nil {
+		h.logger.Error("Failed to store OAuth state", zap.Error(err))
+		h.redirectWithError(w, r, st.AppScheme, "Failed to start Google sign-in")
+		return
+	}
+	h.setStateCookie(w, st.RedirectURL, state, int(oauthStateTTL.Seconds()))
 
 	// Generate the Google OAuth URL
-	authURL := h.config.AuthCodeURL(state, oauth2.AccessTypeOffline)
+	authURL := h.oauthConfig(st.RedirectURL).AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.S256ChallengeOption(st.Verifier))
 
-	h.logger.Info("Redirecting to Google OAuth", zap.String("url", authURL))
+	h.logger.Info("Redirecting to Google OAuth",
+		zap.Bool("pkce", st.CodeChallenge != ""),
+		zap.String("redirect_url", st.RedirectURL),
+	)
 
 	// Redirect user to Google login
 	http.Redirect(w, r, authURL, http.StatusTemporaryRedirect)
@@ -74,21 +160,51 @@ func (h *GoogleOAuthHandler) GoogleOAuthLogin(w http.ResponseWriter, r *http.Req
 func (h *GoogleOAuthHandler) GoogleOAuthCallback(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
+	// Verify the state was issued by us, to this browser, and use it up
+	state := r.URL.Query().Get("state")
+	cookie, err := r.Cookie(oauthStateCookie)
+	if state == "" || err != nil || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		h.redirectWithError(w, r, h.appSchemes[0], "invalid_state")
+		return
+	}
+	h.setStateCookie(w, h.config.RedirectURL, "", -1)
+
+	data, err := h.store.Get(ctx, oauthStateKey(state))
+	if err != nil {
+		if !errors.Is(err, cache.ErrMiss) {
+			h.logger.Error("Failed to load OAuth state", zap.Error(err))
+		}
+		h.redirectWithError(w, r, h.appSchemes[0], "invalid_state")
+		return
+	}
+	_ = h.store.Delete(ctx, oauthStateKey(state))
+
+	var st oauthState
+	if err := json.Unmarshal(data, &st); err != nil {
+		h.redirectWithError(w, r, h.appSchemes[0], "invalid_state")
+		return
+	}
+	// State from before redirect targets were recorded used the defaults
+	if st.AppScheme == "" {
+		st.AppScheme = h.appSchemes[0]
+	}
+	if st.RedirectURL == "" {
+		st.RedirectURL = h.config.RedirectURL
+	}
+
 	// Get the authorization code from the query params
 	code := r.URL.Query().Get("code")
 	if code == "" {
 		h.logger.Error("No code in callback")
-		h.redirectWithError(w, r, "Authorization code missing")
+		h.redirectWithError(w, r, st.AppScheme, "Authorization code missing")
 		return
 	}
 
-	// TODO: Verify state parameter for CSRF protection
-
 	// Exchange the code for tokens
-	token, err := h.config.Exchange(ctx, code)
+	token, err := h.oauthConfig(st.RedirectURL).Exchange(ctx, code, oauth2.VerifierOption(st.Verifier))
 	if err != nil {
 		h.logger.Error("Failed to exchange code for token", zap.Error(err))
-		h.redirectWithError(w, r, "Failed to authenticate with Google")
+		h.redirectWithError(w, r, st.AppScheme, "Failed to authenticate with Google")
 		return
 	}
 
@@ -96,41 +212,175 @@ func (h *GoogleOAuthHandler) GoogleOAuthCallback(w http.ResponseWriter, r *http.
 	idToken, ok := token.Extra("id_token").(string)
 	if !ok {
 		h.logger.Error("No ID token in response")
-		h.redirectWithError(w, r, "Failed to get user info from Google")
+		h.redirectWithError(w, r, st.AppScheme, "Failed to get user info from Google")
 		return
 	}
 
 	// Use the existing GoogleLogin service method to create/login user
 	result, err := h.authService.GoogleLogin(ctx, idToken)
+	if errors.Is(err, domain.ErrGoogleLinkRequired) {
+		// Stable code the app can match on to prompt for password sign-in
+		h.redirectWithError(w, r, st.AppScheme, "account_link_required")
+		return
+	}
 	if err != nil {
 		h.logger.Error("Failed to login user", zap.Error(err))
-		h.redirectWithError(w, r, "Failed to create user account")
+		h.redirectWithError(w, r, st.AppScheme, "Failed to create user account")
+		return
+	}
+
+	if st.CodeChallenge != "" {
+		h.redirectWithCode(w, r, st.AppScheme, st.CodeChallenge, result)
 		return
 	}
 
 	// Redirect back to the app with the tokens as query params
-	h.redirectWithSuccess(w, r, result.AccessToken, result.RefreshToken, result.User.ID.String())
+	h.redirectWithSuccess(w, r, st.AppScheme, result.AccessToken, result.RefreshToken, result.User.ID.String())
+}
+
+// GoogleOAuthTokenRequest redeems a PKCE login code
+type GoogleOAuthTokenRequest struct {
+	Code         string `json:"code"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+// GoogleOAuthToken returns the login result for a code from the PKCE deep
+// link. Only the app holding the verifier can redeem it, so another app that
+// intercepts the deep link gets nothing.
+func (h *GoogleOAuthHandler) GoogleOAuthToken(w http.ResponseWriter, r *http.Request) {
+	var req GoogleOAuthTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+	if req.Code == "" || req.CodeVerifier == "" {
+		response.BadRequest(w, "code and code_verifier are required")
+		return
+	}
+
+	ctx := r.Context()
+	data, err := h.store.Get(ctx, oauthCodeKey(req.Code))
+	if err != nil {
+		if !errors.Is(err, cache.ErrMiss) {
+			h.logger.Error("Failed to load OAuth code", zap.Error(err))
+			response.InternalError(w, "failed to redeem code")
+			return
+		}
+		response.Error(w, http.StatusBadRequest, "INVALID_GRANT", "code is invalid or expired")
+		return
+	}
+	// Codes are single use, whether or not the verifier matches
+	_ = h.store.Delete(ctx, oauthCodeKey(req.Code))
+
+	var pending oauthCode
+	if err := json.Unmarshal(data, &pending); err != nil {
+		response.Error(w, http.StatusBadRequest, "INVALID_GRANT", "code is invalid or expired")
+		return
+	}
+	challenge := oauth2.S256ChallengeFromVerifier(req.CodeVerifier)
+	if subtle.ConstantTimeCompare([]byte(challenge), []byte(pending.CodeChallenge)) != 1 {
+		response.Error(w, http.StatusBadRequest, "INVALID_GRANT", "code_verifier does not match")
+		return
+	}
+
+	response.OK(w, pending.Result)
+}
+
+// redirectWithCode parks the login result under a one-time code and
+// redirects to the app with the code
+func (h *GoogleOAuthHandler) redirectWithCode(w http.ResponseWriter, r *http.Request, scheme, challenge string, result *domain.GoogleLoginResult) {
+	code, err := randomToken()
+	if err != nil {
+		h.logger.Error("Failed to generate OAuth code", zap.Error(err))
+		h.redirectWithError(w, r, scheme, "Failed to complete Google sign-in")
+		return
+	}
+	data, _ := json.Marshal(oauthCode{CodeChallenge: challenge, Result: result})
+	if err := h.store.Set(r.Context(), oauthCodeKey(code), data, oauthCodeTTL); err != nil {
+		h.logger.Error("Failed to store OAuth code", zap.Error(err))
+		h.redirectWithError(w, r, scheme, "Failed to complete Google sign-in")
+		return
+	}
+
+	appURL := fmt.Sprintf("%s://auth/callback?code=%s", scheme, url.QueryEscape(code))
+	http.Redirect(w, r, appURL, http.StatusTemporaryRedirect)
+}
+
+func (h *GoogleOAuthHandler) setStateCookie(w http.ResponseWriter, redirectURL, state string, maxAge int) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     oauthStateCookie,
+		Value:    state,
+		Path:     "/auth/google",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(redirectURL, "https://"),
+		// Lax is sent on Google's top-level redirect back to the callback
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// oauthConfig returns the OAuth config for one of the allowed callbacks
+func (h *GoogleOAuthHandler) oauthConfig(redirectURL string) *oauth2.Config {
+	if redirectURL == h.config.RedirectURL {
+		return h.config
+	}
+	conf := *h.config
+	conf.RedirectURL = redirectURL
+	return &conf
+}
+
+// pickAllowed returns want if it's one of allowed, or the first allowed
+// value when want is empty
+func pickAllowed(allowed []string, want string) (string, bool) {
+	if want == "" {
+		return allowed[0], true
+	}
+	for _, a := range allowed {
+		if a == want {
+			return a, true
+		}
+	}
+	return "", false
+}
+
+func oauthStateKey(state string) string { return "oauth_state:" + state }
+
+func oauthCodeKey(code string) string { return "oauth_code:" + code }
+
+// randomToken returns 32 random bytes, base64url encoded
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// validCodeChallenge checks for an S256 challenge: a base64url SHA-256 hash
+func validCodeChallenge(challenge string) bool {
+	b, err := base64.RawURLEncoding.DecodeString(challenge)
+	return err == nil && len(b) == sha256.Size
 }
 
 // redirectWithSuccess redirects to the app with auth tokens
-func (h *GoogleOAuthHandler) redirectWithSuccess(w http.ResponseWriter, r *http.Request, accessToken, refreshToken, userID string) {
+func (h *GoogleOAuthHandler) redirectWithSuccess(w http.ResponseWriter, r *http.Request, scheme, accessToken, refreshToken, userID string) {
 	// Create deep link URL: locoliveapp://auth/callback?access_token=xxx&refresh_token=yyy
 	appURL := fmt.Sprintf("%s://auth/callback?access_token=%s&refresh_token=%s&user_id=%s",
-		h.appScheme,
+		scheme,
 		url.QueryEscape(accessToken),
 		url.QueryEscape(refreshToken),
 		url.QueryEscape(userID),
 	)
 
-	h.logger.Info("Redirecting to app with tokens", zap.String("scheme", h.appScheme))
+	h.logger.Info("Redirecting to app with tokens", zap.String("scheme", scheme))
 
 	http.Redirect(w, r, appURL, http.StatusTemporaryRedirect)
 }
 
 // redirectWithError redirects to the app with an error message
-func (h *GoogleOAuthHandler) redirectWithError(w http.ResponseWriter, r *http.Request, errorMsg string) {
+func (h *GoogleOAuthHandler) redirectWithError(w http.ResponseWriter, r *http.Request, scheme, errorMsg string) {
 	appURL := fmt.Sprintf("%s://auth/callback?error=%s",
-		h.appScheme,
+		scheme,
 		url.QueryEscape(errorMsg),
 	)
 
@@ -3,17 +3,28 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"sync/atomic"
 	"time"
+
+	"github.com/locolive/backend/pkg/response"
 )
 
 // HealthHandler handles health check endpoints
-type HealthHandler struct{}
+type HealthHandler struct {
+	ready atomic.Bool
+}
 
-// NewHealthHandler creates a new health handler
+// NewHealthHandler creates a new health handler. It reports not ready until
+// SetReady is called, e.g. once the startup warm-up is done.
 func NewHealthHandler() *HealthHandler {
 	return &HealthHandler{}
 }
 
+// SetReady sets whether the instance should take traffic
+func (h *HealthHandler) SetReady(ready bool) {
+	h.ready.Store(ready)
+}
+
 // HealthResponse represents the health check response
 type HealthResponse struct {
 	Status    string `json:"status"`
@@ -34,16 +45,20 @@ func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(resp)
 }
 
-// Ready returns the readiness status (for Kubernetes)
+// Ready returns the readiness status (for Kubernetes), 503 while warming up
 func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
-	// Add dependency checks here (DB, Redis, etc.)
 	resp := HealthResponse{
 		Status:    "ready",
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
 	}
+	status := http.StatusOK
+	if !h.ready.Load() {
+		resp.Status = "warming_up"
+		status = http.StatusServiceUnavailable
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
+	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(resp)
 }
 
@@ -58,3 +73,15 @@ func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(resp)
 }
+
+// TimeResponse is the server clock, for apps to measure their device's skew
+type TimeResponse struct {
+	ServerTime time.Time `json:"server_time"`
+	UnixMillis int64     `json:"unix_ms"`
+}
+
+// Time returns the server time
+func (h *HealthHandler) Time(w http.ResponseWriter, r *http.Request) {
+	now := time.Now().UTC()
+	response.OK(w, TimeResponse{ServerTime: now, UnixMillis: now.UnixMilli()})
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/locolive/backend/internal/auth"
+)
+
+// JWKSHandler publishes the public keys that verify our tokens
+type JWKSHandler struct {
+	jwt *auth.JWTManager
+}
+
+// NewJWKSHandler creates a new JWKS handler
+func NewJWKSHandler(jwt *auth.JWTManager) *JWKSHandler {
+	return &JWKSHandler{jwt: jwt}
+}
+
+// Get serves the key set in standard JWKS form, without the response
+// envelope, so off-the-shelf JWT libraries can consume it. The set is empty
+// while tokens are signed with the shared secret.
+func (h *JWKSHandler) Get(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	// Short enough that a newly added key is picked up before it signs
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(h.jwt.JWKS())
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/middleware"
+	"github.com/locolive/backend/pkg/response"
+	"go.uber.org/zap"
+)
+
+// LegalHoldHandler handles admin-only legal hold and audit log endpoints
+type LegalHoldHandler struct {
+	service *domain.LegalHoldService
+	logger  *zap.Logger
+}
+
+// NewLegalHoldHandler creates a new legal hold handler
+func NewLegalHoldHandler(service *domain.LegalHoldService, logger *zap.Logger) *LegalHoldHandler {
+	return &LegalHoldHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// PlaceHold puts a user under legal hold
+func (h *LegalHoldHandler) PlaceHold(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		response.BadRequest(w, "invalid user id")
+		return
+	}
+
+	var req struct {
+		Reason        string  `json:"reason"`
+		CaseReference *string `json:"case_reference"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	hold, snapshot, err := h.service.PlaceHold(r.Context(), adminID, userID, req.Reason, req.CaseReference)
+	if err != nil {
+		switch err {
+		case domain.ErrLegalHoldReasonRequired:
+			response.BadRequest(w, err.Error())
+		case domain.ErrUserNotFound:
+			response.NotFound(w, "user not found")
+		case domain.ErrLegalHoldExists:
+			response.Conflict(w, err.Error())
+		default:
+			h.logger.Error("place legal hold failed", zap.Error(err))
+			response.InternalError(w, "failed to place legal hold")
+		}
+		return
+	}
+
+	response.Created(w, map[string]interface{}{
+		"hold":     hold,
+		"snapshot": snapshot,
+	})
+}
+
+// ReleaseHold lifts a legal hold
+func (h *LegalHoldHandler) ReleaseHold(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	holdID, err := uuid.Parse(chi.URLParam(r, "holdId"))
+	if err != nil {
+		response.BadRequest(w, "invalid hold id")
+		return
+	}
+
+	hold, err := h.service.ReleaseHold(r.Context(), adminID, holdID)
+	if err != nil {
+		switch err {
+		case domain.ErrLegalHoldNotFound:
+			response.NotFound(w, err.Error())
+		case domain.ErrLegalHoldReleased:
+			response.Conflict(w, err.Error())
+		default:
+			h.logger.Error("release legal hold failed", zap.Error(err))
+			response.InternalError(w, "failed to release legal hold")
+		}
+		return
+	}
+
+	response.OK(w, hold)
+}
+
+// ListHolds lists legal holds; ?active=false includes released ones
+func (h *LegalHoldHandler) ListHolds(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	activeOnly := r.URL.Query().Get("active") != "false"
+	limit, offset := listParams(r, domain.AdminPageLimits)
+
+	holds, err := h.service.GetHolds(r.Context(), adminID, activeOnly, limit, offset)
+	if err != nil {
+		h.logger.Error("list legal holds failed", zap.Error(err))
+		response.InternalError(w, "failed to list legal holds")
+		return
+	}
+
+	response.List(w, holds, response.Meta{Limit: limit, Offset: offset})
+}
+
+// GetSnapshot returns the data preserved when a hold was placed
+func (h *LegalHoldHandler) GetSnapshot(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	holdID, err := uuid.Parse(chi.URLParam(r, "holdId"))
+	if err != nil {
+		response.BadRequest(w, "invalid hold id")
+		return
+	}
+
+	snapshot, err := h.service.GetSnapshot(r.Context(), adminID, holdID)
+	if err != nil {
+		if err == domain.ErrLegalHoldNotFound {
+			response.NotFound(w, err.Error())
+			return
+		}
+		h.logger.Error("get preservation snapshot failed", zap.Error(err))
+		response.InternalError(w, "failed to get preservation snapshot")
+		return
+	}
+
+	response.OK(w, snapshot)
+}
+
+// GetAuditLog returns admin actions taken on ?user_id=
+func (h *LegalHoldHandler) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	userID, err := uuid.Parse(r.URL.Query().Get("user_id"))
+	if err != nil {
+		response.BadRequest(w, "user_id is required")
+		return
+	}
+
+	limit, offset := listParams(r, domain.AdminPageLimits)
+
+	entries, err := h.service.GetAuditLog(r.Context(), adminID, userID, limit, offset)
+	if err != nil {
+		h.logger.Error("get audit log failed", zap.Error(err))
+		response.InternalError(w, "failed to get audit log")
+		return
+	}
+
+	response.List(w, entries, response.Meta{Limit: limit, Offset: offset})
+}
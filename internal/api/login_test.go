@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/locolive/backend/internal/config"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/metrics"
+	"github.com/locolive/backend/internal/slo"
+	"go.uber.org/zap"
+)
+
+// loginAttempts counts failed logins per IP; no email is registered
+type loginAttempts struct {
+	domain.AuthRepository
+
+	mu       sync.Mutex
+	failures map[string]int
+}
+
+func (r *loginAttempts) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return nil, domain.ErrUserNotFound
+}
+
+func (r *loginAttempts) RecordLoginAttempt(ctx context.Context, attempt domain.LoginAttempt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !attempt.Succeeded {
+		r.failures[attempt.IPAddress]++
+	}
+	return nil
+}
+
+func (r *loginAttempts) CountFailedLoginsFromIP(ctx context.Context, ip string, since time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.failures[ip], nil
+}
+
+func TestLoginIPLockoutIgnoresSpoofedHeaders(t *testing.T) {
+	repo := &loginAttempts{failures: make(map[string]int)}
+	logger := zap.NewNop()
+	authService := domain.NewAuthService(repo, nil, nil, nil, nil, nil, nil, domain.EmailLinkSettings{}, nil,
+		domain.LockoutPolicy{MaxIPFailures: 3, IPWindow: time.Hour}, domain.SessionPolicy{}, domain.TextPolicy{}, nil, nil, logger)
+	rt := &Router{
+		authHandler:    NewAuthHandler(authService, repo, nil, false, metrics.NewRegistry(), logger),
+		supportHandler: &SupportHandler{},
+		metrics:        metrics.NewRegistry(),
+		sloTracker:     slo.NewTracker(config.SLOConfig{}, nil, logger),
+		logger:         logger,
+	}
+	handler := rt.Setup()
+
+	spoofed := []string{"1.1.1.1", "2.2.2.2", "3.3.3.3", "4.4.4.4"}
+	for i, ip := range spoofed {
+		req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{"email":"asha@example.com","password":"wrong"}`))
+		req.RemoteAddr = "203.0.113.7:5000"
+		req.Header.Set("True-Client-IP", ip)
+		req.Header.Set("X-Real-IP", ip)
+		req.Header.Set("X-Forwarded-For", ip)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		want := http.StatusUnauthorized
+		if i == len(spoofed)-1 {
+			want = http.StatusTooManyRequests
+		}
+		if rec.Code != want {
+			t.Fatalf("attempt %d: got %d, want %d", i+1, rec.Code, want)
+		}
+	}
+	if len(repo.failures) != 1 || repo.failures["203.0.113.7"] != 3 {
+		t.Fatalf("got failures %v, want 3 from the peer address", repo.failures)
+	}
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/middleware"
+	"github.com/locolive/backend/internal/storage"
+	"github.com/locolive/backend/pkg/response"
+	"go.uber.org/zap"
+)
+
+// Uploads are only served to those allowed to fetch them, so shared caches
+// must not keep them. Files named by storage.SaveFile never change.
+const (
+	privateMediaCacheControl   = "private, max-age=31536000, immutable"
+	privateUploadsCacheControl = "private, max-age=3600"
+)
+
+// MediaHandler serves uploaded files after checking the requester may fetch
+// them. Files on local disk are streamed from root; with a store that signs
+// URLs, the requester is redirected to a short-lived signed URL instead.
+type MediaHandler struct {
+	service *domain.MediaService
+	root    http.FileSystem
+	signer  storage.URLSigner
+	ttl     time.Duration
+	logger  *zap.Logger
+}
+
+// NewMediaHandler creates a new media handler. signer may be nil, in which
+// case files are served from root.
+func NewMediaHandler(service *domain.MediaService, root http.FileSystem, signer storage.URLSigner, ttl time.Duration, logger *zap.Logger) *MediaHandler {
+	return &MediaHandler{
+		service: service,
+		root:    root,
+		signer:  signer,
+		ttl:     ttl,
+		logger:  logger,
+	}
+}
+
+// Serve handles GET /uploads/*
+func (h *MediaHandler) Serve(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+	name, ok := mediaName(r)
+	if !ok {
+		response.NotFound(w, domain.ErrMediaNotFound.Error())
+		return
+	}
+
+	url, err := h.service.Authorize(r.Context(), userID, name)
+	h.send(w, r, name, url, err)
+}
+
+// ServeAny handles GET /admin/media/*, which serves any upload to operators
+func (h *MediaHandler) ServeAny(w http.ResponseWriter, r *http.Request) {
+	name, ok := mediaName(r)
+	if !ok {
+		response.NotFound(w, domain.ErrMediaNotFound.Error())
+		return
+	}
+
+	url, err := h.service.Find(r.Context(), name)
+	h.send(w, r, name, url, err)
+}
+
+func (h *MediaHandler) send(w http.ResponseWriter, r *http.Request, name, url string, err error) {
+	if err != nil {
+		if errors.Is(err, domain.ErrMediaNotFound) {
+			response.NotFound(w, err.Error())
+			return
+		}
+		h.logger.Error("failed to authorize media", zap.Error(err))
+		response.InternalError(w, "failed to load file")
+		return
+	}
+
+	if h.signer == nil {
+		h.serveFile(w, r, name)
+		return
+	}
+
+	signed, err := h.signer.SignedURL(r.Context(), url, h.ttl)
+	if err != nil {
+		h.logger.Error("failed to sign media url", zap.Error(err))
+		response.InternalError(w, "failed to load file")
+		return
+	}
+	// The signed URL expires, so the redirect itself mustn't be cached
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, signed, http.StatusFound)
+}
+
+// serveFile streams a file from local disk. Range requests, needed for
+// scrubbing through videos, and conditional requests on Last-Modified are
+// answered by http.ServeContent.
+func (h *MediaHandler) serveFile(w http.ResponseWriter, r *http.Request, name string) {
+	f, err := h.root.Open("/" + name)
+	if err != nil {
+		response.NotFound(w, domain.ErrMediaNotFound.Error())
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		response.NotFound(w, domain.ErrMediaNotFound.Error())
+		return
+	}
+
+	header := w.Header()
+	if storage.IsImmutable(name) {
+		header.Set("Cache-Control", privateMediaCacheControl)
+		header.Set("ETag", `"`+info.Name()+`"`)
+	} else {
+		header.Set("Cache-Control", privateUploadsCacheControl)
+	}
+	// Uploads are user content, so never let a browser guess another type
+	header.Set("X-Content-Type-Options", "nosniff")
+
+	http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+}
+
+// mediaName returns the file name a media request is for. Uploads are
+// stored flat, so anything with a directory in it can't be one.
+func mediaName(r *http.Request) (string, bool) {
+	name := chi.URLParam(r, "*")
+	if name == "" || strings.Contains(name, "/") {
+		return "", false
+	}
+	return name, true
+}
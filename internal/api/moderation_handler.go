@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/middleware"
+	"github.com/locolive/backend/pkg/response"
+	"go.uber.org/zap"
+)
+
+// ModerationHandler accepts user reports on stories, comments and chats
+type ModerationHandler struct {
+	service *domain.ModerationService
+	logger  *zap.Logger
+}
+
+// NewModerationHandler creates a new moderation handler
+func NewModerationHandler(service *domain.ModerationService, logger *zap.Logger) *ModerationHandler {
+	return &ModerationHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+type reportRequest struct {
+	Reason  string  `json:"reason"`
+	Details *string `json:"details"`
+}
+
+// ReportStory reports another user's story
+func (h *ModerationHandler) ReportStory(w http.ResponseWriter, r *http.Request) {
+	h.report(w, r, "storyId", h.service.ReportStory)
+}
+
+// ReportComment reports another user's comment on a story
+func (h *ModerationHandler) ReportComment(w http.ResponseWriter, r *http.Request) {
+	h.report(w, r, "commentId", h.service.ReportComment)
+}
+
+// ReportChat reports a chat the user takes part in
+func (h *ModerationHandler) ReportChat(w http.ResponseWriter, r *http.Request) {
+	h.report(w, r, "chatId", h.service.ReportChat)
+}
+
+func (h *ModerationHandler) report(w http.ResponseWriter, r *http.Request, param string,
+	report func(ctx context.Context, reporterID, targetID uuid.UUID, reason string, details *string) error) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	targetID, err := uuid.Parse(chi.URLParam(r, param))
+	if err != nil {
+		response.BadRequest(w, "invalid id")
+		return
+	}
+
+	var req reportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	err = report(r.Context(), userID, targetID, req.Reason, req.Details)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidReportReason:
+			response.BadRequest(w, "reason must be one of spam, harassment, nudity, violence, hate, impersonation, other")
+		case domain.ErrVerifiedImpersonation:
+			response.BadRequest(w, err.Error())
+		case domain.ErrAlreadyReported:
+			response.Conflict(w, err.Error())
+		case domain.ErrStoryNotFound, domain.ErrChatNotFound, domain.ErrCommentNotFound:
+			response.NotFound(w, err.Error())
+		case domain.ErrCannotReportOwn, domain.ErrNotChatParticipant:
+			response.Forbidden(w, err.Error())
+		default:
+			h.logger.Error("failed to record report", zap.Error(err))
+			response.InternalError(w, "failed to record report")
+		}
+		return
+	}
+
+	response.NoContent(w)
+}
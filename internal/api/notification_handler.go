@@ -2,8 +2,10 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -32,12 +34,7 @@ func (h *NotificationHandler) GetNotifications(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-	if page < 1 {
-		page = 1
-	}
-	offset := (page - 1) * limit
+	_, limit, offset := pageParams(r, domain.NotificationPageLimits)
 
 	notifs, err := h.service.GetNotifications(r.Context(), userID, limit, offset)
 	if err != nil {
@@ -46,7 +43,7 @@ func (h *NotificationHandler) GetNotifications(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	response.OK(w, notifs)
+	response.List(w, notifs, response.Meta{Limit: limit, Offset: offset})
 }
 
 func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
@@ -72,6 +69,51 @@ func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
 	response.OK(w, map[string]string{"status": "success"})
 }
 
+// MarkOpened is called by the app when the user opens a push notification
+func (h *NotificationHandler) MarkOpened(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.BadRequest(w, "invalid notification id")
+		return
+	}
+
+	if err := h.service.MarkOpened(r.Context(), userID, id); err != nil {
+		if errors.Is(err, domain.ErrNotificationNotFound) {
+			response.NotFound(w, "notification not found")
+			return
+		}
+		h.logger.Error("failed to mark notification opened", zap.Error(err))
+		response.InternalError(w, "failed to update notification")
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// GetDeliveryStats returns push delivery and open rates per notification type
+// over the last ?days= (default 7)
+func (h *NotificationHandler) GetDeliveryStats(w http.ResponseWriter, r *http.Request) {
+	days, _ := strconv.Atoi(r.URL.Query().Get("days"))
+	if days <= 0 {
+		days = 7
+	}
+
+	stats, err := h.service.GetDeliveryStats(r.Context(), time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		h.logger.Error("get notification delivery stats failed", zap.Error(err))
+		response.InternalError(w, "failed to get notification delivery stats")
+		return
+	}
+
+	response.OK(w, stats)
+}
+
 func (h *NotificationHandler) UpdateFCMToken(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserID(r.Context())
 	if !ok {
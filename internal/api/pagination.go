@@ -0,0 +1,28 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/locolive/backend/internal/domain"
+)
+
+// listParams reads ?limit= and ?offset= clamped to limits. Unparseable values
+// count as missing.
+func listParams(r *http.Request, limits domain.PageLimits) (limit, offset int) {
+	limit, _ = strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ = strconv.Atoi(r.URL.Query().Get("offset"))
+	return limits.Clamp(limit, offset)
+}
+
+// pageParams reads the 1-based ?page= and ?limit= clamped to limits, along
+// with the offset of that page
+func pageParams(r *http.Request, limits domain.PageLimits) (page, limit, offset int) {
+	page, _ = strconv.Atoi(r.URL.Query().Get("page"))
+	limit, _ = strconv.Atoi(r.URL.Query().Get("limit"))
+	if page < 1 {
+		page = 1
+	}
+	limit, offset = limits.Page(page, limit)
+	return page, limit, offset
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/middleware"
+	"github.com/locolive/backend/pkg/response"
+	"go.uber.org/zap"
+)
+
+// PlaceHandler manages a user's saved places
+type PlaceHandler struct {
+	service *domain.PlaceService
+	logger  *zap.Logger
+}
+
+// NewPlaceHandler creates a new saved place handler
+func NewPlaceHandler(service *domain.PlaceService, logger *zap.Logger) *PlaceHandler {
+	return &PlaceHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetPlaces lists the authenticated user's saved places
+func (h *PlaceHandler) GetPlaces(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	places, err := h.service.GetPlaces(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("failed to get saved places", zap.Error(err))
+		response.InternalError(w, "failed to fetch saved places")
+		return
+	}
+
+	response.OK(w, places)
+}
+
+// CreatePlace saves a new place
+func (h *PlaceHandler) CreatePlace(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	var params domain.CreatePlaceParams
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+	params.UserID = userID
+
+	place, err := h.service.CreatePlace(r.Context(), params)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidPlace:
+			response.BadRequest(w, "name, kind, story_privacy, coordinates or radius_meters is invalid")
+		case domain.ErrTooManyPlaces:
+			response.Conflict(w, "saved place limit reached")
+		default:
+			h.logger.Error("failed to create saved place", zap.Error(err))
+			response.InternalError(w, "failed to create saved place")
+		}
+		return
+	}
+
+	response.Created(w, place)
+}
+
+// DeletePlace removes a saved place
+func (h *PlaceHandler) DeletePlace(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	placeID, err := uuid.Parse(chi.URLParam(r, "placeId"))
+	if err != nil {
+		response.BadRequest(w, "invalid place id")
+		return
+	}
+
+	if err := h.service.DeletePlace(r.Context(), userID, placeID); err != nil {
+		if err == domain.ErrPlaceNotFound {
+			response.NotFound(w, "saved place not found")
+			return
+		}
+		h.logger.Error("failed to delete saved place", zap.Error(err))
+		response.InternalError(w, "failed to delete saved place")
+		return
+	}
+
+	response.NoContent(w)
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/middleware"
+	"github.com/locolive/backend/pkg/response"
+	"github.com/locolive/backend/pkg/validator"
+	"go.uber.org/zap"
+)
+
+// PrivacyHandler handles admin-run privacy requests
+type PrivacyHandler struct {
+	service *domain.PrivacyService
+	logger  *zap.Logger
+}
+
+// NewPrivacyHandler creates a new privacy request handler
+func NewPrivacyHandler(service *domain.PrivacyService, logger *zap.Logger) *PrivacyHandler {
+	return &PrivacyHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RectifyUser corrects a user's PII
+func (h *PrivacyHandler) RectifyUser(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		response.BadRequest(w, "invalid user id")
+		return
+	}
+
+	var params domain.RectifyUserParams
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	if params.Name != nil {
+		name := validator.SanitizeString(*params.Name, 100)
+		if !validator.ValidateName(name) {
+			response.BadRequest(w, "name must be 2-100 characters")
+			return
+		}
+		params.Name = &name
+	}
+	if params.Email != nil {
+		email := validator.SanitizeEmail(*params.Email)
+		if !validator.ValidateEmail(email) {
+			response.BadRequest(w, "invalid email format")
+			return
+		}
+		params.Email = &email
+	}
+	if params.Phone != nil && !validator.ValidatePhone(*params.Phone) {
+		response.BadRequest(w, "invalid phone format")
+		return
+	}
+
+	user, err := h.service.RectifyUser(r.Context(), adminID, userID, params)
+	if err != nil {
+		switch err {
+		case domain.ErrNothingToRectify:
+			response.BadRequest(w, err.Error())
+		case domain.ErrUserNotFound:
+			response.NotFound(w, err.Error())
+		case domain.ErrEmailAlreadyExists, domain.ErrPhoneAlreadyExists:
+			response.Conflict(w, err.Error())
+		default:
+			h.logger.Error("rectify user failed", zap.Error(err))
+			response.InternalError(w, "failed to rectify user")
+		}
+		return
+	}
+
+	response.OK(w, user)
+}
+
+// PseudonymizeUser replaces a user's PII with a pseudonym
+func (h *PrivacyHandler) PseudonymizeUser(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		response.BadRequest(w, "invalid user id")
+		return
+	}
+
+	result, err := h.service.PseudonymizeUser(r.Context(), adminID, userID)
+	if err != nil {
+		switch err {
+		case domain.ErrUserNotFound:
+			response.NotFound(w, err.Error())
+		case domain.ErrUserOnLegalHold:
+			response.Conflict(w, err.Error())
+		default:
+			h.logger.Error("pseudonymize user failed", zap.Error(err))
+			response.InternalError(w, "failed to pseudonymize user")
+		}
+		return
+	}
+
+	response.OK(w, result)
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/pkg/response"
+	"go.uber.org/zap"
+)
+
+// publicProfileCacheControl lets link preview fetchers and CDNs keep a
+// profile briefly; a profile made private drops out within a minute
+const publicProfileCacheControl = "public, max-age=60"
+
+// PublicProfileHandler serves public profiles to anyone, signed in or not
+type PublicProfileHandler struct {
+	authService *domain.AuthService
+	media       *MediaHandler
+	logger      *zap.Logger
+}
+
+// NewPublicProfileHandler creates a new public profile handler. Avatars are
+// sent through media, like other uploads.
+func NewPublicProfileHandler(authService *domain.AuthService, media *MediaHandler, logger *zap.Logger) *PublicProfileHandler {
+	return &PublicProfileHandler{
+		authService: authService,
+		media:       media,
+		logger:      logger,
+	}
+}
+
+// GetProfile handles GET /public/users/{username}
+func (h *PublicProfileHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	profile, ok := h.lookup(w, r)
+	if !ok {
+		return
+	}
+	if profile.Avatar != nil && *profile.Avatar != "" {
+		profile.AvatarURL = strings.TrimSuffix(r.URL.Path, "/") + "/avatar"
+	}
+
+	w.Header().Set("Cache-Control", publicProfileCacheControl)
+	response.OK(w, profile)
+}
+
+// GetAvatar handles GET /public/users/{username}/avatar, sending the avatar
+// of a public profile
+func (h *PublicProfileHandler) GetAvatar(w http.ResponseWriter, r *http.Request) {
+	profile, ok := h.lookup(w, r)
+	if !ok {
+		return
+	}
+	if profile.Avatar == nil || *profile.Avatar == "" {
+		response.NotFound(w, domain.ErrMediaNotFound.Error())
+		return
+	}
+	h.media.send(w, r, path.Base(*profile.Avatar), *profile.Avatar, nil)
+}
+
+func (h *PublicProfileHandler) lookup(w http.ResponseWriter, r *http.Request) (*domain.PublicProfile, bool) {
+	profile, err := h.authService.GetPublicProfile(r.Context(), chi.URLParam(r, "username"))
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			response.NotFound(w, "user not found")
+			return nil, false
+		}
+		h.logger.Error("failed to get public profile", zap.Error(err))
+		response.InternalError(w, "failed to get profile")
+		return nil, false
+	}
+	return profile, true
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/middleware"
+	"github.com/locolive/backend/pkg/response"
+	"go.uber.org/zap"
+)
+
+// RecapHandler serves the weekly recap card
+type RecapHandler struct {
+	service *domain.RecapService
+	logger  *zap.Logger
+}
+
+// NewRecapHandler creates a new recap handler
+func NewRecapHandler(service *domain.RecapService, logger *zap.Logger) *RecapHandler {
+	return &RecapHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetLatest returns the authenticated user's most recent weekly recap
+func (h *RecapHandler) GetLatest(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	recap, err := h.service.GetLatestRecap(r.Context(), userID)
+	if err != nil {
+		if err == domain.ErrRecapNotFound {
+			response.NotFound(w, "no recap yet")
+			return
+		}
+		h.logger.Error("failed to get weekly recap", zap.Error(err))
+		response.InternalError(w, "failed to fetch recap")
+		return
+	}
+
+	response.OK(w, recap)
+}
@@ -1,16 +1,18 @@
 package api
 
 import (
+	"net"
 	"net/http"
-	"os"
-	"path/filepath"
-	"strings"
 
 	"github.com/go-chi/chi/v5"
 	chimiddleware "github.com/go-chi/chi/v5/middleware"
 
 	"github.com/locolive/backend/internal/auth"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/metrics"
 	"github.com/locolive/backend/internal/middleware"
+	"github.com/locolive/backend/internal/ratelimit"
+	"github.com/locolive/backend/internal/slo"
 	"go.uber.org/zap"
 )
 
@@ -22,7 +24,42 @@ type Router struct {
 	connectionHandler   *ConnectionHandler
 	notificationHandler *NotificationHandler
 	healthHandler       *HealthHandler
+	sloHandler          *SLOHandler
+	adminHandler        *AdminHandler
+	adminUserIDs        []string
+	roleLookup          middleware.RoleLookup
+	usageHandler        *UsageHandler
+	placeHandler        *PlaceHandler
+	recapHandler        *RecapHandler
+	campaignHandler     *CampaignHandler
+	statsHandler        *StatsHandler
+	moderationHandler   *ModerationHandler
+	legalHoldHandler    *LegalHoldHandler
+	privacyHandler      *PrivacyHandler
+	supportHandler      *SupportHandler
+	cardHandler         *CardHandler
+	collectionHandler   *CollectionHandler
+	copyrightHandler    *CopyrightHandler
+	waveHandler         *WaveHandler
+	commentHandler      *CommentHandler
+	activityHandler     *ActivityHandler
+	mediaHandler        *MediaHandler
+	exportHandler       *ExportHandler
+	publicProfile       *PublicProfileHandler
+	graphQL             http.Handler
+	featureGate         *domain.FeatureGate
+	regionHeader        string
+	countryLookup       middleware.CountryLookup
+	versionPolicy       middleware.VersionPolicy
+	appConfigHandler    *AppConfigHandler
+	clientPolicy        domain.ClientPolicy
+	rateLimiter         *ratelimit.Limiter
 	jwtManager          *auth.JWTManager
+	sessionLookup       middleware.SessionLookup
+	metrics             *metrics.Registry
+	sloTracker          *slo.Tracker
+	scrapeToken         string
+	trustedProxies      []*net.IPNet
 	logger              *zap.Logger
 }
 
@@ -35,7 +72,42 @@ func NewRouter(
 	connectionHandler *ConnectionHandler,
 	notificationHandler *NotificationHandler,
 	healthHandler *HealthHandler,
+	sloHandler *SLOHandler,
+	adminHandler *AdminHandler,
+	adminUserIDs []string,
+	roleLookup middleware.RoleLookup,
+	usageHandler *UsageHandler,
+	placeHandler *PlaceHandler,
+	recapHandler *RecapHandler,
+	campaignHandler *CampaignHandler,
+	statsHandler *StatsHandler,
+	moderationHandler *ModerationHandler,
+	legalHoldHandler *LegalHoldHandler,
+	privacyHandler *PrivacyHandler,
+	supportHandler *SupportHandler,
+	cardHandler *CardHandler,
+	collectionHandler *CollectionHandler,
+	copyrightHandler *CopyrightHandler,
+	waveHandler *WaveHandler,
+	commentHandler *CommentHandler,
+	activityHandler *ActivityHandler,
+	mediaHandler *MediaHandler,
+	exportHandler *ExportHandler,
+	publicProfile *PublicProfileHandler,
+	graphQL http.Handler,
+	featureGate *domain.FeatureGate,
+	regionHeader string,
+	countryLookup middleware.CountryLookup,
+	versionPolicy middleware.VersionPolicy,
+	appConfigHandler *AppConfigHandler,
+	clientPolicy domain.ClientPolicy,
+	rateLimiter *ratelimit.Limiter,
 	jwtManager *auth.JWTManager,
+	sessionLookup middleware.SessionLookup,
+	metricsRegistry *metrics.Registry,
+	sloTracker *slo.Tracker,
+	scrapeToken string,
+	trustedProxies []*net.IPNet,
 	logger *zap.Logger,
 ) *Router {
 	return &Router{
@@ -46,7 +118,42 @@ func NewRouter(
 		connectionHandler:   connectionHandler,
 		notificationHandler: notificationHandler,
 		healthHandler:       healthHandler,
+		sloHandler:          sloHandler,
+		adminHandler:        adminHandler,
+		adminUserIDs:        adminUserIDs,
+		roleLookup:          roleLookup,
+		usageHandler:        usageHandler,
+		placeHandler:        placeHandler,
+		recapHandler:        recapHandler,
+		campaignHandler:     campaignHandler,
+		statsHandler:        statsHandler,
+		moderationHandler:   moderationHandler,
+		legalHoldHandler:    legalHoldHandler,
+		privacyHandler:      privacyHandler,
+		supportHandler:      supportHandler,
+		cardHandler:         cardHandler,
+		collectionHandler:   collectionHandler,
+		copyrightHandler:    copyrightHandler,
+		waveHandler:         waveHandler,
+		commentHandler:      commentHandler,
+		activityHandler:     activityHandler,
+		mediaHandler:        mediaHandler,
+		exportHandler:       exportHandler,
+		publicProfile:       publicProfile,
+		graphQL:             graphQL,
+		featureGate:         featureGate,
+		regionHeader:        regionHeader,
+		countryLookup:       countryLookup,
+		versionPolicy:       versionPolicy,
+		appConfigHandler:    appConfigHandler,
+		clientPolicy:        clientPolicy,
+		rateLimiter:         rateLimiter,
 		jwtManager:          jwtManager,
+		sessionLookup:       sessionLookup,
+		metrics:             metricsRegistry,
+		sloTracker:          sloTracker,
+		scrapeToken:         scrapeToken,
+		trustedProxies:      trustedProxies,
 		logger:              logger,
 	}
 }
@@ -57,118 +164,334 @@ func (rt *Router) Setup() *chi.Mux {
 
 	// Global middleware
 	r.Use(chimiddleware.RequestID)
-	r.Use(chimiddleware.RealIP)
+	r.Use(middleware.RealIP(rt.trustedProxies))
+	r.Use(middleware.ClientMiddleware(rt.metrics, rt.sloTracker))
 	r.Use(middleware.RecoveryMiddleware(rt.logger))
 	r.Use(middleware.LoggingMiddleware(rt.logger))
+	r.Use(middleware.MetricsMiddleware(rt.metrics, rt.sloTracker))
 	r.Use(middleware.CORSMiddleware())
 	r.Use(chimiddleware.Compress(5))
 
-	// Serve static files from uploads directory
-	workDir, _ := os.Getwd()
-	filesDir := http.Dir(filepath.Join(workDir, "uploads"))
-	FileServer(r, "/uploads", filesDir)
+	// Uploads are only served to those allowed to see them
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.AuthMiddleware(rt.jwtManager, rt.sessionLookup))
+		r.Use(middleware.ImpersonationGuard(rt.supportHandler.service))
+		r.Get("/uploads/*", rt.mediaHandler.Serve)
+		r.Head("/uploads/*", rt.mediaHandler.Serve)
+	})
 
 	// Health endpoints (no auth required)
 	r.Route("/health", func(r chi.Router) {
 		r.Get("/", rt.healthHandler.Health)
 		r.Get("/ready", rt.healthHandler.Ready)
 		r.Get("/live", rt.healthHandler.Live)
+		r.With(middleware.RequireScrapeToken(rt.scrapeToken)).Get("/slo", rt.sloHandler.GetStatus)
 	})
 
-	// API v1
-	r.Route("/api/v1", func(r chi.Router) {
-		// Auth routes (no auth required)
-		r.Route("/auth", func(r chi.Router) {
-			r.Post("/register", rt.authHandler.Register)
-			r.Post("/login", rt.authHandler.Login)
-			r.Post("/refresh", rt.authHandler.Refresh)
-			r.Post("/logout", rt.authHandler.Logout)
-			r.Post("/google", rt.authHandler.GoogleLogin)
-			r.Post("/forgot-password", rt.authHandler.ForgotPassword)
-			r.Post("/reset-password", rt.authHandler.ResetPassword)
-		})
+	// Prometheus metrics
+	r.With(middleware.RequireScrapeToken(rt.scrapeToken)).Handle("/metrics", rt.metrics.Handler())
 
-		// Protected routes
-		r.Group(func(r chi.Router) {
-			r.Use(middleware.AuthMiddleware(rt.jwtManager))
-
-			// User routes
-			r.Get("/me", rt.authHandler.Me)
-			r.Get("/users/{userId}", rt.authHandler.GetProfile)
-			r.Post("/auth/logout-all", rt.authHandler.LogoutAll)
-			r.Put("/auth/password", rt.authHandler.UpdatePassword)
-			r.Put("/auth/email", rt.authHandler.UpdateEmail)
-			r.Put("/auth/profile", rt.authHandler.UpdateProfile)
-
-			// Story routes
-			r.Route("/stories", func(r chi.Router) {
-				r.Post("/", rt.storyHandler.CreateStory)
-				r.Get("/feed", rt.storyHandler.GetFeed)
-			})
+	// Public keys for services that validate our tokens
+	r.Get("/.well-known/jwks.json", NewJWKSHandler(rt.jwtManager).Get)
 
-			// Chat routes
-			r.Route("/chats", func(r chi.Router) {
-				r.Post("/", rt.chatHandler.CreateChat)
-				r.Get("/", rt.chatHandler.GetChats)
-				r.Get("/{chatId}/messages", rt.chatHandler.GetMessages)
-				r.Post("/{chatId}/messages", rt.chatHandler.SendMessage)
-			})
-
-			// Connection routes
-			r.Route("/connections", func(r chi.Router) {
-				r.Post("/request", rt.connectionHandler.SendRequest)
-				r.Post("/respond", rt.connectionHandler.RespondRequest)
-				r.Get("/", rt.connectionHandler.GetConnections)
-				r.Get("/requests", rt.connectionHandler.GetRequests)
-			})
-
-			// Notification routes
-			r.Route("/notifications", func(r chi.Router) {
-				r.Get("/", rt.notificationHandler.GetNotifications)
-				r.Put("/{id}/read", rt.notificationHandler.MarkRead)
-				r.Post("/fcm-token", rt.notificationHandler.UpdateFCMToken)
-			})
-		})
-	})
+	// API routes; v2 serves the same endpoints with newer response shapes
+	r.Route("/api/v1", rt.apiRoutes(1))
+	r.Route("/api/v2", rt.apiRoutes(LatestAPIVersion))
 
 	// Auth routes at root level for compatibility
 	r.Route("/auth", func(r chi.Router) {
-		r.Post("/register", rt.authHandler.Register)
-		r.Post("/login", rt.authHandler.Login)
+		r.Use(rt.limit("public"))
+		r.Use(middleware.RegionMiddleware(rt.regionHeader, nil))
+		r.With(rt.limit("register")).Post("/register", rt.authHandler.Register)
+		r.With(rt.limit("login")).Post("/login", rt.authHandler.Login)
 		r.Post("/refresh", rt.authHandler.Refresh)
 		r.Post("/logout", rt.authHandler.Logout)
 		r.Post("/google", rt.authHandler.GoogleLogin)
+		r.Post("/apple", rt.authHandler.AppleLogin)
 
 		// Browser-based Google OAuth (for mobile in-app browser)
 		r.Get("/google/login", rt.googleOAuthHandler.GoogleOAuthLogin)
 		r.Get("/google/callback", rt.googleOAuthHandler.GoogleOAuthCallback)
+		r.Post("/google/token", rt.googleOAuthHandler.GoogleOAuthToken)
 	})
 
 	// WebSocket routes
 	r.Group(func(r chi.Router) {
-		r.Use(middleware.AuthMiddleware(rt.jwtManager))
+		r.Use(middleware.AuthMiddleware(rt.jwtManager, rt.sessionLookup))
+		r.Use(middleware.RejectImpersonation)
 		r.Get("/ws/chat", rt.chatHandler.HandleWebSocket)
 	})
 
 	return r
 }
 
-// FileServer conveniently sets up a http.FileServer handler at the given path
-func FileServer(r chi.Router, path string, root http.FileSystem) {
-	if strings.ContainsAny(path, "{}*") {
-		panic("FileServer does not permit any URL parameters.")
-	}
+// LatestAPIVersion is the newest API version served under /api/v{N}
+const LatestAPIVersion = 2
+
+// apiRoutes registers the API under /api/v{version}. Handlers read the
+// negotiated version with middleware.GetAPIVersion.
+func (rt *Router) apiRoutes(version int) func(chi.Router) {
+	return func(r chi.Router) {
+		r.Use(middleware.APIVersionMiddleware(version, rt.versionPolicy, rt.metrics))
+
+		// App config stays reachable for blocked clients so they learn to upgrade
+		r.With(middleware.RegionMiddleware(rt.regionHeader, nil)).Get("/app/config", rt.appConfigHandler.Get)
+		r.Get("/time", rt.healthHandler.Time)
+
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireClientHeader(rt.clientPolicy))
+			r.Use(middleware.RequireSupportedClient(rt.clientPolicy))
+
+			// Auth routes (no auth required)
+			r.Route("/auth", func(r chi.Router) {
+				r.Use(rt.limit("public"))
+				// Sessions record the region they sign in from
+				r.Use(middleware.RegionMiddleware(rt.regionHeader, nil))
+				r.With(rt.limit("register")).Post("/register", rt.authHandler.Register)
+				r.With(rt.limit("login")).Post("/login", rt.authHandler.Login)
+				r.Post("/refresh", rt.authHandler.Refresh)
+				r.Post("/logout", rt.authHandler.Logout)
+				r.Post("/google", rt.authHandler.GoogleLogin)
+				r.Post("/apple", rt.authHandler.AppleLogin)
+				r.With(rt.limit("forgot_password")).Post("/forgot-password", rt.authHandler.ForgotPassword)
+				r.Post("/reset-password", rt.authHandler.ResetPassword)
+				r.Post("/verify-email", rt.authHandler.VerifyEmail)
+				r.With(rt.limit("otp_request")).Post("/otp/request", rt.authHandler.RequestOTP)
+				r.Post("/otp/verify", rt.authHandler.VerifyOTP)
+			})
+
+			// Public statistics (no auth required, heavily cached)
+			r.With(rt.limit("public")).Get("/stats/public", rt.statsHandler.GetPublic)
+
+			// Copyright takedown notices (no account required)
+			r.With(rt.limit("public")).Post("/copyright/claims", rt.copyrightHandler.FileClaim)
 
-	if path != "/" && path[len(path)-1] != '/' {
-		r.Get(path, http.RedirectHandler(path+"/", 301).ServeHTTP)
-		path += "/"
+			// Public profiles for link previews and the web (no auth required)
+			r.Route("/public/users/{username}", func(r chi.Router) {
+				r.Use(rt.limit("public"), rt.limit("public_profile"))
+				r.Get("/", rt.publicProfile.GetProfile)
+				r.Get("/avatar", rt.publicProfile.GetAvatar)
+			})
+
+			// Protected routes
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.AuthMiddleware(rt.jwtManager, rt.sessionLookup))
+				r.Use(middleware.ImpersonationGuard(rt.supportHandler.service))
+				if rt.rateLimiter != nil {
+					r.Use(rt.rateLimiter.Middleware())
+				}
+				r.Use(middleware.RegionMiddleware(rt.regionHeader, rt.countryLookup))
+
+				// User routes
+				r.Get("/me", rt.authHandler.Me)
+				r.Delete("/me", rt.authHandler.DeleteAccount)
+				r.Get("/me/support-access", rt.supportHandler.GetAccess)
+				r.Post("/me/support-access", rt.supportHandler.GrantAccess)
+				r.Delete("/me/support-access", rt.supportHandler.RevokeAccess)
+				r.Get("/me/usage", rt.usageHandler.GetUsage)
+				r.Get("/me/security-events", rt.authHandler.GetSecurityEvents)
+				r.Get("/me/verification", rt.authHandler.GetVerification)
+				r.Post("/me/verification", rt.authHandler.RequestVerification)
+				r.Get("/me/recap", rt.recapHandler.GetLatest)
+				r.Get("/me/saved", rt.storyHandler.GetSavedStories)
+				r.Get("/me/activity", rt.activityHandler.GetActivity)
+				r.Get("/me/activity/unread-count", rt.activityHandler.GetUnreadCount)
+				r.Post("/me/activity/read", rt.activityHandler.MarkRead)
+				r.Get("/me/export", rt.exportHandler.GetExport)
+				r.Post("/me/export", rt.exportHandler.RequestExport)
+				r.Get("/me/campaigns", rt.campaignHandler.GetPreferences)
+				r.Put("/me/campaigns/{campaign}", rt.campaignHandler.SetOptOut)
+				r.Get("/me/places", rt.placeHandler.GetPlaces)
+				r.Post("/me/places", rt.placeHandler.CreatePlace)
+				r.Delete("/me/places/{placeId}", rt.placeHandler.DeletePlace)
+				r.Get("/me/cards", rt.cardHandler.GetCards)
+				r.Post("/me/cards/{cardId}/dismiss", rt.cardHandler.DismissCard)
+				r.Get("/me/strikes", rt.copyrightHandler.GetMyStrikes)
+				r.Post("/copyright/claims/{claimId}/counter-notice", rt.copyrightHandler.FileCounterNotice)
+				r.Get("/users/check-username", rt.authHandler.CheckUsername)
+				r.Get("/users/search", rt.authHandler.SearchUsers)
+				r.Get("/users/{userId}", rt.authHandler.GetProfile)
+				r.Post("/auth/logout-all", rt.authHandler.LogoutAll)
+				r.Get("/sessions", rt.authHandler.GetSessions)
+				r.Delete("/sessions/{id}", rt.authHandler.RevokeSession)
+				r.Put("/auth/password", rt.authHandler.UpdatePassword)
+				r.Put("/auth/email", rt.authHandler.UpdateEmail)
+				r.Put("/auth/profile", rt.authHandler.UpdateProfile)
+				r.Post("/auth/google/link", rt.authHandler.LinkGoogle)
+				r.Post("/auth/apple/link", rt.authHandler.LinkApple)
+				r.Post("/auth/resend-verification", rt.authHandler.ResendVerification)
+				r.Post("/auth/phone/verify", rt.authHandler.VerifyPhone)
+
+				// Story routes
+				r.Route("/stories", func(r chi.Router) {
+					r.With(rt.limit("story_upload")).Post("/", rt.storyHandler.CreateStory)
+					r.With(middleware.RequireFeature(rt.featureGate, domain.FeatureNearbyFeed)).Get("/feed", rt.storyHandler.GetFeed)
+					r.Get("/feed/connections", rt.storyHandler.GetConnectionsFeed)
+					r.With(middleware.RequireFeature(rt.featureGate, domain.FeatureNearbyStrangers)).Get("/feed/discovery", rt.storyHandler.GetDiscoveryFeed)
+					r.Post("/seen", rt.storyHandler.MarkSeen)
+					r.Post("/impressions", rt.storyHandler.RecordImpressions)
+					r.Post("/live/end", rt.storyHandler.EndLiveSession)
+					r.Post("/{storyId}/report", rt.moderationHandler.ReportStory)
+					r.Get("/{storyId}/comments", rt.commentHandler.GetComments)
+					r.With(rt.limit("comment")).Post("/{storyId}/comments", rt.commentHandler.AddComment)
+					r.Delete("/{storyId}/comments/{commentId}", rt.commentHandler.DeleteComment)
+					r.Post("/{storyId}/comments/{commentId}/report", rt.moderationHandler.ReportComment)
+					r.Put("/{storyId}/comment-settings", rt.commentHandler.SetCommentSettings)
+					r.Put("/{storyId}/reaction", rt.commentHandler.React)
+					r.Delete("/{storyId}/reaction", rt.commentHandler.RemoveReaction)
+					r.Get("/{storyId}/reactions", rt.commentHandler.GetReactions)
+					r.With(rt.limit("story_reply")).Post("/{storyId}/replies", rt.chatHandler.ReplyToStory)
+					r.Get("/{storyId}/reach", rt.storyHandler.GetReach)
+					r.Post("/{storyId}/save", rt.storyHandler.SaveStory)
+					r.Delete("/{storyId}/save", rt.storyHandler.UnsaveStory)
+				})
+
+				// Shared story collections
+				r.Route("/collections", func(r chi.Router) {
+					r.Post("/", rt.collectionHandler.CreateCollection)
+					r.Get("/{collectionId}", rt.collectionHandler.GetCollection)
+					r.Get("/{collectionId}/stories", rt.collectionHandler.GetStories)
+					r.Post("/{collectionId}/stories", rt.collectionHandler.AddStory)
+					r.Delete("/{collectionId}/stories/{storyId}", rt.collectionHandler.RemoveStory)
+					r.Post("/{collectionId}/join", rt.collectionHandler.Join)
+					r.Get("/{collectionId}/contributors", rt.collectionHandler.GetContributors)
+					r.Post("/{collectionId}/contributors/{userId}/approve", rt.collectionHandler.ApproveContributor)
+					r.Post("/{collectionId}/contributors/{userId}/reject", rt.collectionHandler.RejectContributor)
+				})
+
+				// Long-poll fallback for clients that can't keep a WebSocket open
+				r.Get("/events/poll", rt.chatHandler.PollEvents)
+
+				// Chat routes
+				r.Route("/chats", func(r chi.Router) {
+					r.Post("/", rt.chatHandler.CreateChat)
+					r.Post("/groups", rt.chatHandler.CreateGroupChat)
+					r.Get("/", rt.chatHandler.GetChats)
+					r.Patch("/{chatId}", rt.chatHandler.UpdateGroupChat)
+					r.Post("/{chatId}/participants", rt.chatHandler.AddParticipants)
+					r.Delete("/{chatId}/participants/{userId}", rt.chatHandler.RemoveParticipant)
+					r.Put("/{chatId}/participants/{userId}/role", rt.chatHandler.SetParticipantRole)
+					r.Get("/{chatId}/messages", rt.chatHandler.GetMessages)
+					r.Get("/{chatId}/messages/search", rt.chatHandler.SearchMessages)
+					r.With(rt.limit(messageRateLimitRule)).Post("/{chatId}/messages", rt.chatHandler.SendMessage)
+					r.Delete("/{chatId}/messages", rt.chatHandler.ClearHistory)
+					r.Put("/{chatId}/retention", rt.chatHandler.SetRetention)
+					r.With(rt.limit(messageRateLimitRule)).Post("/{chatId}/share", rt.chatHandler.ShareStory)
+					r.With(rt.limit(messageRateLimitRule)).Post("/{chatId}/voice", rt.chatHandler.SendVoiceNote)
+					r.Post("/{chatId}/read", rt.chatHandler.MarkRead)
+					r.Post("/{chatId}/delivered", rt.chatHandler.MarkDelivered)
+					r.Post("/{chatId}/mute", rt.chatHandler.MuteChat)
+					r.Delete("/{chatId}/mute", rt.chatHandler.UnmuteChat)
+					r.Post("/{chatId}/archive", rt.chatHandler.ArchiveChat)
+					r.Delete("/{chatId}/archive", rt.chatHandler.UnarchiveChat)
+					r.Post("/{chatId}/pin", rt.chatHandler.PinChat)
+					r.Delete("/{chatId}/pin", rt.chatHandler.UnpinChat)
+					r.Put("/{chatId}/draft", rt.chatHandler.SaveDraft)
+					r.Get("/{chatId}/suggested-replies", rt.chatHandler.GetSuggestedReplies)
+					r.Post("/{chatId}/report", rt.moderationHandler.ReportChat)
+				})
+
+				// GraphQL gateway for the mobile client, when enabled
+				if rt.graphQL != nil {
+					r.Post("/graphql", rt.graphQL.ServeHTTP)
+				}
+
+				// Connection routes
+				r.Route("/connections", func(r chi.Router) {
+					r.Post("/request", rt.connectionHandler.SendRequest)
+					r.Post("/respond", rt.connectionHandler.RespondRequest)
+					r.Get("/", rt.connectionHandler.GetConnections)
+					r.Get("/requests", rt.connectionHandler.GetRequests)
+					r.Get("/status/{userId}", rt.connectionHandler.GetStatus)
+					r.Delete("/{userId}", rt.connectionHandler.RemoveConnection)
+					r.Post("/block", rt.connectionHandler.BlockUser)
+					r.Delete("/block/{userId}", rt.connectionHandler.UnblockUser)
+				})
+
+				// Waves at nearby users
+				r.Route("/waves", func(r chi.Router) {
+					r.With(rt.limit("wave")).Post("/", rt.waveHandler.Wave)
+					r.Get("/", rt.waveHandler.GetWaves)
+				})
+
+				// Notification routes
+				r.Route("/notifications", func(r chi.Router) {
+					r.Get("/", rt.notificationHandler.GetNotifications)
+					r.Put("/{id}/read", rt.notificationHandler.MarkRead)
+					r.Post("/{id}/opened", rt.notificationHandler.MarkOpened)
+					r.Post("/fcm-token", rt.notificationHandler.UpdateFCMToken)
+				})
+
+				// Admin routes. Moderators get the review queue, user lookup and
+				// story takedowns; everything else is admin-only.
+				r.Route("/admin", func(r chi.Router) {
+					r.Group(func(r chi.Router) {
+						r.Use(middleware.RequireRole(rt.roleLookup, rt.adminUserIDs, domain.RoleModerator, domain.RoleAdmin))
+
+						r.Get("/moderation", rt.adminHandler.ListModerationActions)
+						r.Post("/moderation/{actionId}/uphold", rt.adminHandler.UpholdModerationAction)
+						r.Post("/moderation/{actionId}/reverse", rt.adminHandler.ReverseModerationAction)
+						r.Get("/users", rt.adminHandler.LookupUser)
+						r.Get("/users/{userId}", rt.adminHandler.GetUser)
+						r.Post("/stories/{storyId}/takedown", rt.adminHandler.TakedownStory)
+						r.Get("/media/*", rt.mediaHandler.ServeAny)
+					})
+
+					r.Group(func(r chi.Router) {
+						r.Use(middleware.RequireAdmin(rt.roleLookup, rt.adminUserIDs))
+
+						r.Get("/story-archive", rt.adminHandler.ListArchivedStories)
+						r.Get("/story-archive/{storyId}", rt.adminHandler.GetArchivedStory)
+						r.Get("/campaigns/stats", rt.adminHandler.GetCampaignStats)
+						r.Get("/jobs", rt.adminHandler.ListJobs)
+						r.Get("/jobs/{job}/runs", rt.adminHandler.ListJobRuns)
+						r.Get("/metrics/clients", rt.sloHandler.GetClientBreakdown)
+						r.Get("/metrics/notifications", rt.notificationHandler.GetDeliveryStats)
+						r.Get("/legal-holds", rt.legalHoldHandler.ListHolds)
+						r.Post("/users/{userId}/legal-hold", rt.legalHoldHandler.PlaceHold)
+						r.Delete("/legal-holds/{holdId}", rt.legalHoldHandler.ReleaseHold)
+						r.Get("/legal-holds/{holdId}/snapshot", rt.legalHoldHandler.GetSnapshot)
+						r.Get("/audit-log", rt.legalHoldHandler.GetAuditLog)
+						r.Get("/users/{userId}/security-events", rt.adminHandler.GetSecurityEvents)
+						r.Get("/users/{userId}/timeline", rt.adminHandler.GetUserTimeline)
+						r.Patch("/users/{userId}/pii", rt.privacyHandler.RectifyUser)
+						r.Post("/users/{userId}/pseudonymize", rt.privacyHandler.PseudonymizeUser)
+						r.Post("/users/{userId}/impersonate", rt.supportHandler.Impersonate)
+						r.Get("/remote-config", rt.appConfigHandler.ListRemoteConfig)
+						r.Get("/remote-config/history", rt.appConfigHandler.GetRemoteConfigHistory)
+						r.Put("/remote-config/{key}", rt.appConfigHandler.SetRemoteConfig)
+						r.Delete("/remote-config/{key}", rt.appConfigHandler.DeleteRemoteConfig)
+						r.Get("/cards", rt.cardHandler.ListCards)
+						r.Post("/cards", rt.cardHandler.CreateCard)
+						r.Delete("/cards/{cardId}", rt.cardHandler.DeactivateCard)
+						r.Get("/copyright-claims", rt.copyrightHandler.ListClaims)
+						r.Post("/copyright-claims/{claimId}/uphold", rt.copyrightHandler.UpholdClaim)
+						r.Post("/copyright-claims/{claimId}/reject", rt.copyrightHandler.RejectClaim)
+						r.Post("/copyright-claims/{claimId}/restore", rt.copyrightHandler.RestoreClaim)
+						r.Get("/users/{userId}/strikes", rt.copyrightHandler.GetUserStrikes)
+						r.Post("/users/{userId}/deactivate", rt.adminHandler.DeactivateUser)
+						r.Post("/users/{userId}/reactivate", rt.adminHandler.ReactivateUser)
+						r.Put("/users/{userId}/role", rt.adminHandler.SetUserRole)
+						r.Post("/users/{userId}/merge", rt.adminHandler.MergeAccounts)
+						r.Get("/verification-requests", rt.adminHandler.ListVerificationRequests)
+						r.Post("/verification-requests/{requestId}/approve", rt.adminHandler.ApproveVerification)
+						r.Post("/verification-requests/{requestId}/reject", rt.adminHandler.RejectVerification)
+						r.Delete("/users/{userId}/verification", rt.adminHandler.RevokeVerification)
+						r.Post("/notifications/broadcast", rt.adminHandler.Broadcast)
+					})
+				})
+			})
+		})
 	}
-	path += "*"
+}
 
-	r.Get(path, func(w http.ResponseWriter, r *http.Request) {
-		rctx := chi.RouteContext(r.Context())
-		pathPrefix := strings.TrimSuffix(rctx.RoutePattern(), "/*")
-		fs := http.StripPrefix(pathPrefix, http.FileServer(root))
-		fs.ServeHTTP(w, r)
-	})
+// messageRateLimitRule limits chat messages, however they're sent
+const messageRateLimitRule = "message"
+
+// limit applies the named rate limit rule when rate limiting is enabled
+func (rt *Router) limit(rule string) func(http.Handler) http.Handler {
+	if rt.rateLimiter == nil {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	return rt.rateLimiter.Rule(rule)
 }
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/locolive/backend/internal/slo"
+	"github.com/locolive/backend/pkg/response"
+)
+
+// SLOHandler exposes per-route-group SLO status
+type SLOHandler struct {
+	tracker *slo.Tracker
+}
+
+// NewSLOHandler creates a new SLO handler
+func NewSLOHandler(tracker *slo.Tracker) *SLOHandler {
+	return &SLOHandler{tracker: tracker}
+}
+
+// GetStatus returns the current burn rate and remaining error budget for each route group
+func (h *SLOHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	response.OK(w, h.tracker.Statuses())
+}
+
+// GetClientBreakdown returns the server error rate per client platform and
+// app version, flagging builds that error well above the rest
+func (h *SLOHandler) GetClientBreakdown(w http.ResponseWriter, r *http.Request) {
+	response.OK(w, h.tracker.ClientBreakdown())
+}
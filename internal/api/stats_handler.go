@@ -0,0 +1,58 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/pkg/response"
+	"go.uber.org/zap"
+)
+
+// StatsHandler serves public platform statistics
+type StatsHandler struct {
+	service *domain.StatsService
+	maxAge  int
+	logger  *zap.Logger
+}
+
+// NewStatsHandler creates a new stats handler. maxAge is the Cache-Control max-age in seconds.
+func NewStatsHandler(service *domain.StatsService, maxAge int, logger *zap.Logger) *StatsHandler {
+	return &StatsHandler{
+		service: service,
+		maxAge:  maxAge,
+		logger:  logger,
+	}
+}
+
+// GetPublic returns coarse platform numbers, with nearby numbers for ?lat=&lng=
+func (h *StatsHandler) GetPublic(w http.ResponseWriter, r *http.Request) {
+	var lat, lng *float64
+	if latStr := r.URL.Query().Get("lat"); latStr != "" {
+		val, err := strconv.ParseFloat(latStr, 64)
+		if err != nil || val < -90 || val > 90 {
+			response.BadRequest(w, "invalid lat")
+			return
+		}
+		lat = &val
+	}
+	if lngStr := r.URL.Query().Get("lng"); lngStr != "" {
+		val, err := strconv.ParseFloat(lngStr, 64)
+		if err != nil || val < -180 || val > 180 {
+			response.BadRequest(w, "invalid lng")
+			return
+		}
+		lng = &val
+	}
+
+	stats, err := h.service.GetPublicStats(r.Context(), lat, lng)
+	if err != nil {
+		h.logger.Error("failed to get public stats", zap.Error(err))
+		response.InternalError(w, "failed to fetch stats")
+		return
+	}
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", h.maxAge))
+	response.OK(w, stats)
+}
@@ -1,9 +1,14 @@
 package api
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"github.com/locolive/backend/internal/domain"
 	"github.com/locolive/backend/internal/middleware"
 	"github.com/locolive/backend/pkg/response"
@@ -12,12 +17,14 @@ import (
 
 type StoryHandler struct {
 	storyService *domain.StoryService
+	liveService  *domain.LiveService
 	logger       *zap.Logger
 }
 
-func NewStoryHandler(storyService *domain.StoryService, logger *zap.Logger) *StoryHandler {
+func NewStoryHandler(storyService *domain.StoryService, liveService *domain.LiveService, logger *zap.Logger) *StoryHandler {
 	return &StoryHandler{
 		storyService: storyService,
+		liveService:  liveService,
 		logger:       logger,
 	}
 }
@@ -61,31 +68,55 @@ func (h *StoryHandler) CreateStory(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// "approximate" snaps the shown location to a ~200m grid; omitted lets the
+	// server default to approximate for home-like spots
+	var fuzz *bool
+	switch r.FormValue("location_precision") {
+	case "":
+	case "exact":
+		fuzz = new(bool)
+	case "approximate":
+		v := true
+		fuzz = &v
+	default:
+		response.BadRequest(w, "location_precision must be exact or approximate")
+		return
+	}
+
 	params := domain.CreateStoryParams{
-		UserID:      userID,
-		MediaType:   mediaType,
-		Caption:     &caption,
-		LocationLat: lat,
-		LocationLng: lng,
+		UserID:       userID,
+		MediaType:    mediaType,
+		Caption:      &caption,
+		LocationLat:  lat,
+		LocationLng:  lng,
+		FuzzLocation: fuzz,
+		Live:         r.FormValue("live") == "true",
 	}
 
 	story, err := h.storyService.CreateStory(r.Context(), params, file, header.Filename, header.Header.Get("Content-Type"))
 	if err != nil {
+		if err == domain.ErrLiveRequiresLocation || errors.Is(err, domain.ErrCaptionTooLong) {
+			response.BadRequest(w, err.Error())
+			return
+		}
 		h.logger.Error("create story failed", zap.Error(err))
 		response.InternalError(w, "failed to create story")
 		return
 	}
 
+	h.liveService.PublishStory(story)
 	response.Created(w, story)
 }
 
 // GetFeed handles fetching the story feed
 func (h *StoryHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
-	pageStr := r.URL.Query().Get("page")
-	page, _ := strconv.Atoi(pageStr)
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
 
-	limitStr := r.URL.Query().Get("limit")
-	limit, _ := strconv.Atoi(limitStr)
+	page, limit, offset := pageParams(r, domain.FeedPageLimits)
 
 	var lat, lng, radius *float64
 	if latStr := r.URL.Query().Get("lat"); latStr != "" {
@@ -108,12 +139,281 @@ func (h *StoryHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
 		radius = &r
 	}
 
-	stories, err := h.storyService.GetFeed(r.Context(), page, limit, lat, lng, radius)
+	stories, err := h.storyService.GetFeed(r.Context(), userID, page, limit, lat, lng, radius)
 	if err != nil {
 		h.logger.Error("get feed failed", zap.Error(err))
 		response.InternalError(w, "failed to get feed")
 		return
 	}
 
-	response.OK(w, stories)
+	writeFeed(w, r, stories, page, limit, offset)
+}
+
+// EndLiveSession ends the user's live session, unpinning its stories
+func (h *StoryHandler) EndLiveSession(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	if err := h.liveService.EndSession(r.Context(), userID); err != nil {
+		if err == domain.ErrNoLiveSession {
+			response.NotFound(w, err.Error())
+			return
+		}
+		h.logger.Error("end live session failed", zap.Error(err))
+		response.InternalError(w, "failed to end live session")
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// GetConnectionsFeed returns stories from the user's connections, regardless of distance
+func (h *StoryHandler) GetConnectionsFeed(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	page, limit, offset := pageParams(r, domain.FeedPageLimits)
+
+	seen, ok := parseSeenMode(r)
+	if !ok {
+		response.BadRequest(w, "seen must be include, exclude or last")
+		return
+	}
+
+	stories, err := h.storyService.GetConnectionsFeed(r.Context(), userID, seen, page, limit)
+	if err != nil {
+		h.logger.Error("get connections feed failed", zap.Error(err))
+		response.InternalError(w, "failed to get feed")
+		return
+	}
+
+	writeFeed(w, r, stories, page, limit, offset)
+}
+
+// GetDiscoveryFeed returns nearby stories from public users the viewer isn't connected to
+func (h *StoryHandler) GetDiscoveryFeed(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	page, limit, offset := pageParams(r, domain.FeedPageLimits)
+
+	seen, ok := parseSeenMode(r)
+	if !ok {
+		response.BadRequest(w, "seen must be include, exclude or last")
+		return
+	}
+
+	lat, errLat := strconv.ParseFloat(r.URL.Query().Get("lat"), 64)
+	lng, errLng := strconv.ParseFloat(r.URL.Query().Get("lng"), 64)
+	if errLat != nil || errLng != nil {
+		response.BadRequest(w, "lat and lng are required")
+		return
+	}
+
+	radius := 5000.0
+	if radiusStr := r.URL.Query().Get("radius"); radiusStr != "" {
+		if val, err := strconv.ParseFloat(radiusStr, 64); err == nil {
+			radius = val
+		}
+	}
+
+	stories, err := h.storyService.GetDiscoveryFeed(r.Context(), userID, seen, page, limit, lat, lng, radius)
+	if err != nil {
+		h.logger.Error("get discovery feed failed", zap.Error(err))
+		response.InternalError(w, "failed to get feed")
+		return
+	}
+
+	writeFeed(w, r, stories, page, limit, offset)
+}
+
+// writeFeed sends a page of feed stories. API v1 clients get the bare list
+// with the paging in meta; v2 wraps it in the paginated envelope.
+func writeFeed(w http.ResponseWriter, r *http.Request, stories []*domain.Story, page, limit, offset int) {
+	if middleware.GetAPIVersion(r.Context()) < 2 {
+		response.List(w, stories, response.Meta{Limit: limit, Offset: offset})
+		return
+	}
+
+	if stories == nil {
+		stories = []*domain.Story{}
+	}
+	response.Paginated(w, stories, response.Page{
+		Number:  page,
+		Limit:   limit,
+		HasMore: len(stories) == limit,
+	})
+}
+
+// parseSeenMode reads the optional ?seen= feed parameter
+func parseSeenMode(r *http.Request) (domain.SeenMode, bool) {
+	switch mode := domain.SeenMode(r.URL.Query().Get("seen")); mode {
+	case "":
+		return domain.SeenInclude, true
+	case domain.SeenInclude, domain.SeenExclude, domain.SeenLast:
+		return mode, true
+	}
+	return "", false
+}
+
+// MarkSeenRequest lists stories the user has viewed
+type MarkSeenRequest struct {
+	StoryIDs []uuid.UUID `json:"story_ids"`
+}
+
+// MarkSeen records stories the user has viewed
+func (h *StoryHandler) MarkSeen(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	var req MarkSeenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	if err := h.storyService.MarkSeen(r.Context(), userID, req.StoryIDs); err != nil {
+		if err == domain.ErrTooManySeenStories {
+			response.BadRequest(w, fmt.Sprintf("at most %d story ids per request", domain.MaxSeenBatch))
+			return
+		}
+		h.logger.Error("mark stories seen failed", zap.Error(err))
+		response.InternalError(w, "failed to mark stories seen")
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// StoryImpressionsRequest lists stories the app rendered on screen
+type StoryImpressionsRequest struct {
+	StoryIDs []uuid.UUID `json:"story_ids"`
+}
+
+// RecordImpressions records a batch of stories the user's app rendered
+func (h *StoryHandler) RecordImpressions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	var req StoryImpressionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	if err := h.storyService.RecordImpressions(r.Context(), userID, req.StoryIDs); err != nil {
+		if err == domain.ErrTooManyImpressions {
+			response.BadRequest(w, fmt.Sprintf("at most %d story ids per request", domain.MaxImpressionBatch))
+			return
+		}
+		h.logger.Error("record story impressions failed", zap.Error(err))
+		response.InternalError(w, "failed to record impressions")
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// GetReach returns impressions and views of one of the user's stories
+func (h *StoryHandler) GetReach(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	storyID, err := uuid.Parse(chi.URLParam(r, "storyId"))
+	if err != nil {
+		response.BadRequest(w, "invalid story id")
+		return
+	}
+
+	reach, err := h.storyService.GetReach(r.Context(), userID, storyID)
+	if err != nil {
+		if err == domain.ErrStoryNotFound {
+			response.NotFound(w, "story not found")
+			return
+		}
+		h.logger.Error("get story reach failed", zap.Error(err))
+		response.InternalError(w, "failed to get story reach")
+		return
+	}
+
+	response.OK(w, reach)
+}
+
+// SaveStory handles POST /stories/{storyId}/save
+func (h *StoryHandler) SaveStory(w http.ResponseWriter, r *http.Request) {
+	h.setSaved(w, r, true)
+}
+
+// UnsaveStory handles DELETE /stories/{storyId}/save
+func (h *StoryHandler) UnsaveStory(w http.ResponseWriter, r *http.Request) {
+	h.setSaved(w, r, false)
+}
+
+func (h *StoryHandler) setSaved(w http.ResponseWriter, r *http.Request, saved bool) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	storyID, err := uuid.Parse(chi.URLParam(r, "storyId"))
+	if err != nil {
+		response.BadRequest(w, "invalid story id")
+		return
+	}
+
+	if saved {
+		err = h.storyService.SaveStory(r.Context(), userID, storyID)
+	} else {
+		err = h.storyService.UnsaveStory(r.Context(), userID, storyID)
+	}
+	if err != nil {
+		if err == domain.ErrStoryNotFound {
+			response.NotFound(w, "story not found")
+			return
+		}
+		h.logger.Error("update saved story failed", zap.Error(err))
+		response.InternalError(w, "failed to update saved story")
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// GetSavedStories handles GET /me/saved
+func (h *StoryHandler) GetSavedStories(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	limit, offset := listParams(r, domain.FeedPageLimits)
+
+	stories, err := h.storyService.GetSavedStories(r.Context(), userID, limit, offset)
+	if err != nil {
+		h.logger.Error("get saved stories failed", zap.Error(err))
+		response.InternalError(w, "failed to get saved stories")
+		return
+	}
+
+	response.List(w, stories, response.Meta{Limit: limit, Offset: offset})
 }
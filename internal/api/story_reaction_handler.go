@@ -0,0 +1,105 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/middleware"
+	"github.com/locolive/backend/pkg/response"
+	"go.uber.org/zap"
+)
+
+// ReactRequest is the emoji to react to a story with
+type ReactRequest struct {
+	Emoji string `json:"emoji"`
+}
+
+// React handles PUT /stories/{storyId}/reaction, replacing any earlier reaction
+func (h *CommentHandler) React(w http.ResponseWriter, r *http.Request) {
+	userID, storyID, ok := storyRequestParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req ReactRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	reaction, err := h.service.React(r.Context(), userID, storyID, req.Emoji)
+	if err != nil {
+		h.writeReactionError(w, err, "failed to react to story")
+		return
+	}
+
+	response.OK(w, reaction)
+}
+
+// RemoveReaction handles DELETE /stories/{storyId}/reaction
+func (h *CommentHandler) RemoveReaction(w http.ResponseWriter, r *http.Request) {
+	userID, storyID, ok := storyRequestParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoveReaction(r.Context(), userID, storyID); err != nil {
+		h.writeReactionError(w, err, "failed to remove reaction")
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// GetReactions handles GET /stories/{storyId}/reactions, for the story's author
+func (h *CommentHandler) GetReactions(w http.ResponseWriter, r *http.Request) {
+	userID, storyID, ok := storyRequestParams(w, r)
+	if !ok {
+		return
+	}
+
+	limit, offset := listParams(r, domain.ReactionPageLimits)
+
+	reactions, err := h.service.GetReactions(r.Context(), userID, storyID, limit, offset)
+	if err != nil {
+		h.writeReactionError(w, err, "failed to get reactions")
+		return
+	}
+
+	response.List(w, reactions, response.Meta{Limit: limit, Offset: offset})
+}
+
+// storyRequestParams reads the authenticated user and the story in the path,
+// writing the error response if either is missing
+func storyRequestParams(w http.ResponseWriter, r *http.Request) (userID, storyID uuid.UUID, ok bool) {
+	userID, ok = middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	storyID, err := uuid.Parse(chi.URLParam(r, "storyId"))
+	if err != nil {
+		response.BadRequest(w, "invalid story id")
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, storyID, true
+}
+
+func (h *CommentHandler) writeReactionError(w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, domain.ErrStoryNotFound), errors.Is(err, domain.ErrReactionNotFound):
+		response.NotFound(w, err.Error())
+	case errors.Is(err, domain.ErrNotStoryOwner):
+		response.Forbidden(w, err.Error())
+	case errors.Is(err, domain.ErrInvalidReaction), errors.Is(err, domain.ErrCannotReactOwn):
+		response.BadRequest(w, err.Error())
+	default:
+		h.logger.Error(msg, zap.Error(err))
+		response.InternalError(w, msg)
+	}
+}
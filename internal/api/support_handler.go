@@ -0,0 +1,139 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/middleware"
+	"github.com/locolive/backend/pkg/response"
+	"go.uber.org/zap"
+)
+
+// SupportHandler handles support access consent and admin impersonation
+type SupportHandler struct {
+	service *domain.SupportAccessService
+	logger  *zap.Logger
+}
+
+// NewSupportHandler creates a new support handler
+func NewSupportHandler(service *domain.SupportAccessService, logger *zap.Logger) *SupportHandler {
+	return &SupportHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetAccess returns the user's active support access grant
+func (h *SupportHandler) GetAccess(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	grant, err := h.service.GetAccess(r.Context(), userID)
+	if err != nil {
+		if err == domain.ErrSupportConsentRequired {
+			response.NotFound(w, "support access is not granted")
+			return
+		}
+		h.logger.Error("get support access failed", zap.Error(err))
+		response.InternalError(w, "failed to get support access")
+		return
+	}
+
+	response.OK(w, grant)
+}
+
+// GrantAccess lets support agents view the user's account for
+// duration_hours (default and maximum set by config)
+func (h *SupportHandler) GrantAccess(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	var req struct {
+		DurationHours int `json:"duration_hours"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.BadRequest(w, "invalid request body")
+			return
+		}
+	}
+
+	grant, err := h.service.GrantAccess(r.Context(), userID, time.Duration(req.DurationHours)*time.Hour)
+	if err != nil {
+		if err == domain.ErrInvalidSupportDuration {
+			response.BadRequest(w, err.Error())
+			return
+		}
+		h.logger.Error("grant support access failed", zap.Error(err))
+		response.InternalError(w, "failed to grant support access")
+		return
+	}
+
+	response.Created(w, grant)
+}
+
+// RevokeAccess withdraws the user's support access consent
+func (h *SupportHandler) RevokeAccess(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	if err := h.service.RevokeAccess(r.Context(), userID); err != nil {
+		h.logger.Error("revoke support access failed", zap.Error(err))
+		response.InternalError(w, "failed to revoke support access")
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// Impersonate issues a read-only token to view a consenting user's account
+func (h *SupportHandler) Impersonate(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		response.BadRequest(w, "invalid user id")
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	token, err := h.service.Impersonate(r.Context(), adminID, userID, req.Reason)
+	if err != nil {
+		switch err {
+		case domain.ErrSupportReasonRequired:
+			response.BadRequest(w, err.Error())
+		case domain.ErrSupportConsentRequired:
+			response.Error(w, http.StatusForbidden, "SUPPORT_CONSENT_REQUIRED", err.Error())
+		default:
+			h.logger.Error("impersonate user failed", zap.Error(err))
+			response.InternalError(w, "failed to impersonate user")
+		}
+		return
+	}
+
+	response.Created(w, token)
+}
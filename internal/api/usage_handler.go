@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/locolive/backend/internal/middleware"
+	"github.com/locolive/backend/internal/ratelimit"
+	"github.com/locolive/backend/pkg/response"
+	"go.uber.org/zap"
+)
+
+// UsageHandler exposes a user's API usage and fair-use limits
+type UsageHandler struct {
+	limiter *ratelimit.Limiter
+	logger  *zap.Logger
+}
+
+// NewUsageHandler creates a new usage handler. limiter is nil when throttling is disabled.
+func NewUsageHandler(limiter *ratelimit.Limiter, logger *zap.Logger) *UsageHandler {
+	return &UsageHandler{
+		limiter: limiter,
+		logger:  logger,
+	}
+}
+
+// GetUsage returns the authenticated user's request volume and limits
+func (h *UsageHandler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	if h.limiter == nil {
+		response.Error(w, http.StatusServiceUnavailable, "UNAVAILABLE", "usage tracking is disabled")
+		return
+	}
+
+	usage, err := h.limiter.Usage(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("get usage failed", zap.Error(err))
+		response.InternalError(w, "failed to get usage")
+		return
+	}
+
+	response.OK(w, usage)
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/middleware"
+	"github.com/locolive/backend/pkg/response"
+	"go.uber.org/zap"
+)
+
+// WaveHandler sends and lists waves between nearby users
+type WaveHandler struct {
+	service *domain.WaveService
+	logger  *zap.Logger
+}
+
+// NewWaveHandler creates a new wave handler
+func NewWaveHandler(service *domain.WaveService, logger *zap.Logger) *WaveHandler {
+	return &WaveHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Wave handles POST /waves
+func (h *WaveHandler) Wave(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	var req struct {
+		TargetUserID string  `json:"target_user_id"`
+		Lat          float64 `json:"lat"`
+		Lng          float64 `json:"lng"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request")
+		return
+	}
+
+	targetID, err := uuid.Parse(req.TargetUserID)
+	if err != nil {
+		response.BadRequest(w, "invalid target user id")
+		return
+	}
+
+	result, err := h.service.Wave(r.Context(), userID, targetID, req.Lat, req.Lng)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrCannotWaveSelf), errors.Is(err, domain.ErrInvalidWaveLocation):
+			response.BadRequest(w, err.Error())
+		case errors.Is(err, domain.ErrWaveTargetNotFound):
+			response.NotFound(w, err.Error())
+		case errors.Is(err, domain.ErrWaveTargetNotNearby), errors.Is(err, domain.ErrConnectionBlocked):
+			response.Forbidden(w, err.Error())
+		case errors.Is(err, domain.ErrAlreadyConnected):
+			response.Conflict(w, err.Error())
+		case errors.Is(err, domain.ErrWaveTooSoon):
+			response.TooManyRequests(w, err.Error())
+		default:
+			h.logger.Error("failed to send wave", zap.Error(err))
+			response.InternalError(w, "failed to send wave")
+		}
+		return
+	}
+
+	response.Created(w, result)
+}
+
+// GetWaves handles GET /waves, listing waves the user can still wave back at
+func (h *WaveHandler) GetWaves(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	limit, offset := listParams(r, domain.WavePageLimits)
+
+	waves, err := h.service.GetReceivedWaves(r.Context(), userID, limit, offset)
+	if err != nil {
+		h.logger.Error("failed to get waves", zap.Error(err))
+		response.InternalError(w, "failed to get waves")
+		return
+	}
+
+	response.List(w, waves, response.Meta{Limit: limit, Offset: offset})
+}
@@ -1,15 +1,28 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/ratelimit"
 	"go.uber.org/zap"
 )
 
+// wsRequestTimeout bounds the work done for one client->server message
+const wsRequestTimeout = 10 * time.Second
+
+// wsMaxMessageSize caps a client->server frame. The largest is a
+// send_message at the maximum message length, with room for its envelope.
+const wsMaxMessageSize = 64 << 10
+
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
@@ -23,6 +36,20 @@ type Client struct {
 	Conn   *websocket.Conn
 	Send   chan []byte
 	UserID uuid.UUID
+	// chatService stores messages the client sends over the socket
+	chatService *domain.ChatService
+	// rateLimiter counts those messages like the HTTP routes that send them;
+	// nil when rate limiting is off
+	rateLimiter *ratelimit.Limiter
+	// area is the circle watched for live story events; guarded by the manager's mu
+	area *wsArea
+}
+
+// wsArea is a circle a client subscribes to with a subscribe_area message
+type wsArea struct {
+	Lat    float64 `json:"lat"`
+	Lng    float64 `json:"lng"`
+	Radius float64 `json:"radius"`
 }
 
 type WebSocketManager struct {
@@ -33,7 +60,9 @@ type WebSocketManager struct {
 	// Map userID to list of active clients (for multi-device support)
 	userClients map[uuid.UUID]map[*Client]bool
 	mu          sync.RWMutex
-	logger      *zap.Logger
+	// events keeps user events for long-polling clients
+	events *eventLogs
+	logger *zap.Logger
 }
 
 func NewWebSocketManager(logger *zap.Logger) *WebSocketManager {
@@ -43,13 +72,20 @@ func NewWebSocketManager(logger *zap.Logger) *WebSocketManager {
 		unregister:  make(chan *Client),
 		broadcast:   make(chan []byte),
 		userClients: make(map[uuid.UUID]map[*Client]bool),
+		events:      newEventLogs(),
 		logger:      logger,
 	}
 }
 
 func (m *WebSocketManager) Run() {
+	prune := time.NewTicker(time.Minute)
+	defer prune.Stop()
+
 	for {
 		select {
+		case <-prune.C:
+			m.events.prune()
+
 		case client := <-m.register:
 			m.mu.Lock()
 			m.clients[client] = true
@@ -91,76 +127,354 @@ func (m *WebSocketManager) Run() {
 	}
 }
 
-// SendToUser sends a message to a specific user's connected clients
-func (m *WebSocketManager) SendToUser(userID uuid.UUID, message interface{}) {
+// SendToUser sends a message to a specific user's connected clients and to
+// their long-poll event log. It reports whether any connected client took it.
+func (m *WebSocketManager) SendToUser(userID uuid.UUID, message interface{}) bool {
+	return m.sendToUser(userID, message, nil)
+}
+
+// sendToUser is SendToUser, skipping the client except if it's one of the user's
+func (m *WebSocketManager) sendToUser(userID uuid.UUID, message interface{}, except *Client) bool {
+	jsonMsg, err := json.Marshal(message)
+	if err != nil {
+		m.logger.Error("Failed to marshal message", zap.Error(err))
+		return false
+	}
+	m.events.append(userID, jsonMsg)
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
 	clients, ok := m.userClients[userID]
 	if !ok {
-		return
+		return false
 	}
 
-	jsonMsg, err := json.Marshal(message)
+	sent := false
+	for client := range clients {
+		if client == except {
+			continue
+		}
+		select {
+		case client.Send <- jsonMsg:
+			sent = true
+		default:
+			// If buffer is full, we assume client is dead/slow and unregister via loop check
+			// Ideally we don't block here
+		}
+	}
+	return sent
+}
+
+// BroadcastToArea sends an event to every client watching an area that
+// contains the point
+func (m *WebSocketManager) BroadcastToArea(lat, lng float64, event string, payload interface{}) {
+	jsonMsg, err := json.Marshal(WSEvent{Type: event, Payload: payload})
 	if err != nil {
 		m.logger.Error("Failed to marshal message", zap.Error(err))
 		return
 	}
 
-	for client := range clients {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for client := range m.clients {
+		area := client.area
+		if area == nil || domain.HaversineKm(lat, lng, area.Lat, area.Lng)*1000 > area.Radius {
+			continue
+		}
 		select {
 		case client.Send <- jsonMsg:
 		default:
-			// If buffer is full, we assume client is dead/slow and unregister via loop check
-			// Ideally we don't block here
 		}
 	}
 }
 
+// broadcastMessage sends a new_message event to every participant of the
+// message's chat, including the sender's other devices. origin is the client
+// that sent it over the WebSocket, if any; it has an ack instead. Recipients
+// with an open WebSocket have it delivered there and then.
+func (m *WebSocketManager) broadcastMessage(ctx context.Context, chats *domain.ChatService, msg *domain.Message, origin *Client) {
+	chat, err := chats.GetChat(ctx, msg.ChatID, msg.SenderID)
+	if err != nil {
+		m.logger.Warn("failed to load chat for new_message", zap.Error(err))
+		return
+	}
+	event := WSEvent{Type: "new_message", Payload: msg}
+	var pushed []uuid.UUID
+	for _, u := range chat.Users {
+		if m.sendToUser(u.ID, event, origin) && u.ID != msg.SenderID {
+			pushed = append(pushed, u.ID)
+		}
+	}
+
+	updates, err := chats.MessagePushed(ctx, msg, pushed)
+	if err != nil {
+		m.logger.Warn("failed to mark message delivered", zap.Error(err))
+		return
+	}
+	m.sendDeliveryUpdates(updates)
+}
+
+// sendDeliveryUpdates tells senders about their messages' delivery with
+// delivery_update events
+func (m *WebSocketManager) sendDeliveryUpdates(updates []*domain.DeliveryUpdate) {
+	for _, update := range updates {
+		m.SendToUser(update.SenderID, WSEvent{Type: "delivery_update", Payload: update})
+	}
+}
+
+// reply queues an event for this client alone. It never blocks, so a client
+// that stops reading loses replies instead of stalling its read loop.
+func (m *WebSocketManager) reply(client *Client, event WSEvent) {
+	jsonMsg, err := json.Marshal(event)
+	if err != nil {
+		m.logger.Error("Failed to marshal message", zap.Error(err))
+		return
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.clients[client] {
+		return
+	}
+	select {
+	case client.Send <- jsonMsg:
+	default:
+	}
+}
+
+// setArea replaces the area a client watches; nil unsubscribes
+func (m *WebSocketManager) setArea(client *Client, area *wsArea) {
+	m.mu.Lock()
+	client.area = area
+	m.mu.Unlock()
+}
+
 // WebSocket Event types
 type WSEvent struct {
 	Type    string      `json:"type"`
 	Payload interface{} `json:"payload"`
 }
 
+// wsSendMessage is the payload of a send_message. Ref is picked by the client
+// and echoed in the ack or error so it can match replies to its sends.
+type wsSendMessage struct {
+	Ref     string    `json:"ref"`
+	ChatID  uuid.UUID `json:"chat_id"`
+	Content string    `json:"content"`
+}
+
+// wsShareStory is the payload of a share_story
+type wsShareStory struct {
+	Ref     string    `json:"ref"`
+	ChatID  uuid.UUID `json:"chat_id"`
+	StoryID uuid.UUID `json:"story_id"`
+	Content string    `json:"content"`
+}
+
+// wsAck tells the client a send_message was stored
+type wsAck struct {
+	Ref       string    `json:"ref"`
+	MessageID uuid.UUID `json:"message_id"`
+	ChatID    uuid.UUID `json:"chat_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// wsError tells the client a message it sent was refused. Codes are the
+// ones HTTP error responses use.
+type wsError struct {
+	Ref     string `json:"ref,omitempty"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// wsDelivery is the payload of a client's ack of a new_message, and of the
+// message_delivered event it becomes for the other participants
+type wsDelivery struct {
+	ChatID    uuid.UUID `json:"chat_id"`
+	MessageID uuid.UUID `json:"message_id"`
+	UserID    uuid.UUID `json:"user_id"`
+}
+
 func (c *Client) ReadPump(manager *WebSocketManager) {
 	defer func() {
 		manager.unregister <- c
 		c.Conn.Close()
 	}()
 
+	// Larger frames close the connection
+	c.Conn.SetReadLimit(wsMaxMessageSize)
 	for {
-		_, _, err := c.Conn.ReadMessage()
+		_, data, err := c.Conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				// log error
 			}
 			break
 		}
-		// For now, we only push data server->client.
-		// If we want client->server via WS, handle messages here.
+		c.handleMessage(manager, data)
 	}
 }
 
-func (c *Client) WritePump() {
-	defer func() {
-		c.Conn.Close()
-	}()
+// handleMessage applies a client->server message. Unknown messages are
+// ignored, as are malformed ones except send_message and share_story, which
+// get an error.
+func (c *Client) handleMessage(manager *WebSocketManager, data []byte) {
+	var msg struct {
+		Type    string          `json:"type"`
+		Payload json.RawMessage `json:"payload"`
+	}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return
+	}
 
-	for message := range c.Send {
-		w, err := c.Conn.NextWriter(websocket.TextMessage)
-		if err != nil {
+	switch msg.Type {
+	case "subscribe_area":
+		var area wsArea
+		if err := json.Unmarshal(msg.Payload, &area); err != nil {
+			return
+		}
+		if area.Lat < -90 || area.Lat > 90 || area.Lng < -180 || area.Lng > 180 {
 			return
 		}
-		w.Write(message)
+		if area.Radius <= 0 {
+			area.Radius = domain.LiveAreaDefaultRadiusMeters
+		}
+		area.Radius = math.Min(math.Max(area.Radius, domain.MinFeedRadiusMeters), domain.LiveAreaMaxRadiusMeters)
+		manager.setArea(c, &area)
+	case "unsubscribe_area":
+		manager.setArea(c, nil)
+	case "send_message":
+		c.sendMessage(manager, msg.Payload)
+	case "share_story":
+		c.shareStory(manager, msg.Payload)
+	case "ack":
+		c.ackMessage(manager, msg.Payload)
+	case "ping":
+		manager.reply(c, WSEvent{Type: "pong"})
+	}
+}
+
+// sendMessage stores a send_message like POST /chats/{chatId}/messages and
+// answers with an ack carrying the message ID, or an error
+func (c *Client) sendMessage(manager *WebSocketManager, payload json.RawMessage) {
+	var req wsSendMessage
+	if err := json.Unmarshal(payload, &req); err != nil || req.ChatID == uuid.Nil {
+		manager.reply(c, WSEvent{Type: "error", Payload: wsError{Ref: req.Ref, Code: "BAD_REQUEST", Message: "invalid send_message"}})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), wsRequestTimeout)
+	defer cancel()
+	if !c.allow(ctx, manager, req.Ref) {
+		return
+	}
+
+	msg, err := c.chatService.SendMessage(ctx, req.ChatID, c.UserID, req.Content)
+	c.replySent(ctx, manager, req.Ref, msg, err)
+}
+
+// shareStory shares a story like POST /chats/{chatId}/share, answering like
+// send_message
+func (c *Client) shareStory(manager *WebSocketManager, payload json.RawMessage) {
+	var req wsShareStory
+	if err := json.Unmarshal(payload, &req); err != nil || req.ChatID == uuid.Nil || req.StoryID == uuid.Nil {
+		manager.reply(c, WSEvent{Type: "error", Payload: wsError{Ref: req.Ref, Code: "BAD_REQUEST", Message: "invalid share_story"}})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), wsRequestTimeout)
+	defer cancel()
+	if !c.allow(ctx, manager, req.Ref) {
+		return
+	}
+
+	msg, err := c.chatService.ShareStory(ctx, req.ChatID, c.UserID, req.StoryID, req.Content)
+	c.replySent(ctx, manager, req.Ref, msg, err)
+}
+
+// allow counts a message the client sends against the same limits as the
+// HTTP routes for sending messages, answering with a RATE_LIMITED error once
+// they're exceeded
+func (c *Client) allow(ctx context.Context, manager *WebSocketManager, ref string) bool {
+	if c.rateLimiter == nil || c.rateLimiter.Allow(ctx, c.UserID, messageRateLimitRule) {
+		return true
+	}
+	manager.reply(c, WSEvent{Type: "error", Payload: wsError{Ref: ref, Code: "RATE_LIMITED", Message: "too many requests, try again later"}})
+	return false
+}
+
+// replySent acks a stored message and broadcasts it, or reports why it wasn't stored
+func (c *Client) replySent(ctx context.Context, manager *WebSocketManager, ref string, msg *domain.Message, err error) {
+	if err != nil {
+		code := "INTERNAL_ERROR"
+		switch {
+		case errors.Is(err, domain.ErrChatNotFound), errors.Is(err, domain.ErrStoryNotFound):
+			code = "NOT_FOUND"
+		case errors.Is(err, domain.ErrChatFrozen), errors.Is(err, domain.ErrNotChatParticipant), errors.Is(err, domain.ErrStoryNotShareable):
+			code = "FORBIDDEN"
+		case errors.Is(err, domain.ErrMessageTooLong), errors.Is(err, domain.ErrEmptyMessage):
+			code = "BAD_REQUEST"
+		default:
+			manager.logger.Error("failed to send message over websocket", zap.Error(err))
+			err = errors.New("failed to send message")
+		}
+		manager.reply(c, WSEvent{Type: "error", Payload: wsError{Ref: ref, Code: code, Message: err.Error()}})
+		return
+	}
+
+	manager.reply(c, WSEvent{Type: "ack", Payload: wsAck{
+		Ref:       ref,
+		MessageID: msg.ID,
+		ChatID:    msg.ChatID,
+		CreatedAt: msg.CreatedAt,
+	}})
+	manager.broadcastMessage(ctx, c.chatService, msg, c)
+}
+
+// ackMessage records a client's receipt of a new_message as delivered,
+// telling its sender with a delivery_update, and passes it on to the chat's
+// other participants as message_delivered. Acks for chats the client isn't
+// in are ignored.
+func (c *Client) ackMessage(manager *WebSocketManager, payload json.RawMessage) {
+	var delivery wsDelivery
+	if err := json.Unmarshal(payload, &delivery); err != nil || delivery.ChatID == uuid.Nil || delivery.MessageID == uuid.Nil {
+		return
+	}
+	delivery.UserID = c.UserID
 
-		// Add queued chat messages to the current websocket message.
-		n := len(c.Send)
-		for i := 0; i < n; i++ {
-			w.Write(<-c.Send)
+	ctx, cancel := context.WithTimeout(context.Background(), wsRequestTimeout)
+	defer cancel()
+
+	chat, err := c.chatService.GetChat(ctx, delivery.ChatID, c.UserID)
+	if err != nil {
+		return
+	}
+
+	updates, err := c.chatService.MarkDelivered(ctx, delivery.ChatID, c.UserID, []uuid.UUID{delivery.MessageID})
+	if err != nil {
+		manager.logger.Warn("failed to mark message delivered", zap.Error(err))
+	}
+	manager.sendDeliveryUpdates(updates)
+
+	event := WSEvent{Type: "message_delivered", Payload: delivery}
+	for _, u := range chat.Users {
+		if u.ID != c.UserID {
+			manager.SendToUser(u.ID, event)
 		}
+	}
+}
+
+func (c *Client) WritePump() {
+	defer func() {
+		c.Conn.Close()
+	}()
 
-		if err := w.Close(); err != nil {
+	// One event per frame, so each frame decodes as a single JSON value
+	for message := range c.Send {
+		if err := c.Conn.WriteMessage(websocket.TextMessage, message); err != nil {
 			return
 		}
 	}
@@ -0,0 +1,340 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/locolive/backend/internal/auth"
+	"github.com/locolive/backend/internal/cache"
+	"github.com/locolive/backend/internal/config"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/metrics"
+	"github.com/locolive/backend/internal/ratelimit"
+	"github.com/locolive/backend/internal/slo"
+	"go.uber.org/zap"
+)
+
+// wsChatRepo holds one direct chat in memory, enough for messages sent over
+// the WebSocket. Everyone has it muted, so no push notification is sent.
+type wsChatRepo struct {
+	domain.ChatRepository
+	chat *domain.Chat
+}
+
+func (r *wsChatRepo) IsChatParticipant(ctx context.Context, chatID, userID uuid.UUID) (bool, error) {
+	if chatID != r.chat.ID {
+		return false, domain.ErrChatNotFound
+	}
+	for _, u := range r.chat.Users {
+		if u.ID == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *wsChatRepo) GetChatByID(ctx context.Context, chatID uuid.UUID) (*domain.Chat, error) {
+	return r.chat, nil
+}
+
+func (r *wsChatRepo) CreateMessage(ctx context.Context, chatID, senderID uuid.UUID, content string) (*domain.Message, error) {
+	return &domain.Message{
+		ID:        uuid.New(),
+		ChatID:    chatID,
+		SenderID:  senderID,
+		Kind:      domain.MessageKindText,
+		Content:   content,
+		Status:    domain.MessageStatusSent,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+func (r *wsChatRepo) GetMutedChatParticipants(ctx context.Context, chatID uuid.UUID) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, len(r.chat.Users))
+	for i, u := range r.chat.Users {
+		ids[i] = u.ID
+	}
+	return ids, nil
+}
+
+func (r *wsChatRepo) MarkMessageDeliveredTo(ctx context.Context, messageID uuid.UUID, userIDs []uuid.UUID) ([]*domain.DeliveryUpdate, error) {
+	return nil, nil
+}
+
+type wsTestServer struct {
+	server *httptest.Server
+	jwt    *auth.JWTManager
+	chat   *domain.Chat
+}
+
+// newWSTestServer serves the full router, global middleware included, over
+// a real listener so that upgrades hijack a real connection. limiter may be
+// nil to send without limits.
+func newWSTestServer(t *testing.T, limiter *ratelimit.Limiter) *wsTestServer {
+	t.Helper()
+
+	chat := &domain.Chat{
+		ID:    uuid.New(),
+		Users: []*domain.UserResponse{{ID: uuid.New(), Name: "Asha"}, {ID: uuid.New(), Name: "Ravi"}},
+	}
+	logger := zap.NewNop()
+	chatService := domain.NewChatService(&wsChatRepo{chat: chat}, nil, nil, nil, nil,
+		domain.TextPolicy{MaxMessageLength: 1000}, domain.VoiceNotePolicy{})
+	wsManager := NewWebSocketManager(logger)
+	go wsManager.Run()
+
+	jwtManager := auth.NewJWTManager("test-secret", nil, time.Time{}, time.Hour, time.Hour, 0)
+	rt := &Router{
+		chatHandler: NewChatHandler(chatService, wsManager, limiter, logger),
+		// Setup reads the support service for the impersonation guard
+		supportHandler: &SupportHandler{},
+		jwtManager:     jwtManager,
+		metrics:        metrics.NewRegistry(),
+		sloTracker:     slo.NewTracker(config.SLOConfig{}, nil, logger),
+		logger:         logger,
+	}
+
+	server := httptest.NewServer(rt.Setup())
+	t.Cleanup(server.Close)
+	return &wsTestServer{server: server, jwt: jwtManager, chat: chat}
+}
+
+func (s *wsTestServer) dial(t *testing.T, userID uuid.UUID) *websocket.Conn {
+	t.Helper()
+
+	token, err := s.jwt.GenerateAccessToken(userID, uuid.Nil, "", "")
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	header := http.Header{"Authorization": {"Bearer " + token}}
+	url := "ws" + strings.TrimPrefix(s.server.URL, "http") + "/ws/chat"
+
+	conn, resp, err := websocket.DefaultDialer.Dial(url, header)
+	if err != nil {
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		t.Fatalf("upgrade failed with status %d: %v", status, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+type wsTestEvent struct {
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// nextEvent reads the next frame, which must hold exactly one event
+func nextEvent(t *testing.T, conn *websocket.Conn) wsTestEvent {
+	t.Helper()
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("read frame: %v", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var event wsTestEvent
+	if err := dec.Decode(&event); err != nil {
+		t.Fatalf("decode frame %q: %v", data, err)
+	}
+	if err := dec.Decode(&json.RawMessage{}); err != io.EOF {
+		t.Fatalf("frame %q holds more than one event", data)
+	}
+	return event
+}
+
+// readEvent returns the next event of the given type, skipping others
+func readEvent(t *testing.T, conn *websocket.Conn, eventType string) json.RawMessage {
+	t.Helper()
+
+	for {
+		if event := nextEvent(t, conn); event.Type == eventType {
+			return event.Payload
+		}
+	}
+}
+
+// readUntilPong pings and returns the types of the events received before the pong
+func readUntilPong(t *testing.T, conn *websocket.Conn) []string {
+	t.Helper()
+
+	if err := conn.WriteJSON(map[string]string{"type": "ping"}); err != nil {
+		t.Fatalf("write ping: %v", err)
+	}
+	var types []string
+	for {
+		event := nextEvent(t, conn)
+		if event.Type == "pong" {
+			return types
+		}
+		types = append(types, event.Type)
+	}
+}
+
+func TestWebSocketUpgradeThroughRouter(t *testing.T) {
+	s := newWSTestServer(t, nil)
+	conn := s.dial(t, s.chat.Users[0].ID)
+
+	if err := conn.WriteJSON(map[string]string{"type": "ping"}); err != nil {
+		t.Fatalf("write ping: %v", err)
+	}
+	readEvent(t, conn, "pong")
+}
+
+func TestWebSocketUpgradeRequiresAuth(t *testing.T) {
+	s := newWSTestServer(t, nil)
+	url := "ws" + strings.TrimPrefix(s.server.URL, "http") + "/ws/chat"
+
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	if err == nil {
+		t.Fatal("upgrade without a token succeeded")
+	}
+	if resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("got %v, want 401", resp)
+	}
+}
+
+func TestWebSocketSendMessage(t *testing.T) {
+	s := newWSTestServer(t, nil)
+	sender, recipient := s.chat.Users[0], s.chat.Users[1]
+	senderConn := s.dial(t, sender.ID)
+	otherDevice := s.dial(t, sender.ID)
+	recipientConn := s.dial(t, recipient.ID)
+
+	// All clients must be registered before the message is broadcast
+	for _, conn := range []*websocket.Conn{senderConn, otherDevice, recipientConn} {
+		readUntilPong(t, conn)
+	}
+
+	err := senderConn.WriteJSON(map[string]interface{}{
+		"type":    "send_message",
+		"payload": map[string]interface{}{"ref": "r1", "chat_id": s.chat.ID, "content": "hello"},
+	})
+	if err != nil {
+		t.Fatalf("write send_message: %v", err)
+	}
+
+	var ack wsAck
+	if err := json.Unmarshal(readEvent(t, senderConn, "ack"), &ack); err != nil {
+		t.Fatalf("decode ack: %v", err)
+	}
+	if ack.Ref != "r1" || ack.ChatID != s.chat.ID || ack.MessageID == uuid.Nil {
+		t.Fatalf("unexpected ack %+v", ack)
+	}
+	// The sending connection has its ack and isn't sent the message back
+	if types := readUntilPong(t, senderConn); len(types) > 0 {
+		t.Fatalf("sender got %v after its ack", types)
+	}
+
+	for _, conn := range []*websocket.Conn{recipientConn, otherDevice} {
+		var msg domain.Message
+		if err := json.Unmarshal(readEvent(t, conn, "new_message"), &msg); err != nil {
+			t.Fatalf("decode new_message: %v", err)
+		}
+		if msg.ID != ack.MessageID || msg.SenderID != sender.ID || msg.Content != "hello" {
+			t.Fatalf("unexpected new_message %+v", msg)
+		}
+	}
+}
+
+func TestWebSocketSendMessageErrors(t *testing.T) {
+	s := newWSTestServer(t, nil)
+	conn := s.dial(t, s.chat.Users[0].ID)
+
+	tests := []struct {
+		name    string
+		payload map[string]interface{}
+		code    string
+	}{
+		{"missing chat", map[string]interface{}{"ref": "r1", "content": "hello"}, "BAD_REQUEST"},
+		{"empty content", map[string]interface{}{"ref": "r2", "chat_id": s.chat.ID, "content": "  "}, "BAD_REQUEST"},
+		{"unknown chat", map[string]interface{}{"ref": "r3", "chat_id": uuid.New(), "content": "hello"}, "NOT_FOUND"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := conn.WriteJSON(map[string]interface{}{"type": "send_message", "payload": tt.payload}); err != nil {
+				t.Fatalf("write send_message: %v", err)
+			}
+			var got wsError
+			if err := json.Unmarshal(readEvent(t, conn, "error"), &got); err != nil {
+				t.Fatalf("decode error: %v", err)
+			}
+			if got.Ref != tt.payload["ref"] || got.Code != tt.code {
+				t.Fatalf("got %+v, want ref %v code %s", got, tt.payload["ref"], tt.code)
+			}
+		})
+	}
+}
+
+// wsUnknownUsers fails every user lookup, so the limiter uses the new-account tier
+type wsUnknownUsers struct {
+	domain.AuthRepository
+}
+
+func (wsUnknownUsers) GetUserByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	return nil, domain.ErrUserNotFound
+}
+
+func TestWebSocketSendMessageRateLimited(t *testing.T) {
+	cfg := config.RateLimitConfig{
+		NewPerMinute: 100,
+		Rules:        map[string]config.RateLimitRule{messageRateLimitRule: {Limit: 2, Window: time.Minute}},
+	}
+	limiter := ratelimit.NewLimiter(cache.NewMemoryCache(100), wsUnknownUsers{}, cfg, metrics.NewRegistry(), zap.NewNop())
+	s := newWSTestServer(t, limiter)
+	conn := s.dial(t, s.chat.Users[0].ID)
+
+	for i, want := range []string{"ack", "ack", "error"} {
+		err := conn.WriteJSON(map[string]interface{}{
+			"type":    "send_message",
+			"payload": map[string]interface{}{"ref": strconv.Itoa(i), "chat_id": s.chat.ID, "content": "hello"},
+		})
+		if err != nil {
+			t.Fatalf("write send_message: %v", err)
+		}
+		event := nextEvent(t, conn)
+		if event.Type != want {
+			t.Fatalf("message %d: got %s, want %s", i, event.Type, want)
+		}
+		if want == "error" {
+			var got wsError
+			if err := json.Unmarshal(event.Payload, &got); err != nil || got.Code != "RATE_LIMITED" {
+				t.Fatalf("got %s, want RATE_LIMITED", event.Payload)
+			}
+		}
+	}
+}
+
+func TestWebSocketReadLimit(t *testing.T) {
+	s := newWSTestServer(t, nil)
+	conn := s.dial(t, s.chat.Users[0].ID)
+
+	big := map[string]interface{}{
+		"type":    "send_message",
+		"payload": map[string]interface{}{"chat_id": s.chat.ID, "content": strings.Repeat("a", wsMaxMessageSize)},
+	}
+	if err := conn.WriteJSON(big); err != nil {
+		t.Fatalf("write send_message: %v", err)
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, data, err := conn.ReadMessage()
+	if err == nil {
+		t.Fatalf("got %s, want the connection closed", data)
+	}
+	if !websocket.IsCloseError(err, websocket.CloseMessageTooBig) {
+		t.Fatalf("got %v, want close 1009", err)
+	}
+}
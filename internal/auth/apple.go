@@ -0,0 +1,230 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	appleIssuer  = "https://appleid.apple.com"
+	appleKeysURL = "https://appleid.apple.com/auth/keys"
+
+	// appleKeysTTL is how long Apple's signing keys are cached
+	appleKeysTTL = 24 * time.Hour
+	// appleKeysMinRefresh limits refetches triggered by unknown key IDs
+	appleKeysMinRefresh = time.Minute
+)
+
+var (
+	ErrInvalidAppleToken = errors.New("invalid Apple identity token")
+	ErrAppleEmailMissing = errors.New("email not found in Apple token")
+)
+
+// AppleUser represents the user info from an Apple identity token. Apple
+// never puts the user's name in the token; the app sends it separately on
+// the first sign-in.
+type AppleUser struct {
+	AppleID       string
+	Email         string
+	EmailVerified bool
+	// IsPrivateEmail is set for Hide My Email relay addresses
+	IsPrivateEmail bool
+}
+
+// appleClaims are the identity token claims we read. Apple has sent the
+// boolean claims both as JSON booleans and as "true"/"false" strings.
+type appleClaims struct {
+	Email          string          `json:"email"`
+	EmailVerified  json.RawMessage `json:"email_verified"`
+	IsPrivateEmail json.RawMessage `json:"is_private_email"`
+	Nonce          string          `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+// AppleAuthVerifier handles Apple identity token verification
+type AppleAuthVerifier struct {
+	clientIDs []string
+	client    *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+// NewAppleAuthVerifier creates a new Apple auth verifier. clientIDs are the
+// app's bundle ID and any Services IDs used for web sign-in.
+func NewAppleAuthVerifier(clientIDs []string) *AppleAuthVerifier {
+	return &AppleAuthVerifier{
+		clientIDs: clientIDs,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// VerifyIDToken verifies an Apple identity token and returns the user info.
+// If the app passed a hashed nonce to Apple, rawNonce must be the value it
+// hashed; an empty rawNonce skips the check.
+func (v *AppleAuthVerifier) VerifyIDToken(ctx context.Context, idToken, rawNonce string) (*AppleUser, error) {
+	claims := &appleClaims{}
+	_, err := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return v.key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithIssuer(appleIssuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, ErrInvalidAppleToken
+	}
+
+	if !v.validAudience(claims.Audience) || claims.Subject == "" {
+		return nil, ErrInvalidAppleToken
+	}
+	if rawNonce != "" {
+		hashed := sha256.Sum256([]byte(rawNonce))
+		if claims.Nonce != hex.EncodeToString(hashed[:]) {
+			return nil, ErrInvalidAppleToken
+		}
+	}
+	if claims.Email == "" {
+		return nil, ErrAppleEmailMissing
+	}
+
+	return &AppleUser{
+		AppleID:        claims.Subject,
+		Email:          claims.Email,
+		EmailVerified:  appleBool(claims.EmailVerified),
+		IsPrivateEmail: appleBool(claims.IsPrivateEmail),
+	}, nil
+}
+
+// IsConfigured returns true if Apple Sign-In is configured
+func (v *AppleAuthVerifier) IsConfigured() bool {
+	return len(v.clientIDs) > 0 && v.clientIDs[0] != ""
+}
+
+func (v *AppleAuthVerifier) validAudience(audience jwt.ClaimStrings) bool {
+	for _, aud := range audience {
+		for _, clientID := range v.clientIDs {
+			if aud == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PrefetchKeys loads Apple's key set ahead of the first sign-in
+func (v *AppleAuthVerifier) PrefetchKeys(ctx context.Context) error {
+	keys, err := v.fetchKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.keys = keys
+	v.fetchedAt = time.Now()
+	return nil
+}
+
+// key returns Apple's public key kid, refetching the key set when it's stale
+// or doesn't contain kid because Apple rotated keys
+func (v *AppleAuthVerifier) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	key, ok := v.keys[kid]
+	stale := time.Since(v.fetchedAt) > appleKeysTTL
+	if ok && !stale {
+		return key, nil
+	}
+	if !stale && time.Since(v.fetchedAt) < appleKeysMinRefresh {
+		return nil, ErrInvalidAppleToken
+	}
+
+	keys, err := v.fetchKeys(ctx)
+	if err != nil {
+		// Keep verifying with the keys we have if Apple is unreachable
+		if ok {
+			return key, nil
+		}
+		return nil, err
+	}
+	v.keys = keys
+	v.fetchedAt = time.Now()
+
+	if key, ok = v.keys[kid]; !ok {
+		return nil, ErrInvalidAppleToken
+	}
+	return key, nil
+}
+
+func (v *AppleAuthVerifier) fetchKeys(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, appleKeysURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Apple keys: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch Apple keys: status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode Apple keys: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	return keys, nil
+}
+
+// appleBool reads a claim sent either as a JSON boolean or as a string
+func appleBool(raw json.RawMessage) bool {
+	var b bool
+	if json.Unmarshal(raw, &b) == nil {
+		return b
+	}
+	var s string
+	return json.Unmarshal(raw, &s) == nil && s == "true"
+}
@@ -13,6 +13,9 @@ import (
 var (
 	ErrInvalidToken = errors.New("invalid token")
 	ErrExpiredToken = errors.New("token has expired")
+	// ErrTokenNotYetValid means the token's nbf or iat is ahead of our clock
+	// by more than the allowed skew
+	ErrTokenNotYetValid = errors.New("token is not valid yet")
 )
 
 // TokenType distinguishes between access and refresh tokens
@@ -28,35 +31,56 @@ type Claims struct {
 	UserID    uuid.UUID `json:"user_id"`
 	SessionID uuid.UUID `json:"session_id,omitempty"`
 	Email     string    `json:"email,omitempty"`
+	// Region is the country the session signed in from
+	Region    string    `json:"region,omitempty"`
 	TokenType TokenType `json:"token_type"`
+	// ImpersonatorID is the support agent acting as UserID under GrantID;
+	// such tokens are read-only
+	ImpersonatorID *uuid.UUID `json:"impersonator_id,omitempty"`
+	GrantID        *uuid.UUID `json:"grant_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// JWTManager handles JWT operations
+// JWTManager handles JWT operations. Tokens are signed with HS256 and the
+// shared secret unless asymmetric keys are configured, in which case the
+// first key signs and the rest only verify, so keys can be rotated.
 type JWTManager struct {
-	secret        []byte
+	secret []byte
+	keys   []*SigningKey
+	// secretUntil is when tokens signed with the secret stop verifying once
+	// keys are configured; zero keeps accepting them
+	secretUntil   time.Time
 	accessExpiry  time.Duration
 	refreshExpiry time.Duration
-	issuer        string
+	// leeway is the clock skew tolerated on exp, nbf and iat
+	leeway time.Duration
+	issuer string
 }
 
-// NewJWTManager creates a new JWT manager
-func NewJWTManager(secret string, accessExpiry, refreshExpiry time.Duration) *JWTManager {
+// NewJWTManager creates a new JWT manager. leeway absorbs clock differences
+// between the servers that issue and validate tokens. keys may be empty to
+// sign with the secret; otherwise the secret only verifies older tokens,
+// and only until secretUntil if that is set.
+func NewJWTManager(secret string, keys []*SigningKey, secretUntil time.Time, accessExpiry, refreshExpiry, leeway time.Duration) *JWTManager {
 	return &JWTManager{
 		secret:        []byte(secret),
+		keys:          keys,
+		secretUntil:   secretUntil,
 		accessExpiry:  accessExpiry,
 		refreshExpiry: refreshExpiry,
+		leeway:        leeway,
 		issuer:        "locolive",
 	}
 }
 
 // GenerateAccessToken creates a new access token
-func (m *JWTManager) GenerateAccessToken(userID, sessionID uuid.UUID, email string) (string, error) {
+func (m *JWTManager) GenerateAccessToken(userID, sessionID uuid.UUID, email, region string) (string, error) {
 	now := time.Now()
 	claims := &Claims{
 		UserID:    userID,
 		SessionID: sessionID,
 		Email:     email,
+		Region:    region,
 		TokenType: AccessToken,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(now.Add(m.accessExpiry)),
@@ -67,8 +91,28 @@ func (m *JWTManager) GenerateAccessToken(userID, sessionID uuid.UUID, email stri
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(m.secret)
+	return m.sign(claims)
+}
+
+// GenerateImpersonationToken creates an access token for a support agent to
+// act as userID until expiresAt. It has no session or refresh token.
+func (m *JWTManager) GenerateImpersonationToken(userID, agentID, grantID uuid.UUID, expiresAt time.Time) (string, error) {
+	now := time.Now()
+	claims := &Claims{
+		UserID:         userID,
+		TokenType:      AccessToken,
+		ImpersonatorID: &agentID,
+		GrantID:        &grantID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    m.issuer,
+			Subject:   userID.String(),
+		},
+	}
+
+	return m.sign(claims)
 }
 
 // GenerateRefreshToken creates a new refresh token
@@ -88,24 +132,70 @@ func (m *JWTManager) GenerateRefreshToken(userID uuid.UUID) (string, time.Time,
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signed, err := token.SignedString(m.secret)
+	signed, err := m.sign(claims)
 	return signed, expiresAt, err
 }
 
-// ValidateToken validates a JWT and returns the claims
-func (m *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+// sign signs claims with the active key
+func (m *JWTManager) sign(claims *Claims) (string, error) {
+	if len(m.keys) == 0 {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.secret)
+	}
+	key := m.keys[0]
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
+}
+
+// verificationKey picks the key a token must be signed with: the asymmetric
+// key named by its kid, or the shared secret for tokens without one while
+// it's still accepted. The token's alg has to match the key, so a public key
+// can't be used as an HMAC secret.
+func (m *JWTManager) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, ErrInvalidToken
 		}
+		if len(m.keys) > 0 && !m.secretUntil.IsZero() && time.Now().After(m.secretUntil) {
+			return nil, ErrInvalidToken
+		}
 		return m.secret, nil
-	})
+	}
+
+	for _, key := range m.keys {
+		if key.ID == kid {
+			if token.Method.Alg() != key.Method.Alg() {
+				return nil, ErrInvalidToken
+			}
+			return key.Public(), nil
+		}
+	}
+	return nil, ErrInvalidToken
+}
+
+// JWKS returns the public keys that verify tokens, for other services
+func (m *JWTManager) JWKS() JWKSet {
+	set := JWKSet{Keys: make([]JWK, 0, len(m.keys))}
+	for _, key := range m.keys {
+		if jwk, err := publicJWK(key); err == nil {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	return set
+}
+
+// ValidateToken validates a JWT and returns the claims
+func (m *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, m.verificationKey, jwt.WithLeeway(m.leeway), jwt.WithIssuedAt())
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
 			return nil, ErrExpiredToken
 		}
+		if errors.Is(err, jwt.ErrTokenNotValidYet) || errors.Is(err, jwt.ErrTokenUsedBeforeIssued) {
+			return nil, ErrTokenNotYetValid
+		}
 		return nil, ErrInvalidToken
 	}
 
@@ -162,8 +252,8 @@ type TokenPair struct {
 }
 
 // GenerateTokenPair creates both access and refresh tokens
-func (m *JWTManager) GenerateTokenPair(userID, sessionID uuid.UUID, email string) (*TokenPair, error) {
-	accessToken, err := m.GenerateAccessToken(userID, sessionID, email)
+func (m *JWTManager) GenerateTokenPair(userID, sessionID uuid.UUID, email, region string) (*TokenPair, error) {
+	accessToken, err := m.GenerateAccessToken(userID, sessionID, email, region)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func TestSecretSignedTokensAfterCutoff(t *testing.T) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	keys := []*SigningKey{{ID: "2026-10", Method: jwt.SigningMethodEdDSA, Private: private}}
+	legacy, err := NewJWTManager("secret", nil, time.Time{}, time.Hour, time.Hour, 0).GenerateAccessToken(uuid.New(), uuid.New(), "", "")
+	if err != nil {
+		t.Fatalf("sign legacy token: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		secretUntil time.Time
+		valid       bool
+	}{
+		{"no cutoff", time.Time{}, true},
+		{"before cutoff", time.Now().Add(time.Hour), true},
+		{"after cutoff", time.Now().Add(-time.Hour), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewJWTManager("secret", keys, tt.secretUntil, time.Hour, time.Hour, 0)
+			_, err := m.ValidateAccessToken(legacy)
+			if tt.valid && err != nil {
+				t.Fatalf("got %v, want the secret-signed token accepted", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("got %v, want ErrInvalidToken", err)
+			}
+
+			// Tokens signed with the key are unaffected
+			current, err := m.GenerateAccessToken(uuid.New(), uuid.New(), "", "")
+			if err != nil {
+				t.Fatalf("sign token: %v", err)
+			}
+			if _, err := m.ValidateAccessToken(current); err != nil {
+				t.Fatalf("got %v for a key-signed token", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrMiss is returned by Get when the key does not exist
+var ErrMiss = errors.New("cache miss")
+
+// Cache is a byte-oriented key/value store with per-key expiry
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// SetStore holds sets of strings with expiry, for membership checks
+type SetStore interface {
+	// ReplaceSet stores members, which may be none, as the set at key
+	ReplaceSet(ctx context.Context, key string, members []string, ttl time.Duration) error
+	// AreMembers reports which of members are in the set at key, or returns
+	// ErrMiss if there is no set there
+	AreMembers(ctx context.Context, key string, members []string) ([]bool, error)
+}
+
+// Counter is an integer counter store with expiry, used for rate limiting and usage tracking
+type Counter interface {
+	// Incr increments key and sets ttl when the key is created
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+	// GetInt returns the value of key, or 0 if it does not exist
+	GetInt(ctx context.Context, key string) (int64, error)
+}
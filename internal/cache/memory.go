@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MemoryCache is an in-process Cache for single-instance deployments or when
+// Redis isn't configured. Expired entries are dropped lazily on access and
+// whenever the cache grows past maxEntries.
+type MemoryCache struct {
+	mu         sync.Mutex
+	entries    map[string]memoryEntry
+	maxEntries int
+}
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewMemoryCache creates an in-process cache holding at most maxEntries keys
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		entries:    make(map[string]memoryEntry),
+		maxEntries: maxEntries,
+	}
+}
+
+func (c *MemoryCache) Get(ctx context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, ErrMiss
+	}
+	if time.Now().After(e.expiresAt) {
+		delete(c.entries, key)
+		return nil, ErrMiss
+	}
+	return e.value, nil
+}
+
+func (c *MemoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= c.maxEntries {
+		c.evict()
+	}
+	c.entries[key] = memoryEntry{value: value, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+func (c *MemoryCache) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+	return nil
+}
+
+// Incr increments key and sets ttl when the key is created
+func (c *MemoryCache) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	e, ok := c.entries[key]
+	var n int64
+	if ok && now.Before(e.expiresAt) {
+		n, _ = strconv.ParseInt(string(e.value), 10, 64)
+	} else {
+		if len(c.entries) >= c.maxEntries {
+			c.evict()
+		}
+		e = memoryEntry{expiresAt: now.Add(ttl)}
+	}
+	n++
+	e.value = []byte(strconv.FormatInt(n, 10))
+	c.entries[key] = e
+	return n, nil
+}
+
+// GetInt returns the value of key, or 0 if it does not exist
+func (c *MemoryCache) GetInt(ctx context.Context, key string) (int64, error) {
+	value, err := c.Get(ctx, key)
+	if errors.Is(err, ErrMiss) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(string(value), 10, 64)
+}
+
+// evict drops expired entries, or everything if none had expired. Callers hold mu.
+func (c *MemoryCache) evict() {
+	now := time.Now()
+	for key, e := range c.entries {
+		if now.After(e.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+	if len(c.entries) >= c.maxEntries {
+		c.entries = make(map[string]memoryEntry)
+	}
+}
@@ -0,0 +1,331 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRedisTimeout  = 3 * time.Second
+	defaultRedisPoolSize = 10
+)
+
+// RedisError is an error reply returned by the Redis server
+type RedisError string
+
+func (e RedisError) Error() string { return string(e) }
+
+// RedisClient is a minimal pooled Redis client speaking RESP2
+type RedisClient struct {
+	addr     string
+	password string
+	db       int
+	timeout  time.Duration
+	pool     chan *redisConn
+}
+
+type redisConn struct {
+	conn net.Conn
+	rd   *bufio.Reader
+}
+
+// NewRedisClient creates a client from a URL of the form redis://[:password@]host:port[/db].
+// Connections are opened lazily.
+func NewRedisClient(rawURL string, poolSize int) (*RedisClient, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis URL: %w", err)
+	}
+	if u.Scheme != "redis" {
+		return nil, fmt.Errorf("invalid redis URL scheme %q", u.Scheme)
+	}
+
+	c := &RedisClient{
+		addr:    u.Host,
+		timeout: defaultRedisTimeout,
+	}
+	if u.Port() == "" {
+		c.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		c.password, _ = u.User.Password()
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		if c.db, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("invalid redis database %q", db)
+		}
+	}
+
+	if poolSize <= 0 {
+		poolSize = defaultRedisPoolSize
+	}
+	c.pool = make(chan *redisConn, poolSize)
+	return c, nil
+}
+
+// Do sends a single command and returns its reply: string for simple strings,
+// int64 for integers, []byte for bulk strings, []interface{} for arrays and nil
+// for null replies. Server error replies are returned as RedisError.
+func (c *RedisClient) Do(ctx context.Context, args ...string) (interface{}, error) {
+	rc, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := rc.roundTrip(ctx, c.timeout, args)
+	var redisErr RedisError
+	if err != nil && !errors.As(err, &redisErr) {
+		// The connection is in an unknown state after an I/O error
+		rc.conn.Close()
+		return nil, err
+	}
+	c.put(rc)
+	return reply, err
+}
+
+// Get implements Cache
+func (c *RedisClient) Get(ctx context.Context, key string) ([]byte, error) {
+	reply, err := c.Do(ctx, "GET", key)
+	if err != nil {
+		return nil, err
+	}
+	if reply == nil {
+		return nil, ErrMiss
+	}
+	value, ok := reply.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("unexpected redis reply %T", reply)
+	}
+	return value, nil
+}
+
+// Set implements Cache. A zero ttl stores the key without expiry.
+func (c *RedisClient) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	args := []string{"SET", key, string(value)}
+	if ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	}
+	_, err := c.Do(ctx, args...)
+	return err
+}
+
+// Delete implements Cache
+func (c *RedisClient) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	_, err := c.Do(ctx, append([]string{"DEL"}, keys...)...)
+	return err
+}
+
+// Incr implements Counter
+func (c *RedisClient) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	reply, err := c.Do(ctx, "INCR", key)
+	if err != nil {
+		return 0, err
+	}
+	n, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected redis reply %T", reply)
+	}
+	// Only the first increment in a window sets the expiry
+	if n == 1 && ttl > 0 {
+		if _, err := c.Do(ctx, "PEXPIRE", key, strconv.FormatInt(ttl.Milliseconds(), 10)); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// setSentinel is kept in every set, so an empty set still exists and can be
+// told apart from a missing one
+const setSentinel = ""
+
+// replaceSetScript swaps in a new set atomically: ARGV[1] is the expiry in
+// milliseconds and the rest are the members
+const replaceSetScript = `
+redis.call('DEL', KEYS[1])
+for i = 2, #ARGV do
+	redis.call('SADD', KEYS[1], ARGV[i])
+end
+redis.call('PEXPIRE', KEYS[1], ARGV[1])
+return 1
+`
+
+// ReplaceSet implements SetStore
+func (c *RedisClient) ReplaceSet(ctx context.Context, key string, members []string, ttl time.Duration) error {
+	args := append([]string{"EVAL", replaceSetScript, "1", key, strconv.FormatInt(ttl.Milliseconds(), 10), setSentinel}, members...)
+	_, err := c.Do(ctx, args...)
+	return err
+}
+
+// AreMembers implements SetStore with SMISMEMBER (Redis 6.2+), asking for the
+// sentinel first to learn whether the set exists
+func (c *RedisClient) AreMembers(ctx context.Context, key string, members []string) ([]bool, error) {
+	reply, err := c.Do(ctx, append([]string{"SMISMEMBER", key, setSentinel}, members...)...)
+	if err != nil {
+		return nil, err
+	}
+	flags, ok := reply.([]interface{})
+	if !ok || len(flags) != len(members)+1 {
+		return nil, fmt.Errorf("unexpected redis reply %T", reply)
+	}
+	if flags[0] != int64(1) {
+		return nil, ErrMiss
+	}
+	found := make([]bool, len(members))
+	for i, flag := range flags[1:] {
+		found[i] = flag == int64(1)
+	}
+	return found, nil
+}
+
+// GetInt implements Counter
+func (c *RedisClient) GetInt(ctx context.Context, key string) (int64, error) {
+	value, err := c.Get(ctx, key)
+	if errors.Is(err, ErrMiss) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(string(value), 10, 64)
+}
+
+// Ping checks that the server is reachable
+func (c *RedisClient) Ping(ctx context.Context) error {
+	_, err := c.Do(ctx, "PING")
+	return err
+}
+
+// Close closes all idle connections
+func (c *RedisClient) Close() {
+	for {
+		select {
+		case rc := <-c.pool:
+			rc.conn.Close()
+		default:
+			return
+		}
+	}
+}
+
+func (c *RedisClient) get(ctx context.Context) (*redisConn, error) {
+	select {
+	case rc := <-c.pool:
+		return rc, nil
+	default:
+	}
+
+	dialer := net.Dialer{Timeout: c.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	rc := &redisConn{conn: conn, rd: bufio.NewReader(conn)}
+
+	if c.password != "" {
+		if _, err := rc.roundTrip(ctx, c.timeout, []string{"AUTH", c.password}); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("redis auth failed: %w", err)
+		}
+	}
+	if c.db != 0 {
+		if _, err := rc.roundTrip(ctx, c.timeout, []string{"SELECT", strconv.Itoa(c.db)}); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("redis select failed: %w", err)
+		}
+	}
+	return rc, nil
+}
+
+func (c *RedisClient) put(rc *redisConn) {
+	select {
+	case c.pool <- rc:
+	default:
+		rc.conn.Close()
+	}
+}
+
+func (rc *redisConn) roundTrip(ctx context.Context, timeout time.Duration, args []string) (interface{}, error) {
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := rc.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(rc.conn, b.String()); err != nil {
+		return nil, err
+	}
+	return rc.readReply()
+}
+
+func (rc *redisConn) readReply() (interface{}, error) {
+	line, err := rc.rd.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("empty redis reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, RedisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(rc.rd, buf); err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			item, err := rc.readReply()
+			var redisErr RedisError
+			if err != nil && !errors.As(err, &redisErr) {
+				return nil, err
+			}
+			if err != nil {
+				item = err
+			}
+			items[i] = item
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("unknown redis reply type %q", line[0])
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AbuseSignalKind identifies the automated check that raised a signal
+type AbuseSignalKind string
+
+const (
+	AbuseSignalLocationSpoof AbuseSignalKind = "location_spoof"
+	// AbuseSignalContentTakedown is raised when an admin upholds a takedown of the user's content
+	AbuseSignalContentTakedown AbuseSignalKind = "content_takedown"
+	// AbuseSignalCopyrightStrike is raised when a copyright claim against the user's content is upheld
+	AbuseSignalCopyrightStrike AbuseSignalKind = "copyright_strike"
+	// AbuseSignalDuplicateMedia is raised when an uploaded image repeats a recent story's
+	AbuseSignalDuplicateMedia AbuseSignalKind = "duplicate_media"
+)
+
+// AbuseSignal is a weighted piece of evidence against a user. A user's abuse
+// score is the sum of their signal weights over a recent window.
+type AbuseSignal struct {
+	ID        uuid.UUID              `json:"id"`
+	UserID    uuid.UUID              `json:"user_id"`
+	Kind      AbuseSignalKind        `json:"kind"`
+	Weight    int                    `json:"weight"`
+	SubjectID *uuid.UUID             `json:"subject_id,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+type AbuseRepository interface {
+	RecordAbuseSignal(ctx context.Context, signal AbuseSignal) error
+	GetAbuseScore(ctx context.Context, userID uuid.UUID, since time.Time) (int, error)
+}
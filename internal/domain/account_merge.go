@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var ErrMergeSameUser = errors.New("can't merge an account into itself")
+
+// AccountMergeResult reports what merging one account into another moved.
+// Rows dropped were duplicates the merge resolved in favour of the other copy.
+type AccountMergeResult struct {
+	SourceUserID        uuid.UUID `json:"source_user_id"`
+	TargetUserID        uuid.UUID `json:"target_user_id"`
+	StoriesMoved        int64     `json:"stories_moved"`
+	CollectionsMoved    int64     `json:"collections_moved"`
+	ChatsMoved          int64     `json:"chats_moved"`
+	ChatsKeptAsHistory  int64     `json:"chats_kept_as_history"`
+	MessagesMoved       int64     `json:"messages_moved"`
+	ConnectionsMoved    int64     `json:"connections_moved"`
+	ConnectionsDropped  int64     `json:"connections_dropped"`
+	NotificationsMoved  int64     `json:"notifications_moved"`
+	IdentifiersMoved    []string  `json:"identifiers_moved"`
+	SourceSessionsEnded int64     `json:"source_sessions_ended"`
+}
+
+// AccountMergeRepository merges duplicate accounts. The merge and its audit
+// entry are written in one transaction.
+type AccountMergeRepository interface {
+	// MergeAccounts moves source's stories, chats, connections and
+	// notifications to target and deactivates source. Both must be active;
+	// it returns ErrUserOnLegalHold rather than move held data.
+	MergeAccounts(ctx context.Context, sourceID, targetID uuid.UUID, audit AuditEntry) (*AccountMergeResult, error)
+}
+
+// MergeAccounts folds a duplicate account into the one the user keeps, for
+// example an email sign-up into the account later created with Google under
+// another address. Where both accounts have something only one can keep:
+//   - A connection to the same person keeps the stronger status (blocked,
+//     then accepted, then pending); connections between the two are dropped.
+//   - A direct chat with the same person stays with target's; source's is
+//     kept alongside as history. Their chat with each other is deleted.
+//   - Email, phone, Google and Apple sign-ins move only where target has none,
+//     so the user can still sign in the way they did before.
+func (s *AdminService) MergeAccounts(ctx context.Context, adminID, sourceID, targetID uuid.UUID) (*AccountMergeResult, error) {
+	if sourceID == targetID {
+		return nil, ErrMergeSameUser
+	}
+	return s.repo.MergeAccounts(ctx, sourceID, targetID, AuditEntry{
+		ActorID:      adminID,
+		Action:       AuditAccountMerge,
+		TargetUserID: &sourceID,
+	})
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Activity kinds
+const (
+	ActivityProfileView = "profile_view"
+	ActivityStorySaved  = "story_saved"
+)
+
+// ActivityDedupeWindow folds repeats of the same activity by the same actor,
+// so someone opening a profile ten times in a day shows up once
+const ActivityDedupeWindow = 24 * time.Hour
+
+// Activity is an entry in a user's activity stream: something another user
+// did that is worth seeing but not worth a push notification
+type Activity struct {
+	ID   uuid.UUID `json:"id"`
+	Kind string    `json:"kind"`
+	// TargetID is what the activity is about, e.g. the saved story
+	TargetID  *uuid.UUID    `json:"target_id,omitempty"`
+	Actor     *UserResponse `json:"actor"`
+	IsRead    bool          `json:"is_read"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+type ActivityRepository interface {
+	// RecordActivity adds an entry unless the same one was recorded since the given time
+	RecordActivity(ctx context.Context, userID, actorID uuid.UUID, kind string, targetID *uuid.UUID, since time.Time) error
+	// GetActivities lists the user's activity newest first, leaving out
+	// actors who are inactive or blocked either way
+	GetActivities(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*Activity, error)
+	CountUnreadActivities(ctx context.Context, userID uuid.UUID) (int, error)
+	// MarkActivitiesRead marks all of the user's activity read, returning how many entries changed
+	MarkActivitiesRead(ctx context.Context, userID uuid.UUID) (int, error)
+}
+
+// ActivityService keeps the activity stream. Nothing in it is pushed.
+type ActivityService struct {
+	repo ActivityRepository
+}
+
+func NewActivityService(repo ActivityRepository) *ActivityService {
+	return &ActivityService{repo: repo}
+}
+
+// Record adds an entry to userID's stream in the background. Activity is
+// best effort: a failure is logged and never fails the action behind it.
+func (s *ActivityService) Record(userID, actorID uuid.UUID, kind string, targetID *uuid.UUID) {
+	if userID == actorID {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := s.repo.RecordActivity(ctx, userID, actorID, kind, targetID, time.Now().Add(-ActivityDedupeWindow)); err != nil {
+			log.Printf("activity: failed to record %s for %s: %v", kind, userID, err)
+		}
+	}()
+}
+
+// GetActivities lists the user's activity, newest first
+func (s *ActivityService) GetActivities(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*Activity, error) {
+	limit, offset = ActivityPageLimits.Clamp(limit, offset)
+	return s.repo.GetActivities(ctx, userID, limit, offset)
+}
+
+func (s *ActivityService) CountUnread(ctx context.Context, userID uuid.UUID) (int, error) {
+	return s.repo.CountUnreadActivities(ctx, userID)
+}
+
+// MarkAllRead marks the user's whole stream read
+func (s *ActivityService) MarkAllRead(ctx context.Context, userID uuid.UUID) (int, error) {
+	return s.repo.MarkActivitiesRead(ctx, userID)
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidRole       = errors.New("role must be user, moderator or admin")
+	ErrCannotChangeOwn   = errors.New("admins can't deactivate themselves or change their own role")
+	ErrAlreadyTakenDown  = errors.New("story already has an open or upheld takedown")
+	ErrInvalidBroadcast  = errors.New("title (max 100) and body (max 500) are required")
+	ErrLookupKeyRequired = errors.New("one of email, phone or username is required")
+)
+
+// Role is a user's operator role
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+// Valid reports whether r is a known role
+func (r Role) Valid() bool {
+	switch r {
+	case RoleUser, RoleModerator, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+// AdminUser is a user as operators see it, including deactivated accounts
+type AdminUser struct {
+	*UserResponse
+	Role      Role      `json:"role"`
+	IsActive  bool      `json:"is_active"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UserLookup finds a user by exactly one identifier
+type UserLookup struct {
+	Email    string
+	Phone    string
+	Username string
+}
+
+type AdminRepository interface {
+	AccountMergeRepository
+	SecurityEventRepository
+	VerificationRepository
+	UserTimelineRepository
+	JobRunRepository
+	GetUserRole(ctx context.Context, userID uuid.UUID) (Role, error)
+	// GetUserForAdmin returns a user whether or not they're active
+	GetUserForAdmin(ctx context.Context, userID uuid.UUID) (*User, Role, error)
+	FindUserForAdmin(ctx context.Context, lookup UserLookup) (*User, Role, error)
+	// SetUserActive deactivates or reactivates an account; deactivating also
+	// ends its sessions. The audit entry is recorded in the same transaction.
+	SetUserActive(ctx context.Context, userID uuid.UUID, active bool, audit AuditEntry) error
+	SetUserRole(ctx context.Context, userID uuid.UUID, role Role, audit AuditEntry) error
+	// GetActiveUserIDs pages through active users in ID order, after the given ID
+	GetActiveUserIDs(ctx context.Context, after uuid.UUID, limit int) ([]uuid.UUID, error)
+}
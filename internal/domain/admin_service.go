@@ -0,0 +1,190 @@
+package domain
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// broadcastBatchSize is how many users each broadcast page notifies
+const broadcastBatchSize = 1000
+
+// AdminService backs the operator endpoints. Every look at or change to a
+// user is recorded in the audit log.
+type AdminService struct {
+	repo         AdminRepository
+	audit        AuditRepository
+	moderation   *ModerationService
+	notifService *NotificationService
+}
+
+func NewAdminService(repo AdminRepository, audit AuditRepository, moderation *ModerationService, notifService *NotificationService) *AdminService {
+	return &AdminService{
+		repo:         repo,
+		audit:        audit,
+		moderation:   moderation,
+		notifService: notifService,
+	}
+}
+
+// GetUser returns a user, active or not
+func (s *AdminService) GetUser(ctx context.Context, adminID, userID uuid.UUID) (*AdminUser, error) {
+	user, role, err := s.repo.GetUserForAdmin(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.record(ctx, adminID, AuditUserView, &userID, nil); err != nil {
+		return nil, err
+	}
+	return toAdminUser(user, role), nil
+}
+
+// LookupUser finds a user by email, phone or username
+func (s *AdminService) LookupUser(ctx context.Context, adminID uuid.UUID, lookup UserLookup) (*AdminUser, error) {
+	lookup.Email = strings.ToLower(strings.TrimSpace(lookup.Email))
+	lookup.Phone = strings.TrimSpace(lookup.Phone)
+	lookup.Username = cleanUsername(lookup.Username)
+
+	set := 0
+	var by string
+	for key, value := range map[string]string{"email": lookup.Email, "phone": lookup.Phone, "username": lookup.Username} {
+		if value != "" {
+			set++
+			by = key
+		}
+	}
+	if set != 1 {
+		return nil, ErrLookupKeyRequired
+	}
+
+	user, role, err := s.repo.FindUserForAdmin(ctx, lookup)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.record(ctx, adminID, AuditUserView, &user.ID, map[string]interface{}{"lookup": by}); err != nil {
+		return nil, err
+	}
+	return toAdminUser(user, role), nil
+}
+
+// DeactivateUser disables an account and signs it out everywhere. Unlike
+// account deletion, nothing is removed and the account can be reactivated.
+func (s *AdminService) DeactivateUser(ctx context.Context, adminID, userID uuid.UUID, reason string) error {
+	if adminID == userID {
+		return ErrCannotChangeOwn
+	}
+	return s.repo.SetUserActive(ctx, userID, false, AuditEntry{
+		ActorID:      adminID,
+		Action:       AuditUserDeactivate,
+		TargetUserID: &userID,
+		Details:      map[string]interface{}{"reason": reason},
+	})
+}
+
+// ReactivateUser re-enables a deactivated account
+func (s *AdminService) ReactivateUser(ctx context.Context, adminID, userID uuid.UUID) error {
+	return s.repo.SetUserActive(ctx, userID, true, AuditEntry{
+		ActorID:      adminID,
+		Action:       AuditUserReactivate,
+		TargetUserID: &userID,
+	})
+}
+
+// SetRole grants or revokes an operator role
+func (s *AdminService) SetRole(ctx context.Context, adminID, userID uuid.UUID, role Role) error {
+	if !role.Valid() {
+		return ErrInvalidRole
+	}
+	if adminID == userID {
+		return ErrCannotChangeOwn
+	}
+	return s.repo.SetUserRole(ctx, userID, role, AuditEntry{
+		ActorID:      adminID,
+		Action:       AuditUserRoleChange,
+		TargetUserID: &userID,
+		Details:      map[string]interface{}{"role": role},
+	})
+}
+
+// TakedownStory hides a story and tells its author
+func (s *AdminService) TakedownStory(ctx context.Context, adminID, storyID uuid.UUID, reason string) (*ModerationAction, error) {
+	action, err := s.moderation.TakedownStory(ctx, adminID, storyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.record(ctx, adminID, AuditStoryTakedown, action.OwnerID, map[string]interface{}{
+		"story_id": storyID,
+		"reason":   reason,
+	}); err != nil {
+		return nil, err
+	}
+	return action, nil
+}
+
+// Broadcast notifies every active user. The fan-out runs in the background,
+// paced by the outbound push budget.
+func (s *AdminService) Broadcast(ctx context.Context, adminID uuid.UUID, title, body string) error {
+	title, body = strings.TrimSpace(title), strings.TrimSpace(body)
+	if title == "" || body == "" || utf8.RuneCountInString(title) > 100 || utf8.RuneCountInString(body) > 500 {
+		return ErrInvalidBroadcast
+	}
+	if err := s.record(ctx, adminID, AuditNotificationBroadcast, nil, map[string]interface{}{"title": title}); err != nil {
+		return err
+	}
+
+	go s.broadcast(title, body)
+	return nil
+}
+
+func (s *AdminService) broadcast(title, body string) {
+	ctx := context.Background()
+	start := time.Now()
+	created, failed := 0, 0
+
+	after := uuid.Nil
+	for {
+		ids, err := s.repo.GetActiveUserIDs(ctx, after, broadcastBatchSize)
+		if err != nil {
+			log.Printf("broadcast: failed to list users: %v", err)
+			break
+		}
+		if len(ids) == 0 {
+			break
+		}
+		result, err := s.notifService.SendBulkNotification(ctx, ids, "broadcast", title, body, map[string]interface{}{})
+		if err != nil {
+			log.Printf("broadcast: batch failed: %v", err)
+		}
+		if result != nil {
+			created += result.Created
+			failed += len(result.Failed)
+		}
+		if len(ids) < broadcastBatchSize {
+			break
+		}
+		after = ids[len(ids)-1]
+	}
+	log.Printf("broadcast %q: notified %d users, %d failed, in %s", title, created, failed, time.Since(start).Round(time.Second))
+}
+
+func (s *AdminService) record(ctx context.Context, actorID uuid.UUID, action AuditAction, targetUserID *uuid.UUID, details map[string]interface{}) error {
+	return s.audit.RecordAudit(ctx, AuditEntry{
+		ActorID:      actorID,
+		Action:       action,
+		TargetUserID: targetUserID,
+		Details:      details,
+	})
+}
+
+func toAdminUser(user *User, role Role) *AdminUser {
+	return &AdminUser{
+		UserResponse: user.ToResponse(),
+		Role:         role,
+		IsActive:     user.IsActive,
+		UpdatedAt:    user.UpdatedAt,
+	}
+}
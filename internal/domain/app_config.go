@@ -0,0 +1,171 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ClientPolicy decides which app builds may use the API
+type ClientPolicy struct {
+	// MinVersion is the oldest supported app version; empty allows all
+	MinVersion string
+	// BlockedVersions are rejected even if newer than MinVersion
+	BlockedVersions []string
+	// UpdateURL is where blocked clients are sent to upgrade
+	UpdateURL string
+	// RequireClientHeader rejects API requests that don't identify their
+	// build with an X-Client header
+	RequireClientHeader bool
+}
+
+// ClientInfo identifies the app build behind a request. Platform and
+// AppVersion are "unknown" when the client didn't send valid values.
+// IPAddress and UserAgent are kept for security events, not exposed.
+type ClientInfo struct {
+	Platform    string `json:"platform"`
+	AppVersion  string `json:"app_version"`
+	DeviceModel string `json:"device_model,omitempty"`
+	IPAddress   string `json:"-"`
+	UserAgent   string `json:"-"`
+}
+
+type clientInfoKey struct{}
+
+// WithClientInfo returns ctx carrying the caller's client info
+func WithClientInfo(ctx context.Context, client ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoKey{}, client)
+}
+
+// ClientInfoFromContext returns the caller's client info, if known
+func ClientInfoFromContext(ctx context.Context) (ClientInfo, bool) {
+	client, ok := ctx.Value(clientInfoKey{}).(ClientInfo)
+	return client, ok
+}
+
+// Supported reports whether the app version may use the API. Clients that
+// don't report a version are let through.
+func (p ClientPolicy) Supported(version string) bool {
+	if version == "" {
+		return true
+	}
+	for _, blocked := range p.BlockedVersions {
+		if CompareVersions(version, blocked) == 0 {
+			return false
+		}
+	}
+	return p.MinVersion == "" || CompareVersions(version, p.MinVersion) >= 0
+}
+
+// CompareVersions compares dotted numeric versions, returning -1, 0 or 1.
+// Missing components count as zero, so "1.2" == "1.2.0".
+func CompareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// AppConfig is what clients fetch at startup
+type AppConfig struct {
+	MinSupportedVersion string                     `json:"min_supported_version,omitempty"`
+	UpdateURL           string                     `json:"update_url,omitempty"`
+	UpdateRequired      bool                       `json:"update_required"`
+	Features            map[Feature]bool           `json:"features"`
+	KillSwitches        []Feature                  `json:"kill_switches"`
+	ConfigVersion       int64                      `json:"config_version"`
+	Config              map[string]json.RawMessage `json:"config"`
+}
+
+type AppConfigService struct {
+	policy ClientPolicy
+	gate   *FeatureGate
+	repo   RemoteConfigRepository
+}
+
+func NewAppConfigService(policy ClientPolicy, gate *FeatureGate, repo RemoteConfigRepository) *AppConfigService {
+	return &AppConfigService{policy: policy, gate: gate, repo: repo}
+}
+
+// Policy returns the client version policy
+func (s *AppConfigService) Policy() ClientPolicy {
+	return s.policy
+}
+
+// GetConfig returns the config for an app version and platform in the region on ctx
+func (s *AppConfigService) GetConfig(ctx context.Context, appVersion string, platform Platform) (*AppConfig, error) {
+	entries, version, err := s.repo.GetRemoteConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	region := RegionFromContext(ctx)
+	features := make(map[Feature]bool, len(Features))
+	for _, f := range Features {
+		features[f] = s.gate.Allowed(region, f)
+	}
+
+	return &AppConfig{
+		MinSupportedVersion: s.policy.MinVersion,
+		UpdateURL:           s.policy.UpdateURL,
+		UpdateRequired:      !s.policy.Supported(appVersion),
+		Features:            features,
+		KillSwitches:        s.gate.KillSwitches(),
+		ConfigVersion:       version,
+		Config:              ResolveRemoteConfig(entries, platform),
+	}, nil
+}
+
+// ListRemoteConfig returns every remote config entry, including platform
+// overrides, and the current config version
+func (s *AppConfigService) ListRemoteConfig(ctx context.Context) ([]*RemoteConfigEntry, int64, error) {
+	return s.repo.GetRemoteConfig(ctx)
+}
+
+// SetRemoteConfig creates or replaces a remote config value
+func (s *AppConfigService) SetRemoteConfig(ctx context.Context, adminID uuid.UUID, key string, platform Platform, value json.RawMessage, description *string) (*RemoteConfigEntry, error) {
+	if !remoteConfigKeyRegex.MatchString(key) {
+		return nil, ErrInvalidRemoteConfigKey
+	}
+	if _, err := ParsePlatform(string(platform)); err != nil {
+		return nil, err
+	}
+	if len(value) == 0 || !json.Valid(value) {
+		return nil, ErrInvalidRemoteConfig
+	}
+
+	return s.repo.SetRemoteConfig(ctx, &RemoteConfigEntry{
+		Key:         key,
+		Platform:    platform,
+		Value:       value,
+		Description: description,
+		UpdatedBy:   &adminID,
+	})
+}
+
+// DeleteRemoteConfig removes a remote config value
+func (s *AppConfigService) DeleteRemoteConfig(ctx context.Context, adminID uuid.UUID, key string, platform Platform) error {
+	return s.repo.DeleteRemoteConfig(ctx, key, platform, adminID)
+}
+
+// GetRemoteConfigHistory lists past changes, newest first; key "" means all keys
+func (s *AppConfigService) GetRemoteConfigHistory(ctx context.Context, key string, limit, offset int) ([]*RemoteConfigChange, error) {
+	limit, offset = AdminPageLimits.Clamp(limit, offset)
+	return s.repo.GetRemoteConfigHistory(ctx, key, limit, offset)
+}
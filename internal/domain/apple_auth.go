@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/auth"
+)
+
+// AppleLoginResult represents the result of Sign in with Apple
+type AppleLoginResult struct {
+	User         *UserResponse `json:"user"`
+	AccessToken  string        `json:"access_token"`
+	RefreshToken string        `json:"refresh_token"`
+	IsNewUser    bool          `json:"is_new_user"`
+	ServerTime   time.Time     `json:"server_time"`
+}
+
+// AppleLogin handles Sign in with Apple. Apple only hands the user's name to
+// the app, on the first authorization, so name comes from the client and is
+// only used when creating an account.
+func (s *AuthService) AppleLogin(ctx context.Context, idToken, nonce, name string) (*AppleLoginResult, error) {
+	appleUser, err := s.apple.VerifyIDToken(ctx, idToken, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	isNewUser := false
+
+	// Try to find existing user by Apple ID
+	user, err := s.repo.GetUserByAppleID(ctx, appleUser.AppleID)
+	if errors.Is(err, ErrUserNotFound) {
+		// Try to find by email
+		user, err = s.repo.GetUserByEmail(ctx, appleUser.Email)
+		switch {
+		case errors.Is(err, ErrUserNotFound):
+			appleID := appleUser.AppleID
+			user, err = s.repo.CreateUser(ctx, CreateUserParams{
+				Email:         &appleUser.Email,
+				Name:          s.text.ProviderName(appleDisplayName(name, appleUser.Email)),
+				AppleID:       &appleID,
+				EmailVerified: appleUser.EmailVerified,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			isNewUser = true
+		case err != nil:
+			return nil, err
+		default:
+			// Same rule as Google: both sides must have proven the email
+			if !user.EmailVerified || !appleUser.EmailVerified {
+				return nil, ErrAppleLinkRequired
+			}
+			user, err = s.repo.LinkAppleAccount(ctx, user.ID, appleUser.AppleID)
+			if err != nil {
+				return nil, err
+			}
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	session, err := s.newSession(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.recordSecurityEvent(ctx, user.ID, SecurityLogin, map[string]interface{}{"method": "apple", "session_id": session.ID})
+
+	tokenPair, err := s.jwt.GenerateTokenPair(user.ID, session.ID, appleUser.Email, session.RegionCode())
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.repo.CreateRefreshToken(ctx, CreateRefreshTokenParams{
+		UserID:    user.ID,
+		SessionID: &session.ID,
+		TokenHash: auth.HashToken(tokenPair.RefreshToken),
+		ExpiresAt: tokenPair.ExpiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &AppleLoginResult{
+		User:         user.ToResponse(),
+		AccessToken:  tokenPair.AccessToken,
+		RefreshToken: tokenPair.RefreshToken,
+		IsNewUser:    isNewUser,
+		ServerTime:   time.Now().UTC(),
+	}, nil
+}
+
+// LinkApple links an Apple identity to the authenticated user. This is the explicit
+// confirmation step for accounts that AppleLogin refuses to link automatically.
+func (s *AuthService) LinkApple(ctx context.Context, userID uuid.UUID, idToken, nonce string) (*UserResponse, error) {
+	appleUser, err := s.apple.VerifyIDToken(ctx, idToken, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fails if the user already has a different Apple ID, or (unique
+	// violation) the Apple account belongs to another user
+	user, err := s.repo.LinkAppleAccount(ctx, userID, appleUser.AppleID)
+	if err != nil {
+		return nil, err
+	}
+	return user.ToResponse(), nil
+}
+
+// appleDisplayName falls back to the email's local part when the client
+// didn't send a name, e.g. on a reinstall after the first authorization
+func appleDisplayName(name, email string) string {
+	if name = strings.TrimSpace(name); name != "" {
+		return name
+	}
+	local, _, _ := strings.Cut(email, "@")
+	return local
+}
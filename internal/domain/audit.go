@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditAction names an admin action recorded in the audit log
+type AuditAction string
+
+const (
+	AuditLegalHoldPlace        AuditAction = "legal_hold.place"
+	AuditLegalHoldRelease      AuditAction = "legal_hold.release"
+	AuditLegalHoldList         AuditAction = "legal_hold.list"
+	AuditLegalHoldSnapshotView AuditAction = "legal_hold.snapshot_view"
+	AuditLogView               AuditAction = "audit_log.view"
+	AuditPIIRectify            AuditAction = "pii.rectify"
+	AuditPIIPseudonymize       AuditAction = "pii.pseudonymize"
+	AuditSupportImpersonate    AuditAction = "support.impersonate"
+	AuditSupportRequest        AuditAction = "support.request"
+	AuditUserView              AuditAction = "user.view"
+	AuditUserDeactivate        AuditAction = "user.deactivate"
+	AuditUserReactivate        AuditAction = "user.reactivate"
+	AuditUserRoleChange        AuditAction = "user.role_change"
+	AuditStoryTakedown         AuditAction = "story.takedown"
+	AuditNotificationBroadcast AuditAction = "notification.broadcast"
+	AuditAccountMerge          AuditAction = "user.merge"
+	AuditSecurityEventsView    AuditAction = "security_events.view"
+	AuditVerificationApprove   AuditAction = "verification.approve"
+	AuditVerificationReject    AuditAction = "verification.reject"
+	AuditVerificationRevoke    AuditAction = "verification.revoke"
+	AuditUserTimelineView      AuditAction = "user.timeline_view"
+)
+
+// AuditEntry is an append-only record of an admin acting on, or looking at, user data
+type AuditEntry struct {
+	ID           uuid.UUID              `json:"id"`
+	ActorID      uuid.UUID              `json:"actor_id"`
+	Action       AuditAction            `json:"action"`
+	TargetUserID *uuid.UUID             `json:"target_user_id,omitempty"`
+	Details      map[string]interface{} `json:"details,omitempty"`
+	CreatedAt    time.Time              `json:"created_at"`
+}
+
+type AuditRepository interface {
+	RecordAudit(ctx context.Context, entry AuditEntry) error
+	GetAuditLog(ctx context.Context, targetUserID uuid.UUID, limit, offset int) ([]*AuditEntry, error)
+}
@@ -3,10 +3,16 @@ package domain
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/url"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/locolive/backend/internal/auth"
+	emails "github.com/locolive/backend/internal/email"
+	"github.com/locolive/backend/internal/sms"
+	"github.com/locolive/backend/internal/storage"
+	"go.uber.org/zap"
 )
 
 var (
@@ -15,8 +21,31 @@ var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrTokenRevoked       = errors.New("token has been revoked")
 	ErrSessionExpired     = errors.New("session has expired")
-	ErrInvalidToken       = errors.New("invalid token")
-	ErrTokenExpired       = errors.New("token has expired")
+	ErrSessionNotFound    = errors.New("session not found")
+	ErrInvalidVisibility  = errors.New("visibility must be public or private")
+
+	ErrAccountLocked        = errors.New("account is temporarily locked after too many failed logins")
+	ErrTooManyLoginAttempts = errors.New("too many failed login attempts")
+	ErrInvalidToken         = errors.New("invalid token")
+	ErrTokenExpired         = errors.New("token has expired")
+
+	ErrEmailAlreadyVerified     = errors.New("email is already verified")
+	ErrNoEmailToVerify          = errors.New("account has no email address")
+	ErrVerificationRecentlySent = errors.New("a verification email was sent recently")
+
+	// ErrGoogleLinkRequired is returned when a Google login matches an existing
+	// account by email but ownership of that email can't be established, so the
+	// user must sign in and link Google explicitly
+	ErrGoogleLinkRequired = errors.New("account exists; sign in to link Google")
+	// ErrAppleLinkRequired is the Sign in with Apple counterpart of ErrGoogleLinkRequired
+	ErrAppleLinkRequired = errors.New("account exists; sign in to link Apple")
+
+	// Specific duplicates; all match ErrUserAlreadyExists via errors.Is
+	ErrEmailAlreadyExists         = fmt.Errorf("%w: email already in use", ErrUserAlreadyExists)
+	ErrPhoneAlreadyExists         = fmt.Errorf("%w: phone already in use", ErrUserAlreadyExists)
+	ErrGoogleAccountAlreadyLinked = fmt.Errorf("%w: google account already linked", ErrUserAlreadyExists)
+	ErrAppleAccountAlreadyLinked  = fmt.Errorf("%w: apple account already linked", ErrUserAlreadyExists)
+	ErrUsernameTaken              = fmt.Errorf("%w: username already taken", ErrUserAlreadyExists)
 )
 
 // AuthRepository defines the interface for auth data access
@@ -27,32 +56,102 @@ type AuthRepository interface {
 	GetUserByEmail(ctx context.Context, email string) (*User, error)
 	GetUserByPhone(ctx context.Context, phone string) (*User, error)
 	GetUserByGoogleID(ctx context.Context, googleID string) (*User, error)
+	GetUserByAppleID(ctx context.Context, appleID string) (*User, error)
+	GetUserByUsername(ctx context.Context, username string) (*User, error)
+	// GetUsersByIDs returns the active users among ids, in no particular order
+	GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]*User, error)
+	// UsernameExists includes deactivated accounts, which keep their handle
+	// until they're purged
+	UsernameExists(ctx context.Context, username string) (bool, error)
+	SearchUsers(ctx context.Context, params UserSearchParams) ([]*User, error)
 	UpdateUser(ctx context.Context, userID uuid.UUID, params UpdateUserParams) (*User, error)
-	DeleteUser(ctx context.Context, userID uuid.UUID) error
+	DeleteUser(ctx context.Context, userID uuid.UUID) ([]string, error)
 	UpdateUserPassword(ctx context.Context, userID uuid.UUID, passwordHash string) error
 	UpdateUserEmail(ctx context.Context, userID uuid.UUID, email string) error
 	LinkGoogleAccount(ctx context.Context, userID uuid.UUID, googleID string) (*User, error)
+	// LinkAppleAccount fails with ErrAppleAccountAlreadyLinked if the user is
+	// linked to a different Apple ID
+	LinkAppleAccount(ctx context.Context, userID uuid.UUID, appleID string) (*User, error)
 	UserExistsByEmail(ctx context.Context, email string) (bool, error)
 	UserExistsByPhone(ctx context.Context, phone string) (bool, error)
 	VerifyUserPassword(ctx context.Context, email, password string) (*User, error)
 
+	// Login attempt tracking
+	RecordLoginAttempt(ctx context.Context, attempt LoginAttempt) error
+	CountFailedLoginsFromIP(ctx context.Context, ip string, since time.Time) (int, error)
+	// CountFailedLoginsForEmail counts failures since the given time and the
+	// email's last successful login
+	CountFailedLoginsForEmail(ctx context.Context, email string, since time.Time) (int, error)
+	// GetUserLockedUntil returns nil if the user isn't locked
+	GetUserLockedUntil(ctx context.Context, userID uuid.UUID) (*time.Time, error)
+	// RegisterLoginFailure returns the lock's end if this failure locked the user
+	RegisterLoginFailure(ctx context.Context, userID uuid.UUID, maxFailures int, lockFor time.Duration) (*time.Time, error)
+	ResetLoginFailures(ctx context.Context, userID uuid.UUID) error
+
 	// Session operations
 	CreateSession(ctx context.Context, params CreateSessionParams) (*Session, error)
 	GetSessionByID(ctx context.Context, id uuid.UUID) (*Session, error)
+	GetUserSessions(ctx context.Context, userID uuid.UUID) ([]*Session, error)
+	TouchSession(ctx context.Context, id uuid.UUID) error
+	// DeactivateSession ends a session and revokes its refresh tokens
 	DeactivateSession(ctx context.Context, id uuid.UUID) error
 	DeactivateUserSessions(ctx context.Context, userID uuid.UUID) error
+	// EvictSessions ends a user's least recently used active sessions beyond
+	// the newest keep, never ending keepID, and returns them with the push
+	// tokens they had
+	EvictSessions(ctx context.Context, userID, keepID uuid.UUID, keep int) ([]*Session, error)
 
 	// Refresh token operations
 	CreateRefreshToken(ctx context.Context, params CreateRefreshTokenParams) (*RefreshToken, error)
+	// GetRefreshTokenByHash also returns revoked tokens, for reuse detection
 	GetRefreshTokenByHash(ctx context.Context, hash string) (*RefreshToken, error)
 	RevokeRefreshToken(ctx context.Context, id uuid.UUID) error
 	RevokeRefreshTokenByHash(ctx context.Context, hash string) error
+	RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) error
 	RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) error
 
 	// Password reset token operations
 	CreatePasswordResetToken(ctx context.Context, userID uuid.UUID, tokenHash string, expiresAt time.Time) error
 	GetPasswordResetToken(ctx context.Context, tokenHash string) (*PasswordResetToken, error)
 	MarkPasswordResetTokenUsed(ctx context.Context, id uuid.UUID) error
+
+	// Email verification token operations
+	CreateEmailVerificationToken(ctx context.Context, userID uuid.UUID, email, tokenHash string, expiresAt time.Time) error
+	GetEmailVerificationToken(ctx context.Context, tokenHash string) (*EmailVerificationToken, error)
+	// GetLatestEmailVerificationToken returns nil if none was ever issued
+	GetLatestEmailVerificationToken(ctx context.Context, userID uuid.UUID) (*EmailVerificationToken, error)
+	// MarkEmailVerified uses the token and verifies the user's email if it is
+	// still the address the token was sent to, reporting whether it was
+	MarkEmailVerified(ctx context.Context, token *EmailVerificationToken) (bool, error)
+
+	// Phone OTP operations
+	CreatePhoneOTP(ctx context.Context, phone, codeHash string, expiresAt time.Time) error
+	// GetLatestPhoneOTP returns nil if no code was ever sent to phone
+	GetLatestPhoneOTP(ctx context.Context, phone string) (*PhoneOTP, error)
+	CountPhoneOTPsSince(ctx context.Context, phone string, since time.Time) (int, error)
+	IncrementPhoneOTPAttempts(ctx context.Context, id uuid.UUID) error
+	// ConsumePhoneOTP marks a code used, reporting false if it already was
+	ConsumePhoneOTP(ctx context.Context, id uuid.UUID) (bool, error)
+	SetUserPhoneVerified(ctx context.Context, userID uuid.UUID, phone string) (*User, error)
+
+	SecurityEventRepository
+	VerificationRepository
+}
+
+const (
+	// EmailVerificationTTL is how long a verification link stays valid
+	EmailVerificationTTL = 24 * time.Hour
+	// EmailVerificationCooldown is the minimum gap between verification emails
+	EmailVerificationCooldown = time.Minute
+	// PasswordResetTTL is how long a password reset link stays valid
+	PasswordResetTTL = time.Hour
+)
+
+// EmailLinkSettings are the pages verification and password reset emails
+// link to; the token is appended as ?token=
+type EmailLinkSettings struct {
+	VerifyURL string
+	ResetURL  string
 }
 
 // CreateUserParams holds parameters for user creation
@@ -62,17 +161,52 @@ type CreateUserParams struct {
 	PasswordHash  *string
 	Name          string
 	GoogleID      *string
+	AppleID       *string
+	AvatarURL     *string
 	EmailVerified bool
+	PhoneVerified bool
 }
 
 // UpdateUserParams holds parameters for user update
 type UpdateUserParams struct {
 	Name        *string    `json:"name"`
+	Username    *string    `json:"username"`
 	Bio         *string    `json:"bio"`
 	Gender      *string    `json:"gender"`
 	DateOfBirth *time.Time `json:"date_of_birth"`
 	Visibility  *string    `json:"visibility"`
 	AvatarURL   *string    `json:"avatar_url"`
+	CountryCode *string    `json:"country_code"`
+}
+
+// Fields lists the names of the fields being updated
+func (p UpdateUserParams) Fields() []string {
+	var fields []string
+	if p.Name != nil {
+		fields = append(fields, "name")
+	}
+	if p.Username != nil {
+		fields = append(fields, "username")
+	}
+	if p.Bio != nil {
+		fields = append(fields, "bio")
+	}
+	if p.Gender != nil {
+		fields = append(fields, "gender")
+	}
+	if p.DateOfBirth != nil {
+		fields = append(fields, "date_of_birth")
+	}
+	if p.Visibility != nil {
+		fields = append(fields, "visibility")
+	}
+	if p.AvatarURL != nil {
+		fields = append(fields, "avatar_url")
+	}
+	if p.CountryCode != nil {
+		fields = append(fields, "country_code")
+	}
+	return fields
 }
 
 // CreateSessionParams holds parameters for session creation
@@ -81,6 +215,9 @@ type CreateSessionParams struct {
 	DeviceInfo *string
 	IPAddress  *string
 	UserAgent  *string
+	Region     *string
+	Platform   *string
+	AppVersion *string
 	ExpiresAt  time.Time
 }
 
@@ -88,23 +225,50 @@ type CreateSessionParams struct {
 type CreateRefreshTokenParams struct {
 	UserID    uuid.UUID
 	SessionID *uuid.UUID
+	// FamilyID continues a rotated token's family; nil starts a new one
+	FamilyID  *uuid.UUID
 	TokenHash string
 	ExpiresAt time.Time
 }
 
 // AuthService handles authentication business logic
 type AuthService struct {
-	repo   AuthRepository
-	jwt    *auth.JWTManager
-	google *auth.GoogleAuthVerifier
+	repo         AuthRepository
+	connRepo     ConnectionRepository
+	jwt          *auth.JWTManager
+	google       *auth.GoogleAuthVerifier
+	apple        *auth.AppleAuthVerifier
+	storage      storage.FileStorage
+	email        EmailSender
+	links        EmailLinkSettings
+	sms          sms.Provider
+	lockout      LockoutPolicy
+	sessions     SessionPolicy
+	text         TextPolicy
+	notifService *NotificationService
+	activity     *ActivityService
+	logger       *zap.Logger
 }
 
-// NewAuthService creates a new auth service
-func NewAuthService(repo AuthRepository, jwt *auth.JWTManager, google *auth.GoogleAuthVerifier) *AuthService {
+// NewAuthService creates a new auth service. email may be nil, in which case
+// verification and password reset tokens are only logged at debug level.
+func NewAuthService(repo AuthRepository, connRepo ConnectionRepository, jwt *auth.JWTManager, google *auth.GoogleAuthVerifier, apple *auth.AppleAuthVerifier, storage storage.FileStorage, email EmailSender, links EmailLinkSettings, smsProvider sms.Provider, lockout LockoutPolicy, sessions SessionPolicy, text TextPolicy, notifService *NotificationService, activity *ActivityService, logger *zap.Logger) *AuthService {
 	return &AuthService{
-		repo:   repo,
-		jwt:    jwt,
-		google: google,
+		repo:         repo,
+		connRepo:     connRepo,
+		jwt:          jwt,
+		google:       google,
+		apple:        apple,
+		storage:      storage,
+		email:        email,
+		links:        links,
+		sms:          smsProvider,
+		lockout:      lockout,
+		sessions:     sessions,
+		text:         text,
+		notifService: notifService,
+		activity:     activity,
+		logger:       logger,
 	}
 }
 
@@ -113,17 +277,23 @@ type RegisterResult struct {
 	User         *UserResponse `json:"user"`
 	AccessToken  string        `json:"access_token"`
 	RefreshToken string        `json:"refresh_token"`
+	ServerTime   time.Time     `json:"server_time"`
 }
 
 // Register creates a new user with email/password
 func (s *AuthService) Register(ctx context.Context, email, password, name string) (*RegisterResult, error) {
+	name, err := s.text.Name(name)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if user exists
 	exists, err := s.repo.UserExistsByEmail(ctx, email)
 	if err != nil {
 		return nil, err
 	}
 	if exists {
-		return nil, ErrUserAlreadyExists
+		return nil, ErrEmailAlreadyExists
 	}
 
 	// Hash password
@@ -143,17 +313,13 @@ func (s *AuthService) Register(ctx context.Context, email, password, name string
 	}
 
 	// Create session
-	session, err := s.repo.CreateSession(ctx, CreateSessionParams{
-		UserID:    user.ID,
-		ExpiresAt: time.Now().Add(30 * 24 * time.Hour), // 30 days
-		// Device info could be passed in context or params, but for now defaults
-	})
+	session, err := s.newSession(ctx, user.ID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Generate tokens
-	tokenPair, err := s.jwt.GenerateTokenPair(user.ID, session.ID, email)
+	tokenPair, err := s.jwt.GenerateTokenPair(user.ID, session.ID, email, session.RegionCode())
 	if err != nil {
 		return nil, err
 	}
@@ -170,10 +336,15 @@ func (s *AuthService) Register(ctx context.Context, email, password, name string
 		return nil, err
 	}
 
+	if err := s.sendVerificationEmail(ctx, user.ID, email); err != nil {
+		s.logger.Warn("failed to send verification email", zap.String("user_id", user.ID.String()), zap.Error(err))
+	}
+
 	return &RegisterResult{
 		User:         user.ToResponse(),
 		AccessToken:  tokenPair.AccessToken,
 		RefreshToken: tokenPair.RefreshToken,
+		ServerTime:   time.Now().UTC(),
 	}, nil
 }
 
@@ -182,37 +353,47 @@ type LoginResult struct {
 	User         *UserResponse `json:"user"`
 	AccessToken  string        `json:"access_token"`
 	RefreshToken string        `json:"refresh_token"`
+	ServerTime   time.Time     `json:"server_time"`
 }
 
 // Login authenticates a user with email/password
-func (s *AuthService) Login(ctx context.Context, email, password string) (*LoginResult, error) {
+func (s *AuthService) Login(ctx context.Context, email, password, ip string) (*LoginResult, error) {
+	// An unknown email still counts against the caller's IP
 	user, err := s.repo.GetUserByEmail(ctx, email)
 	if err != nil {
-		return nil, ErrInvalidCredentials
+		user = nil
+	}
+	if err := s.checkLoginAllowed(ctx, user, ip); err != nil {
+		return nil, err
 	}
 
-	// User must have a password (not OAuth-only)
-	if user.Email == nil {
+	// User must exist and have a password (not OAuth-only)
+	if user == nil || user.Email == nil {
+		_ = s.recordLoginAttempt(ctx, nil, email, ip, false)
 		return nil, ErrInvalidCredentials
 	}
 
 	// Verify password
 	_, err = s.repo.VerifyUserPassword(ctx, *user.Email, password)
 	if err != nil {
+		if err := s.recordLoginAttempt(ctx, user, email, ip, false); err == ErrAccountLocked {
+			return nil, err
+		}
 		return nil, ErrInvalidCredentials
 	}
+	if err := s.recordLoginAttempt(ctx, user, email, ip, true); err != nil {
+		s.logger.Warn("failed to reset login failures", zap.Error(err))
+	}
 
 	// Create session
-	session, err := s.repo.CreateSession(ctx, CreateSessionParams{
-		UserID:    user.ID,
-		ExpiresAt: time.Now().Add(30 * 24 * time.Hour),
-	})
+	session, err := s.newSession(ctx, user.ID)
 	if err != nil {
 		return nil, err
 	}
+	s.recordSecurityEvent(ctx, user.ID, SecurityLogin, map[string]interface{}{"method": "password", "session_id": session.ID})
 
 	// Generate tokens
-	tokenPair, err := s.jwt.GenerateTokenPair(user.ID, session.ID, *user.Email)
+	tokenPair, err := s.jwt.GenerateTokenPair(user.ID, session.ID, *user.Email, session.RegionCode())
 	if err != nil {
 		return nil, err
 	}
@@ -233,13 +414,15 @@ func (s *AuthService) Login(ctx context.Context, email, password string) (*Login
 		User:         user.ToResponse(),
 		AccessToken:  tokenPair.AccessToken,
 		RefreshToken: tokenPair.RefreshToken,
+		ServerTime:   time.Now().UTC(),
 	}, nil
 }
 
 // RefreshResult represents the result of token refresh
 type RefreshResult struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	ServerTime   time.Time `json:"server_time"`
 }
 
 // RefreshToken validates and rotates a refresh token
@@ -258,8 +441,7 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*R
 	}
 
 	if storedToken.Revoked {
-		// Token reuse detected - revoke all user tokens
-		_ = s.repo.RevokeUserRefreshTokens(ctx, claims.UserID)
+		s.handleRefreshTokenReuse(ctx, storedToken)
 		return nil, ErrTokenRevoked
 	}
 
@@ -278,23 +460,29 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*R
 	}
 
 	// Handle session
-	var sessionID uuid.UUID
+	var session *Session
 	if storedToken.SessionID != nil {
-		sessionID = *storedToken.SessionID
+		session, err = s.repo.GetSessionByID(ctx, *storedToken.SessionID)
+		if err != nil {
+			if errors.Is(err, ErrSessionNotFound) {
+				return nil, ErrTokenRevoked
+			}
+			return nil, err
+		}
+		// Refreshes happen throughout active use, so they mark the session active
+		if err := s.repo.TouchSession(ctx, session.ID); err != nil {
+			s.logger.Warn("failed to touch session", zap.Error(err))
+		}
 	} else {
 		// Legacy token without session, create one
-		session, err := s.repo.CreateSession(ctx, CreateSessionParams{
-			UserID:    claims.UserID,
-			ExpiresAt: time.Now().Add(30 * 24 * time.Hour),
-		})
+		session, err = s.newSession(ctx, claims.UserID)
 		if err != nil {
 			return nil, err
 		}
-		sessionID = session.ID
 	}
 
 	// Generate new token pair
-	tokenPair, err := s.jwt.GenerateTokenPair(claims.UserID, sessionID, email)
+	tokenPair, err := s.jwt.GenerateTokenPair(claims.UserID, session.ID, email, session.RegionCode())
 	if err != nil {
 		return nil, err
 	}
@@ -303,7 +491,8 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*R
 	newTokenHash := auth.HashToken(tokenPair.RefreshToken)
 	_, err = s.repo.CreateRefreshToken(ctx, CreateRefreshTokenParams{
 		UserID:    claims.UserID,
-		SessionID: &sessionID,
+		SessionID: &session.ID,
+		FamilyID:  &storedToken.FamilyID,
 		TokenHash: newTokenHash,
 		ExpiresAt: tokenPair.ExpiresAt,
 	})
@@ -314,18 +503,112 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*R
 	return &RefreshResult{
 		AccessToken:  tokenPair.AccessToken,
 		RefreshToken: tokenPair.RefreshToken,
+		ServerTime:   time.Now().UTC(),
 	}, nil
 }
 
-// Logout revokes a refresh token
+// handleRefreshTokenReuse responds to a rotated-out refresh token being used
+// again, meaning it was copied: either the legitimate client or an attacker
+// holds a stale token. Only the affected family and its session are ended, so
+// the user's other devices stay signed in.
+func (s *AuthService) handleRefreshTokenReuse(ctx context.Context, token *RefreshToken) {
+	fields := []zap.Field{
+		zap.String("event", "refresh_token_reuse"),
+		zap.String("user_id", token.UserID.String()),
+		zap.String("family_id", token.FamilyID.String()),
+	}
+	if token.SessionID != nil {
+		fields = append(fields, zap.String("session_id", token.SessionID.String()))
+	}
+	s.logger.Warn("security event: refresh token reuse detected", fields...)
+	details := map[string]interface{}{"family_id": token.FamilyID}
+	if token.SessionID != nil {
+		details["session_id"] = *token.SessionID
+	}
+	s.recordSecurityEvent(ctx, token.UserID, SecurityRefreshTokenReuse, details)
+
+	if err := s.repo.RevokeRefreshTokenFamily(ctx, token.FamilyID); err != nil {
+		s.logger.Error("failed to revoke refresh token family", zap.Error(err))
+	}
+	if token.SessionID != nil {
+		if err := s.repo.DeactivateSession(ctx, *token.SessionID); err != nil {
+			s.logger.Error("failed to end session after token reuse", zap.Error(err))
+		}
+	}
+}
+
+// newSession starts a 30-day session tagged with the caller's region and
+// app build, ending the user's oldest sessions if they're over the cap
+func (s *AuthService) newSession(ctx context.Context, userID uuid.UUID) (*Session, error) {
+	params := CreateSessionParams{
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(30 * 24 * time.Hour),
+	}
+	if region := RegionFromContext(ctx); region != "" {
+		params.Region = &region
+	}
+	if client, ok := ClientInfoFromContext(ctx); ok {
+		params.Platform = &client.Platform
+		params.AppVersion = &client.AppVersion
+		if client.DeviceModel != "" {
+			params.DeviceInfo = &client.DeviceModel
+		}
+	}
+	session, err := s.repo.CreateSession(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	s.enforceSessionCap(ctx, session)
+	return session, nil
+}
+
+// Logout revokes a refresh token and ends the session it belongs to
 func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
 	tokenHash := auth.HashToken(refreshToken)
+	storedToken, err := s.repo.GetRefreshTokenByHash(ctx, tokenHash)
+	if err == nil && !storedToken.Revoked && storedToken.SessionID != nil {
+		return s.repo.DeactivateSession(ctx, *storedToken.SessionID)
+	}
 	return s.repo.RevokeRefreshTokenByHash(ctx, tokenHash)
 }
 
-// LogoutAll revokes all refresh tokens for a user
+// LogoutAll revokes all refresh tokens for a user and ends their sessions
 func (s *AuthService) LogoutAll(ctx context.Context, userID uuid.UUID) error {
-	return s.repo.RevokeUserRefreshTokens(ctx, userID)
+	if err := s.repo.RevokeUserRefreshTokens(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.repo.DeactivateUserSessions(ctx, userID); err != nil {
+		return err
+	}
+	s.recordSecurityEvent(ctx, userID, SecurityLogoutAll, nil)
+	return nil
+}
+
+// GetSessions returns the user's active sessions, flagging the one making the request
+func (s *AuthService) GetSessions(ctx context.Context, userID, currentSessionID uuid.UUID) ([]*SessionResponse, error) {
+	sessions, err := s.repo.GetUserSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, session.ToResponse(currentSessionID))
+	}
+	return result, nil
+}
+
+// RevokeSession signs out one of the user's sessions
+func (s *AuthService) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	session, err := s.repo.GetSessionByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	// Other users' sessions are reported as missing rather than forbidden
+	if session.UserID != userID {
+		return ErrSessionNotFound
+	}
+	return s.repo.DeactivateSession(ctx, sessionID)
 }
 
 // GoogleLoginResult represents the result of Google OAuth login
@@ -334,6 +617,7 @@ type GoogleLoginResult struct {
 	AccessToken  string        `json:"access_token"`
 	RefreshToken string        `json:"refresh_token"`
 	IsNewUser    bool          `json:"is_new_user"`
+	ServerTime   time.Time     `json:"server_time"`
 }
 
 // GoogleLogin handles Google OAuth login
@@ -349,50 +633,68 @@ func (s *AuthService) GoogleLogin(ctx context.Context, idToken string) (*GoogleL
 
 	// Try to find existing user by Google ID
 	user, err = s.repo.GetUserByGoogleID(ctx, googleUser.GoogleID)
-	if err != nil {
+	if errors.Is(err, ErrUserNotFound) {
 		// Try to find by email
 		user, err = s.repo.GetUserByEmail(ctx, googleUser.Email)
-		if err != nil {
+		switch {
+		case errors.Is(err, ErrUserNotFound):
 			// Create new user
 			googleID := googleUser.GoogleID
-			avatarURL := googleUser.Picture
+			var avatarURL *string
+			if googleUser.Picture != "" {
+				avatarURL = &googleUser.Picture
+			}
 
 			user, err = s.repo.CreateUser(ctx, CreateUserParams{
 				Email:         &googleUser.Email,
-				Name:          googleUser.Name,
+				Name:          s.text.ProviderName(googleUser.Name),
 				GoogleID:      &googleID,
+				AvatarURL:     avatarURL,
 				EmailVerified: googleUser.EmailVerified,
 			})
 			if err != nil {
 				return nil, err
 			}
 
-			// Set avatar if provided
-			if avatarURL != "" {
-				user.AvatarURL = &avatarURL
-			}
-
 			isNewUser = true
-		} else {
-			// Link Google account to existing user
+		case err != nil:
+			return nil, err
+		default:
+			// Only link automatically when both sides have proven ownership of
+			// the email; otherwise anyone could claim an unverified account
+			if !user.EmailVerified || !googleUser.EmailVerified {
+				return nil, ErrGoogleLinkRequired
+			}
 			user, err = s.repo.LinkGoogleAccount(ctx, user.ID, googleUser.GoogleID)
 			if err != nil {
 				return nil, err
 			}
 		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Existing users who never set an avatar pick up their Google picture
+	if !isNewUser && user.AvatarURL == nil && googleUser.Picture != "" {
+		user, err = s.repo.UpdateUser(ctx, user.ID, UpdateUserParams{AvatarURL: &googleUser.Picture})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if user.AvatarURL != nil && *user.AvatarURL == googleUser.Picture {
+		go s.mirrorGoogleAvatar(user.ID, googleUser.Picture)
 	}
 
 	// Create session
-	session, err := s.repo.CreateSession(ctx, CreateSessionParams{
-		UserID:    user.ID,
-		ExpiresAt: time.Now().Add(30 * 24 * time.Hour),
-	})
+	session, err := s.newSession(ctx, user.ID)
 	if err != nil {
 		return nil, err
 	}
+	s.recordSecurityEvent(ctx, user.ID, SecurityLogin, map[string]interface{}{"method": "google", "session_id": session.ID})
 
 	// Generate tokens
-	tokenPair, err := s.jwt.GenerateTokenPair(user.ID, session.ID, googleUser.Email)
+	tokenPair, err := s.jwt.GenerateTokenPair(user.ID, session.ID, googleUser.Email, session.RegionCode())
 	if err != nil {
 		return nil, err
 	}
@@ -414,32 +716,116 @@ func (s *AuthService) GoogleLogin(ctx context.Context, idToken string) (*GoogleL
 		AccessToken:  tokenPair.AccessToken,
 		RefreshToken: tokenPair.RefreshToken,
 		IsNewUser:    isNewUser,
+		ServerTime:   time.Now().UTC(),
 	}, nil
 }
 
+// mirrorGoogleAvatar copies a Google-hosted avatar into our storage so it keeps
+// working if Google rotates or expires the URL. Failures keep the Google URL.
+func (s *AuthService) mirrorGoogleAvatar(userID uuid.UUID, pictureURL string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	mirroredURL, err := storage.MirrorImage(ctx, s.storage, pictureURL, "avatar")
+	if err != nil {
+		s.logger.Warn("failed to mirror Google avatar", zap.String("user_id", userID.String()), zap.Error(err))
+		return
+	}
+
+	if _, err := s.repo.UpdateUser(ctx, userID, UpdateUserParams{AvatarURL: &mirroredURL}); err != nil {
+		s.logger.Warn("failed to save mirrored avatar", zap.String("user_id", userID.String()), zap.Error(err))
+		_ = s.storage.DeleteFile(ctx, mirroredURL)
+	}
+}
+
+// LinkGoogle links a Google identity to the authenticated user. This is the explicit
+// confirmation step for accounts that GoogleLogin refuses to link automatically.
+func (s *AuthService) LinkGoogle(ctx context.Context, userID uuid.UUID, idToken string) (*UserResponse, error) {
+	googleUser, err := s.google.VerifyIDToken(ctx, idToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.GoogleID != nil {
+		if *user.GoogleID == googleUser.GoogleID {
+			return user.ToResponse(), nil
+		}
+		return nil, ErrGoogleAccountAlreadyLinked
+	}
+
+	// A unique violation here means the Google account belongs to another user
+	user, err = s.repo.LinkGoogleAccount(ctx, userID, googleUser.GoogleID)
+	if err != nil {
+		return nil, err
+	}
+	return user.ToResponse(), nil
+}
+
 // GetUserByID retrieves a user by ID
 func (s *AuthService) GetUserByID(ctx context.Context, id uuid.UUID) (*User, error) {
 	return s.repo.GetUserByID(ctx, id)
 }
 
-// InitiatePasswordReset creates a password reset token
-func (s *AuthService) InitiatePasswordReset(ctx context.Context, email string) (string, error) {
+// GetUserCards returns the card of each active user among ids, for showing
+// who wrote something. Deactivated users are left out.
+func (s *AuthService) GetUserCards(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*UserResponse, error) {
+	users, err := s.repo.GetUsersByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	cards := make(map[uuid.UUID]*UserResponse, len(users))
+	for _, user := range users {
+		cards[user.ID] = user.ToCardResponse()
+	}
+	return cards, nil
+}
+
+// InitiatePasswordReset creates a password reset token and emails the link.
+// The email is sent in the background, so neither the response time nor a
+// delivery failure reveals whether the address has an account.
+func (s *AuthService) InitiatePasswordReset(ctx context.Context, email string) error {
 	user, err := s.repo.GetUserByEmail(ctx, email)
 	if err != nil {
-		return "", ErrUserNotFound
+		return ErrUserNotFound
 	}
 
 	// Generate reset token
 	token := auth.GenerateRandomToken(32)
 	tokenHash := auth.HashToken(token)
-	expiresAt := time.Now().Add(1 * time.Hour)
+	expiresAt := time.Now().Add(PasswordResetTTL)
 
 	err = s.repo.CreatePasswordResetToken(ctx, user.ID, tokenHash, expiresAt)
 	if err != nil {
-		return "", err
+		return err
+	}
+
+	go s.sendPasswordResetEmail(user.ID, email, token)
+	return nil
+}
+
+// sendPasswordResetEmail emails the reset link for token to address
+func (s *AuthService) sendPasswordResetEmail(userID uuid.UUID, address, token string) {
+	if s.email == nil {
+		s.logger.Debug("email delivery disabled; password reset token issued",
+			zap.String("user_id", userID.String()), zap.String("token", token))
+		return
+	}
+
+	msg, err := emails.PasswordReset(s.links.ResetURL+"?token="+url.QueryEscape(token), PasswordResetTTL)
+	if err != nil {
+		s.logger.Error("failed to render password reset email", zap.Error(err))
+		return
 	}
 
-	return token, nil
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := s.email.SendEmail(ctx, address, msg.Subject, msg.Body); err != nil {
+		s.logger.Error("failed to send password reset email", zap.String("user_id", userID.String()), zap.Error(err))
+	}
 }
 
 // ResetPassword resets password using a reset token
@@ -475,9 +861,19 @@ func (s *AuthService) ResetPassword(ctx context.Context, token, newPassword stri
 	// Mark token as used
 	_ = s.repo.MarkPasswordResetTokenUsed(ctx, resetToken.ID)
 
-	// Revoke all refresh tokens for security
+	// Proving control of the email unlocks an account locked by failed logins
+	if err := s.repo.ResetLoginFailures(ctx, resetToken.UserID); err != nil {
+		return err
+	}
+
+	// Revoke all refresh tokens for security, and end the sessions so access
+	// tokens stolen along with the old password stop working too
 	_ = s.repo.RevokeUserRefreshTokens(ctx, resetToken.UserID)
+	if err := s.repo.DeactivateUserSessions(ctx, resetToken.UserID); err != nil {
+		return err
+	}
 
+	s.recordSecurityEvent(ctx, resetToken.UserID, SecurityPasswordReset, nil)
 	return nil
 }
 
@@ -506,7 +902,11 @@ func (s *AuthService) UpdatePassword(ctx context.Context, userID uuid.UUID, curr
 	}
 
 	// Update password
-	return s.repo.UpdateUserPassword(ctx, userID, passwordHash)
+	if err := s.repo.UpdateUserPassword(ctx, userID, passwordHash); err != nil {
+		return err
+	}
+	s.recordSecurityEvent(ctx, userID, SecurityPasswordChange, nil)
+	return nil
 }
 
 // UpdateEmail changes email for authenticated user
@@ -533,34 +933,181 @@ func (s *AuthService) UpdateEmail(ctx context.Context, userID uuid.UUID, newEmai
 		return err
 	}
 	if exists {
-		return ErrUserAlreadyExists
+		return ErrEmailAlreadyExists
+	}
+
+	// Update email, which marks it unverified until the new address is confirmed
+	if err := s.repo.UpdateUserEmail(ctx, userID, newEmail); err != nil {
+		return err
+	}
+	s.recordSecurityEvent(ctx, userID, SecurityEmailChange, nil)
+	if err := s.sendVerificationEmail(ctx, userID, newEmail); err != nil {
+		s.logger.Warn("failed to send verification email", zap.String("user_id", userID.String()), zap.Error(err))
+	}
+	return nil
+}
+
+// VerifyEmail marks the user's email verified using a token from a verification email
+func (s *AuthService) VerifyEmail(ctx context.Context, token string) error {
+	verification, err := s.repo.GetEmailVerificationToken(ctx, auth.HashToken(token))
+	if err != nil {
+		return ErrInvalidToken
+	}
+	if verification.UsedAt != nil {
+		return ErrInvalidToken
+	}
+	if time.Now().After(verification.ExpiresAt) {
+		return ErrTokenExpired
 	}
 
-	// Update email
-	return s.repo.UpdateUserEmail(ctx, userID, newEmail)
+	verified, err := s.repo.MarkEmailVerified(ctx, verification)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		// The account's email changed after this token was sent
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+// ResendVerification sends a fresh verification email to the user's current address
+func (s *AuthService) ResendVerification(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if user.Email == nil {
+		return ErrNoEmailToVerify
+	}
+	if user.EmailVerified {
+		return ErrEmailAlreadyVerified
+	}
+
+	latest, err := s.repo.GetLatestEmailVerificationToken(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if latest != nil && time.Since(latest.CreatedAt) < EmailVerificationCooldown {
+		return ErrVerificationRecentlySent
+	}
+
+	return s.sendVerificationEmail(ctx, userID, *user.Email)
+}
+
+// sendVerificationEmail issues a verification token for address and emails the link
+func (s *AuthService) sendVerificationEmail(ctx context.Context, userID uuid.UUID, address string) error {
+	token := auth.GenerateRandomToken(32)
+	err := s.repo.CreateEmailVerificationToken(ctx, userID, address, auth.HashToken(token), time.Now().Add(EmailVerificationTTL))
+	if err != nil {
+		return err
+	}
+
+	if s.email == nil {
+		s.logger.Debug("email delivery disabled; verification token issued",
+			zap.String("user_id", userID.String()), zap.String("token", token))
+		return nil
+	}
+
+	msg, err := emails.Verification(s.links.VerifyURL+"?token="+url.QueryEscape(token), EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+	return s.email.SendEmail(ctx, address, msg.Subject, msg.Body)
 }
 
 // UpdateProfile updates the authenticated user's profile
 func (s *AuthService) UpdateProfile(ctx context.Context, userID uuid.UUID, params UpdateUserParams) (*UserResponse, error) {
+	if params.CountryCode != nil {
+		code, ok := NormalizeCountryCode(*params.CountryCode)
+		if !ok {
+			return nil, ErrInvalidCountryCode
+		}
+		params.CountryCode = &code
+	}
+	if params.Username != nil {
+		username, err := NormalizeUsername(*params.Username)
+		if err != nil {
+			return nil, err
+		}
+		params.Username = &username
+	}
+	if params.Visibility != nil && *params.Visibility != VisibilityPublic && *params.Visibility != VisibilityPrivate {
+		return nil, ErrInvalidVisibility
+	}
+	if params.Name != nil {
+		name, err := s.text.Name(*params.Name)
+		if err != nil {
+			return nil, err
+		}
+		params.Name = &name
+	}
+	if params.Bio != nil {
+		bio, err := s.text.Bio(*params.Bio)
+		if err != nil {
+			return nil, err
+		}
+		params.Bio = &bio
+	}
+
 	// Update user in repo
 	user, err := s.repo.UpdateUser(ctx, userID, params)
 	if err != nil {
 		return nil, err
 	}
+	s.recordSecurityEvent(ctx, userID, SecurityProfileUpdate, map[string]interface{}{"fields": params.Fields()})
 
 	return user.ToResponse(), nil
 }
 
-// GetUser retrieves a user by ID
-func (s *AuthService) GetUser(ctx context.Context, userID uuid.UUID) (*UserResponse, error) {
+// GetUser retrieves a user's profile as viewerID may see it
+func (s *AuthService) GetUser(ctx context.Context, viewerID, userID uuid.UUID) (*UserResponse, error) {
 	user, err := s.repo.GetUserByID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
+	return s.profileFor(ctx, viewerID, user)
+}
+
+// profileFor applies the profile's visibility. Private profiles are limited
+// unless the viewer has an accepted connection with the owner, and a block
+// either way hides the profile.
+func (s *AuthService) profileFor(ctx context.Context, viewerID uuid.UUID, user *User) (*UserResponse, error) {
+	if user.ID == viewerID {
+		return user.ToResponse(), nil
+	}
+
+	conn, err := s.connRepo.GetConnectionBetween(ctx, viewerID, user.ID)
+	if err != nil && !errors.Is(err, ErrConnectionNotFound) {
+		return nil, err
+	}
+	if conn != nil && conn.Status == ConnectionStatusBlocked {
+		return nil, ErrUserNotFound
+	}
+	if _, impersonated := ImpersonatorFromContext(ctx); !impersonated {
+		s.activity.Record(user.ID, viewerID, ActivityProfileView, nil)
+	}
+	if user.Visibility != VisibilityPublic && (conn == nil || conn.Status != ConnectionStatusAccepted) {
+		return user.ToLimitedResponse(), nil
+	}
 	return user.ToResponse(), nil
 }
 
-// DeleteAccount deletes a user account (soft delete)
+// DeleteAccount deactivates a user account and removes its content right away.
+// The account itself is purged after the deletion grace period. Media files
+// are removed best effort; a failed delete leaves an orphaned file, not data
+// tied to the user.
 func (s *AuthService) DeleteAccount(ctx context.Context, userID uuid.UUID) error {
-	return s.repo.DeleteUser(ctx, userID)
+	mediaURLs, err := s.repo.DeleteUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, url := range mediaURLs {
+		if err := s.storage.DeleteFile(ctx, url); err != nil {
+			s.logger.Warn("failed to delete media of deleted account",
+				zap.String("user_id", userID.String()), zap.String("url", url), zap.Error(err))
+		}
+	}
+	return nil
 }
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// resetRepo serves one reset token and records which sessions were ended
+type resetRepo struct {
+	AuthRepository
+	token       *PasswordResetToken
+	deactivated []uuid.UUID
+}
+
+func (r *resetRepo) GetPasswordResetToken(ctx context.Context, tokenHash string) (*PasswordResetToken, error) {
+	return r.token, nil
+}
+
+func (r *resetRepo) UpdateUserPassword(ctx context.Context, userID uuid.UUID, passwordHash string) error {
+	return nil
+}
+
+func (r *resetRepo) MarkPasswordResetTokenUsed(ctx context.Context, id uuid.UUID) error { return nil }
+
+func (r *resetRepo) ResetLoginFailures(ctx context.Context, userID uuid.UUID) error { return nil }
+
+func (r *resetRepo) RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) error { return nil }
+
+func (r *resetRepo) DeactivateUserSessions(ctx context.Context, userID uuid.UUID) error {
+	r.deactivated = append(r.deactivated, userID)
+	return nil
+}
+
+func (r *resetRepo) RecordSecurityEvent(ctx context.Context, event SecurityEvent) error { return nil }
+
+func TestResetPasswordEndsSessions(t *testing.T) {
+	userID := uuid.New()
+	repo := &resetRepo{token: &PasswordResetToken{ID: uuid.New(), UserID: userID, ExpiresAt: time.Now().Add(time.Hour)}}
+	s := NewAuthService(repo, nil, nil, nil, nil, nil, nil, EmailLinkSettings{}, nil,
+		LockoutPolicy{}, SessionPolicy{}, TextPolicy{}, nil, nil, zap.NewNop())
+
+	if err := s.ResetPassword(context.Background(), "token", "new-password-123"); err != nil {
+		t.Fatalf("reset password: %v", err)
+	}
+	if len(repo.deactivated) != 1 || repo.deactivated[0] != userID {
+		t.Fatalf("deactivated sessions of %v, want %v", repo.deactivated, userID)
+	}
+}
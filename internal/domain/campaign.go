@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrUnknownCampaign = errors.New("unknown campaign")
+
+type CampaignID string
+
+const (
+	CampaignInactive        CampaignID = "inactive_7d"
+	CampaignFirstStory      CampaignID = "first_story"
+	CampaignPendingRequests CampaignID = "pending_requests"
+
+	// CampaignAll is used for opt-outs covering every campaign
+	CampaignAll CampaignID = "*"
+)
+
+// CampaignRule selects a campaign's audience
+type CampaignRule string
+
+const (
+	// CampaignRuleInactive matches users with no session activity for Threshold
+	CampaignRuleInactive CampaignRule = "inactive"
+	// CampaignRuleNoStory matches users older than Threshold who never posted
+	CampaignRuleNoStory CampaignRule = "no_story"
+	// CampaignRulePendingRequests matches users with requests waiting longer than Threshold
+	CampaignRulePendingRequests CampaignRule = "pending_requests"
+)
+
+type CampaignChannel string
+
+const (
+	CampaignChannelPush  CampaignChannel = "push"
+	CampaignChannelEmail CampaignChannel = "email"
+)
+
+// Campaign is a templated lifecycle message. Title and Body may use {name} and {count}.
+type Campaign struct {
+	ID        CampaignID      `json:"id"`
+	Rule      CampaignRule    `json:"rule"`
+	Threshold time.Duration   `json:"-"`
+	Channel   CampaignChannel `json:"channel"`
+	Title     string          `json:"title"`
+	Body      string          `json:"body"`
+}
+
+// LifecycleCampaigns are evaluated in order, so earlier campaigns win when a
+// user qualifies for several within the frequency cap
+var LifecycleCampaigns = []Campaign{
+	{
+		ID:        CampaignPendingRequests,
+		Rule:      CampaignRulePendingRequests,
+		Threshold: 48 * time.Hour,
+		Channel:   CampaignChannelPush,
+		Title:     "{count} people want to connect",
+		Body:      "Your connection requests are waiting for you",
+	},
+	{
+		ID:        CampaignFirstStory,
+		Rule:      CampaignRuleNoStory,
+		Threshold: 72 * time.Hour,
+		Channel:   CampaignChannelEmail,
+		Title:     "Share your first story, {name}",
+		Body:      "Hi {name}, post a story to let people nearby see what's happening around you.",
+	},
+	{
+		ID:        CampaignInactive,
+		Rule:      CampaignRuleInactive,
+		Threshold: 7 * 24 * time.Hour,
+		Channel:   CampaignChannelPush,
+		Title:     "We miss you, {name}",
+		Body:      "See what's happening nearby today",
+	},
+}
+
+// FindCampaign returns the lifecycle campaign with the given ID
+func FindCampaign(id CampaignID) (*Campaign, error) {
+	for i := range LifecycleCampaigns {
+		if LifecycleCampaigns[i].ID == id {
+			return &LifecycleCampaigns[i], nil
+		}
+	}
+	return nil, ErrUnknownCampaign
+}
+
+// CampaignTarget is a user matched by a campaign rule
+type CampaignTarget struct {
+	UserID uuid.UUID
+	Name   string
+	Email  *string
+	// Count is rule specific, e.g. the number of pending requests
+	Count int
+}
+
+// Render fills the campaign templates for a target
+func (c *Campaign) Render(t CampaignTarget) (title, body string) {
+	r := strings.NewReplacer("{name}", t.Name, "{count}", strconv.Itoa(t.Count))
+	return r.Replace(c.Title), r.Replace(c.Body)
+}
+
+// AudienceQuery selects users for a campaign who aren't opted out, haven't had
+// this campaign within Cooldown, and haven't had any campaign within FrequencyCap
+type AudienceQuery struct {
+	Campaign     CampaignID
+	Rule         CampaignRule
+	Threshold    time.Duration
+	Cooldown     time.Duration
+	FrequencyCap time.Duration
+	Now          time.Time
+	After        uuid.UUID
+	Limit        int
+}
+
+// CampaignStats is the performance of a campaign over a period. A push is opened
+// when its notification is read; a user converted if they were active after the send.
+type CampaignStats struct {
+	Campaign       CampaignID `json:"campaign"`
+	Sent           int        `json:"sent"`
+	Opened         int        `json:"opened"`
+	Converted      int        `json:"converted"`
+	OpenRate       float64    `json:"open_rate"`
+	ConversionRate float64    `json:"conversion_rate"`
+}
+
+// CampaignPreference is whether a user receives a campaign
+type CampaignPreference struct {
+	Campaign CampaignID `json:"campaign"`
+	OptedOut bool       `json:"opted_out"`
+}
+
+type CampaignRepository interface {
+	GetCampaignAudience(ctx context.Context, q AudienceQuery) ([]CampaignTarget, error)
+	RecordCampaignSend(ctx context.Context, campaign CampaignID, userID uuid.UUID, channel CampaignChannel) (uuid.UUID, error)
+	GetCampaignStats(ctx context.Context, since time.Time) ([]*CampaignStats, error)
+	GetCampaignOptOuts(ctx context.Context, userID uuid.UUID) ([]CampaignID, error)
+	SetCampaignOptOut(ctx context.Context, userID uuid.UUID, campaign CampaignID, optedOut bool) error
+}
+
+// EmailSender delivers plain-text email
+type EmailSender interface {
+	SendEmail(ctx context.Context, to, subject, body string) error
+}
@@ -0,0 +1,189 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/metrics"
+)
+
+const campaignBatchSize = 500
+
+// CampaignSettings are the engine-wide caps applied to every campaign
+type CampaignSettings struct {
+	// FrequencyCap is the minimum gap between any two lifecycle messages to a user
+	FrequencyCap time.Duration
+	// Cooldown is the minimum gap before the same campaign is sent to a user again
+	Cooldown time.Duration
+}
+
+type CampaignService struct {
+	repo         CampaignRepository
+	notifService *NotificationService
+	email        EmailSender
+	settings     CampaignSettings
+	messages     *metrics.CounterVec
+}
+
+// NewCampaignService creates the lifecycle engine. email may be nil, in which
+// case email campaigns are delivered as push notifications.
+func NewCampaignService(repo CampaignRepository, notifService *NotificationService, email EmailSender, settings CampaignSettings, registry *metrics.Registry) *CampaignService {
+	return &CampaignService{
+		repo:         repo,
+		notifService: notifService,
+		email:        email,
+		settings:     settings,
+		messages: registry.Counter(
+			"campaign_messages_total",
+			"Lifecycle campaign messages by campaign, channel and result",
+			"campaign", "channel", "result",
+		),
+	}
+}
+
+// Run evaluates every campaign on each interval
+func (s *CampaignService) Run(ctx context.Context, interval time.Duration, jobs *JobTracker) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			jobs.Track(ctx, JobCampaigns, s.runAll)
+		}
+	}
+}
+
+// runAll runs every campaign once, returning the messages sent and the
+// campaigns that failed
+func (s *CampaignService) runAll(ctx context.Context) (int64, error) {
+	var total int64
+	var errs []error
+	for i := range LifecycleCampaigns {
+		sent, err := s.RunCampaign(ctx, &LifecycleCampaigns[i], time.Now())
+		if err != nil {
+			log.Printf("campaign %s: %v", LifecycleCampaigns[i].ID, err)
+			errs = append(errs, fmt.Errorf("campaign %s: %w", LifecycleCampaigns[i].ID, err))
+		}
+		if sent > 0 {
+			log.Printf("campaign %s: sent %d messages", LifecycleCampaigns[i].ID, sent)
+		}
+		total += int64(sent)
+	}
+	return total, errors.Join(errs...)
+}
+
+// RunCampaign sends a campaign to every user its rule currently matches
+func (s *CampaignService) RunCampaign(ctx context.Context, c *Campaign, now time.Time) (int, error) {
+	sent := 0
+	after := uuid.Nil
+
+	for {
+		targets, err := s.repo.GetCampaignAudience(ctx, AudienceQuery{
+			Campaign:     c.ID,
+			Rule:         c.Rule,
+			Threshold:    c.Threshold,
+			Cooldown:     s.settings.Cooldown,
+			FrequencyCap: s.settings.FrequencyCap,
+			Now:          now,
+			After:        after,
+			Limit:        campaignBatchSize,
+		})
+		if err != nil {
+			return sent, err
+		}
+
+		for _, target := range targets {
+			if err := s.send(ctx, c, target); err != nil {
+				log.Printf("campaign %s to %s failed: %v", c.ID, target.UserID, err)
+				continue
+			}
+			sent++
+		}
+
+		if len(targets) < campaignBatchSize {
+			return sent, nil
+		}
+		after = targets[len(targets)-1].UserID
+	}
+}
+
+func (s *CampaignService) send(ctx context.Context, c *Campaign, target CampaignTarget) error {
+	channel := c.Channel
+	if channel == CampaignChannelEmail && (s.email == nil || target.Email == nil) {
+		channel = CampaignChannelPush
+	}
+
+	// Record first so a failed delivery still counts against the frequency cap
+	// and can't cause a retry storm
+	sendID, err := s.repo.RecordCampaignSend(ctx, c.ID, target.UserID, channel)
+	if err != nil {
+		return err
+	}
+
+	title, body := c.Render(target)
+	if channel == CampaignChannelEmail {
+		err = s.email.SendEmail(ctx, *target.Email, title, body)
+	} else {
+		err = s.notifService.SendNotification(ctx, target.UserID, "campaign", title, body, map[string]interface{}{
+			"campaign": string(c.ID),
+			"send_id":  sendID.String(),
+		})
+	}
+
+	result := "sent"
+	if err != nil {
+		result = "failed"
+	}
+	s.messages.Inc(string(c.ID), string(channel), result)
+	return err
+}
+
+// GetStats returns per-campaign performance since the given time
+func (s *CampaignService) GetStats(ctx context.Context, since time.Time) ([]*CampaignStats, error) {
+	stats, err := s.repo.GetCampaignStats(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	for _, st := range stats {
+		if st.Sent > 0 {
+			st.OpenRate = float64(st.Opened) / float64(st.Sent)
+			st.ConversionRate = float64(st.Converted) / float64(st.Sent)
+		}
+	}
+	return stats, nil
+}
+
+// GetPreferences lists every campaign and whether the user is opted out of it
+func (s *CampaignService) GetPreferences(ctx context.Context, userID uuid.UUID) ([]CampaignPreference, error) {
+	optOuts, err := s.repo.GetCampaignOptOuts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[CampaignID]bool, len(optOuts))
+	for _, id := range optOuts {
+		out[id] = true
+	}
+
+	prefs := []CampaignPreference{{Campaign: CampaignAll, OptedOut: out[CampaignAll]}}
+	for _, c := range LifecycleCampaigns {
+		prefs = append(prefs, CampaignPreference{Campaign: c.ID, OptedOut: out[CampaignAll] || out[c.ID]})
+	}
+	return prefs, nil
+}
+
+// SetOptOut opts a user in or out of a campaign, or of all campaigns with CampaignAll
+func (s *CampaignService) SetOptOut(ctx context.Context, userID uuid.UUID, campaign CampaignID, optedOut bool) error {
+	if campaign != CampaignAll {
+		if _, err := FindCampaign(campaign); err != nil {
+			return err
+		}
+	}
+	return s.repo.SetCampaignOptOut(ctx, userID, campaign, optedOut)
+}
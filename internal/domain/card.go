@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrCardNotFound = errors.New("card not found")
+	ErrInvalidCard  = errors.New("invalid card")
+)
+
+// MaxCards caps how many cards a user is served at once
+const MaxCards = 10
+
+// CardKind is what a card is for; clients pick a layout by kind
+type CardKind string
+
+const (
+	CardKindWhatsNew CardKind = "whats_new"
+	CardKindTip      CardKind = "tip"
+	CardKindPrompt   CardKind = "prompt"
+)
+
+// CardSegment selects which users see a card
+type CardSegment string
+
+const (
+	CardSegmentAll CardSegment = "all"
+	// CardSegmentNewUsers matches accounts younger than NewUserWindow
+	CardSegmentNewUsers CardSegment = "new_users"
+	// CardSegmentNoStories matches users who have never posted a story
+	CardSegmentNoStories CardSegment = "no_stories"
+	// CardSegmentNoConnections matches users without an accepted connection
+	CardSegmentNoConnections CardSegment = "no_connections"
+)
+
+// NewUserWindow is how long an account counts as new for card targeting
+const NewUserWindow = 7 * 24 * time.Hour
+
+// Card is a server-driven in-app message
+type Card struct {
+	ID          uuid.UUID   `json:"id"`
+	Kind        CardKind    `json:"kind"`
+	Title       string      `json:"title"`
+	Body        string      `json:"body"`
+	ImageURL    *string     `json:"image_url,omitempty"`
+	ActionLabel *string     `json:"action_label,omitempty"`
+	ActionURL   *string     `json:"action_url,omitempty"`
+	Segment     CardSegment `json:"segment"`
+	Platform    Platform    `json:"platform"`
+	Priority    int         `json:"priority"`
+	StartsAt    time.Time   `json:"starts_at"`
+	EndsAt      *time.Time  `json:"ends_at,omitempty"`
+	Active      bool        `json:"active"`
+	CreatedBy   *uuid.UUID  `json:"created_by,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+type CreateCardParams struct {
+	Kind        CardKind    `json:"kind"`
+	Title       string      `json:"title"`
+	Body        string      `json:"body"`
+	ImageURL    *string     `json:"image_url"`
+	ActionLabel *string     `json:"action_label"`
+	ActionURL   *string     `json:"action_url"`
+	Segment     CardSegment `json:"segment"`
+	Platform    Platform    `json:"platform"`
+	Priority    int         `json:"priority"`
+	StartsAt    *time.Time  `json:"starts_at"`
+	EndsAt      *time.Time  `json:"ends_at"`
+	CreatedBy   uuid.UUID   `json:"-"`
+}
+
+type CardRepository interface {
+	CreateCard(ctx context.Context, params CreateCardParams) (*Card, error)
+	GetCards(ctx context.Context, activeOnly bool, limit, offset int) ([]*Card, error)
+	DeactivateCard(ctx context.Context, cardID uuid.UUID) error
+	// GetCardsForUser returns live, undismissed cards whose segment matches
+	// the user and whose platform matches platform, highest priority first
+	GetCardsForUser(ctx context.Context, userID uuid.UUID, platform Platform, limit int) ([]*Card, error)
+	DismissCard(ctx context.Context, userID, cardID uuid.UUID) error
+}
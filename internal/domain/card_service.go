@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type CardService struct {
+	repo CardRepository
+}
+
+func NewCardService(repo CardRepository) *CardService {
+	return &CardService{repo: repo}
+}
+
+// GetCards returns the cards to show a user, in display order
+func (s *CardService) GetCards(ctx context.Context, userID uuid.UUID, platform Platform) ([]*Card, error) {
+	return s.repo.GetCardsForUser(ctx, userID, platform, MaxCards)
+}
+
+// DismissCard hides a card for a user on every device
+func (s *CardService) DismissCard(ctx context.Context, userID, cardID uuid.UUID) error {
+	return s.repo.DismissCard(ctx, userID, cardID)
+}
+
+// CreateCard publishes a card
+func (s *CardService) CreateCard(ctx context.Context, params CreateCardParams) (*Card, error) {
+	params.Title = strings.TrimSpace(params.Title)
+	params.Body = strings.TrimSpace(params.Body)
+	if params.Segment == "" {
+		params.Segment = CardSegmentAll
+	}
+	if err := validateCard(params); err != nil {
+		return nil, err
+	}
+	return s.repo.CreateCard(ctx, params)
+}
+
+// ListCards lists cards for admins, newest first
+func (s *CardService) ListCards(ctx context.Context, activeOnly bool, limit, offset int) ([]*Card, error) {
+	limit, offset = AdminPageLimits.Clamp(limit, offset)
+	return s.repo.GetCards(ctx, activeOnly, limit, offset)
+}
+
+// DeactivateCard stops serving a card
+func (s *CardService) DeactivateCard(ctx context.Context, cardID uuid.UUID) error {
+	return s.repo.DeactivateCard(ctx, cardID)
+}
+
+func validateCard(p CreateCardParams) error {
+	switch p.Kind {
+	case CardKindWhatsNew, CardKindTip, CardKindPrompt:
+	default:
+		return ErrInvalidCard
+	}
+	switch p.Segment {
+	case CardSegmentAll, CardSegmentNewUsers, CardSegmentNoStories, CardSegmentNoConnections:
+	default:
+		return ErrInvalidCard
+	}
+	if _, err := ParsePlatform(string(p.Platform)); err != nil {
+		return ErrInvalidCard
+	}
+	if p.Title == "" || len(p.Title) > 100 || p.Body == "" {
+		return ErrInvalidCard
+	}
+	if p.ActionLabel != nil && len(*p.ActionLabel) > 50 {
+		return ErrInvalidCard
+	}
+	if p.EndsAt != nil && p.StartsAt != nil && !p.EndsAt.After(*p.StartsAt) {
+		return ErrInvalidCard
+	}
+	return nil
+}
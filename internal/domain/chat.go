@@ -2,32 +2,138 @@ package domain
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+var (
+	ErrCannotChatSelf     = errors.New("cannot start a chat with yourself")
+	ErrChatTargetNotFound = errors.New("chat target user not found")
+	ErrChatBlocked        = errors.New("cannot chat with this user")
+	ErrChatNotFound       = errors.New("chat not found")
+	// ErrInvalidMessageCursor is returned for a before or after cursor that
+	// isn't a message in the chat
+	ErrInvalidMessageCursor = errors.New("cursor is not a message in this chat")
+)
+
 type Chat struct {
-	ID          uuid.UUID       `json:"id"`
-	Users       []*UserResponse `json:"users,omitempty"`
-	LastMessage *Message        `json:"last_message,omitempty"`
-	CreatedAt   time.Time       `json:"created_at"`
-	UpdatedAt   time.Time       `json:"updated_at"`
+	ID      uuid.UUID `json:"id"`
+	IsGroup bool      `json:"is_group"`
+	// Name and AvatarURL are set for group chats only
+	Name      *string         `json:"name,omitempty"`
+	AvatarURL *string         `json:"avatar_url,omitempty"`
+	Users     []*UserResponse `json:"users,omitempty"`
+	// AdminIDs are the group members who can manage it
+	AdminIDs    []uuid.UUID `json:"admin_ids,omitempty"`
+	LastMessage *Message    `json:"last_message,omitempty"`
+	FrozenAt    *time.Time  `json:"frozen_at,omitempty"`
+	UnreadCount int         `json:"unread_count"`
+	// MessageRetentionSeconds is how long messages are kept, if not forever
+	MessageRetentionSeconds *int `json:"message_retention_seconds,omitempty"`
+	// Preferences and Draft are the requesting user's own, set in their chat list
+	Preferences *ChatPreferences `json:"preferences,omitempty"`
+	Draft       *ChatDraft       `json:"draft,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
 }
 
 type Message struct {
-	ID        uuid.UUID  `json:"id"`
-	ChatID    uuid.UUID  `json:"chat_id"`
-	SenderID  uuid.UUID  `json:"sender_id"`
-	Content   string     `json:"content"`
+	ID       uuid.UUID `json:"id"`
+	ChatID   uuid.UUID `json:"chat_id"`
+	SenderID uuid.UUID `json:"sender_id"`
+	// Kind is MessageKindText, MessageKindStoryShare, MessageKindStoryReply,
+	// MessageKindVoice or MessageKindDeleted
+	Kind    string `json:"kind"`
+	Content string `json:"content"`
+	// SharedStory is set on story shares, whose Content is an optional note,
+	// and on story replies, the story replied to
+	SharedStory *SharedStory `json:"shared_story,omitempty"`
+	// Media is set on voice notes, whose Content is empty
+	Media *MessageMedia `json:"media,omitempty"`
+	// Status is MessageStatusSent, MessageStatusDelivered or MessageStatusRead
+	Status    string     `json:"status"`
 	ReadAt    *time.Time `json:"read_at,omitempty"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 	CreatedAt time.Time  `json:"created_at"`
 }
 
+// MessagePage selects a page of a chat's messages, newest first. With Before
+// set it holds the messages older than that one; with After, the oldest of
+// those newer than it, for catching up. Otherwise it starts at the newest
+// message, skipping Offset. With Since set, only messages after it are
+// included, hiding history the reader cleared.
+type MessagePage struct {
+	Limit  int
+	Offset int
+	Before *uuid.UUID
+	After  *uuid.UUID
+	Since  *time.Time
+}
+
+// ReadReceipt tells a sender which of their messages a reader has read
+type ReadReceipt struct {
+	ChatID     uuid.UUID   `json:"chat_id"`
+	ReaderID   uuid.UUID   `json:"reader_id"`
+	SenderID   uuid.UUID   `json:"-"`
+	MessageIDs []uuid.UUID `json:"message_ids"`
+	ReadAt     time.Time   `json:"read_at"`
+}
+
 type ChatRepository interface {
+	ChatHistoryRepository
 	CreateChat(ctx context.Context, user1ID, user2ID uuid.UUID) (*Chat, error)
+	// CreateGroupChat creates a group with the creator as its admin
+	CreateGroupChat(ctx context.Context, creatorID uuid.UUID, name string, avatarURL *string, memberIDs []uuid.UUID) (*Chat, error)
+	// UpdateGroupChat changes the fields that are non-nil; an empty avatar URL clears it
+	UpdateGroupChat(ctx context.Context, chatID uuid.UUID, name, avatarURL *string) error
+	// AddChatParticipants adds members to a group, skipping those already in
+	// it, or returns ErrGroupChatFull if that would take it past maxMembers
+	AddChatParticipants(ctx context.Context, chatID uuid.UUID, userIDs []uuid.UUID, maxMembers int) error
+	// RemoveChatParticipant removes a member from a group. If no admin is
+	// left, the longest-standing member becomes one; the last member leaving
+	// deletes the group.
+	RemoveChatParticipant(ctx context.Context, chatID, userID uuid.UUID) error
+	SetChatParticipantRole(ctx context.Context, chatID, userID uuid.UUID, role ChatRole) error
 	GetChatByID(ctx context.Context, chatID uuid.UUID) (*Chat, error)
+	// IsChatParticipant returns ErrChatNotFound for a chat that doesn't exist
+	IsChatParticipant(ctx context.Context, chatID, userID uuid.UUID) (bool, error)
+	// GetChatsByUserID lists all the user's chats with their preferences and
+	// drafts, pinned ones first, archived or not
 	GetChatsByUserID(ctx context.Context, userID uuid.UUID) ([]*Chat, error)
+	// SetChatMute mutes the chat for the user until the given time, or
+	// indefinitely if it is nil, or unmutes it
+	SetChatMute(ctx context.Context, chatID, userID uuid.UUID, muted bool, until *time.Time) (*ChatPreferences, error)
+	SetChatArchived(ctx context.Context, chatID, userID uuid.UUID, archived bool) (*ChatPreferences, error)
+	// SetChatPinned returns ErrTooManyPinnedChats if pinning would take the
+	// user past maxPinned
+	SetChatPinned(ctx context.Context, chatID, userID uuid.UUID, pinned bool, maxPinned int) (*ChatPreferences, error)
+	// SetChatDraft replaces the user's draft for the chat; empty content clears it
+	SetChatDraft(ctx context.Context, chatID, userID uuid.UUID, content string) (*ChatDraft, error)
+	// GetMutedChatParticipants lists the members who have the chat muted now
+	GetMutedChatParticipants(ctx context.Context, chatID uuid.UUID) ([]uuid.UUID, error)
 	CreateMessage(ctx context.Context, chatID, senderID uuid.UUID, content string) (*Message, error)
-	GetMessages(ctx context.Context, chatID uuid.UUID, limit, offset int) ([]*Message, error)
+	CreateStoryShare(ctx context.Context, chatID, senderID uuid.UUID, content string, story *SharedStory) (*Message, error)
+	CreateStoryReply(ctx context.Context, chatID, senderID uuid.UUID, content string, story *SharedStory) (*Message, error)
+	CreateVoiceNote(ctx context.Context, chatID, senderID uuid.UUID, media *MessageMedia) (*Message, error)
+	// GetViewableStory returns an active story the viewer can see, or ErrStoryNotFound
+	GetViewableStory(ctx context.Context, viewerID, storyID uuid.UUID) (*CommentableStory, error)
+	// GetStorySnapshot returns what a share of an active story shows, or ErrStoryNotFound
+	GetStorySnapshot(ctx context.Context, storyID uuid.UUID) (*SharedStory, error)
+	// GetMessages returns ErrInvalidMessageCursor if the page's cursor isn't a message in the chat
+	GetMessages(ctx context.Context, chatID uuid.UUID, page MessagePage) ([]*Message, error)
+	// SearchMessages returns the chat's messages after since matching the
+	// query, best first, or ErrMessageSearchUnavailable while messages are
+	// encrypted. Deleted messages are never matched.
+	SearchMessages(ctx context.Context, chatID uuid.UUID, query string, since *time.Time, limit, offset int) ([]*Message, error)
+	// MarkMessagesRead sets read_at on the chat's unread messages from
+	// everyone but readerID, returning one receipt per sender
+	MarkMessagesRead(ctx context.Context, chatID, readerID uuid.UUID) ([]*ReadReceipt, error)
+	// MarkMessagesDelivered marks the user's receipts for the given messages
+	// delivered, returning one update per sender for those not already marked
+	MarkMessagesDelivered(ctx context.Context, chatID, userID uuid.UUID, messageIDs []uuid.UUID) ([]*DeliveryUpdate, error)
+	// MarkMessageDeliveredTo marks a message delivered to the given
+	// recipients, returning one update per recipient not already marked
+	MarkMessageDeliveredTo(ctx context.Context, messageID uuid.UUID, userIDs []uuid.UUID) ([]*DeliveryUpdate, error)
 }
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ChatDraft is a user's unsent text for a chat, kept so it follows them
+// across devices. An empty Content means the draft was cleared.
+type ChatDraft struct {
+	ChatID    uuid.UUID `json:"chat_id"`
+	Content   string    `json:"content"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Canned quick replies, picked by what the last message from someone else was
+var (
+	storyShareReplies = []string{"😍", "Love this!", "Where is this?"}
+	questionReplies   = []string{"Yes", "No", "Not sure"}
+	textReplies       = []string{"👍", "Haha", "Sounds good"}
+)
+
+// SaveDraft stores the user's draft for a chat, replacing the one saved
+// from any of their devices. Blank content clears it.
+func (s *ChatService) SaveDraft(ctx context.Context, chatID, userID uuid.UUID, content string) (*ChatDraft, error) {
+	content, err := sanitizeWithin(content, s.text.MaxMessageLength, ErrMessageTooLong)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireParticipant(ctx, chatID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.SetChatDraft(ctx, chatID, userID, content)
+}
+
+// SuggestReplies returns quick replies to the chat's last message, or none
+// if the user sent it themselves, it was deleted or the chat is empty
+func (s *ChatService) SuggestReplies(ctx context.Context, chatID, userID uuid.UUID) ([]string, error) {
+	clearedAt, err := s.repo.GetChatClearedAt(ctx, chatID, userID)
+	if err != nil {
+		return nil, err
+	}
+	last, err := s.repo.GetMessages(ctx, chatID, MessagePage{Limit: 1, Since: clearedAt})
+	if err != nil {
+		return nil, err
+	}
+	if len(last) == 0 || last[0].SenderID == userID || last[0].Kind == MessageKindDeleted {
+		return []string{}, nil
+	}
+	return suggestedReplies(last[0]), nil
+}
+
+func suggestedReplies(msg *Message) []string {
+	switch {
+	case msg.Kind == MessageKindStoryShare:
+		return storyShareReplies
+	case strings.HasSuffix(strings.TrimSpace(msg.Content), "?"):
+		return questionReplies
+	default:
+		return textReplies
+	}
+}
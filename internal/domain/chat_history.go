@@ -0,0 +1,152 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Who a chat's history is cleared for
+const (
+	ClearForMe       = "me"
+	ClearForEveryone = "everyone"
+)
+
+// MessageKindDeleted is a message cleared for everyone or past its chat's
+// retention. It keeps its place in the chat but nothing it said.
+const MessageKindDeleted = "deleted"
+
+// MessageRetentionOptions are the retention periods a chat can be set to
+var MessageRetentionOptions = []time.Duration{
+	24 * time.Hour,
+	7 * 24 * time.Hour,
+	30 * 24 * time.Hour,
+	90 * 24 * time.Hour,
+}
+
+var (
+	ErrInvalidClearScope = errors.New(`for must be "me" or "everyone"`)
+	ErrInvalidRetention  = errors.New("message_retention_seconds must be 0 or one of 86400, 604800, 2592000, 7776000")
+)
+
+type ChatHistoryRepository interface {
+	// GetChatClearedAt returns when the user last cleared the chat for
+	// themselves, or ErrNotChatParticipant if they aren't in it
+	GetChatClearedAt(ctx context.Context, chatID, userID uuid.UUID) (*time.Time, error)
+	// ClearChatHistory hides the chat's messages so far from the user
+	ClearChatHistory(ctx context.Context, chatID, userID uuid.UUID) error
+	// DeleteChatMessages tombstones all the chat's messages, returning the
+	// voice notes whose files are no longer referenced
+	DeleteChatMessages(ctx context.Context, chatID uuid.UUID) (mediaURLs []string, err error)
+	// SetChatMessageRetention sets how long the chat keeps messages; nil keeps them
+	SetChatMessageRetention(ctx context.Context, chatID uuid.UUID, seconds *int) error
+	// DeleteExpiredMessages tombstones messages past their chat's retention,
+	// returning how many and the voice notes whose files are no longer referenced
+	DeleteExpiredMessages(ctx context.Context) (deleted int64, mediaURLs []string, err error)
+}
+
+// ClearHistory clears a chat's messages so far. For the user alone, they
+// stop seeing them; for everyone, they are tombstoned, which in a group only
+// an admin can do. A frozen chat can't be cleared for everyone, as it is
+// waiting on review.
+func (s *ChatService) ClearHistory(ctx context.Context, chatID, userID uuid.UUID, scope string) (*Chat, error) {
+	if scope != ClearForMe && scope != ClearForEveryone {
+		return nil, ErrInvalidClearScope
+	}
+	chat, err := s.GetChat(ctx, chatID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if scope == ClearForMe {
+		if err := s.repo.ClearChatHistory(ctx, chatID, userID); err != nil {
+			return nil, err
+		}
+		return chat, nil
+	}
+
+	if chat.FrozenAt != nil {
+		return nil, ErrChatFrozen
+	}
+	if chat.IsGroup && !chatHasAdmin(chat, userID) {
+		return nil, ErrNotChatAdmin
+	}
+	mediaURLs, err := s.repo.DeleteChatMessages(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+	s.deleteMessageMedia(ctx, mediaURLs)
+	return chat, nil
+}
+
+// SetMessageRetention makes a chat delete its messages once they are older
+// than retention, or keep them if it is zero. In a group only admins can.
+func (s *ChatService) SetMessageRetention(ctx context.Context, chatID, userID uuid.UUID, retention time.Duration) (*Chat, error) {
+	var seconds *int
+	if retention != 0 {
+		if !validRetention(retention) {
+			return nil, ErrInvalidRetention
+		}
+		secs := int(retention / time.Second)
+		seconds = &secs
+	}
+
+	chat, err := s.GetChat(ctx, chatID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if chat.IsGroup && !chatHasAdmin(chat, userID) {
+		return nil, ErrNotChatAdmin
+	}
+	if err := s.repo.SetChatMessageRetention(ctx, chatID, seconds); err != nil {
+		return nil, err
+	}
+	return s.repo.GetChatByID(ctx, chatID)
+}
+
+func validRetention(retention time.Duration) bool {
+	for _, option := range MessageRetentionOptions {
+		if retention == option {
+			return true
+		}
+	}
+	return false
+}
+
+// RunRetention deletes messages past their chat's retention every interval
+// until ctx is cancelled
+func (s *ChatService) RunRetention(ctx context.Context, interval time.Duration, jobs *JobTracker) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			jobs.Track(ctx, JobMessageRetention, s.deleteExpired)
+		}
+	}
+}
+
+func (s *ChatService) deleteExpired(ctx context.Context) (int64, error) {
+	deleted, mediaURLs, err := s.repo.DeleteExpiredMessages(ctx)
+	if err != nil {
+		log.Printf("chat: failed to delete expired messages: %v", err)
+		return 0, err
+	}
+	s.deleteMessageMedia(ctx, mediaURLs)
+	return deleted, nil
+}
+
+// deleteMessageMedia removes the files of deleted voice notes
+func (s *ChatService) deleteMessageMedia(ctx context.Context, urls []string) {
+	for _, url := range urls {
+		if err := s.storage.DeleteFile(ctx, url); err != nil {
+			log.Printf("chat: failed to delete voice note %s: %v", url, err)
+		}
+	}
+}
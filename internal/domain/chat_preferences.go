@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidMuteUntil   = errors.New("muted_until must be in the future")
+	ErrTooManyPinnedChats = errors.New("too many pinned chats")
+)
+
+// MaxPinnedChats is the most chats a user can pin
+const MaxPinnedChats = 5
+
+// ChatPreferences are one member's own settings for a chat. A muted chat
+// sends no push notifications; MutedUntil is nil while it is muted until
+// unmuted. Archived chats are left out of the chat list, and pinned ones
+// head it.
+type ChatPreferences struct {
+	Muted      bool       `json:"muted"`
+	MutedUntil *time.Time `json:"muted_until,omitempty"`
+	Archived   bool       `json:"archived"`
+	Pinned     bool       `json:"pinned"`
+}
+
+// MuteChat stops push notifications from a chat for the user until the
+// given time, or until it is unmuted if until is nil
+func (s *ChatService) MuteChat(ctx context.Context, chatID, userID uuid.UUID, until *time.Time) (*ChatPreferences, error) {
+	if until != nil && !until.After(time.Now()) {
+		return nil, ErrInvalidMuteUntil
+	}
+	if err := s.requireParticipant(ctx, chatID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.SetChatMute(ctx, chatID, userID, true, until)
+}
+
+// UnmuteChat turns push notifications from a chat back on for the user
+func (s *ChatService) UnmuteChat(ctx context.Context, chatID, userID uuid.UUID) (*ChatPreferences, error) {
+	if err := s.requireParticipant(ctx, chatID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.SetChatMute(ctx, chatID, userID, false, nil)
+}
+
+// SetChatArchived moves a chat out of the user's chat list or back into
+// it. Archiving a chat unpins it.
+func (s *ChatService) SetChatArchived(ctx context.Context, chatID, userID uuid.UUID, archived bool) (*ChatPreferences, error) {
+	if err := s.requireParticipant(ctx, chatID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.SetChatArchived(ctx, chatID, userID, archived)
+}
+
+// SetChatPinned pins a chat to the top of the user's chat list, or unpins
+// it. Pinning an archived chat unarchives it.
+func (s *ChatService) SetChatPinned(ctx context.Context, chatID, userID uuid.UUID, pinned bool) (*ChatPreferences, error) {
+	if err := s.requireParticipant(ctx, chatID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.SetChatPinned(ctx, chatID, userID, pinned, MaxPinnedChats)
+}
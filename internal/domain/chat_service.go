@@ -2,84 +2,202 @@ package domain
 
 import (
 	"context"
+	"errors"
+	"log"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/storage"
 )
 
 type ChatService struct {
 	repo         ChatRepository
+	userRepo     AuthRepository
+	connRepo     ConnectionRepository
 	notifService *NotificationService
+	storage      storage.FileStorage
+	text         TextPolicy
+	voice        VoiceNotePolicy
 }
 
-func NewChatService(repo ChatRepository, notifService *NotificationService) *ChatService {
+func NewChatService(repo ChatRepository, userRepo AuthRepository, connRepo ConnectionRepository, notifService *NotificationService, storage storage.FileStorage, text TextPolicy, voice VoiceNotePolicy) *ChatService {
 	return &ChatService{
 		repo:         repo,
+		userRepo:     userRepo,
+		connRepo:     connRepo,
 		notifService: notifService,
+		storage:      storage,
+		text:         text,
+		voice:        voice,
 	}
 }
 
-func (s *ChatService) CreateChat(ctx context.Context, user1ID, user2ID uuid.UUID) (*Chat, error) {
-	if user1ID == user2ID {
-		// return nil, errors.New("cannot chat with self")
-		// Or handle appropriately. For now let repository handle or fail.
+// CreateChat starts (or returns the existing) 1:1 chat between the caller and an
+// active target user who hasn't been blocked by, or blocked, the caller
+func (s *ChatService) CreateChat(ctx context.Context, userID, targetID uuid.UUID) (*Chat, error) {
+	if userID == targetID {
+		return nil, ErrCannotChatSelf
 	}
-	return s.repo.CreateChat(ctx, user1ID, user2ID)
+
+	// GetUserByID only returns active users
+	if _, err := s.userRepo.GetUserByID(ctx, targetID); err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, ErrChatTargetNotFound
+		}
+		return nil, err
+	}
+
+	conn, err := s.connRepo.GetConnectionBetween(ctx, userID, targetID)
+	if err != nil && !errors.Is(err, ErrConnectionNotFound) {
+		return nil, err
+	}
+	if conn != nil && conn.Status == ConnectionStatusBlocked {
+		return nil, ErrChatBlocked
+	}
+
+	return s.repo.CreateChat(ctx, userID, targetID)
 }
 
-func (s *ChatService) GetUserChats(ctx context.Context, userID uuid.UUID) ([]*Chat, error) {
-	return s.repo.GetChatsByUserID(ctx, userID)
+// GetUserChats lists the user's archived chats, or the rest of them with
+// pinned ones first
+func (s *ChatService) GetUserChats(ctx context.Context, userID uuid.UUID, archived bool) ([]*Chat, error) {
+	chats, err := s.repo.GetChatsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	listed := make([]*Chat, 0, len(chats))
+	for _, chat := range chats {
+		if chat.Preferences.Archived == archived {
+			listed = append(listed, chat)
+		}
+	}
+	return listed, nil
 }
 
-func (s *ChatService) GetChat(ctx context.Context, chatID uuid.UUID) (*Chat, error) {
+// GetChat returns a chat the user takes part in
+func (s *ChatService) GetChat(ctx context.Context, chatID, userID uuid.UUID) (*Chat, error) {
+	if err := s.requireParticipant(ctx, chatID, userID); err != nil {
+		return nil, err
+	}
 	return s.repo.GetChatByID(ctx, chatID)
 }
 
 func (s *ChatService) SendMessage(ctx context.Context, chatID, senderID uuid.UUID, content string) (*Message, error) {
+	content, err := s.text.Message(content)
+	if err != nil {
+		return nil, err
+	}
+
+	chat, err := s.GetChat(ctx, chatID, senderID)
+	if err != nil {
+		return nil, err
+	}
+	if chat.FrozenAt != nil {
+		return nil, ErrChatFrozen
+	}
+
 	msg, err := s.repo.CreateMessage(ctx, chatID, senderID, content)
 	if err != nil {
 		return nil, err
 	}
 
-	// Send notification asynchronously
-	go func() {
-		// We need to find the OTHER user in the chat to notify them
-		// Get participants
-		chat, err := s.repo.GetChatByID(context.Background(), chatID)
-		if err != nil {
-			return
-		}
+	go s.notifyMessage(chat, msg, content)
+	return msg, nil
+}
+
+// notifyMessage pushes a new message to everyone in the chat but its sender
+// and those who muted it. The app acks the message_id in the push data as
+// delivered.
+func (s *ChatService) notifyMessage(chat *Chat, msg *Message, preview string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
 
-		var receiverID uuid.UUID
-		var senderName string
+	muted := make(map[uuid.UUID]bool)
+	mutedIDs, err := s.repo.GetMutedChatParticipants(ctx, chat.ID)
+	if err != nil {
+		log.Printf("chat: failed to load muted members of %s: %v", chat.ID, err)
+	}
+	for _, id := range mutedIDs {
+		muted[id] = true
+	}
 
-		for _, u := range chat.Users {
-			if u.ID != senderID {
-				receiverID = u.ID
-			} else {
-				senderName = u.Name
-			}
+	senderName := "Someone"
+	for _, u := range chat.Users {
+		if u.ID == msg.SenderID {
+			senderName = u.Name
 		}
+	}
+	title, body := chatTitle(chat, senderName), preview
+	if chat.IsGroup {
+		body = senderName + ": " + preview
+	}
 
-		if receiverID != uuid.Nil {
-			_ = s.notifService.SendNotification(
-				context.Background(),
-				receiverID,
-				"message",
-				senderName,
-				content, // In prod, truncate this
-				map[string]interface{}{
-					"chat_id": chatID.String(),
-				},
-			)
+	for _, u := range chat.Users {
+		if u.ID == msg.SenderID || muted[u.ID] {
+			continue
 		}
-	}()
+		_ = s.notifService.SendNotification(
+			ctx,
+			u.ID,
+			"message",
+			title,
+			body, // In prod, truncate this
+			map[string]interface{}{
+				"chat_id":    chat.ID.String(),
+				"message_id": msg.ID.String(),
+			},
+		)
+	}
+}
 
-	return msg, nil
+// MarkRead marks everything the user has received in a chat as read. The
+// receipts, one per sender, are for telling the senders.
+func (s *ChatService) MarkRead(ctx context.Context, chatID, userID uuid.UUID) ([]*ReadReceipt, error) {
+	if err := s.requireParticipant(ctx, chatID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.MarkMessagesRead(ctx, chatID, userID)
 }
 
-func (s *ChatService) GetMessages(ctx context.Context, chatID uuid.UUID, limit, offset int) ([]*Message, error) {
-	if limit <= 0 {
-		limit = 50
+// GetMessages lists a chat's messages for one of its participants, newest
+// first, from after they last cleared it. next is the cursor for the following page in the same direction:
+// for older pages it is nil once history runs out, while catching up it is
+// always set, so the client can keep asking for newer messages.
+func (s *ChatService) GetMessages(ctx context.Context, chatID, userID uuid.UUID, page MessagePage) (messages []*Message, next *uuid.UUID, err error) {
+	page.Since, err = s.repo.GetChatClearedAt(ctx, chatID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	page.Limit, page.Offset = MessagePageLimits.Clamp(page.Limit, page.Offset)
+	if page.Before != nil || page.After != nil {
+		page.Offset = 0
+	}
+
+	messages, err = s.repo.GetMessages(ctx, chatID, page)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch {
+	case page.After != nil && len(messages) > 0:
+		next = &messages[0].ID
+	case page.After != nil:
+		next = page.After
+	case len(messages) == page.Limit:
+		next = &messages[len(messages)-1].ID
+	}
+	return messages, next, nil
+}
+
+// requireParticipant returns ErrNotChatParticipant unless the user is in the chat
+func (s *ChatService) requireParticipant(ctx context.Context, chatID, userID uuid.UUID) error {
+	ok, err := s.repo.IsChatParticipant(ctx, chatID, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotChatParticipant
 	}
-	return s.repo.GetMessages(ctx, chatID, limit, offset)
+	return nil
 }
@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrCollectionNotFound    = errors.New("collection not found")
+	ErrInvalidCollection     = errors.New("invalid collection")
+	ErrNotCollectionOwner    = errors.New("only the collection owner can do this")
+	ErrNotContributor        = errors.New("you are not an approved contributor to this collection")
+	ErrContributorNotFound   = errors.New("contribution request not found")
+	ErrCollectionClosed      = errors.New("collection is not accepting stories")
+	ErrStoryNotInCollection  = errors.New("story is not in this collection")
+	ErrStoryNotContributable = errors.New("only your own active stories can be added")
+)
+
+type CollectionKind string
+
+const (
+	CollectionKindEvent CollectionKind = "event"
+	CollectionKindPlace CollectionKind = "place"
+)
+
+// ContributionPolicy decides who may add stories to a collection
+type ContributionPolicy string
+
+const (
+	// ContributionOpen lets anyone add stories
+	ContributionOpen ContributionPolicy = "open"
+	// ContributionApproval requires the owner to approve each contributor
+	ContributionApproval ContributionPolicy = "approval"
+)
+
+type ContributorStatus string
+
+const (
+	ContributorPending  ContributorStatus = "pending"
+	ContributorApproved ContributorStatus = "approved"
+	ContributorRejected ContributorStatus = "rejected"
+)
+
+// StoryCollection is a shared story reel for an event or place
+type StoryCollection struct {
+	ID                 uuid.UUID          `json:"id"`
+	OwnerID            uuid.UUID          `json:"owner_id"`
+	Kind               CollectionKind     `json:"kind"`
+	Title              string             `json:"title"`
+	Description        *string            `json:"description,omitempty"`
+	LocationLat        *float64           `json:"location_lat,omitempty"`
+	LocationLng        *float64           `json:"location_lng,omitempty"`
+	ContributionPolicy ContributionPolicy `json:"contribution_policy"`
+	StartsAt           time.Time          `json:"starts_at"`
+	EndsAt             *time.Time         `json:"ends_at,omitempty"`
+	CreatedAt          time.Time          `json:"created_at"`
+	// StoryCount is the number of active stories in the reel
+	StoryCount int `json:"story_count"`
+}
+
+// Open reports whether the collection accepts stories at t
+func (c *StoryCollection) Open(t time.Time) bool {
+	return !t.Before(c.StartsAt) && (c.EndsAt == nil || t.Before(*c.EndsAt))
+}
+
+type CollectionContributor struct {
+	CollectionID uuid.UUID         `json:"collection_id"`
+	UserID       uuid.UUID         `json:"user_id"`
+	Status       ContributorStatus `json:"status"`
+	RequestedAt  time.Time         `json:"requested_at"`
+	DecidedAt    *time.Time        `json:"decided_at,omitempty"`
+}
+
+type CreateCollectionParams struct {
+	OwnerID            uuid.UUID          `json:"-"`
+	Kind               CollectionKind     `json:"kind"`
+	Title              string             `json:"title"`
+	Description        *string            `json:"description"`
+	LocationLat        *float64           `json:"location_lat"`
+	LocationLng        *float64           `json:"location_lng"`
+	ContributionPolicy ContributionPolicy `json:"contribution_policy"`
+	StartsAt           *time.Time         `json:"starts_at"`
+	EndsAt             *time.Time         `json:"ends_at"`
+}
+
+type CollectionRepository interface {
+	CreateCollection(ctx context.Context, params CreateCollectionParams) (*StoryCollection, error)
+	GetCollection(ctx context.Context, id uuid.UUID) (*StoryCollection, error)
+	// RequestContribution records a contributor with the given status, leaving an existing row unchanged
+	RequestContribution(ctx context.Context, collectionID, userID uuid.UUID, status ContributorStatus) (*CollectionContributor, error)
+	GetContributor(ctx context.Context, collectionID, userID uuid.UUID) (*CollectionContributor, error)
+	GetContributors(ctx context.Context, collectionID uuid.UUID, status ContributorStatus) ([]*CollectionContributor, error)
+	SetContributorStatus(ctx context.Context, collectionID, userID uuid.UUID, status ContributorStatus) (*CollectionContributor, error)
+	AddCollectionStory(ctx context.Context, collectionID, storyID uuid.UUID) error
+	RemoveCollectionStory(ctx context.Context, collectionID, storyID uuid.UUID) error
+	// GetCollectionStories returns the reel's active stories, oldest first
+	GetCollectionStories(ctx context.Context, collectionID uuid.UUID, limit, offset int) ([]*Story, error)
+	// GetStoryOwner returns the author of an active, visible story
+	GetStoryOwner(ctx context.Context, storyID uuid.UUID) (uuid.UUID, error)
+}
@@ -0,0 +1,182 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CollectionService struct {
+	repo CollectionRepository
+}
+
+func NewCollectionService(repo CollectionRepository) *CollectionService {
+	return &CollectionService{repo: repo}
+}
+
+// CreateCollection starts a shared reel owned by params.OwnerID
+func (s *CollectionService) CreateCollection(ctx context.Context, params CreateCollectionParams) (*StoryCollection, error) {
+	params.Title = strings.TrimSpace(params.Title)
+	if params.ContributionPolicy == "" {
+		params.ContributionPolicy = ContributionApproval
+	}
+	if err := validateCollection(params); err != nil {
+		return nil, err
+	}
+	return s.repo.CreateCollection(ctx, params)
+}
+
+// GetCollection returns a collection
+func (s *CollectionService) GetCollection(ctx context.Context, id uuid.UUID) (*StoryCollection, error) {
+	return s.repo.GetCollection(ctx, id)
+}
+
+// Join asks to contribute to a collection. Open collections approve right
+// away; otherwise the request waits for the owner.
+func (s *CollectionService) Join(ctx context.Context, userID, collectionID uuid.UUID) (*CollectionContributor, error) {
+	collection, err := s.repo.GetCollection(ctx, collectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := ContributorPending
+	if collection.ContributionPolicy == ContributionOpen || collection.OwnerID == userID {
+		status = ContributorApproved
+	}
+	return s.repo.RequestContribution(ctx, collectionID, userID, status)
+}
+
+// GetContributors lists a collection's contributors with a status; owner only
+func (s *CollectionService) GetContributors(ctx context.Context, ownerID, collectionID uuid.UUID, status ContributorStatus) ([]*CollectionContributor, error) {
+	if err := s.requireOwner(ctx, ownerID, collectionID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetContributors(ctx, collectionID, status)
+}
+
+// DecideContributor approves or rejects a contributor; owner only
+func (s *CollectionService) DecideContributor(ctx context.Context, ownerID, collectionID, userID uuid.UUID, approve bool) (*CollectionContributor, error) {
+	if err := s.requireOwner(ctx, ownerID, collectionID); err != nil {
+		return nil, err
+	}
+
+	status := ContributorRejected
+	if approve {
+		status = ContributorApproved
+	}
+	return s.repo.SetContributorStatus(ctx, collectionID, userID, status)
+}
+
+// AddStory adds one of the user's active stories to a collection they may contribute to
+func (s *CollectionService) AddStory(ctx context.Context, userID, collectionID, storyID uuid.UUID) error {
+	collection, err := s.repo.GetCollection(ctx, collectionID)
+	if err != nil {
+		return err
+	}
+	if !collection.Open(time.Now()) {
+		return ErrCollectionClosed
+	}
+	if err := s.requireContributor(ctx, collection, userID); err != nil {
+		return err
+	}
+
+	ownerID, err := s.repo.GetStoryOwner(ctx, storyID)
+	if err == ErrStoryNotFound || (err == nil && ownerID != userID) {
+		return ErrStoryNotContributable
+	}
+	if err != nil {
+		return err
+	}
+	return s.repo.AddCollectionStory(ctx, collectionID, storyID)
+}
+
+// RemoveStory takes a story out of a collection. The story's author and the
+// collection owner may do this.
+func (s *CollectionService) RemoveStory(ctx context.Context, userID, collectionID, storyID uuid.UUID) error {
+	collection, err := s.repo.GetCollection(ctx, collectionID)
+	if err != nil {
+		return err
+	}
+	if collection.OwnerID != userID {
+		ownerID, err := s.repo.GetStoryOwner(ctx, storyID)
+		if err == ErrStoryNotFound {
+			return ErrStoryNotInCollection
+		}
+		if err != nil {
+			return err
+		}
+		if ownerID != userID {
+			return ErrNotCollectionOwner
+		}
+	}
+	return s.repo.RemoveCollectionStory(ctx, collectionID, storyID)
+}
+
+// GetStories returns the combined reel, oldest first
+func (s *CollectionService) GetStories(ctx context.Context, collectionID uuid.UUID, page, limit int) ([]*Story, error) {
+	if _, err := s.repo.GetCollection(ctx, collectionID); err != nil {
+		return nil, err
+	}
+	limit, offset := FeedPageLimits.Page(page, limit)
+	stories, err := s.repo.GetCollectionStories(ctx, collectionID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return applyLocationPrivacy(stories), nil
+}
+
+func (s *CollectionService) requireOwner(ctx context.Context, userID, collectionID uuid.UUID) error {
+	collection, err := s.repo.GetCollection(ctx, collectionID)
+	if err != nil {
+		return err
+	}
+	if collection.OwnerID != userID {
+		return ErrNotCollectionOwner
+	}
+	return nil
+}
+
+func (s *CollectionService) requireContributor(ctx context.Context, collection *StoryCollection, userID uuid.UUID) error {
+	if collection.OwnerID == userID || collection.ContributionPolicy == ContributionOpen {
+		return nil
+	}
+	contributor, err := s.repo.GetContributor(ctx, collection.ID, userID)
+	if err == ErrContributorNotFound {
+		return ErrNotContributor
+	}
+	if err != nil {
+		return err
+	}
+	if contributor.Status != ContributorApproved {
+		return ErrNotContributor
+	}
+	return nil
+}
+
+func validateCollection(p CreateCollectionParams) error {
+	switch p.Kind {
+	case CollectionKindEvent, CollectionKindPlace:
+	default:
+		return ErrInvalidCollection
+	}
+	switch p.ContributionPolicy {
+	case ContributionOpen, ContributionApproval:
+	default:
+		return ErrInvalidCollection
+	}
+	if p.Title == "" || len(p.Title) > 100 {
+		return ErrInvalidCollection
+	}
+	if (p.LocationLat == nil) != (p.LocationLng == nil) {
+		return ErrInvalidCollection
+	}
+	if p.LocationLat != nil && (*p.LocationLat < -90 || *p.LocationLat > 90 || *p.LocationLng < -180 || *p.LocationLng > 180) {
+		return ErrInvalidCollection
+	}
+	if p.EndsAt != nil && p.StartsAt != nil && !p.EndsAt.After(*p.StartsAt) {
+		return ErrInvalidCollection
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"log"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrCommentNotFound     = errors.New("comment not found")
+	ErrCommentsDisabled    = errors.New("comments are turned off for this story")
+	ErrCannotDeleteComment = errors.New("only the comment's or the story's author can delete a comment")
+)
+
+// MaxCommentMentions caps the users one comment can @mention
+const MaxCommentMentions = 10
+
+// mentionRegex finds @handles that aren't part of an email address or another word
+var mentionRegex = regexp.MustCompile(`(?:^|[^\w.@])@([A-Za-z0-9_][A-Za-z0-9_.]{1,28}[A-Za-z0-9_])`)
+
+// Comment is a comment on a story
+type Comment struct {
+	ID      uuid.UUID `json:"id"`
+	StoryID uuid.UUID `json:"story_id"`
+	UserID  uuid.UUID `json:"user_id"`
+	Content string    `json:"content"`
+	// MentionIDs are the users @mentioned who can see the story
+	MentionIDs []uuid.UUID `json:"mention_ids,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+
+	// For API responses: the comment's author
+	User *UserResponse `json:"user,omitempty"`
+}
+
+// CommentableStory is what commenting needs to know about a story
+type CommentableStory struct {
+	ID               uuid.UUID
+	UserID           uuid.UUID
+	CommentsDisabled bool
+}
+
+// StoryCommentStats are the comment fields shown on a story in feeds
+type StoryCommentStats struct {
+	Count    int
+	Disabled bool
+}
+
+type CommentRepository interface {
+	StoryReactionRepository
+	// GetViewableStory returns an active, visible story the viewer can see:
+	// their own, or one by an active author who hasn't blocked them nor been
+	// blocked by them and is public or connected with them. Otherwise it
+	// returns ErrStoryNotFound.
+	GetViewableStory(ctx context.Context, viewerID, storyID uuid.UUID) (*CommentableStory, error)
+	// SetCommentsDisabled returns ErrStoryNotFound unless the story is one of the owner's active ones
+	SetCommentsDisabled(ctx context.Context, ownerID, storyID uuid.UUID, disabled bool) error
+	CreateComment(ctx context.Context, comment *Comment) error
+	// GetComments lists a story's visible comments, oldest first, leaving out
+	// comments from users blocked by or blocking the viewer
+	GetComments(ctx context.Context, storyID, viewerID uuid.UUID, limit, offset int) ([]*Comment, error)
+	GetComment(ctx context.Context, commentID uuid.UUID) (*Comment, error)
+	DeleteComment(ctx context.Context, commentID uuid.UUID) error
+	// GetCommenterIDs returns everyone who has a visible comment on the story
+	GetCommenterIDs(ctx context.Context, storyID uuid.UUID) ([]uuid.UUID, error)
+}
+
+type CommentService struct {
+	repo         CommentRepository
+	userRepo     AuthRepository
+	notifService *NotificationService
+	text         TextPolicy
+}
+
+func NewCommentService(repo CommentRepository, userRepo AuthRepository, notifService *NotificationService, text TextPolicy) *CommentService {
+	return &CommentService{
+		repo:         repo,
+		userRepo:     userRepo,
+		notifService: notifService,
+		text:         text,
+	}
+}
+
+// AddComment comments on a story the user can see. The story's author, the
+// users mentioned and the others in the thread are notified.
+func (s *CommentService) AddComment(ctx context.Context, userID, storyID uuid.UUID, content string) (*Comment, error) {
+	content, err := s.text.Comment(content)
+	if err != nil {
+		return nil, err
+	}
+
+	story, err := s.repo.GetViewableStory(ctx, userID, storyID)
+	if err != nil {
+		return nil, err
+	}
+	if story.CommentsDisabled {
+		return nil, ErrCommentsDisabled
+	}
+
+	mentionIDs, err := s.resolveMentions(ctx, userID, storyID, content)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &Comment{StoryID: storyID, UserID: userID, Content: content, MentionIDs: mentionIDs}
+	if err := s.repo.CreateComment(ctx, comment); err != nil {
+		return nil, err
+	}
+
+	go s.notifyComment(story, comment)
+	return comment, nil
+}
+
+// GetComments lists the comments on a story the viewer can see, oldest first
+func (s *CommentService) GetComments(ctx context.Context, viewerID, storyID uuid.UUID, limit, offset int) ([]*Comment, error) {
+	if _, err := s.repo.GetViewableStory(ctx, viewerID, storyID); err != nil {
+		return nil, err
+	}
+	limit, offset = CommentPageLimits.Clamp(limit, offset)
+	return s.repo.GetComments(ctx, storyID, viewerID, limit, offset)
+}
+
+// DeleteComment removes a comment. Its author can delete it, and so can the
+// story's author, to tidy their thread.
+func (s *CommentService) DeleteComment(ctx context.Context, userID, storyID, commentID uuid.UUID) error {
+	comment, err := s.repo.GetComment(ctx, commentID)
+	if err != nil {
+		return err
+	}
+	if comment.StoryID != storyID {
+		return ErrCommentNotFound
+	}
+
+	if comment.UserID != userID {
+		story, err := s.repo.GetViewableStory(ctx, userID, storyID)
+		if err != nil {
+			return err
+		}
+		if story.UserID != userID {
+			return ErrCannotDeleteComment
+		}
+	}
+	return s.repo.DeleteComment(ctx, commentID)
+}
+
+// SetCommentsEnabled turns commenting on one of the user's stories on or off.
+// Existing comments stay.
+func (s *CommentService) SetCommentsEnabled(ctx context.Context, userID, storyID uuid.UUID, enabled bool) error {
+	return s.repo.SetCommentsDisabled(ctx, userID, storyID, !enabled)
+}
+
+// resolveMentions returns the users @mentioned in content, leaving out the
+// author, unknown handles and anyone who can't see the story
+func (s *CommentService) resolveMentions(ctx context.Context, authorID, storyID uuid.UUID, content string) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	seen := map[string]bool{}
+	for _, match := range mentionRegex.FindAllStringSubmatch(content, -1) {
+		if len(ids) == MaxCommentMentions {
+			break
+		}
+		username, err := NormalizeUsername(match[1])
+		if err != nil || seen[username] {
+			continue
+		}
+		seen[username] = true
+
+		user, err := s.userRepo.GetUserByUsername(ctx, username)
+		if errors.Is(err, ErrUserNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if user.ID == authorID {
+			continue
+		}
+
+		// Don't tell people about stories they aren't allowed to see
+		_, err = s.repo.GetViewableStory(ctx, user.ID, storyID)
+		if errors.Is(err, ErrStoryNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, user.ID)
+	}
+	return ids, nil
+}
+
+// notifyComment tells the story's author, then the users mentioned, then the
+// rest of the thread. Nobody hears about one comment twice.
+func (s *CommentService) notifyComment(story *CommentableStory, comment *Comment) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	data := map[string]interface{}{
+		"story_id":   story.ID.String(),
+		"comment_id": comment.ID.String(),
+		"user_id":    comment.UserID.String(),
+	}
+	notified := map[uuid.UUID]bool{comment.UserID: true}
+
+	if !notified[story.UserID] {
+		notified[story.UserID] = true
+		if err := s.notifService.SendNotification(ctx, story.UserID, "story_comment", "New comment", comment.Content, data); err != nil {
+			log.Printf("comments: failed to notify story author %s: %v", story.UserID, err)
+		}
+	}
+
+	for _, id := range comment.MentionIDs {
+		if notified[id] {
+			continue
+		}
+		notified[id] = true
+		if err := s.notifService.SendNotification(ctx, id, "comment_mention", "You were mentioned", comment.Content, data); err != nil {
+			log.Printf("comments: failed to notify mention %s: %v", id, err)
+		}
+	}
+
+	commenters, err := s.repo.GetCommenterIDs(ctx, story.ID)
+	if err != nil {
+		log.Printf("comments: failed to load thread participants: %v", err)
+		return
+	}
+	var thread []uuid.UUID
+	for _, id := range commenters {
+		if !notified[id] {
+			notified[id] = true
+			thread = append(thread, id)
+		}
+	}
+	if len(thread) == 0 {
+		return
+	}
+	result, err := s.notifService.SendBulkNotification(ctx, thread, "comment_reply", "New reply", "Someone else commented on a story you commented on", data)
+	if err != nil {
+		log.Printf("comments: thread notifications: %v", err)
+		return
+	}
+	if len(result.Failed) > 0 {
+		log.Printf("comments: %d of %d thread notifications failed", len(result.Failed), len(thread))
+	}
+}
@@ -2,17 +2,69 @@ package domain
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+var (
+	ErrConnectionNotFound    = errors.New("connection not found")
+	ErrCannotConnectSelf     = errors.New("cannot connect with self")
+	ErrAlreadyConnected      = errors.New("already connected")
+	ErrConnectionCooldown    = errors.New("connection request was recently declined")
+	ErrConnectionBlocked     = errors.New("connection is blocked")
+	ErrConnectionNotPending  = errors.New("connection is not pending")
+	ErrNotConnectionReceiver = errors.New("unauthorized to respond to this request")
+)
+
+// ConnectionRejectionCooldown is how long a requester must wait before
+// re-sending a request that was declined
+const ConnectionRejectionCooldown = 7 * 24 * time.Hour
+
 type ConnectionStatus string
 
 const (
 	ConnectionStatusPending  ConnectionStatus = "pending"
 	ConnectionStatusAccepted ConnectionStatus = "accepted"
 	ConnectionStatusRejected ConnectionStatus = "rejected"
+	ConnectionStatusBlocked  ConnectionStatus = "blocked"
+)
+
+// ConnectionState is the relationship between two users as seen by one of them
+type ConnectionState string
+
+const (
+	ConnectionStateNone             ConnectionState = "none"
+	ConnectionStateOutgoing         ConnectionState = "outgoing"
+	ConnectionStateIncoming         ConnectionState = "incoming"
+	ConnectionStateConnected        ConnectionState = "connected"
+	ConnectionStateRejectedCooldown ConnectionState = "rejected_cooldown"
+	ConnectionStateBlocked          ConnectionState = "blocked"
+)
+
+// ConnectionAction is something a user does to a relationship
+type ConnectionAction string
+
+const (
+	ConnectionActionRequest ConnectionAction = "request"
+	ConnectionActionAccept  ConnectionAction = "accept"
+	ConnectionActionReject  ConnectionAction = "reject"
+	ConnectionActionRemove  ConnectionAction = "remove"
+	ConnectionActionBlock   ConnectionAction = "block"
+	ConnectionActionUnblock ConnectionAction = "unblock"
+	// ConnectionActionMutual connects two users who both reached out some
+	// other way, such as waving at each other
+	ConnectionActionMutual ConnectionAction = "mutual"
+)
+
+// ConnectionEvent is a side effect of a transition that the other user should hear about
+type ConnectionEvent string
+
+const (
+	ConnectionEventNone      ConnectionEvent = ""
+	ConnectionEventRequested ConnectionEvent = "connection_request"
+	ConnectionEventAccepted  ConnectionEvent = "connection_accepted"
 )
 
 type Connection struct {
@@ -27,10 +79,126 @@ type Connection struct {
 	User *UserResponse `json:"user,omitempty"`
 }
 
+// ConnectionUpdate describes how the single row for a user pair should change
+type ConnectionUpdate struct {
+	Delete      bool
+	RequesterID uuid.UUID
+	ReceiverID  uuid.UUID
+	Status      ConnectionStatus
+}
+
 type ConnectionRepository interface {
-	CreateConnectionRequest(ctx context.Context, requesterID, receiverID uuid.UUID) (*Connection, error)
+	// TransitionConnection locks the pair (userA, userB), passes the current row
+	// (nil if none) to decide, and persists the returned update. A nil update
+	// leaves the row unchanged. Returns the resulting row, or nil if deleted.
+	TransitionConnection(ctx context.Context, userA, userB uuid.UUID, decide func(current *Connection) (*ConnectionUpdate, error)) (*Connection, error)
+	GetConnectionBetween(ctx context.Context, userA, userB uuid.UUID) (*Connection, error)
 	UpdateConnectionStatus(ctx context.Context, connectionID uuid.UUID, status ConnectionStatus) (*Connection, error)
 	GetConnectionByID(ctx context.Context, connectionID uuid.UUID) (*Connection, error)
 	GetConnections(ctx context.Context, userID uuid.UUID, status ConnectionStatus, limit, offset int) ([]*Connection, error)
+	GetConnectedUserIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+	// GetConnectedAmong returns which of the candidates the user is connected with
+	GetConnectedAmong(ctx context.Context, userID uuid.UUID, candidateIDs []uuid.UUID) (map[uuid.UUID]bool, error)
 	DeleteConnection(ctx context.Context, connectionID uuid.UUID) error
 }
+
+// ConnectionStateFor resolves the state of conn from viewerID's side. A nil conn means no relationship.
+func ConnectionStateFor(conn *Connection, viewerID uuid.UUID, now time.Time) ConnectionState {
+	if conn == nil {
+		return ConnectionStateNone
+	}
+
+	switch conn.Status {
+	case ConnectionStatusPending:
+		if conn.RequesterID == viewerID {
+			return ConnectionStateOutgoing
+		}
+		return ConnectionStateIncoming
+	case ConnectionStatusAccepted:
+		return ConnectionStateConnected
+	case ConnectionStatusRejected:
+		// Only the declined requester is held back; the receiver may still reach out
+		if conn.RequesterID == viewerID && now.Before(conn.UpdatedAt.Add(ConnectionRejectionCooldown)) {
+			return ConnectionStateRejectedCooldown
+		}
+		return ConnectionStateNone
+	case ConnectionStatusBlocked:
+		return ConnectionStateBlocked
+	}
+	return ConnectionStateNone
+}
+
+// PlanConnectionTransition decides what happens when actorID performs action
+// towards otherID given the current row. It is pure so every transition is deterministic.
+func PlanConnectionTransition(current *Connection, actorID, otherID uuid.UUID, action ConnectionAction, now time.Time) (*ConnectionUpdate, ConnectionEvent, error) {
+	state := ConnectionStateFor(current, actorID, now)
+
+	switch action {
+	case ConnectionActionRequest:
+		switch state {
+		case ConnectionStateNone:
+			return &ConnectionUpdate{RequesterID: actorID, ReceiverID: otherID, Status: ConnectionStatusPending}, ConnectionEventRequested, nil
+		case ConnectionStateOutgoing:
+			// Already requested; don't notify again
+			return nil, ConnectionEventNone, nil
+		case ConnectionStateIncoming:
+			// Both sides want to connect, so accept the existing request
+			return &ConnectionUpdate{RequesterID: current.RequesterID, ReceiverID: current.ReceiverID, Status: ConnectionStatusAccepted}, ConnectionEventAccepted, nil
+		case ConnectionStateConnected:
+			return nil, ConnectionEventNone, ErrAlreadyConnected
+		case ConnectionStateRejectedCooldown:
+			return nil, ConnectionEventNone, ErrConnectionCooldown
+		case ConnectionStateBlocked:
+			return nil, ConnectionEventNone, ErrConnectionBlocked
+		}
+
+	case ConnectionActionAccept, ConnectionActionReject:
+		if state == ConnectionStateBlocked {
+			return nil, ConnectionEventNone, ErrConnectionBlocked
+		}
+		if state != ConnectionStateIncoming {
+			return nil, ConnectionEventNone, ErrConnectionNotPending
+		}
+		if action == ConnectionActionAccept {
+			return &ConnectionUpdate{RequesterID: current.RequesterID, ReceiverID: current.ReceiverID, Status: ConnectionStatusAccepted}, ConnectionEventAccepted, nil
+		}
+		return &ConnectionUpdate{RequesterID: current.RequesterID, ReceiverID: current.ReceiverID, Status: ConnectionStatusRejected}, ConnectionEventNone, nil
+
+	case ConnectionActionRemove:
+		// Withdraw an outgoing request or disconnect
+		if state == ConnectionStateOutgoing || state == ConnectionStateConnected {
+			return &ConnectionUpdate{Delete: true}, ConnectionEventNone, nil
+		}
+		return nil, ConnectionEventNone, ErrConnectionNotFound
+
+	case ConnectionActionBlock:
+		if state == ConnectionStateBlocked {
+			// Already blocked by either side; keep the original blocker
+			return nil, ConnectionEventNone, nil
+		}
+		return &ConnectionUpdate{RequesterID: actorID, ReceiverID: otherID, Status: ConnectionStatusBlocked}, ConnectionEventNone, nil
+
+	case ConnectionActionUnblock:
+		// Only the user who blocked can lift it
+		if state == ConnectionStateBlocked && current.RequesterID == actorID {
+			return &ConnectionUpdate{Delete: true}, ConnectionEventNone, nil
+		}
+		return nil, ConnectionEventNone, ErrConnectionNotFound
+
+	case ConnectionActionMutual:
+		// Both sides already asked, so no request is left pending. A decline
+		// doesn't stand in the way either, since the decliner waved back.
+		switch state {
+		case ConnectionStateConnected:
+			return nil, ConnectionEventNone, nil
+		case ConnectionStateBlocked:
+			return nil, ConnectionEventNone, ErrConnectionBlocked
+		}
+		if current != nil && current.Status == ConnectionStatusPending {
+			return &ConnectionUpdate{RequesterID: current.RequesterID, ReceiverID: current.ReceiverID, Status: ConnectionStatusAccepted}, ConnectionEventNone, nil
+		}
+		return &ConnectionUpdate{RequesterID: otherID, ReceiverID: actorID, Status: ConnectionStatusAccepted}, ConnectionEventNone, nil
+	}
+
+	return nil, ConnectionEventNone, errors.New("unsupported connection action")
+}
@@ -2,7 +2,7 @@ package domain
 
 import (
 	"context"
-	"errors"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -19,36 +19,69 @@ func NewConnectionService(repo ConnectionRepository, notifService *NotificationS
 	}
 }
 
-func (s *ConnectionService) SendRequest(ctx context.Context, requesterID, receiverID uuid.UUID) (*Connection, error) {
-	if requesterID == receiverID {
-		return nil, errors.New("cannot connect with self")
+// apply runs a transition for actorID towards otherID and sends the resulting notification
+func (s *ConnectionService) apply(ctx context.Context, actorID, otherID uuid.UUID, action ConnectionAction) (*Connection, error) {
+	if actorID == otherID {
+		return nil, ErrCannotConnectSelf
 	}
-	conn, err := s.repo.CreateConnectionRequest(ctx, requesterID, receiverID)
+
+	var event ConnectionEvent
+	conn, err := s.repo.TransitionConnection(ctx, actorID, otherID, func(current *Connection) (*ConnectionUpdate, error) {
+		update, ev, err := PlanConnectionTransition(current, actorID, otherID, action, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		event = ev
+		return update, nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Notify receiver
-	go func() {
-		// Need requester name. Ideally service should look it up or accept it.
-		// For now simple generic message or fetch user
-		// Not injecting UserRepo here to avoid bloat, assuming just "New Request" is enough for now or id lookup inside
-		// Actually, let's just say "New Connection Request"
-		_ = s.notifService.SendNotification(
-			context.Background(),
-			receiverID,
-			"connection_request",
-			"New Connection Request",
-			"Someone wants to connect with you",
-			map[string]interface{}{
-				"requester_id": requesterID.String(),
-			},
-		)
-	}()
+	// Events always concern the user who didn't act
+	s.notify(event, otherID, actorID)
 
+	// conn is nil when the transition deleted the row
 	return conn, nil
 }
 
+func (s *ConnectionService) notify(event ConnectionEvent, receiverID, actorID uuid.UUID) {
+	switch event {
+	case ConnectionEventRequested:
+		go func() {
+			_ = s.notifService.SendNotification(
+				context.Background(),
+				receiverID,
+				string(ConnectionEventRequested),
+				"New Connection Request",
+				"Someone wants to connect with you",
+				map[string]interface{}{
+					"requester_id": actorID.String(),
+				},
+			)
+		}()
+	case ConnectionEventAccepted:
+		go func() {
+			_ = s.notifService.SendNotification(
+				context.Background(),
+				receiverID,
+				string(ConnectionEventAccepted),
+				"Connection Accepted",
+				"You are now connected!",
+				map[string]interface{}{
+					"accepter_id": actorID.String(),
+				},
+			)
+		}()
+	}
+}
+
+// SendRequest sends a connection request, or accepts the target's pending request to us
+func (s *ConnectionService) SendRequest(ctx context.Context, requesterID, receiverID uuid.UUID) (*Connection, error) {
+	return s.apply(ctx, requesterID, receiverID, ConnectionActionRequest)
+}
+
+// RespondToRequest accepts or rejects a pending request addressed to userID
 func (s *ConnectionService) RespondToRequest(ctx context.Context, userID, connectionID uuid.UUID, accept bool) (*Connection, error) {
 	conn, err := s.repo.GetConnectionByID(ctx, connectionID)
 	if err != nil {
@@ -56,52 +89,67 @@ func (s *ConnectionService) RespondToRequest(ctx context.Context, userID, connec
 	}
 
 	if conn.ReceiverID != userID {
-		return nil, errors.New("unauthorized to respond to this request")
-	}
-
-	if conn.Status != ConnectionStatusPending {
-		return nil, errors.New("connection is not pending")
+		return nil, ErrNotConnectionReceiver
 	}
 
-	status := ConnectionStatusRejected
+	action := ConnectionActionReject
 	if accept {
-		status = ConnectionStatusAccepted
+		action = ConnectionActionAccept
 	}
+	return s.apply(ctx, userID, conn.RequesterID, action)
+}
 
-	updatedConn, err := s.repo.UpdateConnectionStatus(ctx, connectionID, status)
-	if err != nil {
-		return nil, err
+// RemoveConnection withdraws an outgoing request or disconnects from a user
+func (s *ConnectionService) RemoveConnection(ctx context.Context, userID, otherID uuid.UUID) error {
+	_, err := s.apply(ctx, userID, otherID, ConnectionActionRemove)
+	return err
+}
+
+// BlockUser blocks otherID, replacing any existing relationship
+func (s *ConnectionService) BlockUser(ctx context.Context, userID, otherID uuid.UUID) error {
+	_, err := s.apply(ctx, userID, otherID, ConnectionActionBlock)
+	return err
+}
+
+// UnblockUser lifts a block previously placed by userID
+func (s *ConnectionService) UnblockUser(ctx context.Context, userID, otherID uuid.UUID) error {
+	_, err := s.apply(ctx, userID, otherID, ConnectionActionUnblock)
+	return err
+}
+
+// ConnectMutual connects two users who both reached out, with firstID as the
+// one who did so first. Neither is notified; the caller tells them how it came about.
+func (s *ConnectionService) ConnectMutual(ctx context.Context, firstID, secondID uuid.UUID) (*Connection, error) {
+	return s.apply(ctx, secondID, firstID, ConnectionActionMutual)
+}
+
+// GetState returns the relationship between userID and otherID from userID's side
+func (s *ConnectionService) GetState(ctx context.Context, userID, otherID uuid.UUID) (ConnectionState, error) {
+	if userID == otherID {
+		return ConnectionStateNone, ErrCannotConnectSelf
 	}
 
-	if accept {
-		// Notify original requester
-		go func() {
-			_ = s.notifService.SendNotification(
-				context.Background(),
-				conn.RequesterID,
-				"connection_accepted",
-				"Connection Accepted",
-				"You are now connected!",
-				map[string]interface{}{
-					"accepter_id": userID.String(),
-				},
-			)
-		}()
+	conn, err := s.repo.GetConnectionBetween(ctx, userID, otherID)
+	if err == ErrConnectionNotFound {
+		return ConnectionStateNone, nil
+	}
+	if err != nil {
+		return ConnectionStateNone, err
 	}
+	return ConnectionStateFor(conn, userID, time.Now()), nil
+}
 
-	return updatedConn, nil
+// ConnectedAmong returns which of the others userID has an accepted connection with
+func (s *ConnectionService) ConnectedAmong(ctx context.Context, userID uuid.UUID, otherIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	return s.repo.GetConnectedAmong(ctx, userID, otherIDs)
 }
 
 func (s *ConnectionService) GetConnections(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*Connection, error) {
-	if limit <= 0 {
-		limit = 20
-	}
+	limit, offset = ConnectionPageLimits.Clamp(limit, offset)
 	return s.repo.GetConnections(ctx, userID, ConnectionStatusAccepted, limit, offset)
 }
 
 func (s *ConnectionService) GetPendingRequests(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*Connection, error) {
-	if limit <= 0 {
-		limit = 20
-	}
+	limit, offset = ConnectionPageLimits.Clamp(limit, offset)
 	return s.repo.GetConnections(ctx, userID, ConnectionStatusPending, limit, offset)
 }
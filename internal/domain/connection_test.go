@@ -0,0 +1,171 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestPlanConnectionTransition(t *testing.T) {
+	now := time.Now()
+	actor, other := uuid.New(), uuid.New()
+	row := func(requester, receiver uuid.UUID, status ConnectionStatus, age time.Duration) *Connection {
+		return &Connection{ID: uuid.New(), RequesterID: requester, ReceiverID: receiver, Status: status, UpdatedAt: now.Add(-age)}
+	}
+	update := func(requester, receiver uuid.UUID, status ConnectionStatus) *ConnectionUpdate {
+		return &ConnectionUpdate{RequesterID: requester, ReceiverID: receiver, Status: status}
+	}
+	remove := &ConnectionUpdate{Delete: true}
+
+	// Every row the actor can face, and the state it resolves to for them
+	rows := []struct {
+		name    string
+		current *Connection
+		state   ConnectionState
+	}{
+		{"no row", nil, ConnectionStateNone},
+		{"actor declined", row(other, actor, ConnectionStatusRejected, time.Hour), ConnectionStateNone},
+		{"declined, cooldown over", row(actor, other, ConnectionStatusRejected, ConnectionRejectionCooldown+time.Hour), ConnectionStateNone},
+		{"outgoing", row(actor, other, ConnectionStatusPending, time.Hour), ConnectionStateOutgoing},
+		{"incoming", row(other, actor, ConnectionStatusPending, time.Hour), ConnectionStateIncoming},
+		{"connected", row(other, actor, ConnectionStatusAccepted, time.Hour), ConnectionStateConnected},
+		{"declined, in cooldown", row(actor, other, ConnectionStatusRejected, time.Hour), ConnectionStateRejectedCooldown},
+		{"actor blocked", row(actor, other, ConnectionStatusBlocked, time.Hour), ConnectionStateBlocked},
+		{"other blocked", row(other, actor, ConnectionStatusBlocked, time.Hour), ConnectionStateBlocked},
+	}
+	actions := []ConnectionAction{
+		ConnectionActionRequest, ConnectionActionAccept, ConnectionActionReject, ConnectionActionRemove,
+		ConnectionActionBlock, ConnectionActionUnblock, ConnectionActionMutual,
+	}
+
+	tests := []struct {
+		from   string
+		action ConnectionAction
+		update *ConnectionUpdate
+		event  ConnectionEvent
+		err    error
+	}{
+		{"no row", ConnectionActionRequest, update(actor, other, ConnectionStatusPending), ConnectionEventRequested, nil},
+		{"no row", ConnectionActionAccept, nil, ConnectionEventNone, ErrConnectionNotPending},
+		{"no row", ConnectionActionReject, nil, ConnectionEventNone, ErrConnectionNotPending},
+		{"no row", ConnectionActionRemove, nil, ConnectionEventNone, ErrConnectionNotFound},
+		{"no row", ConnectionActionBlock, update(actor, other, ConnectionStatusBlocked), ConnectionEventNone, nil},
+		{"no row", ConnectionActionUnblock, nil, ConnectionEventNone, ErrConnectionNotFound},
+		{"no row", ConnectionActionMutual, update(other, actor, ConnectionStatusAccepted), ConnectionEventNone, nil},
+
+		{"actor declined", ConnectionActionRequest, update(actor, other, ConnectionStatusPending), ConnectionEventRequested, nil},
+		{"actor declined", ConnectionActionAccept, nil, ConnectionEventNone, ErrConnectionNotPending},
+		{"actor declined", ConnectionActionReject, nil, ConnectionEventNone, ErrConnectionNotPending},
+		{"actor declined", ConnectionActionRemove, nil, ConnectionEventNone, ErrConnectionNotFound},
+		{"actor declined", ConnectionActionBlock, update(actor, other, ConnectionStatusBlocked), ConnectionEventNone, nil},
+		{"actor declined", ConnectionActionUnblock, nil, ConnectionEventNone, ErrConnectionNotFound},
+		{"actor declined", ConnectionActionMutual, update(other, actor, ConnectionStatusAccepted), ConnectionEventNone, nil},
+
+		{"declined, cooldown over", ConnectionActionRequest, update(actor, other, ConnectionStatusPending), ConnectionEventRequested, nil},
+		{"declined, cooldown over", ConnectionActionAccept, nil, ConnectionEventNone, ErrConnectionNotPending},
+		{"declined, cooldown over", ConnectionActionReject, nil, ConnectionEventNone, ErrConnectionNotPending},
+		{"declined, cooldown over", ConnectionActionRemove, nil, ConnectionEventNone, ErrConnectionNotFound},
+		{"declined, cooldown over", ConnectionActionBlock, update(actor, other, ConnectionStatusBlocked), ConnectionEventNone, nil},
+		{"declined, cooldown over", ConnectionActionUnblock, nil, ConnectionEventNone, ErrConnectionNotFound},
+		{"declined, cooldown over", ConnectionActionMutual, update(other, actor, ConnectionStatusAccepted), ConnectionEventNone, nil},
+
+		{"outgoing", ConnectionActionRequest, nil, ConnectionEventNone, nil},
+		{"outgoing", ConnectionActionAccept, nil, ConnectionEventNone, ErrConnectionNotPending},
+		{"outgoing", ConnectionActionReject, nil, ConnectionEventNone, ErrConnectionNotPending},
+		{"outgoing", ConnectionActionRemove, remove, ConnectionEventNone, nil},
+		{"outgoing", ConnectionActionBlock, update(actor, other, ConnectionStatusBlocked), ConnectionEventNone, nil},
+		{"outgoing", ConnectionActionUnblock, nil, ConnectionEventNone, ErrConnectionNotFound},
+		{"outgoing", ConnectionActionMutual, update(actor, other, ConnectionStatusAccepted), ConnectionEventNone, nil},
+
+		{"incoming", ConnectionActionRequest, update(other, actor, ConnectionStatusAccepted), ConnectionEventAccepted, nil},
+		{"incoming", ConnectionActionAccept, update(other, actor, ConnectionStatusAccepted), ConnectionEventAccepted, nil},
+		{"incoming", ConnectionActionReject, update(other, actor, ConnectionStatusRejected), ConnectionEventNone, nil},
+		{"incoming", ConnectionActionRemove, nil, ConnectionEventNone, ErrConnectionNotFound},
+		{"incoming", ConnectionActionBlock, update(actor, other, ConnectionStatusBlocked), ConnectionEventNone, nil},
+		{"incoming", ConnectionActionUnblock, nil, ConnectionEventNone, ErrConnectionNotFound},
+		{"incoming", ConnectionActionMutual, update(other, actor, ConnectionStatusAccepted), ConnectionEventNone, nil},
+
+		{"connected", ConnectionActionRequest, nil, ConnectionEventNone, ErrAlreadyConnected},
+		{"connected", ConnectionActionAccept, nil, ConnectionEventNone, ErrConnectionNotPending},
+		{"connected", ConnectionActionReject, nil, ConnectionEventNone, ErrConnectionNotPending},
+		{"connected", ConnectionActionRemove, remove, ConnectionEventNone, nil},
+		{"connected", ConnectionActionBlock, update(actor, other, ConnectionStatusBlocked), ConnectionEventNone, nil},
+		{"connected", ConnectionActionUnblock, nil, ConnectionEventNone, ErrConnectionNotFound},
+		{"connected", ConnectionActionMutual, nil, ConnectionEventNone, nil},
+
+		{"declined, in cooldown", ConnectionActionRequest, nil, ConnectionEventNone, ErrConnectionCooldown},
+		{"declined, in cooldown", ConnectionActionAccept, nil, ConnectionEventNone, ErrConnectionNotPending},
+		{"declined, in cooldown", ConnectionActionReject, nil, ConnectionEventNone, ErrConnectionNotPending},
+		{"declined, in cooldown", ConnectionActionRemove, nil, ConnectionEventNone, ErrConnectionNotFound},
+		{"declined, in cooldown", ConnectionActionBlock, update(actor, other, ConnectionStatusBlocked), ConnectionEventNone, nil},
+		{"declined, in cooldown", ConnectionActionUnblock, nil, ConnectionEventNone, ErrConnectionNotFound},
+		{"declined, in cooldown", ConnectionActionMutual, update(other, actor, ConnectionStatusAccepted), ConnectionEventNone, nil},
+
+		{"actor blocked", ConnectionActionRequest, nil, ConnectionEventNone, ErrConnectionBlocked},
+		{"actor blocked", ConnectionActionAccept, nil, ConnectionEventNone, ErrConnectionBlocked},
+		{"actor blocked", ConnectionActionReject, nil, ConnectionEventNone, ErrConnectionBlocked},
+		{"actor blocked", ConnectionActionRemove, nil, ConnectionEventNone, ErrConnectionNotFound},
+		{"actor blocked", ConnectionActionBlock, nil, ConnectionEventNone, nil},
+		{"actor blocked", ConnectionActionUnblock, remove, ConnectionEventNone, nil},
+		{"actor blocked", ConnectionActionMutual, nil, ConnectionEventNone, ErrConnectionBlocked},
+
+		{"other blocked", ConnectionActionRequest, nil, ConnectionEventNone, ErrConnectionBlocked},
+		{"other blocked", ConnectionActionAccept, nil, ConnectionEventNone, ErrConnectionBlocked},
+		{"other blocked", ConnectionActionReject, nil, ConnectionEventNone, ErrConnectionBlocked},
+		{"other blocked", ConnectionActionRemove, nil, ConnectionEventNone, ErrConnectionNotFound},
+		{"other blocked", ConnectionActionBlock, nil, ConnectionEventNone, nil},
+		{"other blocked", ConnectionActionUnblock, nil, ConnectionEventNone, ErrConnectionNotFound},
+		{"other blocked", ConnectionActionMutual, nil, ConnectionEventNone, ErrConnectionBlocked},
+	}
+
+	covered := make(map[string]bool)
+	for _, tt := range tests {
+		covered[tt.from+"/"+string(tt.action)] = true
+	}
+	for _, r := range rows {
+		if got := ConnectionStateFor(r.current, actor, now); got != r.state {
+			t.Fatalf("%s: got state %s, want %s", r.name, got, r.state)
+		}
+		for _, action := range actions {
+			if !covered[r.name+"/"+string(action)] {
+				t.Errorf("no case for %s/%s", r.name, action)
+			}
+		}
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%s", tt.from, tt.action), func(t *testing.T) {
+			var current *Connection
+			found := false
+			for _, r := range rows {
+				if r.name == tt.from {
+					current, found = r.current, true
+				}
+			}
+			if !found {
+				t.Fatalf("unknown row %q", tt.from)
+			}
+
+			got, event, err := PlanConnectionTransition(current, actor, other, tt.action, now)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if !reflect.DeepEqual(got, tt.update) {
+				t.Fatalf("got update %+v, want %+v", got, tt.update)
+			}
+			if event != tt.event {
+				t.Fatalf("got event %q, want %q", event, tt.event)
+			}
+		})
+	}
+
+	t.Run("unknown action", func(t *testing.T) {
+		if _, _, err := PlanConnectionTransition(nil, actor, other, "poke", now); err == nil {
+			t.Fatal("got no error for an unknown action")
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrCopyrightClaimNotFound = errors.New("copyright claim not found")
+	ErrInvalidCopyrightClaim  = errors.New("invalid copyright claim")
+	ErrCopyrightClaimState    = errors.New("copyright claim can't do that in its current state")
+	ErrNotClaimedContentOwner = errors.New("only the owner of the claimed content can file a counter-notice")
+)
+
+type CopyrightClaimStatus string
+
+const (
+	// CopyrightPending claims have hidden the story and await review
+	CopyrightPending CopyrightClaimStatus = "pending"
+	// CopyrightUpheld claims keep the story hidden and gave its owner a strike
+	CopyrightUpheld CopyrightClaimStatus = "upheld"
+	// CopyrightRejected claims were invalid; the story was restored
+	CopyrightRejected CopyrightClaimStatus = "rejected"
+	// CopyrightCounterNoticed claims were disputed by the owner and await a restore decision
+	CopyrightCounterNoticed CopyrightClaimStatus = "counter_noticed"
+	// CopyrightRestored claims were overturned by a counter-notice; the strike was revoked
+	CopyrightRestored CopyrightClaimStatus = "restored"
+)
+
+// StrikeKindCopyright is a strike from an upheld copyright claim
+const StrikeKindCopyright = "copyright"
+
+// CopyrightStrikeWeight is the abuse signal weight of a copyright strike
+const CopyrightStrikeWeight = 30
+
+// CopyrightClaim is a takedown request from a rights holder
+type CopyrightClaim struct {
+	ID               uuid.UUID            `json:"id"`
+	StoryID          uuid.UUID            `json:"story_id"`
+	OwnerID          *uuid.UUID           `json:"owner_id,omitempty"`
+	ClaimantName     string               `json:"claimant_name"`
+	ClaimantEmail    string               `json:"claimant_email"`
+	WorkDescription  string               `json:"work_description"`
+	Signature        string               `json:"signature"`
+	Status           CopyrightClaimStatus `json:"status"`
+	CounterNotice    *string              `json:"counter_notice,omitempty"`
+	CounterSignature *string              `json:"counter_signature,omitempty"`
+	CounterNoticedAt *time.Time           `json:"counter_noticed_at,omitempty"`
+	ReviewedBy       *uuid.UUID           `json:"reviewed_by,omitempty"`
+	ReviewedAt       *time.Time           `json:"reviewed_at,omitempty"`
+	CreatedAt        time.Time            `json:"created_at"`
+}
+
+// FileCopyrightClaimParams is the takedown notice a claimant submits
+type FileCopyrightClaimParams struct {
+	StoryID         uuid.UUID `json:"story_id"`
+	ClaimantName    string    `json:"claimant_name"`
+	ClaimantEmail   string    `json:"claimant_email"`
+	WorkDescription string    `json:"work_description"`
+	// GoodFaith affirms the use is not authorized by the rights holder or the law
+	GoodFaith bool `json:"good_faith"`
+	// Accurate affirms, under penalty of perjury, the notice is accurate and
+	// the claimant may act for the rights holder
+	Accurate  bool   `json:"accurate"`
+	Signature string `json:"signature"`
+}
+
+// Strike is an enforcement record against a user
+type Strike struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	Kind      string     `json:"kind"`
+	ClaimID   uuid.UUID  `json:"claim_id"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+type CopyrightRepository interface {
+	// CreateCopyrightClaim records the claim and hides the story in one transaction
+	CreateCopyrightClaim(ctx context.Context, claim *CopyrightClaim) error
+	GetCopyrightClaim(ctx context.Context, id uuid.UUID) (*CopyrightClaim, error)
+	GetCopyrightClaims(ctx context.Context, status CopyrightClaimStatus, limit, offset int) ([]*CopyrightClaim, error)
+	// FileCounterNotice moves a pending or upheld claim to counter_noticed
+	FileCounterNotice(ctx context.Context, claimID uuid.UUID, notice, signature string) (*CopyrightClaim, error)
+	// ResolveCopyrightClaim moves a claim in one of the from statuses to status.
+	// Upholding issues a strike (reporting whether a new one was issued);
+	// rejecting or restoring unhides the story and revokes any strike.
+	ResolveCopyrightClaim(ctx context.Context, claimID, reviewerID uuid.UUID, from []CopyrightClaimStatus, status CopyrightClaimStatus) (*CopyrightClaim, bool, error)
+	GetUserStrikes(ctx context.Context, userID uuid.UUID) ([]*Strike, error)
+}
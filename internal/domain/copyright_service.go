@@ -0,0 +1,155 @@
+package domain
+
+import (
+	"context"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CopyrightService struct {
+	repo         CopyrightRepository
+	abuseRepo    AbuseRepository
+	notifService *NotificationService
+}
+
+func NewCopyrightService(repo CopyrightRepository, abuseRepo AbuseRepository, notifService *NotificationService) *CopyrightService {
+	return &CopyrightService{
+		repo:         repo,
+		abuseRepo:    abuseRepo,
+		notifService: notifService,
+	}
+}
+
+// FileClaim records a takedown notice and hides the story until it is reviewed
+func (s *CopyrightService) FileClaim(ctx context.Context, params FileCopyrightClaimParams) (*CopyrightClaim, error) {
+	claim := &CopyrightClaim{
+		StoryID:         params.StoryID,
+		ClaimantName:    strings.TrimSpace(params.ClaimantName),
+		ClaimantEmail:   strings.ToLower(strings.TrimSpace(params.ClaimantEmail)),
+		WorkDescription: strings.TrimSpace(params.WorkDescription),
+		Signature:       strings.TrimSpace(params.Signature),
+	}
+	if _, err := mail.ParseAddress(claim.ClaimantEmail); err != nil {
+		return nil, ErrInvalidCopyrightClaim
+	}
+	if claim.ClaimantName == "" || claim.WorkDescription == "" || claim.Signature == "" ||
+		len(claim.ClaimantName) > 200 || len(claim.Signature) > 200 ||
+		!params.GoodFaith || !params.Accurate {
+		return nil, ErrInvalidCopyrightClaim
+	}
+
+	if err := s.repo.CreateCopyrightClaim(ctx, claim); err != nil {
+		return nil, err
+	}
+	log.Printf("copyright: claim %s filed against story %s", claim.ID, claim.StoryID)
+	go s.notifyOwner(claim)
+	return claim, nil
+}
+
+// FileCounterNotice lets the owner dispute a pending or upheld claim on their story
+func (s *CopyrightService) FileCounterNotice(ctx context.Context, userID, claimID uuid.UUID, notice, signature string) (*CopyrightClaim, error) {
+	notice, signature = strings.TrimSpace(notice), strings.TrimSpace(signature)
+	if notice == "" || signature == "" || len(signature) > 200 {
+		return nil, ErrInvalidCopyrightClaim
+	}
+
+	claim, err := s.repo.GetCopyrightClaim(ctx, claimID)
+	if err != nil {
+		return nil, err
+	}
+	if claim.OwnerID == nil || *claim.OwnerID != userID {
+		return nil, ErrNotClaimedContentOwner
+	}
+	return s.repo.FileCounterNotice(ctx, claimID, notice, signature)
+}
+
+// GetClaims lists claims with the given status, oldest first
+func (s *CopyrightService) GetClaims(ctx context.Context, status CopyrightClaimStatus, limit, offset int) ([]*CopyrightClaim, error) {
+	limit, offset = AdminPageLimits.Clamp(limit, offset)
+	return s.repo.GetCopyrightClaims(ctx, status, limit, offset)
+}
+
+// Uphold keeps the story down and gives its owner a strike. A counter-noticed
+// claim is upheld when the claimant shows they have taken the dispute to court.
+func (s *CopyrightService) Uphold(ctx context.Context, claimID, reviewerID uuid.UUID) (*CopyrightClaim, error) {
+	claim, issued, err := s.repo.ResolveCopyrightClaim(ctx, claimID, reviewerID,
+		[]CopyrightClaimStatus{CopyrightPending, CopyrightCounterNoticed}, CopyrightUpheld)
+	if err != nil {
+		return nil, err
+	}
+
+	if issued && claim.OwnerID != nil {
+		err := s.abuseRepo.RecordAbuseSignal(ctx, AbuseSignal{
+			UserID:    *claim.OwnerID,
+			Kind:      AbuseSignalCopyrightStrike,
+			Weight:    CopyrightStrikeWeight,
+			SubjectID: &claim.StoryID,
+			Details:   map[string]interface{}{"claim_id": claim.ID},
+		})
+		if err != nil {
+			log.Printf("failed to record copyright abuse signal: %v", err)
+		}
+	}
+
+	go s.notifyOwner(claim)
+	return claim, nil
+}
+
+// Reject dismisses an invalid pending claim and restores the story
+func (s *CopyrightService) Reject(ctx context.Context, claimID, reviewerID uuid.UUID) (*CopyrightClaim, error) {
+	claim, _, err := s.repo.ResolveCopyrightClaim(ctx, claimID, reviewerID,
+		[]CopyrightClaimStatus{CopyrightPending}, CopyrightRejected)
+	if err != nil {
+		return nil, err
+	}
+	go s.notifyOwner(claim)
+	return claim, nil
+}
+
+// Restore puts counter-noticed content back up and revokes the strike
+func (s *CopyrightService) Restore(ctx context.Context, claimID, reviewerID uuid.UUID) (*CopyrightClaim, error) {
+	claim, _, err := s.repo.ResolveCopyrightClaim(ctx, claimID, reviewerID,
+		[]CopyrightClaimStatus{CopyrightCounterNoticed}, CopyrightRestored)
+	if err != nil {
+		return nil, err
+	}
+	go s.notifyOwner(claim)
+	return claim, nil
+}
+
+// GetStrikes lists a user's strikes, newest first, including revoked ones
+func (s *CopyrightService) GetStrikes(ctx context.Context, userID uuid.UUID) ([]*Strike, error) {
+	return s.repo.GetUserStrikes(ctx, userID)
+}
+
+func (s *CopyrightService) notifyOwner(claim *CopyrightClaim) {
+	if claim.OwnerID == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	title, body := copyrightMessage(claim.Status)
+	err := s.notifService.SendNotification(ctx, *claim.OwnerID, "copyright", title, body, map[string]interface{}{
+		"claim_id": claim.ID.String(),
+		"story_id": claim.StoryID.String(),
+		"status":   string(claim.Status),
+	})
+	if err != nil {
+		log.Printf("copyright: failed to notify %s: %v", *claim.OwnerID, err)
+	}
+}
+
+func copyrightMessage(status CopyrightClaimStatus) (title, body string) {
+	switch status {
+	case CopyrightUpheld:
+		return "Copyright strike", "A copyright claim against your story was upheld and your account received a strike. You can file a counter-notice if you believe this is a mistake."
+	case CopyrightRejected, CopyrightRestored:
+		return "Content restored", "Your story was reviewed after a copyright claim and has been restored."
+	}
+	return "Story hidden", "Your story is hidden after a copyright claim. You can file a counter-notice if you have the rights to it."
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrDataExportNotFound = errors.New("no data export requested")
+	// ErrDataExportTooSoon is returned for a new export within DataExportCooldown of the last one
+	ErrDataExportTooSoon = errors.New("a data export was already made recently, try again later")
+)
+
+const (
+	// DataExportCooldown is the minimum gap between a user's exports
+	DataExportCooldown = 24 * time.Hour
+	// DataExportRetention is how long a built archive can be downloaded
+	DataExportRetention = 7 * 24 * time.Hour
+	// DataExportStaleAfter is when a build still running is taken to have
+	// died with its worker and is picked up again
+	DataExportStaleAfter = 30 * time.Minute
+	exportMessageBatch   = 500
+)
+
+// Where a data export is
+const (
+	DataExportPending = "pending"
+	DataExportRunning = "running"
+	DataExportReady   = "ready"
+	DataExportFailed  = "failed"
+)
+
+// DataExport is a user's request for an archive of their data. DownloadURL
+// is set once it is ready, and goes through the same access checks as other
+// uploads, so only its owner can fetch it.
+type DataExport struct {
+	ID          uuid.UUID  `json:"id"`
+	UserID      uuid.UUID  `json:"-"`
+	Status      string     `json:"status"`
+	DownloadURL *string    `json:"download_url,omitempty"`
+	SizeBytes   *int64     `json:"size_bytes,omitempty"`
+	RequestedAt time.Time  `json:"requested_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+// Active reports whether the export is still waiting or being built
+func (e *DataExport) Active() bool {
+	return e.Status == DataExportPending || e.Status == DataExportRunning
+}
+
+// ExportSections are the parts of an archive read straight from the
+// database, keyed by the file they are written to without its extension
+type ExportSections map[string]json.RawMessage
+
+type DataExportRepository interface {
+	// CreateDataExport queues an export for the user, or returns their
+	// active one with created false if there is one already
+	CreateDataExport(ctx context.Context, userID uuid.UUID) (export *DataExport, created bool, err error)
+	// GetLatestDataExport returns the user's newest export, or ErrDataExportNotFound
+	GetLatestDataExport(ctx context.Context, userID uuid.UUID) (*DataExport, error)
+	// ClaimDataExport marks the oldest pending export, or one running since
+	// before staleBefore, as running and returns it. It returns
+	// ErrDataExportNotFound when there is none.
+	ClaimDataExport(ctx context.Context, staleBefore time.Time) (*DataExport, error)
+	CompleteDataExport(ctx context.Context, exportID uuid.UUID, fileURL string, sizeBytes int64, expiresAt time.Time) error
+	FailDataExport(ctx context.Context, exportID uuid.UUID) error
+	// DeleteExpiredDataExports removes exports expired or failed before
+	// now, returning the URLs of their archives
+	DeleteExpiredDataExports(ctx context.Context, now time.Time) ([]string, error)
+	// GetExportSections reads the user's profile, stories, connections,
+	// comments and saved places
+	GetExportSections(ctx context.Context, userID uuid.UUID) (ExportSections, error)
+}
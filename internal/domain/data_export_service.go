@@ -0,0 +1,249 @@
+package domain
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/storage"
+)
+
+// ExportService builds archives of a user's own data for them to download.
+// Requests are queued and built by Run; the user is notified when theirs is
+// ready.
+type ExportService struct {
+	repo         DataExportRepository
+	chatRepo     ChatRepository
+	storage      storage.FileStorage
+	notifService *NotificationService
+}
+
+func NewExportService(repo DataExportRepository, chatRepo ChatRepository, storage storage.FileStorage, notifService *NotificationService) *ExportService {
+	return &ExportService{
+		repo:         repo,
+		chatRepo:     chatRepo,
+		storage:      storage,
+		notifService: notifService,
+	}
+}
+
+// RequestExport queues an export of the user's data. An export already
+// waiting or being built is returned as is, with created false.
+func (s *ExportService) RequestExport(ctx context.Context, userID uuid.UUID) (*DataExport, bool, error) {
+	latest, err := s.repo.GetLatestDataExport(ctx, userID)
+	switch {
+	case errors.Is(err, ErrDataExportNotFound):
+	case err != nil:
+		return nil, false, err
+	case latest.Active():
+		return latest, false, nil
+	case latest.Status == DataExportReady && time.Since(latest.RequestedAt) < DataExportCooldown:
+		return nil, false, ErrDataExportTooSoon
+	}
+	return s.repo.CreateDataExport(ctx, userID)
+}
+
+// GetLatestExport returns the user's newest export, to poll its status
+func (s *ExportService) GetLatestExport(ctx context.Context, userID uuid.UUID) (*DataExport, error) {
+	return s.repo.GetLatestDataExport(ctx, userID)
+}
+
+// Run builds queued exports and removes expired archives every interval
+func (s *ExportService) Run(ctx context.Context, interval time.Duration, jobs *JobTracker) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			jobs.Track(ctx, JobDataExports, s.process)
+		}
+	}
+}
+
+// process removes expired exports and builds every queued one, returning how
+// many of each it got through and what failed
+func (s *ExportService) process(ctx context.Context) (int64, error) {
+	done, err := s.deleteExpired(ctx)
+	errs := []error{err}
+
+	for ctx.Err() == nil {
+		export, err := s.repo.ClaimDataExport(ctx, time.Now().Add(-DataExportStaleAfter))
+		if errors.Is(err, ErrDataExportNotFound) {
+			break
+		}
+		if err != nil {
+			log.Printf("data export: failed to claim export: %v", err)
+			errs = append(errs, err)
+			break
+		}
+		if err := s.build(ctx, export); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		done++
+	}
+	return done, errors.Join(errs...)
+}
+
+func (s *ExportService) build(ctx context.Context, export *DataExport) error {
+	url, size, err := s.writeArchive(ctx, export.UserID)
+	if err != nil {
+		log.Printf("data export: failed to build %s: %v", export.ID, err)
+		if err := s.repo.FailDataExport(ctx, export.ID); err != nil {
+			log.Printf("data export: failed to mark %s failed: %v", export.ID, err)
+		}
+		return err
+	}
+
+	expiresAt := time.Now().Add(DataExportRetention)
+	if err := s.repo.CompleteDataExport(ctx, export.ID, url, size, expiresAt); err != nil {
+		log.Printf("data export: failed to complete %s: %v", export.ID, err)
+		_ = s.storage.DeleteFile(ctx, url)
+		return err
+	}
+
+	err = s.notifService.SendNotification(ctx, export.UserID, "data_export",
+		"Your data is ready",
+		"Your LocoLive data export is ready to download for the next 7 days",
+		map[string]interface{}{"export_id": export.ID.String()},
+	)
+	if err != nil {
+		log.Printf("data export: failed to notify %s: %v", export.UserID, err)
+	}
+	return nil
+}
+
+// writeArchive zips the user's data into a temporary file and uploads it,
+// returning its URL and size
+func (s *ExportService) writeArchive(ctx context.Context, userID uuid.UUID) (string, int64, error) {
+	f, err := os.CreateTemp("", "export-*.zip")
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	if err := s.writeData(ctx, zw, userID); err != nil {
+		return "", 0, err
+	}
+	if err := zw.Close(); err != nil {
+		return "", 0, err
+	}
+
+	size, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", 0, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", 0, err
+	}
+	url, err := s.storage.SaveFile(ctx, f, "export.zip", "application/zip")
+	if err != nil {
+		return "", 0, err
+	}
+	return url, size, nil
+}
+
+// writeData writes profile.json and the other sections, chats.json, and
+// each chat's messages, newest first, to messages/<chat id>.json
+func (s *ExportService) writeData(ctx context.Context, zw *zip.Writer, userID uuid.UUID) error {
+	sections, err := s.repo.GetExportSections(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for name, data := range sections {
+		if err := writeJSON(zw, name+".json", data); err != nil {
+			return err
+		}
+	}
+
+	chats, err := s.chatRepo.GetChatsByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if chats == nil {
+		chats = []*Chat{}
+	}
+	if err := writeJSON(zw, "chats.json", chats); err != nil {
+		return err
+	}
+	for _, chat := range chats {
+		if err := s.writeMessages(ctx, zw, chat.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *ExportService) writeMessages(ctx context.Context, zw *zip.Writer, chatID uuid.UUID) error {
+	w, err := zw.Create(fmt.Sprintf("messages/%s.json", chatID))
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	page := MessagePage{Limit: exportMessageBatch}
+	first := true
+	for {
+		messages, err := s.chatRepo.GetMessages(ctx, chatID, page)
+		if err != nil {
+			return err
+		}
+		for _, msg := range messages {
+			if !first {
+				if _, err := io.WriteString(w, ","); err != nil {
+					return err
+				}
+			}
+			first = false
+			if err := enc.Encode(msg); err != nil {
+				return err
+			}
+		}
+		if len(messages) < page.Limit {
+			break
+		}
+		page.Before = &messages[len(messages)-1].ID
+	}
+
+	_, err = io.WriteString(w, "]\n")
+	return err
+}
+
+func writeJSON(zw *zip.Writer, name string, v interface{}) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func (s *ExportService) deleteExpired(ctx context.Context) (int64, error) {
+	urls, err := s.repo.DeleteExpiredDataExports(ctx, time.Now())
+	if err != nil {
+		log.Printf("data export: failed to delete expired exports: %v", err)
+		return 0, err
+	}
+	for _, url := range urls {
+		if err := s.storage.DeleteFile(ctx, url); err != nil {
+			log.Printf("data export: failed to delete %s: %v", url, err)
+		}
+	}
+	return int64(len(urls)), nil
+}
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/locolive/backend/pkg/imagehash"
+)
+
+const (
+	// DuplicateMediaDistance is the most bits two image hashes may differ by
+	// for the images to count as the same picture
+	DuplicateMediaDistance = 6
+	// DuplicateMediaWindow is how far back uploads are compared
+	DuplicateMediaWindow = 24 * time.Hour
+	// duplicateMediaWeight is small so the odd repost barely counts, while an
+	// account posting the same promo every few minutes climbs fast
+	duplicateMediaWeight = 2
+)
+
+// MediaMatch is an earlier story whose image an upload repeats
+type MediaMatch struct {
+	StoryID  uuid.UUID
+	UserID   uuid.UUID
+	Distance int
+}
+
+// matchDuplicateMedia hashes an uploaded image and looks for a recent story
+// with the same picture, from any user. Images that can't be hashed are
+// skipped, and failures are only logged since the story is posted regardless.
+func (s *StoryService) matchDuplicateMedia(ctx context.Context, params *CreateStoryParams, data *bytes.Buffer) *MediaMatch {
+	hash, err := imagehash.Difference(data.Bytes())
+	if err != nil {
+		return nil
+	}
+	h := int64(hash)
+	params.MediaHash = &h
+
+	match, err := s.repo.FindDuplicateMedia(ctx, h, DuplicateMediaDistance, time.Now().Add(-DuplicateMediaWindow))
+	if err != nil {
+		log.Printf("failed to look up duplicate media: %v", err)
+		return nil
+	}
+	if match != nil {
+		params.DuplicateOf = &match.StoryID
+	}
+	return match
+}
+
+// recordDuplicateMedia feeds a repost into the uploader's abuse score. Like a
+// spoofed location, the story stays up but ranks below the rest of the feed.
+func (s *StoryService) recordDuplicateMedia(ctx context.Context, story *Story, match *MediaMatch) {
+	err := s.abuseRepo.RecordAbuseSignal(ctx, AbuseSignal{
+		UserID:    story.UserID,
+		Kind:      AbuseSignalDuplicateMedia,
+		Weight:    duplicateMediaWeight,
+		SubjectID: &story.ID,
+		Details: map[string]interface{}{
+			"duplicate_of": match.StoryID,
+			"distance":     match.Distance,
+			"same_user":    match.UserID == story.UserID,
+		},
+	})
+	if err != nil {
+		log.Printf("failed to record duplicate media signal: %v", err)
+	}
+}
@@ -0,0 +1,308 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotGroupChat        = errors.New("not a group chat")
+	ErrNotChatAdmin        = errors.New("only group admins can do this")
+	ErrGroupChatFull       = errors.New("group chat is full")
+	ErrInvalidGroupMembers = errors.New("group members must be active users you're connected with")
+	ErrChatMemberNotFound  = errors.New("user is not in this chat")
+	ErrInvalidChatRole     = errors.New("role must be admin or member")
+	ErrInvalidChatAvatar   = errors.New("avatar_url must be an http or https URL")
+	// ErrLastChatAdmin is returned for demoting a group's only admin
+	ErrLastChatAdmin = errors.New("a group needs at least one admin")
+)
+
+// MaxGroupChatMembers caps the size of a group, its creator included
+const MaxGroupChatMembers = 50
+
+// ChatRole is what a member may do in a group chat
+type ChatRole string
+
+const (
+	ChatRoleAdmin  ChatRole = "admin"
+	ChatRoleMember ChatRole = "member"
+)
+
+type CreateGroupChatParams struct {
+	Name      string      `json:"name"`
+	AvatarURL *string     `json:"avatar_url"`
+	MemberIDs []uuid.UUID `json:"member_ids"`
+}
+
+type UpdateGroupChatParams struct {
+	Name *string `json:"name"`
+	// AvatarURL replaces the avatar; an empty string removes it
+	AvatarURL *string `json:"avatar_url"`
+}
+
+// CreateGroupChat starts a group with the creator as admin. Members must be
+// connected with the creator, so nobody is pulled into a chat by a stranger.
+func (s *ChatService) CreateGroupChat(ctx context.Context, creatorID uuid.UUID, params CreateGroupChatParams) (*Chat, error) {
+	name, err := s.text.Name(params.Name)
+	if err != nil {
+		return nil, err
+	}
+	avatarURL, err := groupAvatarURL(params.AvatarURL)
+	if err != nil {
+		return nil, err
+	}
+
+	members := newChatMembers(params.MemberIDs, nil, creatorID)
+	if len(members) == 0 {
+		return nil, ErrInvalidGroupMembers
+	}
+	if len(members)+1 > MaxGroupChatMembers {
+		return nil, ErrGroupChatFull
+	}
+	if err := s.checkGroupMembers(ctx, creatorID, members); err != nil {
+		return nil, err
+	}
+
+	chat, err := s.repo.CreateGroupChat(ctx, creatorID, name, avatarURL, members)
+	if err != nil {
+		return nil, err
+	}
+	go s.notifyAddedToGroup(chat, creatorID, members)
+	return chat, nil
+}
+
+// UpdateGroupChat renames a group or changes its avatar
+func (s *ChatService) UpdateGroupChat(ctx context.Context, chatID, actorID uuid.UUID, params UpdateGroupChatParams) (*Chat, error) {
+	if _, err := s.groupChatAdmin(ctx, chatID, actorID); err != nil {
+		return nil, err
+	}
+
+	if params.Name != nil {
+		name, err := s.text.Name(*params.Name)
+		if err != nil {
+			return nil, err
+		}
+		params.Name = &name
+	}
+	if params.AvatarURL != nil {
+		avatarURL, err := groupAvatarURL(params.AvatarURL)
+		if err != nil {
+			return nil, err
+		}
+		if avatarURL == nil {
+			avatarURL = new(string)
+		}
+		params.AvatarURL = avatarURL
+	}
+
+	if err := s.repo.UpdateGroupChat(ctx, chatID, params.Name, params.AvatarURL); err != nil {
+		return nil, err
+	}
+	return s.repo.GetChatByID(ctx, chatID)
+}
+
+// AddParticipants adds the admin's connections to a group
+func (s *ChatService) AddParticipants(ctx context.Context, chatID, actorID uuid.UUID, userIDs []uuid.UUID) (*Chat, error) {
+	chat, err := s.groupChatAdmin(ctx, chatID, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	members := newChatMembers(userIDs, chat, actorID)
+	if len(members) == 0 {
+		return chat, nil
+	}
+	if err := s.checkGroupMembers(ctx, actorID, members); err != nil {
+		return nil, err
+	}
+	if err := s.repo.AddChatParticipants(ctx, chatID, members, MaxGroupChatMembers); err != nil {
+		return nil, err
+	}
+
+	chat, err = s.repo.GetChatByID(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+	go s.notifyAddedToGroup(chat, actorID, members)
+	return chat, nil
+}
+
+// RemoveParticipant takes a member out of a group. Anyone can leave; only
+// admins can remove others.
+func (s *ChatService) RemoveParticipant(ctx context.Context, chatID, actorID, userID uuid.UUID) (*Chat, error) {
+	chat, err := s.groupChat(ctx, chatID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if actorID != userID && !chatHasAdmin(chat, actorID) {
+		return nil, ErrNotChatAdmin
+	}
+	if !chatHasUser(chat, userID) {
+		return nil, ErrChatMemberNotFound
+	}
+
+	if err := s.repo.RemoveChatParticipant(ctx, chatID, userID); err != nil {
+		return nil, err
+	}
+	chat, err = s.repo.GetChatByID(ctx, chatID)
+	if errors.Is(err, ErrChatNotFound) {
+		// The last member left
+		return nil, nil
+	}
+	return chat, err
+}
+
+// SetParticipantRole makes a member an admin or an admin a member
+func (s *ChatService) SetParticipantRole(ctx context.Context, chatID, actorID, userID uuid.UUID, role ChatRole) (*Chat, error) {
+	if role != ChatRoleAdmin && role != ChatRoleMember {
+		return nil, ErrInvalidChatRole
+	}
+	chat, err := s.groupChatAdmin(ctx, chatID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !chatHasUser(chat, userID) {
+		return nil, ErrChatMemberNotFound
+	}
+	if role == ChatRoleMember && chatHasAdmin(chat, userID) && len(chat.AdminIDs) == 1 {
+		return nil, ErrLastChatAdmin
+	}
+
+	if err := s.repo.SetChatParticipantRole(ctx, chatID, userID, role); err != nil {
+		return nil, err
+	}
+	return s.repo.GetChatByID(ctx, chatID)
+}
+
+// groupChat loads a group chat the user is a member of
+func (s *ChatService) groupChat(ctx context.Context, chatID, userID uuid.UUID) (*Chat, error) {
+	chat, err := s.repo.GetChatByID(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+	if !chatHasUser(chat, userID) {
+		return nil, ErrNotChatParticipant
+	}
+	if !chat.IsGroup {
+		return nil, ErrNotGroupChat
+	}
+	return chat, nil
+}
+
+// groupChatAdmin loads a group chat the user is an admin of
+func (s *ChatService) groupChatAdmin(ctx context.Context, chatID, userID uuid.UUID) (*Chat, error) {
+	chat, err := s.groupChat(ctx, chatID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !chatHasAdmin(chat, userID) {
+		return nil, ErrNotChatAdmin
+	}
+	return chat, nil
+}
+
+// checkGroupMembers requires every new member to be active and connected with
+// the user adding them
+func (s *ChatService) checkGroupMembers(ctx context.Context, adderID uuid.UUID, memberIDs []uuid.UUID) error {
+	connected, err := s.connRepo.GetConnectedUserIDs(ctx, adderID)
+	if err != nil {
+		return err
+	}
+	isConnected := make(map[uuid.UUID]bool, len(connected))
+	for _, id := range connected {
+		isConnected[id] = true
+	}
+
+	for _, id := range memberIDs {
+		if !isConnected[id] {
+			return ErrInvalidGroupMembers
+		}
+		// GetUserByID only returns active users
+		if _, err := s.userRepo.GetUserByID(ctx, id); err != nil {
+			if errors.Is(err, ErrUserNotFound) {
+				return ErrInvalidGroupMembers
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *ChatService) notifyAddedToGroup(chat *Chat, adderID uuid.UUID, memberIDs []uuid.UUID) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	adderName := "Someone"
+	for _, u := range chat.Users {
+		if u.ID == adderID {
+			adderName = u.Name
+		}
+	}
+	for _, id := range memberIDs {
+		err := s.notifService.SendNotification(ctx, id, "group_chat_added", chatTitle(chat, adderName), adderName+" added you to the group", map[string]interface{}{
+			"chat_id": chat.ID.String(),
+		})
+		if err != nil {
+			log.Printf("group chat: failed to notify %s: %v", id, err)
+		}
+	}
+}
+
+// newChatMembers dedupes ids, leaving out the user adding them and anyone
+// already in the chat
+func newChatMembers(ids []uuid.UUID, chat *Chat, adderID uuid.UUID) []uuid.UUID {
+	seen := map[uuid.UUID]bool{adderID: true}
+	if chat != nil {
+		for _, u := range chat.Users {
+			seen[u.ID] = true
+		}
+	}
+	var members []uuid.UUID
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			members = append(members, id)
+		}
+	}
+	return members
+}
+
+// groupAvatarURL trims an avatar URL, returning nil for an empty one
+func groupAvatarURL(avatarURL *string) (*string, error) {
+	if avatarURL == nil {
+		return nil, nil
+	}
+	trimmed := strings.TrimSpace(*avatarURL)
+	if trimmed == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(trimmed)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, ErrInvalidChatAvatar
+	}
+	return &trimmed, nil
+}
+
+// chatTitle is how a chat is named in notifications: the group name, or
+// otherwise the other person's name
+func chatTitle(chat *Chat, otherName string) string {
+	if chat.IsGroup && chat.Name != nil {
+		return *chat.Name
+	}
+	return otherName
+}
+
+func chatHasAdmin(chat *Chat, userID uuid.UUID) bool {
+	for _, id := range chat.AdminIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
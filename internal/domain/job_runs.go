@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/locolive/backend/internal/metrics"
+)
+
+// Background jobs whose runs are recorded
+const (
+	JobTokenCleanup      = "token_cleanup"
+	JobStoryCleanup      = "story_cleanup"
+	JobAccountPurge      = "account_purge"
+	JobPartitions        = "partition_maintenance"
+	JobMessageEncryption = "message_encryption"
+	JobWeeklyRecap       = "weekly_recap"
+	JobCampaigns         = "campaigns"
+	JobLiveSessions      = "live_sessions"
+	JobDataExports       = "data_exports"
+	JobMessageRetention  = "message_retention"
+	JobNearbyFeeds       = "nearby_feeds"
+)
+
+// JobRunRetention is how long job runs are kept
+const JobRunRetention = 30 * 24 * time.Hour
+
+// JobRun is one run of a background job. Error is set when any part of the
+// run failed; rows done before the failure are still counted.
+type JobRun struct {
+	Job          string    `json:"job"`
+	StartedAt    time.Time `json:"started_at"`
+	DurationMs   int64     `json:"duration_ms"`
+	RowsAffected int64     `json:"rows_affected"`
+	Error        *string   `json:"error,omitempty"`
+}
+
+type JobRunRepository interface {
+	RecordJobRun(ctx context.Context, run *JobRun) error
+	// GetLatestJobRuns returns the last run of each job
+	GetLatestJobRuns(ctx context.Context) ([]*JobRun, error)
+	ListJobRuns(ctx context.Context, job string, limit, offset int) ([]*JobRun, error)
+}
+
+// JobTracker times background job runs and records them in job_runs and
+// the metrics registry
+type JobTracker struct {
+	repo     JobRunRepository
+	lastRun  *metrics.GaugeVec
+	duration *metrics.HistogramVec
+	rows     *metrics.CounterVec
+	failures *metrics.CounterVec
+}
+
+func NewJobTracker(repo JobRunRepository, registry *metrics.Registry) *JobTracker {
+	return &JobTracker{
+		repo:     repo,
+		lastRun:  registry.Gauge("job_last_run_timestamp_seconds", "When each background job last finished", "job"),
+		duration: registry.Histogram("job_duration_seconds", "Background job run time", []float64{0.1, 0.5, 1, 5, 10, 30, 60, 300, 900}, "job"),
+		rows:     registry.Counter("job_rows_affected_total", "Rows changed by background jobs", "job"),
+		failures: registry.Counter("job_failures_total", "Background job runs that failed", "job"),
+	}
+}
+
+// Track runs fn as a run of job and records how it went. fn returns how many
+// rows it changed and logs its own failures, with whatever detail it has.
+func (t *JobTracker) Track(ctx context.Context, job string, fn func(ctx context.Context) (int64, error)) {
+	started := time.Now()
+	rows, err := fn(ctx)
+	elapsed := time.Since(started)
+
+	t.lastRun.Set(float64(time.Now().Unix()), job)
+	t.duration.Observe(elapsed.Seconds(), job)
+	t.rows.Add(float64(rows), job)
+
+	run := &JobRun{
+		Job:          job,
+		StartedAt:    started,
+		DurationMs:   elapsed.Milliseconds(),
+		RowsAffected: rows,
+	}
+	if err != nil {
+		t.failures.Inc(job)
+		msg := err.Error()
+		run.Error = &msg
+	}
+
+	if err := t.repo.RecordJobRun(ctx, run); err != nil {
+		log.Printf("jobs: failed to record %s run: %v", job, err)
+	}
+}
+
+// GetJobs returns the last run of every background job that has run
+func (s *AdminService) GetJobs(ctx context.Context) ([]*JobRun, error) {
+	return s.repo.GetLatestJobRuns(ctx)
+}
+
+// GetJobRuns returns a job's runs, newest first
+func (s *AdminService) GetJobRuns(ctx context.Context, job string, limit, offset int) ([]*JobRun, error) {
+	limit, offset = AdminPageLimits.Clamp(limit, offset)
+	return s.repo.ListJobRuns(ctx, job, limit, offset)
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrLegalHoldNotFound       = errors.New("legal hold not found")
+	ErrLegalHoldExists         = errors.New("user is already under legal hold")
+	ErrLegalHoldReasonRequired = errors.New("legal hold reason is required")
+	ErrLegalHoldReleased       = errors.New("legal hold already released")
+)
+
+// LegalHold exempts a user's data from every retention and cleanup job until released
+type LegalHold struct {
+	ID            uuid.UUID  `json:"id"`
+	UserID        uuid.UUID  `json:"user_id"`
+	Reason        string     `json:"reason"`
+	CaseReference *string    `json:"case_reference,omitempty"`
+	PlacedBy      uuid.UUID  `json:"placed_by"`
+	PlacedAt      time.Time  `json:"placed_at"`
+	ReleasedBy    *uuid.UUID `json:"released_by,omitempty"`
+	ReleasedAt    *time.Time `json:"released_at,omitempty"`
+}
+
+// PreservationSnapshot is the user's data as it stood when the hold was placed.
+// Checksum is the hex SHA-256 of Data; Verified reports whether it still matches.
+type PreservationSnapshot struct {
+	ID        uuid.UUID       `json:"id"`
+	HoldID    uuid.UUID       `json:"hold_id"`
+	UserID    uuid.UUID       `json:"user_id"`
+	Data      json.RawMessage `json:"data"`
+	Checksum  string          `json:"checksum"`
+	Verified  bool            `json:"verified"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type LegalHoldRepository interface {
+	// PlaceLegalHold creates the hold and its preservation snapshot atomically.
+	// It returns ErrLegalHoldExists if the user already has an active hold and
+	// ErrUserNotFound if the user doesn't exist.
+	PlaceLegalHold(ctx context.Context, hold *LegalHold) (*PreservationSnapshot, error)
+	ReleaseLegalHold(ctx context.Context, holdID, releasedBy uuid.UUID) (*LegalHold, error)
+	GetLegalHold(ctx context.Context, holdID uuid.UUID) (*LegalHold, error)
+	GetLegalHolds(ctx context.Context, activeOnly bool, limit, offset int) ([]*LegalHold, error)
+	GetPreservationSnapshot(ctx context.Context, holdID uuid.UUID) (*PreservationSnapshot, error)
+}
+
+// SnapshotChecksum returns the hex SHA-256 of snapshot data
+func SnapshotChecksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
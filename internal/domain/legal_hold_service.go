@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// LegalHoldService places and releases legal holds. Every call, reads
+// included, is written to the audit log first; if that fails the call fails.
+type LegalHoldService struct {
+	repo  LegalHoldRepository
+	audit AuditRepository
+}
+
+func NewLegalHoldService(repo LegalHoldRepository, audit AuditRepository) *LegalHoldService {
+	return &LegalHoldService{
+		repo:  repo,
+		audit: audit,
+	}
+}
+
+// PlaceHold puts a user under legal hold and preserves a snapshot of their data
+func (s *LegalHoldService) PlaceHold(ctx context.Context, adminID, userID uuid.UUID, reason string, caseReference *string) (*LegalHold, *PreservationSnapshot, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, nil, ErrLegalHoldReasonRequired
+	}
+
+	details := map[string]interface{}{"reason": reason}
+	if caseReference != nil {
+		details["case_reference"] = *caseReference
+	}
+	if err := s.record(ctx, adminID, AuditLegalHoldPlace, &userID, details); err != nil {
+		return nil, nil, err
+	}
+
+	hold := &LegalHold{
+		UserID:        userID,
+		Reason:        reason,
+		CaseReference: caseReference,
+		PlacedBy:      adminID,
+	}
+	snapshot, err := s.repo.PlaceLegalHold(ctx, hold)
+	if err != nil {
+		return nil, nil, err
+	}
+	snapshot.Data = nil
+	snapshot.Verified = true
+	return hold, snapshot, nil
+}
+
+// ReleaseHold lifts a hold; the snapshot is kept
+func (s *LegalHoldService) ReleaseHold(ctx context.Context, adminID, holdID uuid.UUID) (*LegalHold, error) {
+	hold, err := s.repo.GetLegalHold(ctx, holdID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.record(ctx, adminID, AuditLegalHoldRelease, &hold.UserID, map[string]interface{}{"hold_id": holdID.String()}); err != nil {
+		return nil, err
+	}
+	return s.repo.ReleaseLegalHold(ctx, holdID, adminID)
+}
+
+// GetHolds lists legal holds, newest first
+func (s *LegalHoldService) GetHolds(ctx context.Context, adminID uuid.UUID, activeOnly bool, limit, offset int) ([]*LegalHold, error) {
+	limit, offset = AdminPageLimits.Clamp(limit, offset)
+	if err := s.record(ctx, adminID, AuditLegalHoldList, nil, map[string]interface{}{"active_only": activeOnly}); err != nil {
+		return nil, err
+	}
+	return s.repo.GetLegalHolds(ctx, activeOnly, limit, offset)
+}
+
+// GetSnapshot returns a hold's preserved data and whether it still matches its checksum
+func (s *LegalHoldService) GetSnapshot(ctx context.Context, adminID, holdID uuid.UUID) (*PreservationSnapshot, error) {
+	hold, err := s.repo.GetLegalHold(ctx, holdID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.record(ctx, adminID, AuditLegalHoldSnapshotView, &hold.UserID, map[string]interface{}{"hold_id": holdID.String()}); err != nil {
+		return nil, err
+	}
+
+	snapshot, err := s.repo.GetPreservationSnapshot(ctx, holdID)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Verified = SnapshotChecksum(snapshot.Data) == snapshot.Checksum
+	return snapshot, nil
+}
+
+// GetAuditLog returns admin actions taken on a user, newest first
+func (s *LegalHoldService) GetAuditLog(ctx context.Context, adminID, userID uuid.UUID, limit, offset int) ([]*AuditEntry, error) {
+	limit, offset = AdminPageLimits.Clamp(limit, offset)
+	if err := s.record(ctx, adminID, AuditLogView, &userID, nil); err != nil {
+		return nil, err
+	}
+	return s.audit.GetAuditLog(ctx, userID, limit, offset)
+}
+
+func (s *LegalHoldService) record(ctx context.Context, actorID uuid.UUID, action AuditAction, targetUserID *uuid.UUID, details map[string]interface{}) error {
+	return s.audit.RecordAudit(ctx, AuditEntry{
+		ActorID:      actorID,
+		Action:       action,
+		TargetUserID: targetUserID,
+		Details:      details,
+	})
+}
@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrLiveRequiresLocation = errors.New("live stories need a location")
+	ErrNoLiveSession        = errors.New("no live session in progress")
+)
+
+const (
+	// LiveSessionIdleTimeout ends a live session when its author stops posting
+	LiveSessionIdleTimeout = 30 * time.Minute
+	// LiveSessionMaxDuration caps how long one session stays pinned
+	LiveSessionMaxDuration = 6 * time.Hour
+	// LiveAreaDefaultRadiusMeters is the area watched for live events when a client doesn't pick one
+	LiveAreaDefaultRadiusMeters = 5000.0
+	// LiveAreaMaxRadiusMeters caps the area a client can watch for live events
+	LiveAreaMaxRadiusMeters = 50000.0
+)
+
+// WebSocket events sent to subscribers of an area
+const (
+	EventLiveStory = "live_story"
+	EventLiveEnded = "live_ended"
+)
+
+// LiveSession is a run of live stories from one author at one spot
+type LiveSession struct {
+	ID         uuid.UUID  `json:"id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	Lat        float64    `json:"-"`
+	Lng        float64    `json:"-"`
+	StartedAt  time.Time  `json:"started_at"`
+	LastPostAt time.Time  `json:"last_post_at"`
+	EndedAt    *time.Time `json:"ended_at,omitempty"`
+}
+
+// LiveCutoffs returns the last_post_at and started_at times a session must be
+// after to still be live
+func LiveCutoffs(now time.Time) (idleSince, startedSince time.Time) {
+	return now.Add(-LiveSessionIdleTimeout), now.Add(-LiveSessionMaxDuration)
+}
+
+type LiveRepository interface {
+	// EndLiveSession ends the user's session, returning ErrNoLiveSession if none is in progress
+	EndLiveSession(ctx context.Context, userID uuid.UUID) (*LiveSession, error)
+	// EndStaleLiveSessions ends sessions idle since idleSince or started before startedSince
+	EndStaleLiveSessions(ctx context.Context, idleSince, startedSince time.Time) ([]*LiveSession, error)
+}
+
+// AreaBroadcaster pushes an event to clients watching an area around a point
+type AreaBroadcaster interface {
+	BroadcastToArea(lat, lng float64, event string, payload interface{})
+}
+
+// LiveService announces live stories to nearby subscribers and ends sessions
+type LiveService struct {
+	repo        LiveRepository
+	broadcaster AreaBroadcaster
+}
+
+func NewLiveService(repo LiveRepository, broadcaster AreaBroadcaster) *LiveService {
+	return &LiveService{repo: repo, broadcaster: broadcaster}
+}
+
+// PublishStory tells subscribers near a live story that it was posted
+func (s *LiveService) PublishStory(story *Story) {
+	if !story.Live || story.LocationLat == nil || story.LocationLng == nil {
+		return
+	}
+	s.broadcaster.BroadcastToArea(*story.LocationLat, *story.LocationLng, EventLiveStory, story)
+}
+
+// EndSession ends the user's live session now
+func (s *LiveService) EndSession(ctx context.Context, userID uuid.UUID) error {
+	session, err := s.repo.EndLiveSession(ctx, userID)
+	if err != nil {
+		return err
+	}
+	s.announceEnded(session)
+	return nil
+}
+
+// Run ends stale sessions every interval until ctx is cancelled
+func (s *LiveService) Run(ctx context.Context, interval time.Duration, jobs *JobTracker) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			jobs.Track(ctx, JobLiveSessions, s.endStale)
+		}
+	}
+}
+
+func (s *LiveService) endStale(ctx context.Context) (int64, error) {
+	idleSince, startedSince := LiveCutoffs(time.Now())
+	ended, err := s.repo.EndStaleLiveSessions(ctx, idleSince, startedSince)
+	if err != nil {
+		log.Printf("live sessions: failed to end stale sessions: %v", err)
+		return 0, err
+	}
+	for _, session := range ended {
+		s.announceEnded(session)
+	}
+	return int64(len(ended)), nil
+}
+
+func (s *LiveService) announceEnded(session *LiveSession) {
+	s.broadcaster.BroadcastToArea(session.Lat, session.Lng, EventLiveEnded, map[string]interface{}{
+		"session_id": session.ID,
+		"user_id":    session.UserID,
+	})
+}
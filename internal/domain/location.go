@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// Flags recorded on stories whose coordinates look spoofed
+const (
+	LocationFlagNullIsland       = "null_island"
+	LocationFlagImpossibleTravel = "impossible_travel"
+	LocationFlagOutsideArea      = "outside_service_area"
+)
+
+const earthRadiusKm = 6371.0
+
+// LocationFuzzGridMeters is the grid size fuzzed story locations are snapped to
+const LocationFuzzGridMeters = 200.0
+
+const metersPerDegreeLat = 111320.0
+
+// GeoBounds is a latitude/longitude bounding box
+type GeoBounds struct {
+	MinLat, MinLng, MaxLat, MaxLng float64
+}
+
+// Contains reports whether the point lies inside the box
+func (b GeoBounds) Contains(lat, lng float64) bool {
+	return lat >= b.MinLat && lat <= b.MaxLat && lng >= b.MinLng && lng <= b.MaxLng
+}
+
+// LocationPoint is a position reported at a point in time
+type LocationPoint struct {
+	Lat float64
+	Lng float64
+	At  time.Time
+}
+
+// LocationPolicy holds the thresholds used to detect spoofed coordinates
+type LocationPolicy struct {
+	// ServiceArea is where the app operates; nil disables the check
+	ServiceArea *GeoBounds
+	// MaxTravelSpeedKmh is the fastest plausible speed between consecutive posts
+	MaxTravelSpeedKmh float64
+}
+
+// minTravelCheckKm ignores GPS jitter and short hops when checking travel speed
+const minTravelCheckKm = 5.0
+
+// CheckLocation returns the spoofing flags for next given the user's previous
+// known position, which may be nil
+func (p LocationPolicy) CheckLocation(prev *LocationPoint, next LocationPoint) []string {
+	var flags []string
+
+	// (0, 0) is where failed geocoders and fake GPS apps default to
+	if math.Abs(next.Lat) < 0.1 && math.Abs(next.Lng) < 0.1 {
+		flags = append(flags, LocationFlagNullIsland)
+	}
+
+	if p.ServiceArea != nil && !p.ServiceArea.Contains(next.Lat, next.Lng) {
+		flags = append(flags, LocationFlagOutsideArea)
+	}
+
+	if prev != nil && p.MaxTravelSpeedKmh > 0 {
+		distance := HaversineKm(prev.Lat, prev.Lng, next.Lat, next.Lng)
+		if distance > minTravelCheckKm {
+			// Floor the interval so near-simultaneous posts don't divide by ~0
+			hours := math.Max(next.At.Sub(prev.At).Hours(), 1.0/60)
+			if distance/hours > p.MaxTravelSpeedKmh {
+				flags = append(flags, LocationFlagImpossibleTravel)
+			}
+		}
+	}
+
+	return flags
+}
+
+// HaversineKm returns the great-circle distance between two points in kilometers
+func HaversineKm(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := math.Pi / 180
+	dLat := (lat2 - lat1) * toRad
+	dLng := (lng2 - lng1) * toRad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*toRad)*math.Cos(lat2*toRad)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// SnapToGrid returns the center of the gridMeters-sized cell containing the point.
+// Snapping, unlike random jitter, can't be averaged out across many posts.
+func SnapToGrid(lat, lng, gridMeters float64) (float64, float64) {
+	latStep := gridMeters / metersPerDegreeLat
+	snappedLat := math.Floor(lat/latStep)*latStep + latStep/2
+
+	// Longitude degrees shrink towards the poles; size cells from the snapped
+	// latitude so every point in a row shares the same grid
+	lngStep := latStep / math.Max(math.Cos(snappedLat*math.Pi/180), 0.01)
+	snappedLng := math.Floor(lng/lngStep)*lngStep + lngStep/2
+
+	return snappedLat, snappedLng
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrMediaNotFound is returned for an upload that doesn't exist or that the
+// viewer may not fetch, so its existence isn't given away
+var ErrMediaNotFound = errors.New("file not found")
+
+// MediaRepository finds uploaded files by name, the last element of their URL
+type MediaRepository interface {
+	// GetAccessibleMediaURL returns the stored URL of an upload the viewer
+	// may fetch, or ErrMediaNotFound
+	GetAccessibleMediaURL(ctx context.Context, viewerID uuid.UUID, name string) (string, error)
+	// GetMediaURL returns the stored URL of any upload, or ErrMediaNotFound
+	GetMediaURL(ctx context.Context, name string) (string, error)
+}
+
+// MediaService decides who may fetch uploaded files. Story media goes to
+// those who can see the story or are in a chat it was shared into, archived
+// story media to its author, group avatars and voice notes to the chat's
+// members, data export archives to the user they are of until they expire,
+// and profile avatars and card images to anyone signed in.
+type MediaService struct {
+	repo MediaRepository
+}
+
+func NewMediaService(repo MediaRepository) *MediaService {
+	return &MediaService{repo: repo}
+}
+
+// Authorize returns the stored URL of an upload the viewer may fetch
+func (s *MediaService) Authorize(ctx context.Context, viewerID uuid.UUID, name string) (string, error) {
+	return s.repo.GetAccessibleMediaURL(ctx, viewerID, name)
+}
+
+// Find returns the stored URL of any upload, for operators reviewing reports
+func (s *MediaService) Find(ctx context.Context, name string) (string, error) {
+	return s.repo.GetMediaURL(ctx, name)
+}
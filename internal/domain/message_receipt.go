@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrTooManyDeliveredMessages is returned for a delivered call over MaxDeliveredBatch
+var ErrTooManyDeliveredMessages = errors.New("too many messages to mark delivered")
+
+// MaxDeliveredBatch is the most message IDs accepted in one delivered call
+const MaxDeliveredBatch = 100
+
+// A message's status, as its sender sees it. In a group it only moves on
+// once it has for every recipient.
+const (
+	MessageStatusSent      = "sent"
+	MessageStatusDelivered = "delivered"
+	MessageStatusRead      = "read"
+)
+
+// DeliveryUpdate tells a sender that some of their messages reached a
+// recipient's device, or were read by them
+type DeliveryUpdate struct {
+	ChatID     uuid.UUID   `json:"chat_id"`
+	UserID     uuid.UUID   `json:"user_id"`
+	SenderID   uuid.UUID   `json:"-"`
+	MessageIDs []uuid.UUID `json:"message_ids"`
+	Status     string      `json:"status"`
+	At         time.Time   `json:"at"`
+}
+
+// DeliveryUpdate is the read receipt as a delivery_update for its sender
+func (r *ReadReceipt) DeliveryUpdate() *DeliveryUpdate {
+	return &DeliveryUpdate{
+		ChatID:     r.ChatID,
+		UserID:     r.ReaderID,
+		SenderID:   r.SenderID,
+		MessageIDs: r.MessageIDs,
+		Status:     MessageStatusRead,
+		At:         r.ReadAt,
+	}
+}
+
+// MarkDelivered records that the user's device received messages in a chat,
+// acknowledged over the WebSocket or after a push. Messages already marked
+// are skipped; the updates, one per sender, are for telling the senders.
+func (s *ChatService) MarkDelivered(ctx context.Context, chatID, userID uuid.UUID, messageIDs []uuid.UUID) ([]*DeliveryUpdate, error) {
+	if len(messageIDs) == 0 {
+		return nil, nil
+	}
+	if len(messageIDs) > MaxDeliveredBatch {
+		return nil, ErrTooManyDeliveredMessages
+	}
+	if err := s.requireParticipant(ctx, chatID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.MarkMessagesDelivered(ctx, chatID, userID, messageIDs)
+}
+
+// MessagePushed records that a new message reached the open WebSockets of
+// the given recipients, returning an update per recipient for its sender
+func (s *ChatService) MessagePushed(ctx context.Context, msg *Message, recipientIDs []uuid.UUID) ([]*DeliveryUpdate, error) {
+	if len(recipientIDs) == 0 {
+		return nil, nil
+	}
+	return s.repo.MarkMessageDeliveredTo(ctx, msg.ID, recipientIDs)
+}
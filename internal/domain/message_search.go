@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Message search query bounds, in characters
+const (
+	MinMessageSearchLength = 2
+	MaxMessageSearchLength = 100
+)
+
+var (
+	ErrInvalidMessageSearchQuery = errors.New("q must be 2-100 characters")
+	// ErrMessageSearchUnavailable is returned while messages are encrypted
+	// at rest, as there is no plaintext to search
+	ErrMessageSearchUnavailable = errors.New("message search is unavailable while messages are encrypted")
+)
+
+// SearchMessages finds messages in a chat the user takes part in by the
+// words they contain, best matches first. History they cleared is left out.
+func (s *ChatService) SearchMessages(ctx context.Context, chatID, userID uuid.UUID, query string, limit, offset int) ([]*Message, error) {
+	query = strings.TrimSpace(query)
+	if n := utf8.RuneCountInString(query); n < MinMessageSearchLength || n > MaxMessageSearchLength {
+		return nil, ErrInvalidMessageSearchQuery
+	}
+	clearedAt, err := s.repo.GetChatClearedAt(ctx, chatID, userID)
+	if err != nil {
+		return nil, err
+	}
+	limit, offset = MessageSearchPageLimits.Clamp(limit, offset)
+	return s.repo.SearchMessages(ctx, chatID, query, clearedAt, limit, offset)
+}
@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrAlreadyReported          = errors.New("already reported")
+	ErrCannotReportOwn          = errors.New("cannot report your own content")
+	ErrInvalidReportReason      = errors.New("invalid report reason")
+	ErrNotChatParticipant       = errors.New("not a participant in this chat")
+	ErrChatFrozen               = errors.New("chat is frozen pending review")
+	ErrModerationActionNotFound = errors.New("moderation action not found")
+	ErrModerationActionResolved = errors.New("moderation action already reviewed")
+)
+
+type ReportTargetType string
+
+const (
+	ReportTargetStory   ReportTargetType = "story"
+	ReportTargetChat    ReportTargetType = "chat"
+	ReportTargetComment ReportTargetType = "comment"
+)
+
+// ReportReasons are the accepted report reasons
+var ReportReasons = map[string]bool{
+	"spam":          true,
+	"harassment":    true,
+	"nudity":        true,
+	"violence":      true,
+	"hate":          true,
+	"impersonation": true,
+	"other":         true,
+}
+
+type Report struct {
+	ID         uuid.UUID        `json:"id"`
+	ReporterID uuid.UUID        `json:"reporter_id"`
+	TargetType ReportTargetType `json:"target_type"`
+	TargetID   uuid.UUID        `json:"target_id"`
+	Reason     string           `json:"reason"`
+	Details    *string          `json:"details,omitempty"`
+	CreatedAt  time.Time        `json:"created_at"`
+}
+
+type ModerationStatus string
+
+const (
+	ModerationPending  ModerationStatus = "pending"
+	ModerationUpheld   ModerationStatus = "upheld"
+	ModerationReversed ModerationStatus = "reversed"
+)
+
+// ModerationAction is an automatic takedown: a hidden story or comment, or a
+// frozen chat. OwnerID is the story or comment author; chats have no single owner.
+type ModerationAction struct {
+	ID          uuid.UUID        `json:"id"`
+	TargetType  ReportTargetType `json:"target_type"`
+	TargetID    uuid.UUID        `json:"target_id"`
+	OwnerID     *uuid.UUID       `json:"owner_id,omitempty"`
+	ReportCount int              `json:"report_count"`
+	Status      ModerationStatus `json:"status"`
+	CreatedAt   time.Time        `json:"created_at"`
+	ReviewedBy  *uuid.UUID       `json:"reviewed_by,omitempty"`
+	ReviewedAt  *time.Time       `json:"reviewed_at,omitempty"`
+}
+
+// TakedownRule triggers a takedown when Threshold distinct users report a
+// target within Window. A zero threshold disables the rule.
+type TakedownRule struct {
+	Threshold int
+	Window    time.Duration
+}
+
+type TakedownPolicy struct {
+	Story   TakedownRule
+	Chat    TakedownRule
+	Comment TakedownRule
+}
+
+type ModerationRepository interface {
+	// CreateReport returns ErrAlreadyReported if the reporter already reported the target
+	CreateReport(ctx context.Context, report Report) error
+	// CountRecentReports counts reports since the given time, ignoring any made
+	// before the target's last reversed takedown
+	CountRecentReports(ctx context.Context, targetType ReportTargetType, targetID uuid.UUID, since time.Time) (int, error)
+	// ApplyTakedown hides or freezes the target and records the action. It
+	// reports false if the target already has an open or upheld takedown.
+	ApplyTakedown(ctx context.Context, action *ModerationAction) (bool, error)
+	GetModerationActions(ctx context.Context, status ModerationStatus, limit, offset int) ([]*ModerationAction, error)
+	// ResolveModerationAction reviews a pending action; reversing it restores the target
+	ResolveModerationAction(ctx context.Context, actionID, reviewerID uuid.UUID, status ModerationStatus) (*ModerationAction, error)
+	GetStoryOwner(ctx context.Context, storyID uuid.UUID) (uuid.UUID, error)
+	// GetCommentOwner returns the author of a visible comment, or ErrCommentNotFound
+	GetCommentOwner(ctx context.Context, commentID uuid.UUID) (uuid.UUID, error)
+	IsUserVerified(ctx context.Context, userID uuid.UUID) (bool, error)
+}
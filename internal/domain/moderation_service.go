@@ -0,0 +1,254 @@
+package domain
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ModerationService struct {
+	repo         ModerationRepository
+	chatRepo     ChatRepository
+	abuseRepo    AbuseRepository
+	notifService *NotificationService
+	policy       TakedownPolicy
+}
+
+func NewModerationService(repo ModerationRepository, chatRepo ChatRepository, abuseRepo AbuseRepository, notifService *NotificationService, policy TakedownPolicy) *ModerationService {
+	return &ModerationService{
+		repo:         repo,
+		chatRepo:     chatRepo,
+		abuseRepo:    abuseRepo,
+		notifService: notifService,
+		policy:       policy,
+	}
+}
+
+// ReportStory records a report and hides the story if it crosses the takedown threshold
+func (s *ModerationService) ReportStory(ctx context.Context, reporterID, storyID uuid.UUID, reason string, details *string) error {
+	ownerID, err := s.repo.GetStoryOwner(ctx, storyID)
+	if err != nil {
+		return err
+	}
+	if ownerID == reporterID {
+		return ErrCannotReportOwn
+	}
+	if reason == "impersonation" {
+		verified, err := s.repo.IsUserVerified(ctx, ownerID)
+		if err != nil {
+			return err
+		}
+		if verified {
+			return ErrVerifiedImpersonation
+		}
+	}
+	return s.report(ctx, Report{
+		ReporterID: reporterID,
+		TargetType: ReportTargetStory,
+		TargetID:   storyID,
+		Reason:     reason,
+		Details:    details,
+	}, &ownerID)
+}
+
+// ReportChat records a report by a participant and freezes the chat if it crosses the threshold
+func (s *ModerationService) ReportChat(ctx context.Context, reporterID, chatID uuid.UUID, reason string, details *string) error {
+	chat, err := s.chatRepo.GetChatByID(ctx, chatID)
+	if err != nil {
+		return err
+	}
+	if !chatHasUser(chat, reporterID) {
+		return ErrNotChatParticipant
+	}
+	return s.report(ctx, Report{
+		ReporterID: reporterID,
+		TargetType: ReportTargetChat,
+		TargetID:   chatID,
+		Reason:     reason,
+		Details:    details,
+	}, nil)
+}
+
+// ReportComment records a report and hides the comment if it crosses the threshold
+func (s *ModerationService) ReportComment(ctx context.Context, reporterID, commentID uuid.UUID, reason string, details *string) error {
+	ownerID, err := s.repo.GetCommentOwner(ctx, commentID)
+	if err != nil {
+		return err
+	}
+	if ownerID == reporterID {
+		return ErrCannotReportOwn
+	}
+	return s.report(ctx, Report{
+		ReporterID: reporterID,
+		TargetType: ReportTargetComment,
+		TargetID:   commentID,
+		Reason:     reason,
+		Details:    details,
+	}, &ownerID)
+}
+
+func (s *ModerationService) report(ctx context.Context, report Report, ownerID *uuid.UUID) error {
+	if !ReportReasons[report.Reason] {
+		return ErrInvalidReportReason
+	}
+	if err := s.repo.CreateReport(ctx, report); err != nil {
+		return err
+	}
+
+	rule := s.policy.Story
+	switch report.TargetType {
+	case ReportTargetChat:
+		rule = s.policy.Chat
+	case ReportTargetComment:
+		rule = s.policy.Comment
+	}
+	if rule.Threshold <= 0 {
+		return nil
+	}
+
+	count, err := s.repo.CountRecentReports(ctx, report.TargetType, report.TargetID, time.Now().Add(-rule.Window))
+	if err != nil {
+		return err
+	}
+	if count < rule.Threshold {
+		return nil
+	}
+
+	action := &ModerationAction{
+		TargetType:  report.TargetType,
+		TargetID:    report.TargetID,
+		OwnerID:     ownerID,
+		ReportCount: count,
+	}
+	created, err := s.repo.ApplyTakedown(ctx, action)
+	if err != nil {
+		return err
+	}
+	if created {
+		log.Printf("moderation: auto takedown of %s %s after %d reports", action.TargetType, action.TargetID, count)
+		go s.notifyOwners(action)
+	}
+	return nil
+}
+
+// GetActions lists takedowns with the given status, oldest first
+func (s *ModerationService) GetActions(ctx context.Context, status ModerationStatus, limit, offset int) ([]*ModerationAction, error) {
+	limit, offset = AdminPageLimits.Clamp(limit, offset)
+	return s.repo.GetModerationActions(ctx, status, limit, offset)
+}
+
+// ResolveAction upholds or reverses a pending takedown and tells the owner
+func (s *ModerationService) ResolveAction(ctx context.Context, actionID, reviewerID uuid.UUID, uphold bool) (*ModerationAction, error) {
+	status := ModerationReversed
+	if uphold {
+		status = ModerationUpheld
+	}
+
+	action, err := s.repo.ResolveModerationAction(ctx, actionID, reviewerID, status)
+	if err != nil {
+		return nil, err
+	}
+
+	if uphold && action.OwnerID != nil {
+		err := s.abuseRepo.RecordAbuseSignal(ctx, AbuseSignal{
+			UserID:    *action.OwnerID,
+			Kind:      AbuseSignalContentTakedown,
+			Weight:    20,
+			SubjectID: &action.TargetID,
+			Details:   map[string]interface{}{"target_type": action.TargetType, "reports": action.ReportCount},
+		})
+		if err != nil {
+			log.Printf("failed to record takedown abuse signal: %v", err)
+		}
+	}
+
+	go s.notifyOwners(action)
+	return action, nil
+}
+
+// TakedownStory hides a story on a moderator's call. It's recorded as an
+// upheld takedown, so it can still be reversed from the moderation queue.
+func (s *ModerationService) TakedownStory(ctx context.Context, reviewerID, storyID uuid.UUID) (*ModerationAction, error) {
+	ownerID, err := s.repo.GetStoryOwner(ctx, storyID)
+	if err != nil {
+		return nil, err
+	}
+
+	action := &ModerationAction{TargetType: ReportTargetStory, TargetID: storyID, OwnerID: &ownerID}
+	created, err := s.repo.ApplyTakedown(ctx, action)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, ErrAlreadyTakenDown
+	}
+	return s.ResolveAction(ctx, action.ID, reviewerID, true)
+}
+
+// notifyOwners tells the story or comment author, or every chat participant, about a takedown or its review
+func (s *ModerationService) notifyOwners(action *ModerationAction) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var userIDs []uuid.UUID
+	if action.OwnerID != nil {
+		userIDs = append(userIDs, *action.OwnerID)
+	} else if action.TargetType == ReportTargetChat {
+		chat, err := s.chatRepo.GetChatByID(ctx, action.TargetID)
+		if err != nil {
+			log.Printf("moderation: failed to load chat participants: %v", err)
+			return
+		}
+		for _, u := range chat.Users {
+			userIDs = append(userIDs, u.ID)
+		}
+	}
+
+	title, body := moderationMessage(action)
+	for _, userID := range userIDs {
+		err := s.notifService.SendNotification(ctx, userID, "moderation", title, body, map[string]interface{}{
+			"action_id":   action.ID.String(),
+			"target_type": string(action.TargetType),
+			"target_id":   action.TargetID.String(),
+			"status":      string(action.Status),
+		})
+		if err != nil {
+			log.Printf("moderation: failed to notify %s: %v", userID, err)
+		}
+	}
+}
+
+func moderationMessage(action *ModerationAction) (title, body string) {
+	what := "Your story"
+	switch action.TargetType {
+	case ReportTargetChat:
+		what = "A chat of yours"
+	case ReportTargetComment:
+		what = "Your comment"
+	}
+
+	switch action.Status {
+	case ModerationUpheld:
+		return "Review complete", what + " was reviewed and will stay unavailable for breaking our community guidelines."
+	case ModerationReversed:
+		return "Content restored", what + " was reviewed and has been restored."
+	}
+	switch action.TargetType {
+	case ReportTargetChat:
+		return "Chat paused", "A chat of yours received several reports and is paused while we review it."
+	case ReportTargetComment:
+		return "Comment hidden", "Your comment received several reports and is hidden while we review it."
+	}
+	return "Story hidden", "Your story received several reports and is hidden while we review it."
+}
+
+func chatHasUser(chat *Chat, userID uuid.UUID) bool {
+	for _, u := range chat.Users {
+		if u.ID == userID {
+			return true
+		}
+	}
+	return false
+}
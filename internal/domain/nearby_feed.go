@@ -0,0 +1,192 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/locolive/backend/pkg/geohash"
+)
+
+// ErrNearbyFeedNotFound is returned for an area with no fresh materialized feed
+var ErrNearbyFeedNotFound = errors.New("no materialized feed for this area")
+
+// nearbyFeedRadiusBuckets are the radii, in meters, nearby feed queries are
+// rounded up to; larger ones are rounded up to a multiple of the last
+var nearbyFeedRadiusBuckets = []float64{1000, 2000, 5000, 10000, 20000, 50000, 100000}
+
+// NearbyFeedMaxAge is how long a materialized feed is served after it was
+// built. The worker rebuilds hot areas every minute, so only areas that
+// cooled down go stale.
+const NearbyFeedMaxAge = 3 * time.Minute
+
+// maxTrackedFeedAreas bounds the areas counted between materializer runs
+const maxTrackedFeedAreas = 100000
+
+// FeedArea is the geohash cell and radius bucket a nearby feed request snaps
+// to; everyone in the cell asking for a similar radius gets the feed around
+// its center, Lat and Lng
+type FeedArea struct {
+	Cell     string
+	Radius   float64
+	Lat, Lng float64
+}
+
+// SnapFeedArea maps a nearby feed request onto its FeedArea. The cell is
+// kept small beside the radius, under a sixth of the smallest radius in its
+// range, so centering the query on it moves the edge little.
+func SnapFeedArea(lat, lng, radius float64) FeedArea {
+	bucket := nearbyFeedRadiusBuckets[len(nearbyFeedRadiusBuckets)-1]
+	if radius > bucket {
+		bucket = math.Ceil(radius/bucket) * bucket
+	}
+	for _, b := range nearbyFeedRadiusBuckets {
+		if radius <= b {
+			bucket = b
+			break
+		}
+	}
+
+	// Cells are about 150 m, 1.2 km and 4.9 km wide at these precisions
+	precision := 7
+	switch {
+	case bucket >= 50000:
+		precision = 5
+	case bucket >= 10000:
+		precision = 6
+	}
+	cell := geohash.Encode(lat, lng, precision)
+	bounds, _ := geohash.Decode(cell)
+	centerLat, centerLng := bounds.Center()
+	return FeedArea{Cell: cell, Radius: bucket, Lat: centerLat, Lng: centerLng}
+}
+
+// NearbyFeedPolicy controls feed materialization: every minute the HotCells
+// areas most requested on this instance get their first Stories stories
+// ranked and stored. HotCells 0 turns it off.
+type NearbyFeedPolicy struct {
+	HotCells int
+	Stories  int
+}
+
+type NearbyFeedRepository interface {
+	// MaterializeNearbyFeed ranks the first max stories of the area as the
+	// nearby feed would and stores their IDs, returning how many there were
+	MaterializeNearbyFeed(ctx context.Context, area FeedArea, max int) (int, error)
+	// GetNearbyFeed returns the area's materialized story IDs if they were
+	// built after freshSince, or ErrNearbyFeedNotFound
+	GetNearbyFeed(ctx context.Context, area FeedArea, freshSince time.Time) ([]uuid.UUID, error)
+	// GetActiveStoriesByIDs returns the stories still active and visible, in
+	// the order given
+	GetActiveStoriesByIDs(ctx context.Context, ids []uuid.UUID) ([]*Story, error)
+	// DeleteStaleNearbyFeeds removes feeds built before the cutoff
+	DeleteStaleNearbyFeeds(ctx context.Context, builtBefore time.Time) (int64, error)
+}
+
+// feedHeat counts nearby feed requests per area between materializer runs
+type feedHeat struct {
+	mu    sync.Mutex
+	areas map[FeedArea]int
+}
+
+func newFeedHeat() *feedHeat {
+	return &feedHeat{areas: make(map[FeedArea]int)}
+}
+
+func (h *feedHeat) record(area FeedArea) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.areas[area]; ok || len(h.areas) < maxTrackedFeedAreas {
+		h.areas[area]++
+	}
+}
+
+// take returns the n most requested areas and starts counting afresh
+func (h *feedHeat) take(n int) []FeedArea {
+	h.mu.Lock()
+	counts := h.areas
+	h.areas = make(map[FeedArea]int)
+	h.mu.Unlock()
+
+	areas := make([]FeedArea, 0, len(counts))
+	for area := range counts {
+		areas = append(areas, area)
+	}
+	sort.Slice(areas, func(i, j int) bool { return counts[areas[i]] > counts[areas[j]] })
+	if len(areas) > n {
+		areas = areas[:n]
+	}
+	return areas
+}
+
+// getNearbyStories serves a nearby feed page from the area's materialized
+// feed when it has one that covers the page, else queries it live
+func (s *StoryService) getNearbyStories(ctx context.Context, lat, lng, radius float64, limit, offset int) ([]*Story, error) {
+	if s.nearby.HotCells <= 0 {
+		return s.repo.GetStoriesByLocation(ctx, lat, lng, radius, limit, offset)
+	}
+
+	area := SnapFeedArea(lat, lng, radius)
+	s.heat.record(area)
+
+	ids, err := s.repo.GetNearbyFeed(ctx, area, time.Now().Add(-NearbyFeedMaxAge))
+	if err != nil && !errors.Is(err, ErrNearbyFeedNotFound) {
+		return nil, err
+	}
+	// A feed cut off at Stories only covers pages within it
+	if err == nil && (offset+limit <= len(ids) || len(ids) < s.nearby.Stories) {
+		if offset >= len(ids) {
+			return []*Story{}, nil
+		}
+		ids = ids[offset:]
+		if len(ids) > limit {
+			ids = ids[:limit]
+		}
+		return s.repo.GetActiveStoriesByIDs(ctx, ids)
+	}
+	return s.repo.GetStoriesByLocation(ctx, area.Lat, area.Lng, area.Radius, limit, offset)
+}
+
+// RunNearbyFeeds materializes the feeds of the hottest areas every interval
+// until ctx is cancelled
+func (s *StoryService) RunNearbyFeeds(ctx context.Context, interval time.Duration, jobs *JobTracker) {
+	if s.nearby.HotCells <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			jobs.Track(ctx, JobNearbyFeeds, s.materializeNearbyFeeds)
+		}
+	}
+}
+
+func (s *StoryService) materializeNearbyFeeds(ctx context.Context) (int64, error) {
+	var total int64
+	var errs []error
+	for _, area := range s.heat.take(s.nearby.HotCells) {
+		n, err := s.repo.MaterializeNearbyFeed(ctx, area, s.nearby.Stories)
+		if err != nil {
+			log.Printf("nearby feeds: failed to materialize %s/%.0f: %v", area.Cell, area.Radius, err)
+			errs = append(errs, err)
+			continue
+		}
+		total += int64(n)
+	}
+
+	if _, err := s.repo.DeleteStaleNearbyFeeds(ctx, time.Now().Add(-NearbyFeedMaxAge)); err != nil {
+		log.Printf("nearby feeds: failed to delete stale feeds: %v", err)
+		errs = append(errs, err)
+	}
+	return total, errors.Join(errs...)
+}
@@ -2,29 +2,78 @@ package domain
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+var ErrNotificationNotFound = errors.New("notification not found")
+
 type Notification struct {
-	ID        uuid.UUID `json:"id"`
-	UserID    uuid.UUID `json:"user_id"`
-	Type      string    `json:"type"`
-	Title     string    `json:"title"`
-	Body      string    `json:"body"`
-	Data      Map       `json:"data"` // leveraging the Map type or map[string]interface{}
-	IsRead    bool      `json:"is_read"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	Type      string     `json:"type"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	Data      Map        `json:"data"` // leveraging the Map type or map[string]interface{}
+	IsRead    bool       `json:"is_read"`
+	OpenedAt  *time.Time `json:"opened_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
 }
 
 // Map alias for JSONB data
 type Map map[string]interface{}
 
+// NotificationBatchSize is the number of notifications written per bulk insert
+const NotificationBatchSize = 1000
+
+// BulkNotificationResult reports the outcome of a bulk send
+type BulkNotificationResult struct {
+	Created int         `json:"created"`
+	Failed  []uuid.UUID `json:"failed,omitempty"`
+}
+
+// Push delivery statuses
+const (
+	DeliverySent   = "sent"
+	DeliveryFailed = "failed"
+)
+
+// NotificationDelivery is the outcome of one push to one device
+type NotificationDelivery struct {
+	NotificationID uuid.UUID
+	UserID         uuid.UUID
+	Type           string
+	Status         string
+	ErrorClass     string // FCM error class when Status is DeliveryFailed
+}
+
+// NotificationDeliveryStats is push performance for a notification type over a
+// period. A notification counts as delivered when a push to at least one of
+// the user's devices was accepted by FCM; OpenRate is opened over delivered.
+type NotificationDeliveryStats struct {
+	Type         string         `json:"type"`
+	Sent         int            `json:"sent"`
+	Failed       int            `json:"failed"`
+	Delivered    int            `json:"delivered"`
+	Opened       int            `json:"opened"`
+	DeliveryRate float64        `json:"delivery_rate"`
+	OpenRate     float64        `json:"open_rate"`
+	Errors       map[string]int `json:"errors"`
+}
+
 type NotificationRepository interface {
-	CreateNotification(ctx context.Context, userID uuid.UUID, typeStr, title, body string, data map[string]interface{}) error
+	CreateNotification(ctx context.Context, userID uuid.UUID, typeStr, title, body string, data map[string]interface{}) (uuid.UUID, error)
+	// CreateNotifications inserts the same notification for every user in one
+	// statement and returns the notification IDs in the order of userIDs
+	CreateNotifications(ctx context.Context, userIDs []uuid.UUID, typeStr, title, body string, data map[string]interface{}) ([]uuid.UUID, error)
 	GetNotifications(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*Notification, error)
 	MarkNotificationRead(ctx context.Context, notificationID uuid.UUID) error
+	MarkNotificationOpened(ctx context.Context, userID, notificationID uuid.UUID) error
+	RecordNotificationDelivery(ctx context.Context, delivery NotificationDelivery) error
+	GetNotificationDeliveryStats(ctx context.Context, since time.Time) ([]*NotificationDeliveryStats, error)
 	UpdateSessionFCMToken(ctx context.Context, sessionID uuid.UUID, fcmToken string) error
 	GetFCMTokens(ctx context.Context, userID uuid.UUID) ([]string, error)
+	GetFCMTokensForUsers(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID][]string, error)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/locolive/backend/internal/fcm"
@@ -12,19 +13,21 @@ import (
 type NotificationService struct {
 	repo      NotificationRepository
 	fcmClient *fcm.Client
+	budget    *OutboundBudget
 }
 
-func NewNotificationService(repo NotificationRepository, fcmClient *fcm.Client) *NotificationService {
+// NewNotificationService creates a notification service. Pushes spend from
+// budget, which may be nil to leave them uncapped.
+func NewNotificationService(repo NotificationRepository, fcmClient *fcm.Client, budget *OutboundBudget) *NotificationService {
 	return &NotificationService{
 		repo:      repo,
 		fcmClient: fcmClient,
+		budget:    budget,
 	}
 }
 
 func (s *NotificationService) GetNotifications(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*Notification, error) {
-	if limit <= 0 {
-		limit = 20
-	}
+	limit, offset = NotificationPageLimits.Clamp(limit, offset)
 	return s.repo.GetNotifications(ctx, userID, limit, offset)
 }
 
@@ -32,38 +35,170 @@ func (s *NotificationService) MarkRead(ctx context.Context, userID, notification
 	return s.repo.MarkNotificationRead(ctx, notificationID)
 }
 
+// MarkOpened records that the user opened the notification from a push. It
+// also marks the notification read.
+func (s *NotificationService) MarkOpened(ctx context.Context, userID, notificationID uuid.UUID) error {
+	return s.repo.MarkNotificationOpened(ctx, userID, notificationID)
+}
+
+// GetDeliveryStats returns push delivery and open rates per notification type
+// since the given time
+func (s *NotificationService) GetDeliveryStats(ctx context.Context, since time.Time) ([]*NotificationDeliveryStats, error) {
+	stats, err := s.repo.GetNotificationDeliveryStats(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	for _, st := range stats {
+		if attempts := st.Sent + st.Failed; attempts > 0 {
+			st.DeliveryRate = float64(st.Sent) / float64(attempts)
+		}
+		if st.Delivered > 0 {
+			st.OpenRate = float64(st.Opened) / float64(st.Delivered)
+		}
+	}
+	return stats, nil
+}
+
 func (s *NotificationService) SendNotification(ctx context.Context, userID uuid.UUID, typeStr, title, body string, data map[string]interface{}) error {
 	// 1. Create in DB
-	err := s.repo.CreateNotification(ctx, userID, typeStr, title, body, data)
+	notificationID, err := s.repo.CreateNotification(ctx, userID, typeStr, title, body, data)
 	if err != nil {
 		return err
 	}
 
 	// 2. Send push if client available
 	if s.fcmClient != nil {
-		// Convert map[string]interface{} to map[string]string for FCM
-		strData := make(map[string]string)
-		for k, v := range data {
-			strData[k] = fmt.Sprintf("%v", v)
-		}
-		strData["type"] = typeStr
-
 		tokens, err := s.repo.GetFCMTokens(ctx, userID)
 		if err != nil {
 			log.Printf("failed to get fcm tokens: %v", err)
 			return nil // Don't fail the operation
 		}
+		s.push(userID, notificationID, tokens, typeStr, title, body, data)
+	}
+	return nil
+}
+
+// SendBulkNotification creates the same notification for many users in batches.
+// A failed batch is retried row by row so one bad recipient (e.g. a deleted
+// user) doesn't drop the rest; recipients that still fail are reported.
+func (s *NotificationService) SendBulkNotification(ctx context.Context, userIDs []uuid.UUID, typeStr, title, body string, data map[string]interface{}) (*BulkNotificationResult, error) {
+	result := &BulkNotificationResult{}
+
+	for start := 0; start < len(userIDs); start += NotificationBatchSize {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		batch := userIDs[start:min(start+NotificationBatchSize, len(userIDs))]
 
-		for _, token := range tokens {
-			if token == "" {
+		ids, err := s.repo.CreateNotifications(ctx, batch, typeStr, title, body, data)
+		if err == nil {
+			created := make(map[uuid.UUID]uuid.UUID, len(batch))
+			for i, userID := range batch {
+				created[userID] = ids[i]
+			}
+			result.Created += len(created)
+			s.pushToUsers(ctx, created, typeStr, title, body, data)
+			continue
+		}
+
+		log.Printf("bulk notification batch of %d failed, retrying individually: %v", len(batch), err)
+		created := make(map[uuid.UUID]uuid.UUID, len(batch))
+		for _, userID := range batch {
+			id, err := s.repo.CreateNotification(ctx, userID, typeStr, title, body, data)
+			if err != nil {
+				result.Failed = append(result.Failed, userID)
 				continue
 			}
-			go func(t string) {
-				_ = s.fcmClient.Send(context.Background(), t, title, body, strData)
-			}(token)
+			created[userID] = id
 		}
+		result.Created += len(created)
+		s.pushToUsers(ctx, created, typeStr, title, body, data)
 	}
-	return nil
+
+	return result, nil
+}
+
+// pushToUsers sends a push to every active device of the given users.
+// notificationIDs maps each user to their copy of the notification.
+func (s *NotificationService) pushToUsers(ctx context.Context, notificationIDs map[uuid.UUID]uuid.UUID, typeStr, title, body string, data map[string]interface{}) {
+	if s.fcmClient == nil || len(notificationIDs) == 0 {
+		return
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(notificationIDs))
+	for userID := range notificationIDs {
+		userIDs = append(userIDs, userID)
+	}
+	tokensByUser, err := s.repo.GetFCMTokensForUsers(ctx, userIDs)
+	if err != nil {
+		log.Printf("failed to get fcm tokens: %v", err)
+		return
+	}
+
+	for userID, tokens := range tokensByUser {
+		s.push(userID, notificationIDs[userID], tokens, typeStr, title, body, data)
+	}
+}
+
+// push sends the notification to each token and records every outcome. The
+// notification ID rides along in the data so the app can report the open.
+func (s *NotificationService) push(userID, notificationID uuid.UUID, tokens []string, typeStr, title, body string, data map[string]interface{}) {
+	// Convert map[string]interface{} to map[string]string for FCM
+	strData := make(map[string]string)
+	for k, v := range data {
+		strData[k] = fmt.Sprintf("%v", v)
+	}
+	strData["type"] = typeStr
+	strData["notification_id"] = notificationID.String()
+
+	for _, token := range tokens {
+		if token == "" {
+			continue
+		}
+		go func(t string) {
+			ctx := context.Background()
+			delivery := NotificationDelivery{
+				NotificationID: notificationID,
+				UserID:         userID,
+				Type:           typeStr,
+				Status:         DeliverySent,
+			}
+			if err := s.budget.Take(ctx, OutboundPush, NotificationPriority(typeStr)); err != nil {
+				delivery.Status = DeliveryFailed
+				delivery.ErrorClass = "budget_exhausted"
+			} else if err := s.fcmClient.Send(ctx, t, title, body, strData); err != nil {
+				delivery.Status = DeliveryFailed
+				delivery.ErrorClass = fcm.ErrorClass(err)
+			}
+			if err := s.repo.RecordNotificationDelivery(ctx, delivery); err != nil {
+				log.Printf("failed to record notification delivery: %v", err)
+			}
+		}(token)
+	}
+}
+
+// PushToDevice sends a push to one device without adding it to the user's
+// notifications, for notices that only concern that device. It runs in the
+// background and is a no-op without FCM.
+func (s *NotificationService) PushToDevice(token, typeStr, title, body string, data map[string]string) {
+	if s.fcmClient == nil || token == "" {
+		return
+	}
+	strData := map[string]string{"type": typeStr}
+	for k, v := range data {
+		strData[k] = v
+	}
+
+	go func() {
+		ctx := context.Background()
+		if err := s.budget.Take(ctx, OutboundPush, NotificationPriority(typeStr)); err != nil {
+			log.Printf("device push %s dropped: %v", typeStr, err)
+			return
+		}
+		if err := s.fcmClient.Send(ctx, token, title, body, strData); err != nil {
+			log.Printf("device push %s failed: %v", typeStr, err)
+		}
+	}()
 }
 
 func (s *NotificationService) UpdateFCMToken(ctx context.Context, sessionID uuid.UUID, token string) error {
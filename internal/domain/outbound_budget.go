@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/locolive/backend/internal/cache"
+	"github.com/locolive/backend/internal/metrics"
+)
+
+// OutboundChannel is a way we reach users through a third-party provider
+type OutboundChannel string
+
+const (
+	OutboundPush  OutboundChannel = "push"
+	OutboundEmail OutboundChannel = "email"
+)
+
+// OutboundPriority decides who gives way when a budget runs low.
+// Transactional messages (sign-in, security, replies) may use a channel's
+// whole budget; marketing only its share, so campaigns run dry first.
+type OutboundPriority string
+
+const (
+	PriorityTransactional OutboundPriority = "transactional"
+	PriorityMarketing     OutboundPriority = "marketing"
+)
+
+var ErrOutboundBudgetExhausted = errors.New("outbound message budget exhausted")
+
+// marketingNotificationTypes are the push types sent at marketing priority
+var marketingNotificationTypes = map[string]bool{
+	"campaign":     true,
+	"weekly_recap": true,
+}
+
+// NotificationPriority returns the budget priority of a notification type
+func NotificationPriority(typeStr string) OutboundPriority {
+	if marketingNotificationTypes[typeStr] {
+		return PriorityMarketing
+	}
+	return PriorityTransactional
+}
+
+// OutboundLimits caps one channel per minute and per UTC day; zero means no cap
+type OutboundLimits struct {
+	PerMinute int
+	PerDay    int
+}
+
+// OutboundBudget caps the pushes and emails we hand to providers across every
+// instance sharing the counter, so a runaway fan-out can't exhaust Firebase
+// quotas or get our sending domain blocklisted. Counter failures let messages
+// through, as an unavailable Redis shouldn't stop sign-in emails.
+type OutboundBudget struct {
+	counter        cache.Counter
+	limits         map[OutboundChannel]OutboundLimits
+	marketingShare float64
+	rejected       *metrics.CounterVec
+}
+
+// NewOutboundBudget creates a budget. marketingShare is the fraction of each
+// limit that marketing messages may use.
+func NewOutboundBudget(counter cache.Counter, limits map[OutboundChannel]OutboundLimits, marketingShare float64, registry *metrics.Registry) *OutboundBudget {
+	return &OutboundBudget{
+		counter:        counter,
+		limits:         limits,
+		marketingShare: marketingShare,
+		rejected:       registry.Counter("outbound_budget_rejected_total", "Pushes and emails dropped by the outbound budget", "channel", "priority", "window"),
+	}
+}
+
+// Take spends one message from the channel's budget, or returns
+// ErrOutboundBudgetExhausted if the priority's share of a window is used up.
+// A nil budget allows everything.
+func (b *OutboundBudget) Take(ctx context.Context, channel OutboundChannel, priority OutboundPriority) error {
+	if b == nil {
+		return nil
+	}
+
+	limits := b.limits[channel]
+	now := time.Now().UTC()
+	windows := []struct {
+		name  string
+		limit int
+		size  time.Duration
+	}{
+		{"minute", limits.PerMinute, time.Minute},
+		{"day", limits.PerDay, 24 * time.Hour},
+	}
+	for _, w := range windows {
+		if w.limit <= 0 {
+			continue
+		}
+		key := fmt.Sprintf("outbound:%s:%s:%d", channel, w.name, now.Truncate(w.size).Unix())
+		limit := int64(w.limit)
+
+		// Marketing checks before counting so messages it's refused don't
+		// eat into what's left for transactional ones
+		if priority == PriorityMarketing {
+			limit = int64(float64(w.limit) * b.marketingShare)
+			used, err := b.counter.GetInt(ctx, key)
+			if err != nil {
+				log.Printf("outbound budget counter failed: %v", err)
+				continue
+			}
+			if used >= limit {
+				b.rejected.Inc(string(channel), string(priority), w.name)
+				return ErrOutboundBudgetExhausted
+			}
+		}
+
+		used, err := b.counter.Incr(ctx, key, w.size)
+		if err != nil {
+			log.Printf("outbound budget counter failed: %v", err)
+			continue
+		}
+		if used > limit {
+			b.rejected.Inc(string(channel), string(priority), w.name)
+			return ErrOutboundBudgetExhausted
+		}
+	}
+	return nil
+}
+
+// EmailSender wraps sender so every email spends from the email budget at
+// the given priority. A nil sender stays nil.
+func (b *OutboundBudget) EmailSender(sender EmailSender, priority OutboundPriority) EmailSender {
+	if sender == nil || b == nil {
+		return sender
+	}
+	return &budgetedEmailSender{sender: sender, budget: b, priority: priority}
+}
+
+type budgetedEmailSender struct {
+	sender   EmailSender
+	budget   *OutboundBudget
+	priority OutboundPriority
+}
+
+func (s *budgetedEmailSender) SendEmail(ctx context.Context, to, subject, body string) error {
+	if err := s.budget.Take(ctx, OutboundEmail, s.priority); err != nil {
+		return err
+	}
+	return s.sender.SendEmail(ctx, to, subject, body)
+}
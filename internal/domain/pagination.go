@@ -0,0 +1,50 @@
+package domain
+
+// PageLimits bounds the page size of a list. A missing or non-positive limit
+// gets Default and a larger one than Max is cut to Max, so clients can neither
+// get an empty page by leaving the limit out nor read a whole table at once.
+type PageLimits struct {
+	Default int
+	Max     int
+}
+
+// Page sizes of the lists the API serves
+var (
+	FeedPageLimits          = PageLimits{Default: DefaultFeedLimit, Max: 50}
+	UserSearchPageLimits    = PageLimits{Default: DefaultUserSearchLimit, Max: MaxUserSearchLimit}
+	MessagePageLimits       = PageLimits{Default: 50, Max: 100}
+	MessageSearchPageLimits = PageLimits{Default: 20, Max: 50}
+	NotificationPageLimits  = PageLimits{Default: 20, Max: 100}
+	ConnectionPageLimits    = PageLimits{Default: 20, Max: 100}
+	SecurityEventPageLimits = PageLimits{Default: 50, Max: 100}
+	WavePageLimits          = PageLimits{Default: 20, Max: 100}
+	CommentPageLimits       = PageLimits{Default: 20, Max: 100}
+	ReactionPageLimits      = PageLimits{Default: 50, Max: 100}
+	ActivityPageLimits      = PageLimits{Default: 20, Max: 100}
+	// AdminPageLimits covers the operator queues and logs
+	AdminPageLimits = PageLimits{Default: 50, Max: 100}
+)
+
+// Clamp returns the limit and offset to query with
+func (l PageLimits) Clamp(limit, offset int) (int, int) {
+	if limit <= 0 {
+		limit = l.Default
+	}
+	if limit > l.Max {
+		limit = l.Max
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
+// Page returns the limit and offset of a 1-based page number. Pages below 1
+// are the first page.
+func (l PageLimits) Page(page, limit int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	limit, _ = l.Clamp(limit, 0)
+	return l.Clamp(limit, (page-1)*limit)
+}
@@ -0,0 +1,241 @@
+package domain
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/auth"
+)
+
+var (
+	ErrInvalidPhone         = errors.New("phone must be in international format, e.g. +919876543210")
+	ErrInvalidOTP           = errors.New("invalid or expired code")
+	ErrOTPAttemptsExceeded  = errors.New("too many wrong codes; request a new one")
+	ErrOTPRecentlySent      = errors.New("a code was sent recently; wait before requesting another")
+	ErrOTPLimitReached      = errors.New("too many codes requested for this number; try again later")
+	ErrPhoneSignupNeedsName = errors.New("name is required to create an account")
+	ErrPhoneAlreadyVerified = errors.New("phone is already verified on this account")
+)
+
+const (
+	// OTPLength is the number of digits in a phone code
+	OTPLength = 6
+	// OTPTTL is how long a phone code stays valid
+	OTPTTL = 5 * time.Minute
+	// OTPMaxAttempts is the number of wrong guesses that burn a code
+	OTPMaxAttempts = 5
+	// OTPResendCooldown is the minimum gap between codes to one number
+	OTPResendCooldown = time.Minute
+	// OTPHourlyLimit caps the codes sent to one number per hour
+	OTPHourlyLimit = 5
+)
+
+var e164Regex = regexp.MustCompile(`^\+[1-9]\d{7,14}$`)
+
+// NormalizePhone strips formatting from a phone number and checks it is E.164
+func NormalizePhone(phone string) (string, bool) {
+	cleaned := strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "").Replace(strings.TrimSpace(phone))
+	return cleaned, e164Regex.MatchString(cleaned)
+}
+
+// PhoneLoginResult represents the result of a phone OTP login
+type PhoneLoginResult struct {
+	User         *UserResponse `json:"user"`
+	AccessToken  string        `json:"access_token"`
+	RefreshToken string        `json:"refresh_token"`
+	IsNewUser    bool          `json:"is_new_user"`
+	ServerTime   time.Time     `json:"server_time"`
+}
+
+// RequestPhoneOTP texts a one-time code to phone, subject to per-number limits
+func (s *AuthService) RequestPhoneOTP(ctx context.Context, phone string) error {
+	phone, ok := NormalizePhone(phone)
+	if !ok {
+		return ErrInvalidPhone
+	}
+
+	latest, err := s.repo.GetLatestPhoneOTP(ctx, phone)
+	if err != nil {
+		return err
+	}
+	if latest != nil && time.Since(latest.CreatedAt) < OTPResendCooldown {
+		return ErrOTPRecentlySent
+	}
+	sent, err := s.repo.CountPhoneOTPsSince(ctx, phone, time.Now().Add(-time.Hour))
+	if err != nil {
+		return err
+	}
+	if sent >= OTPHourlyLimit {
+		return ErrOTPLimitReached
+	}
+
+	code, err := generateOTP()
+	if err != nil {
+		return err
+	}
+	if err := s.repo.CreatePhoneOTP(ctx, phone, hashOTP(phone, code), time.Now().Add(OTPTTL)); err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("%s is your LocoLive code. It expires in %d minutes. Don't share it with anyone.", code, int(OTPTTL.Minutes()))
+	return s.sms.Send(ctx, phone, body)
+}
+
+// PhoneLogin signs in with a phone code, creating an account for a new number.
+// name is only needed, and only used, when the number has no account yet.
+func (s *AuthService) PhoneLogin(ctx context.Context, phone, code, name string) (*PhoneLoginResult, error) {
+	phone, ok := NormalizePhone(phone)
+	if !ok {
+		return nil, ErrInvalidPhone
+	}
+
+	user, err := s.repo.GetUserByPhone(ctx, phone)
+	if err != nil && !errors.Is(err, ErrUserNotFound) {
+		return nil, err
+	}
+	// Checked before the code is used up so the user can retry with a name
+	if user == nil {
+		if strings.TrimSpace(name) == "" {
+			return nil, ErrPhoneSignupNeedsName
+		}
+		if name, err = s.text.Name(name); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.consumePhoneOTP(ctx, phone, code); err != nil {
+		return nil, err
+	}
+
+	isNewUser := false
+	switch {
+	case user == nil:
+		user, err = s.repo.CreateUser(ctx, CreateUserParams{
+			Phone:         &phone,
+			Name:          name,
+			PhoneVerified: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		isNewUser = true
+	case !user.PhoneVerified:
+		user, err = s.repo.SetUserPhoneVerified(ctx, user.ID, phone)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	session, err := s.newSession(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.recordSecurityEvent(ctx, user.ID, SecurityLogin, map[string]interface{}{"method": "phone", "session_id": session.ID})
+
+	var email string
+	if user.Email != nil {
+		email = *user.Email
+	}
+	tokenPair, err := s.jwt.GenerateTokenPair(user.ID, session.ID, email, session.RegionCode())
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.repo.CreateRefreshToken(ctx, CreateRefreshTokenParams{
+		UserID:    user.ID,
+		SessionID: &session.ID,
+		TokenHash: auth.HashToken(tokenPair.RefreshToken),
+		ExpiresAt: tokenPair.ExpiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PhoneLoginResult{
+		User:         user.ToResponse(),
+		AccessToken:  tokenPair.AccessToken,
+		RefreshToken: tokenPair.RefreshToken,
+		IsNewUser:    isNewUser,
+		ServerTime:   time.Now().UTC(),
+	}, nil
+}
+
+// VerifyPhone adds a phone number to the signed-in user's account using a code sent to it
+func (s *AuthService) VerifyPhone(ctx context.Context, userID uuid.UUID, phone, code string) (*UserResponse, error) {
+	phone, ok := NormalizePhone(phone)
+	if !ok {
+		return nil, ErrInvalidPhone
+	}
+
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if user.PhoneVerified && user.Phone != nil && *user.Phone == phone {
+		return nil, ErrPhoneAlreadyVerified
+	}
+
+	if err := s.consumePhoneOTP(ctx, phone, code); err != nil {
+		return nil, err
+	}
+
+	user, err = s.repo.SetUserPhoneVerified(ctx, userID, phone)
+	if err != nil {
+		return nil, err
+	}
+	return user.ToResponse(), nil
+}
+
+// consumePhoneOTP checks code against the latest code sent to phone and uses it up
+func (s *AuthService) consumePhoneOTP(ctx context.Context, phone, code string) error {
+	otp, err := s.repo.GetLatestPhoneOTP(ctx, phone)
+	if err != nil {
+		return err
+	}
+	if otp == nil || otp.ConsumedAt != nil || time.Now().After(otp.ExpiresAt) {
+		return ErrInvalidOTP
+	}
+	if otp.Attempts >= OTPMaxAttempts {
+		return ErrOTPAttemptsExceeded
+	}
+
+	if !auth.CompareTokenHash(phone+":"+strings.TrimSpace(code), otp.CodeHash) {
+		if err := s.repo.IncrementPhoneOTPAttempts(ctx, otp.ID); err != nil {
+			return err
+		}
+		return ErrInvalidOTP
+	}
+
+	consumed, err := s.repo.ConsumePhoneOTP(ctx, otp.ID)
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return ErrInvalidOTP
+	}
+	return nil
+}
+
+func hashOTP(phone, code string) string {
+	return auth.HashToken(phone + ":" + code)
+}
+
+// generateOTP returns a uniformly random numeric code of OTPLength digits
+func generateOTP() (string, error) {
+	max := big.NewInt(1)
+	for i := 0; i < OTPLength; i++ {
+		max.Mul(max, big.NewInt(10))
+	}
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", OTPLength, n), nil
+}
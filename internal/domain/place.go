@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrPlaceNotFound          = errors.New("saved place not found")
+	ErrInvalidPlace           = errors.New("invalid saved place")
+	ErrTooManyPlaces          = errors.New("too many saved places")
+	ErrLocationSharingBlocked = errors.New("location sharing is blocked inside a saved place")
+)
+
+const (
+	MaxSavedPlaces           = 10
+	MinPlaceRadiusMeters     = 50
+	MaxPlaceRadiusMeters     = 2000
+	DefaultPlaceRadiusMeters = 200
+)
+
+type PlaceKind string
+
+const (
+	PlaceKindHome  PlaceKind = "home"
+	PlaceKindWork  PlaceKind = "work"
+	PlaceKindOther PlaceKind = "other"
+)
+
+// PlacePrivacy is what happens to a story posted inside a saved place
+type PlacePrivacy string
+
+const (
+	// PlacePrivacyHide drops the story's location entirely
+	PlacePrivacyHide PlacePrivacy = "hide"
+	// PlacePrivacyFuzz forces the story's location to approximate
+	PlacePrivacyFuzz PlacePrivacy = "fuzz"
+)
+
+type SavedPlace struct {
+	ID           uuid.UUID    `json:"id"`
+	UserID       uuid.UUID    `json:"user_id"`
+	Name         string       `json:"name"`
+	Kind         PlaceKind    `json:"kind"`
+	Lat          float64      `json:"lat"`
+	Lng          float64      `json:"lng"`
+	RadiusMeters int          `json:"radius_meters"`
+	StoryPrivacy PlacePrivacy `json:"story_privacy"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+type CreatePlaceParams struct {
+	UserID       uuid.UUID
+	Name         string       `json:"name"`
+	Kind         PlaceKind    `json:"kind"`
+	Lat          float64      `json:"lat"`
+	Lng          float64      `json:"lng"`
+	RadiusMeters int          `json:"radius_meters"`
+	StoryPrivacy PlacePrivacy `json:"story_privacy"`
+}
+
+type PlaceRepository interface {
+	CreatePlace(ctx context.Context, params CreatePlaceParams) (*SavedPlace, error)
+	GetPlacesByUser(ctx context.Context, userID uuid.UUID) ([]*SavedPlace, error)
+	DeletePlace(ctx context.Context, userID, placeID uuid.UUID) error
+}
+
+// Contains reports whether the point lies within the place's radius
+func (p *SavedPlace) Contains(lat, lng float64) bool {
+	return HaversineKm(p.Lat, p.Lng, lat, lng)*1000 <= float64(p.RadiusMeters)
+}
+
+// MatchPlace returns the most restrictive place containing the point, or nil.
+// Hiding beats fuzzing when places overlap.
+func MatchPlace(places []*SavedPlace, lat, lng float64) *SavedPlace {
+	var match *SavedPlace
+	for _, p := range places {
+		if !p.Contains(lat, lng) {
+			continue
+		}
+		if match == nil || p.StoryPrivacy == PlacePrivacyHide {
+			match = p
+		}
+		if match.StoryPrivacy == PlacePrivacyHide {
+			break
+		}
+	}
+	return match
+}
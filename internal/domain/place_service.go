@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type PlaceService struct {
+	repo PlaceRepository
+	gate *FeatureGate
+}
+
+func NewPlaceService(repo PlaceRepository, gate *FeatureGate) *PlaceService {
+	return &PlaceService{repo: repo, gate: gate}
+}
+
+func (s *PlaceService) CreatePlace(ctx context.Context, params CreatePlaceParams) (*SavedPlace, error) {
+	params.Name = strings.TrimSpace(params.Name)
+	if params.Kind == "" {
+		params.Kind = PlaceKindOther
+	}
+	if params.StoryPrivacy == "" {
+		params.StoryPrivacy = PlacePrivacyFuzz
+	}
+	if params.RadiusMeters == 0 {
+		params.RadiusMeters = DefaultPlaceRadiusMeters
+	}
+	if err := validatePlace(params); err != nil {
+		return nil, err
+	}
+
+	places, err := s.repo.GetPlacesByUser(ctx, params.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if len(places) >= MaxSavedPlaces {
+		return nil, ErrTooManyPlaces
+	}
+
+	return s.repo.CreatePlace(ctx, params)
+}
+
+func validatePlace(params CreatePlaceParams) error {
+	if params.Name == "" || len(params.Name) > 100 {
+		return ErrInvalidPlace
+	}
+	switch params.Kind {
+	case PlaceKindHome, PlaceKindWork, PlaceKindOther:
+	default:
+		return ErrInvalidPlace
+	}
+	switch params.StoryPrivacy {
+	case PlacePrivacyHide, PlacePrivacyFuzz:
+	default:
+		return ErrInvalidPlace
+	}
+	if params.Lat < -90 || params.Lat > 90 || params.Lng < -180 || params.Lng > 180 {
+		return ErrInvalidPlace
+	}
+	if params.RadiusMeters < MinPlaceRadiusMeters || params.RadiusMeters > MaxPlaceRadiusMeters {
+		return ErrInvalidPlace
+	}
+	return nil
+}
+
+func (s *PlaceService) GetPlaces(ctx context.Context, userID uuid.UUID) ([]*SavedPlace, error) {
+	return s.repo.GetPlacesByUser(ctx, userID)
+}
+
+func (s *PlaceService) DeletePlace(ctx context.Context, userID, placeID uuid.UUID) error {
+	return s.repo.DeletePlace(ctx, userID, placeID)
+}
+
+// CheckLiveLocation returns ErrLocationSharingBlocked if the point is inside
+// any of the user's saved places, or ErrFeatureUnavailable if live location is
+// disabled in the caller's region. Live location updates must pass this before
+// being broadcast.
+func (s *PlaceService) CheckLiveLocation(ctx context.Context, userID uuid.UUID, lat, lng float64) error {
+	if err := s.gate.Check(ctx, FeatureLiveLocation); err != nil {
+		return err
+	}
+
+	places, err := s.repo.GetPlacesByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if MatchPlace(places, lat, lng) != nil {
+		return ErrLocationSharingBlocked
+	}
+	return nil
+}
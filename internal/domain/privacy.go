@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNothingToRectify = errors.New("no fields to rectify")
+	ErrUserOnLegalHold  = errors.New("user is under legal hold")
+)
+
+// RectifyUserParams holds corrected PII; nil fields are left unchanged
+type RectifyUserParams struct {
+	Name        *string    `json:"name"`
+	Email       *string    `json:"email"`
+	Phone       *string    `json:"phone"`
+	Bio         *string    `json:"bio"`
+	Gender      *string    `json:"gender"`
+	DateOfBirth *time.Time `json:"date_of_birth"`
+}
+
+// Fields lists the names of the fields being corrected. Only names are
+// audited; the values themselves are PII.
+func (p RectifyUserParams) Fields() []string {
+	var fields []string
+	if p.Name != nil {
+		fields = append(fields, "name")
+	}
+	if p.Email != nil {
+		fields = append(fields, "email")
+	}
+	if p.Phone != nil {
+		fields = append(fields, "phone")
+	}
+	if p.Bio != nil {
+		fields = append(fields, "bio")
+	}
+	if p.Gender != nil {
+		fields = append(fields, "gender")
+	}
+	if p.DateOfBirth != nil {
+		fields = append(fields, "date_of_birth")
+	}
+	return fields
+}
+
+// PseudonymizationResult reports what a pseudonymization changed
+type PseudonymizationResult struct {
+	UserID               uuid.UUID `json:"user_id"`
+	Pseudonym            string    `json:"pseudonym"`
+	SessionsScrubbed     int64     `json:"sessions_scrubbed"`
+	TokensRevoked        int64     `json:"tokens_revoked"`
+	AuditEntriesRedacted int64     `json:"audit_entries_redacted"`
+}
+
+// PrivacyRepository applies privacy requests. Each method writes its audit
+// entry in the same transaction as the change, so neither happens without the other.
+type PrivacyRepository interface {
+	RectifyUserPII(ctx context.Context, userID uuid.UUID, params RectifyUserParams, audit AuditEntry) (*User, error)
+	// PseudonymizeUser replaces the user's PII in users, sessions and the audit
+	// log. It returns ErrUserOnLegalHold rather than touch held data.
+	PseudonymizeUser(ctx context.Context, userID uuid.UUID, audit AuditEntry) (*PseudonymizationResult, error)
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// PrivacyService handles admin-run privacy requests on a user's PII
+type PrivacyService struct {
+	repo PrivacyRepository
+}
+
+func NewPrivacyService(repo PrivacyRepository) *PrivacyService {
+	return &PrivacyService{repo: repo}
+}
+
+// RectifyUser corrects a user's PII
+func (s *PrivacyService) RectifyUser(ctx context.Context, adminID, userID uuid.UUID, params RectifyUserParams) (*User, error) {
+	fields := params.Fields()
+	if len(fields) == 0 {
+		return nil, ErrNothingToRectify
+	}
+
+	return s.repo.RectifyUserPII(ctx, userID, params, AuditEntry{
+		ActorID:      adminID,
+		Action:       AuditPIIRectify,
+		TargetUserID: &userID,
+		Details:      map[string]interface{}{"fields": fields},
+	})
+}
+
+// PseudonymizeUser replaces a user's PII with a pseudonym and closes their sessions
+func (s *PrivacyService) PseudonymizeUser(ctx context.Context, adminID, userID uuid.UUID) (*PseudonymizationResult, error) {
+	return s.repo.PseudonymizeUser(ctx, userID, AuditEntry{
+		ActorID:      adminID,
+		Action:       AuditPIIPseudonymize,
+		TargetUserID: &userID,
+	})
+}
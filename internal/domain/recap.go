@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrRecapNotFound = errors.New("weekly recap not found")
+
+const (
+	// RecapNearbyRadiusMeters is how far from the user's last location nearby moments are taken
+	RecapNearbyRadiusMeters = 5000.0
+	RecapTopMoments         = 3
+	recapBatchSize          = 500
+)
+
+// WeeklyRecap summarises a user's week. It is sent as a push and kept as an in-app card.
+type WeeklyRecap struct {
+	UserID         uuid.UUID     `json:"user_id"`
+	WeekStart      time.Time     `json:"week_start"`
+	StoriesPosted  int           `json:"stories_posted"`
+	NewConnections int           `json:"new_connections"`
+	NearbyStories  int           `json:"nearby_stories"`
+	TopMoments     []RecapMoment `json:"top_moments"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+// RecapMoment is a nearby story highlighted in a recap. It carries no coordinates.
+type RecapMoment struct {
+	StoryID   uuid.UUID `json:"story_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	MediaURL  string    `json:"media_url"`
+	Caption   *string   `json:"caption,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WeeklyActivity is a user's rolled-up activity between two times
+type WeeklyActivity struct {
+	StoriesPosted  int
+	NewConnections int
+	// LastLat and LastLng are the most recent story location, nil if none
+	LastLat *float64
+	LastLng *float64
+}
+
+type RecapRepository interface {
+	// GetRecapCandidates returns active users with activity in [from, to) who have no
+	// recap for weekStart yet, ordered by ID and starting after the given ID
+	GetRecapCandidates(ctx context.Context, weekStart, from, to time.Time, after uuid.UUID, limit int) ([]uuid.UUID, error)
+	GetWeeklyActivity(ctx context.Context, userID uuid.UUID, from, to time.Time) (*WeeklyActivity, error)
+	GetNearbyMoments(ctx context.Context, userID uuid.UUID, lat, lng, radius float64, limit int) (int, []RecapMoment, error)
+	// SaveWeeklyRecap stores the recap and reports false if one already existed
+	SaveWeeklyRecap(ctx context.Context, recap *WeeklyRecap) (bool, error)
+	GetLatestWeeklyRecap(ctx context.Context, userID uuid.UUID) (*WeeklyRecap, error)
+}
+
+// RecapWeekStart returns the Monday 00:00 UTC that starts the week containing t
+func RecapWeekStart(t time.Time) time.Time {
+	t = t.UTC()
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,156 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RecapService struct {
+	repo         RecapRepository
+	notifService *NotificationService
+}
+
+func NewRecapService(repo RecapRepository, notifService *NotificationService) *RecapService {
+	return &RecapService{
+		repo:         repo,
+		notifService: notifService,
+	}
+}
+
+// Run sends the weekly recaps every Sunday once sendHour (UTC) has passed. It
+// checks hourly; recaps already sent for the week are skipped, so restarts and
+// repeated checks are safe.
+func (s *RecapService) Run(ctx context.Context, sendHour int, jobs *JobTracker) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			now = now.UTC()
+			if now.Weekday() != time.Sunday || now.Hour() < sendHour {
+				continue
+			}
+			jobs.Track(ctx, JobWeeklyRecap, func(ctx context.Context) (int64, error) {
+				sent, err := s.SendWeeklyRecaps(ctx, now)
+				if err != nil {
+					log.Printf("weekly recap: %v", err)
+				}
+				if sent > 0 {
+					log.Printf("weekly recap: sent %d recaps", sent)
+				}
+				return int64(sent), err
+			})
+		}
+	}
+}
+
+// SendWeeklyRecaps builds and delivers recaps for the week containing now
+func (s *RecapService) SendWeeklyRecaps(ctx context.Context, now time.Time) (int, error) {
+	weekStart := RecapWeekStart(now)
+	sent := 0
+	after := uuid.Nil
+
+	for {
+		userIDs, err := s.repo.GetRecapCandidates(ctx, weekStart, weekStart, now, after, recapBatchSize)
+		if err != nil {
+			return sent, err
+		}
+		for _, userID := range userIDs {
+			ok, err := s.sendRecap(ctx, userID, weekStart, now)
+			if err != nil {
+				log.Printf("weekly recap for %s failed: %v", userID, err)
+				continue
+			}
+			if ok {
+				sent++
+			}
+		}
+		if len(userIDs) < recapBatchSize {
+			return sent, nil
+		}
+		after = userIDs[len(userIDs)-1]
+	}
+}
+
+func (s *RecapService) sendRecap(ctx context.Context, userID uuid.UUID, weekStart, now time.Time) (bool, error) {
+	recap, err := s.BuildRecap(ctx, userID, weekStart, now)
+	if err != nil {
+		return false, err
+	}
+
+	created, err := s.repo.SaveWeeklyRecap(ctx, recap)
+	if err != nil || !created {
+		return false, err
+	}
+
+	err = s.notifService.SendNotification(ctx, userID,
+		"weekly_recap",
+		"Your week nearby",
+		recapSummary(recap),
+		map[string]interface{}{
+			"week_start":      recap.WeekStart.Format("2006-01-02"),
+			"stories_posted":  recap.StoriesPosted,
+			"new_connections": recap.NewConnections,
+			"nearby_stories":  recap.NearbyStories,
+		},
+	)
+	return err == nil, err
+}
+
+// BuildRecap assembles a user's recap for the week starting at weekStart, up to now
+func (s *RecapService) BuildRecap(ctx context.Context, userID uuid.UUID, weekStart, now time.Time) (*WeeklyRecap, error) {
+	activity, err := s.repo.GetWeeklyActivity(ctx, userID, weekStart, now)
+	if err != nil {
+		return nil, err
+	}
+
+	recap := &WeeklyRecap{
+		UserID:         userID,
+		WeekStart:      weekStart,
+		StoriesPosted:  activity.StoriesPosted,
+		NewConnections: activity.NewConnections,
+		TopMoments:     []RecapMoment{},
+		CreatedAt:      now,
+	}
+
+	if activity.LastLat != nil && activity.LastLng != nil {
+		count, moments, err := s.repo.GetNearbyMoments(ctx, userID, *activity.LastLat, *activity.LastLng, RecapNearbyRadiusMeters, RecapTopMoments)
+		if err != nil {
+			return nil, err
+		}
+		recap.NearbyStories = count
+		recap.TopMoments = moments
+	}
+	return recap, nil
+}
+
+// GetLatestRecap returns the user's most recent recap card
+func (s *RecapService) GetLatestRecap(ctx context.Context, userID uuid.UUID) (*WeeklyRecap, error) {
+	return s.repo.GetLatestWeeklyRecap(ctx, userID)
+}
+
+func recapSummary(recap *WeeklyRecap) string {
+	switch {
+	case recap.StoriesPosted > 0 && recap.NewConnections > 0:
+		return fmt.Sprintf("You posted %s and made %s this week", plural(recap.StoriesPosted, "story", "stories"), plural(recap.NewConnections, "new connection", "new connections"))
+	case recap.StoriesPosted > 0:
+		return fmt.Sprintf("You posted %s this week", plural(recap.StoriesPosted, "story", "stories"))
+	case recap.NewConnections > 0:
+		return fmt.Sprintf("You made %s this week", plural(recap.NewConnections, "new connection", "new connections"))
+	}
+	return "See what happened nearby this week"
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return "1 " + one
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
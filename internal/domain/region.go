@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrFeatureUnavailable = errors.New("feature is not available in your region")
+	ErrInvalidCountryCode = errors.New("country_code must be an ISO 3166-1 alpha-2 code")
+)
+
+// Feature names a capability that can be switched off per jurisdiction
+type Feature string
+
+const (
+	// FeatureNearbyFeed is the location-based story feed
+	FeatureNearbyFeed Feature = "nearby_feed"
+	// FeatureNearbyStrangers is discovery of stories from users you aren't connected to
+	FeatureNearbyStrangers Feature = "nearby_strangers"
+	// FeatureLiveLocation is live location sharing
+	FeatureLiveLocation Feature = "live_location"
+)
+
+// Features lists every gated feature
+var Features = []Feature{FeatureNearbyFeed, FeatureNearbyStrangers, FeatureLiveLocation}
+
+// FeatureGate disables features per region, keyed by ISO 3166-1 alpha-2 code,
+// or everywhere via kill switches. Requests from an unknown region are only
+// subject to kill switches.
+type FeatureGate struct {
+	disabled map[string]map[Feature]bool
+	killed   map[Feature]bool
+}
+
+// NewFeatureGate builds a gate from region -> disabled feature names and the
+// features killed in every region
+func NewFeatureGate(disabled map[string][]string, killSwitches []string) *FeatureGate {
+	g := &FeatureGate{
+		disabled: make(map[string]map[Feature]bool, len(disabled)),
+		killed:   make(map[Feature]bool, len(killSwitches)),
+	}
+	for _, f := range killSwitches {
+		g.killed[Feature(f)] = true
+	}
+	for region, features := range disabled {
+		region = strings.ToUpper(region)
+		if g.disabled[region] == nil {
+			g.disabled[region] = make(map[Feature]bool, len(features))
+		}
+		for _, f := range features {
+			g.disabled[region][Feature(f)] = true
+		}
+	}
+	return g
+}
+
+// Allowed reports whether feature is available in region
+func (g *FeatureGate) Allowed(region string, feature Feature) bool {
+	if g == nil {
+		return true
+	}
+	if g.killed[feature] {
+		return false
+	}
+	return region == "" || !g.disabled[region][feature]
+}
+
+// KillSwitches returns the features switched off everywhere
+func (g *FeatureGate) KillSwitches() []Feature {
+	killed := []Feature{}
+	if g == nil {
+		return killed
+	}
+	for f := range g.killed {
+		killed = append(killed, f)
+	}
+	sort.Slice(killed, func(i, j int) bool { return killed[i] < killed[j] })
+	return killed
+}
+
+// Check returns ErrFeatureUnavailable if feature is disabled in the region on ctx
+func (g *FeatureGate) Check(ctx context.Context, feature Feature) error {
+	if !g.Allowed(RegionFromContext(ctx), feature) {
+		return ErrFeatureUnavailable
+	}
+	return nil
+}
+
+type regionKey struct{}
+
+// WithRegion returns a context carrying the caller's resolved region
+func WithRegion(ctx context.Context, region string) context.Context {
+	return context.WithValue(ctx, regionKey{}, region)
+}
+
+// RegionFromContext returns the caller's region, or "" if it couldn't be resolved
+func RegionFromContext(ctx context.Context) string {
+	region, _ := ctx.Value(regionKey{}).(string)
+	return region
+}
+
+// NormalizeCountryCode upper-cases a two-letter country code, reporting false if it isn't one
+func NormalizeCountryCode(code string) (string, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 2 || code[0] < 'A' || code[0] > 'Z' || code[1] < 'A' || code[1] > 'Z' {
+		return "", false
+	}
+	return code, true
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrRemoteConfigNotFound   = errors.New("remote config entry not found")
+	ErrInvalidRemoteConfigKey = errors.New("key must be 1-100 lowercase letters, digits, dots, dashes or underscores")
+	ErrInvalidPlatform        = errors.New("platform must be ios, android or web")
+	ErrInvalidRemoteConfig    = errors.New("value must be valid JSON")
+)
+
+// Platform is a client platform; PlatformAll applies to every platform
+type Platform string
+
+const (
+	PlatformAll     Platform = ""
+	PlatformIOS     Platform = "ios"
+	PlatformAndroid Platform = "android"
+	PlatformWeb     Platform = "web"
+)
+
+// ParsePlatform validates a platform name; "" means all platforms
+func ParsePlatform(value string) (Platform, error) {
+	switch p := Platform(value); p {
+	case PlatformAll, PlatformIOS, PlatformAndroid, PlatformWeb:
+		return p, nil
+	}
+	return "", ErrInvalidPlatform
+}
+
+var remoteConfigKeyRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,99}$`)
+
+// RemoteConfigEntry is one admin-managed client config value
+type RemoteConfigEntry struct {
+	Key         string          `json:"key"`
+	Platform    Platform        `json:"platform"`
+	Value       json.RawMessage `json:"value"`
+	Description *string         `json:"description,omitempty"`
+	Version     int64           `json:"version"`
+	UpdatedBy   *uuid.UUID      `json:"updated_by,omitempty"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// RemoteConfigChange is a past change to a remote config entry
+type RemoteConfigChange struct {
+	Version   int64           `json:"version"`
+	Key       string          `json:"key"`
+	Platform  Platform        `json:"platform"`
+	Value     json.RawMessage `json:"value,omitempty"`
+	Deleted   bool            `json:"deleted"`
+	ChangedBy *uuid.UUID      `json:"changed_by,omitempty"`
+	ChangedAt time.Time       `json:"changed_at"`
+}
+
+type RemoteConfigRepository interface {
+	// GetRemoteConfig returns every entry and the current config version
+	GetRemoteConfig(ctx context.Context) ([]*RemoteConfigEntry, int64, error)
+	SetRemoteConfig(ctx context.Context, entry *RemoteConfigEntry) (*RemoteConfigEntry, error)
+	DeleteRemoteConfig(ctx context.Context, key string, platform Platform, deletedBy uuid.UUID) error
+	GetRemoteConfigHistory(ctx context.Context, key string, limit, offset int) ([]*RemoteConfigChange, error)
+}
+
+// ResolveRemoteConfig flattens entries for one platform; platform-specific
+// values override the all-platforms value for the same key
+func ResolveRemoteConfig(entries []*RemoteConfigEntry, platform Platform) map[string]json.RawMessage {
+	resolved := make(map[string]json.RawMessage, len(entries))
+	for _, e := range entries {
+		if e.Platform == PlatformAll {
+			if _, ok := resolved[e.Key]; !ok {
+				resolved[e.Key] = e.Value
+			}
+		} else if e.Platform == platform {
+			resolved[e.Key] = e.Value
+		}
+	}
+	return resolved
+}
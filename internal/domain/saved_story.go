@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// SaveStory bookmarks a story the user can see. Bookmarks don't keep a
+// story alive: once it expires it drops out of the saved list.
+func (s *StoryService) SaveStory(ctx context.Context, userID, storyID uuid.UUID) error {
+	story, err := s.repo.GetViewableStory(ctx, userID, storyID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.SaveStory(ctx, userID, storyID); err != nil {
+		return err
+	}
+	s.activity.Record(story.UserID, userID, ActivityStorySaved, &story.ID)
+	return nil
+}
+
+// UnsaveStory removes a bookmark. It succeeds whether or not the story was saved.
+func (s *StoryService) UnsaveStory(ctx context.Context, userID, storyID uuid.UUID) error {
+	return s.repo.UnsaveStory(ctx, userID, storyID)
+}
+
+// GetSavedStories lists the user's saved stories that are still active and
+// visible to them, most recently saved first
+func (s *StoryService) GetSavedStories(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*Story, error) {
+	limit, offset = FeedPageLimits.Clamp(limit, offset)
+	stories, err := s.repo.GetSavedStories(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return s.personalize(ctx, userID, stories)
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// SecurityEventType names a security-sensitive change to an account
+type SecurityEventType string
+
+const (
+	SecurityLogin             SecurityEventType = "login"
+	SecurityLogoutAll         SecurityEventType = "logout_all"
+	SecurityPasswordChange    SecurityEventType = "password_change"
+	SecurityPasswordReset     SecurityEventType = "password_reset"
+	SecurityEmailChange       SecurityEventType = "email_change"
+	SecurityRefreshTokenReuse SecurityEventType = "refresh_token_reuse"
+	SecurityProfileUpdate     SecurityEventType = "profile_update"
+)
+
+// SecurityEvent records a security-sensitive action on an account and where
+// it came from. Details hold field names and IDs, never secrets or PII values.
+type SecurityEvent struct {
+	ID        uuid.UUID              `json:"id"`
+	UserID    uuid.UUID              `json:"user_id"`
+	Event     SecurityEventType      `json:"event"`
+	IPAddress *string                `json:"ip_address,omitempty"`
+	UserAgent *string                `json:"user_agent,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+type SecurityEventRepository interface {
+	RecordSecurityEvent(ctx context.Context, event SecurityEvent) error
+	// GetSecurityEvents lists a user's events, newest first
+	GetSecurityEvents(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*SecurityEvent, error)
+}
+
+// recordSecurityEvent logs an event with the caller's IP and user agent.
+// Failures are only logged, so they never undo the action being recorded.
+func (s *AuthService) recordSecurityEvent(ctx context.Context, userID uuid.UUID, event SecurityEventType, details map[string]interface{}) {
+	entry := SecurityEvent{UserID: userID, Event: event, Details: details}
+	if client, ok := ClientInfoFromContext(ctx); ok {
+		if client.IPAddress != "" {
+			entry.IPAddress = &client.IPAddress
+		}
+		if client.UserAgent != "" {
+			entry.UserAgent = &client.UserAgent
+		}
+	}
+	if err := s.repo.RecordSecurityEvent(ctx, entry); err != nil {
+		s.logger.Error("failed to record security event",
+			zap.String("user_id", userID.String()),
+			zap.String("event", string(event)),
+			zap.Error(err),
+		)
+	}
+}
+
+// GetSecurityEvents returns the user's recent security events
+func (s *AuthService) GetSecurityEvents(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*SecurityEvent, error) {
+	limit, offset = SecurityEventPageLimits.Clamp(limit, offset)
+	return s.repo.GetSecurityEvents(ctx, userID, limit, offset)
+}
+
+// GetSecurityEvents returns a user's security events for an admin, audited
+func (s *AdminService) GetSecurityEvents(ctx context.Context, adminID, userID uuid.UUID, limit, offset int) ([]*SecurityEvent, error) {
+	limit, offset = AdminPageLimits.Clamp(limit, offset)
+	events, err := s.repo.GetSecurityEvents(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.record(ctx, adminID, AuditSecurityEventsView, &userID, nil); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// SessionPolicy caps how many sessions a user keeps. Reinstalling the app
+// signs in afresh without ending the old session, so without a cap sessions
+// and refresh tokens pile up. A zero MaxPerUser disables the cap.
+type SessionPolicy struct {
+	MaxPerUser int
+}
+
+// enforceSessionCap ends a user's least recently used sessions beyond the cap
+// and tells those devices they were signed out. Failures are only logged, as
+// the sign-in that triggered it has already succeeded.
+func (s *AuthService) enforceSessionCap(ctx context.Context, session *Session) {
+	if s.sessions.MaxPerUser <= 0 {
+		return
+	}
+
+	evicted, err := s.repo.EvictSessions(ctx, session.UserID, session.ID, s.sessions.MaxPerUser)
+	if err != nil {
+		s.logger.Error("failed to evict sessions", zap.String("user_id", session.UserID.String()), zap.Error(err))
+		return
+	}
+	if len(evicted) == 0 {
+		return
+	}
+	s.logger.Info("evicted sessions over the per-user cap",
+		zap.String("user_id", session.UserID.String()),
+		zap.Int("evicted", len(evicted)),
+	)
+
+	if s.notifService == nil {
+		return
+	}
+	for _, e := range evicted {
+		if e.FCMToken == nil {
+			continue
+		}
+		s.notifService.PushToDevice(*e.FCMToken, "session_evicted",
+			"Signed out",
+			"You were signed out on this device because your account signed in on too many others.",
+			map[string]string{"session_id": e.ID.String()},
+		)
+	}
+}
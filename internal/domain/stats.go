@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// Public stats are computed for coarse grid cells so nearby numbers are
+	// shared across everyone in the cell and can't locate a single user
+	statsNearbyGridMeters = 5000.0
+	statsNearbyRadius     = 10000.0
+	statsCityGridMeters   = 25000.0
+	statsCityRadius       = 25000.0
+	statsWeek             = 7 * 24 * time.Hour
+)
+
+// StoryArea is a coarse cell holding active stories, used to pick which
+// areas to warm up on startup
+type StoryArea struct {
+	Lat     float64
+	Lng     float64
+	Stories int
+}
+
+// PublicStats are coarse platform numbers for social proof. Nearby fields are
+// only set when a location is given.
+type PublicStats struct {
+	ActiveStories           int       `json:"active_stories"`
+	WeeklyActiveUsers       int       `json:"weekly_active_users"`
+	ActiveStoriesNearby     *int      `json:"active_stories_nearby,omitempty"`
+	UsersInYourCityThisWeek *int      `json:"users_in_your_city_this_week,omitempty"`
+	GeneratedAt             time.Time `json:"generated_at"`
+}
+
+type StatsRepository interface {
+	CountActiveStories(ctx context.Context) (int, error)
+	CountActiveStoriesNear(ctx context.Context, lat, lng, radius float64) (int, error)
+	// CountWeeklyActiveUsers counts users with session activity since the given time
+	CountWeeklyActiveUsers(ctx context.Context, since time.Time) (int, error)
+	// CountPostersNear counts users whose rolled-up story location is near the point since the given time
+	CountPostersNear(ctx context.Context, lat, lng, radius float64, since time.Time) (int, error)
+}
+
+// CoarseCount rounds a count down so public numbers don't reveal exact activity
+func CoarseCount(n int) int {
+	switch {
+	case n < 10:
+		return n
+	case n < 100:
+		return n / 10 * 10
+	case n < 1000:
+		return n / 50 * 50
+	default:
+		return n / 100 * 100
+	}
+}
@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/locolive/backend/internal/cache"
+)
+
+type StatsService struct {
+	repo  StatsRepository
+	cache cache.Cache
+	ttl   time.Duration
+}
+
+func NewStatsService(repo StatsRepository, cache cache.Cache, ttl time.Duration) *StatsService {
+	return &StatsService{
+		repo:  repo,
+		cache: cache,
+		ttl:   ttl,
+	}
+}
+
+// GetPublicStats returns cached platform-wide numbers, plus nearby numbers when lat/lng are given
+func (s *StatsService) GetPublicStats(ctx context.Context, lat, lng *float64) (*PublicStats, error) {
+	var stats PublicStats
+	err := s.cached(ctx, "stats:public:global", &stats, func() (interface{}, error) {
+		active, err := s.repo.CountActiveStories(ctx)
+		if err != nil {
+			return nil, err
+		}
+		weekly, err := s.repo.CountWeeklyActiveUsers(ctx, time.Now().Add(-statsWeek))
+		if err != nil {
+			return nil, err
+		}
+		return PublicStats{
+			ActiveStories:     CoarseCount(active),
+			WeeklyActiveUsers: CoarseCount(weekly),
+			GeneratedAt:       time.Now().UTC(),
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if lat == nil || lng == nil {
+		return &stats, nil
+	}
+
+	nearLat, nearLng := SnapToGrid(*lat, *lng, statsNearbyGridMeters)
+	var nearby int
+	err = s.cached(ctx, fmt.Sprintf("stats:public:nearby:%.4f:%.4f", nearLat, nearLng), &nearby, func() (interface{}, error) {
+		n, err := s.repo.CountActiveStoriesNear(ctx, nearLat, nearLng, statsNearbyRadius)
+		return CoarseCount(n), err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cityLat, cityLng := SnapToGrid(*lat, *lng, statsCityGridMeters)
+	var city int
+	err = s.cached(ctx, fmt.Sprintf("stats:public:city:%.4f:%.4f", cityLat, cityLng), &city, func() (interface{}, error) {
+		n, err := s.repo.CountPostersNear(ctx, cityLat, cityLng, statsCityRadius, time.Now().Add(-statsWeek))
+		return CoarseCount(n), err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stats.ActiveStoriesNearby = &nearby
+	stats.UsersInYourCityThisWeek = &city
+	return &stats, nil
+}
+
+// cached decodes key into dst, or computes, stores and decodes it on a miss.
+// Cache failures only cost a recomputation.
+func (s *StatsService) cached(ctx context.Context, key string, dst interface{}, compute func() (interface{}, error)) error {
+	if data, err := s.cache.Get(ctx, key); err == nil {
+		if json.Unmarshal(data, dst) == nil {
+			return nil
+		}
+	}
+
+	value, err := compute()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if err := s.cache.Set(ctx, key, data, s.ttl); err != nil {
+		log.Printf("failed to cache %s: %v", key, err)
+	}
+	return json.Unmarshal(data, dst)
+}
@@ -2,22 +2,82 @@ package domain
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+var (
+	ErrStoryNotFound      = errors.New("story not found")
+	ErrTooManySeenStories = errors.New("too many stories to mark seen")
+	ErrTooManyImpressions = errors.New("too many story impressions")
+)
+
 type Story struct {
-	ID          uuid.UUID     `json:"id"`
-	UserID      uuid.UUID     `json:"user_id"`
-	MediaURL    string        `json:"media_url"`
-	MediaType   string        `json:"media_type"` // "image" or "video"
-	Caption     *string       `json:"caption,omitempty"`
-	LocationLat *float64      `json:"location_lat,omitempty"`
-	LocationLng *float64      `json:"location_lng,omitempty"`
-	ExpiresAt   time.Time     `json:"expires_at"`
-	CreatedAt   time.Time     `json:"created_at"`
-	User        *UserResponse `json:"user,omitempty"` // For feed response
+	ID          uuid.UUID `json:"id"`
+	UserID      uuid.UUID `json:"user_id"`
+	MediaURL    string    `json:"media_url"`
+	MediaType   string    `json:"media_type"` // "image" or "video"
+	Caption     *string   `json:"caption,omitempty"`
+	LocationLat *float64  `json:"location_lat,omitempty"`
+	LocationLng *float64  `json:"location_lng,omitempty"`
+	// LocationFuzzed means the coordinates above are snapped to a coarse grid for display
+	LocationFuzzed bool          `json:"location_approximate"`
+	ExpiresAt      time.Time     `json:"expires_at"`
+	CreatedAt      time.Time     `json:"created_at"`
+	User           *UserResponse `json:"user,omitempty"` // For feed response
+	// Seen is whether the viewer has already seen the story; only set on personal feeds
+	Seen bool `json:"seen"`
+	// Live is set while the story's live session is in progress; live stories
+	// are pinned to the top of the nearby feed
+	Live bool `json:"live"`
+	// CommentCount and CommentsDisabled are only set on feeds
+	CommentCount     int  `json:"comment_count"`
+	CommentsDisabled bool `json:"comments_disabled"`
+}
+
+// SeenMode controls how a personal feed treats stories the viewer has already seen
+type SeenMode string
+
+const (
+	SeenInclude SeenMode = "include"
+	SeenExclude SeenMode = "exclude"
+	// SeenLast keeps seen stories but ranks them after unseen ones
+	SeenLast SeenMode = "last"
+)
+
+// MaxSeenBatch is the most story IDs accepted in one mark-seen call
+const MaxSeenBatch = 100
+
+// MaxImpressionBatch is the most story IDs accepted in one impressions call
+const MaxImpressionBatch = 200
+
+// StoryReach is how many people a story reached. Impressions counts viewers
+// whose app rendered it, Views those who opened it.
+type StoryReach struct {
+	StoryID     uuid.UUID `json:"story_id"`
+	Impressions int       `json:"impressions"`
+	Views       int       `json:"views"`
+	ViewRate    float64   `json:"view_rate"`
+	// Shares counts the times it was shared into chats
+	Shares int `json:"shares"`
+}
+
+// ArchivedStory is the retained metadata of an expired story
+type ArchivedStory struct {
+	ID          uuid.UUID `json:"id"`
+	UserID      uuid.UUID `json:"user_id"`
+	MediaURL    string    `json:"media_url"`
+	MediaType   string    `json:"media_type"`
+	Caption     *string   `json:"caption,omitempty"`
+	LocationLat *float64  `json:"location_lat,omitempty"`
+	LocationLng *float64  `json:"location_lng,omitempty"`
+	// Archived coordinates are always exact; LocationFuzzed records what users saw
+	LocationFuzzed bool      `json:"location_fuzzed"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	CreatedAt      time.Time `json:"created_at"`
+	ArchivedAt     time.Time `json:"archived_at"`
 }
 
 type CreateStoryParams struct {
@@ -28,11 +88,64 @@ type CreateStoryParams struct {
 	LocationLat *float64
 	LocationLng *float64
 	ExpiresAt   time.Time // Calculated by service usually
+	// FuzzLocation snaps the displayed location to a grid; nil lets the service decide
+	FuzzLocation *bool
+	// LocationFlags are set by the service from location sanity checks
+	LocationFlags []string
+	// Live adds the story to the author's live session, starting one if needed
+	Live bool
+	// MediaHash is the perceptual hash of an image story, set by the service
+	MediaHash *int64
+	// DuplicateOf is the earlier story whose image this one repeats
+	DuplicateOf *uuid.UUID
 }
 
 type StoryRepository interface {
+	NearbyFeedRepository
 	CreateStory(ctx context.Context, params CreateStoryParams) (*Story, error)
 	GetActiveStories(ctx context.Context, limit, offset int) ([]*Story, error)
 	GetStoriesByLocation(ctx context.Context, lat, lng, radius float64, limit, offset int) ([]*Story, error)
+	GetConnectionsFeed(ctx context.Context, userID uuid.UUID, seen SeenMode, limit, offset int) ([]*Story, error)
+	GetDiscoveryFeed(ctx context.Context, userID uuid.UUID, lat, lng, radius float64, seen SeenMode, limit, offset int) ([]*Story, error)
+	// MarkStoriesSeen records views of the given stories, ignoring ones that no longer exist
+	MarkStoriesSeen(ctx context.Context, viewerID uuid.UUID, storyIDs []uuid.UUID) error
+	GetSeenStoryIDs(ctx context.Context, viewerID uuid.UUID, storyIDs []uuid.UUID) (map[uuid.UUID]bool, error)
+	// RecordStoryImpressions records that the viewer's app rendered the stories,
+	// once per viewer, ignoring their own and ones that no longer exist
+	RecordStoryImpressions(ctx context.Context, viewerID uuid.UUID, storyIDs []uuid.UUID) error
+	// GetStoryReach returns an active story's reach if ownerID posted it
+	GetStoryReach(ctx context.Context, ownerID, storyID uuid.UUID) (*StoryReach, error)
+	// GetLiveStoryIDs returns which of the stories belong to a live session still in progress
+	GetLiveStoryIDs(ctx context.Context, storyIDs []uuid.UUID) (map[uuid.UUID]bool, error)
+	// GetStoryCommentStats returns the visible comment count and whether
+	// commenting is off for each of the stories
+	GetStoryCommentStats(ctx context.Context, storyIDs []uuid.UUID) (map[uuid.UUID]StoryCommentStats, error)
+	// GetViewableStory returns an active story the viewer can see, or ErrStoryNotFound
+	GetViewableStory(ctx context.Context, viewerID, storyID uuid.UUID) (*CommentableStory, error)
+	SaveStory(ctx context.Context, userID, storyID uuid.UUID) error
+	UnsaveStory(ctx context.Context, userID, storyID uuid.UUID) error
+	// GetSavedStories lists saved stories that are active and still visible to the user
+	GetSavedStories(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*Story, error)
 	DeleteExpiredStories(ctx context.Context) (int64, error)
+	// GetLatestStoryLocation returns where and when the user last posted a located story, or nil
+	GetLatestStoryLocation(ctx context.Context, userID uuid.UUID) (*LocationPoint, error)
+	CountStoriesNear(ctx context.Context, userID uuid.UUID, lat, lng, radius float64, since time.Time) (int, error)
+	// FindDuplicateMedia returns the earliest story since the given time whose
+	// media hash is within maxDistance bits of hash, or nil
+	FindDuplicateMedia(ctx context.Context, hash int64, maxDistance int, since time.Time) (*MediaMatch, error)
+	// ArchiveExpiredStories moves expired stories into the archive atomically
+	ArchiveExpiredStories(ctx context.Context) (int64, error)
+	PurgeStoryArchive(ctx context.Context, archivedBefore time.Time) (int64, error)
+	GetArchivedStory(ctx context.Context, storyID uuid.UUID) (*ArchivedStory, error)
+	GetArchivedStoriesByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*ArchivedStory, error)
+}
+
+// ApplyLocationPrivacy replaces fuzzed coordinates with their grid cell center.
+// Call it before returning a story to clients.
+func (s *Story) ApplyLocationPrivacy() {
+	if !s.LocationFuzzed || s.LocationLat == nil || s.LocationLng == nil {
+		return
+	}
+	lat, lng := SnapToGrid(*s.LocationLat, *s.LocationLng, LocationFuzzGridMeters)
+	s.LocationLat, s.LocationLng = &lat, &lng
 }
@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/locolive/backend/pkg/validator"
+)
+
+var (
+	ErrInvalidReaction  = errors.New("reaction must be a single emoji")
+	ErrCannotReactOwn   = errors.New("cannot react to your own story")
+	ErrNotStoryOwner    = errors.New("only the story's author can see its reactions")
+	ErrReactionNotFound = errors.New("reaction not found")
+)
+
+// maxReactionRunes fits the longest emoji sequences, such as families joined
+// with ZWJ and subdivision flags
+const maxReactionRunes = 12
+
+// zwj joins emoji into one, as in family and profession sequences
+const zwj = '\u200d'
+
+// StoryReaction is a viewer's emoji reaction to a story
+type StoryReaction struct {
+	StoryID   uuid.UUID `json:"story_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Emoji     string    `json:"emoji"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// For API responses: who reacted
+	User *UserResponse `json:"user,omitempty"`
+}
+
+type StoryReactionRepository interface {
+	// SetStoryReaction stores the user's reaction to the story, replacing
+	// any earlier one, and fills in its times. It reports false if they had
+	// already reacted with the same emoji.
+	SetStoryReaction(ctx context.Context, reaction *StoryReaction) (bool, error)
+	// DeleteStoryReaction returns ErrReactionNotFound if the user hadn't reacted
+	DeleteStoryReaction(ctx context.Context, storyID, userID uuid.UUID) error
+	// GetStoryReactions lists a story's reactions by active users with
+	// who reacted, latest first
+	GetStoryReactions(ctx context.Context, storyID uuid.UUID, limit, offset int) ([]*StoryReaction, error)
+}
+
+// ValidReaction sanitizes a reaction, which must be one emoji: symbols,
+// optionally joined with ZWJ or followed by variation selectors, skin tone
+// modifiers or flag tags, with no letters, digits or spaces
+func ValidReaction(emoji string) (string, error) {
+	emoji = validator.SanitizeText(emoji)
+	n := utf8.RuneCountInString(emoji)
+	if n == 0 || n > maxReactionRunes {
+		return "", ErrInvalidReaction
+	}
+
+	symbol := false
+	for _, r := range emoji {
+		switch {
+		case unicode.Is(unicode.So, r):
+			symbol = true
+		case r == zwj, unicode.Is(unicode.Variation_Selector, r), unicode.Is(unicode.Sk, r),
+			r >= 0xE0020 && r <= 0xE007F:
+		default:
+			return "", ErrInvalidReaction
+		}
+	}
+	if !symbol {
+		return "", ErrInvalidReaction
+	}
+	return emoji, nil
+}
+
+// React sets the user's reaction to a story they can see, replacing any
+// earlier one. The story's author is notified unless the emoji is the same.
+func (s *CommentService) React(ctx context.Context, userID, storyID uuid.UUID, emoji string) (*StoryReaction, error) {
+	emoji, err := ValidReaction(emoji)
+	if err != nil {
+		return nil, err
+	}
+
+	story, err := s.repo.GetViewableStory(ctx, userID, storyID)
+	if err != nil {
+		return nil, err
+	}
+	if story.UserID == userID {
+		return nil, ErrCannotReactOwn
+	}
+
+	reaction := &StoryReaction{StoryID: storyID, UserID: userID, Emoji: emoji}
+	changed, err := s.repo.SetStoryReaction(ctx, reaction)
+	if err != nil {
+		return nil, err
+	}
+
+	if changed {
+		go s.notifyReaction(story, reaction)
+	}
+	return reaction, nil
+}
+
+// RemoveReaction takes back the user's reaction to a story
+func (s *CommentService) RemoveReaction(ctx context.Context, userID, storyID uuid.UUID) error {
+	return s.repo.DeleteStoryReaction(ctx, storyID, userID)
+}
+
+// GetReactions lists the reactions to one of the user's own stories
+func (s *CommentService) GetReactions(ctx context.Context, userID, storyID uuid.UUID, limit, offset int) ([]*StoryReaction, error) {
+	story, err := s.repo.GetViewableStory(ctx, userID, storyID)
+	if err != nil {
+		return nil, err
+	}
+	if story.UserID != userID {
+		return nil, ErrNotStoryOwner
+	}
+	limit, offset = ReactionPageLimits.Clamp(limit, offset)
+	return s.repo.GetStoryReactions(ctx, storyID, limit, offset)
+}
+
+func (s *CommentService) notifyReaction(story *CommentableStory, reaction *StoryReaction) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	data := map[string]interface{}{
+		"story_id": story.ID.String(),
+		"user_id":  reaction.UserID.String(),
+		"emoji":    reaction.Emoji,
+	}
+	if err := s.notifService.SendNotification(ctx, story.UserID, "story_reaction", "New reaction", "Reacted "+reaction.Emoji+" to your story", data); err != nil {
+		log.Printf("reactions: failed to notify story author %s: %v", story.UserID, err)
+	}
+}
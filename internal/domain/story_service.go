@@ -1,26 +1,105 @@
 package domain
 
 import (
+	"bytes"
 	"context"
 	"io"
+	"log"
+	"math"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/locolive/backend/internal/storage"
 )
 
+const (
+	// A spot with at least homeLikeMinStories posts within homeLikeRadiusMeters
+	// over homeLikeWindow is treated as home-like
+	homeLikeRadiusMeters = 150.0
+	homeLikeMinStories   = 3
+	homeLikeWindow       = 30 * 24 * time.Hour
+
+	// MinFeedRadiusMeters keeps nearby queries coarser than the fuzzing grid so
+	// exact coordinates can't be recovered by probing with tiny radii
+	MinFeedRadiusMeters = 1000.0
+)
+
 type StoryService struct {
-	repo    StoryRepository
-	storage storage.FileStorage
+	repo           StoryRepository
+	connRepo       ConnectionRepository
+	abuseRepo      AbuseRepository
+	placeRepo      PlaceRepository
+	notifService   *NotificationService
+	activity       *ActivityService
+	storage        storage.FileStorage
+	locationPolicy LocationPolicy
+	text           TextPolicy
+	nearby         NearbyFeedPolicy
+	heat           *feedHeat
 }
 
-func NewStoryService(repo StoryRepository, storage storage.FileStorage) *StoryService {
+func NewStoryService(repo StoryRepository, connRepo ConnectionRepository, abuseRepo AbuseRepository, placeRepo PlaceRepository, notifService *NotificationService, activity *ActivityService, storage storage.FileStorage, locationPolicy LocationPolicy, text TextPolicy, nearby NearbyFeedPolicy) *StoryService {
 	return &StoryService{
-		repo:    repo,
-		storage: storage,
+		repo:           repo,
+		connRepo:       connRepo,
+		abuseRepo:      abuseRepo,
+		placeRepo:      placeRepo,
+		notifService:   notifService,
+		activity:       activity,
+		storage:        storage,
+		locationPolicy: locationPolicy,
+		text:           text,
+		nearby:         nearby,
+		heat:           newFeedHeat(),
 	}
 }
 
 func (s *StoryService) CreateStory(ctx context.Context, params CreateStoryParams, file io.Reader, filename, contentType string) (*Story, error) {
+	if params.Caption != nil {
+		caption, err := s.text.Caption(*params.Caption)
+		if err != nil {
+			return nil, err
+		}
+		if caption == "" {
+			params.Caption = nil
+		} else {
+			params.Caption = &caption
+		}
+	}
+
+	if params.LocationLat != nil && params.LocationLng != nil {
+		if err := s.applySavedPlaces(ctx, &params); err != nil {
+			return nil, err
+		}
+	}
+
+	if params.Live && (params.LocationLat == nil || params.LocationLng == nil) {
+		return nil, ErrLiveRequiresLocation
+	}
+
+	if params.LocationLat != nil && params.LocationLng != nil {
+		flags, err := s.checkLocation(ctx, params.UserID, *params.LocationLat, *params.LocationLng)
+		if err != nil {
+			return nil, err
+		}
+		params.LocationFlags = flags
+
+		if params.FuzzLocation == nil {
+			home, err := s.isHomeLike(ctx, params.UserID, *params.LocationLat, *params.LocationLng)
+			if err != nil {
+				return nil, err
+			}
+			params.FuzzLocation = &home
+		}
+	}
+
+	// Keep a copy of images as they upload to hash them afterwards
+	var image *bytes.Buffer
+	if params.MediaType == "image" {
+		image = &bytes.Buffer{}
+		file = io.TeeReader(file, image)
+	}
+
 	// Upload file
 	url, err := s.storage.SaveFile(ctx, file, filename, contentType)
 	if err != nil {
@@ -28,25 +107,317 @@ func (s *StoryService) CreateStory(ctx context.Context, params CreateStoryParams
 	}
 	params.MediaURL = url
 
+	var duplicate *MediaMatch
+	if image != nil {
+		duplicate = s.matchDuplicateMedia(ctx, &params, image)
+	}
+
 	// Set default expiry to 24 hours if not set
 	if params.ExpiresAt.IsZero() {
 		params.ExpiresAt = time.Now().Add(24 * time.Hour)
 	}
-	return s.repo.CreateStory(ctx, params)
+
+	story, err := s.repo.CreateStory(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(params.LocationFlags) > 0 {
+		s.recordLocationSpoof(ctx, story, params.LocationFlags)
+	}
+	if duplicate != nil {
+		s.recordDuplicateMedia(ctx, story, duplicate)
+	}
+
+	go s.fanOutStory(story)
+	story.ApplyLocationPrivacy()
+	return story, nil
 }
 
-func (s *StoryService) GetFeed(ctx context.Context, page, limit int, lat, lng, radius *float64) ([]*Story, error) {
-	if limit <= 0 {
-		limit = 10
+// applySavedPlaces enforces the privacy of any saved place the story was posted
+// from. It overrides the client's requested precision.
+func (s *StoryService) applySavedPlaces(ctx context.Context, params *CreateStoryParams) error {
+	places, err := s.placeRepo.GetPlacesByUser(ctx, params.UserID)
+	if err != nil {
+		return err
+	}
+
+	place := MatchPlace(places, *params.LocationLat, *params.LocationLng)
+	if place == nil {
+		return nil
 	}
-	if page < 1 {
-		page = 1
+	switch place.StoryPrivacy {
+	case PlacePrivacyHide:
+		params.LocationLat, params.LocationLng = nil, nil
+	case PlacePrivacyFuzz:
+		fuzz := true
+		params.FuzzLocation = &fuzz
+	}
+	return nil
+}
+
+// isHomeLike reports whether the user keeps posting from around this spot, which
+// usually means home or work, so the location should default to approximate
+func (s *StoryService) isHomeLike(ctx context.Context, userID uuid.UUID, lat, lng float64) (bool, error) {
+	since := time.Now().Add(-homeLikeWindow)
+	count, err := s.repo.CountStoriesNear(ctx, userID, lat, lng, homeLikeRadiusMeters, since)
+	if err != nil {
+		return false, err
 	}
-	offset := (page - 1) * limit
+	return count >= homeLikeMinStories, nil
+}
 
+// checkLocation runs spoofing checks against the user's previous located story
+func (s *StoryService) checkLocation(ctx context.Context, userID uuid.UUID, lat, lng float64) ([]string, error) {
+	prev, err := s.repo.GetLatestStoryLocation(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.locationPolicy.CheckLocation(prev, LocationPoint{Lat: lat, Lng: lng, At: time.Now()}), nil
+}
+
+// recordLocationSpoof feeds flagged coordinates into the user's abuse score. The
+// story is still published, just down-ranked, so failures here are only logged.
+func (s *StoryService) recordLocationSpoof(ctx context.Context, story *Story, flags []string) {
+	err := s.abuseRepo.RecordAbuseSignal(ctx, AbuseSignal{
+		UserID:    story.UserID,
+		Kind:      AbuseSignalLocationSpoof,
+		Weight:    5 * len(flags),
+		SubjectID: &story.ID,
+		Details: map[string]interface{}{
+			"flags": flags,
+			"lat":   *story.LocationLat,
+			"lng":   *story.LocationLng,
+		},
+	})
+	if err != nil {
+		log.Printf("failed to record location spoof signal: %v", err)
+	}
+}
+
+// fanOutStory notifies the author's connections about a new story
+func (s *StoryService) fanOutStory(story *Story) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	userIDs, err := s.connRepo.GetConnectedUserIDs(ctx, story.UserID)
+	if err != nil {
+		log.Printf("story fan-out: failed to load connections: %v", err)
+		return
+	}
+	if len(userIDs) == 0 {
+		return
+	}
+
+	result, err := s.notifService.SendBulkNotification(ctx, userIDs,
+		"new_story",
+		"New Story",
+		"Someone you're connected with posted a story",
+		map[string]interface{}{
+			"story_id":  story.ID.String(),
+			"author_id": story.UserID.String(),
+		},
+	)
+	if err != nil {
+		log.Printf("story fan-out: %v", err)
+		return
+	}
+	if len(result.Failed) > 0 {
+		log.Printf("story fan-out: %d of %d notifications failed", len(result.Failed), len(userIDs))
+	}
+}
+
+func (s *StoryService) GetFeed(ctx context.Context, viewerID uuid.UUID, page, limit int, lat, lng, radius *float64) ([]*Story, error) {
+	limit, offset := FeedPageLimits.Page(page, limit)
+
+	var stories []*Story
+	var err error
 	if lat != nil && lng != nil && radius != nil {
-		return s.repo.GetStoriesByLocation(ctx, *lat, *lng, *radius, limit, offset)
+		stories, err = s.getNearbyStories(ctx, *lat, *lng, math.Max(*radius, MinFeedRadiusMeters), limit, offset)
+	} else {
+		stories, err = s.repo.GetActiveStories(ctx, limit, offset)
+	}
+	if err != nil {
+		return nil, err
+	}
+	stories, err = s.filterVisible(ctx, viewerID, stories)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.markLive(ctx, stories); err != nil {
+		return nil, err
+	}
+	if err := s.markComments(ctx, stories); err != nil {
+		return nil, err
+	}
+	return applyLocationPrivacy(stories), nil
+}
+
+// filterVisible drops stories by private authors the viewer isn't connected
+// to, checking every author on the page in one lookup
+func (s *StoryService) filterVisible(ctx context.Context, viewerID uuid.UUID, stories []*Story) ([]*Story, error) {
+	var authors []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	for _, story := range stories {
+		if story.User == nil || story.User.Visibility == VisibilityPublic || story.UserID == viewerID || seen[story.UserID] {
+			continue
+		}
+		seen[story.UserID] = true
+		authors = append(authors, story.UserID)
 	}
+	if len(authors) == 0 {
+		return stories, nil
+	}
+
+	connected, err := s.connRepo.GetConnectedAmong(ctx, viewerID, authors)
+	if err != nil {
+		return nil, err
+	}
+	visible := stories[:0]
+	for _, story := range stories {
+		if story.User == nil || story.User.Visibility == VisibilityPublic || story.UserID == viewerID || connected[story.UserID] {
+			visible = append(visible, story)
+		}
+	}
+	return visible, nil
+}
+
+// markLive sets Live on stories whose live session is still in progress
+func (s *StoryService) markLive(ctx context.Context, stories []*Story) error {
+	if len(stories) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(stories))
+	for i, story := range stories {
+		ids[i] = story.ID
+	}
+	live, err := s.repo.GetLiveStoryIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, story := range stories {
+		story.Live = live[story.ID]
+	}
+	return nil
+}
+
+// markComments sets the comment count and whether commenting is off
+func (s *StoryService) markComments(ctx context.Context, stories []*Story) error {
+	if len(stories) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(stories))
+	for i, story := range stories {
+		ids[i] = story.ID
+	}
+	stats, err := s.repo.GetStoryCommentStats(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, story := range stories {
+		story.CommentCount = stats[story.ID].Count
+		story.CommentsDisabled = stats[story.ID].Disabled
+	}
+	return nil
+}
+
+// GetConnectionsFeed returns stories from the user's connections, newest first
+func (s *StoryService) GetConnectionsFeed(ctx context.Context, userID uuid.UUID, seen SeenMode, page, limit int) ([]*Story, error) {
+	limit, offset := FeedPageLimits.Page(page, limit)
+	stories, err := s.repo.GetConnectionsFeed(ctx, userID, seen, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return s.personalize(ctx, userID, stories)
+}
+
+// GetDiscoveryFeed returns nearby stories from public users the viewer isn't connected to
+func (s *StoryService) GetDiscoveryFeed(ctx context.Context, userID uuid.UUID, seen SeenMode, page, limit int, lat, lng, radius float64) ([]*Story, error) {
+	limit, offset := FeedPageLimits.Page(page, limit)
+	stories, err := s.repo.GetDiscoveryFeed(ctx, userID, lat, lng, math.Max(radius, MinFeedRadiusMeters), seen, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return s.personalize(ctx, userID, stories)
+}
+
+// personalize prepares a viewer's feed page: seen flags, comment stats and location privacy
+func (s *StoryService) personalize(ctx context.Context, viewerID uuid.UUID, stories []*Story) ([]*Story, error) {
+	if len(stories) == 0 {
+		return stories, nil
+	}
+
+	ids := make([]uuid.UUID, len(stories))
+	for i, story := range stories {
+		ids[i] = story.ID
+	}
+	seen, err := s.repo.GetSeenStoryIDs(ctx, viewerID, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, story := range stories {
+		story.Seen = seen[story.ID]
+	}
+	if err := s.markComments(ctx, stories); err != nil {
+		return nil, err
+	}
+	return applyLocationPrivacy(stories), nil
+}
+
+// MarkSeen records that the viewer has seen the given stories
+func (s *StoryService) MarkSeen(ctx context.Context, viewerID uuid.UUID, storyIDs []uuid.UUID) error {
+	if len(storyIDs) == 0 {
+		return nil
+	}
+	if len(storyIDs) > MaxSeenBatch {
+		return ErrTooManySeenStories
+	}
+	return s.repo.MarkStoriesSeen(ctx, viewerID, storyIDs)
+}
+
+// RecordImpressions records the stories the viewer's app rendered. Clients
+// batch them; duplicates within and across batches count once.
+func (s *StoryService) RecordImpressions(ctx context.Context, viewerID uuid.UUID, storyIDs []uuid.UUID) error {
+	if len(storyIDs) == 0 {
+		return nil
+	}
+	if len(storyIDs) > MaxImpressionBatch {
+		return ErrTooManyImpressions
+	}
+	return s.repo.RecordStoryImpressions(ctx, viewerID, storyIDs)
+}
+
+// GetReach returns the reach of one of the user's active stories
+func (s *StoryService) GetReach(ctx context.Context, userID, storyID uuid.UUID) (*StoryReach, error) {
+	reach, err := s.repo.GetStoryReach(ctx, userID, storyID)
+	if err != nil {
+		return nil, err
+	}
+	if reach.Impressions > 0 {
+		reach.ViewRate = float64(reach.Views) / float64(reach.Impressions)
+	}
+	return reach, nil
+}
+
+// DefaultFeedLimit is the page size used when a feed request doesn't set one
+const DefaultFeedLimit = 10
+
+func applyLocationPrivacy(stories []*Story) []*Story {
+	for _, story := range stories {
+		story.ApplyLocationPrivacy()
+	}
+	return stories
+}
+
+// GetArchivedStory returns the archived metadata of an expired story
+func (s *StoryService) GetArchivedStory(ctx context.Context, storyID uuid.UUID) (*ArchivedStory, error) {
+	return s.repo.GetArchivedStory(ctx, storyID)
+}
 
-	return s.repo.GetActiveStories(ctx, limit, offset)
+// GetArchivedStories returns a user's archived stories, newest first
+func (s *StoryService) GetArchivedStories(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*ArchivedStory, error) {
+	limit, offset = AdminPageLimits.Clamp(limit, offset)
+	return s.repo.GetArchivedStoriesByUser(ctx, userID, limit, offset)
 }
@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrStoryNotShareable is returned for sharing a story into a chat with
+	// someone who isn't allowed to see it
+	ErrStoryNotShareable = errors.New("someone in this chat can't see this story")
+	ErrCannotReplyOwn    = errors.New("cannot reply to your own story")
+)
+
+const (
+	MessageKindText       = "text"
+	MessageKindStoryShare = "story_share"
+	// MessageKindStoryReply is a reply to a story, sent to its author's 1:1
+	// chat with the SharedStory it replies to
+	MessageKindStoryReply = "story_reply"
+)
+
+// SharedStory is the snapshot of a story shared into a chat, taken when it
+// was shared. Once the story expires or is taken down, Available is false
+// and the media URL is withheld, leaving the author and expiry for a
+// placeholder.
+type SharedStory struct {
+	StoryID   uuid.UUID         `json:"story_id"`
+	MediaURL  string            `json:"media_url,omitempty"`
+	MediaType string            `json:"media_type"`
+	Author    SharedStoryAuthor `json:"author"`
+	ExpiresAt time.Time         `json:"expires_at"`
+	Available bool              `json:"available"`
+}
+
+type SharedStoryAuthor struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Username  string    `json:"username,omitempty"`
+	AvatarURL string    `json:"avatar_url,omitempty"`
+}
+
+// ShareStory posts a story the sender can see into one of their chats, with
+// an optional note. Everyone else in the chat must be able to see the story
+// too, so sharing can't pass a connections-only story to strangers.
+func (s *ChatService) ShareStory(ctx context.Context, chatID, senderID, storyID uuid.UUID, note string) (*Message, error) {
+	// Unlike a plain message, a share may come without any text
+	note, err := sanitizeWithin(note, s.text.MaxMessageLength, ErrMessageTooLong)
+	if err != nil {
+		return nil, err
+	}
+
+	chat, err := s.GetChat(ctx, chatID, senderID)
+	if err != nil {
+		return nil, err
+	}
+	if chat.FrozenAt != nil {
+		return nil, ErrChatFrozen
+	}
+
+	if _, err := s.repo.GetViewableStory(ctx, senderID, storyID); err != nil {
+		return nil, err
+	}
+	for _, u := range chat.Users {
+		if u.ID == senderID {
+			continue
+		}
+		_, err := s.repo.GetViewableStory(ctx, u.ID, storyID)
+		if errors.Is(err, ErrStoryNotFound) {
+			return nil, ErrStoryNotShareable
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	story, err := s.repo.GetStorySnapshot(ctx, storyID)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := s.repo.CreateStoryShare(ctx, chatID, senderID, note, story)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := "Shared a story"
+	if note != "" {
+		preview = note
+	}
+	go s.notifyMessage(chat, msg, preview)
+	return msg, nil
+}
+
+// ReplyToStory replies to a story the user can see with a message in their
+// 1:1 chat with its author, opening the chat if there isn't one yet. The
+// message carries a snapshot of the story, as a share does. It returns the
+// chat too, for telling its members.
+func (s *ChatService) ReplyToStory(ctx context.Context, userID, storyID uuid.UUID, content string) (*Chat, *Message, error) {
+	content, err := s.text.Message(content)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	viewable, err := s.repo.GetViewableStory(ctx, userID, storyID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if viewable.UserID == userID {
+		return nil, nil, ErrCannotReplyOwn
+	}
+
+	chat, err := s.CreateChat(ctx, userID, viewable.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if chat.FrozenAt != nil {
+		return nil, nil, ErrChatFrozen
+	}
+
+	story, err := s.repo.GetStorySnapshot(ctx, storyID)
+	if err != nil {
+		return nil, nil, err
+	}
+	msg, err := s.repo.CreateStoryReply(ctx, chat.ID, userID, content, story)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	go s.notifyMessage(chat, msg, "Replied to your story: "+content)
+	return chat, msg, nil
+}
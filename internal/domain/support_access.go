@@ -0,0 +1,172 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/auth"
+)
+
+var (
+	ErrSupportConsentRequired = errors.New("user has not granted support access")
+	ErrSupportAccessRevoked   = errors.New("support access has been revoked or has expired")
+	ErrSupportReasonRequired  = errors.New("a reason is required to impersonate a user")
+	ErrInvalidSupportDuration = errors.New("invalid support access duration")
+)
+
+// SupportGrant is a user's consent for support agents to view their account
+// read-only until ExpiresAt
+type SupportGrant struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ImpersonationToken is a read-only access token for a support agent
+type ImpersonationToken struct {
+	AccessToken string    `json:"access_token"`
+	UserID      uuid.UUID `json:"user_id"`
+	GrantID     uuid.UUID `json:"grant_id"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type impersonatorKey struct{}
+
+// WithImpersonator returns a context for a request a support agent makes as
+// the user
+func WithImpersonator(ctx context.Context, agentID uuid.UUID) context.Context {
+	return context.WithValue(ctx, impersonatorKey{}, agentID)
+}
+
+// ImpersonatorFromContext returns the support agent acting as the user, if
+// any. What agents do isn't the user's activity and shouldn't be recorded as it.
+func ImpersonatorFromContext(ctx context.Context) (uuid.UUID, bool) {
+	agentID, ok := ctx.Value(impersonatorKey{}).(uuid.UUID)
+	return agentID, ok
+}
+
+type SupportAccessRepository interface {
+	// CreateSupportGrant replaces the user's active grant, if any
+	CreateSupportGrant(ctx context.Context, userID uuid.UUID, expiresAt time.Time) (*SupportGrant, error)
+	// GetActiveSupportGrant returns ErrSupportConsentRequired if the user has none
+	GetActiveSupportGrant(ctx context.Context, userID uuid.UUID) (*SupportGrant, error)
+	RevokeSupportGrants(ctx context.Context, userID uuid.UUID) error
+	IsSupportGrantActive(ctx context.Context, grantID uuid.UUID) (bool, error)
+}
+
+// SupportAccessService lets users consent to support access and support
+// agents impersonate consenting users. Impersonation and every request made
+// with an impersonation token are written to the audit log first; if that
+// fails the call fails.
+type SupportAccessService struct {
+	repo     SupportAccessRepository
+	audit    AuditRepository
+	jwt      *auth.JWTManager
+	maxGrant time.Duration
+	tokenTTL time.Duration
+}
+
+func NewSupportAccessService(repo SupportAccessRepository, audit AuditRepository, jwt *auth.JWTManager, maxGrant, tokenTTL time.Duration) *SupportAccessService {
+	return &SupportAccessService{
+		repo:     repo,
+		audit:    audit,
+		jwt:      jwt,
+		maxGrant: maxGrant,
+		tokenTTL: tokenTTL,
+	}
+}
+
+// GrantAccess gives support access to the user's account for duration, or the
+// maximum when duration is zero
+func (s *SupportAccessService) GrantAccess(ctx context.Context, userID uuid.UUID, duration time.Duration) (*SupportGrant, error) {
+	if duration == 0 {
+		duration = s.maxGrant
+	}
+	if duration < 0 || duration > s.maxGrant {
+		return nil, ErrInvalidSupportDuration
+	}
+	return s.repo.CreateSupportGrant(ctx, userID, time.Now().Add(duration))
+}
+
+// GetAccess returns the user's active grant
+func (s *SupportAccessService) GetAccess(ctx context.Context, userID uuid.UUID) (*SupportGrant, error) {
+	return s.repo.GetActiveSupportGrant(ctx, userID)
+}
+
+// RevokeAccess withdraws consent; outstanding impersonation tokens stop
+// working on their next request
+func (s *SupportAccessService) RevokeAccess(ctx context.Context, userID uuid.UUID) error {
+	return s.repo.RevokeSupportGrants(ctx, userID)
+}
+
+// Impersonate issues a read-only token for agentID to act as userID. The
+// token expires after the token TTL or with the grant, whichever is first.
+func (s *SupportAccessService) Impersonate(ctx context.Context, agentID, userID uuid.UUID, reason string) (*ImpersonationToken, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrSupportReasonRequired
+	}
+
+	grant, err := s.repo.GetActiveSupportGrant(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(s.tokenTTL)
+	if grant.ExpiresAt.Before(expiresAt) {
+		expiresAt = grant.ExpiresAt
+	}
+
+	err = s.audit.RecordAudit(ctx, AuditEntry{
+		ActorID:      agentID,
+		Action:       AuditSupportImpersonate,
+		TargetUserID: &userID,
+		Details: map[string]interface{}{
+			"reason":     reason,
+			"grant_id":   grant.ID.String(),
+			"expires_at": expiresAt,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.jwt.GenerateImpersonationToken(userID, agentID, grant.ID, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &ImpersonationToken{
+		AccessToken: token,
+		UserID:      userID,
+		GrantID:     grant.ID,
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+// CheckImpersonatedRequest allows a request made with an impersonation token
+// while its grant is active, and records it in the audit log
+func (s *SupportAccessService) CheckImpersonatedRequest(ctx context.Context, agentID, userID, grantID uuid.UUID, method, path string) error {
+	active, err := s.repo.IsSupportGrantActive(ctx, grantID)
+	if err != nil {
+		return err
+	}
+	if !active {
+		return ErrSupportAccessRevoked
+	}
+
+	return s.audit.RecordAudit(ctx, AuditEntry{
+		ActorID:      agentID,
+		Action:       AuditSupportRequest,
+		TargetUserID: &userID,
+		Details: map[string]interface{}{
+			"grant_id": grantID.String(),
+			"method":   method,
+			"path":     path,
+		},
+	})
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/locolive/backend/pkg/validator"
+)
+
+var (
+	ErrInvalidName    = errors.New("invalid name")
+	ErrBioTooLong     = errors.New("bio is too long")
+	ErrCaptionTooLong = errors.New("caption is too long")
+	ErrMessageTooLong = errors.New("message is too long")
+	ErrEmptyMessage   = errors.New("message is empty")
+	ErrCommentTooLong = errors.New("comment is too long")
+	ErrEmptyComment   = errors.New("comment is empty")
+)
+
+// minNameLength keeps names from being a lone letter or emoji
+const minNameLength = 2
+
+// TextPolicy caps the length of user-written text, counted in characters
+// after sanitizing. A zero cap means no limit.
+type TextPolicy struct {
+	MaxNameLength    int
+	MaxBioLength     int
+	MaxCaptionLength int
+	MaxMessageLength int
+	MaxCommentLength int
+}
+
+// Name sanitizes a display name, which must have at least two characters
+func (p TextPolicy) Name(name string) (string, error) {
+	name = validator.SanitizeText(name)
+	if n := utf8.RuneCountInString(name); n < minNameLength || overLimit(n, p.MaxNameLength) {
+		return "", fmt.Errorf("%w: must be %d-%d characters", ErrInvalidName, minNameLength, p.MaxNameLength)
+	}
+	return name, nil
+}
+
+// ProviderName sanitizes a name taken from a sign-in provider. Unlike Name it
+// never fails, cutting long names to the limit instead of refusing the sign-in.
+func (p TextPolicy) ProviderName(name string) string {
+	name = validator.SanitizeText(name)
+	if runes := []rune(name); overLimit(len(runes), p.MaxNameLength) {
+		name = strings.TrimSpace(string(runes[:p.MaxNameLength]))
+	}
+	return name
+}
+
+// Bio sanitizes a profile bio; an empty bio clears it
+func (p TextPolicy) Bio(bio string) (string, error) {
+	return sanitizeWithin(bio, p.MaxBioLength, ErrBioTooLong)
+}
+
+// Caption sanitizes a story caption
+func (p TextPolicy) Caption(caption string) (string, error) {
+	return sanitizeWithin(caption, p.MaxCaptionLength, ErrCaptionTooLong)
+}
+
+// Message sanitizes a chat message, which can't be empty
+func (p TextPolicy) Message(content string) (string, error) {
+	content, err := sanitizeWithin(content, p.MaxMessageLength, ErrMessageTooLong)
+	if err == nil && content == "" {
+		return "", ErrEmptyMessage
+	}
+	return content, err
+}
+
+// Comment sanitizes a story comment, which can't be empty
+func (p TextPolicy) Comment(content string) (string, error) {
+	content, err := sanitizeWithin(content, p.MaxCommentLength, ErrCommentTooLong)
+	if err == nil && content == "" {
+		return "", ErrEmptyComment
+	}
+	return content, err
+}
+
+func sanitizeWithin(text string, max int, tooLong error) (string, error) {
+	text = validator.SanitizeMultiline(text)
+	if overLimit(utf8.RuneCountInString(text), max) {
+		return "", fmt.Errorf("%w: at most %d characters", tooLong, max)
+	}
+	return text, nil
+}
+
+func overLimit(n, max int) bool {
+	return max > 0 && n > max
+}
@@ -12,33 +12,50 @@ type User struct {
 	Email         *string    `json:"email,omitempty"`
 	Phone         *string    `json:"phone,omitempty"`
 	Name          string     `json:"name"`
+	Username      *string    `json:"username,omitempty"`
 	AvatarURL     *string    `json:"avatar_url,omitempty"`
 	Bio           *string    `json:"bio,omitempty"`
 	Gender        *string    `json:"gender,omitempty"`
 	DateOfBirth   *time.Time `json:"date_of_birth,omitempty"`
 	Visibility    string     `json:"visibility"`
+	CountryCode   *string    `json:"country_code,omitempty"`
 	GoogleID      *string    `json:"-"`
 	EmailVerified bool       `json:"email_verified"`
 	PhoneVerified bool       `json:"phone_verified"`
 	IsActive      bool       `json:"is_active"`
+	VerifiedAt    *time.Time `json:"verified_at,omitempty"`
 	CreatedAt     time.Time  `json:"created_at"`
 	UpdatedAt     time.Time  `json:"updated_at"`
 }
 
+// Profile visibility. Private profiles show only the basics to users who
+// aren't connected to their owner.
+const (
+	VisibilityPublic  = "public"
+	VisibilityPrivate = "private"
+)
+
 // UserResponse is the public representation of a user
 type UserResponse struct {
 	ID            uuid.UUID `json:"id"`
 	Email         string    `json:"email,omitempty"`
 	Phone         string    `json:"phone,omitempty"`
 	Name          string    `json:"name"`
+	Username      string    `json:"username,omitempty"`
 	AvatarURL     string    `json:"avatar_url,omitempty"`
 	Bio           string    `json:"bio,omitempty"`
 	Gender        string    `json:"gender,omitempty"`
 	DateOfBirth   string    `json:"date_of_birth,omitempty"`
 	Visibility    string    `json:"visibility,omitempty"`
+	CountryCode   string    `json:"country_code,omitempty"`
 	EmailVerified bool      `json:"email_verified"`
 	PhoneVerified bool      `json:"phone_verified"`
-	CreatedAt     time.Time `json:"created_at"`
+	// Verified marks a public figure whose identity an admin has confirmed
+	Verified  bool      `json:"verified"`
+	CreatedAt time.Time `json:"created_at"`
+	// Limited is set when a private profile is shown to someone who isn't
+	// connected to its owner
+	Limited bool `json:"limited,omitempty"`
 }
 
 // ToResponse converts a User to a UserResponse
@@ -49,6 +66,7 @@ func (u *User) ToResponse() *UserResponse {
 		Visibility:    u.Visibility,
 		EmailVerified: u.EmailVerified,
 		PhoneVerified: u.PhoneVerified,
+		Verified:      u.VerifiedAt != nil,
 		CreatedAt:     u.CreatedAt,
 	}
 
@@ -58,6 +76,9 @@ func (u *User) ToResponse() *UserResponse {
 	if u.Phone != nil {
 		response.Phone = *u.Phone
 	}
+	if u.Username != nil {
+		response.Username = *u.Username
+	}
 	if u.AvatarURL != nil {
 		response.AvatarURL = *u.AvatarURL
 	}
@@ -67,6 +88,9 @@ func (u *User) ToResponse() *UserResponse {
 	if u.Gender != nil {
 		response.Gender = *u.Gender
 	}
+	if u.CountryCode != nil {
+		response.CountryCode = *u.CountryCode
+	}
 	if u.DateOfBirth != nil {
 		response.DateOfBirth = u.DateOfBirth.Format("2006-01-02")
 	}
@@ -74,6 +98,44 @@ func (u *User) ToResponse() *UserResponse {
 	return response
 }
 
+// ToLimitedResponse returns only the fields a private profile shows to users
+// who aren't connected to its owner
+func (u *User) ToLimitedResponse() *UserResponse {
+	response := &UserResponse{
+		ID:         u.ID,
+		Name:       u.Name,
+		Visibility: u.Visibility,
+		Verified:   u.VerifiedAt != nil,
+		CreatedAt:  u.CreatedAt,
+		Limited:    true,
+	}
+	if u.Username != nil {
+		response.Username = *u.Username
+	}
+	if u.AvatarURL != nil {
+		response.AvatarURL = *u.AvatarURL
+	}
+	return response
+}
+
+// ToCardResponse returns what is shown next to something the user wrote:
+// who they are, without any of their profile
+func (u *User) ToCardResponse() *UserResponse {
+	response := &UserResponse{
+		ID:        u.ID,
+		Name:      u.Name,
+		Verified:  u.VerifiedAt != nil,
+		CreatedAt: u.CreatedAt,
+	}
+	if u.Username != nil {
+		response.Username = *u.Username
+	}
+	if u.AvatarURL != nil {
+		response.AvatarURL = *u.AvatarURL
+	}
+	return response
+}
+
 // Session represents a user session
 type Session struct {
 	ID             uuid.UUID `json:"id"`
@@ -81,6 +143,9 @@ type Session struct {
 	DeviceInfo     *string   `json:"device_info,omitempty"`
 	IPAddress      *string   `json:"ip_address,omitempty"`
 	UserAgent      *string   `json:"user_agent,omitempty"`
+	Region         *string   `json:"region,omitempty"`
+	Platform       *string   `json:"platform,omitempty"`
+	AppVersion     *string   `json:"app_version,omitempty"`
 	FCMToken       *string   `json:"fcm_token,omitempty"`
 	IsActive       bool      `json:"is_active"`
 	CreatedAt      time.Time `json:"created_at"`
@@ -88,11 +153,64 @@ type Session struct {
 	LastActivityAt time.Time `json:"last_activity_at"`
 }
 
+// RegionCode returns the country the session signed in from, or "" if unknown
+func (s *Session) RegionCode() string {
+	if s.Region == nil {
+		return ""
+	}
+	return *s.Region
+}
+
+// SessionResponse is a session as shown to its owner
+type SessionResponse struct {
+	ID             uuid.UUID `json:"id"`
+	DeviceInfo     string    `json:"device_info,omitempty"`
+	IPAddress      string    `json:"ip_address,omitempty"`
+	UserAgent      string    `json:"user_agent,omitempty"`
+	Region         string    `json:"region,omitempty"`
+	Platform       string    `json:"platform,omitempty"`
+	AppVersion     string    `json:"app_version,omitempty"`
+	Current        bool      `json:"current"`
+	CreatedAt      time.Time `json:"created_at"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+// ToResponse converts a Session to a SessionResponse
+func (s *Session) ToResponse(currentSessionID uuid.UUID) *SessionResponse {
+	response := &SessionResponse{
+		ID:             s.ID,
+		Region:         s.RegionCode(),
+		Current:        s.ID == currentSessionID,
+		CreatedAt:      s.CreatedAt,
+		LastActivityAt: s.LastActivityAt,
+		ExpiresAt:      s.ExpiresAt,
+	}
+	if s.DeviceInfo != nil {
+		response.DeviceInfo = *s.DeviceInfo
+	}
+	if s.Platform != nil {
+		response.Platform = *s.Platform
+	}
+	if s.AppVersion != nil {
+		response.AppVersion = *s.AppVersion
+	}
+	if s.IPAddress != nil {
+		response.IPAddress = *s.IPAddress
+	}
+	if s.UserAgent != nil {
+		response.UserAgent = *s.UserAgent
+	}
+	return response
+}
+
 // RefreshToken represents a stored refresh token
 type RefreshToken struct {
 	ID        uuid.UUID  `json:"id"`
 	UserID    uuid.UUID  `json:"user_id"`
 	SessionID *uuid.UUID `json:"session_id,omitempty"`
+	// FamilyID is shared by a token and every token rotated from it
+	FamilyID  uuid.UUID  `json:"family_id"`
 	TokenHash string     `json:"-"`
 	ExpiresAt time.Time  `json:"expires_at"`
 	Revoked   bool       `json:"revoked"`
@@ -109,3 +227,25 @@ type PasswordResetToken struct {
 	Used      bool      `json:"used"`
 	CreatedAt time.Time `json:"created_at"`
 }
+
+// EmailVerificationToken proves ownership of the email address it was sent to
+type EmailVerificationToken struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	Email     string     `json:"email"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// PhoneOTP is a one-time code sent by SMS to prove ownership of a phone number
+type PhoneOTP struct {
+	ID         uuid.UUID  `json:"id"`
+	Phone      string     `json:"phone"`
+	CodeHash   string     `json:"-"`
+	Attempts   int        `json:"attempts"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	ConsumedAt *time.Time `json:"consumed_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// User search query bounds, in characters
+const (
+	MinUserSearchLength = 2
+	MaxUserSearchLength = 64
+)
+
+// User search page sizes
+const (
+	DefaultUserSearchLimit = 20
+	MaxUserSearchLimit     = 50
+)
+
+var ErrInvalidSearchQuery = errors.New("q must be 2-64 characters")
+
+// UserSearchParams filters a user search. Results exclude the viewer, users
+// either side has blocked, and non-public users the viewer isn't connected to.
+type UserSearchParams struct {
+	ViewerID    uuid.UUID
+	Query       string
+	CountryCode string
+	Limit       int
+	Offset      int
+}
+
+// UserSearchResult is the public part of a profile shown in search results
+type UserSearchResult struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Username  string    `json:"username,omitempty"`
+	AvatarURL string    `json:"avatar_url,omitempty"`
+	Bio       string    `json:"bio,omitempty"`
+	Verified  bool      `json:"verified"`
+}
+
+// SearchUsers finds users by name, username or bio, best matches first. A
+// leading @ searches handles the way the profile lookup accepts them.
+func (s *AuthService) SearchUsers(ctx context.Context, params UserSearchParams) ([]*UserSearchResult, error) {
+	params.Query = strings.TrimPrefix(strings.TrimSpace(params.Query), "@")
+	if n := utf8.RuneCountInString(params.Query); n < MinUserSearchLength || n > MaxUserSearchLength {
+		return nil, ErrInvalidSearchQuery
+	}
+	if params.CountryCode != "" {
+		code, ok := NormalizeCountryCode(params.CountryCode)
+		if !ok {
+			return nil, ErrInvalidCountryCode
+		}
+		params.CountryCode = code
+	}
+	params.Limit, params.Offset = UserSearchPageLimits.Clamp(params.Limit, params.Offset)
+
+	users, err := s.repo.SearchUsers(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*UserSearchResult, len(users))
+	for i, user := range users {
+		result := &UserSearchResult{ID: user.ID, Name: user.Name, Verified: user.VerifiedAt != nil}
+		if user.Username != nil {
+			result.Username = *user.Username
+		}
+		if user.AvatarURL != nil {
+			result.AvatarURL = *user.AvatarURL
+		}
+		if user.Bio != nil {
+			result.Bio = *user.Bio
+		}
+		results[i] = result
+	}
+	return results, nil
+}
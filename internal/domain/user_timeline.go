@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// What a timeline entry records
+const (
+	TimelineSecurity       = "security"
+	TimelineLoginFailed    = "login_failed"
+	TimelineStory          = "story"
+	TimelineMessage        = "message"
+	TimelineComment        = "comment"
+	TimelineReportFiled    = "report_filed"
+	TimelineReportReceived = "report_received"
+	TimelineTakedown       = "takedown"
+	TimelineStrike         = "strike"
+	TimelineAdminAction    = "admin_action"
+)
+
+// timelineAdminActions are the audit log actions that change an account,
+// shown on its timeline; views of it are left out
+var timelineAdminActions = []AuditAction{
+	AuditUserDeactivate, AuditUserReactivate, AuditUserRoleChange, AuditAccountMerge,
+	AuditLegalHoldPlace, AuditLegalHoldRelease, AuditPIIRectify, AuditPIIPseudonymize,
+	AuditVerificationApprove, AuditVerificationRevoke,
+}
+
+// TimelineEntry is one thing that happened on an account, for operators
+// investigating abuse. ID is the row it comes from, such as the story or the
+// report. Messages carry their chat and kind, never their content.
+type TimelineEntry struct {
+	Type    string                 `json:"type"`
+	ID      uuid.UUID              `json:"id"`
+	At      time.Time              `json:"at"`
+	Details map[string]interface{} `json:"details"`
+}
+
+type UserTimelineRepository interface {
+	// GetUserTimeline lists what the user did and what was done to their
+	// account, newest first. adminActions picks the audit log entries shown.
+	GetUserTimeline(ctx context.Context, userID uuid.UUID, adminActions []AuditAction, limit, offset int) ([]*TimelineEntry, error)
+}
+
+// GetUserTimeline returns a user's sign-ins, posts, messages, reports and
+// enforcement history in one list, newest first, for an admin, audited
+func (s *AdminService) GetUserTimeline(ctx context.Context, adminID, userID uuid.UUID, limit, offset int) ([]*TimelineEntry, error) {
+	limit, offset = AdminPageLimits.Clamp(limit, offset)
+	if _, _, err := s.repo.GetUserForAdmin(ctx, userID); err != nil {
+		return nil, err
+	}
+	if err := s.record(ctx, adminID, AuditUserTimelineView, &userID, map[string]interface{}{"limit": limit, "offset": offset}); err != nil {
+		return nil, err
+	}
+	return s.repo.GetUserTimeline(ctx, userID, timelineAdminActions, limit, offset)
+}
@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidUsername  = errors.New("username must be 3-30 letters, digits, underscores or periods, and not start or end with a period")
+	ErrReservedUsername = errors.New("username is reserved")
+)
+
+var usernameRegex = regexp.MustCompile(`^[a-z0-9_](?:[a-z0-9_.]{1,28})[a-z0-9_]$`)
+
+// reservedUsernames can't be claimed, as they'd pass for the app or staff
+var reservedUsernames = map[string]bool{
+	"admin": true, "administrator": true, "api": true, "help": true, "locolive": true,
+	"me": true, "moderator": true, "official": true, "root": true, "staff": true,
+	"support": true, "system": true,
+}
+
+// NormalizeUsername lowercases a handle, dropping a leading @, and validates it
+func NormalizeUsername(username string) (string, error) {
+	username = cleanUsername(username)
+	if !usernameRegex.MatchString(username) || strings.Contains(username, "..") {
+		return "", ErrInvalidUsername
+	}
+	if reservedUsernames[username] {
+		return "", ErrReservedUsername
+	}
+	return username, nil
+}
+
+func cleanUsername(username string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(username), "@"))
+}
+
+// UsernameAvailability is the result of a username check
+type UsernameAvailability struct {
+	Username  string `json:"username"`
+	Available bool   `json:"available"`
+}
+
+// CheckUsername reports whether a handle is free. The caller's own handle
+// counts as available, so the profile editor can check it unchanged.
+func (s *AuthService) CheckUsername(ctx context.Context, userID uuid.UUID, username string) (*UsernameAvailability, error) {
+	normalized, err := NormalizeUsername(username)
+	if errors.Is(err, ErrReservedUsername) {
+		return &UsernameAvailability{Username: cleanUsername(username)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	taken, err := s.repo.UsernameExists(ctx, normalized)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		user, err := s.repo.GetUserByID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		taken = user.Username == nil || *user.Username != normalized
+	}
+	return &UsernameAvailability{Username: normalized, Available: !taken}, nil
+}
+
+// GetUserByUsername looks up a profile by handle, with or without the @, as
+// viewerID may see it
+func (s *AuthService) GetUserByUsername(ctx context.Context, viewerID uuid.UUID, username string) (*UserResponse, error) {
+	normalized, err := NormalizeUsername(username)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	user, err := s.repo.GetUserByUsername(ctx, normalized)
+	if err != nil {
+		return nil, err
+	}
+	return s.profileFor(ctx, viewerID, user)
+}
+
+// PublicProfile is what anyone, signed in or not, can see of a public
+// profile, for link previews and the web profile page
+type PublicProfile struct {
+	Name     string `json:"name"`
+	Username string `json:"username"`
+	// AvatarURL is where the avatar can be fetched without signing in, set
+	// by the API; Avatar is the stored URL, which needs signing in
+	AvatarURL string  `json:"avatar_url,omitempty"`
+	Avatar    *string `json:"-"`
+	Verified  bool    `json:"verified"`
+}
+
+// GetPublicProfile looks up a profile by handle for someone who may not be
+// signed in. Private profiles are ErrUserNotFound, so their handles can't be
+// confirmed without an account.
+func (s *AuthService) GetPublicProfile(ctx context.Context, username string) (*PublicProfile, error) {
+	normalized, err := NormalizeUsername(username)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	user, err := s.repo.GetUserByUsername(ctx, normalized)
+	if err != nil {
+		return nil, err
+	}
+	if user.Visibility != VisibilityPublic {
+		return nil, ErrUserNotFound
+	}
+	return &PublicProfile{
+		Name:     user.Name,
+		Username: normalized,
+		Avatar:   user.AvatarURL,
+		Verified: user.VerifiedAt != nil,
+	}, nil
+}
@@ -0,0 +1,153 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidVerificationRequest  = errors.New("category must be one of the listed ones and details 1-1000 characters")
+	ErrVerificationRequestPending  = errors.New("a verification request is already pending")
+	ErrVerificationRequestNotFound = errors.New("verification request not found")
+	ErrVerificationRequestResolved = errors.New("verification request already reviewed")
+	ErrAlreadyVerified             = errors.New("account is already verified")
+	ErrNotVerified                 = errors.New("account is not verified")
+	// ErrVerifiedImpersonation is returned for impersonation reports against a
+	// verified account; the badge is the proof it is who it says it is
+	ErrVerifiedImpersonation = errors.New("verified accounts can't be reported for impersonation")
+)
+
+type VerificationStatus string
+
+const (
+	VerificationPending  VerificationStatus = "pending"
+	VerificationApproved VerificationStatus = "approved"
+	VerificationRejected VerificationStatus = "rejected"
+)
+
+// VerificationCategories are the kinds of public figure we verify
+var VerificationCategories = map[string]bool{
+	"creator":    true,
+	"journalist": true,
+	"business":   true,
+	"government": true,
+	"athlete":    true,
+	"other":      true,
+}
+
+// VerificationRequest is a user's application for the verified badge
+type VerificationRequest struct {
+	ID         uuid.UUID          `json:"id"`
+	UserID     uuid.UUID          `json:"user_id"`
+	Category   string             `json:"category"`
+	Details    string             `json:"details"`
+	Status     VerificationStatus `json:"status"`
+	ReviewNote *string            `json:"review_note,omitempty"`
+	ReviewedBy *uuid.UUID         `json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time         `json:"reviewed_at,omitempty"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+type VerificationRepository interface {
+	// CreateVerificationRequest returns ErrVerificationRequestPending if the
+	// user already has a request awaiting review
+	CreateVerificationRequest(ctx context.Context, req *VerificationRequest) error
+	// GetLatestVerificationRequest returns the user's most recent request
+	GetLatestVerificationRequest(ctx context.Context, userID uuid.UUID) (*VerificationRequest, error)
+	GetVerificationRequests(ctx context.Context, status VerificationStatus, limit, offset int) ([]*VerificationRequest, error)
+	// ResolveVerificationRequest approves or rejects a pending request, setting
+	// the user's badge on approval. The audit entry is recorded in the same transaction.
+	ResolveVerificationRequest(ctx context.Context, requestID, reviewerID uuid.UUID, status VerificationStatus, note *string, audit AuditEntry) (*VerificationRequest, error)
+	// RevokeVerification removes a user's badge, or returns ErrNotVerified
+	RevokeVerification(ctx context.Context, userID uuid.UUID, audit AuditEntry) error
+	IsUserVerified(ctx context.Context, userID uuid.UUID) (bool, error)
+}
+
+// RequestVerification applies for the verified badge. One request may be
+// pending at a time; a rejected user can apply again.
+func (s *AuthService) RequestVerification(ctx context.Context, userID uuid.UUID, category, details string) (*VerificationRequest, error) {
+	category, details = strings.ToLower(strings.TrimSpace(category)), strings.TrimSpace(details)
+	if !VerificationCategories[category] || details == "" || utf8.RuneCountInString(details) > 1000 {
+		return nil, ErrInvalidVerificationRequest
+	}
+
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.VerifiedAt != nil {
+		return nil, ErrAlreadyVerified
+	}
+
+	req := &VerificationRequest{UserID: userID, Category: category, Details: details}
+	if err := s.repo.CreateVerificationRequest(ctx, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// GetVerificationRequest returns the user's latest verification request
+func (s *AuthService) GetVerificationRequest(ctx context.Context, userID uuid.UUID) (*VerificationRequest, error) {
+	return s.repo.GetLatestVerificationRequest(ctx, userID)
+}
+
+// GetVerificationRequests lists requests with the given status, oldest first
+func (s *AdminService) GetVerificationRequests(ctx context.Context, status VerificationStatus, limit, offset int) ([]*VerificationRequest, error) {
+	limit, offset = AdminPageLimits.Clamp(limit, offset)
+	return s.repo.GetVerificationRequests(ctx, status, limit, offset)
+}
+
+// ResolveVerification approves or rejects a pending request and tells the applicant
+func (s *AdminService) ResolveVerification(ctx context.Context, adminID, requestID uuid.UUID, approve bool, note string) (*VerificationRequest, error) {
+	status, action := VerificationRejected, AuditVerificationReject
+	if approve {
+		status, action = VerificationApproved, AuditVerificationApprove
+	}
+	var reviewNote *string
+	if note = strings.TrimSpace(note); note != "" {
+		reviewNote = &note
+	}
+
+	req, err := s.repo.ResolveVerificationRequest(ctx, requestID, adminID, status, reviewNote, AuditEntry{
+		ActorID: adminID,
+		Action:  action,
+		Details: map[string]interface{}{"request_id": requestID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	go s.notifyVerification(req.UserID, status)
+	return req, nil
+}
+
+// RevokeVerification takes the badge away from a user
+func (s *AdminService) RevokeVerification(ctx context.Context, adminID, userID uuid.UUID, reason string) error {
+	return s.repo.RevokeVerification(ctx, userID, AuditEntry{
+		ActorID:      adminID,
+		Action:       AuditVerificationRevoke,
+		TargetUserID: &userID,
+		Details:      map[string]interface{}{"reason": reason},
+	})
+}
+
+func (s *AdminService) notifyVerification(userID uuid.UUID, status VerificationStatus) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	title, body := "Verification approved", "Your account is now verified and shows the verified badge."
+	if status == VerificationRejected {
+		title, body = "Verification not approved", "We couldn't verify your account this time. You can apply again with more details."
+	}
+	err := s.notifService.SendNotification(ctx, userID, "verification", title, body, map[string]interface{}{
+		"status": string(status),
+	})
+	if err != nil {
+		log.Printf("verification: failed to notify %s: %v", userID, err)
+	}
+}
@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/locolive/backend/pkg/audio"
+)
+
+var (
+	ErrInvalidVoiceNote  = errors.New("voice notes must be m4a or ogg audio")
+	ErrVoiceNoteTooLarge = errors.New("voice note is too large")
+	ErrVoiceNoteTooLong  = errors.New("voice note is too long")
+)
+
+const MessageKindVoice = "voice"
+
+// MessageMedia is the audio of a voice note. MediaType is its content type,
+// audio/mp4 or audio/ogg, for clients to pick a player; the duration is
+// read from the file, not taken from the client.
+type MessageMedia struct {
+	URL        string `json:"url"`
+	MediaType  string `json:"media_type"`
+	DurationMs int    `json:"duration_ms"`
+	SizeBytes  int64  `json:"size_bytes"`
+}
+
+// VoiceNotePolicy caps voice note uploads
+type VoiceNotePolicy struct {
+	MaxBytes    int64
+	MaxDuration time.Duration
+}
+
+// VoiceNotePolicy returns the caps on voice notes
+func (s *ChatService) VoiceNotePolicy() VoiceNotePolicy {
+	return s.voice
+}
+
+// SendVoiceNote stores an m4a or ogg upload of size bytes and posts it into
+// the chat as a voice message
+func (s *ChatService) SendVoiceNote(ctx context.Context, chatID, senderID uuid.UUID, file io.ReaderAt, size int64) (*Message, error) {
+	if size > s.voice.MaxBytes {
+		return nil, fmt.Errorf("%w: the limit is %d bytes", ErrVoiceNoteTooLarge, s.voice.MaxBytes)
+	}
+
+	chat, err := s.GetChat(ctx, chatID, senderID)
+	if err != nil {
+		return nil, err
+	}
+	if chat.FrozenAt != nil {
+		return nil, ErrChatFrozen
+	}
+
+	info, err := audio.Probe(file, size)
+	if err != nil {
+		return nil, ErrInvalidVoiceNote
+	}
+	if info.Duration > s.voice.MaxDuration {
+		return nil, fmt.Errorf("%w: the limit is %s", ErrVoiceNoteTooLong, s.voice.MaxDuration)
+	}
+
+	url, err := s.storage.SaveFile(ctx, io.NewSectionReader(file, 0, size), "voice."+info.Format, info.ContentType())
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := s.repo.CreateVoiceNote(ctx, chatID, senderID, &MessageMedia{
+		URL:        url,
+		MediaType:  info.ContentType(),
+		DurationMs: int(info.Duration.Milliseconds()),
+		SizeBytes:  size,
+	})
+	if err != nil {
+		if err := s.storage.DeleteFile(ctx, url); err != nil {
+			log.Printf("chat: failed to delete voice note %s: %v", url, err)
+		}
+		return nil, err
+	}
+
+	go s.notifyMessage(chat, msg, "Sent a voice message")
+	return msg, nil
+}
@@ -0,0 +1,179 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrCannotWaveSelf      = errors.New("cannot wave at self")
+	ErrWaveTargetNotFound  = errors.New("wave target user not found")
+	ErrWaveTargetNotNearby = errors.New("user is not nearby")
+	ErrInvalidWaveLocation = errors.New("lat and lng must be valid coordinates")
+	// ErrWaveTooSoon is returned for a second wave at the same user on one UTC day
+	ErrWaveTooSoon = errors.New("already waved at this user today")
+)
+
+const (
+	// WaveRadiusMeters is how close to the sender the target must have posted a
+	// story in the last day to count as nearby
+	WaveRadiusMeters = 10000
+	// WaveReplyWindow is how long a wave can be answered by waving back
+	WaveReplyWindow = 7 * 24 * time.Hour
+	// waveNearbyWindow is how recent the target's story near the sender must be
+	waveNearbyWindow = 24 * time.Hour
+)
+
+// Wave is a lightweight hello from one user to another nearby
+type Wave struct {
+	ID         uuid.UUID `json:"id"`
+	SenderID   uuid.UUID `json:"sender_id"`
+	ReceiverID uuid.UUID `json:"receiver_id"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	// For API responses: the sender of a received wave
+	User *UserResponse `json:"user,omitempty"`
+}
+
+// WaveResult is a sent wave. When the receiver had already waved back within
+// WaveReplyWindow it is mutual, and carries the connection and chat it opened.
+type WaveResult struct {
+	Wave       *Wave       `json:"wave"`
+	Mutual     bool        `json:"mutual"`
+	Connection *Connection `json:"connection,omitempty"`
+	Chat       *Chat       `json:"chat,omitempty"`
+}
+
+type WaveRepository interface {
+	// CreateWave returns ErrWaveTooSoon if the sender already waved at the
+	// receiver today (UTC)
+	CreateWave(ctx context.Context, senderID, receiverID uuid.UUID) (*Wave, error)
+	// HasWaved reports whether senderID waved at receiverID since the given time
+	HasWaved(ctx context.Context, senderID, receiverID uuid.UUID, since time.Time) (bool, error)
+	// GetReceivedWaves lists waves to the user since the given time, newest
+	// first, with each sender's public profile
+	GetReceivedWaves(ctx context.Context, userID uuid.UUID, since time.Time, limit, offset int) ([]*Wave, error)
+}
+
+type WaveService struct {
+	repo         WaveRepository
+	storyRepo    StoryRepository
+	userRepo     AuthRepository
+	connService  *ConnectionService
+	chatService  *ChatService
+	notifService *NotificationService
+}
+
+func NewWaveService(repo WaveRepository, storyRepo StoryRepository, userRepo AuthRepository, connService *ConnectionService, chatService *ChatService, notifService *NotificationService) *WaveService {
+	return &WaveService{
+		repo:         repo,
+		storyRepo:    storyRepo,
+		userRepo:     userRepo,
+		connService:  connService,
+		chatService:  chatService,
+		notifService: notifService,
+	}
+}
+
+// Wave sends a wave from the sender, at lat/lng, to a target who posted a story
+// near there in the last day. Waving back at someone who waved within
+// WaveReplyWindow connects the two and opens a chat between them.
+func (s *WaveService) Wave(ctx context.Context, senderID, targetID uuid.UUID, lat, lng float64) (*WaveResult, error) {
+	if senderID == targetID {
+		return nil, ErrCannotWaveSelf
+	}
+	if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return nil, ErrInvalidWaveLocation
+	}
+
+	// GetUserByID only returns active users
+	if _, err := s.userRepo.GetUserByID(ctx, targetID); err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, ErrWaveTargetNotFound
+		}
+		return nil, err
+	}
+
+	state, err := s.connService.GetState(ctx, senderID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	switch state {
+	case ConnectionStateConnected:
+		return nil, ErrAlreadyConnected
+	case ConnectionStateBlocked:
+		return nil, ErrConnectionBlocked
+	}
+
+	// Nearby is judged from stories the target chose to share, so a wave
+	// reveals nothing about where they are that the feed doesn't
+	now := time.Now()
+	count, err := s.storyRepo.CountStoriesNear(ctx, targetID, lat, lng, WaveRadiusMeters, now.Add(-waveNearbyWindow))
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrWaveTargetNotNearby
+	}
+
+	wave, err := s.repo.CreateWave(ctx, senderID, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	waved, err := s.repo.HasWaved(ctx, targetID, senderID, now.Add(-WaveReplyWindow))
+	if err != nil {
+		return nil, err
+	}
+	if !waved {
+		go s.notifyWave(targetID, senderID)
+		return &WaveResult{Wave: wave}, nil
+	}
+
+	conn, err := s.connService.ConnectMutual(ctx, targetID, senderID)
+	if err != nil {
+		return nil, err
+	}
+	chat, err := s.chatService.CreateChat(ctx, senderID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	go s.notifyMatch(targetID, senderID, chat.ID)
+	go s.notifyMatch(senderID, targetID, chat.ID)
+	return &WaveResult{Wave: wave, Mutual: true, Connection: conn, Chat: chat}, nil
+}
+
+// GetReceivedWaves lists the waves the user can still answer, newest first
+func (s *WaveService) GetReceivedWaves(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*Wave, error) {
+	limit, offset = WavePageLimits.Clamp(limit, offset)
+	return s.repo.GetReceivedWaves(ctx, userID, time.Now().Add(-WaveReplyWindow), limit, offset)
+}
+
+func (s *WaveService) notifyWave(receiverID, senderID uuid.UUID) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	err := s.notifService.SendNotification(ctx, receiverID, "wave", "Someone waved at you", "Wave back to connect and start chatting.", map[string]interface{}{
+		"sender_id": senderID.String(),
+	})
+	if err != nil {
+		log.Printf("waves: failed to notify %s: %v", receiverID, err)
+	}
+}
+
+func (s *WaveService) notifyMatch(receiverID, otherID, chatID uuid.UUID) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	err := s.notifService.SendNotification(ctx, receiverID, "wave_match", "You waved at each other", "You're now connected. Say hello!", map[string]interface{}{
+		"user_id": otherID.String(),
+		"chat_id": chatID.String(),
+	})
+	if err != nil {
+		log.Printf("waves: failed to notify %s: %v", receiverID, err)
+	}
+}
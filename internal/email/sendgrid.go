@@ -0,0 +1,79 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// SendGridSender sends plain-text email through the SendGrid v3 Mail Send API
+type SendGridSender struct {
+	apiKey string
+	from   *mail.Address
+	client *http.Client
+}
+
+// NewSendGridSender creates a sender. from may include a display name, e.g.
+// "LocoLive <no-reply@locolive.app>".
+func NewSendGridSender(apiKey, from string) (*SendGridSender, error) {
+	addr, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address %q: %w", from, err)
+	}
+	return &SendGridSender{
+		apiKey: apiKey,
+		from:   addr,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// SendEmail delivers a single message
+func (s *SendGridSender) SendEmail(ctx context.Context, to, subject, body string) error {
+	type address struct {
+		Email string `json:"email"`
+		Name  string `json:"name,omitempty"`
+	}
+	type content struct {
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	}
+	payload, err := json.Marshal(map[string]any{
+		"personalizations": []map[string]any{{"to": []address{{Email: to}}}},
+		"from":             address{Email: s.from.Address, Name: s.from.Name},
+		"subject":          subject,
+		"content":          []content{{Type: "text/plain", Value: body}},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.sendgrid.com/v3/mail/send", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	return doRequest(s.client, req, "sendgrid")
+}
+
+// doRequest sends req and turns a non-2xx response into an error
+func doRequest(client *http.Client, req *http.Request, provider string) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned %d: %s", provider, resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+)
+
+// SESSender sends plain-text email through the Amazon SES v2 API. Credentials
+// come from the default AWS chain (environment, shared config or an IAM role).
+type SESSender struct {
+	region      string
+	from        string
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+	client      *http.Client
+}
+
+// NewSESSender creates a sender for the given region. from must be a verified
+// SES identity.
+func NewSESSender(ctx context.Context, region, from string) (*SESSender, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("unable to load AWS config: %w", err)
+	}
+	return &SESSender{
+		region:      region,
+		from:        from,
+		credentials: cfg.Credentials,
+		signer:      v4.NewSigner(),
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// SendEmail delivers a single message
+func (s *SESSender) SendEmail(ctx context.Context, to, subject, body string) error {
+	type text struct {
+		Data    string `json:"Data"`
+		Charset string `json:"Charset"`
+	}
+	payload, err := json.Marshal(map[string]any{
+		"FromEmailAddress": s.from,
+		"Destination":      map[string]any{"ToAddresses": []string{to}},
+		"Content": map[string]any{
+			"Simple": map[string]any{
+				"Subject": text{Data: subject, Charset: "UTF-8"},
+				"Body":    map[string]any{"Text": text{Data: body, Charset: "UTF-8"}},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("https://email.%s.amazonaws.com/v2/email/outbound-emails", s.region)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	creds, err := s.credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("retrieving AWS credentials: %w", err)
+	}
+	hash := sha256.Sum256(payload)
+	if err := s.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(hash[:]), "ses", s.region, time.Now()); err != nil {
+		return fmt.Errorf("signing SES request: %w", err)
+	}
+
+	return doRequest(s.client, req, "ses")
+}
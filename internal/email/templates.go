@@ -0,0 +1,64 @@
+package email
+
+import (
+	"bytes"
+	"strconv"
+	"text/template"
+	"time"
+)
+
+// Message is a rendered email
+type Message struct {
+	Subject string
+	Body    string
+}
+
+// linkData fills the link templates
+type linkData struct {
+	Link      string
+	ExpiresIn string
+}
+
+var verificationTemplate = template.Must(template.New("verification").Parse(
+	`Confirm your email address for LocoLive by opening this link:
+
+{{.Link}}
+
+The link expires in {{.ExpiresIn}}. If you didn't create a LocoLive account, you can ignore this email.`))
+
+var passwordResetTemplate = template.Must(template.New("password_reset").Parse(
+	`Someone asked to reset the password of your LocoLive account. Choose a new password by opening this link:
+
+{{.Link}}
+
+The link expires in {{.ExpiresIn}} and works once. If you didn't ask for this, you can ignore this email; your password won't change.`))
+
+// Verification renders the email address confirmation email
+func Verification(link string, ttl time.Duration) (Message, error) {
+	return render(verificationTemplate, "Confirm your email", link, ttl)
+}
+
+// PasswordReset renders the password reset email
+func PasswordReset(link string, ttl time.Duration) (Message, error) {
+	return render(passwordResetTemplate, "Reset your LocoLive password", link, ttl)
+}
+
+func render(t *template.Template, subject, link string, ttl time.Duration) (Message, error) {
+	var body bytes.Buffer
+	if err := t.Execute(&body, linkData{Link: link, ExpiresIn: humanDuration(ttl)}); err != nil {
+		return Message{}, err
+	}
+	return Message{Subject: subject, Body: body.String()}, nil
+}
+
+// humanDuration formats whole hours or minutes, e.g. "24 hours" or "1 hour"
+func humanDuration(d time.Duration) string {
+	n, unit := int(d/time.Minute), "minute"
+	if d >= time.Hour && d%time.Hour == 0 {
+		n, unit = int(d/time.Hour), "hour"
+	}
+	if n == 1 {
+		return "1 " + unit
+	}
+	return strconv.Itoa(n) + " " + unit + "s"
+}
@@ -0,0 +1,120 @@
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// DataKeySize is the size of AES-256 data and master keys
+const DataKeySize = 32
+
+var (
+	// ErrUnknownKey is returned when data was wrapped with a master key the provider doesn't have
+	ErrUnknownKey = errors.New("unknown master key")
+	// ErrDecrypt is returned when ciphertext fails authentication
+	ErrDecrypt = errors.New("decryption failed")
+)
+
+// KeyProvider wraps and unwraps data keys with master keys that never leave
+// it. A KMS client can implement it; StaticKeyProvider holds keys from config.
+type KeyProvider interface {
+	// CurrentKeyID names the master key new data keys are wrapped with
+	CurrentKeyID() string
+	Wrap(ctx context.Context, dataKey []byte) (keyID string, wrapped []byte, err error)
+	Unwrap(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// NewDataKey returns a random AES-256 key
+func NewDataKey() ([]byte, error) {
+	key := make([]byte, DataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Seal encrypts plaintext with AES-GCM, binding it to aad. The random nonce
+// is prepended to the result.
+func Seal(key, plaintext, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize(), gcm.NonceSize()+len(plaintext)+gcm.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+// Open decrypts a Seal result, failing with ErrDecrypt if it was altered or aad differs
+func Open(key, ciphertext, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	nonce, sealed := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, sealed, aad)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != DataKeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes", DataKeySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// StaticKeyProvider wraps data keys with master keys loaded from config
+type StaticKeyProvider struct {
+	current string
+	keys    map[string][]byte
+}
+
+// NewStaticKeyProvider creates a provider that wraps new data keys with current.
+// Retired master keys stay in keys so existing data keys can still be unwrapped.
+func NewStaticKeyProvider(current string, keys map[string][]byte) (*StaticKeyProvider, error) {
+	for id, key := range keys {
+		if len(key) != DataKeySize {
+			return nil, fmt.Errorf("master key %q must be %d bytes", id, DataKeySize)
+		}
+	}
+	if _, ok := keys[current]; !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, current)
+	}
+	return &StaticKeyProvider{current: current, keys: keys}, nil
+}
+
+// CurrentKeyID returns the ID of the master key used for wrapping
+func (p *StaticKeyProvider) CurrentKeyID() string {
+	return p.current
+}
+
+// Wrap encrypts a data key with the current master key
+func (p *StaticKeyProvider) Wrap(ctx context.Context, dataKey []byte) (string, []byte, error) {
+	wrapped, err := Seal(p.keys[p.current], dataKey, []byte(p.current))
+	return p.current, wrapped, err
+}
+
+// Unwrap decrypts a data key wrapped with the master key keyID
+func (p *StaticKeyProvider) Unwrap(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	key, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, keyID)
+	}
+	return Open(key, wrapped, []byte(keyID))
+}
@@ -60,3 +60,27 @@ func (c *Client) Send(ctx context.Context, token string, title, body string, dat
 	}
 	return nil
 }
+
+// ErrorClass buckets a Send error by its FCM error code for delivery analytics
+func ErrorClass(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case messaging.IsUnregistered(err):
+		return "unregistered"
+	case messaging.IsInvalidArgument(err):
+		return "invalid_argument"
+	case messaging.IsSenderIDMismatch(err):
+		return "sender_id_mismatch"
+	case messaging.IsQuotaExceeded(err):
+		return "quota_exceeded"
+	case messaging.IsThirdPartyAuthError(err):
+		return "third_party_auth"
+	case messaging.IsUnavailable(err):
+		return "unavailable"
+	case messaging.IsInternal(err):
+		return "internal"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,210 @@
+package graph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/config"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/middleware"
+	"go.uber.org/zap"
+)
+
+// fakeRepos holds one group chat in memory and counts the batched lookups
+type fakeRepos struct {
+	domain.ChatRepository
+	domain.AuthRepository
+	domain.ConnectionRepository
+
+	chat     *domain.Chat
+	users    map[uuid.UUID]*domain.User
+	messages []*domain.Message
+
+	mu            sync.Mutex
+	userBatches   [][]uuid.UUID
+	connectedSets [][]uuid.UUID
+}
+
+func (f *fakeRepos) GetChatClearedAt(ctx context.Context, chatID, userID uuid.UUID) (*time.Time, error) {
+	ok, err := f.IsChatParticipant(ctx, chatID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, domain.ErrNotChatParticipant
+	}
+	return nil, nil
+}
+
+func (f *fakeRepos) IsChatParticipant(ctx context.Context, chatID, userID uuid.UUID) (bool, error) {
+	if chatID != f.chat.ID {
+		return false, domain.ErrChatNotFound
+	}
+	for _, u := range f.chat.Users {
+		if u.ID == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeRepos) GetChatByID(ctx context.Context, chatID uuid.UUID) (*domain.Chat, error) {
+	return f.chat, nil
+}
+
+func (f *fakeRepos) GetMessages(ctx context.Context, chatID uuid.UUID, page domain.MessagePage) ([]*domain.Message, error) {
+	return f.messages, nil
+}
+
+func (f *fakeRepos) GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]*domain.User, error) {
+	f.mu.Lock()
+	f.userBatches = append(f.userBatches, ids)
+	f.mu.Unlock()
+
+	var users []*domain.User
+	for _, id := range ids {
+		if u, ok := f.users[id]; ok {
+			users = append(users, u)
+		}
+	}
+	return users, nil
+}
+
+func (f *fakeRepos) GetConnectedAmong(ctx context.Context, userID uuid.UUID, candidateIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	f.mu.Lock()
+	f.connectedSets = append(f.connectedSets, candidateIDs)
+	f.mu.Unlock()
+	return map[uuid.UUID]bool{candidateIDs[0]: true}, nil
+}
+
+// newFakeRepos creates a chat of three, with messages from each of them and
+// from a fourth user who has since deleted their account
+func newFakeRepos() *fakeRepos {
+	f := &fakeRepos{chat: &domain.Chat{ID: uuid.New(), IsGroup: true}, users: make(map[uuid.UUID]*domain.User)}
+	for _, name := range []string{"Asha", "Ravi", "Meera"} {
+		u := &domain.User{ID: uuid.New(), Name: name}
+		f.users[u.ID] = u
+		f.chat.Users = append(f.chat.Users, u.ToCardResponse())
+	}
+	senders := []uuid.UUID{uuid.New()}
+	for _, u := range f.chat.Users {
+		senders = append(senders, u.ID)
+	}
+	for i := 0; i < 12; i++ {
+		f.messages = append(f.messages, &domain.Message{ID: uuid.New(), ChatID: f.chat.ID, SenderID: senders[i%len(senders)], Content: "hi"})
+	}
+	return f
+}
+
+func newTestHandler(f *fakeRepos) *Handler {
+	logger := zap.NewNop()
+	authService := domain.NewAuthService(f, f, nil, nil, nil, nil, nil, domain.EmailLinkSettings{}, nil,
+		domain.LockoutPolicy{}, domain.SessionPolicy{}, domain.TextPolicy{}, nil, nil, logger)
+	chatService := domain.NewChatService(f, f, f, nil, nil, domain.TextPolicy{}, domain.VoiceNotePolicy{})
+	resolver := NewResolver(authService, nil, chatService, domain.NewConnectionService(f, nil), nil, logger)
+	return NewHandler(resolver, config.GraphQLConfig{Enabled: true, MaxComplexity: 500}, logger)
+}
+
+type gqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func query(t *testing.T, h *Handler, viewerID uuid.UUID, q string) gqlResponse {
+	t.Helper()
+
+	body, _ := json.Marshal(map[string]string{"query": q})
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, viewerID))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	var resp gqlResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response %q: %v", rec.Body.String(), err)
+	}
+	return resp
+}
+
+func TestMessageSendersLoadInOneBatch(t *testing.T) {
+	f := newFakeRepos()
+	viewer := f.chat.Users[0].ID
+
+	resp := query(t, newTestHandler(f), viewer, `{ messages(chatId: "`+f.chat.ID.String()+`") { messages { content sender { name } } } }`)
+	if len(resp.Errors) > 0 {
+		t.Fatalf("unexpected errors %+v", resp.Errors)
+	}
+	if len(f.userBatches) != 1 || len(f.userBatches[0]) != 4 {
+		t.Fatalf("got user lookups %v, want one of 4 senders", f.userBatches)
+	}
+
+	var data struct {
+		Messages struct {
+			Messages []struct {
+				Sender *struct{ Name string }
+			}
+		}
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		t.Fatalf("decode data: %v", err)
+	}
+	missing := 0
+	for _, m := range data.Messages.Messages {
+		if m.Sender == nil {
+			missing++
+		}
+	}
+	if missing != 3 {
+		t.Fatalf("got %d messages without a sender, want the deleted user's 3", missing)
+	}
+}
+
+func TestConnectedLoadsInOneBatch(t *testing.T) {
+	f := newFakeRepos()
+	viewer := f.chat.Users[0].ID
+
+	resp := query(t, newTestHandler(f), viewer, `{ chat(id: "`+f.chat.ID.String()+`") { users { name connected } } }`)
+	if len(resp.Errors) > 0 {
+		t.Fatalf("unexpected errors %+v", resp.Errors)
+	}
+	// The viewer is never looked up
+	if len(f.connectedSets) != 1 || len(f.connectedSets[0]) != 2 {
+		t.Fatalf("got connection lookups %v, want one of the 2 others", f.connectedSets)
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	f := newFakeRepos()
+	h := newTestHandler(f)
+	viewer := f.chat.Users[0].ID
+
+	tests := []struct {
+		name   string
+		viewer uuid.UUID
+		query  string
+		code   string
+	}{
+		{"unknown chat", viewer, `{ chat(id: "` + uuid.NewString() + `") { id } }`, "NOT_FOUND"},
+		{"not a participant", uuid.New(), `{ messages(chatId: "` + f.chat.ID.String() + `") { next } }`, "FORBIDDEN"},
+		{"profile without id or username", viewer, `{ profile { user { id } } }`, "BAD_REQUEST"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := query(t, h, tt.viewer, tt.query)
+			if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != tt.code {
+				t.Fatalf("got errors %+v, want code %s", resp.Errors, tt.code)
+			}
+		})
+	}
+}
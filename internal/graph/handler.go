@@ -0,0 +1,104 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/locolive/backend/internal/config"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/middleware"
+	"github.com/locolive/backend/pkg/response"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.uber.org/zap"
+)
+
+// errBadArguments is returned for arguments the schema can't rule out
+var errBadArguments = errors.New("give either id or username")
+
+// errorCodes are the domain errors shown to clients, with the same codes
+// the REST API answers them with. Others are logged and hidden.
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{domain.ErrUserNotFound, "NOT_FOUND"},
+	{domain.ErrChatNotFound, "NOT_FOUND"},
+	{domain.ErrNotChatParticipant, "FORBIDDEN"},
+	{domain.ErrFeatureUnavailable, "FEATURE_UNAVAILABLE"},
+	{domain.ErrInvalidMessageCursor, "BAD_REQUEST"},
+	{errBadArguments, "BAD_REQUEST"},
+}
+
+// Handler serves GraphQL queries over POST. It must be mounted behind the
+// auth middleware.
+type Handler struct {
+	resolver *Resolver
+	server   *handler.Server
+	logger   *zap.Logger
+}
+
+// NewHandler creates the GraphQL endpoint
+func NewHandler(resolver *Resolver, cfg config.GraphQLConfig, logger *zap.Logger) *Handler {
+	server := handler.New(NewExecutableSchema(Config{Resolvers: resolver}))
+	server.AddTransport(transport.POST{})
+	server.SetQueryCache(lru.New[*ast.QueryDocument](1000))
+	server.Use(extension.FixedComplexityLimit(cfg.MaxComplexity))
+	if cfg.Introspection {
+		server.Use(extension.Introspection{})
+	}
+
+	h := &Handler{resolver: resolver, server: server, logger: logger}
+	server.AroundFields(h.presentErrors)
+	server.SetRecoverFunc(func(ctx context.Context, err interface{}) error {
+		logger.Error("graphql resolver panicked", zap.Any("panic", err))
+		return errors.New("internal error")
+	})
+	return h
+}
+
+// ServeHTTP runs a query with fresh loaders for the signed-in user
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	viewerID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Unauthorized(w, "not authenticated")
+		return
+	}
+
+	ctx := withLoaders(r.Context(), h.resolver.newLoaders(viewerID))
+	h.server.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// presentErrors gives the known domain errors resolvers return their REST
+// code and hides the rest. Errors gqlgen raises itself, such as for invalid
+// queries or arguments, never reach resolvers and pass through as they are.
+func (h *Handler) presentErrors(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	res, err := next(ctx)
+	if err == nil {
+		return res, nil
+	}
+	var gqlErr *gqlerror.Error
+	if errors.As(err, &gqlErr) {
+		return res, err
+	}
+
+	gqlErr = gqlerror.WrapPath(graphql.GetPath(ctx), err)
+	for _, known := range errorCodes {
+		if errors.Is(err, known.err) {
+			gqlErr.Message = known.err.Error()
+			gqlErr.Extensions = map[string]interface{}{"code": known.code}
+			return res, gqlErr
+		}
+	}
+
+	h.logger.Error("graphql query failed", zap.String("path", gqlErr.Path.String()), zap.Error(err))
+	gqlErr.Message = "internal error"
+	gqlErr.Extensions = map[string]interface{}{"code": "INTERNAL_ERROR"}
+	return res, gqlErr
+}
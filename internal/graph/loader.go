@@ -0,0 +1,113 @@
+package graph
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/domain"
+)
+
+// loaderWait is how long a loader collects keys before fetching them. The
+// fields of a list resolve concurrently, so this is enough to gather a page.
+const loaderWait = 2 * time.Millisecond
+
+// loaderMaxBatch caps the keys fetched at once; more start another batch
+const loaderMaxBatch = 200
+
+// loader batches the keys asked for while a query resolves into one fetch,
+// so resolving a field on every item of a list doesn't query once per item.
+// Results are kept for the rest of the request.
+type loader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	batches map[K]*batch[K, V]
+	pending *batch[K, V]
+}
+
+type batch[K comparable, V any] struct {
+	keys   []K
+	full   chan struct{}
+	done   chan struct{}
+	values map[K]V
+	err    error
+}
+
+func newLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{fetch: fetch, batches: make(map[K]*batch[K, V])}
+}
+
+// Load returns the value for key, or the zero value if fetch didn't return one
+func (l *loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	b, ok := l.batches[key]
+	if !ok {
+		if l.pending == nil {
+			l.pending = &batch[K, V]{full: make(chan struct{}), done: make(chan struct{})}
+			go l.run(ctx, l.pending)
+		}
+		b = l.pending
+		b.keys = append(b.keys, key)
+		l.batches[key] = b
+		if len(b.keys) == loaderMaxBatch {
+			l.pending = nil
+			close(b.full)
+		}
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-b.done:
+		return b.values[key], b.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// run fetches b once it is full or has waited loaderWait
+func (l *loader[K, V]) run(ctx context.Context, b *batch[K, V]) {
+	timer := time.NewTimer(loaderWait)
+	defer timer.Stop()
+	select {
+	case <-b.full:
+	case <-timer.C:
+		l.mu.Lock()
+		if l.pending == b {
+			l.pending = nil
+		}
+		l.mu.Unlock()
+	}
+
+	b.values, b.err = l.fetch(ctx, b.keys)
+	close(b.done)
+}
+
+// loaders are the per-request loaders, batching lookups for the viewer
+type loaders struct {
+	// users loads user cards; deactivated users load as nil
+	users *loader[uuid.UUID, *domain.UserResponse]
+	// connected loads whether the viewer is connected with a user
+	connected *loader[uuid.UUID, bool]
+}
+
+func (r *Resolver) newLoaders(viewerID uuid.UUID) *loaders {
+	return &loaders{
+		users: newLoader(r.authService.GetUserCards),
+		connected: newLoader(func(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]bool, error) {
+			return r.connectionService.ConnectedAmong(ctx, viewerID, ids)
+		}),
+	}
+}
+
+type loadersKey struct{}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFor(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
@@ -0,0 +1,8 @@
+package graph
+
+import "github.com/locolive/backend/internal/domain"
+
+// Profile is a user's profile as the viewer may see it
+type Profile struct {
+	User *domain.UserResponse `json:"user"`
+}
@@ -0,0 +1,114 @@
+// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
+
+package graph
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/domain"
+)
+
+type MessagePage struct {
+	Messages []*domain.Message `json:"messages"`
+	// Pass as before to get the next page; null on the last page
+	Next *uuid.UUID `json:"next,omitempty"`
+}
+
+type Query struct {
+}
+
+type ConnectionState string
+
+const (
+	ConnectionStateNone             ConnectionState = "NONE"
+	ConnectionStateOutgoing         ConnectionState = "OUTGOING"
+	ConnectionStateIncoming         ConnectionState = "INCOMING"
+	ConnectionStateConnected        ConnectionState = "CONNECTED"
+	ConnectionStateRejectedCooldown ConnectionState = "REJECTED_COOLDOWN"
+	ConnectionStateBlocked          ConnectionState = "BLOCKED"
+)
+
+var AllConnectionState = []ConnectionState{
+	ConnectionStateNone,
+	ConnectionStateOutgoing,
+	ConnectionStateIncoming,
+	ConnectionStateConnected,
+	ConnectionStateRejectedCooldown,
+	ConnectionStateBlocked,
+}
+
+func (e ConnectionState) IsValid() bool {
+	switch e {
+	case ConnectionStateNone, ConnectionStateOutgoing, ConnectionStateIncoming, ConnectionStateConnected, ConnectionStateRejectedCooldown, ConnectionStateBlocked:
+		return true
+	}
+	return false
+}
+
+func (e ConnectionState) String() string {
+	return string(e)
+}
+
+func (e *ConnectionState) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ConnectionState(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ConnectionState", str)
+	}
+	return nil
+}
+
+func (e ConnectionState) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type SeenMode string
+
+const (
+	SeenModeInclude SeenMode = "INCLUDE"
+	SeenModeExclude SeenMode = "EXCLUDE"
+	// Keep seen stories but rank them after unseen ones
+	SeenModeLast SeenMode = "LAST"
+)
+
+var AllSeenMode = []SeenMode{
+	SeenModeInclude,
+	SeenModeExclude,
+	SeenModeLast,
+}
+
+func (e SeenMode) IsValid() bool {
+	switch e {
+	case SeenModeInclude, SeenModeExclude, SeenModeLast:
+		return true
+	}
+	return false
+}
+
+func (e SeenMode) String() string {
+	return string(e)
+}
+
+func (e *SeenMode) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SeenMode(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SeenMode", str)
+	}
+	return nil
+}
+
+func (e SeenMode) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
@@ -0,0 +1,50 @@
+// Package graph is the GraphQL gateway for the mobile client. Its resolvers
+// call the same domain services as the REST handlers.
+package graph
+
+//go:generate go run github.com/99designs/gqlgen generate --config ../../gqlgen.yml
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/middleware"
+	"go.uber.org/zap"
+)
+
+// Resolver is the root resolver, shared by all requests
+type Resolver struct {
+	authService       *domain.AuthService
+	storyService      *domain.StoryService
+	chatService       *domain.ChatService
+	connectionService *domain.ConnectionService
+	featureGate       *domain.FeatureGate
+	logger            *zap.Logger
+}
+
+// NewResolver creates the root resolver
+func NewResolver(authService *domain.AuthService, storyService *domain.StoryService, chatService *domain.ChatService, connectionService *domain.ConnectionService, featureGate *domain.FeatureGate, logger *zap.Logger) *Resolver {
+	return &Resolver{
+		authService:       authService,
+		storyService:      storyService,
+		chatService:       chatService,
+		connectionService: connectionService,
+		featureGate:       featureGate,
+		logger:            logger,
+	}
+}
+
+// viewerID returns the signed-in user; Handler turns away requests without one
+func viewerID(ctx context.Context) uuid.UUID {
+	userID, _ := middleware.GetUserID(ctx)
+	return userID
+}
+
+// intValue returns the value of an optional argument, 0 if it was left out
+func intValue(v *int) int {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
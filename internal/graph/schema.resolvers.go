@@ -0,0 +1,119 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/domain"
+)
+
+func (r *messageResolver) Sender(ctx context.Context, obj *domain.Message) (*domain.UserResponse, error) {
+	return loadersFor(ctx).users.Load(ctx, obj.SenderID)
+}
+
+func (r *profileResolver) ConnectionState(ctx context.Context, obj *Profile) (ConnectionState, error) {
+	state, err := r.connectionService.GetState(ctx, viewerID(ctx), obj.User.ID)
+	if errors.Is(err, domain.ErrCannotConnectSelf) {
+		return ConnectionStateNone, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return ConnectionState(strings.ToUpper(string(state))), nil
+}
+
+func (r *queryResolver) Me(ctx context.Context) (*domain.UserResponse, error) {
+	user, err := r.authService.GetUserByID(ctx, viewerID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return user.ToResponse(), nil
+}
+
+func (r *queryResolver) Feed(ctx context.Context, page *int, limit *int, lat *float64, lng *float64, radius *float64) ([]*domain.Story, error) {
+	if err := r.featureGate.Check(ctx, domain.FeatureNearbyFeed); err != nil {
+		return nil, err
+	}
+	if lat != nil && lng != nil && radius == nil {
+		// Same default as the REST feed
+		defaultRadius := 5000.0
+		radius = &defaultRadius
+	}
+	return r.storyService.GetFeed(ctx, viewerID(ctx), intValue(page), intValue(limit), lat, lng, radius)
+}
+
+func (r *queryResolver) ConnectionsFeed(ctx context.Context, seen *SeenMode, page *int, limit *int) ([]*domain.Story, error) {
+	mode := domain.SeenInclude
+	if seen != nil {
+		mode = domain.SeenMode(strings.ToLower(string(*seen)))
+	}
+	return r.storyService.GetConnectionsFeed(ctx, viewerID(ctx), mode, intValue(page), intValue(limit))
+}
+
+func (r *queryResolver) Profile(ctx context.Context, id *uuid.UUID, username *string) (*Profile, error) {
+	var user *domain.UserResponse
+	var err error
+	switch {
+	case id != nil && username == nil:
+		user, err = r.authService.GetUser(ctx, viewerID(ctx), *id)
+	case username != nil && id == nil:
+		user, err = r.authService.GetUserByUsername(ctx, viewerID(ctx), strings.TrimPrefix(*username, "@"))
+	default:
+		return nil, errBadArguments
+	}
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Profile{User: user}, nil
+}
+
+func (r *queryResolver) Chats(ctx context.Context, archived *bool) ([]*domain.Chat, error) {
+	return r.chatService.GetUserChats(ctx, viewerID(ctx), archived != nil && *archived)
+}
+
+func (r *queryResolver) Chat(ctx context.Context, id uuid.UUID) (*domain.Chat, error) {
+	return r.chatService.GetChat(ctx, id, viewerID(ctx))
+}
+
+func (r *queryResolver) Messages(ctx context.Context, chatID uuid.UUID, limit *int, before *uuid.UUID) (*MessagePage, error) {
+	messages, next, err := r.chatService.GetMessages(ctx, chatID, viewerID(ctx), domain.MessagePage{Limit: intValue(limit), Before: before})
+	if err != nil {
+		return nil, err
+	}
+	return &MessagePage{Messages: messages, Next: next}, nil
+}
+
+func (r *storyResolver) User(ctx context.Context, obj *domain.Story) (*domain.UserResponse, error) {
+	if obj.User != nil {
+		return obj.User, nil
+	}
+	return loadersFor(ctx).users.Load(ctx, obj.UserID)
+}
+
+func (r *userResolver) Connected(ctx context.Context, obj *domain.UserResponse) (bool, error) {
+	if obj.ID == viewerID(ctx) {
+		return false, nil
+	}
+	return loadersFor(ctx).connected.Load(ctx, obj.ID)
+}
+
+func (r *Resolver) Message() MessageResolver { return &messageResolver{r} }
+
+func (r *Resolver) Profile() ProfileResolver { return &profileResolver{r} }
+
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+func (r *Resolver) Story() StoryResolver { return &storyResolver{r} }
+
+func (r *Resolver) User() UserResolver { return &userResolver{r} }
+
+type messageResolver struct{ *Resolver }
+type profileResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
+type storyResolver struct{ *Resolver }
+type userResolver struct{ *Resolver }
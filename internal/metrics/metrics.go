@@ -0,0 +1,262 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are latency buckets (in seconds) suited for HTTP and DB timings
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+const labelSeparator = "\xff"
+
+// Registry holds all application metrics and renders them in Prometheus text format
+type Registry struct {
+	mu         sync.RWMutex
+	counters   map[string]*CounterVec
+	gauges     map[string]*GaugeVec
+	histograms map[string]*HistogramVec
+}
+
+// NewRegistry creates an empty metrics registry
+func NewRegistry() *Registry {
+	return &Registry{
+		counters:   make(map[string]*CounterVec),
+		gauges:     make(map[string]*GaugeVec),
+		histograms: make(map[string]*HistogramVec),
+	}
+}
+
+// Counter returns the counter with the given name, creating it if needed
+func (r *Registry) Counter(name, help string, labelNames ...string) *CounterVec {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if c, ok := r.counters[name]; ok {
+		return c
+	}
+	c := &CounterVec{name: name, help: help, labelNames: labelNames, values: make(map[string]float64)}
+	r.counters[name] = c
+	return c
+}
+
+// Gauge returns the gauge with the given name, creating it if needed
+func (r *Registry) Gauge(name, help string, labelNames ...string) *GaugeVec {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if g, ok := r.gauges[name]; ok {
+		return g
+	}
+	g := &GaugeVec{name: name, help: help, labelNames: labelNames, values: make(map[string]float64)}
+	r.gauges[name] = g
+	return g
+}
+
+// Histogram returns the histogram with the given name, creating it if needed.
+// A nil buckets slice uses DefaultBuckets.
+func (r *Registry) Histogram(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if h, ok := r.histograms[name]; ok {
+		return h
+	}
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	h := &HistogramVec{name: name, help: help, labelNames: labelNames, buckets: buckets, values: make(map[string]*histogramValue)}
+	r.histograms[name] = h
+	return h
+}
+
+// CounterVec is a monotonically increasing counter partitioned by labels
+type CounterVec struct {
+	name       string
+	help       string
+	labelNames []string
+	mu         sync.Mutex
+	values     map[string]float64
+}
+
+// Inc increments the counter for the given label values by one
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add increments the counter for the given label values by v
+func (c *CounterVec) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		return
+	}
+	key := strings.Join(labelValues, labelSeparator)
+	c.mu.Lock()
+	c.values[key] += v
+	c.mu.Unlock()
+}
+
+// GaugeVec is a value that can go up and down, partitioned by labels
+type GaugeVec struct {
+	name       string
+	help       string
+	labelNames []string
+	mu         sync.Mutex
+	values     map[string]float64
+}
+
+// Set sets the gauge for the given label values
+func (g *GaugeVec) Set(v float64, labelValues ...string) {
+	key := strings.Join(labelValues, labelSeparator)
+	g.mu.Lock()
+	g.values[key] = v
+	g.mu.Unlock()
+}
+
+// Add adds v (which may be negative) to the gauge for the given label values
+func (g *GaugeVec) Add(v float64, labelValues ...string) {
+	key := strings.Join(labelValues, labelSeparator)
+	g.mu.Lock()
+	g.values[key] += v
+	g.mu.Unlock()
+}
+
+// HistogramVec tracks the distribution of observations, partitioned by labels
+type HistogramVec struct {
+	name       string
+	help       string
+	labelNames []string
+	buckets    []float64
+	mu         sync.Mutex
+	values     map[string]*histogramValue
+}
+
+type histogramValue struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// Observe records a single observation for the given label values
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	key := strings.Join(labelValues, labelSeparator)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	hv, ok := h.values[key]
+	if !ok {
+		hv = &histogramValue{counts: make([]uint64, len(h.buckets))}
+		h.values[key] = hv
+	}
+	for i, upper := range h.buckets {
+		if v <= upper {
+			hv.counts[i]++
+		}
+	}
+	hv.count++
+	hv.sum += v
+}
+
+// Handler returns an HTTP handler that serves the registry in Prometheus text format
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = r.Write(w)
+	})
+}
+
+// Write renders all metrics in Prometheus text exposition format
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var b strings.Builder
+
+	for _, name := range sortedKeys(r.counters) {
+		c := r.counters[name]
+		c.mu.Lock()
+		writeHeader(&b, c.name, c.help, "counter")
+		for _, key := range sortedKeys(c.values) {
+			writeSample(&b, c.name, c.labelNames, key, "", "", c.values[key])
+		}
+		c.mu.Unlock()
+	}
+
+	for _, name := range sortedKeys(r.gauges) {
+		g := r.gauges[name]
+		g.mu.Lock()
+		writeHeader(&b, g.name, g.help, "gauge")
+		for _, key := range sortedKeys(g.values) {
+			writeSample(&b, g.name, g.labelNames, key, "", "", g.values[key])
+		}
+		g.mu.Unlock()
+	}
+
+	for _, name := range sortedKeys(r.histograms) {
+		h := r.histograms[name]
+		h.mu.Lock()
+		writeHeader(&b, h.name, h.help, "histogram")
+		for _, key := range sortedKeys(h.values) {
+			hv := h.values[key]
+			for i, upper := range h.buckets {
+				writeSample(&b, h.name+"_bucket", h.labelNames, key, "le", formatFloat(upper), float64(hv.counts[i]))
+			}
+			writeSample(&b, h.name+"_bucket", h.labelNames, key, "le", "+Inf", float64(hv.count))
+			writeSample(&b, h.name+"_sum", h.labelNames, key, "", "", hv.sum)
+			writeSample(&b, h.name+"_count", h.labelNames, key, "", "", float64(hv.count))
+		}
+		h.mu.Unlock()
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeHeader(b *strings.Builder, name, help, typ string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func writeSample(b *strings.Builder, name string, labelNames []string, key, extraName, extraValue string, value float64) {
+	b.WriteString(name)
+
+	var pairs []string
+	if len(labelNames) > 0 {
+		values := strings.Split(key, labelSeparator)
+		for i, ln := range labelNames {
+			v := ""
+			if i < len(values) {
+				v = values[i]
+			}
+			pairs = append(pairs, fmt.Sprintf("%s=%q", ln, v))
+		}
+	}
+	if extraName != "" {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", extraName, extraValue))
+	}
+	if len(pairs) > 0 {
+		b.WriteString("{" + strings.Join(pairs, ",") + "}")
+	}
+
+	b.WriteString(" " + formatFloat(value) + "\n")
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
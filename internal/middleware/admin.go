@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/pkg/response"
+)
+
+// RoleLookup resolves a user's role
+type RoleLookup interface {
+	GetUserRole(ctx context.Context, userID uuid.UUID) (domain.Role, error)
+}
+
+// RequireAdmin restricts a route group to admins
+func RequireAdmin(roles RoleLookup, adminUserIDs []string) func(http.Handler) http.Handler {
+	return RequireRole(roles, adminUserIDs, domain.RoleAdmin)
+}
+
+// RequireRole restricts a route group to users holding one of allowed. It must
+// run after AuthMiddleware. Users in adminUserIDs count as admins whatever
+// their stored role, so the first admin can be bootstrapped from config.
+// Invalid IDs in the list are ignored, and impersonation tokens are always
+// refused.
+func RequireRole(roles RoleLookup, adminUserIDs []string, allowed ...domain.Role) func(http.Handler) http.Handler {
+	admins := make(map[uuid.UUID]struct{}, len(adminUserIDs))
+	for _, id := range adminUserIDs {
+		if parsed, err := uuid.Parse(id); err == nil {
+			admins[parsed] = struct{}{}
+		}
+	}
+	permitted := make(map[domain.Role]bool, len(allowed))
+	for _, role := range allowed {
+		permitted[role] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := GetUserID(r.Context())
+			if !ok {
+				response.Unauthorized(w, "unauthorized")
+				return
+			}
+			// An agent impersonating an admin doesn't get the admin's powers
+			if _, impersonated := GetImpersonatorID(r.Context()); impersonated {
+				response.Forbidden(w, "admin access required")
+				return
+			}
+
+			role := domain.RoleUser
+			if _, ok := admins[userID]; ok {
+				role = domain.RoleAdmin
+			} else {
+				stored, err := roles.GetUserRole(r.Context(), userID)
+				switch {
+				case err == nil:
+					role = stored
+				case !errors.Is(err, domain.ErrUserNotFound):
+					response.InternalError(w, "failed to check access")
+					return
+				}
+			}
+			if !permitted[role] {
+				response.Forbidden(w, "admin access required")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
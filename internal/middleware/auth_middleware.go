@@ -2,24 +2,37 @@ package middleware
 
 import (
 	"context"
+	"errors"
+	"net"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/locolive/backend/internal/auth"
+	"github.com/locolive/backend/internal/domain"
 	"github.com/locolive/backend/pkg/response"
 )
 
 type contextKey string
 
 const (
-	UserIDKey    contextKey = "user_id"
-	SessionIDKey contextKey = "session_id"
-	EmailKey     contextKey = "email"
+	UserIDKey        contextKey = "user_id"
+	SessionIDKey     contextKey = "session_id"
+	SessionRegionKey contextKey = "session_region"
+	EmailKey         contextKey = "email"
 )
 
-// AuthMiddleware creates JWT authentication middleware
-func AuthMiddleware(jwtManager *auth.JWTManager) func(http.Handler) http.Handler {
+// SessionLookup returns an active session, or domain.ErrSessionNotFound once
+// it has been deactivated
+type SessionLookup interface {
+	GetSessionByID(ctx context.Context, id uuid.UUID) (*domain.Session, error)
+}
+
+// AuthMiddleware creates JWT authentication middleware. With sessions set,
+// access tokens are also rejected as soon as their session is revoked rather
+// than when they expire.
+func AuthMiddleware(jwtManager *auth.JWTManager, sessions SessionLookup) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Get Authorization header
@@ -45,14 +58,39 @@ func AuthMiddleware(jwtManager *auth.JWTManager) func(http.Handler) http.Handler
 					response.Unauthorized(w, "token has expired")
 					return
 				}
+				if err == auth.ErrTokenNotYetValid {
+					response.ErrorWithDetails(w, http.StatusUnauthorized, "TOKEN_NOT_YET_VALID", "token is not valid yet",
+						map[string]interface{}{"server_time": time.Now().UTC()})
+					return
+				}
 				response.Unauthorized(w, "invalid token")
 				return
 			}
 
+			// Tokens issued before sessions were tracked carry no session to check
+			if sessions != nil && claims.SessionID != uuid.Nil {
+				session, err := sessions.GetSessionByID(r.Context(), claims.SessionID)
+				switch {
+				case errors.Is(err, domain.ErrSessionNotFound):
+					response.Unauthorized(w, "session has been revoked")
+					return
+				case err == nil && time.Now().After(session.ExpiresAt):
+					response.Unauthorized(w, "session has expired")
+					return
+				}
+				// Other lookup errors let the signed token stand so a database
+				// blip doesn't sign everyone out
+			}
+
 			// Add user info to context
 			ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
 			ctx = context.WithValue(ctx, SessionIDKey, claims.SessionID)
+			ctx = context.WithValue(ctx, SessionRegionKey, claims.Region)
 			ctx = context.WithValue(ctx, EmailKey, claims.Email)
+			if claims.ImpersonatorID != nil && claims.GrantID != nil {
+				ctx = domain.WithImpersonator(ctx, *claims.ImpersonatorID)
+				ctx = context.WithValue(ctx, SupportGrantIDKey, *claims.GrantID)
+			}
 
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
@@ -71,6 +109,12 @@ func GetSessionID(ctx context.Context) (uuid.UUID, bool) {
 	return sessionID, ok
 }
 
+// GetSessionRegion extracts the country the session signed in from, or "" if unknown
+func GetSessionRegion(ctx context.Context) string {
+	region, _ := ctx.Value(SessionRegionKey).(string)
+	return region
+}
+
 // GetEmail extracts email from context
 func GetEmail(ctx context.Context) (string, bool) {
 	email, ok := ctx.Value(EmailKey).(string)
@@ -94,8 +138,9 @@ func OptionalAuthMiddleware(jwtManager *auth.JWTManager) func(http.Handler) http
 			}
 
 			token := parts[1]
+			// Impersonation tokens need ImpersonationGuard, so they count as anonymous here
 			claims, err := jwtManager.ValidateAccessToken(token)
-			if err != nil {
+			if err != nil || claims.ImpersonatorID != nil {
 				next.ServeHTTP(w, r)
 				return
 			}
@@ -106,3 +151,12 @@ func OptionalAuthMiddleware(jwtManager *auth.JWTManager) func(http.Handler) http
 		})
 	}
 }
+
+// ClientIP returns the caller's address without its port. RealIP has already
+// replaced RemoteAddr with the client IP a trusted proxy forwarded.
+func ClientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
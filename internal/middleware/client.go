@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/metrics"
+	"github.com/locolive/backend/pkg/response"
+)
+
+// ClientHeader identifies the app build, e.g.
+// "X-Client: platform=ios; version=2.4.0; model=iPhone15,2"
+const ClientHeader = "X-Client"
+
+// UnknownPlatform is reported for clients without a recognized platform
+const UnknownPlatform = "unknown"
+
+// maxDeviceModelLength bounds the free-form model we log and store
+const maxDeviceModelLength = 64
+
+// ClientObserver receives the outcome of each request per client build
+// (e.g. the SLO tracker's per-version error rates)
+type ClientObserver interface {
+	ObserveClient(platform, appVersion string, status int)
+}
+
+// ParseClient reads the caller's build from X-Client, falling back to the
+// older X-Platform and X-App-Version headers. Platform and version are
+// normalized so they're safe to use as metric labels.
+func ParseClient(r *http.Request) domain.ClientInfo {
+	var platform, version, model string
+	for _, part := range strings.Split(r.Header.Get(ClientHeader), ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "platform":
+			platform = value
+		case "version":
+			version = value
+		case "model":
+			model = value
+		}
+	}
+	if platform == "" {
+		platform = r.Header.Get(PlatformHeader)
+	}
+	if version == "" {
+		version = r.Header.Get(AppVersionHeader)
+	}
+
+	client := domain.ClientInfo{
+		Platform:    UnknownPlatform,
+		AppVersion:  UnknownAppVersion,
+		DeviceModel: cleanDeviceModel(model),
+	}
+	if p, err := domain.ParsePlatform(strings.ToLower(strings.TrimSpace(platform))); err == nil && p != domain.PlatformAll {
+		client.Platform = string(p)
+	}
+	if version = strings.TrimSpace(version); appVersionRegex.MatchString(version) {
+		client.AppVersion = version
+	}
+	return client
+}
+
+func cleanDeviceModel(model string) string {
+	model = strings.Map(func(r rune) rune {
+		if !unicode.IsPrint(r) {
+			return -1
+		}
+		return r
+	}, model)
+	if len(model) > maxDeviceModelLength {
+		model = strings.ToValidUTF8(model[:maxDeviceModelLength], "")
+	}
+	return model
+}
+
+// ClientMiddleware stores the caller's build on the context for logs and
+// sessions, and counts responses per platform and app version
+func ClientMiddleware(registry *metrics.Registry, observer ClientObserver) func(http.Handler) http.Handler {
+	requests := registry.Counter("http_requests_by_client_total", "HTTP requests by client platform, app version and status class", "platform", "app_version", "status")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			client := ParseClient(r)
+			client.IPAddress = ClientIP(r)
+			client.UserAgent = r.UserAgent()
+			wrapped := wrapResponseWriter(w)
+
+			next.ServeHTTP(wrapped, r.WithContext(domain.WithClientInfo(r.Context(), client)))
+
+			requests.Inc(client.Platform, client.AppVersion, statusClass(wrapped.status))
+			if observer != nil {
+				observer.ObserveClient(client.Platform, client.AppVersion, wrapped.status)
+			}
+		})
+	}
+}
+
+// RequireClientHeader rejects requests without an X-Client header when the
+// policy asks for it
+func RequireClientHeader(policy domain.ClientPolicy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if policy.RequireClientHeader && r.Header.Get(ClientHeader) == "" {
+				response.Error(w, http.StatusBadRequest, "CLIENT_HEADER_REQUIRED",
+					"X-Client header is required, e.g. platform=ios; version=2.4.0; model=iPhone15,2")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func statusClass(status int) string {
+	return strconv.Itoa(status/100) + "xx"
+}
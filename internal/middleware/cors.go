@@ -21,13 +21,20 @@ func CORSMiddleware() func(next http.Handler) http.Handler {
 			"Accept",
 			"Authorization",
 			"Content-Type",
+			"If-None-Match",
 			"X-CSRF-Token",
 			"X-Requested-With",
+			AppVersionHeader,
+			PlatformHeader,
 		},
 
 		// Expose headers to the client
 		ExposedHeaders: []string{
+			"API-Version",
+			"Deprecation",
+			"ETag",
 			"Link",
+			"Sunset",
 			"X-Request-Id",
 		},
 
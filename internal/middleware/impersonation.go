@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/pkg/response"
+)
+
+const SupportGrantIDKey contextKey = "support_grant_id"
+
+// ImpersonationChecker approves and audits each request made with a support
+// impersonation token
+type ImpersonationChecker interface {
+	CheckImpersonatedRequest(ctx context.Context, agentID, userID, grantID uuid.UUID, method, path string) error
+}
+
+// GetImpersonatorID returns the support agent acting as the user, if any
+func GetImpersonatorID(ctx context.Context) (uuid.UUID, bool) {
+	return domain.ImpersonatorFromContext(ctx)
+}
+
+// ImpersonationGuard makes impersonation tokens read-only: only GET and HEAD
+// pass, and only while the user's grant is active. Each request is audited
+// before it runs. It must run after AuthMiddleware.
+func ImpersonationGuard(checker ImpersonationChecker) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			agentID, ok := GetImpersonatorID(r.Context())
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				response.Error(w, http.StatusForbidden, "IMPERSONATION_READ_ONLY", "support access is read-only")
+				return
+			}
+
+			userID, _ := GetUserID(r.Context())
+			grantID, _ := r.Context().Value(SupportGrantIDKey).(uuid.UUID)
+			err := checker.CheckImpersonatedRequest(r.Context(), agentID, userID, grantID, r.Method, r.URL.Path)
+			if errors.Is(err, domain.ErrSupportAccessRevoked) {
+				response.Unauthorized(w, "support access has been revoked or has expired")
+				return
+			}
+			if err != nil {
+				response.InternalError(w, "failed to authorize support access")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RejectImpersonation blocks impersonation tokens outright, for routes the
+// read-only guard can't protect such as WebSockets
+func RejectImpersonation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := GetImpersonatorID(r.Context()); ok {
+			response.Forbidden(w, "not available with support access")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
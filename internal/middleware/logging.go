@@ -1,10 +1,14 @@
 package middleware
 
 import (
+	"bufio"
+	"errors"
+	"net"
 	"net/http"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/domain"
 	"go.uber.org/zap"
 )
 
@@ -38,6 +42,29 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	return n, err
 }
 
+// Unwrap lets http.ResponseController reach the underlying writer, e.g. to
+// extend a long-poll's write deadline
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// Hijack passes through to the underlying writer. WebSocket upgraders assert
+// http.Hijacker on the writer they are given rather than unwrapping it, so
+// without this no upgrade gets past the middleware. A hijacked connection is
+// recorded as 101 Switching Protocols.
+func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := rw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	conn, buf, err := hijacker.Hijack()
+	if err == nil && !rw.wroteHeader {
+		rw.status = http.StatusSwitchingProtocols
+		rw.wroteHeader = true
+	}
+	return conn, buf, err
+}
+
 // LoggingMiddleware creates request logging middleware
 func LoggingMiddleware(logger *zap.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -65,11 +92,15 @@ func LoggingMiddleware(logger *zap.Logger) func(http.Handler) http.Handler {
 				zap.Int("status", wrapped.status),
 				zap.Int("size", wrapped.size),
 				zap.Duration("duration", duration),
-				zap.String("ip", getRealIP(r)),
+				zap.String("ip", ClientIP(r)),
 				zap.String("user_agent", r.UserAgent()),
 				zap.String("request_id", requestID),
 			}
 
+			if client, ok := domain.ClientInfoFromContext(r.Context()); ok {
+				fields = append(fields, clientFields(client)...)
+			}
+
 			// Add user ID if present (from auth middleware)
 			if userID, ok := GetUserID(r.Context()); ok {
 				fields = append(fields, zap.String("user_id", userID.String()))
@@ -80,22 +111,15 @@ func LoggingMiddleware(logger *zap.Logger) func(http.Handler) http.Handler {
 	}
 }
 
-// getRealIP extracts the real client IP from request headers
-func getRealIP(r *http.Request) string {
-	// Check X-Forwarded-For header
-	forwarded := r.Header.Get("X-Forwarded-For")
-	if forwarded != "" {
-		return forwarded
+func clientFields(client domain.ClientInfo) []zap.Field {
+	fields := []zap.Field{
+		zap.String("platform", client.Platform),
+		zap.String("app_version", client.AppVersion),
 	}
-
-	// Check X-Real-IP header
-	realIP := r.Header.Get("X-Real-IP")
-	if realIP != "" {
-		return realIP
+	if client.DeviceModel != "" {
+		fields = append(fields, zap.String("device_model", client.DeviceModel))
 	}
-
-	// Fall back to RemoteAddr
-	return r.RemoteAddr
+	return fields
 }
 
 // RecoveryMiddleware recovers from panics and logs them
@@ -104,11 +128,16 @@ func RecoveryMiddleware(logger *zap.Logger) func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			defer func() {
 				if err := recover(); err != nil {
-					logger.Error("panic recovered",
+					fields := []zap.Field{
 						zap.Any("error", err),
 						zap.String("path", r.URL.Path),
 						zap.String("method", r.Method),
-					)
+					}
+					// Crash reports carry the build so a bad release stands out
+					if client, ok := domain.ClientInfoFromContext(r.Context()); ok {
+						fields = append(fields, clientFields(client)...)
+					}
+					logger.Error("panic recovered", fields...)
 					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 				}
 			}()
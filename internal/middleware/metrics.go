@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/locolive/backend/internal/metrics"
+)
+
+// RequestObserver receives the outcome of every completed HTTP request
+type RequestObserver interface {
+	ObserveRequest(group string, status int, duration time.Duration)
+}
+
+// MetricsMiddleware records request counts and latencies per route and
+// forwards each result to the given observers (e.g. the SLO tracker)
+func MetricsMiddleware(registry *metrics.Registry, observers ...RequestObserver) func(http.Handler) http.Handler {
+	requests := registry.Counter("http_requests_total", "Total HTTP requests", "method", "route", "group", "status")
+	latency := registry.Histogram("http_request_duration_seconds", "HTTP request latency", nil, "method", "route", "group")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			wrapped := wrapResponseWriter(w)
+
+			next.ServeHTTP(wrapped, r)
+
+			duration := time.Since(start)
+			route := routePattern(r)
+			group := RouteGroup(r.Method, route)
+
+			requests.Inc(r.Method, route, group, strconv.Itoa(wrapped.status))
+			latency.Observe(duration.Seconds(), r.Method, route, group)
+
+			for _, o := range observers {
+				o.ObserveRequest(group, wrapped.status, duration)
+			}
+		})
+	}
+}
+
+var apiPrefixRegex = regexp.MustCompile(`^/api/v\d+`)
+
+// routePattern returns the matched chi route pattern, which keeps metric
+// label cardinality bounded (e.g. "/api/v1/chats/{chatId}/messages")
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			return pattern
+		}
+	}
+	return "unmatched"
+}
+
+// mediaUploadGroup holds multipart upload routes, whose latency depends on
+// the client's connection and file size rather than the server
+const mediaUploadGroup = "media_upload"
+
+// mediaUploadRoutes are the upload routes, without the API version prefix
+var mediaUploadRoutes = map[string]bool{
+	"POST stories":              true,
+	"POST chats/{chatId}/voice": true,
+}
+
+// RouteGroup maps a request to its top-level group, across API versions,
+// e.g. "/api/v1/stories/feed" -> "stories", "/auth/login" -> "auth". Uploads
+// go in mediaUploadGroup so they don't count against their route's latency SLO.
+func RouteGroup(method, route string) string {
+	route = apiPrefixRegex.ReplaceAllString(route, "")
+	route = strings.Trim(route, "/")
+	if route == "" || route == "unmatched" {
+		return "other"
+	}
+	if mediaUploadRoutes[method+" "+route] {
+		return mediaUploadGroup
+	}
+	if i := strings.Index(route, "/"); i >= 0 {
+		route = route[:i]
+	}
+	return route
+}
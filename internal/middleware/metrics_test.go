@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRouteGroup(t *testing.T) {
+	tests := []struct {
+		method string
+		route  string
+		want   string
+	}{
+		{http.MethodGet, "/api/v1/stories/feed", "stories"},
+		{http.MethodPost, "/api/v1/stories/", mediaUploadGroup},
+		{http.MethodPost, "/api/v2/stories/", mediaUploadGroup},
+		{http.MethodPost, "/api/v1/chats/{chatId}/voice", mediaUploadGroup},
+		{http.MethodPost, "/api/v1/chats/{chatId}/messages", "chats"},
+		{http.MethodGet, "/uploads/*", "uploads"},
+		{http.MethodPost, "/auth/login", "auth"},
+		{http.MethodGet, "unmatched", "other"},
+	}
+	for _, tt := range tests {
+		if got := RouteGroup(tt.method, tt.route); got != tt.want {
+			t.Errorf("RouteGroup(%s, %s) = %q, want %q", tt.method, tt.route, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// RealIP replaces RemoteAddr with the client address forwarded by a trusted
+// proxy, so ClientIP and everything keyed on it see the real caller.
+// Forwarding headers are only read when the direct peer is in trusted, since
+// anyone else can set them to anything. X-Real-IP, which the proxy
+// overwrites, is used first, then the last X-Forwarded-For hop that isn't
+// itself a trusted proxy. True-Client-IP is never read.
+func RealIP(trusted []*net.IPNet) func(http.Handler) http.Handler {
+	isTrusted := func(ip net.IP) bool {
+		for _, n := range trusted {
+			if n.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			peer := net.ParseIP(ClientIP(r))
+			if peer != nil && isTrusted(peer) {
+				if ip := forwardedIP(r, isTrusted); ip != nil {
+					r.RemoteAddr = ip.String()
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// forwardedIP returns the client address in a trusted proxy's headers, or nil
+func forwardedIP(r *http.Request, isTrusted func(net.IP) bool) net.IP {
+	if ip := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ip != nil {
+		return ip
+	}
+
+	// Hops are appended, so those left of the first untrusted one from the
+	// right may have been made up by the client
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			return nil
+		}
+		if !isTrusted(ip) {
+			return ip
+		}
+	}
+	return nil
+}
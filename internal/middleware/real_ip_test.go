@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRealIP(t *testing.T) {
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+	trusted := []*net.IPNet{proxies}
+
+	tests := []struct {
+		name    string
+		peer    string
+		headers map[string]string
+		want    string
+	}{
+		{"direct client", "203.0.113.7:5000", nil, "203.0.113.7"},
+		{"direct client spoofing", "203.0.113.7:5000", map[string]string{"X-Real-IP": "1.2.3.4", "X-Forwarded-For": "1.2.3.4", "True-Client-IP": "1.2.3.4"}, "203.0.113.7"},
+		{"proxy sets x-real-ip", "10.0.0.2:5000", map[string]string{"X-Real-IP": "198.51.100.4"}, "198.51.100.4"},
+		{"true-client-ip ignored", "10.0.0.2:5000", map[string]string{"True-Client-IP": "1.2.3.4", "X-Real-IP": "198.51.100.4"}, "198.51.100.4"},
+		{"last untrusted forwarded hop", "10.0.0.2:5000", map[string]string{"X-Forwarded-For": "1.2.3.4, 198.51.100.4, 10.0.0.9"}, "198.51.100.4"},
+		{"proxy without headers", "10.0.0.2:5000", nil, "10.0.0.2"},
+		{"garbage forwarded hop", "10.0.0.2:5000", map[string]string{"X-Forwarded-For": "1.2.3.4, nonsense"}, "10.0.0.2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.peer
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			var got string
+			RealIP(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = ClientIP(r)
+			})).ServeHTTP(httptest.NewRecorder(), req)
+			if got != tt.want {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/pkg/response"
+)
+
+// CountryLookup returns the country code on a user's profile, or "" if unset
+type CountryLookup interface {
+	GetUserCountry(ctx context.Context, userID uuid.UUID) (string, error)
+}
+
+// RegionMiddleware resolves the caller's region and stores it on the request
+// context (see domain.RegionFromContext). The country the edge derived from the
+// client IP, sent in header, wins; otherwise the region the session signed in
+// from, then the authenticated user's profile country, is used. It must run
+// after AuthMiddleware for the fallbacks to apply.
+func RegionMiddleware(header string, lookup CountryLookup) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			region, ok := domain.NormalizeCountryCode(r.Header.Get(header))
+			// XX is what edges send when the IP has no known country
+			if !ok || region == "XX" {
+				region = GetSessionRegion(r.Context())
+				if userID, ok := GetUserID(r.Context()); ok && region == "" && lookup != nil {
+					region, _ = lookup.GetUserCountry(r.Context(), userID)
+				}
+			}
+
+			next.ServeHTTP(w, r.WithContext(domain.WithRegion(r.Context(), region)))
+		})
+	}
+}
+
+// RequireFeature rejects requests from regions where feature is disabled. It
+// must run after RegionMiddleware.
+func RequireFeature(gate *domain.FeatureGate, feature domain.Feature) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := gate.Check(r.Context(), feature); err != nil {
+				response.Error(w, http.StatusForbidden, "FEATURE_UNAVAILABLE", err.Error())
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
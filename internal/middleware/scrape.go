@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/locolive/backend/pkg/response"
+)
+
+// RequireScrapeToken restricts operational endpoints such as /metrics to
+// scrapers sending "Authorization: Bearer <token>". With no token configured
+// the endpoints answer 404, so they're never exposed by accident.
+func RequireScrapeToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token == "" {
+				response.NotFound(w, "not found")
+				return
+			}
+			sent, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+				response.Unauthorized(w, "invalid scrape token")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/metrics"
+	"github.com/locolive/backend/pkg/response"
+)
+
+// APIVersionKey holds the negotiated API version on the request context
+const APIVersionKey contextKey = "api_version"
+
+// AppVersionHeader is sent by the mobile apps with their build version
+const AppVersionHeader = "X-App-Version"
+
+// PlatformHeader is sent by clients with their platform (ios, android, web)
+const PlatformHeader = "X-Platform"
+
+// UnknownAppVersion is reported for clients without a valid X-App-Version
+const UnknownAppVersion = "unknown"
+
+var (
+	acceptVersionRegex = regexp.MustCompile(`application/vnd\.locolive\.v(\d+)\+json`)
+	appVersionRegex    = regexp.MustCompile(`^\d{1,4}(\.\d{1,4}){0,2}$`)
+)
+
+// Deprecation describes when an API version was deprecated and when it goes away
+type Deprecation struct {
+	DeprecatedAt time.Time
+	SunsetAt     time.Time
+}
+
+// VersionPolicy lists the supported API versions and which are deprecated
+type VersionPolicy struct {
+	Latest       int
+	Deprecations map[int]Deprecation
+	// Link, if set, is sent as the deprecation documentation link
+	Link string
+}
+
+// APIVersionMiddleware negotiates the API version for routes mounted under
+// /api/v{pathVersion}. A client can opt into a newer version on an older path
+// with "Accept: application/vnd.locolive.v{N}+json". The result is stored on
+// the context, echoed in the API-Version header, and counted per app version;
+// deprecated versions also get Deprecation, Sunset and Link headers.
+func APIVersionMiddleware(pathVersion int, policy VersionPolicy, registry *metrics.Registry) func(http.Handler) http.Handler {
+	requests := registry.Counter("api_requests_by_version_total", "API requests by negotiated API version and client app version", "api_version", "app_version")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			version := pathVersion
+			if m := acceptVersionRegex.FindStringSubmatch(r.Header.Get("Accept")); m != nil {
+				requested, _ := strconv.Atoi(m[1])
+				if requested < 1 || requested > policy.Latest {
+					response.Error(w, http.StatusNotAcceptable, "UNSUPPORTED_API_VERSION",
+						fmt.Sprintf("API version %d is not supported; latest is %d", requested, policy.Latest))
+					return
+				}
+				if requested > version {
+					version = requested
+				}
+			}
+
+			w.Header().Set("API-Version", strconv.Itoa(version))
+			w.Header().Add("Vary", "Accept")
+			if d, ok := policy.Deprecations[version]; ok {
+				w.Header().Set("Deprecation", "@"+strconv.FormatInt(d.DeprecatedAt.Unix(), 10))
+				if !d.SunsetAt.IsZero() {
+					w.Header().Set("Sunset", d.SunsetAt.UTC().Format(http.TimeFormat))
+				}
+				if policy.Link != "" {
+					w.Header().Add("Link", "<"+policy.Link+`>; rel="deprecation"`)
+				}
+			}
+
+			requests.Inc(strconv.Itoa(version), AppVersion(r))
+
+			ctx := context.WithValue(r.Context(), APIVersionKey, version)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// GetAPIVersion returns the negotiated API version, defaulting to 1
+func GetAPIVersion(ctx context.Context) int {
+	if version, ok := ctx.Value(APIVersionKey).(int); ok {
+		return version
+	}
+	return 1
+}
+
+// AppVersion returns the client's app version from X-Client or X-App-Version,
+// or UnknownAppVersion if it is missing or malformed, so metric labels stay bounded
+func AppVersion(r *http.Request) string {
+	return ParseClient(r).AppVersion
+}
+
+// upgradeRequired is the error detail sent to blocked clients
+type upgradeRequired struct {
+	AppVersion string `json:"app_version"`
+	MinVersion string `json:"min_version,omitempty"`
+	UpdateURL  string `json:"update_url,omitempty"`
+}
+
+// RequireSupportedClient rejects app versions the policy blocks with a 426
+// UPGRADE_REQUIRED error whose details tell the app where to update.
+// Clients that don't send X-App-Version are let through.
+func RequireSupportedClient(policy domain.ClientPolicy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			version := AppVersion(r)
+			if version != UnknownAppVersion && !policy.Supported(version) {
+				response.ErrorWithDetails(w, http.StatusUpgradeRequired, "UPGRADE_REQUIRED",
+					"this app version is no longer supported, please update", upgradeRequired{
+						AppVersion: version,
+						MinVersion: policy.MinVersion,
+						UpdateURL:  policy.UpdateURL,
+					})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/cache"
+	"github.com/locolive/backend/internal/config"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/metrics"
+	"github.com/locolive/backend/internal/middleware"
+	"github.com/locolive/backend/pkg/response"
+	"go.uber.org/zap"
+)
+
+const (
+	TierNew      = "new"
+	TierStandard = "standard"
+
+	window = time.Minute
+)
+
+// Usage is a user's current request volume and limits
+type Usage struct {
+	Tier           string    `json:"tier"`
+	LimitPerMinute int       `json:"limit_per_minute"`
+	UsedThisMinute int64     `json:"used_this_minute"`
+	Remaining      int64     `json:"remaining"`
+	ResetAt        time.Time `json:"reset_at"`
+	RequestsToday  int64     `json:"requests_today"`
+}
+
+// Limiter tracks per-user request volume in fixed one-minute windows and
+// throttles users above their tier's fair-use limit
+type Limiter struct {
+	counter       cache.Counter
+	users         domain.AuthRepository
+	cfg           config.RateLimitConfig
+	logger        *zap.Logger
+	throttled     *metrics.CounterVec
+	ruleThrottled *metrics.CounterVec
+}
+
+// NewLimiter creates a new limiter
+func NewLimiter(counter cache.Counter, users domain.AuthRepository, cfg config.RateLimitConfig, registry *metrics.Registry, logger *zap.Logger) *Limiter {
+	return &Limiter{
+		counter:       counter,
+		users:         users,
+		cfg:           cfg,
+		logger:        logger,
+		throttled:     registry.Counter("rate_limited_requests_total", "Requests rejected by fair-use throttling", "tier"),
+		ruleThrottled: registry.Counter("rate_limited_rule_requests_total", "Requests rejected by per-route rate limit rules", "rule"),
+	}
+}
+
+// Middleware counts every authenticated request and rejects it with 429 once the
+// user's tier limit is exceeded. It must run after AuthMiddleware. Counter
+// failures let the request through so a Redis outage doesn't take the API down.
+func (l *Limiter) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := middleware.GetUserID(r.Context())
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			now := time.Now()
+			tier, limit := l.tierFor(ctx, userID, now)
+			windowStart := now.Truncate(window)
+			resetAt := windowStart.Add(window)
+
+			used, err := l.counter.Incr(ctx, minuteKey(userID, windowStart), window)
+			if err != nil {
+				l.logger.Warn("rate limit counter failed", zap.Error(err))
+				next.ServeHTTP(w, r)
+				return
+			}
+			if _, err := l.counter.Incr(ctx, dayKey(userID, now), 48*time.Hour); err != nil {
+				l.logger.Warn("usage counter failed", zap.Error(err))
+			}
+
+			remaining := int64(limit) - used
+			if remaining < 0 {
+				remaining = 0
+			}
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limit))
+			w.Header().Set("X-RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(resetAt.Unix(), 10))
+
+			if used > int64(limit) {
+				l.throttled.Inc(tier)
+				w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(resetAt).Seconds())+1))
+				response.TooManyRequests(w, "rate limit exceeded")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Usage returns the user's current request volume without counting a request
+func (l *Limiter) Usage(ctx context.Context, userID uuid.UUID) (*Usage, error) {
+	now := time.Now()
+	tier, limit := l.tierFor(ctx, userID, now)
+	windowStart := now.Truncate(window)
+
+	used, err := l.counter.GetInt(ctx, minuteKey(userID, windowStart))
+	if err != nil {
+		return nil, err
+	}
+	today, err := l.counter.GetInt(ctx, dayKey(userID, now))
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := int64(limit) - used
+	if remaining < 0 {
+		remaining = 0
+	}
+	return &Usage{
+		Tier:           tier,
+		LimitPerMinute: limit,
+		UsedThisMinute: used,
+		Remaining:      remaining,
+		ResetAt:        windowStart.Add(window),
+		RequestsToday:  today,
+	}, nil
+}
+
+// Allow counts an action a user takes outside an HTTP request, such as a
+// message sent over the WebSocket, against their fair-use tier and the named
+// rule, as if it were a request to a route using both. Like the middleware,
+// it allows the action when a counter fails.
+func (l *Limiter) Allow(ctx context.Context, userID uuid.UUID, rule string) bool {
+	now := time.Now()
+	tier, limit := l.tierFor(ctx, userID, now)
+
+	used, err := l.counter.Incr(ctx, minuteKey(userID, now.Truncate(window)), window)
+	switch {
+	case err != nil:
+		l.logger.Warn("rate limit counter failed", zap.Error(err))
+	case used > int64(limit):
+		l.throttled.Inc(tier)
+		return false
+	}
+	if _, err := l.counter.Incr(ctx, dayKey(userID, now), 48*time.Hour); err != nil {
+		l.logger.Warn("usage counter failed", zap.Error(err))
+	}
+
+	r, ok := l.cfg.Rules[rule]
+	if !ok || r.Limit <= 0 {
+		return true
+	}
+	used, err = l.counter.Incr(ctx, ruleKey(rule, "user:"+userID.String(), now.Truncate(r.Window)), r.Window)
+	if err != nil {
+		l.logger.Warn("rate limit counter failed", zap.String("rule", rule), zap.Error(err))
+		return true
+	}
+	if used > int64(r.Limit) {
+		l.ruleThrottled.Inc(rule)
+		return false
+	}
+	return true
+}
+
+// tierFor classifies the user. Lookup failures fall back to the stricter tier.
+func (l *Limiter) tierFor(ctx context.Context, userID uuid.UUID, now time.Time) (string, int) {
+	user, err := l.users.GetUserByID(ctx, userID)
+	if err != nil || !user.EmailVerified || now.Sub(user.CreatedAt) < l.cfg.NewAccountAge {
+		return TierNew, l.cfg.NewPerMinute
+	}
+	return TierStandard, l.cfg.StandardPerMinute
+}
+
+func minuteKey(userID uuid.UUID, windowStart time.Time) string {
+	return fmt.Sprintf("ratelimit:%s:%d", userID, windowStart.Unix())
+}
+
+func dayKey(userID uuid.UUID, now time.Time) string {
+	return fmt.Sprintf("usage:%s:%s", userID, now.UTC().Format("20060102"))
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/domain"
+)
+
+// RecordAbuseSignal stores a weighted abuse signal against a user
+func (r *PostgresRepository) RecordAbuseSignal(ctx context.Context, signal domain.AbuseSignal) error {
+	details, err := json.Marshal(signal.Details)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO abuse_signals (user_id, kind, weight, subject_id, details)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err = r.db.Exec(ctx, query, signal.UserID, signal.Kind, signal.Weight, signal.SubjectID, details)
+	return err
+}
+
+// GetAbuseScore sums a user's signal weights since the given time
+func (r *PostgresRepository) GetAbuseScore(ctx context.Context, userID uuid.UUID, since time.Time) (int, error) {
+	query := `SELECT COALESCE(SUM(weight), 0) FROM abuse_signals WHERE user_id = $1 AND created_at >= $2`
+	var score int
+	err := r.db.QueryRow(ctx, query, userID, since).Scan(&score)
+	return score, err
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/locolive/backend/internal/config"
+	"github.com/locolive/backend/internal/domain"
+	"go.uber.org/zap"
+)
+
+// anonymizedProfile is what other users see of a deleted account, e.g. in
+// their chats. Clearing the identifiers frees them for a new account.
+const anonymizedProfile = `name = 'Deleted user',
+	username = NULL, email = NULL, phone = NULL, google_id = NULL, apple_id = NULL, password_hash = NULL,
+	avatar_url = NULL, bio = NULL, gender = NULL, date_of_birth = NULL,
+	email_verified = FALSE, phone_verified = FALSE, verified_at = NULL`
+
+// PurgeDeletedUsers permanently removes accounts deleted before deletedBefore.
+// Their remaining data (messages, connections, notifications and so on) goes
+// with them through ON DELETE CASCADE. Accounts on legal hold are kept. Hold
+// records outlive the hold and block the delete, so accounts that were ever
+// held are only anonymized once released, as they were skipped at deletion.
+func (r *PostgresRepository) PurgeDeletedUsers(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM users
+		WHERE is_active = FALSE AND deleted_at < $1
+		AND NOT EXISTS (SELECT 1 FROM legal_holds lh WHERE lh.user_id = users.id)
+	`, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	purged := tag.RowsAffected()
+
+	tag, err = r.db.Exec(ctx, `
+		UPDATE users SET `+anonymizedProfile+`
+		WHERE is_active = FALSE AND deleted_at < $1 AND name <> 'Deleted user'
+		AND `+notOnLegalHold("users.id"), deletedBefore)
+	if err != nil {
+		return purged, err
+	}
+	return purged + tag.RowsAffected(), nil
+}
+
+// StartAccountPurgeWorker purges accounts past the deletion grace period on
+// every interval
+func (r *PostgresRepository) StartAccountPurgeWorker(ctx context.Context, cfg config.AccountDeletionConfig, jobs *domain.JobTracker, logger *zap.Logger) {
+	go func() {
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				jobs.Track(ctx, domain.JobAccountPurge, func(ctx context.Context) (int64, error) {
+					purged, err := r.PurgeDeletedUsers(ctx, time.Now().Add(-cfg.GracePeriod))
+					if err != nil {
+						logger.Error("failed to purge deleted accounts", zap.Error(err))
+					} else if purged > 0 {
+						logger.Info("purged deleted accounts", zap.Int64("count", purged))
+					}
+					return purged, err
+				})
+			}
+		}
+	}()
+}
@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/domain"
+)
+
+// connectionRankSQL ranks a connection status for merge conflicts; the
+// higher rank survives
+func connectionRankSQL(col string) string {
+	return `(CASE ` + col + ` WHEN 'blocked' THEN 3 WHEN 'accepted' THEN 2 WHEN 'pending' THEN 1 ELSE 0 END)`
+}
+
+// mergeIdentifiers are a user's sign-in identifiers, moved to the merge
+// target where it has none
+type mergeIdentifiers struct {
+	email, phone, googleID, appleID *string
+	emailVerified, phoneVerified    bool
+}
+
+// MergeAccounts moves source's data to target and deactivates source, all in
+// one transaction with the audit entry. Both users are locked first, so
+// concurrent merges or deletions of either wait.
+func (r *PostgresRepository) MergeAccounts(ctx context.Context, sourceID, targetID uuid.UUID, audit domain.AuditEntry) (*domain.AccountMergeResult, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, email, phone, google_id, apple_id, email_verified, phone_verified,
+			EXISTS (SELECT 1 FROM legal_holds WHERE user_id = users.id AND released_at IS NULL)
+		FROM users WHERE id = ANY($1) AND is_active = TRUE
+		ORDER BY id
+		FOR UPDATE
+	`, []uuid.UUID{sourceID, targetID})
+	if err != nil {
+		return nil, err
+	}
+	users := make(map[uuid.UUID]mergeIdentifiers, 2)
+	held := false
+	for rows.Next() {
+		var id uuid.UUID
+		var ids mergeIdentifiers
+		var onHold bool
+		if err := rows.Scan(&id, &ids.email, &ids.phone, &ids.googleID, &ids.appleID, &ids.emailVerified, &ids.phoneVerified, &onHold); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		users[id] = ids
+		held = held || onHold
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(users) != 2 {
+		return nil, domain.ErrUserNotFound
+	}
+	if held {
+		return nil, domain.ErrUserOnLegalHold
+	}
+
+	result := &domain.AccountMergeResult{SourceUserID: sourceID, TargetUserID: targetID, IdentifiersMoved: []string{}}
+	exec := func(dst *int64, query string, args ...any) error {
+		tag, err := tx.Exec(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		if dst != nil {
+			*dst += tag.RowsAffected()
+		}
+		return nil
+	}
+
+	steps := []struct {
+		dst   *int64
+		query string
+	}{
+		// Stories and what they hang off
+		{&result.StoriesMoved, `UPDATE stories SET user_id = $2 WHERE user_id = $1`},
+		{nil, `UPDATE story_archive SET user_id = $2 WHERE user_id = $1`},
+		{&result.CollectionsMoved, `UPDATE story_collections SET owner_id = $2 WHERE owner_id = $1`},
+		{nil, `
+			DELETE FROM collection_contributors s USING collection_contributors t
+			WHERE s.user_id = $1 AND t.user_id = $2 AND s.collection_id = t.collection_id
+		`},
+		{nil, `UPDATE collection_contributors SET user_id = $2 WHERE user_id = $1`},
+		{nil, `DELETE FROM story_views s USING story_views t WHERE s.viewer_id = $1 AND t.viewer_id = $2 AND s.story_id = t.story_id`},
+		{nil, `UPDATE story_views SET viewer_id = $2 WHERE viewer_id = $1`},
+		{nil, `DELETE FROM story_impressions s USING story_impressions t WHERE s.viewer_id = $1 AND t.viewer_id = $2 AND s.story_id = t.story_id`},
+		{nil, `UPDATE story_impressions SET viewer_id = $2 WHERE viewer_id = $1`},
+		{nil, `DELETE FROM saved_stories s USING saved_stories t WHERE s.user_id = $1 AND t.user_id = $2 AND s.story_id = t.story_id`},
+		{nil, `UPDATE saved_stories SET user_id = $2 WHERE user_id = $1`},
+
+		// Connections: the pair itself goes, then the weaker of each duplicate
+		{&result.ConnectionsDropped, `
+			DELETE FROM connections
+			WHERE (requester_id = $1 AND receiver_id = $2) OR (requester_id = $2 AND receiver_id = $1)
+		`},
+		{&result.ConnectionsDropped, `
+			DELETE FROM connections c
+			USING (
+				SELECT s.id AS source_row, t.id AS target_row,
+					` + connectionRankSQL("s.status") + ` > ` + connectionRankSQL("t.status") + ` AS source_wins
+				FROM connections s
+				JOIN connections t
+					ON (CASE WHEN s.requester_id = $1 THEN s.receiver_id ELSE s.requester_id END)
+					 = (CASE WHEN t.requester_id = $2 THEN t.receiver_id ELSE t.requester_id END)
+				WHERE $1 IN (s.requester_id, s.receiver_id) AND $2 IN (t.requester_id, t.receiver_id)
+			) d
+			WHERE c.id = CASE WHEN d.source_wins THEN d.target_row ELSE d.source_row END
+		`},
+		{&result.ConnectionsMoved, `
+			UPDATE connections SET
+				requester_id = CASE WHEN requester_id = $1 THEN $2 ELSE requester_id END,
+				receiver_id = CASE WHEN receiver_id = $1 THEN $2 ELSE receiver_id END,
+				updated_at = NOW()
+			WHERE $1 IN (requester_id, receiver_id)
+		`},
+
+		// Chats: their chat with each other goes; a direct chat both had with
+		// the same person stays target's, and source's becomes plain history. In a
+		// group both were in, target keeps the higher of the two roles.
+		{nil, `DELETE FROM chats WHERE direct_user_low = LEAST($1::uuid, $2::uuid) AND direct_user_high = GREATEST($1::uuid, $2::uuid)`},
+		{&result.ChatsKeptAsHistory, `
+			UPDATE chats s SET direct_user_low = NULL, direct_user_high = NULL
+			FROM chats t
+			WHERE $1 IN (s.direct_user_low, s.direct_user_high)
+			AND $2 IN (t.direct_user_low, t.direct_user_high)
+			AND (CASE WHEN s.direct_user_low = $1 THEN s.direct_user_high ELSE s.direct_user_low END)
+			  = (CASE WHEN t.direct_user_low = $2 THEN t.direct_user_high ELSE t.direct_user_low END)
+		`},
+		{nil, `
+			UPDATE chats SET
+				direct_user_low = LEAST(CASE WHEN direct_user_low = $1 THEN direct_user_high ELSE direct_user_low END, $2),
+				direct_user_high = GREATEST(CASE WHEN direct_user_low = $1 THEN direct_user_high ELSE direct_user_low END, $2)
+			WHERE $1 IN (direct_user_low, direct_user_high)
+		`},
+		{nil, `
+			UPDATE chat_participants t SET role = 'admin'
+			FROM chat_participants s
+			WHERE s.user_id = $1 AND t.user_id = $2 AND s.chat_id = t.chat_id AND s.role = 'admin'
+		`},
+		{nil, `
+			DELETE FROM chat_participants s USING chat_participants t
+			WHERE s.user_id = $1 AND t.user_id = $2 AND s.chat_id = t.chat_id
+		`},
+		{&result.ChatsMoved, `UPDATE chat_participants SET user_id = $2 WHERE user_id = $1`},
+		{&result.MessagesMoved, `UPDATE messages SET sender_id = $2 WHERE sender_id = $1`},
+		{nil, `DELETE FROM message_receipts WHERE (user_id = $1 AND sender_id = $2) OR (user_id = $2 AND sender_id = $1)`},
+		{nil, `DELETE FROM message_receipts s USING message_receipts t WHERE s.user_id = $1 AND t.user_id = $2 AND s.message_id = t.message_id`},
+		{nil, `UPDATE message_receipts SET user_id = $2 WHERE user_id = $1`},
+		{nil, `UPDATE message_receipts SET sender_id = $2 WHERE sender_id = $1`},
+		{nil, `UPDATE story_comments SET user_id = $2 WHERE user_id = $1`},
+		{nil, `UPDATE story_comments SET mention_ids = array_replace(mention_ids, $1, $2) WHERE $1 = ANY(mention_ids)`},
+		{nil, `
+			DELETE FROM story_reactions s USING story_reactions t
+			WHERE s.user_id = $1 AND t.user_id = $2 AND s.story_id = t.story_id
+		`},
+		// Reactions between the two would become reactions to one's own story
+		{nil, `
+			DELETE FROM story_reactions sr USING stories s
+			WHERE s.id = sr.story_id AND sr.user_id IN ($1, $2) AND s.user_id IN ($1, $2)
+		`},
+		{nil, `UPDATE story_reactions SET user_id = $2 WHERE user_id = $1`},
+
+		// Notifications, their delivery records and the activity stream
+		{&result.NotificationsMoved, `UPDATE notifications SET user_id = $2 WHERE user_id = $1`},
+		{nil, `UPDATE notification_deliveries SET user_id = $2 WHERE user_id = $1`},
+		{nil, `DELETE FROM activities WHERE (user_id = $1 AND actor_id = $2) OR (user_id = $2 AND actor_id = $1)`},
+		{nil, `UPDATE activities SET user_id = $2 WHERE user_id = $1`},
+		{nil, `UPDATE activities SET actor_id = $2 WHERE actor_id = $1`},
+
+		// Source is signed out everywhere
+		{&result.SourceSessionsEnded, `UPDATE sessions SET is_active = FALSE, fcm_token = NULL WHERE user_id = $1 AND is_active = TRUE`},
+		{nil, `UPDATE refresh_tokens SET revoked = TRUE, revoked_at = NOW() WHERE user_id = $1 AND revoked = FALSE`},
+	}
+	for _, step := range steps {
+		if err := exec(step.dst, step.query, sourceID, targetID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Sign-in identifiers move where target has none. Source gives them up
+	// first, as each is unique.
+	source, target := users[sourceID], users[targetID]
+	var moved mergeIdentifiers
+	if source.email != nil && target.email == nil {
+		moved.email, moved.emailVerified = source.email, source.emailVerified
+		result.IdentifiersMoved = append(result.IdentifiersMoved, "email")
+	}
+	if source.phone != nil && target.phone == nil {
+		moved.phone, moved.phoneVerified = source.phone, source.phoneVerified
+		result.IdentifiersMoved = append(result.IdentifiersMoved, "phone")
+	}
+	if source.googleID != nil && target.googleID == nil {
+		moved.googleID = source.googleID
+		result.IdentifiersMoved = append(result.IdentifiersMoved, "google")
+	}
+	if source.appleID != nil && target.appleID == nil {
+		moved.appleID = source.appleID
+		result.IdentifiersMoved = append(result.IdentifiersMoved, "apple")
+	}
+
+	err = exec(nil, `
+		UPDATE users SET
+			email = CASE WHEN $2 THEN NULL ELSE email END,
+			email_verified = CASE WHEN $2 THEN FALSE ELSE email_verified END,
+			phone = CASE WHEN $3 THEN NULL ELSE phone END,
+			phone_verified = CASE WHEN $3 THEN FALSE ELSE phone_verified END,
+			google_id = CASE WHEN $4 THEN NULL ELSE google_id END,
+			apple_id = CASE WHEN $5 THEN NULL ELSE apple_id END,
+			is_active = FALSE
+		WHERE id = $1
+	`, sourceID, moved.email != nil, moved.phone != nil, moved.googleID != nil, moved.appleID != nil)
+	if err != nil {
+		return nil, err
+	}
+	err = exec(nil, `
+		UPDATE users SET
+			email = COALESCE($2, email),
+			email_verified = CASE WHEN $2::text IS NOT NULL THEN $3 ELSE email_verified END,
+			phone = COALESCE($4, phone),
+			phone_verified = CASE WHEN $4::text IS NOT NULL THEN $5 ELSE phone_verified END,
+			google_id = COALESCE($6, google_id),
+			apple_id = COALESCE($7, apple_id)
+		WHERE id = $1
+	`, targetID, moved.email, moved.emailVerified, moved.phone, moved.phoneVerified, moved.googleID, moved.appleID)
+	if err != nil {
+		return nil, err
+	}
+
+	audit.Details = map[string]interface{}{
+		"target_user_id":        targetID,
+		"stories_moved":         result.StoriesMoved,
+		"collections_moved":     result.CollectionsMoved,
+		"chats_moved":           result.ChatsMoved,
+		"chats_kept_as_history": result.ChatsKeptAsHistory,
+		"messages_moved":        result.MessagesMoved,
+		"connections_moved":     result.ConnectionsMoved,
+		"connections_dropped":   result.ConnectionsDropped,
+		"notifications_moved":   result.NotificationsMoved,
+		"identifiers_moved":     result.IdentifiersMoved,
+	}
+	if err := insertAudit(ctx, tx, audit); err != nil {
+		return nil, err
+	}
+	return result, tx.Commit(ctx)
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/domain"
+)
+
+// RecordActivity adds an activity entry unless the actor did the same thing
+// to the same target since the given time
+func (r *PostgresRepository) RecordActivity(ctx context.Context, userID, actorID uuid.UUID, kind string, targetID *uuid.UUID, since time.Time) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO activities (user_id, actor_id, kind, target_id)
+		SELECT $1, $2, $3, $4
+		WHERE NOT EXISTS (
+			SELECT 1 FROM activities
+			WHERE user_id = $1 AND actor_id = $2 AND kind = $3
+			AND target_id IS NOT DISTINCT FROM $4
+			AND created_at > $5
+		)
+	`, userID, actorID, kind, targetID, since)
+	return err
+}
+
+// GetActivities lists a user's activity newest first, with each actor
+func (r *PostgresRepository) GetActivities(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*domain.Activity, error) {
+	query := `
+		SELECT a.id, a.kind, a.target_id, a.read_at IS NOT NULL, a.created_at,
+		       u.id, u.name, COALESCE(u.username, ''), COALESCE(u.avatar_url, ''), u.verified_at IS NOT NULL, u.created_at
+		FROM activities a
+		JOIN users u ON u.id = a.actor_id
+		WHERE a.user_id = $1 AND u.is_active = TRUE
+		AND ` + notBlockedWith("a.actor_id", "$1") + `
+		ORDER BY a.created_at DESC, a.id
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.Query(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var activities []*domain.Activity
+	for rows.Next() {
+		var a domain.Activity
+		var u domain.UserResponse
+		err := rows.Scan(&a.ID, &a.Kind, &a.TargetID, &a.IsRead, &a.CreatedAt,
+			&u.ID, &u.Name, &u.Username, &u.AvatarURL, &u.Verified, &u.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		a.Actor = &u
+		activities = append(activities, &a)
+	}
+	return activities, rows.Err()
+}
+
+// CountUnreadActivities counts the unread entries GetActivities would list
+func (r *PostgresRepository) CountUnreadActivities(ctx context.Context, userID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM activities a
+		JOIN users u ON u.id = a.actor_id
+		WHERE a.user_id = $1 AND a.read_at IS NULL AND u.is_active = TRUE
+		AND `+notBlockedWith("a.actor_id", "$1"),
+		userID).Scan(&count)
+	return count, err
+}
+
+// MarkActivitiesRead marks all of a user's unread activity read
+func (r *PostgresRepository) MarkActivitiesRead(ctx context.Context, userID uuid.UUID) (int, error) {
+	tag, err := r.db.Exec(ctx, `UPDATE activities SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL`, userID)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
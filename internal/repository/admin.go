@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/locolive/backend/internal/domain"
+)
+
+const adminUserColumns = `id, email, phone, name, username, avatar_url, bio, gender, date_of_birth, visibility, country_code, google_id, email_verified, phone_verified, is_active, verified_at, created_at, updated_at, role`
+
+func scanAdminUser(row pgx.Row) (*domain.User, domain.Role, error) {
+	var user domain.User
+	var role domain.Role
+	err := row.Scan(
+		&user.ID,
+		&user.Email,
+		&user.Phone,
+		&user.Name,
+		&user.Username,
+		&user.AvatarURL,
+		&user.Bio,
+		&user.Gender,
+		&user.DateOfBirth,
+		&user.Visibility,
+		&user.CountryCode,
+		&user.GoogleID,
+		&user.EmailVerified,
+		&user.PhoneVerified,
+		&user.IsActive,
+		&user.VerifiedAt,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&role,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, "", domain.ErrUserNotFound
+		}
+		return nil, "", err
+	}
+	return &user, role, nil
+}
+
+// GetUserRole returns an active user's role
+func (r *PostgresRepository) GetUserRole(ctx context.Context, userID uuid.UUID) (domain.Role, error) {
+	var role domain.Role
+	err := r.db.QueryRow(ctx, `SELECT role FROM users WHERE id = $1 AND is_active = TRUE`, userID).Scan(&role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", domain.ErrUserNotFound
+	}
+	return role, err
+}
+
+// GetUserForAdmin returns a user and their role, active or not
+func (r *PostgresRepository) GetUserForAdmin(ctx context.Context, userID uuid.UUID) (*domain.User, domain.Role, error) {
+	return scanAdminUser(r.db.QueryRow(ctx, `SELECT `+adminUserColumns+` FROM users WHERE id = $1`, userID))
+}
+
+// FindUserForAdmin finds a user by the one identifier set in lookup
+func (r *PostgresRepository) FindUserForAdmin(ctx context.Context, lookup domain.UserLookup) (*domain.User, domain.Role, error) {
+	query := `
+		SELECT ` + adminUserColumns + ` FROM users
+		WHERE ($1 <> '' AND email = $1) OR ($2 <> '' AND phone = $2) OR ($3 <> '' AND username = $3)
+		LIMIT 1
+	`
+	return scanAdminUser(r.db.QueryRow(ctx, query, lookup.Email, lookup.Phone, lookup.Username))
+}
+
+// SetUserActive flips a user's active flag, ending their sessions when
+// deactivating, and records the audit entry in the same transaction
+func (r *PostgresRepository) SetUserActive(ctx context.Context, userID uuid.UUID, active bool, audit domain.AuditEntry) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `UPDATE users SET is_active = $2, updated_at = NOW() WHERE id = $1`, userID, active)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	if !active {
+		if _, err := tx.Exec(ctx, `UPDATE sessions SET is_active = FALSE WHERE user_id = $1 AND is_active = TRUE`, userID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `UPDATE refresh_tokens SET revoked = TRUE, revoked_at = NOW() WHERE user_id = $1 AND revoked = FALSE`, userID); err != nil {
+			return err
+		}
+	}
+
+	if err := insertAudit(ctx, tx, audit); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// SetUserRole changes a user's role and records the audit entry in the same transaction
+func (r *PostgresRepository) SetUserRole(ctx context.Context, userID uuid.UUID, role domain.Role, audit domain.AuditEntry) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `UPDATE users SET role = $2, updated_at = NOW() WHERE id = $1`, userID, role)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	if err := insertAudit(ctx, tx, audit); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// GetActiveUserIDs pages through active users in ID order
+func (r *PostgresRepository) GetActiveUserIDs(ctx context.Context, after uuid.UUID, limit int) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id FROM users WHERE is_active = TRUE AND id > $1 ORDER BY id LIMIT $2
+	`, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
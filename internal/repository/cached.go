@@ -0,0 +1,408 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/cache"
+	"github.com/locolive/backend/internal/config"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/metrics"
+	"go.uber.org/zap"
+)
+
+// CachedRepository decorates PostgresRepository with a read-through cache for
+// user and session lookups and each user's set of connections. Every write
+// that can change a cached row deletes its key so the next read repopulates
+// it. Nearby story queries are cached too, but only expire.
+type CachedRepository struct {
+	*PostgresRepository
+	cache    cache.Cache
+	sets     cache.SetStore
+	cfg      config.CacheConfig
+	logger   *zap.Logger
+	requests *metrics.CounterVec
+}
+
+// NewCachedRepository wraps repo with cache
+func NewCachedRepository(repo *PostgresRepository, c cache.Cache, sets cache.SetStore, cfg config.CacheConfig, registry *metrics.Registry, logger *zap.Logger) *CachedRepository {
+	return &CachedRepository{
+		PostgresRepository: repo,
+		cache:              c,
+		sets:               sets,
+		cfg:                cfg,
+		logger:             logger,
+		requests:           registry.Counter("cache_requests_total", "Cache lookups by result", "entity", "result"),
+	}
+}
+
+func userCacheKey(id uuid.UUID) string    { return "user:" + id.String() }
+func sessionCacheKey(id uuid.UUID) string { return "session:" + id.String() }
+
+func connectionSetKey(id uuid.UUID) string { return "connections:" + id.String() }
+
+// userAndSessionKeys are the keys of a user and the given sessions of theirs
+func userAndSessionKeys(userID uuid.UUID, sessionIDs []uuid.UUID) []string {
+	keys := []string{userCacheKey(userID)}
+	for _, id := range sessionIDs {
+		keys = append(keys, sessionCacheKey(id))
+	}
+	return keys
+}
+
+func storyGeoCacheKey(cell string, radius float64, limit, offset int) string {
+	return fmt.Sprintf("stories:geo:%s:%d:%d:%d", cell, int(radius), limit, offset)
+}
+
+// GetUserByID retrieves an active user, preferring the cache
+func (r *CachedRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	var user domain.User
+	if r.load(ctx, "user", userCacheKey(id), &user) {
+		return &user, nil
+	}
+
+	u, err := r.PostgresRepository.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	r.store(ctx, userCacheKey(id), u, r.cfg.UserTTL)
+	return u, nil
+}
+
+// GetSessionByID retrieves an active session, preferring the cache
+func (r *CachedRepository) GetSessionByID(ctx context.Context, id uuid.UUID) (*domain.Session, error) {
+	var session domain.Session
+	if r.load(ctx, "session", sessionCacheKey(id), &session) {
+		return &session, nil
+	}
+
+	s, err := r.PostgresRepository.GetSessionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	r.store(ctx, sessionCacheKey(id), s, r.cfg.SessionTTL)
+	return s, nil
+}
+
+// UpdateUser updates a user profile and invalidates its cache entry
+func (r *CachedRepository) UpdateUser(ctx context.Context, userID uuid.UUID, params domain.UpdateUserParams) (*domain.User, error) {
+	user, err := r.PostgresRepository.UpdateUser(ctx, userID, params)
+	r.invalidate(ctx, userCacheKey(userID))
+	return user, err
+}
+
+// UpdateUserEmail updates a user's email and invalidates its cache entry
+func (r *CachedRepository) UpdateUserEmail(ctx context.Context, userID uuid.UUID, email string) error {
+	err := r.PostgresRepository.UpdateUserEmail(ctx, userID, email)
+	r.invalidate(ctx, userCacheKey(userID))
+	return err
+}
+
+// MarkEmailVerified verifies a user's email and invalidates their cache entry
+func (r *CachedRepository) MarkEmailVerified(ctx context.Context, token *domain.EmailVerificationToken) (bool, error) {
+	verified, err := r.PostgresRepository.MarkEmailVerified(ctx, token)
+	r.invalidate(ctx, userCacheKey(token.UserID))
+	return verified, err
+}
+
+// SetUserPhoneVerified sets a user's verified phone and invalidates their cache entry
+func (r *CachedRepository) SetUserPhoneVerified(ctx context.Context, userID uuid.UUID, phone string) (*domain.User, error) {
+	user, err := r.PostgresRepository.SetUserPhoneVerified(ctx, userID, phone)
+	r.invalidate(ctx, userCacheKey(userID))
+	return user, err
+}
+
+// LinkGoogleAccount links a Google account and invalidates the user's cache entry
+func (r *CachedRepository) LinkGoogleAccount(ctx context.Context, userID uuid.UUID, googleID string) (*domain.User, error) {
+	user, err := r.PostgresRepository.LinkGoogleAccount(ctx, userID, googleID)
+	r.invalidate(ctx, userCacheKey(userID))
+	return user, err
+}
+
+// LinkAppleAccount links an Apple account and invalidates the user's cache entry
+func (r *CachedRepository) LinkAppleAccount(ctx context.Context, userID uuid.UUID, appleID string) (*domain.User, error) {
+	user, err := r.PostgresRepository.LinkAppleAccount(ctx, userID, appleID)
+	r.invalidate(ctx, userCacheKey(userID))
+	return user, err
+}
+
+// DeleteUser soft deletes a user and invalidates the user and all their sessions
+func (r *CachedRepository) DeleteUser(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	sessionIDs, err := r.PostgresRepository.GetActiveSessionIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaURLs, err := r.PostgresRepository.DeleteUser(ctx, userID)
+	keys := []string{userCacheKey(userID)}
+	for _, id := range sessionIDs {
+		keys = append(keys, sessionCacheKey(id))
+	}
+	r.invalidate(ctx, keys...)
+	return mediaURLs, err
+}
+
+// DeactivateSession deactivates a session and invalidates its cache entry
+func (r *CachedRepository) DeactivateSession(ctx context.Context, id uuid.UUID) error {
+	err := r.PostgresRepository.DeactivateSession(ctx, id)
+	r.invalidate(ctx, sessionCacheKey(id))
+	return err
+}
+
+// DeactivateUserSessions deactivates all of a user's sessions and invalidates them
+func (r *CachedRepository) DeactivateUserSessions(ctx context.Context, userID uuid.UUID) error {
+	sessionIDs, err := r.PostgresRepository.GetActiveSessionIDs(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	err = r.PostgresRepository.DeactivateUserSessions(ctx, userID)
+	keys := make([]string, 0, len(sessionIDs))
+	for _, id := range sessionIDs {
+		keys = append(keys, sessionCacheKey(id))
+	}
+	r.invalidate(ctx, keys...)
+	return err
+}
+
+// EvictSessions ends a user's oldest sessions beyond the cap and invalidates them
+func (r *CachedRepository) EvictSessions(ctx context.Context, userID, keepID uuid.UUID, keep int) ([]*domain.Session, error) {
+	sessions, err := r.PostgresRepository.EvictSessions(ctx, userID, keepID, keep)
+	keys := make([]string, 0, len(sessions))
+	for _, session := range sessions {
+		keys = append(keys, sessionCacheKey(session.ID))
+	}
+	r.invalidate(ctx, keys...)
+	return sessions, err
+}
+
+// SetUserActive activates or deactivates a user and invalidates the user and,
+// when deactivating, their sessions
+func (r *CachedRepository) SetUserActive(ctx context.Context, userID uuid.UUID, active bool, audit domain.AuditEntry) error {
+	sessionIDs, err := r.PostgresRepository.GetActiveSessionIDs(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	err = r.PostgresRepository.SetUserActive(ctx, userID, active, audit)
+	r.invalidate(ctx, userAndSessionKeys(userID, sessionIDs)...)
+	return err
+}
+
+// MergeAccounts merges source into target and invalidates both users,
+// source's sessions and the connection sets of everyone involved
+func (r *CachedRepository) MergeAccounts(ctx context.Context, sourceID, targetID uuid.UUID, audit domain.AuditEntry) (*domain.AccountMergeResult, error) {
+	sessionIDs, err := r.PostgresRepository.GetActiveSessionIDs(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Source's connections move to target, changing their sets too
+	connectedIDs, err := r.PostgresRepository.GetConnectedUserIDs(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.PostgresRepository.MergeAccounts(ctx, sourceID, targetID, audit)
+	keys := []string{userCacheKey(sourceID), userCacheKey(targetID), connectionSetKey(sourceID), connectionSetKey(targetID)}
+	for _, id := range sessionIDs {
+		keys = append(keys, sessionCacheKey(id))
+	}
+	for _, id := range connectedIDs {
+		keys = append(keys, connectionSetKey(id))
+	}
+	r.invalidate(ctx, keys...)
+	return result, err
+}
+
+// RectifyUserPII corrects a user's PII and invalidates the user and their sessions
+func (r *CachedRepository) RectifyUserPII(ctx context.Context, userID uuid.UUID, params domain.RectifyUserParams, audit domain.AuditEntry) (*domain.User, error) {
+	sessionIDs, err := r.PostgresRepository.GetActiveSessionIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := r.PostgresRepository.RectifyUserPII(ctx, userID, params, audit)
+	r.invalidate(ctx, userAndSessionKeys(userID, sessionIDs)...)
+	return user, err
+}
+
+// PseudonymizeUser replaces a user's PII and invalidates the user and their
+// sessions, whose cached copies still hold it
+func (r *CachedRepository) PseudonymizeUser(ctx context.Context, userID uuid.UUID, audit domain.AuditEntry) (*domain.PseudonymizationResult, error) {
+	sessionIDs, err := r.PostgresRepository.GetActiveSessionIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.PostgresRepository.PseudonymizeUser(ctx, userID, audit)
+	r.invalidate(ctx, userAndSessionKeys(userID, sessionIDs)...)
+	return result, err
+}
+
+// ResolveVerificationRequest reviews a request and invalidates the applicant,
+// whose badge may have changed
+func (r *CachedRepository) ResolveVerificationRequest(ctx context.Context, requestID, reviewerID uuid.UUID, status domain.VerificationStatus, note *string, audit domain.AuditEntry) (*domain.VerificationRequest, error) {
+	req, err := r.PostgresRepository.ResolveVerificationRequest(ctx, requestID, reviewerID, status, note, audit)
+	if err == nil {
+		r.invalidate(ctx, userCacheKey(req.UserID))
+	}
+	return req, err
+}
+
+// RevokeVerification removes a user's badge and invalidates the user
+func (r *CachedRepository) RevokeVerification(ctx context.Context, userID uuid.UUID, audit domain.AuditEntry) error {
+	err := r.PostgresRepository.RevokeVerification(ctx, userID, audit)
+	r.invalidate(ctx, userCacheKey(userID))
+	return err
+}
+
+// GetConnectedAmong answers from the user's cached connection set, loading
+// all their connections into it on a miss
+func (r *CachedRepository) GetConnectedAmong(ctx context.Context, userID uuid.UUID, candidateIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	connected := make(map[uuid.UUID]bool)
+	if len(candidateIDs) == 0 {
+		return connected, nil
+	}
+
+	key := connectionSetKey(userID)
+	members := make([]string, len(candidateIDs))
+	for i, id := range candidateIDs {
+		members[i] = id.String()
+	}
+	found, err := r.sets.AreMembers(ctx, key, members)
+	if err == nil {
+		r.requests.Inc("connections", "hit")
+		for i, id := range candidateIDs {
+			if found[i] {
+				connected[id] = true
+			}
+		}
+		return connected, nil
+	}
+	if !errors.Is(err, cache.ErrMiss) {
+		r.logger.Warn("cache read failed", zap.String("key", key), zap.Error(err))
+	}
+	r.requests.Inc("connections", "miss")
+
+	ids, err := r.PostgresRepository.GetConnectedUserIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	all := make([]string, len(ids))
+	for i, id := range ids {
+		all[i] = id.String()
+	}
+	if err := r.sets.ReplaceSet(ctx, key, all, r.cfg.ConnectionsTTL); err != nil {
+		r.logger.Warn("cache write failed", zap.String("key", key), zap.Error(err))
+	}
+
+	candidates := make(map[uuid.UUID]bool, len(candidateIDs))
+	for _, id := range candidateIDs {
+		candidates[id] = true
+	}
+	for _, id := range ids {
+		if candidates[id] {
+			connected[id] = true
+		}
+	}
+	return connected, nil
+}
+
+// TransitionConnection changes a pair's connection and invalidates both
+// users' connection sets
+func (r *CachedRepository) TransitionConnection(ctx context.Context, userA, userB uuid.UUID, decide func(current *domain.Connection) (*domain.ConnectionUpdate, error)) (*domain.Connection, error) {
+	conn, err := r.PostgresRepository.TransitionConnection(ctx, userA, userB, decide)
+	r.invalidate(ctx, connectionSetKey(userA), connectionSetKey(userB))
+	return conn, err
+}
+
+// UpdateConnectionStatus changes a connection's status and invalidates both
+// users' connection sets
+func (r *CachedRepository) UpdateConnectionStatus(ctx context.Context, connectionID uuid.UUID, status domain.ConnectionStatus) (*domain.Connection, error) {
+	conn, err := r.PostgresRepository.UpdateConnectionStatus(ctx, connectionID, status)
+	if err == nil {
+		r.invalidate(ctx, connectionSetKey(conn.RequesterID), connectionSetKey(conn.ReceiverID))
+	}
+	return conn, err
+}
+
+// DeleteConnection deletes a connection and invalidates both users'
+// connection sets
+func (r *CachedRepository) DeleteConnection(ctx context.Context, connectionID uuid.UUID) error {
+	conn, err := r.PostgresRepository.GetConnectionByID(ctx, connectionID)
+	if err != nil {
+		if errors.Is(err, domain.ErrConnectionNotFound) {
+			return r.PostgresRepository.DeleteConnection(ctx, connectionID)
+		}
+		return err
+	}
+
+	err = r.PostgresRepository.DeleteConnection(ctx, connectionID)
+	r.invalidate(ctx, connectionSetKey(conn.RequesterID), connectionSetKey(conn.ReceiverID))
+	return err
+}
+
+// GetStoriesByLocation answers nearby story queries from the cache, keyed by
+// the geohash cell and radius bucket the query snaps to. A miss queries
+// around the cell's center, so the result is the same for everyone in the
+// cell. Entries are never invalidated: new, expired and hidden stories show
+// up once StoryGeoTTL has passed.
+func (r *CachedRepository) GetStoriesByLocation(ctx context.Context, lat, lng, radius float64, limit, offset int) ([]*domain.Story, error) {
+	area := domain.SnapFeedArea(lat, lng, radius)
+	key := storyGeoCacheKey(area.Cell, area.Radius, limit, offset)
+
+	var stories []*domain.Story
+	if r.load(ctx, "story_geo", key, &stories) {
+		return stories, nil
+	}
+
+	stories, err := r.PostgresRepository.GetStoriesByLocation(ctx, area.Lat, area.Lng, area.Radius, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	r.store(ctx, key, stories, r.cfg.StoryGeoTTL)
+	return stories, nil
+}
+
+// load decodes key into dst, reporting whether it was a hit. Cache errors are
+// treated as misses so an unavailable cache only costs latency.
+func (r *CachedRepository) load(ctx context.Context, entity, key string, dst interface{}) bool {
+	data, err := r.cache.Get(ctx, key)
+	if err == nil {
+		err = gob.NewDecoder(bytes.NewReader(data)).Decode(dst)
+	}
+	if err != nil {
+		if !errors.Is(err, cache.ErrMiss) {
+			r.logger.Warn("cache read failed", zap.String("key", key), zap.Error(err))
+		}
+		r.requests.Inc(entity, "miss")
+		return false
+	}
+	r.requests.Inc(entity, "hit")
+	return true
+}
+
+// store encodes with gob rather than JSON so fields hidden from API responses,
+// such as User.GoogleID, survive the round trip
+func (r *CachedRepository) store(ctx context.Context, key string, value interface{}, ttl time.Duration) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+		r.logger.Warn("cache encode failed", zap.String("key", key), zap.Error(err))
+		return
+	}
+	if err := r.cache.Set(ctx, key, buf.Bytes(), ttl); err != nil {
+		r.logger.Warn("cache write failed", zap.String("key", key), zap.Error(err))
+	}
+}
+
+func (r *CachedRepository) invalidate(ctx context.Context, keys ...string) {
+	if err := r.cache.Delete(ctx, keys...); err != nil {
+		r.logger.Error("cache invalidation failed", zap.Strings("keys", keys), zap.Error(err))
+	}
+}
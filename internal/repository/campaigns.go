@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/domain"
+)
+
+// campaignRules maps each rule to its audience condition and rule-specific count.
+// $2 is the rule's threshold cutoff (now - threshold).
+var campaignRules = map[domain.CampaignRule]struct {
+	where string
+	count string
+}{
+	domain.CampaignRuleInactive: {
+		where: `u.created_at < $2 AND NOT EXISTS (
+			SELECT 1 FROM sessions s WHERE s.user_id = u.id AND s.last_activity_at >= $2
+		)`,
+		count: `0`,
+	},
+	domain.CampaignRuleNoStory: {
+		where: `u.created_at < $2 AND NOT EXISTS (
+			SELECT 1 FROM user_activity_daily a WHERE a.user_id = u.id
+		)`,
+		count: `0`,
+	},
+	domain.CampaignRulePendingRequests: {
+		where: `EXISTS (
+			SELECT 1 FROM connections c
+			WHERE c.receiver_id = u.id AND c.status = 'pending' AND c.updated_at < $2
+		)`,
+		count: `(SELECT COUNT(*) FROM connections c WHERE c.receiver_id = u.id AND c.status = 'pending')`,
+	},
+}
+
+// GetCampaignAudience returns the users a campaign rule matches, excluding
+// opted-out users and those within the cooldown or frequency cap
+func (r *PostgresRepository) GetCampaignAudience(ctx context.Context, q domain.AudienceQuery) ([]domain.CampaignTarget, error) {
+	rule, ok := campaignRules[q.Rule]
+	if !ok {
+		return nil, domain.ErrUnknownCampaign
+	}
+
+	query := `
+		SELECT u.id, u.name, CASE WHEN u.email_verified THEN u.email END, ` + rule.count + `
+		FROM users u
+		WHERE u.is_active = TRUE AND u.id > $5
+		AND ` + rule.where + `
+		AND NOT EXISTS (
+			SELECT 1 FROM campaign_opt_outs o
+			WHERE o.user_id = u.id AND o.campaign IN ($1, '*')
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM campaign_sends cs
+			WHERE cs.user_id = u.id
+			AND (cs.sent_at >= $4 OR (cs.campaign = $1 AND cs.sent_at >= $3))
+		)
+		ORDER BY u.id
+		LIMIT $6
+	`
+	rows, err := r.db.Query(ctx, query,
+		q.Campaign,
+		q.Now.Add(-q.Threshold),
+		q.Now.Add(-q.Cooldown),
+		q.Now.Add(-q.FrequencyCap),
+		q.After,
+		q.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var targets []domain.CampaignTarget
+	for rows.Next() {
+		var t domain.CampaignTarget
+		if err := rows.Scan(&t.UserID, &t.Name, &t.Email, &t.Count); err != nil {
+			return nil, err
+		}
+		targets = append(targets, t)
+	}
+	return targets, rows.Err()
+}
+
+// RecordCampaignSend logs a campaign message and returns its ID
+func (r *PostgresRepository) RecordCampaignSend(ctx context.Context, campaign domain.CampaignID, userID uuid.UUID, channel domain.CampaignChannel) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO campaign_sends (campaign, user_id, channel)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`, campaign, userID, channel).Scan(&id)
+	return id, err
+}
+
+// GetCampaignStats aggregates sends, opens and conversions per campaign since the given time
+func (r *PostgresRepository) GetCampaignStats(ctx context.Context, since time.Time) ([]*domain.CampaignStats, error) {
+	query := `
+		SELECT cs.campaign,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE EXISTS (
+				SELECT 1 FROM notifications n
+				WHERE n.user_id = cs.user_id AND n.type = 'campaign' AND n.created_at >= cs.sent_at
+				AND n.is_read AND n.data->>'send_id' = cs.id::text
+			)),
+			COUNT(*) FILTER (WHERE EXISTS (
+				SELECT 1 FROM sessions s
+				WHERE s.user_id = cs.user_id AND s.last_activity_at > cs.sent_at
+			))
+		FROM campaign_sends cs
+		WHERE cs.sent_at >= $1
+		GROUP BY cs.campaign
+		ORDER BY cs.campaign
+	`
+	rows, err := r.db.Query(ctx, query, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []*domain.CampaignStats
+	for rows.Next() {
+		var s domain.CampaignStats
+		if err := rows.Scan(&s.Campaign, &s.Sent, &s.Opened, &s.Converted); err != nil {
+			return nil, err
+		}
+		stats = append(stats, &s)
+	}
+	return stats, rows.Err()
+}
+
+// GetCampaignOptOuts returns the campaigns a user has opted out of
+func (r *PostgresRepository) GetCampaignOptOuts(ctx context.Context, userID uuid.UUID) ([]domain.CampaignID, error) {
+	rows, err := r.db.Query(ctx, `SELECT campaign FROM campaign_opt_outs WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []domain.CampaignID
+	for rows.Next() {
+		var id domain.CampaignID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// SetCampaignOptOut adds or removes a user's opt-out for a campaign
+func (r *PostgresRepository) SetCampaignOptOut(ctx context.Context, userID uuid.UUID, campaign domain.CampaignID, optedOut bool) error {
+	if optedOut {
+		_, err := r.db.Exec(ctx, `
+			INSERT INTO campaign_opt_outs (user_id, campaign)
+			VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+		`, userID, campaign)
+		return err
+	}
+	_, err := r.db.Exec(ctx, `DELETE FROM campaign_opt_outs WHERE user_id = $1 AND campaign = $2`, userID, campaign)
+	return err
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/locolive/backend/internal/domain"
+)
+
+const cardColumns = `c.id, c.kind, c.title, c.body, c.image_url, c.action_label, c.action_url, c.segment, c.platform, c.priority, c.starts_at, c.ends_at, c.active, c.created_by, c.created_at`
+
+func scanCard(row pgx.Row) (*domain.Card, error) {
+	var c domain.Card
+	err := row.Scan(&c.ID, &c.Kind, &c.Title, &c.Body, &c.ImageURL, &c.ActionLabel, &c.ActionURL,
+		&c.Segment, &c.Platform, &c.Priority, &c.StartsAt, &c.EndsAt, &c.Active, &c.CreatedBy, &c.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrCardNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func scanCards(rows pgx.Rows) ([]*domain.Card, error) {
+	defer rows.Close()
+
+	var cards []*domain.Card
+	for rows.Next() {
+		c, err := scanCard(rows)
+		if err != nil {
+			return nil, err
+		}
+		cards = append(cards, c)
+	}
+	return cards, rows.Err()
+}
+
+// CreateCard inserts a card
+func (r *PostgresRepository) CreateCard(ctx context.Context, params domain.CreateCardParams) (*domain.Card, error) {
+	return scanCard(r.db.QueryRow(ctx, `
+		INSERT INTO cards AS c (kind, title, body, image_url, action_label, action_url, segment, platform, priority, starts_at, ends_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, COALESCE($10, NOW()), $11, $12)
+		RETURNING `+cardColumns,
+		params.Kind, params.Title, params.Body, params.ImageURL, params.ActionLabel, params.ActionURL,
+		params.Segment, params.Platform, params.Priority, params.StartsAt, params.EndsAt, params.CreatedBy,
+	))
+}
+
+// GetCards lists cards, newest first
+func (r *PostgresRepository) GetCards(ctx context.Context, activeOnly bool, limit, offset int) ([]*domain.Card, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+cardColumns+`
+		FROM cards c
+		WHERE NOT $1 OR c.active
+		ORDER BY c.created_at DESC
+		LIMIT $2 OFFSET $3
+	`, activeOnly, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return scanCards(rows)
+}
+
+// DeactivateCard stops a card from being served
+func (r *PostgresRepository) DeactivateCard(ctx context.Context, cardID uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `UPDATE cards SET active = FALSE WHERE id = $1`, cardID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrCardNotFound
+	}
+	return nil
+}
+
+// GetCardsForUser returns live cards the user hasn't dismissed whose segment
+// and platform match, highest priority first
+func (r *PostgresRepository) GetCardsForUser(ctx context.Context, userID uuid.UUID, platform domain.Platform, limit int) ([]*domain.Card, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+cardColumns+`
+		FROM cards c
+		JOIN users u ON u.id = $1
+		WHERE c.active
+		AND c.starts_at <= NOW()
+		AND (c.ends_at IS NULL OR c.ends_at > NOW())
+		AND (c.platform = '' OR c.platform = $2)
+		AND NOT EXISTS (SELECT 1 FROM card_dismissals d WHERE d.card_id = c.id AND d.user_id = $1)
+		AND CASE c.segment
+			WHEN 'all' THEN TRUE
+			WHEN 'new_users' THEN u.created_at > $3
+			WHEN 'no_stories' THEN NOT EXISTS (SELECT 1 FROM stories s WHERE s.user_id = $1)
+				AND NOT EXISTS (SELECT 1 FROM story_archive a WHERE a.user_id = $1)
+			WHEN 'no_connections' THEN NOT EXISTS (
+				SELECT 1 FROM connections x
+				WHERE (x.requester_id = $1 OR x.receiver_id = $1) AND x.status = 'accepted'
+			)
+			ELSE FALSE
+		END
+		ORDER BY c.priority DESC, c.created_at DESC
+		LIMIT $4
+	`, userID, platform, time.Now().Add(-domain.NewUserWindow), limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanCards(rows)
+}
+
+// DismissCard records that the user dismissed a card; dismissing twice is a no-op
+func (r *PostgresRepository) DismissCard(ctx context.Context, userID, cardID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO card_dismissals (card_id, user_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, cardID, userID)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+		return domain.ErrCardNotFound
+	}
+	return err
+}
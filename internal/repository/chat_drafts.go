@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/locolive/backend/internal/domain"
+)
+
+// SetChatDraft replaces the user's draft for a chat, sealing it like message
+// content when encryption is enabled. Empty content clears it.
+func (r *PostgresRepository) SetChatDraft(ctx context.Context, chatID, userID uuid.UUID, content string) (*domain.ChatDraft, error) {
+	var plaintext *string
+	var ciphertext []byte
+	var keyVersion *int
+	if content != "" {
+		plaintext = &content
+		if r.messageKeys != nil {
+			sealed, version, err := r.sealMessage(ctx, r.db, chatID, content)
+			if err != nil {
+				return nil, err
+			}
+			plaintext, ciphertext, keyVersion = nil, sealed, &version
+		}
+	}
+
+	draft := domain.ChatDraft{ChatID: chatID, Content: content}
+	err := r.db.QueryRow(ctx, `
+		UPDATE chat_participants
+		SET draft = $3, draft_ciphertext = $4, draft_key_version = $5, draft_updated_at = NOW()
+		WHERE chat_id = $1 AND user_id = $2
+		RETURNING draft_updated_at
+	`, chatID, userID, plaintext, ciphertext, keyVersion).Scan(&draft.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotChatParticipant
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &draft, nil
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/locolive/backend/internal/domain"
+)
+
+// unwipedMessage matches tombstones whose content or media is still stored,
+// as in idx_messages_unwiped
+const unwipedMessage = `deleted_at IS NOT NULL
+	AND (content <> '' OR content_ciphertext IS NOT NULL OR shared_story_id IS NOT NULL OR media_url IS NOT NULL)`
+
+// wipeDeletedMessagesQuery clears what tombstones said, returning the voice
+// notes they held. Chats with anyone on legal hold, and messages sent by
+// someone on hold, are skipped until the hold is released. $1 limits it to
+// one chat, or is NULL for all of them.
+var wipeDeletedMessagesQuery = `
+	UPDATE messages m SET content = '', content_ciphertext = NULL, key_version = NULL,
+		shared_story_id = NULL, shared_story = NULL,
+		media_url = NULL, media_type = NULL, media_duration_ms = NULL, media_size_bytes = NULL
+	FROM (
+		SELECT id, created_at, media_url FROM messages
+		WHERE ($1::uuid IS NULL OR chat_id = $1) AND ` + unwipedMessage + `
+			AND ` + notOnLegalHold("messages.sender_id") + `
+			AND NOT EXISTS (
+				SELECT 1 FROM chat_participants cp
+				WHERE cp.chat_id = messages.chat_id AND NOT ` + notOnLegalHold("cp.user_id") + `
+			)
+		FOR UPDATE
+	) old
+	WHERE m.id = old.id AND m.created_at = old.created_at
+	RETURNING old.media_url
+`
+
+// GetChatClearedAt returns when the user cleared the chat for themselves,
+// nil if they never have
+func (r *PostgresRepository) GetChatClearedAt(ctx context.Context, chatID, userID uuid.UUID) (*time.Time, error) {
+	var participant bool
+	var clearedAt *time.Time
+	err := r.db.QueryRow(ctx, `
+		SELECT cp.user_id IS NOT NULL, cp.cleared_at
+		FROM chats c
+		LEFT JOIN chat_participants cp ON cp.chat_id = c.id AND cp.user_id = $2
+		WHERE c.id = $1
+	`, chatID, userID).Scan(&participant, &clearedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrChatNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !participant {
+		return nil, domain.ErrNotChatParticipant
+	}
+	return clearedAt, nil
+}
+
+func (r *PostgresRepository) ClearChatHistory(ctx context.Context, chatID, userID uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `UPDATE chat_participants SET cleared_at = NOW() WHERE chat_id = $1 AND user_id = $2`, chatID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotChatParticipant
+	}
+	return nil
+}
+
+// DeleteChatMessages tombstones the chat's messages and wipes them, unless
+// a legal hold keeps their content for now
+func (r *PostgresRepository) DeleteChatMessages(ctx context.Context, chatID uuid.UUID) ([]string, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `UPDATE messages SET deleted_at = NOW() WHERE chat_id = $1 AND deleted_at IS NULL`, chatID); err != nil {
+		return nil, err
+	}
+	mediaURLs, err := wipeDeletedMessages(ctx, tx, &chatID)
+	if err != nil {
+		return nil, err
+	}
+	return mediaURLs, tx.Commit(ctx)
+}
+
+func (r *PostgresRepository) SetChatMessageRetention(ctx context.Context, chatID uuid.UUID, seconds *int) error {
+	tag, err := r.db.Exec(ctx, `UPDATE chats SET message_retention_seconds = $2, updated_at = NOW() WHERE id = $1`, chatID, seconds)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrChatNotFound
+	}
+	return nil
+}
+
+// DeleteExpiredMessages tombstones messages older than their chat's
+// retention, leaving frozen chats alone while they are reviewed, then wipes
+// every tombstone no legal hold keeps, including those a released hold kept
+func (r *PostgresRepository) DeleteExpiredMessages(ctx context.Context) (int64, []string, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE messages m SET deleted_at = NOW()
+		FROM chats c
+		WHERE c.id = m.chat_id AND c.message_retention_seconds IS NOT NULL AND c.frozen_at IS NULL
+			AND m.deleted_at IS NULL
+			AND m.created_at < NOW() - make_interval(secs => c.message_retention_seconds)
+	`)
+	if err != nil {
+		return 0, nil, err
+	}
+	mediaURLs, err := wipeDeletedMessages(ctx, tx, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	return tag.RowsAffected(), mediaURLs, tx.Commit(ctx)
+}
+
+func wipeDeletedMessages(ctx context.Context, tx pgx.Tx, chatID *uuid.UUID) ([]string, error) {
+	rows, err := tx.Query(ctx, wipeDeletedMessagesQuery, chatID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mediaURLs []string
+	for rows.Next() {
+		var url *string
+		if err := rows.Scan(&url); err != nil {
+			return nil, err
+		}
+		if url != nil {
+			mediaURLs = append(mediaURLs, *url)
+		}
+	}
+	return mediaURLs, rows.Err()
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/locolive/backend/internal/domain"
+)
+
+// chatPreferenceColumns reads a chat_participants row's preferences. A chat
+// muted indefinitely has muted_until 'infinity', shown as no end time.
+const chatPreferenceColumns = `
+	COALESCE(muted_until > NOW(), FALSE),
+	CASE WHEN muted_until = 'infinity' THEN NULL ELSE muted_until END,
+	archived, pinned`
+
+func scanChatPreferences(row pgx.Row) (*domain.ChatPreferences, error) {
+	var prefs domain.ChatPreferences
+	if err := row.Scan(&prefs.Muted, &prefs.MutedUntil, &prefs.Archived, &prefs.Pinned); err != nil {
+		return nil, err
+	}
+	return &prefs, nil
+}
+
+// SetChatMute mutes the chat for the user until the given time, or
+// indefinitely if it is nil, or unmutes it
+func (r *PostgresRepository) SetChatMute(ctx context.Context, chatID, userID uuid.UUID, muted bool, until *time.Time) (*domain.ChatPreferences, error) {
+	query := `
+		UPDATE chat_participants
+		SET muted_until = CASE WHEN $3 THEN COALESCE($4, 'infinity'::timestamptz) END
+		WHERE chat_id = $1 AND user_id = $2
+		RETURNING ` + chatPreferenceColumns
+	return r.setChatPreferences(ctx, query, chatID, userID, muted, until)
+}
+
+// SetChatArchived archives or unarchives the chat for the user, unpinning
+// it when archived
+func (r *PostgresRepository) SetChatArchived(ctx context.Context, chatID, userID uuid.UUID, archived bool) (*domain.ChatPreferences, error) {
+	query := `
+		UPDATE chat_participants
+		SET archived = $3, pinned = pinned AND NOT $3
+		WHERE chat_id = $1 AND user_id = $2
+		RETURNING ` + chatPreferenceColumns
+	return r.setChatPreferences(ctx, query, chatID, userID, archived)
+}
+
+// SetChatPinned pins or unpins the chat for the user, unarchiving it when
+// pinned. Pinning fails with ErrTooManyPinnedChats once maxPinned other
+// chats are pinned.
+func (r *PostgresRepository) SetChatPinned(ctx context.Context, chatID, userID uuid.UUID, pinned bool, maxPinned int) (*domain.ChatPreferences, error) {
+	query := `
+		UPDATE chat_participants
+		SET pinned = $3, archived = archived AND NOT $3
+		WHERE chat_id = $1 AND user_id = $2
+		AND (NOT $3 OR pinned OR (
+			SELECT COUNT(*) FROM chat_participants WHERE user_id = $2 AND pinned
+		) < $4)
+		RETURNING ` + chatPreferenceColumns
+	prefs, err := r.setChatPreferences(ctx, query, chatID, userID, pinned, maxPinned)
+	if errors.Is(err, domain.ErrNotChatParticipant) {
+		// The caller checked membership, so the pin limit stopped the update
+		return nil, domain.ErrTooManyPinnedChats
+	}
+	return prefs, err
+}
+
+func (r *PostgresRepository) setChatPreferences(ctx context.Context, query string, args ...interface{}) (*domain.ChatPreferences, error) {
+	prefs, err := scanChatPreferences(r.db.QueryRow(ctx, query, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotChatParticipant
+	}
+	return prefs, err
+}
+
+// GetMutedChatParticipants lists the members who have the chat muted now
+func (r *PostgresRepository) GetMutedChatParticipants(ctx context.Context, chatID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(ctx, `SELECT user_id FROM chat_participants WHERE chat_id = $1 AND muted_until > NOW()`, chatID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
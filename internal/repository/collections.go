@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/locolive/backend/internal/domain"
+)
+
+const collectionColumns = `c.id, c.owner_id, c.kind, c.title, c.description, c.location_lat, c.location_lng, c.contribution_policy, c.starts_at, c.ends_at, c.created_at,
+		(SELECT COUNT(*) FROM collection_stories cs JOIN stories s ON s.id = cs.story_id
+		 WHERE cs.collection_id = c.id AND s.expires_at > NOW() AND s.hidden_at IS NULL)`
+
+func scanCollection(row pgx.Row) (*domain.StoryCollection, error) {
+	var c domain.StoryCollection
+	err := row.Scan(&c.ID, &c.OwnerID, &c.Kind, &c.Title, &c.Description, &c.LocationLat, &c.LocationLng,
+		&c.ContributionPolicy, &c.StartsAt, &c.EndsAt, &c.CreatedAt, &c.StoryCount)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrCollectionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+const contributorColumns = `collection_id, user_id, status, requested_at, decided_at`
+
+func scanContributor(row pgx.Row) (*domain.CollectionContributor, error) {
+	var c domain.CollectionContributor
+	err := row.Scan(&c.CollectionID, &c.UserID, &c.Status, &c.RequestedAt, &c.DecidedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrContributorNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// CreateCollection inserts a collection
+func (r *PostgresRepository) CreateCollection(ctx context.Context, params domain.CreateCollectionParams) (*domain.StoryCollection, error) {
+	return scanCollection(r.db.QueryRow(ctx, `
+		INSERT INTO story_collections AS c (owner_id, kind, title, description, location_lat, location_lng, contribution_policy, starts_at, ends_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE($8, NOW()), $9)
+		RETURNING `+collectionColumns,
+		params.OwnerID, params.Kind, params.Title, params.Description, params.LocationLat, params.LocationLng,
+		params.ContributionPolicy, params.StartsAt, params.EndsAt,
+	))
+}
+
+// GetCollection retrieves a collection by ID
+func (r *PostgresRepository) GetCollection(ctx context.Context, id uuid.UUID) (*domain.StoryCollection, error) {
+	return scanCollection(r.db.QueryRow(ctx, `SELECT `+collectionColumns+` FROM story_collections c WHERE c.id = $1`, id))
+}
+
+// RequestContribution adds a contributor with the given status. An existing
+// request keeps its status, except that a pending one is approved if status is.
+func (r *PostgresRepository) RequestContribution(ctx context.Context, collectionID, userID uuid.UUID, status domain.ContributorStatus) (*domain.CollectionContributor, error) {
+	return scanContributor(r.db.QueryRow(ctx, `
+		INSERT INTO collection_contributors (collection_id, user_id, status, decided_at)
+		VALUES ($1, $2, $3, CASE WHEN $3 = 'approved' THEN NOW() END)
+		ON CONFLICT (collection_id, user_id) DO UPDATE
+		SET status = CASE WHEN collection_contributors.status = 'pending' THEN EXCLUDED.status ELSE collection_contributors.status END,
+			decided_at = COALESCE(collection_contributors.decided_at, EXCLUDED.decided_at)
+		RETURNING `+contributorColumns,
+		collectionID, userID, status,
+	))
+}
+
+// GetContributor returns a user's contribution request for a collection
+func (r *PostgresRepository) GetContributor(ctx context.Context, collectionID, userID uuid.UUID) (*domain.CollectionContributor, error) {
+	return scanContributor(r.db.QueryRow(ctx, `
+		SELECT `+contributorColumns+` FROM collection_contributors
+		WHERE collection_id = $1 AND user_id = $2
+	`, collectionID, userID))
+}
+
+// GetContributors lists a collection's contributors with a status, oldest request first
+func (r *PostgresRepository) GetContributors(ctx context.Context, collectionID uuid.UUID, status domain.ContributorStatus) ([]*domain.CollectionContributor, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+contributorColumns+` FROM collection_contributors
+		WHERE collection_id = $1 AND status = $2
+		ORDER BY requested_at
+	`, collectionID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var contributors []*domain.CollectionContributor
+	for rows.Next() {
+		c, err := scanContributor(rows)
+		if err != nil {
+			return nil, err
+		}
+		contributors = append(contributors, c)
+	}
+	return contributors, rows.Err()
+}
+
+// SetContributorStatus approves or rejects a contribution request
+func (r *PostgresRepository) SetContributorStatus(ctx context.Context, collectionID, userID uuid.UUID, status domain.ContributorStatus) (*domain.CollectionContributor, error) {
+	return scanContributor(r.db.QueryRow(ctx, `
+		UPDATE collection_contributors SET status = $3, decided_at = NOW()
+		WHERE collection_id = $1 AND user_id = $2
+		RETURNING `+contributorColumns,
+		collectionID, userID, status,
+	))
+}
+
+// AddCollectionStory adds a story to a collection; adding it twice is a no-op
+func (r *PostgresRepository) AddCollectionStory(ctx context.Context, collectionID, storyID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO collection_stories (collection_id, story_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, collectionID, storyID)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+		return domain.ErrStoryNotFound
+	}
+	return err
+}
+
+// RemoveCollectionStory takes a story out of a collection
+func (r *PostgresRepository) RemoveCollectionStory(ctx context.Context, collectionID, storyID uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM collection_stories WHERE collection_id = $1 AND story_id = $2`, collectionID, storyID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrStoryNotInCollection
+	}
+	return nil
+}
+
+// GetCollectionStories returns a collection's active stories from every
+// contributor, oldest first so the reel plays in order
+func (r *PostgresRepository) GetCollectionStories(ctx context.Context, collectionID uuid.UUID, limit, offset int) ([]*domain.Story, error) {
+	query := `
+		SELECT ` + storyWithUserColumns + `
+		FROM collection_stories cs
+		JOIN stories s ON s.id = cs.story_id
+		JOIN users u ON s.user_id = u.id
+		WHERE cs.collection_id = $1
+		AND s.expires_at > NOW() AND s.hidden_at IS NULL AND u.is_active = TRUE
+		ORDER BY s.created_at
+		LIMIT $2 OFFSET $3
+	`
+	return r.queryStories(ctx, query, collectionID, limit, offset)
+}
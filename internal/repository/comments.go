@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/locolive/backend/internal/domain"
+)
+
+// notBlockedWith matches rows whose user (in column userColumn) hasn't blocked
+// the user in parameter viewerParam and isn't blocked by them
+func notBlockedWith(userColumn, viewerParam string) string {
+	return `NOT EXISTS (
+			SELECT 1 FROM connections c
+			WHERE c.status = 'blocked'
+			AND ((c.requester_id = ` + viewerParam + ` AND c.receiver_id = ` + userColumn + `) OR (c.receiver_id = ` + viewerParam + ` AND c.requester_id = ` + userColumn + `))
+		)`
+}
+
+// storyVisibleTo matches active stories (aliased s, with their author as u)
+// that the user in parameter viewerParam can see: their own, or one by an
+// active author who is public or connected with them, with no block between
+func storyVisibleTo(viewerParam string) string {
+	return `s.expires_at > NOW() AND s.hidden_at IS NULL
+		AND (s.user_id = ` + viewerParam + ` OR (
+			u.is_active = TRUE
+			AND ` + notBlockedWith("s.user_id", viewerParam) + `
+			AND (u.visibility = 'public' OR EXISTS (
+				SELECT 1 FROM connections c
+				WHERE c.status = 'accepted'
+				AND ((c.requester_id = ` + viewerParam + ` AND c.receiver_id = s.user_id) OR (c.receiver_id = ` + viewerParam + ` AND c.requester_id = s.user_id))
+			))
+		))`
+}
+
+// GetViewableStory returns an active story the viewer is allowed to see
+func (r *PostgresRepository) GetViewableStory(ctx context.Context, viewerID, storyID uuid.UUID) (*domain.CommentableStory, error) {
+	query := `
+		SELECT s.id, s.user_id, s.comments_disabled
+		FROM stories s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.id = $2 AND ` + storyVisibleTo("$1") + `
+	`
+	var story domain.CommentableStory
+	err := r.db.QueryRow(ctx, query, viewerID, storyID).Scan(&story.ID, &story.UserID, &story.CommentsDisabled)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrStoryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &story, nil
+}
+
+// SetCommentsDisabled turns commenting on one of the owner's active stories on or off
+func (r *PostgresRepository) SetCommentsDisabled(ctx context.Context, ownerID, storyID uuid.UUID, disabled bool) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE stories SET comments_disabled = $3
+		WHERE id = $2 AND user_id = $1 AND expires_at > NOW()
+	`, ownerID, storyID, disabled)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrStoryNotFound
+	}
+	return nil
+}
+
+// CreateComment stores a comment, filling in its ID and creation time
+func (r *PostgresRepository) CreateComment(ctx context.Context, comment *domain.Comment) error {
+	mentionIDs := comment.MentionIDs
+	if mentionIDs == nil {
+		mentionIDs = []uuid.UUID{}
+	}
+	return r.db.QueryRow(ctx, `
+		INSERT INTO story_comments (story_id, user_id, content, mention_ids)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`, comment.StoryID, comment.UserID, comment.Content, mentionIDs).Scan(&comment.ID, &comment.CreatedAt)
+}
+
+// GetComments lists a story's visible comments with their authors, oldest first
+func (r *PostgresRepository) GetComments(ctx context.Context, storyID, viewerID uuid.UUID, limit, offset int) ([]*domain.Comment, error) {
+	query := `
+		SELECT sc.id, sc.story_id, sc.user_id, sc.content, sc.mention_ids, sc.created_at,
+		       u.id, u.name, COALESCE(u.username, ''), COALESCE(u.avatar_url, ''), u.verified_at IS NOT NULL, u.created_at
+		FROM story_comments sc
+		JOIN users u ON u.id = sc.user_id
+		WHERE sc.story_id = $1 AND sc.hidden_at IS NULL AND u.is_active = TRUE
+		AND ` + notBlockedWith("sc.user_id", "$2") + `
+		ORDER BY sc.created_at, sc.id
+		LIMIT $3 OFFSET $4
+	`
+	rows, err := r.db.Query(ctx, query, storyID, viewerID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []*domain.Comment
+	for rows.Next() {
+		var c domain.Comment
+		var u domain.UserResponse
+		err := rows.Scan(&c.ID, &c.StoryID, &c.UserID, &c.Content, &c.MentionIDs, &c.CreatedAt,
+			&u.ID, &u.Name, &u.Username, &u.AvatarURL, &u.Verified, &u.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		c.User = &u
+		comments = append(comments, &c)
+	}
+	return comments, rows.Err()
+}
+
+// GetComment returns a visible comment
+func (r *PostgresRepository) GetComment(ctx context.Context, commentID uuid.UUID) (*domain.Comment, error) {
+	var c domain.Comment
+	err := r.db.QueryRow(ctx, `
+		SELECT id, story_id, user_id, content, mention_ids, created_at
+		FROM story_comments WHERE id = $1 AND hidden_at IS NULL
+	`, commentID).Scan(&c.ID, &c.StoryID, &c.UserID, &c.Content, &c.MentionIDs, &c.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrCommentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// DeleteComment removes a comment
+func (r *PostgresRepository) DeleteComment(ctx context.Context, commentID uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM story_comments WHERE id = $1`, commentID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrCommentNotFound
+	}
+	return nil
+}
+
+// GetCommenterIDs returns the distinct authors of a story's visible comments
+func (r *PostgresRepository) GetCommenterIDs(ctx context.Context, storyID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT DISTINCT user_id FROM story_comments WHERE story_id = $1 AND hidden_at IS NULL
+	`, storyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// GetStoryCommentStats returns the visible comment count and comment setting of each story
+func (r *PostgresRepository) GetStoryCommentStats(ctx context.Context, storyIDs []uuid.UUID) (map[uuid.UUID]domain.StoryCommentStats, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT s.id, s.comments_disabled,
+		       (SELECT COUNT(*) FROM story_comments sc WHERE sc.story_id = s.id AND sc.hidden_at IS NULL)
+		FROM stories s
+		WHERE s.id = ANY($1)
+	`, storyIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make(map[uuid.UUID]domain.StoryCommentStats, len(storyIDs))
+	for rows.Next() {
+		var id uuid.UUID
+		var st domain.StoryCommentStats
+		if err := rows.Scan(&id, &st.Disabled, &st.Count); err != nil {
+			return nil, err
+		}
+		stats[id] = st
+	}
+	return stats, rows.Err()
+}
+
+// GetCommentOwner returns the author of a visible comment
+func (r *PostgresRepository) GetCommentOwner(ctx context.Context, commentID uuid.UUID) (uuid.UUID, error) {
+	comment, err := r.GetComment(ctx, commentID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return comment.UserID, nil
+}
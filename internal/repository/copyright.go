@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/locolive/backend/internal/domain"
+)
+
+const copyrightClaimColumns = `id, story_id, owner_id, claimant_name, claimant_email, work_description, signature,
+	status, counter_notice, counter_signature, counter_noticed_at, reviewed_by, reviewed_at, created_at`
+
+func scanCopyrightClaim(row pgx.Row) (*domain.CopyrightClaim, error) {
+	var c domain.CopyrightClaim
+	err := row.Scan(&c.ID, &c.StoryID, &c.OwnerID, &c.ClaimantName, &c.ClaimantEmail, &c.WorkDescription, &c.Signature,
+		&c.Status, &c.CounterNotice, &c.CounterSignature, &c.CounterNoticedAt, &c.ReviewedBy, &c.ReviewedAt, &c.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// CreateCopyrightClaim records a claim against an active story and hides it
+func (r *PostgresRepository) CreateCopyrightClaim(ctx context.Context, claim *domain.CopyrightClaim) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	created, err := scanCopyrightClaim(tx.QueryRow(ctx, `
+		INSERT INTO copyright_claims (story_id, owner_id, claimant_name, claimant_email, work_description, signature)
+		SELECT id, user_id, $2, $3, $4, $5 FROM stories WHERE id = $1 AND expires_at > NOW()
+		RETURNING `+copyrightClaimColumns,
+		claim.StoryID, claim.ClaimantName, claim.ClaimantEmail, claim.WorkDescription, claim.Signature,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrStoryNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE stories SET hidden_at = COALESCE(hidden_at, NOW()) WHERE id = $1`, claim.StoryID); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	*claim = *created
+	return nil
+}
+
+// GetCopyrightClaim returns a claim by ID
+func (r *PostgresRepository) GetCopyrightClaim(ctx context.Context, id uuid.UUID) (*domain.CopyrightClaim, error) {
+	claim, err := scanCopyrightClaim(r.db.QueryRow(ctx, `SELECT `+copyrightClaimColumns+` FROM copyright_claims WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrCopyrightClaimNotFound
+	}
+	return claim, err
+}
+
+// GetCopyrightClaims lists claims with the given status, oldest first
+func (r *PostgresRepository) GetCopyrightClaims(ctx context.Context, status domain.CopyrightClaimStatus, limit, offset int) ([]*domain.CopyrightClaim, error) {
+	query := `SELECT ` + copyrightClaimColumns + ` FROM copyright_claims WHERE status = $1 ORDER BY created_at LIMIT $2 OFFSET $3`
+	rows, err := r.db.Query(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var claims []*domain.CopyrightClaim
+	for rows.Next() {
+		c, err := scanCopyrightClaim(rows)
+		if err != nil {
+			return nil, err
+		}
+		claims = append(claims, c)
+	}
+	return claims, rows.Err()
+}
+
+// FileCounterNotice records the owner's counter-notice on a pending or upheld claim
+func (r *PostgresRepository) FileCounterNotice(ctx context.Context, claimID uuid.UUID, notice, signature string) (*domain.CopyrightClaim, error) {
+	claim, err := scanCopyrightClaim(r.db.QueryRow(ctx, `
+		UPDATE copyright_claims
+		SET status = 'counter_noticed', counter_notice = $2, counter_signature = $3, counter_noticed_at = NOW()
+		WHERE id = $1 AND status IN ('pending', 'upheld')
+		RETURNING `+copyrightClaimColumns,
+		claimID, notice, signature,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrCopyrightClaimState
+	}
+	return claim, err
+}
+
+// ResolveCopyrightClaim moves a claim from one of the given statuses. Upholding
+// keeps the story hidden and issues a strike; rejecting or restoring revokes
+// the strike and unhides the story unless something else still holds it down.
+func (r *PostgresRepository) ResolveCopyrightClaim(ctx context.Context, claimID, reviewerID uuid.UUID, from []domain.CopyrightClaimStatus, status domain.CopyrightClaimStatus) (*domain.CopyrightClaim, bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback(ctx)
+
+	fromStatuses := make([]string, len(from))
+	for i, s := range from {
+		fromStatuses[i] = string(s)
+	}
+
+	claim, err := scanCopyrightClaim(tx.QueryRow(ctx, `
+		UPDATE copyright_claims
+		SET status = $2, reviewed_by = $3, reviewed_at = NOW()
+		WHERE id = $1 AND status = ANY($4)
+		RETURNING `+copyrightClaimColumns,
+		claimID, status, reviewerID, fromStatuses,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		var exists bool
+		if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM copyright_claims WHERE id = $1)`, claimID).Scan(&exists); err != nil {
+			return nil, false, err
+		}
+		if exists {
+			return nil, false, domain.ErrCopyrightClaimState
+		}
+		return nil, false, domain.ErrCopyrightClaimNotFound
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	issued := false
+	if status == domain.CopyrightUpheld {
+		if _, err := tx.Exec(ctx, `UPDATE stories SET hidden_at = COALESCE(hidden_at, NOW()) WHERE id = $1`, claim.StoryID); err != nil {
+			return nil, false, err
+		}
+		if claim.OwnerID != nil {
+			tag, err := tx.Exec(ctx, `
+				INSERT INTO user_strikes (user_id, kind, claim_id) VALUES ($1, $2, $3)
+				ON CONFLICT (claim_id) DO NOTHING
+			`, *claim.OwnerID, domain.StrikeKindCopyright, claim.ID)
+			if err != nil {
+				return nil, false, err
+			}
+			issued = tag.RowsAffected() == 1
+		}
+	} else {
+		if _, err := tx.Exec(ctx, `UPDATE user_strikes SET revoked_at = NOW() WHERE claim_id = $1 AND revoked_at IS NULL`, claim.ID); err != nil {
+			return nil, false, err
+		}
+		if err := restoreStory(ctx, tx, claim.StoryID); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return claim, issued, tx.Commit(ctx)
+}
+
+// restoreStory unhides a story unless a moderation takedown or another
+// copyright claim still keeps it down
+func restoreStory(ctx context.Context, tx pgx.Tx, storyID uuid.UUID) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE stories SET hidden_at = NULL
+		WHERE id = $1
+		AND NOT EXISTS (
+			SELECT 1 FROM moderation_actions
+			WHERE target_type = 'story' AND target_id = $1 AND status IN ('pending', 'upheld')
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM copyright_claims
+			WHERE story_id = $1 AND status IN ('pending', 'upheld', 'counter_noticed')
+		)
+	`, storyID)
+	return err
+}
+
+// GetUserStrikes lists a user's strikes, newest first
+func (r *PostgresRepository) GetUserStrikes(ctx context.Context, userID uuid.UUID) ([]*domain.Strike, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, user_id, kind, claim_id, created_at, revoked_at
+		FROM user_strikes WHERE user_id = $1
+		ORDER BY created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	strikes := []*domain.Strike{}
+	for rows.Next() {
+		var s domain.Strike
+		if err := rows.Scan(&s.ID, &s.UserID, &s.Kind, &s.ClaimID, &s.CreatedAt, &s.RevokedAt); err != nil {
+			return nil, err
+		}
+		strikes = append(strikes, &s)
+	}
+	return strikes, rows.Err()
+}
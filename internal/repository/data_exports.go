@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/locolive/backend/internal/domain"
+)
+
+const dataExportColumns = `id, user_id, status, file_url, size_bytes, requested_at, completed_at, expires_at`
+
+// exportSectionsQuery collects what a user gave us or did, as opposed to
+// preservationSnapshotQuery, which keeps everything held about them
+const exportSectionsQuery = `
+	SELECT jsonb_build_object(
+		'profile', (SELECT to_jsonb(u) - 'password_hash' - 'failed_login_attempts' - 'locked_until' FROM users u WHERE u.id = $1),
+		'stories', COALESCE((
+			SELECT jsonb_agg(to_jsonb(x) ORDER BY x.created_at) FROM (
+				SELECT id, media_url, media_type, caption, location_lat, location_lng, created_at, expires_at, NULL::timestamptz AS archived_at
+				FROM stories WHERE user_id = $1
+				UNION ALL
+				SELECT id, media_url, media_type, caption, location_lat, location_lng, created_at, expires_at, archived_at
+				FROM story_archive WHERE user_id = $1
+			) x
+		), '[]'),
+		'connections', COALESCE((
+			SELECT jsonb_agg(jsonb_build_object(
+				'user_id', u.id,
+				'name', u.name,
+				'username', u.username,
+				'status', c.status,
+				'requested_by_me', c.requester_id = $1,
+				'created_at', c.created_at,
+				'updated_at', c.updated_at
+			) ORDER BY c.created_at)
+			FROM connections c
+			JOIN users u ON u.id = CASE WHEN c.requester_id = $1 THEN c.receiver_id ELSE c.requester_id END
+			WHERE c.requester_id = $1 OR c.receiver_id = $1
+		), '[]'),
+		'comments', COALESCE((
+			SELECT jsonb_agg(jsonb_build_object('id', x.id, 'story_id', x.story_id, 'content', x.content, 'created_at', x.created_at) ORDER BY x.created_at)
+			FROM story_comments x WHERE x.user_id = $1
+		), '[]'),
+		'reactions', COALESCE((
+			SELECT jsonb_agg(jsonb_build_object('story_id', x.story_id, 'emoji', x.emoji, 'created_at', x.created_at) ORDER BY x.created_at)
+			FROM story_reactions x WHERE x.user_id = $1
+		), '[]'),
+		'places', COALESCE((SELECT jsonb_agg(to_jsonb(x) - 'user_id' ORDER BY x.created_at) FROM saved_places x WHERE x.user_id = $1), '[]')
+	)::text
+`
+
+func scanDataExport(row pgx.Row) (*domain.DataExport, error) {
+	var e domain.DataExport
+	err := row.Scan(&e.ID, &e.UserID, &e.Status, &e.DownloadURL, &e.SizeBytes, &e.RequestedAt, &e.CompletedAt, &e.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrDataExportNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// CreateDataExport queues an export, leaning on idx_data_exports_active so
+// that concurrent requests end up with the same one
+func (r *PostgresRepository) CreateDataExport(ctx context.Context, userID uuid.UUID) (*domain.DataExport, bool, error) {
+	export, err := scanDataExport(r.db.QueryRow(ctx, `
+		INSERT INTO data_exports (user_id) VALUES ($1)
+		ON CONFLICT (user_id) WHERE status IN ('pending', 'running') DO NOTHING
+		RETURNING `+dataExportColumns, userID))
+	if !errors.Is(err, domain.ErrDataExportNotFound) {
+		return export, err == nil, err
+	}
+
+	export, err = scanDataExport(r.db.QueryRow(ctx, `
+		SELECT `+dataExportColumns+` FROM data_exports
+		WHERE user_id = $1 AND status IN ('pending', 'running')
+	`, userID))
+	return export, false, err
+}
+
+func (r *PostgresRepository) GetLatestDataExport(ctx context.Context, userID uuid.UUID) (*domain.DataExport, error) {
+	return scanDataExport(r.db.QueryRow(ctx, `
+		SELECT `+dataExportColumns+` FROM data_exports
+		WHERE user_id = $1
+		ORDER BY requested_at DESC
+		LIMIT 1
+	`, userID))
+}
+
+// ClaimDataExport takes the next export to build, skipping ones another
+// instance has just claimed
+func (r *PostgresRepository) ClaimDataExport(ctx context.Context, staleBefore time.Time) (*domain.DataExport, error) {
+	return scanDataExport(r.db.QueryRow(ctx, `
+		UPDATE data_exports SET status = 'running', started_at = NOW()
+		WHERE id = (
+			SELECT id FROM data_exports
+			WHERE status = 'pending' OR (status = 'running' AND started_at < $1)
+			ORDER BY requested_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+dataExportColumns, staleBefore))
+}
+
+func (r *PostgresRepository) CompleteDataExport(ctx context.Context, exportID uuid.UUID, fileURL string, sizeBytes int64, expiresAt time.Time) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE data_exports
+		SET status = 'ready', file_url = $2, size_bytes = $3, completed_at = NOW(), expires_at = $4
+		WHERE id = $1
+	`, exportID, fileURL, sizeBytes, expiresAt)
+	return err
+}
+
+func (r *PostgresRepository) FailDataExport(ctx context.Context, exportID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE data_exports SET status = 'failed', completed_at = NOW() WHERE id = $1
+	`, exportID)
+	return err
+}
+
+// DeleteExpiredDataExports removes expired archives and failed exports,
+// which are kept for as long as an archive would have been
+func (r *PostgresRepository) DeleteExpiredDataExports(ctx context.Context, now time.Time) ([]string, error) {
+	rows, err := r.db.Query(ctx, `
+		DELETE FROM data_exports
+		WHERE expires_at < $1 OR (status = 'failed' AND completed_at < $2)
+		RETURNING file_url
+	`, now, now.Add(-domain.DataExportRetention))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var urls []string
+	for rows.Next() {
+		var url *string
+		if err := rows.Scan(&url); err != nil {
+			return nil, err
+		}
+		if url != nil {
+			urls = append(urls, *url)
+		}
+	}
+	return urls, rows.Err()
+}
+
+func (r *PostgresRepository) GetExportSections(ctx context.Context, userID uuid.UUID) (domain.ExportSections, error) {
+	var data string
+	if err := r.db.QueryRow(ctx, exportSectionsQuery, userID).Scan(&data); err != nil {
+		return nil, err
+	}
+	var sections domain.ExportSections
+	if err := json.Unmarshal([]byte(data), &sections); err != nil {
+		return nil, err
+	}
+	return sections, nil
+}
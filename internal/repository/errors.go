@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/locolive/backend/internal/domain"
+)
+
+// SQLSTATEs the repository checks for
+const (
+	uniqueViolation = "23505"
+	// queryCanceled covers both statement_timeout and cancel requests
+	queryCanceled = "57014"
+)
+
+// userUniqueConstraints maps unique constraints on users to domain errors
+var userUniqueConstraints = map[string]error{
+	"users_email_key":     domain.ErrEmailAlreadyExists,
+	"users_phone_key":     domain.ErrPhoneAlreadyExists,
+	"users_google_id_key": domain.ErrGoogleAccountAlreadyLinked,
+	"users_apple_id_key":  domain.ErrAppleAccountAlreadyLinked,
+	"users_username_key":  domain.ErrUsernameTaken,
+}
+
+// mapUserError translates unique violations on users so that concurrent writes
+// losing a race surface the same error as the exists-check would have
+func mapUserError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != uniqueViolation {
+		return err
+	}
+	if mapped, ok := userUniqueConstraints[pgErr.ConstraintName]; ok {
+		return mapped
+	}
+	return domain.ErrUserAlreadyExists
+}
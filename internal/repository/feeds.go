@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/locolive/backend/internal/domain"
+)
+
+// storyWithUserColumns matches scanStoryWithUser for queries over stories s JOIN users u
+const storyWithUserColumns = `s.id, s.user_id, s.media_url, s.media_type, s.caption, s.location_lat, s.location_lng, s.location_fuzzed, s.expires_at, s.created_at,
+		u.id, u.email, u.phone, u.name, u.username, u.avatar_url, u.bio, u.gender, u.date_of_birth, u.visibility, u.country_code, u.google_id, u.email_verified, u.phone_verified, u.is_active, u.verified_at, u.created_at, u.updated_at`
+
+// storyDownranked sorts stories with a spoofed location or a reposted image
+// after the rest; it assumes the story is aliased s
+const storyDownranked = `(cardinality(s.location_flags) > 0 OR s.duplicate_of IS NOT NULL)`
+
+// GetConnectionsFeed returns active stories from the user's accepted connections, regardless of distance
+func (r *PostgresRepository) GetConnectionsFeed(ctx context.Context, userID uuid.UUID, seen domain.SeenMode, limit, offset int) ([]*domain.Story, error) {
+	seenFilter, seenOrder := seenClauses(seen)
+	query := `
+		SELECT ` + storyWithUserColumns + `
+		FROM stories s
+		JOIN users u ON s.user_id = u.id
+		JOIN connections c
+			ON c.status = 'accepted'
+			AND ((c.requester_id = $1 AND c.receiver_id = s.user_id) OR (c.receiver_id = $1 AND c.requester_id = s.user_id))
+		WHERE s.expires_at > NOW() AND s.hidden_at IS NULL AND u.is_active = TRUE` + seenFilter + `
+		ORDER BY ` + seenOrder + `s.created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	return r.queryStories(ctx, query, userID, limit, offset)
+}
+
+// GetDiscoveryFeed returns nearby active stories from public-visibility users the
+// viewer isn't connected to or blocked by. Stories rank by distance and age, each
+// normalised so one at the edge of the radius ranks like one a day old.
+func (r *PostgresRepository) GetDiscoveryFeed(ctx context.Context, userID uuid.UUID, lat, lng, radius float64, seen domain.SeenMode, limit, offset int) ([]*domain.Story, error) {
+	seenFilter, seenOrder := seenClauses(seen)
+	query := `
+		SELECT ` + storyWithUserColumns + `
+		FROM stories s
+		JOIN users u ON s.user_id = u.id
+		WHERE s.expires_at > NOW() AND s.hidden_at IS NULL
+		AND s.user_id <> $1
+		AND u.is_active = TRUE AND u.visibility = 'public'
+		AND s.location_lat IS NOT NULL AND s.location_lng IS NOT NULL
+		AND earth_box(ll_to_earth($2, $3), $4) @> ll_to_earth(s.location_lat, s.location_lng)
+		AND earth_distance(ll_to_earth($2, $3), ll_to_earth(s.location_lat, s.location_lng)) < $4
+		AND NOT EXISTS (
+			SELECT 1 FROM connections c
+			WHERE c.status IN ('accepted', 'blocked')
+			AND ((c.requester_id = $1 AND c.receiver_id = s.user_id) OR (c.receiver_id = $1 AND c.requester_id = s.user_id))
+		)` + seenFilter + `
+		ORDER BY ` + storyDownranked + `, ` + seenOrder + `
+			earth_distance(ll_to_earth($2, $3), ll_to_earth(s.location_lat, s.location_lng)) / $4
+			+ EXTRACT(EPOCH FROM NOW() - s.created_at) / 86400,
+			s.created_at DESC
+		LIMIT $5 OFFSET $6
+	`
+	return r.queryStories(ctx, query, userID, lat, lng, radius, limit, offset)
+}
+
+// seenClauses returns the WHERE and ORDER BY fragments for a seen mode. Both
+// assume the viewer is $1 and the story is aliased s.
+func seenClauses(mode domain.SeenMode) (filter, order string) {
+	const seen = `EXISTS (SELECT 1 FROM story_views v WHERE v.viewer_id = $1 AND v.story_id = s.id)`
+	switch mode {
+	case domain.SeenExclude:
+		return "\n\t\tAND NOT " + seen, ""
+	case domain.SeenLast:
+		return "", seen + ", "
+	}
+	return "", ""
+}
+
+// MarkStoriesSeen records that the viewer has seen the given stories
+func (r *PostgresRepository) MarkStoriesSeen(ctx context.Context, viewerID uuid.UUID, storyIDs []uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO story_views (viewer_id, story_id)
+		SELECT $1, id FROM stories WHERE id = ANY($2)
+		ON CONFLICT DO NOTHING
+	`, viewerID, storyIDs)
+	return err
+}
+
+// RecordStoryImpressions records the stories the viewer's app rendered
+func (r *PostgresRepository) RecordStoryImpressions(ctx context.Context, viewerID uuid.UUID, storyIDs []uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO story_impressions (story_id, viewer_id)
+		SELECT id, $1 FROM stories WHERE id = ANY($2) AND user_id <> $1
+		ON CONFLICT DO NOTHING
+	`, viewerID, storyIDs)
+	return err
+}
+
+// GetStoryReach counts an active story's impressions and views. Another user's
+// story is reported as not found.
+func (r *PostgresRepository) GetStoryReach(ctx context.Context, ownerID, storyID uuid.UUID) (*domain.StoryReach, error) {
+	reach := &domain.StoryReach{StoryID: storyID}
+	err := r.db.QueryRow(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM story_impressions i WHERE i.story_id = s.id),
+			(SELECT COUNT(*) FROM story_views v WHERE v.story_id = s.id AND v.viewer_id <> s.user_id),
+			(SELECT COUNT(*) FROM messages m WHERE m.shared_story_id = s.id AND NOT m.story_reply AND m.created_at >= s.created_at)
+		FROM stories s
+		WHERE s.id = $1 AND s.user_id = $2 AND s.expires_at > NOW()
+	`, storyID, ownerID).Scan(&reach.Impressions, &reach.Views, &reach.Shares)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrStoryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return reach, nil
+}
+
+// GetSeenStoryIDs returns which of the given stories the viewer has seen
+func (r *PostgresRepository) GetSeenStoryIDs(ctx context.Context, viewerID uuid.UUID, storyIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	rows, err := r.db.Query(ctx, `SELECT story_id FROM story_views WHERE viewer_id = $1 AND story_id = ANY($2)`, viewerID, storyIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seen := make(map[uuid.UUID]bool)
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		seen[id] = true
+	}
+	return seen, rows.Err()
+}
+
+func (r *PostgresRepository) queryStories(ctx context.Context, query string, args ...interface{}) ([]*domain.Story, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stories []*domain.Story
+	for rows.Next() {
+		story, err := scanStoryWithUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		stories = append(stories, story)
+	}
+	return stories, rows.Err()
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/locolive/backend/internal/domain"
+)
+
+// CreateGroupChat creates a group chat with the creator as admin and the
+// members as plain members
+func (r *PostgresRepository) CreateGroupChat(ctx context.Context, creatorID uuid.UUID, name string, avatarURL *string, memberIDs []uuid.UUID) (*domain.Chat, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var chatID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		INSERT INTO chats (is_group, name, avatar_url, created_by)
+		VALUES (TRUE, $1, $2, $3)
+		RETURNING id
+	`, name, avatarURL, creatorID).Scan(&chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO chat_participants (chat_id, user_id, role)
+		SELECT $1::uuid, id, CASE WHEN id = $2 THEN 'admin' ELSE 'member' END
+		FROM unnest($3::uuid[]) AS id
+	`, chatID, creatorID, append([]uuid.UUID{creatorID}, memberIDs...))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return r.GetChatByID(ctx, chatID)
+}
+
+// UpdateGroupChat sets the non-nil fields of a group chat
+func (r *PostgresRepository) UpdateGroupChat(ctx context.Context, chatID uuid.UUID, name, avatarURL *string) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE chats SET
+			name = COALESCE($2, name),
+			avatar_url = CASE WHEN $3::text IS NULL THEN avatar_url ELSE NULLIF($3, '') END,
+			updated_at = NOW()
+		WHERE id = $1 AND is_group
+	`, chatID, name, avatarURL)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrChatNotFound
+	}
+	return nil
+}
+
+// AddChatParticipants adds members to a group. The chat row is locked so
+// concurrent additions can't take it past maxMembers.
+func (r *PostgresRepository) AddChatParticipants(ctx context.Context, chatID uuid.UUID, userIDs []uuid.UUID, maxMembers int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var members, joining int
+	err = tx.QueryRow(ctx, `
+		SELECT (SELECT COUNT(*) FROM chat_participants WHERE chat_id = c.id),
+		       (SELECT COUNT(*) FROM unnest($2::uuid[]) AS id
+		        WHERE NOT EXISTS (SELECT 1 FROM chat_participants WHERE chat_id = c.id AND user_id = id))
+		FROM chats c
+		WHERE c.id = $1 AND c.is_group
+		FOR UPDATE
+	`, chatID, userIDs).Scan(&members, &joining)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrChatNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if members+joining > maxMembers {
+		return domain.ErrGroupChatFull
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO chat_participants (chat_id, user_id)
+		SELECT $1::uuid, id FROM unnest($2::uuid[]) AS id
+		ON CONFLICT (chat_id, user_id) DO NOTHING
+	`, chatID, userIDs)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE chats SET updated_at = NOW() WHERE id = $1`, chatID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// RemoveChatParticipant removes a member from a group, promoting the
+// longest-standing member if no admin is left and deleting an emptied group
+func (r *PostgresRepository) RemoveChatParticipant(ctx context.Context, chatID, userID uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var locked uuid.UUID
+	err = tx.QueryRow(ctx, `SELECT id FROM chats WHERE id = $1 AND is_group FOR UPDATE`, chatID).Scan(&locked)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrChatNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(ctx, `DELETE FROM chat_participants WHERE chat_id = $1 AND user_id = $2`, chatID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrChatMemberNotFound
+	}
+
+	// Someone who left no longer holds back the status of earlier messages
+	if _, err := tx.Exec(ctx, `DELETE FROM message_receipts WHERE chat_id = $1 AND user_id = $2`, chatID, userID); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE chat_participants SET role = 'admin'
+		WHERE chat_id = $1 AND user_id = (
+			SELECT user_id FROM chat_participants WHERE chat_id = $1
+			ORDER BY joined_at, user_id LIMIT 1
+		)
+		AND NOT EXISTS (SELECT 1 FROM chat_participants WHERE chat_id = $1 AND role = 'admin')
+	`, chatID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM chats WHERE id = $1
+		AND NOT EXISTS (SELECT 1 FROM chat_participants WHERE chat_id = $1)
+	`, chatID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// SetChatParticipantRole changes a group member's role
+func (r *PostgresRepository) SetChatParticipantRole(ctx context.Context, chatID, userID uuid.UUID, role domain.ChatRole) error {
+	tag, err := r.db.Exec(ctx, `UPDATE chat_participants SET role = $3 WHERE chat_id = $1 AND user_id = $2`, chatID, userID, string(role))
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrChatMemberNotFound
+	}
+	return nil
+}
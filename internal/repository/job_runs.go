@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/locolive/backend/internal/domain"
+)
+
+const jobRunColumns = `job, started_at, duration_ms, rows_affected, error`
+
+func (r *PostgresRepository) RecordJobRun(ctx context.Context, run *domain.JobRun) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO job_runs (job, started_at, duration_ms, rows_affected, error)
+		VALUES ($1, $2, $3, $4, $5)
+	`, run.Job, run.StartedAt, run.DurationMs, run.RowsAffected, run.Error)
+	return err
+}
+
+func (r *PostgresRepository) GetLatestJobRuns(ctx context.Context) ([]*domain.JobRun, error) {
+	return r.queryJobRuns(ctx, `
+		SELECT DISTINCT ON (job) `+jobRunColumns+` FROM job_runs
+		ORDER BY job, started_at DESC
+	`)
+}
+
+func (r *PostgresRepository) ListJobRuns(ctx context.Context, job string, limit, offset int) ([]*domain.JobRun, error) {
+	return r.queryJobRuns(ctx, `
+		SELECT `+jobRunColumns+` FROM job_runs
+		WHERE job = $1
+		ORDER BY started_at DESC
+		LIMIT $2 OFFSET $3
+	`, job, limit, offset)
+}
+
+func (r *PostgresRepository) queryJobRuns(ctx context.Context, query string, args ...interface{}) ([]*domain.JobRun, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []*domain.JobRun{}
+	for rows.Next() {
+		var run domain.JobRun
+		if err := rows.Scan(&run.Job, &run.StartedAt, &run.DurationMs, &run.RowsAffected, &run.Error); err != nil {
+			return nil, err
+		}
+		runs = append(runs, &run)
+	}
+	return runs, rows.Err()
+}
@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/locolive/backend/internal/domain"
+)
+
+// foreignKeyViolation is the SQLSTATE for foreign_key_violation
+const foreignKeyViolation = "23503"
+
+// notOnLegalHold is a WHERE fragment that excludes rows owned by a user under
+// legal hold. Every retention and cleanup delete must include it.
+func notOnLegalHold(userColumn string) string {
+	return `NOT EXISTS (SELECT 1 FROM legal_holds lh WHERE lh.user_id = ` + userColumn + ` AND lh.released_at IS NULL)`
+}
+
+// preservationSnapshotQuery collects everything held about a user as one JSON document
+const preservationSnapshotQuery = `
+	SELECT jsonb_build_object(
+		'user', (SELECT to_jsonb(u) - 'password_hash' FROM users u WHERE u.id = $1),
+		'sessions', COALESCE((SELECT jsonb_agg(to_jsonb(x) ORDER BY x.created_at) FROM sessions x WHERE x.user_id = $1), '[]'),
+		'login_attempts', COALESCE((SELECT jsonb_agg(to_jsonb(x) ORDER BY x.created_at) FROM login_attempts x WHERE x.user_id = $1), '[]'),
+		'stories', COALESCE((SELECT jsonb_agg(to_jsonb(x) ORDER BY x.created_at) FROM stories x WHERE x.user_id = $1), '[]'),
+		'story_archive', COALESCE((SELECT jsonb_agg(to_jsonb(x) ORDER BY x.created_at) FROM story_archive x WHERE x.user_id = $1), '[]'),
+		'story_comments', COALESCE((SELECT jsonb_agg(to_jsonb(x) ORDER BY x.created_at) FROM story_comments x WHERE x.user_id = $1), '[]'),
+		'story_reactions', COALESCE((SELECT jsonb_agg(to_jsonb(x) ORDER BY x.created_at) FROM story_reactions x WHERE x.user_id = $1), '[]'),
+		'connections', COALESCE((SELECT jsonb_agg(to_jsonb(x) ORDER BY x.created_at) FROM connections x WHERE x.requester_id = $1 OR x.receiver_id = $1), '[]'),
+		'messages', COALESCE((
+			SELECT jsonb_agg(to_jsonb(x) ORDER BY x.created_at) FROM messages x
+			WHERE x.chat_id IN (SELECT chat_id FROM chat_participants WHERE user_id = $1)
+		), '[]'),
+		'chat_keys', COALESCE((
+			SELECT jsonb_agg(to_jsonb(x) ORDER BY x.chat_id, x.version) FROM chat_keys x
+			WHERE x.chat_id IN (SELECT chat_id FROM chat_participants WHERE user_id = $1)
+		), '[]'),
+		'saved_places', COALESCE((SELECT jsonb_agg(to_jsonb(x) ORDER BY x.created_at) FROM saved_places x WHERE x.user_id = $1), '[]'),
+		'reports', COALESCE((SELECT jsonb_agg(to_jsonb(x) ORDER BY x.created_at) FROM reports x WHERE x.reporter_id = $1), '[]')
+	)::text
+`
+
+const legalHoldColumns = `id, user_id, reason, case_reference, placed_by, placed_at, released_by, released_at`
+
+func scanLegalHold(row pgx.Row) (*domain.LegalHold, error) {
+	var h domain.LegalHold
+	err := row.Scan(&h.ID, &h.UserID, &h.Reason, &h.CaseReference, &h.PlacedBy, &h.PlacedAt, &h.ReleasedBy, &h.ReleasedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrLegalHoldNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// PlaceLegalHold creates a hold and snapshots the user's data in the same transaction
+func (r *PostgresRepository) PlaceLegalHold(ctx context.Context, hold *domain.LegalHold) (*domain.PreservationSnapshot, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO legal_holds (user_id, reason, case_reference, placed_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, placed_at
+	`, hold.UserID, hold.Reason, hold.CaseReference, hold.PlacedBy).Scan(&hold.ID, &hold.PlacedAt)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case uniqueViolation:
+			return nil, domain.ErrLegalHoldExists
+		case foreignKeyViolation:
+			return nil, domain.ErrUserNotFound
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// jsonb text output is canonical, so the checksum can be recomputed from the stored value
+	var data string
+	if err := tx.QueryRow(ctx, preservationSnapshotQuery, hold.UserID).Scan(&data); err != nil {
+		return nil, err
+	}
+
+	snapshot := &domain.PreservationSnapshot{
+		HoldID:   hold.ID,
+		UserID:   hold.UserID,
+		Data:     json.RawMessage(data),
+		Checksum: domain.SnapshotChecksum([]byte(data)),
+	}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO preservation_snapshots (hold_id, user_id, data, checksum)
+		VALUES ($1, $2, $3::jsonb, $4)
+		RETURNING id, created_at
+	`, snapshot.HoldID, snapshot.UserID, data, snapshot.Checksum).Scan(&snapshot.ID, &snapshot.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot, tx.Commit(ctx)
+}
+
+// ReleaseLegalHold lifts an active hold
+func (r *PostgresRepository) ReleaseLegalHold(ctx context.Context, holdID, releasedBy uuid.UUID) (*domain.LegalHold, error) {
+	hold, err := scanLegalHold(r.db.QueryRow(ctx, `
+		UPDATE legal_holds SET released_by = $2, released_at = NOW()
+		WHERE id = $1 AND released_at IS NULL
+		RETURNING `+legalHoldColumns,
+		holdID, releasedBy,
+	))
+	if errors.Is(err, domain.ErrLegalHoldNotFound) {
+		if _, err := r.GetLegalHold(ctx, holdID); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrLegalHoldReleased
+	}
+	return hold, err
+}
+
+// GetLegalHold retrieves a hold by ID
+func (r *PostgresRepository) GetLegalHold(ctx context.Context, holdID uuid.UUID) (*domain.LegalHold, error) {
+	return scanLegalHold(r.db.QueryRow(ctx, `SELECT `+legalHoldColumns+` FROM legal_holds WHERE id = $1`, holdID))
+}
+
+// GetLegalHolds lists holds, newest first
+func (r *PostgresRepository) GetLegalHolds(ctx context.Context, activeOnly bool, limit, offset int) ([]*domain.LegalHold, error) {
+	query := `
+		SELECT ` + legalHoldColumns + `
+		FROM legal_holds
+		WHERE NOT $1 OR released_at IS NULL
+		ORDER BY placed_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.Query(ctx, query, activeOnly, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var holds []*domain.LegalHold
+	for rows.Next() {
+		h, err := scanLegalHold(rows)
+		if err != nil {
+			return nil, err
+		}
+		holds = append(holds, h)
+	}
+	return holds, rows.Err()
+}
+
+// GetPreservationSnapshot returns the snapshot taken when a hold was placed
+func (r *PostgresRepository) GetPreservationSnapshot(ctx context.Context, holdID uuid.UUID) (*domain.PreservationSnapshot, error) {
+	var s domain.PreservationSnapshot
+	var data string
+	err := r.db.QueryRow(ctx, `
+		SELECT id, hold_id, user_id, data::text, checksum, created_at
+		FROM preservation_snapshots
+		WHERE hold_id = $1
+	`, holdID).Scan(&s.ID, &s.HoldID, &s.UserID, &data, &s.Checksum, &s.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrLegalHoldNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.Data = json.RawMessage(data)
+	return &s, nil
+}
+
+// RecordAudit appends an entry to the admin audit log
+func (r *PostgresRepository) RecordAudit(ctx context.Context, entry domain.AuditEntry) error {
+	return insertAudit(ctx, r.db, entry)
+}
+
+// execer is satisfied by both the pool and a transaction
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+}
+
+func insertAudit(ctx context.Context, db execer, entry domain.AuditEntry) error {
+	if entry.Details == nil {
+		entry.Details = map[string]interface{}{}
+	}
+	details, err := json.Marshal(entry.Details)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(ctx, `
+		INSERT INTO admin_audit_log (actor_id, action, target_user_id, details)
+		VALUES ($1, $2, $3, $4)
+	`, entry.ActorID, entry.Action, entry.TargetUserID, details)
+	return err
+}
+
+// GetAuditLog lists audit entries about a user, newest first
+func (r *PostgresRepository) GetAuditLog(ctx context.Context, targetUserID uuid.UUID, limit, offset int) ([]*domain.AuditEntry, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, actor_id, action, target_user_id, details, created_at
+		FROM admin_audit_log
+		WHERE target_user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`, targetUserID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*domain.AuditEntry
+	for rows.Next() {
+		var e domain.AuditEntry
+		if err := rows.Scan(&e.ID, &e.ActorID, &e.Action, &e.TargetUserID, &e.Details, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &e)
+	}
+	return entries, rows.Err()
+}
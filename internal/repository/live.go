@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/locolive/backend/internal/domain"
+)
+
+const liveSessionColumns = `id, user_id, location_lat, location_lng, started_at, last_post_at, ended_at`
+
+func scanLiveSession(row pgx.Row) (*domain.LiveSession, error) {
+	var s domain.LiveSession
+	err := row.Scan(&s.ID, &s.UserID, &s.Lat, &s.Lng, &s.StartedAt, &s.LastPostAt, &s.EndedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNoLiveSession
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// liveSessionActive is a WHERE fragment matching sessions still in progress,
+// given the idle and started cutoffs as the two parameters named
+func liveSessionActive(alias, idleParam, startedParam string) string {
+	return alias + `.ended_at IS NULL AND ` + alias + `.last_post_at > ` + idleParam + ` AND ` + alias + `.started_at > ` + startedParam
+}
+
+// touchLiveSession extends the user's live session from the given spot, or
+// starts a new one if they have none in progress, and returns its ID
+func touchLiveSession(ctx context.Context, tx pgx.Tx, userID uuid.UUID, lat, lng float64) (uuid.UUID, error) {
+	idleSince, startedSince := domain.LiveCutoffs(time.Now())
+
+	var id uuid.UUID
+	err := tx.QueryRow(ctx, `
+		UPDATE live_sessions s
+		SET last_post_at = NOW(), location_lat = $2, location_lng = $3
+		WHERE s.user_id = $1 AND `+liveSessionActive("s", "$4", "$5")+`
+		RETURNING s.id
+	`, userID, lat, lng, idleSince, startedSince).Scan(&id)
+	if err == nil || !errors.Is(err, pgx.ErrNoRows) {
+		return id, err
+	}
+
+	// A stale session the worker hasn't ended yet would block the new one
+	if _, err := tx.Exec(ctx, `UPDATE live_sessions SET ended_at = NOW() WHERE user_id = $1 AND ended_at IS NULL`, userID); err != nil {
+		return uuid.Nil, err
+	}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO live_sessions (user_id, location_lat, location_lng)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`, userID, lat, lng).Scan(&id)
+	return id, err
+}
+
+// GetLiveStoryIDs returns which of the stories belong to a live session still in progress
+func (r *PostgresRepository) GetLiveStoryIDs(ctx context.Context, storyIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	idleSince, startedSince := domain.LiveCutoffs(time.Now())
+	rows, err := r.db.Query(ctx, `
+		SELECT s.id
+		FROM stories s
+		JOIN live_sessions ls ON ls.id = s.live_session_id
+		WHERE s.id = ANY($1) AND `+liveSessionActive("ls", "$2", "$3"),
+		storyIDs, idleSince, startedSince,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	live := make(map[uuid.UUID]bool)
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		live[id] = true
+	}
+	return live, rows.Err()
+}
+
+// EndLiveSession ends the user's session in progress
+func (r *PostgresRepository) EndLiveSession(ctx context.Context, userID uuid.UUID) (*domain.LiveSession, error) {
+	return scanLiveSession(r.db.QueryRow(ctx, `
+		UPDATE live_sessions SET ended_at = NOW()
+		WHERE user_id = $1 AND ended_at IS NULL
+		RETURNING `+liveSessionColumns,
+		userID,
+	))
+}
+
+// EndStaleLiveSessions ends sessions idle since idleSince or started before startedSince
+func (r *PostgresRepository) EndStaleLiveSessions(ctx context.Context, idleSince, startedSince time.Time) ([]*domain.LiveSession, error) {
+	rows, err := r.db.Query(ctx, `
+		UPDATE live_sessions SET ended_at = NOW()
+		WHERE ended_at IS NULL AND (last_post_at <= $1 OR started_at <= $2)
+		RETURNING `+liveSessionColumns,
+		idleSince, startedSince,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*domain.LiveSession
+	for rows.Next() {
+		s, err := scanLiveSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/locolive/backend/internal/domain"
+)
+
+// RecordLoginAttempt stores a password login attempt
+func (r *PostgresRepository) RecordLoginAttempt(ctx context.Context, attempt domain.LoginAttempt) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO login_attempts (user_id, email, ip_address, succeeded)
+		VALUES ($1, $2, NULLIF($3, ''), $4)
+	`, attempt.UserID, attempt.Email, attempt.IPAddress, attempt.Succeeded)
+	return err
+}
+
+// CountFailedLoginsFromIP counts failed attempts from ip since the given time
+func (r *PostgresRepository) CountFailedLoginsFromIP(ctx context.Context, ip string, since time.Time) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*) FROM login_attempts
+		WHERE ip_address = $1 AND NOT succeeded AND created_at >= $2
+	`, ip, since).Scan(&count)
+	return count, err
+}
+
+// CountFailedLoginsForEmail counts failed attempts on email since the given
+// time and its last successful login
+func (r *PostgresRepository) CountFailedLoginsForEmail(ctx context.Context, email string, since time.Time) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*) FROM login_attempts
+		WHERE email = $1 AND NOT succeeded AND created_at >= GREATEST($2, COALESCE((
+			SELECT MAX(created_at) FROM login_attempts WHERE email = $1 AND succeeded
+		), $2))
+	`, email, since).Scan(&count)
+	return count, err
+}
+
+// GetUserLockedUntil returns when the user's lock ends, or nil if they aren't locked
+func (r *PostgresRepository) GetUserLockedUntil(ctx context.Context, userID uuid.UUID) (*time.Time, error) {
+	var lockedUntil *time.Time
+	err := r.db.QueryRow(ctx, `
+		SELECT locked_until FROM users WHERE id = $1 AND locked_until > NOW()
+	`, userID).Scan(&lockedUntil)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return lockedUntil, err
+}
+
+// RegisterLoginFailure counts a failed login and locks the user for lockFor
+// once maxFailures are reached in a row, returning the new lock's end if it
+// did. The count restarts after each lock.
+func (r *PostgresRepository) RegisterLoginFailure(ctx context.Context, userID uuid.UUID, maxFailures int, lockFor time.Duration) (*time.Time, error) {
+	var lockedUntil *time.Time
+	err := r.db.QueryRow(ctx, `
+		UPDATE users SET
+			failed_login_attempts = CASE WHEN failed_login_attempts + 1 >= $2 THEN 0 ELSE failed_login_attempts + 1 END,
+			locked_until = CASE WHEN failed_login_attempts + 1 >= $2 THEN NOW() + $3 * INTERVAL '1 second' ELSE locked_until END
+		WHERE id = $1
+		RETURNING CASE WHEN failed_login_attempts = 0 THEN locked_until END
+	`, userID, maxFailures, lockFor.Seconds()).Scan(&lockedUntil)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrUserNotFound
+	}
+	return lockedUntil, err
+}
+
+// ResetLoginFailures clears the user's failure count and any lock
+func (r *PostgresRepository) ResetLoginFailures(ctx context.Context, userID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE users SET failed_login_attempts = 0, locked_until = NULL
+		WHERE id = $1 AND (failed_login_attempts > 0 OR locked_until IS NOT NULL)
+	`, userID)
+	return err
+}
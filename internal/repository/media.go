@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/locolive/backend/internal/domain"
+)
+
+// mediaName is the file name at the end of a URL column, as indexed by
+// migrations 058, 059 and 062
+func mediaName(column string) string {
+	return `substring(` + column + ` from '[^/]+$')`
+}
+
+// GetAccessibleMediaURL returns the stored URL of the upload named name if
+// the viewer may fetch it
+func (r *PostgresRepository) GetAccessibleMediaURL(ctx context.Context, viewerID uuid.UUID, name string) (string, error) {
+	query := `
+		SELECT s.media_url FROM stories s
+		JOIN users u ON u.id = s.user_id
+		WHERE ` + mediaName("s.media_url") + ` = $2 AND (` + storyVisibleTo("$1") + `
+			OR (s.expires_at > NOW() AND s.hidden_at IS NULL AND EXISTS (
+				SELECT 1 FROM messages m
+				JOIN chat_participants cp ON cp.chat_id = m.chat_id AND cp.user_id = $1
+				WHERE m.shared_story_id = s.id AND m.deleted_at IS NULL
+			)))
+		UNION ALL
+		SELECT a.media_url FROM story_archive a
+		WHERE ` + mediaName("a.media_url") + ` = $2 AND a.user_id = $1
+		UNION ALL
+		SELECT c.avatar_url FROM chats c
+		JOIN chat_participants cp ON cp.chat_id = c.id AND cp.user_id = $1
+		WHERE ` + mediaName("c.avatar_url") + ` = $2
+		UNION ALL
+		SELECT u.avatar_url FROM users u WHERE ` + mediaName("u.avatar_url") + ` = $2
+		UNION ALL
+		SELECT k.image_url FROM cards k WHERE ` + mediaName("k.image_url") + ` = $2
+		UNION ALL
+		SELECT e.file_url FROM data_exports e
+		WHERE ` + mediaName("e.file_url") + ` = $2 AND e.user_id = $1 AND e.status = 'ready' AND e.expires_at > NOW()
+		UNION ALL
+		SELECT m.media_url FROM messages m
+		JOIN chat_participants cp ON cp.chat_id = m.chat_id AND cp.user_id = $1
+		WHERE ` + mediaName("m.media_url") + ` = $2 AND m.media_url IS NOT NULL AND m.deleted_at IS NULL
+		LIMIT 1
+	`
+	return r.queryMediaURL(ctx, query, viewerID, name)
+}
+
+// GetMediaURL returns the stored URL of the upload named name, whoever may
+// see it. Data exports are left out; they are for their owner alone.
+func (r *PostgresRepository) GetMediaURL(ctx context.Context, name string) (string, error) {
+	query := `
+		SELECT media_url FROM stories WHERE ` + mediaName("media_url") + ` = $1
+		UNION ALL
+		SELECT media_url FROM story_archive WHERE ` + mediaName("media_url") + ` = $1
+		UNION ALL
+		SELECT avatar_url FROM chats WHERE ` + mediaName("avatar_url") + ` = $1
+		UNION ALL
+		SELECT avatar_url FROM users WHERE ` + mediaName("avatar_url") + ` = $1
+		UNION ALL
+		SELECT image_url FROM cards WHERE ` + mediaName("image_url") + ` = $1
+		UNION ALL
+		SELECT media_url FROM messages WHERE ` + mediaName("media_url") + ` = $1 AND media_url IS NOT NULL
+		LIMIT 1
+	`
+	return r.queryMediaURL(ctx, query, name)
+}
+
+func (r *PostgresRepository) queryMediaURL(ctx context.Context, query string, args ...interface{}) (string, error) {
+	var url string
+	err := r.db.QueryRow(ctx, query, args...).Scan(&url)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", domain.ErrMediaNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return url, nil
+}
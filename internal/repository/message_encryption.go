@@ -0,0 +1,367 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/locolive/backend/internal/domain"
+	"github.com/locolive/backend/internal/encryption"
+	"go.uber.org/zap"
+)
+
+// maxCachedChatKeys bounds the in-memory cache of unwrapped chat keys
+const maxCachedChatKeys = 10000
+
+// messageEncryptionBatch is the number of rows encrypted or rewrapped per query
+const messageEncryptionBatch = 500
+
+var errMessageEncryptionDisabled = errors.New("message is encrypted but message encryption is not configured")
+
+// queryExecer is satisfied by both the pool and a transaction
+type queryExecer interface {
+	execer
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+type chatKeyRef struct {
+	chatID  uuid.UUID
+	version int
+}
+
+// messageKeyring seals chat messages with per-chat data keys. Unwrapped keys
+// are cached so a KMS-backed provider isn't called on every read.
+type messageKeyring struct {
+	provider encryption.KeyProvider
+	rotation time.Duration
+
+	mu   sync.Mutex
+	keys map[chatKeyRef][]byte
+}
+
+func (k *messageKeyring) cached(ref chatKeyRef) []byte {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.keys[ref]
+}
+
+func (k *messageKeyring) remember(ref chatKeyRef, key []byte) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if len(k.keys) >= maxCachedChatKeys {
+		k.keys = make(map[chatKeyRef][]byte)
+	}
+	k.keys[ref] = key
+}
+
+// EnableMessageEncryption encrypts new messages with per-chat data keys
+// wrapped by provider. A chat starts a new data key once its current one is
+// older than rotation. Without it messages are stored as plaintext.
+func (r *PostgresRepository) EnableMessageEncryption(provider encryption.KeyProvider, rotation time.Duration) {
+	r.messageKeys = &messageKeyring{
+		provider: provider,
+		rotation: rotation,
+		keys:     make(map[chatKeyRef][]byte),
+	}
+}
+
+// messageAAD binds a ciphertext to its chat so it can't be moved to another chat
+func messageAAD(chatID uuid.UUID) []byte {
+	return chatID[:]
+}
+
+// sealMessage encrypts content under the chat's current data key
+func (r *PostgresRepository) sealMessage(ctx context.Context, db queryExecer, chatID uuid.UUID, content string) ([]byte, int, error) {
+	version, key, err := r.currentChatKey(ctx, db, chatID)
+	if err != nil {
+		return nil, 0, err
+	}
+	ciphertext, err := encryption.Seal(key, []byte(content), messageAAD(chatID))
+	return ciphertext, version, err
+}
+
+// openMessage sets msg.Content from whichever of the plaintext or encrypted columns is set
+func (r *PostgresRepository) openMessage(ctx context.Context, msg *domain.Message, content *string, ciphertext []byte, version *int) error {
+	text, err := r.openChatText(ctx, msg.ChatID, content, ciphertext, version)
+	if err != nil {
+		return err
+	}
+	msg.Content = text
+	return nil
+}
+
+// openChatText returns text stored for a chat, a message or a draft, from
+// whichever of the plaintext or encrypted columns is set
+func (r *PostgresRepository) openChatText(ctx context.Context, chatID uuid.UUID, content *string, ciphertext []byte, version *int) (string, error) {
+	if ciphertext == nil {
+		if content != nil {
+			return *content, nil
+		}
+		return "", nil
+	}
+	if r.messageKeys == nil || version == nil {
+		return "", errMessageEncryptionDisabled
+	}
+
+	key, err := r.chatKey(ctx, chatID, *version)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := encryption.Open(key, ciphertext, messageAAD(chatID))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// currentChatKey returns the chat's newest data key, starting a new version
+// if it has none or the newest is due for rotation
+func (r *PostgresRepository) currentChatKey(ctx context.Context, db queryExecer, chatID uuid.UUID) (int, []byte, error) {
+	var (
+		version     int
+		masterKeyID string
+		wrapped     []byte
+		createdAt   time.Time
+	)
+	err := db.QueryRow(ctx, `
+		SELECT version, master_key_id, wrapped_key, created_at
+		FROM chat_keys WHERE chat_id = $1
+		ORDER BY version DESC LIMIT 1
+	`, chatID).Scan(&version, &masterKeyID, &wrapped, &createdAt)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil, err
+	}
+	if err == nil && time.Since(createdAt) < r.messageKeys.rotation {
+		key, err := r.unwrapChatKey(ctx, chatKeyRef{chatID, version}, masterKeyID, wrapped)
+		return version, key, err
+	}
+
+	key, err := encryption.NewDataKey()
+	if err != nil {
+		return 0, nil, err
+	}
+	masterKeyID, wrapped, err = r.messageKeys.provider.Wrap(ctx, key)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	next := chatKeyRef{chatID, version + 1}
+	tag, err := db.Exec(ctx, `
+		INSERT INTO chat_keys (chat_id, version, master_key_id, wrapped_key)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (chat_id, version) DO NOTHING
+	`, chatID, next.version, masterKeyID, wrapped)
+	if err != nil {
+		return 0, nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		// Another writer started this version first; use theirs
+		key, err := r.loadChatKey(ctx, db, next)
+		return next.version, key, err
+	}
+
+	r.messageKeys.remember(next, key)
+	return next.version, key, nil
+}
+
+// chatKey returns a specific version of a chat's data key
+func (r *PostgresRepository) chatKey(ctx context.Context, chatID uuid.UUID, version int) ([]byte, error) {
+	ref := chatKeyRef{chatID, version}
+	if key := r.messageKeys.cached(ref); key != nil {
+		return key, nil
+	}
+	return r.loadChatKey(ctx, r.db, ref)
+}
+
+func (r *PostgresRepository) loadChatKey(ctx context.Context, db queryExecer, ref chatKeyRef) ([]byte, error) {
+	var masterKeyID string
+	var wrapped []byte
+	err := db.QueryRow(ctx, `
+		SELECT master_key_id, wrapped_key FROM chat_keys WHERE chat_id = $1 AND version = $2
+	`, ref.chatID, ref.version).Scan(&masterKeyID, &wrapped)
+	if err != nil {
+		return nil, err
+	}
+	return r.unwrapChatKey(ctx, ref, masterKeyID, wrapped)
+}
+
+func (r *PostgresRepository) unwrapChatKey(ctx context.Context, ref chatKeyRef, masterKeyID string, wrapped []byte) ([]byte, error) {
+	if key := r.messageKeys.cached(ref); key != nil {
+		return key, nil
+	}
+	key, err := r.messageKeys.provider.Unwrap(ctx, masterKeyID, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	r.messageKeys.remember(ref, key)
+	return key, nil
+}
+
+// EncryptPlaintextMessages encrypts up to limit messages still stored as
+// plaintext, oldest first, returning how many it encrypted. Deleted messages
+// are left to be wiped instead.
+func (r *PostgresRepository) EncryptPlaintextMessages(ctx context.Context, limit int) (int, error) {
+	if r.messageKeys == nil {
+		return 0, errMessageEncryptionDisabled
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT id, chat_id, content, created_at FROM messages
+		WHERE content_ciphertext IS NULL AND deleted_at IS NULL
+		ORDER BY created_at LIMIT $1
+	`, limit)
+	if err != nil {
+		return 0, err
+	}
+	var pending []domain.Message
+	for rows.Next() {
+		var msg domain.Message
+		if err := rows.Scan(&msg.ID, &msg.ChatID, &msg.Content, &msg.CreatedAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		pending = append(pending, msg)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	encrypted := 0
+	for _, msg := range pending {
+		ciphertext, version, err := r.sealMessage(ctx, r.db, msg.ChatID, msg.Content)
+		if err != nil {
+			return encrypted, err
+		}
+		tag, err := r.db.Exec(ctx, `
+			UPDATE messages SET content = NULL, content_ciphertext = $3, key_version = $4
+			WHERE id = $1 AND created_at = $2 AND content_ciphertext IS NULL AND deleted_at IS NULL
+		`, msg.ID, msg.CreatedAt, ciphertext, version)
+		if err != nil {
+			return encrypted, err
+		}
+		encrypted += int(tag.RowsAffected())
+	}
+	return encrypted, nil
+}
+
+// RewrapChatKeys rewraps up to limit chat keys that aren't wrapped with the
+// current master key, so retired master keys can be removed from config
+func (r *PostgresRepository) RewrapChatKeys(ctx context.Context, limit int) (int, error) {
+	if r.messageKeys == nil {
+		return 0, errMessageEncryptionDisabled
+	}
+	provider := r.messageKeys.provider
+
+	rows, err := r.db.Query(ctx, `
+		SELECT chat_id, version, master_key_id, wrapped_key FROM chat_keys
+		WHERE master_key_id <> $1 LIMIT $2
+	`, provider.CurrentKeyID(), limit)
+	if err != nil {
+		return 0, err
+	}
+	type staleKey struct {
+		ref         chatKeyRef
+		masterKeyID string
+		wrapped     []byte
+	}
+	var stale []staleKey
+	for rows.Next() {
+		var k staleKey
+		if err := rows.Scan(&k.ref.chatID, &k.ref.version, &k.masterKeyID, &k.wrapped); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		stale = append(stale, k)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	rewrapped := 0
+	for _, k := range stale {
+		key, err := provider.Unwrap(ctx, k.masterKeyID, k.wrapped)
+		if err != nil {
+			return rewrapped, err
+		}
+		masterKeyID, wrapped, err := provider.Wrap(ctx, key)
+		if err != nil {
+			return rewrapped, err
+		}
+		tag, err := r.db.Exec(ctx, `
+			UPDATE chat_keys SET master_key_id = $3, wrapped_key = $4
+			WHERE chat_id = $1 AND version = $2 AND master_key_id = $5
+		`, k.ref.chatID, k.ref.version, masterKeyID, wrapped, k.masterKeyID)
+		if err != nil {
+			return rewrapped, err
+		}
+		rewrapped += int(tag.RowsAffected())
+	}
+	return rewrapped, nil
+}
+
+// MaintainMessageEncryption encrypts remaining plaintext messages and rewraps
+// chat keys under the current master key, a batch at a time. It returns the
+// rows changed and the failure of each step that stopped early.
+func (r *PostgresRepository) MaintainMessageEncryption(ctx context.Context, logger *zap.Logger) (int64, error) {
+	jobs := []struct {
+		name string
+		run  func(ctx context.Context, limit int) (int, error)
+	}{
+		{"encrypt plaintext messages", r.EncryptPlaintextMessages},
+		{"rewrap chat keys", r.RewrapChatKeys},
+	}
+
+	var affected int64
+	var errs []error
+	for _, job := range jobs {
+		total := 0
+		for ctx.Err() == nil {
+			n, err := job.run(ctx, messageEncryptionBatch)
+			total += n
+			if err != nil {
+				logger.Error("message encryption maintenance failed", zap.String("job", job.name), zap.Error(err))
+				errs = append(errs, err)
+				break
+			}
+			if n < messageEncryptionBatch {
+				break
+			}
+		}
+		if total > 0 {
+			logger.Info("message encryption maintenance", zap.String("job", job.name), zap.Int("rows", total))
+		}
+		affected += int64(total)
+	}
+	return affected, errors.Join(errs...)
+}
+
+// StartMessageEncryptionWorker runs encryption maintenance immediately and then
+// on every interval. It does nothing unless message encryption is enabled.
+func (r *PostgresRepository) StartMessageEncryptionWorker(ctx context.Context, interval time.Duration, jobs *domain.JobTracker, logger *zap.Logger) {
+	if r.messageKeys == nil {
+		return
+	}
+	maintain := func(ctx context.Context) (int64, error) {
+		return r.MaintainMessageEncryption(ctx, logger)
+	}
+	go func() {
+		jobs.Track(ctx, domain.JobMessageEncryption, maintain)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				jobs.Track(ctx, domain.JobMessageEncryption, maintain)
+			}
+		}
+	}()
+}
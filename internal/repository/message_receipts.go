@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/domain"
+)
+
+// MarkMessagesDelivered marks the user's receipts for the given messages
+// delivered and groups the newly marked ones into one update per sender
+func (r *PostgresRepository) MarkMessagesDelivered(ctx context.Context, chatID, userID uuid.UUID, messageIDs []uuid.UUID) ([]*domain.DeliveryUpdate, error) {
+	rows, err := r.db.Query(ctx, `
+		UPDATE message_receipts SET delivered_at = NOW()
+		WHERE chat_id = $1 AND user_id = $2 AND message_id = ANY($3) AND delivered_at IS NULL
+		RETURNING message_id, sender_id, delivered_at
+	`, chatID, userID, messageIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var updates []*domain.DeliveryUpdate
+	bySender := make(map[uuid.UUID]*domain.DeliveryUpdate)
+	for rows.Next() {
+		var id, senderID uuid.UUID
+		var deliveredAt time.Time
+		if err := rows.Scan(&id, &senderID, &deliveredAt); err != nil {
+			return nil, err
+		}
+		update, ok := bySender[senderID]
+		if !ok {
+			update = &domain.DeliveryUpdate{
+				ChatID:   chatID,
+				UserID:   userID,
+				SenderID: senderID,
+				Status:   domain.MessageStatusDelivered,
+				At:       deliveredAt,
+			}
+			bySender[senderID] = update
+			updates = append(updates, update)
+		}
+		update.MessageIDs = append(update.MessageIDs, id)
+	}
+	return updates, rows.Err()
+}
+
+// MarkMessageDeliveredTo marks one message delivered to each of the given
+// recipients that hadn't had it yet, with one update per recipient
+func (r *PostgresRepository) MarkMessageDeliveredTo(ctx context.Context, messageID uuid.UUID, userIDs []uuid.UUID) ([]*domain.DeliveryUpdate, error) {
+	rows, err := r.db.Query(ctx, `
+		UPDATE message_receipts SET delivered_at = NOW()
+		WHERE message_id = $1 AND user_id = ANY($2) AND delivered_at IS NULL
+		RETURNING chat_id, user_id, sender_id, delivered_at
+	`, messageID, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var updates []*domain.DeliveryUpdate
+	for rows.Next() {
+		update := domain.DeliveryUpdate{
+			MessageIDs: []uuid.UUID{messageID},
+			Status:     domain.MessageStatusDelivered,
+		}
+		if err := rows.Scan(&update.ChatID, &update.UserID, &update.SenderID, &update.At); err != nil {
+			return nil, err
+		}
+		updates = append(updates, &update)
+	}
+	return updates, rows.Err()
+}
+
+// PurgeMessageReceipts deletes receipts created before the cutoff, keeping
+// those of senders or recipients under legal hold
+func (r *PostgresRepository) PurgeMessageReceipts(ctx context.Context, createdBefore time.Time) (int64, error) {
+	query := `DELETE FROM message_receipts WHERE created_at < $1 AND ` +
+		notOnLegalHold("message_receipts.user_id") + ` AND ` + notOnLegalHold("message_receipts.sender_id")
+	tag, err := r.db.Exec(ctx, query, createdBefore)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
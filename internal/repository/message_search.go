@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/domain"
+)
+
+// messageSearchVector must match idx_messages_content_search
+const messageSearchVector = `to_tsvector('simple', COALESCE(content, ''))`
+
+// SearchMessages finds a chat's messages after since containing the query's
+// words, ranked by how well they match and then newest first
+func (r *PostgresRepository) SearchMessages(ctx context.Context, chatID uuid.UUID, query string, since *time.Time, limit, offset int) ([]*domain.Message, error) {
+	// Encrypted messages only store ciphertext
+	if r.messageKeys != nil {
+		return nil, domain.ErrMessageSearchUnavailable
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT `+messageColumns+`
+		FROM messages
+		WHERE chat_id = $1 AND `+messageSearchVector+` @@ plainto_tsquery('simple', $2)
+			AND deleted_at IS NULL AND ($5::timestamptz IS NULL OR created_at > $5)
+		ORDER BY ts_rank(`+messageSearchVector+`, plainto_tsquery('simple', $2)) DESC, created_at DESC, id DESC
+		LIMIT $3 OFFSET $4
+	`, chatID, query, limit, offset, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []*domain.Message
+	for rows.Next() {
+		msg, err := r.scanMessage(ctx, rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+	}
+	return messages, rows.Err()
+}
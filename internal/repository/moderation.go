@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/locolive/backend/internal/domain"
+)
+
+const moderationActionColumns = `id, target_type, target_id, owner_id, report_count, status, created_at, reviewed_by, reviewed_at`
+
+func scanModerationAction(row pgx.Row) (*domain.ModerationAction, error) {
+	var a domain.ModerationAction
+	err := row.Scan(&a.ID, &a.TargetType, &a.TargetID, &a.OwnerID, &a.ReportCount, &a.Status, &a.CreatedAt, &a.ReviewedBy, &a.ReviewedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// CreateReport stores a report, returning ErrAlreadyReported for repeat reports
+func (r *PostgresRepository) CreateReport(ctx context.Context, report domain.Report) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO reports (reporter_id, target_type, target_id, reason, details)
+		VALUES ($1, $2, $3, $4, $5)
+	`, report.ReporterID, report.TargetType, report.TargetID, report.Reason, report.Details)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return domain.ErrAlreadyReported
+	}
+	return err
+}
+
+// CountRecentReports counts reports on a target since the given time. Reports
+// made before the target's last reversed takedown were already reviewed and don't count.
+func (r *PostgresRepository) CountRecentReports(ctx context.Context, targetType domain.ReportTargetType, targetID uuid.UUID, since time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM reports
+		WHERE target_type = $1 AND target_id = $2
+		AND created_at >= GREATEST($3, (
+			SELECT MAX(reviewed_at) FROM moderation_actions
+			WHERE target_type = $1 AND target_id = $2 AND status = 'reversed'
+		))
+	`
+	var n int
+	err := r.db.QueryRow(ctx, query, targetType, targetID, since).Scan(&n)
+	return n, err
+}
+
+// ApplyTakedown hides a story or freezes a chat and records the pending action
+func (r *PostgresRepository) ApplyTakedown(ctx context.Context, action *domain.ModerationAction) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO moderation_actions (target_type, target_id, owner_id, report_count)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (target_type, target_id) WHERE status IN ('pending', 'upheld') DO NOTHING
+		RETURNING id, status, created_at
+	`, action.TargetType, action.TargetID, action.OwnerID, action.ReportCount).Scan(&action.ID, &action.Status, &action.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := setTakedown(ctx, tx, action.TargetType, action.TargetID, true); err != nil {
+		return false, err
+	}
+	return true, tx.Commit(ctx)
+}
+
+// setTakedown hides, freezes or restores the target of a moderation action. A story
+// under an open copyright claim stays hidden.
+func setTakedown(ctx context.Context, tx pgx.Tx, targetType domain.ReportTargetType, targetID uuid.UUID, down bool) error {
+	var query string
+	switch targetType {
+	case domain.ReportTargetStory:
+		if !down {
+			return restoreStory(ctx, tx, targetID)
+		}
+		query = `UPDATE stories SET hidden_at = CASE WHEN $2 THEN COALESCE(hidden_at, NOW()) END WHERE id = $1`
+	case domain.ReportTargetChat:
+		query = `UPDATE chats SET frozen_at = CASE WHEN $2 THEN NOW() END WHERE id = $1`
+	case domain.ReportTargetComment:
+		query = `UPDATE story_comments SET hidden_at = CASE WHEN $2 THEN COALESCE(hidden_at, NOW()) END WHERE id = $1`
+	default:
+		return errors.New("unknown moderation target type")
+	}
+	_, err := tx.Exec(ctx, query, targetID, down)
+	return err
+}
+
+// GetModerationActions lists actions with the given status, oldest first
+func (r *PostgresRepository) GetModerationActions(ctx context.Context, status domain.ModerationStatus, limit, offset int) ([]*domain.ModerationAction, error) {
+	query := `SELECT ` + moderationActionColumns + ` FROM moderation_actions WHERE status = $1 ORDER BY created_at LIMIT $2 OFFSET $3`
+	rows, err := r.db.Query(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var actions []*domain.ModerationAction
+	for rows.Next() {
+		a, err := scanModerationAction(rows)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, a)
+	}
+	return actions, rows.Err()
+}
+
+// ResolveModerationAction marks a pending action upheld or reversed, restoring the target on reversal
+func (r *PostgresRepository) ResolveModerationAction(ctx context.Context, actionID, reviewerID uuid.UUID, status domain.ModerationStatus) (*domain.ModerationAction, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	action, err := scanModerationAction(tx.QueryRow(ctx, `
+		UPDATE moderation_actions
+		SET status = $2, reviewed_by = $3, reviewed_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING `+moderationActionColumns,
+		actionID, status, reviewerID,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		var exists bool
+		if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM moderation_actions WHERE id = $1)`, actionID).Scan(&exists); err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, domain.ErrModerationActionResolved
+		}
+		return nil, domain.ErrModerationActionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if status == domain.ModerationReversed {
+		if err := setTakedown(ctx, tx, action.TargetType, action.TargetID, false); err != nil {
+			return nil, err
+		}
+	}
+	return action, tx.Commit(ctx)
+}
+
+// GetStoryOwner returns the author of an active story
+func (r *PostgresRepository) GetStoryOwner(ctx context.Context, storyID uuid.UUID) (uuid.UUID, error) {
+	var ownerID uuid.UUID
+	err := r.db.QueryRow(ctx, `
+		SELECT user_id FROM stories WHERE id = $1 AND expires_at > NOW() AND hidden_at IS NULL
+	`, storyID).Scan(&ownerID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, domain.ErrStoryNotFound
+	}
+	return ownerID, err
+}
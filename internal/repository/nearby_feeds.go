@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/locolive/backend/internal/domain"
+)
+
+// MaterializeNearbyFeed stores the area's first max stories in the order
+// GetStoriesByLocation ranks them, around the area's center
+func (r *PostgresRepository) MaterializeNearbyFeed(ctx context.Context, area domain.FeedArea, max int) (int, error) {
+	query := `
+		INSERT INTO nearby_feeds (cell, radius, story_ids, built_at)
+		SELECT $7, $8, ARRAY(SELECT s.id ` + nearbyStoriesQuery + ` LIMIT $6), NOW()
+		ON CONFLICT (cell, radius) DO UPDATE SET story_ids = EXCLUDED.story_ids, built_at = EXCLUDED.built_at
+		RETURNING cardinality(story_ids)
+	`
+	idleSince, startedSince := domain.LiveCutoffs(time.Now())
+	var n int
+	err := r.db.QueryRow(ctx, query, area.Lat, area.Lng, area.Radius, idleSince, startedSince, max, area.Cell, int(area.Radius)).Scan(&n)
+	return n, err
+}
+
+func (r *PostgresRepository) GetNearbyFeed(ctx context.Context, area domain.FeedArea, freshSince time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.QueryRow(ctx, `
+		SELECT story_ids FROM nearby_feeds WHERE cell = $1 AND radius = $2 AND built_at > $3
+	`, area.Cell, int(area.Radius), freshSince).Scan(&ids)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNearbyFeedNotFound
+	}
+	return ids, err
+}
+
+// GetActiveStoriesByIDs loads the stories that haven't expired or been
+// hidden since their IDs were taken, keeping the given order
+func (r *PostgresRepository) GetActiveStoriesByIDs(ctx context.Context, ids []uuid.UUID) ([]*domain.Story, error) {
+	query := `
+		SELECT ` + storyWithUserColumns + `
+		FROM unnest($1::uuid[]) WITH ORDINALITY AS ids(id, ord)
+		JOIN stories s ON s.id = ids.id
+		JOIN users u ON s.user_id = u.id
+		WHERE s.expires_at > NOW() AND s.hidden_at IS NULL
+		ORDER BY ids.ord
+	`
+	return r.queryStories(ctx, query, ids)
+}
+
+func (r *PostgresRepository) DeleteStaleNearbyFeeds(ctx context.Context, builtBefore time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM nearby_feeds WHERE built_at < $1`, builtBefore)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/domain"
+)
+
+// MarkNotificationOpened records the first open of a user's notification
+func (r *PostgresRepository) MarkNotificationOpened(ctx context.Context, userID, notificationID uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE notifications SET opened_at = COALESCE(opened_at, NOW()), is_read = TRUE
+		WHERE id = $1 AND user_id = $2
+	`, notificationID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotificationNotFound
+	}
+	return nil
+}
+
+// RecordNotificationDelivery logs the outcome of one push
+func (r *PostgresRepository) RecordNotificationDelivery(ctx context.Context, d domain.NotificationDelivery) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO notification_deliveries (notification_id, user_id, type, status, error_class)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+	`, d.NotificationID, d.UserID, d.Type, d.Status, d.ErrorClass)
+	return err
+}
+
+// GetNotificationDeliveryStats aggregates push outcomes and opens per
+// notification type for pushes sent since the given time
+func (r *PostgresRepository) GetNotificationDeliveryStats(ctx context.Context, since time.Time) ([]*domain.NotificationDeliveryStats, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT d.type,
+			COUNT(*) FILTER (WHERE d.status = 'sent'),
+			COUNT(*) FILTER (WHERE d.status = 'failed'),
+			COUNT(DISTINCT d.notification_id) FILTER (WHERE d.status = 'sent'),
+			COUNT(DISTINCT d.notification_id) FILTER (WHERE d.status = 'sent' AND EXISTS (
+				SELECT 1 FROM notifications n
+				WHERE n.id = d.notification_id AND n.opened_at IS NOT NULL
+			))
+		FROM notification_deliveries d
+		WHERE d.sent_at >= $1
+		GROUP BY d.type
+		ORDER BY d.type
+	`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []*domain.NotificationDeliveryStats
+	byType := make(map[string]*domain.NotificationDeliveryStats)
+	for rows.Next() {
+		s := &domain.NotificationDeliveryStats{Errors: make(map[string]int)}
+		if err := rows.Scan(&s.Type, &s.Sent, &s.Failed, &s.Delivered, &s.Opened); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+		byType[s.Type] = s
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	errRows, err := r.db.Query(ctx, `
+		SELECT type, COALESCE(error_class, 'unknown'), COUNT(*)
+		FROM notification_deliveries
+		WHERE sent_at >= $1 AND status = 'failed'
+		GROUP BY type, error_class
+	`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer errRows.Close()
+
+	for errRows.Next() {
+		var typ, class string
+		var count int
+		if err := errRows.Scan(&typ, &class, &count); err != nil {
+			return nil, err
+		}
+		if s, ok := byType[typ]; ok {
+			s.Errors[class] += count
+		}
+	}
+	return stats, errRows.Err()
+}
+
+// PurgeNotificationDeliveries removes push outcomes older than the given time
+func (r *PostgresRepository) PurgeNotificationDeliveries(ctx context.Context, sentBefore time.Time) (int64, error) {
+	query := `DELETE FROM notification_deliveries WHERE sent_at < $1 AND ` + notOnLegalHold("notification_deliveries.user_id")
+	tag, err := r.db.Exec(ctx, query, sentBefore)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
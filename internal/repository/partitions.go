@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/locolive/backend/internal/config"
+	"github.com/locolive/backend/internal/domain"
+	"go.uber.org/zap"
+)
+
+// EnsurePartitions creates any missing monthly partitions for table from the
+// current month through monthsAhead months in the future
+func (r *PostgresRepository) EnsurePartitions(ctx context.Context, table string, monthsAhead int) (int, error) {
+	var created int
+	err := r.db.QueryRow(ctx, `SELECT create_monthly_partitions($1, CURRENT_DATE, $2)`, table, monthsAhead).Scan(&created)
+	return created, err
+}
+
+// DropPartitionsBefore drops monthly partitions of table that only hold rows older than cutoff
+func (r *PostgresRepository) DropPartitionsBefore(ctx context.Context, table string, cutoff time.Time) (int, error) {
+	var dropped int
+	err := r.db.QueryRow(ctx, `SELECT drop_partitions_before($1, $2)`, table, cutoff).Scan(&dropped)
+	return dropped, err
+}
+
+// heldRowsQueries check whether a partitioned table has rows older than $1 that
+// belong to a user under legal hold; chat messages belong to every participant
+var heldRowsQueries = map[string]string{
+	"messages": `SELECT EXISTS (
+		SELECT 1 FROM messages m
+		JOIN chat_participants cp ON cp.chat_id = m.chat_id
+		WHERE m.created_at < $1 AND NOT ` + notOnLegalHold("cp.user_id") + `
+	)`,
+	"notifications": `SELECT EXISTS (
+		SELECT 1 FROM notifications n
+		WHERE n.created_at < $1 AND NOT ` + notOnLegalHold("n.user_id") + `
+	)`,
+}
+
+// HasLegalHoldRowsBefore reports whether table has rows older than cutoff that
+// are under legal hold, in which case its old partitions must not be dropped
+func (r *PostgresRepository) HasLegalHoldRowsBefore(ctx context.Context, table string, cutoff time.Time) (bool, error) {
+	query, ok := heldRowsQueries[table]
+	if !ok {
+		return false, nil
+	}
+	var held bool
+	err := r.db.QueryRow(ctx, query, cutoff).Scan(&held)
+	return held, err
+}
+
+// MaintainPartitions keeps future partitions available and applies retention.
+// It carries on past failures, returning them all along with the partitions
+// created and dropped and the rows purged.
+func (r *PostgresRepository) MaintainPartitions(ctx context.Context, cfg config.PartitionConfig, logger *zap.Logger) (int64, error) {
+	var affected int64
+	var errs []error
+
+	retention := map[string]time.Duration{
+		"messages":      cfg.MessageRetention,
+		"notifications": cfg.NotificationRetention,
+	}
+
+	for table, keep := range retention {
+		// Inserts fail outright if no partition covers NOW(), so this must keep working
+		created, err := r.EnsurePartitions(ctx, table, cfg.MonthsAhead)
+		if err != nil {
+			logger.Error("failed to create partitions", zap.String("table", table), zap.Error(err))
+			errs = append(errs, err)
+		} else if created > 0 {
+			affected += int64(created)
+			logger.Info("created partitions", zap.String("table", table), zap.Int("count", created))
+		}
+
+		if keep <= 0 {
+			continue
+		}
+		cutoff := time.Now().Add(-keep)
+
+		// Partitions are dropped whole, so any held row keeps them all until the hold is released
+		held, err := r.HasLegalHoldRowsBefore(ctx, table, cutoff)
+		if err != nil {
+			logger.Error("failed to check legal holds", zap.String("table", table), zap.Error(err))
+			errs = append(errs, err)
+			continue
+		}
+		if held {
+			logger.Warn("skipping partition retention for data under legal hold", zap.String("table", table))
+			continue
+		}
+
+		dropped, err := r.DropPartitionsBefore(ctx, table, cutoff)
+		if err != nil {
+			logger.Error("failed to drop expired partitions", zap.String("table", table), zap.Error(err))
+			errs = append(errs, err)
+		} else if dropped > 0 {
+			affected += int64(dropped)
+			logger.Info("dropped expired partitions", zap.String("table", table), zap.Int("count", dropped))
+		}
+	}
+
+	// Message receipts aren't partitioned but share the messages' retention
+	if keep := cfg.MessageRetention; keep > 0 {
+		purged, err := r.PurgeMessageReceipts(ctx, time.Now().Add(-keep))
+		if err != nil {
+			logger.Error("failed to purge message receipts", zap.Error(err))
+			errs = append(errs, err)
+		} else if purged > 0 {
+			affected += purged
+			logger.Info("purged message receipts", zap.Int64("count", purged))
+		}
+	}
+
+	// Push outcomes aren't partitioned but share the notifications' retention
+	if cfg.NotificationRetention > 0 {
+		purged, err := r.PurgeNotificationDeliveries(ctx, time.Now().Add(-cfg.NotificationRetention))
+		if err != nil {
+			logger.Error("failed to purge notification deliveries", zap.Error(err))
+			errs = append(errs, err)
+		} else if purged > 0 {
+			affected += purged
+			logger.Info("purged notification deliveries", zap.Int64("count", purged))
+		}
+	}
+	return affected, errors.Join(errs...)
+}
+
+// StartPartitionWorker runs partition maintenance immediately and then on every interval
+func (r *PostgresRepository) StartPartitionWorker(ctx context.Context, cfg config.PartitionConfig, jobs *domain.JobTracker, logger *zap.Logger) {
+	maintain := func(ctx context.Context) (int64, error) {
+		return r.MaintainPartitions(ctx, cfg, logger)
+	}
+	go func() {
+		jobs.Track(ctx, domain.JobPartitions, maintain)
+
+		ticker := time.NewTicker(cfg.MaintenanceInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				jobs.Track(ctx, domain.JobPartitions, maintain)
+			}
+		}
+	}()
+}
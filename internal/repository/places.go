@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/locolive/backend/internal/domain"
+)
+
+const savedPlaceColumns = `id, user_id, name, kind, lat, lng, radius_meters, story_privacy, created_at, updated_at`
+
+func scanSavedPlace(row pgx.Row) (*domain.SavedPlace, error) {
+	var p domain.SavedPlace
+	err := row.Scan(&p.ID, &p.UserID, &p.Name, &p.Kind, &p.Lat, &p.Lng, &p.RadiusMeters, &p.StoryPrivacy, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// CreatePlace saves a new place for a user
+func (r *PostgresRepository) CreatePlace(ctx context.Context, params domain.CreatePlaceParams) (*domain.SavedPlace, error) {
+	query := `
+		INSERT INTO saved_places (user_id, name, kind, lat, lng, radius_meters, story_privacy)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + savedPlaceColumns
+	return scanSavedPlace(r.db.QueryRow(ctx, query,
+		params.UserID, params.Name, params.Kind, params.Lat, params.Lng, params.RadiusMeters, params.StoryPrivacy,
+	))
+}
+
+// GetPlacesByUser returns all of a user's saved places
+func (r *PostgresRepository) GetPlacesByUser(ctx context.Context, userID uuid.UUID) ([]*domain.SavedPlace, error) {
+	query := `SELECT ` + savedPlaceColumns + ` FROM saved_places WHERE user_id = $1 ORDER BY created_at`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var places []*domain.SavedPlace
+	for rows.Next() {
+		p, err := scanSavedPlace(rows)
+		if err != nil {
+			return nil, err
+		}
+		places = append(places, p)
+	}
+	return places, rows.Err()
+}
+
+// DeletePlace removes a saved place owned by the user
+func (r *PostgresRepository) DeletePlace(ctx context.Context, userID, placeID uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM saved_places WHERE id = $1 AND user_id = $2`, placeID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrPlaceNotFound
+	}
+	return nil
+}
@@ -16,6 +16,9 @@ import (
 // PostgresRepository implements domain.AuthRepository using PostgreSQL
 type PostgresRepository struct {
 	db *pgxpool.Pool
+
+	// messageKeys is nil unless message encryption is enabled
+	messageKeys *messageKeyring
 }
 
 // NewPostgresRepository creates a new PostgreSQL repository
@@ -26,9 +29,9 @@ func NewPostgresRepository(db *pgxpool.Pool) *PostgresRepository {
 // CreateUser creates a new user
 func (r *PostgresRepository) CreateUser(ctx context.Context, params domain.CreateUserParams) (*domain.User, error) {
 	query := `
-		INSERT INTO users (email, phone, password_hash, name, google_id, email_verified)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, email, phone, name, avatar_url, bio, gender, date_of_birth, visibility, google_id, email_verified, phone_verified, is_active, created_at, updated_at
+		INSERT INTO users (email, phone, password_hash, name, google_id, apple_id, avatar_url, email_verified, phone_verified)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, email, phone, name, username, avatar_url, bio, gender, date_of_birth, visibility, country_code, google_id, email_verified, phone_verified, is_active, verified_at, created_at, updated_at
 	`
 
 	row := r.db.QueryRow(ctx, query,
@@ -37,26 +40,26 @@ func (r *PostgresRepository) CreateUser(ctx context.Context, params domain.Creat
 		params.PasswordHash,
 		params.Name,
 		params.GoogleID,
+		params.AppleID,
+		params.AvatarURL,
 		params.EmailVerified,
+		params.PhoneVerified,
 	)
 
-	return scanUser(row)
+	user, err := scanUser(row)
+	return user, mapUserError(err)
 }
 
 // GetUserByID retrieves a user by ID
 func (r *PostgresRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
-	query := `
-		SELECT id, email, phone, name, avatar_url, bio, gender, date_of_birth, visibility, google_id, email_verified, phone_verified, is_active, created_at, updated_at
-		FROM users WHERE id = $1 AND is_active = TRUE
-	`
-	row := r.db.QueryRow(ctx, query, id)
+	row := r.db.QueryRow(ctx, getUserByIDQuery, id)
 	return scanUser(row)
 }
 
 // GetUserByEmail retrieves a user by email
 func (r *PostgresRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
 	query := `
-		SELECT id, email, phone, name, avatar_url, bio, gender, date_of_birth, visibility, google_id, email_verified, phone_verified, is_active, created_at, updated_at
+		SELECT id, email, phone, name, username, avatar_url, bio, gender, date_of_birth, visibility, country_code, google_id, email_verified, phone_verified, is_active, verified_at, created_at, updated_at
 		FROM users WHERE email = $1 AND is_active = TRUE
 	`
 	row := r.db.QueryRow(ctx, query, email)
@@ -66,7 +69,7 @@ func (r *PostgresRepository) GetUserByEmail(ctx context.Context, email string) (
 // GetUserByPhone retrieves a user by phone
 func (r *PostgresRepository) GetUserByPhone(ctx context.Context, phone string) (*domain.User, error) {
 	query := `
-		SELECT id, email, phone, name, avatar_url, bio, gender, date_of_birth, visibility, google_id, email_verified, phone_verified, is_active, created_at, updated_at
+		SELECT id, email, phone, name, username, avatar_url, bio, gender, date_of_birth, visibility, country_code, google_id, email_verified, phone_verified, is_active, verified_at, created_at, updated_at
 		FROM users WHERE phone = $1 AND is_active = TRUE
 	`
 	row := r.db.QueryRow(ctx, query, phone)
@@ -76,17 +79,60 @@ func (r *PostgresRepository) GetUserByPhone(ctx context.Context, phone string) (
 // GetUserByGoogleID retrieves a user by Google ID
 func (r *PostgresRepository) GetUserByGoogleID(ctx context.Context, googleID string) (*domain.User, error) {
 	query := `
-		SELECT id, email, phone, name, avatar_url, bio, gender, date_of_birth, visibility, google_id, email_verified, phone_verified, is_active, created_at, updated_at
+		SELECT id, email, phone, name, username, avatar_url, bio, gender, date_of_birth, visibility, country_code, google_id, email_verified, phone_verified, is_active, verified_at, created_at, updated_at
 		FROM users WHERE google_id = $1 AND is_active = TRUE
 	`
 	row := r.db.QueryRow(ctx, query, googleID)
 	return scanUser(row)
 }
 
+// GetUserByAppleID retrieves a user by Apple ID
+func (r *PostgresRepository) GetUserByAppleID(ctx context.Context, appleID string) (*domain.User, error) {
+	query := `
+		SELECT id, email, phone, name, username, avatar_url, bio, gender, date_of_birth, visibility, country_code, google_id, email_verified, phone_verified, is_active, verified_at, created_at, updated_at
+		FROM users WHERE apple_id = $1 AND is_active = TRUE
+	`
+	row := r.db.QueryRow(ctx, query, appleID)
+	return scanUser(row)
+}
+
+// GetUserByUsername retrieves a user by their handle
+func (r *PostgresRepository) GetUserByUsername(ctx context.Context, username string) (*domain.User, error) {
+	query := `
+		SELECT id, email, phone, name, username, avatar_url, bio, gender, date_of_birth, visibility, country_code, google_id, email_verified, phone_verified, is_active, verified_at, created_at, updated_at
+		FROM users WHERE username = $1 AND is_active = TRUE
+	`
+	row := r.db.QueryRow(ctx, query, username)
+	return scanUser(row)
+}
+
+// GetUsersByIDs retrieves the active users among ids
+func (r *PostgresRepository) GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]*domain.User, error) {
+	query := `
+		SELECT id, email, phone, name, username, avatar_url, bio, gender, date_of_birth, visibility, country_code, google_id, email_verified, phone_verified, is_active, verified_at, created_at, updated_at
+		FROM users WHERE id = ANY($1) AND is_active = TRUE
+	`
+	rows, err := r.db.Query(ctx, query, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*domain.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
 // GetUserWithPassword retrieves a user with password hash for verification
 func (r *PostgresRepository) GetUserWithPassword(ctx context.Context, email string) (*domain.User, string, error) {
 	query := `
-		SELECT id, email, phone, name, avatar_url, bio, gender, date_of_birth, visibility, google_id, email_verified, phone_verified, is_active, created_at, updated_at, password_hash
+		SELECT id, email, phone, name, username, avatar_url, bio, gender, date_of_birth, visibility, country_code, google_id, email_verified, phone_verified, is_active, verified_at, created_at, updated_at, password_hash
 		FROM users WHERE email = $1 AND is_active = TRUE
 	`
 	row := r.db.QueryRow(ctx, query, email)
@@ -98,15 +144,18 @@ func (r *PostgresRepository) GetUserWithPassword(ctx context.Context, email stri
 		&user.Email,
 		&user.Phone,
 		&user.Name,
+		&user.Username,
 		&user.AvatarURL,
 		&user.Bio,
 		&user.Gender,
 		&user.DateOfBirth,
 		&user.Visibility,
+		&user.CountryCode,
 		&user.GoogleID,
 		&user.EmailVerified,
 		&user.PhoneVerified,
 		&user.IsActive,
+		&user.VerifiedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 		&passwordHash,
@@ -156,10 +205,27 @@ func (r *PostgresRepository) LinkGoogleAccount(ctx context.Context, userID uuid.
 	query := `
 		UPDATE users SET google_id = $2
 		WHERE id = $1
-		RETURNING id, email, phone, name, avatar_url, bio, gender, date_of_birth, visibility, google_id, email_verified, phone_verified, is_active, created_at, updated_at
+		RETURNING id, email, phone, name, username, avatar_url, bio, gender, date_of_birth, visibility, country_code, google_id, email_verified, phone_verified, is_active, verified_at, created_at, updated_at
 	`
 	row := r.db.QueryRow(ctx, query, userID, googleID)
-	return scanUser(row)
+	user, err := scanUser(row)
+	return user, mapUserError(err)
+}
+
+// LinkAppleAccount links an Apple account to an existing user. A user already
+// linked to a different Apple ID is left alone.
+func (r *PostgresRepository) LinkAppleAccount(ctx context.Context, userID uuid.UUID, appleID string) (*domain.User, error) {
+	query := `
+		UPDATE users SET apple_id = $2
+		WHERE id = $1 AND (apple_id IS NULL OR apple_id = $2)
+		RETURNING id, email, phone, name, username, avatar_url, bio, gender, date_of_birth, visibility, country_code, google_id, email_verified, phone_verified, is_active, verified_at, created_at, updated_at
+	`
+	row := r.db.QueryRow(ctx, query, userID, appleID)
+	user, err := scanUser(row)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil, domain.ErrAppleAccountAlreadyLinked
+	}
+	return user, mapUserError(err)
 }
 
 // UserExistsByEmail checks if a user exists by email
@@ -170,6 +236,14 @@ func (r *PostgresRepository) UserExistsByEmail(ctx context.Context, email string
 	return exists, err
 }
 
+// UsernameExists checks if any account, active or not, holds a username
+func (r *PostgresRepository) UsernameExists(ctx context.Context, username string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM users WHERE username = $1)`
+	var exists bool
+	err := r.db.QueryRow(ctx, query, username).Scan(&exists)
+	return exists, err
+}
+
 // UserExistsByPhone checks if a user exists by phone
 func (r *PostgresRepository) UserExistsByPhone(ctx context.Context, phone string) (bool, error) {
 	query := `SELECT EXISTS(SELECT 1 FROM users WHERE phone = $1)`
@@ -181,15 +255,18 @@ func (r *PostgresRepository) UserExistsByPhone(ctx context.Context, phone string
 // CreateSession creates a new session
 func (r *PostgresRepository) CreateSession(ctx context.Context, params domain.CreateSessionParams) (*domain.Session, error) {
 	query := `
-		INSERT INTO sessions (user_id, device_info, ip_address, user_agent, expires_at)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id, user_id, device_info, ip_address, user_agent, is_active, created_at, expires_at, last_activity_at
+		INSERT INTO sessions (user_id, device_info, ip_address, user_agent, region, platform, app_version, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, user_id, device_info, ip_address, user_agent, region, platform, app_version, is_active, created_at, expires_at, last_activity_at
 	`
 	row := r.db.QueryRow(ctx, query,
 		params.UserID,
 		params.DeviceInfo,
 		params.IPAddress,
 		params.UserAgent,
+		params.Region,
+		params.Platform,
+		params.AppVersion,
 		params.ExpiresAt,
 	)
 	return scanSession(row)
@@ -197,19 +274,135 @@ func (r *PostgresRepository) CreateSession(ctx context.Context, params domain.Cr
 
 // GetSessionByID retrieves a session by ID
 func (r *PostgresRepository) GetSessionByID(ctx context.Context, id uuid.UUID) (*domain.Session, error) {
+	row := r.db.QueryRow(ctx, getSessionByIDQuery, id)
+	return scanSession(row)
+}
+
+// TouchSession records activity on a session
+func (r *PostgresRepository) TouchSession(ctx context.Context, sessionID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `UPDATE sessions SET last_activity_at = NOW() WHERE id = $1`, sessionID)
+	return err
+}
+
+// GetUserSessions returns a user's active, unexpired sessions, most recently used first
+func (r *PostgresRepository) GetUserSessions(ctx context.Context, userID uuid.UUID) ([]*domain.Session, error) {
 	query := `
-		SELECT id, user_id, device_info, ip_address, user_agent, is_active, created_at, expires_at, last_activity_at
-		FROM sessions WHERE id = $1 AND is_active = TRUE
+		SELECT id, user_id, device_info, ip_address, user_agent, region, platform, app_version, is_active, created_at, expires_at, last_activity_at
+		FROM sessions
+		WHERE user_id = $1 AND is_active = TRUE AND expires_at > NOW()
+		ORDER BY last_activity_at DESC
 	`
-	row := r.db.QueryRow(ctx, query, id)
-	return scanSession(row)
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*domain.Session
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
 }
 
-// DeactivateSession deactivates a session
+// DeactivateSession deactivates a session and revokes its refresh tokens
 func (r *PostgresRepository) DeactivateSession(ctx context.Context, id uuid.UUID) error {
-	query := `UPDATE sessions SET is_active = FALSE WHERE id = $1`
-	_, err := r.db.Exec(ctx, query, id)
-	return err
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `UPDATE sessions SET is_active = FALSE WHERE id = $1`, id); err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE refresh_tokens SET revoked = TRUE, revoked_at = NOW()
+		WHERE session_id = $1 AND revoked = FALSE
+	`, id)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// EvictSessions deactivates a user's least recently used active sessions so
+// at most keep remain, counting keepID, and revokes their refresh tokens. The
+// evicted sessions' push tokens are cleared and returned.
+func (r *PostgresRepository) EvictSessions(ctx context.Context, userID, keepID uuid.UUID, keep int) ([]*domain.Session, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		WITH evicted AS (
+			SELECT id, fcm_token FROM sessions
+			WHERE user_id = $1 AND id <> $2 AND is_active = TRUE
+			ORDER BY last_activity_at DESC, created_at DESC
+			OFFSET $3
+			FOR UPDATE
+		)
+		UPDATE sessions s SET is_active = FALSE, fcm_token = NULL
+		FROM evicted e
+		WHERE s.id = e.id
+		RETURNING s.id, s.user_id, s.device_info, e.fcm_token
+	`, userID, keepID, max(keep-1, 0))
+	if err != nil {
+		return nil, err
+	}
+	var sessions []*domain.Session
+	var ids []uuid.UUID
+	for rows.Next() {
+		var session domain.Session
+		if err := rows.Scan(&session.ID, &session.UserID, &session.DeviceInfo, &session.FCMToken); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		sessions = append(sessions, &session)
+		ids = append(ids, session.ID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE refresh_tokens SET revoked = TRUE, revoked_at = NOW()
+		WHERE session_id = ANY($1) AND revoked = FALSE
+	`, ids)
+	if err != nil {
+		return nil, err
+	}
+	return sessions, tx.Commit(ctx)
+}
+
+// GetActiveSessionIDs returns the IDs of a user's active sessions
+func (r *PostgresRepository) GetActiveSessionIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	query := `SELECT id FROM sessions WHERE user_id = $1 AND is_active = TRUE`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
 }
 
 // DeactivateUserSessions deactivates all sessions for a user
@@ -222,25 +415,27 @@ func (r *PostgresRepository) DeactivateUserSessions(ctx context.Context, userID
 // CreateRefreshToken creates a new refresh token
 func (r *PostgresRepository) CreateRefreshToken(ctx context.Context, params domain.CreateRefreshTokenParams) (*domain.RefreshToken, error) {
 	query := `
-		INSERT INTO refresh_tokens (user_id, session_id, token_hash, expires_at)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, user_id, session_id, token_hash, expires_at, revoked, revoked_at, created_at
+		INSERT INTO refresh_tokens (user_id, session_id, family_id, token_hash, expires_at)
+		VALUES ($1, $2, COALESCE($3, uuid_generate_v4()), $4, $5)
+		RETURNING id, user_id, session_id, family_id, token_hash, expires_at, revoked, revoked_at, created_at
 	`
 	row := r.db.QueryRow(ctx, query,
 		params.UserID,
 		params.SessionID,
+		params.FamilyID,
 		params.TokenHash,
 		params.ExpiresAt,
 	)
 	return scanRefreshToken(row)
 }
 
-// GetRefreshTokenByHash retrieves a refresh token by hash
+// GetRefreshTokenByHash retrieves an unexpired refresh token by hash, including
+// revoked ones so that reuse of a rotated token can be detected
 func (r *PostgresRepository) GetRefreshTokenByHash(ctx context.Context, hash string) (*domain.RefreshToken, error) {
 	query := `
-		SELECT id, user_id, session_id, token_hash, expires_at, revoked, revoked_at, created_at
+		SELECT id, user_id, session_id, family_id, token_hash, expires_at, revoked, revoked_at, created_at
 		FROM refresh_tokens
-		WHERE token_hash = $1 AND revoked = FALSE AND expires_at > NOW()
+		WHERE token_hash = $1 AND expires_at > NOW()
 	`
 	row := r.db.QueryRow(ctx, query, hash)
 	return scanRefreshToken(row)
@@ -260,6 +455,13 @@ func (r *PostgresRepository) RevokeRefreshTokenByHash(ctx context.Context, hash
 	return err
 }
 
+// RevokeRefreshTokenFamily revokes every token descended from the same login
+func (r *PostgresRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) error {
+	query := `UPDATE refresh_tokens SET revoked = TRUE, revoked_at = NOW() WHERE family_id = $1 AND revoked = FALSE`
+	_, err := r.db.Exec(ctx, query, familyID)
+	return err
+}
+
 // RevokeUserRefreshTokens revokes all refresh tokens for a user
 func (r *PostgresRepository) RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) error {
 	query := `UPDATE refresh_tokens SET revoked = TRUE, revoked_at = NOW() WHERE user_id = $1`
@@ -276,9 +478,11 @@ func (r *PostgresRepository) UpdateUser(ctx context.Context, userID uuid.UUID, p
 			gender = COALESCE($4, gender),
 			date_of_birth = COALESCE($5, date_of_birth),
 			visibility = COALESCE($6, visibility),
-			avatar_url = COALESCE($7, avatar_url)
+			avatar_url = COALESCE($7, avatar_url),
+			country_code = COALESCE($8, country_code),
+			username = COALESCE($9, username)
 		WHERE id = $1
-		RETURNING id, email, phone, name, avatar_url, bio, gender, date_of_birth, visibility, google_id, email_verified, phone_verified, is_active, created_at, updated_at
+		RETURNING id, email, phone, name, username, avatar_url, bio, gender, date_of_birth, visibility, country_code, google_id, email_verified, phone_verified, is_active, verified_at, created_at, updated_at
 	`
 	row := r.db.QueryRow(ctx, query,
 		userID,
@@ -288,37 +492,120 @@ func (r *PostgresRepository) UpdateUser(ctx context.Context, userID uuid.UUID, p
 		params.DateOfBirth,
 		params.Visibility,
 		params.AvatarURL,
+		params.CountryCode,
+		params.Username,
 	)
-	return scanUser(row)
+	user, err := scanUser(row)
+	return user, mapUserError(err)
+}
+
+// GetUserCountry returns the country code on a user's profile, or "" if unset
+func (r *PostgresRepository) GetUserCountry(ctx context.Context, userID uuid.UUID) (string, error) {
+	var code *string
+	err := r.db.QueryRow(ctx, `SELECT country_code FROM users WHERE id = $1`, userID).Scan(&code)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil
+		}
+		return "", err
+	}
+	if code == nil {
+		return "", nil
+	}
+	return *code, nil
 }
 
-// DeleteUser performs a soft delete on a user
-func (r *PostgresRepository) DeleteUser(ctx context.Context, userID uuid.UUID) error {
+// DeleteUser deactivates a user's account, revokes their sessions and tokens,
+// replaces their profile with a "Deleted user" placeholder in other users'
+// chats and deletes their stories and data exports. It returns the media URLs
+// the caller should remove from storage. A user on legal hold is only
+// deactivated; their data is kept until the hold is released and the purge job
+// removes the account.
+func (r *PostgresRepository) DeleteUser(ctx context.Context, userID uuid.UUID) ([]string, error) {
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer tx.Rollback(ctx)
 
-	// 1. Set is_active = FALSE
-	_, err = tx.Exec(ctx, "UPDATE users SET is_active = FALSE WHERE id = $1", userID)
+	var avatarURL *string
+	var held bool
+	err = tx.QueryRow(ctx, `
+		SELECT avatar_url, EXISTS (
+			SELECT 1 FROM legal_holds WHERE user_id = $1 AND released_at IS NULL
+		)
+		FROM users WHERE id = $1
+		FOR UPDATE
+	`, userID).Scan(&avatarURL, &held)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrUserNotFound
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// 2. Revoke all sessions
-	_, err = tx.Exec(ctx, "UPDATE sessions SET is_active = FALSE WHERE user_id = $1", userID)
+	// 1. Deactivate, starting the grace period before the purge
+	_, err = tx.Exec(ctx, "UPDATE users SET is_active = FALSE, deleted_at = COALESCE(deleted_at, NOW()) WHERE id = $1", userID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	// 2. Revoke all sessions, dropping push tokens
+	_, err = tx.Exec(ctx, "UPDATE sessions SET is_active = FALSE, fcm_token = NULL WHERE user_id = $1", userID)
+	if err != nil {
+		return nil, err
 	}
 
 	// 3. Revoke all refresh tokens
-	_, err = tx.Exec(ctx, "UPDATE refresh_tokens SET revoked = TRUE, revoked_at = NOW() WHERE user_id = $1", userID)
+	_, err = tx.Exec(ctx, "UPDATE refresh_tokens SET revoked = TRUE, revoked_at = NOW() WHERE user_id = $1 AND revoked = FALSE", userID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return tx.Commit(ctx)
+	if held {
+		return nil, tx.Commit(ctx)
+	}
+
+	// 4. Anonymize the profile other chat participants see, freeing the
+	// email, phone and social logins for a new account
+	_, err = tx.Exec(ctx, `UPDATE users SET `+anonymizedProfile+` WHERE id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 5. Delete stories, live and archived, and data export archives
+	var mediaURLs []string
+	if avatarURL != nil && *avatarURL != "" {
+		mediaURLs = append(mediaURLs, *avatarURL)
+	}
+	rows, err := tx.Query(ctx, `
+		WITH live AS (
+			DELETE FROM stories WHERE user_id = $1 RETURNING media_url
+		), archived AS (
+			DELETE FROM story_archive WHERE user_id = $1 RETURNING media_url
+		), exports AS (
+			DELETE FROM data_exports WHERE user_id = $1 RETURNING file_url
+		)
+		SELECT media_url FROM live UNION SELECT media_url FROM archived
+		UNION SELECT file_url FROM exports WHERE file_url IS NOT NULL
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		mediaURLs = append(mediaURLs, url)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return mediaURLs, tx.Commit(ctx)
 }
 
 // Helper functions for scanning rows
@@ -330,15 +617,18 @@ func scanUser(row pgx.Row) (*domain.User, error) {
 		&user.Email,
 		&user.Phone,
 		&user.Name,
+		&user.Username,
 		&user.AvatarURL,
 		&user.Bio,
 		&user.Gender,
 		&user.DateOfBirth,
 		&user.Visibility,
+		&user.CountryCode,
 		&user.GoogleID,
 		&user.EmailVerified,
 		&user.PhoneVerified,
 		&user.IsActive,
+		&user.VerifiedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -359,6 +649,9 @@ func scanSession(row pgx.Row) (*domain.Session, error) {
 		&session.DeviceInfo,
 		&session.IPAddress,
 		&session.UserAgent,
+		&session.Region,
+		&session.Platform,
+		&session.AppVersion,
 		&session.IsActive,
 		&session.CreatedAt,
 		&session.ExpiresAt,
@@ -366,7 +659,7 @@ func scanSession(row pgx.Row) (*domain.Session, error) {
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, errors.New("session not found")
+			return nil, domain.ErrSessionNotFound
 		}
 		return nil, err
 	}
@@ -379,6 +672,7 @@ func scanRefreshToken(row pgx.Row) (*domain.RefreshToken, error) {
 		&token.ID,
 		&token.UserID,
 		&token.SessionID,
+		&token.FamilyID,
 		&token.TokenHash,
 		&token.ExpiresAt,
 		&token.Revoked,
@@ -394,24 +688,38 @@ func scanRefreshToken(row pgx.Row) (*domain.RefreshToken, error) {
 	return &token, nil
 }
 
-// CleanupExpiredTokens removes expired and revoked tokens
-func (r *PostgresRepository) CleanupExpiredTokens(ctx context.Context) error {
+// CleanupExpiredTokens removes expired and revoked tokens, except those of
+// users under legal hold, and old job runs. It returns the rows changed.
+func (r *PostgresRepository) CleanupExpiredTokens(ctx context.Context) (int64, error) {
 	queries := []string{
-		`DELETE FROM refresh_tokens WHERE expires_at < NOW() OR revoked = TRUE AND revoked_at < NOW() - INTERVAL '7 days'`,
+		`DELETE FROM refresh_tokens WHERE (expires_at < NOW() OR revoked = TRUE AND revoked_at < NOW() - INTERVAL '7 days') AND ` + notOnLegalHold("refresh_tokens.user_id"),
 		`UPDATE sessions SET is_active = FALSE WHERE expires_at < NOW()`,
-		`DELETE FROM password_reset_tokens WHERE expires_at < NOW() OR used = TRUE`,
+		`DELETE FROM password_reset_tokens WHERE (expires_at < NOW() OR used = TRUE) AND ` + notOnLegalHold("password_reset_tokens.user_id"),
+		`DELETE FROM phone_otps WHERE created_at < NOW() - INTERVAL '1 day'`,
+		`DELETE FROM login_attempts WHERE created_at < NOW() - INTERVAL '30 days' AND (user_id IS NULL OR ` + notOnLegalHold("login_attempts.user_id") + `)`,
+		`DELETE FROM email_verification_tokens WHERE (expires_at < NOW() OR used_at IS NOT NULL) AND ` + notOnLegalHold("email_verification_tokens.user_id"),
+		`DELETE FROM security_events WHERE created_at < NOW() - INTERVAL '1 year' AND ` + notOnLegalHold("security_events.user_id"),
+		`DELETE FROM activities WHERE created_at < NOW() - INTERVAL '90 days'`,
 	}
 
+	var affected int64
 	for _, query := range queries {
-		if _, err := r.db.Exec(ctx, query); err != nil {
-			return err
+		tag, err := r.db.Exec(ctx, query)
+		if err != nil {
+			return affected, err
 		}
+		affected += tag.RowsAffected()
+	}
+
+	tag, err := r.db.Exec(ctx, `DELETE FROM job_runs WHERE started_at < $1`, time.Now().Add(-domain.JobRunRetention))
+	if err != nil {
+		return affected, err
 	}
-	return nil
+	return affected + tag.RowsAffected(), nil
 }
 
 // StartCleanupWorker starts a background worker to clean up expired tokens
-func (r *PostgresRepository) StartCleanupWorker(ctx context.Context, interval time.Duration) {
+func (r *PostgresRepository) StartCleanupWorker(ctx context.Context, interval time.Duration, jobs *domain.JobTracker) {
 	go func() {
 		ticker := time.NewTicker(interval)
 		defer ticker.Stop()
@@ -421,7 +729,7 @@ func (r *PostgresRepository) StartCleanupWorker(ctx context.Context, interval ti
 			case <-ctx.Done():
 				return
 			case <-ticker.C:
-				_ = r.CleanupExpiredTokens(ctx)
+				jobs.Track(ctx, domain.JobTokenCleanup, r.CleanupExpiredTokens)
 			}
 		}
 	}()
@@ -475,7 +783,7 @@ func (r *PostgresRepository) MarkPasswordResetTokenUsed(ctx context.Context, id
 func (r *PostgresRepository) UpdateUserEmail(ctx context.Context, userID uuid.UUID, email string) error {
 	query := `UPDATE users SET email = $2, email_verified = FALSE WHERE id = $1`
 	_, err := r.db.Exec(ctx, query, userID, email)
-	return err
+	return mapUserError(err)
 }
 
 // UpdateSessionFCMToken updates a session's FCM token
@@ -490,8 +798,8 @@ func scanStoryWithUser(row pgx.Row) (*domain.Story, error) {
 	var s domain.Story
 	var u domain.User
 	err := row.Scan(
-		&s.ID, &s.UserID, &s.MediaURL, &s.MediaType, &s.Caption, &s.LocationLat, &s.LocationLng, &s.ExpiresAt, &s.CreatedAt,
-		&u.ID, &u.Email, &u.Phone, &u.Name, &u.AvatarURL, &u.Bio, &u.Gender, &u.DateOfBirth, &u.Visibility, &u.GoogleID, &u.EmailVerified, &u.PhoneVerified, &u.IsActive, &u.CreatedAt, &u.UpdatedAt,
+		&s.ID, &s.UserID, &s.MediaURL, &s.MediaType, &s.Caption, &s.LocationLat, &s.LocationLng, &s.LocationFuzzed, &s.ExpiresAt, &s.CreatedAt,
+		&u.ID, &u.Email, &u.Phone, &u.Name, &u.Username, &u.AvatarURL, &u.Bio, &u.Gender, &u.DateOfBirth, &u.Visibility, &u.CountryCode, &u.GoogleID, &u.EmailVerified, &u.PhoneVerified, &u.IsActive, &u.VerifiedAt, &u.CreatedAt, &u.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -501,18 +809,39 @@ func scanStoryWithUser(row pgx.Row) (*domain.Story, error) {
 }
 
 func (r *PostgresRepository) CreateStory(ctx context.Context, params domain.CreateStoryParams) (*domain.Story, error) {
+	// The column is NOT NULL, so never send a nil slice
+	locationFlags := params.LocationFlags
+	if locationFlags == nil {
+		locationFlags = []string{}
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var liveSessionID *uuid.UUID
+	if params.Live {
+		id, err := touchLiveSession(ctx, tx, params.UserID, *params.LocationLat, *params.LocationLng)
+		if err != nil {
+			return nil, err
+		}
+		liveSessionID = &id
+	}
+
 	query := `
 		WITH inserted_story AS (
-			INSERT INTO stories (user_id, media_url, media_type, caption, location_lat, location_lng, expires_at)
-			VALUES ($1, $2, $3, $4, $5, $6, $7)
-			RETURNING id, user_id, media_url, media_type, caption, location_lat, location_lng, expires_at, created_at
+			INSERT INTO stories (user_id, media_url, media_type, caption, location_lat, location_lng, expires_at, location_flags, location_fuzzed, live_session_id, media_hash, duplicate_of)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			RETURNING id, user_id, media_url, media_type, caption, location_lat, location_lng, location_fuzzed, expires_at, created_at
 		)
-		SELECT s.id, s.user_id, s.media_url, s.media_type, s.caption, s.location_lat, s.location_lng, s.expires_at, s.created_at,
-		       u.id, u.email, u.phone, u.name, u.avatar_url, u.bio, u.gender, u.date_of_birth, u.visibility, u.google_id, u.email_verified, u.phone_verified, u.is_active, u.created_at, u.updated_at
+		SELECT s.id, s.user_id, s.media_url, s.media_type, s.caption, s.location_lat, s.location_lng, s.location_fuzzed, s.expires_at, s.created_at,
+		       u.id, u.email, u.phone, u.name, u.username, u.avatar_url, u.bio, u.gender, u.date_of_birth, u.visibility, u.country_code, u.google_id, u.email_verified, u.phone_verified, u.is_active, u.verified_at, u.created_at, u.updated_at
 		FROM inserted_story s
 		JOIN users u ON s.user_id = u.id
 	`
-	row := r.db.QueryRow(ctx, query,
+	row := tx.QueryRow(ctx, query,
 		params.UserID,
 		params.MediaURL,
 		params.MediaType,
@@ -520,17 +849,88 @@ func (r *PostgresRepository) CreateStory(ctx context.Context, params domain.Crea
 		params.LocationLat,
 		params.LocationLng,
 		params.ExpiresAt,
+		locationFlags,
+		params.FuzzLocation != nil && *params.FuzzLocation,
+		liveSessionID,
+		params.MediaHash,
+		params.DuplicateOf,
 	)
-	return scanStoryWithUser(row)
+	story, err := scanStoryWithUser(row)
+	if err != nil {
+		return nil, err
+	}
+	story.Live = params.Live
+	return story, tx.Commit(ctx)
+}
+
+// FindDuplicateMedia returns the earliest story since the given time whose
+// media hash differs from hash in at most maxDistance bits
+func (r *PostgresRepository) FindDuplicateMedia(ctx context.Context, hash int64, maxDistance int, since time.Time) (*domain.MediaMatch, error) {
+	query := `
+		SELECT id, user_id, distance
+		FROM (
+			SELECT id, user_id, created_at,
+				length(replace(((media_hash # $1)::bit(64))::text, '0', '')) AS distance
+			FROM stories
+			WHERE media_hash IS NOT NULL AND created_at > $2
+		) candidates
+		WHERE distance <= $3
+		ORDER BY created_at
+		LIMIT 1
+	`
+	var match domain.MediaMatch
+	err := r.db.QueryRow(ctx, query, hash, since, maxDistance).Scan(&match.StoryID, &match.UserID, &match.Distance)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &match, nil
+}
+
+// CountStoriesNear counts the user's stories posted within radius meters of a point since the given time
+func (r *PostgresRepository) CountStoriesNear(ctx context.Context, userID uuid.UUID, lat, lng, radius float64, since time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM stories
+		WHERE user_id = $1 AND created_at >= $5
+		AND location_lat IS NOT NULL AND location_lng IS NOT NULL
+		AND earth_box(ll_to_earth($2, $3), $4) @> ll_to_earth(location_lat, location_lng)
+		AND earth_distance(ll_to_earth($2, $3), ll_to_earth(location_lat, location_lng)) < $4
+	`
+	var count int
+	err := r.db.QueryRow(ctx, query, userID, lat, lng, radius, since).Scan(&count)
+	return count, err
+}
+
+// GetLatestStoryLocation returns the position of the user's most recent located story
+func (r *PostgresRepository) GetLatestStoryLocation(ctx context.Context, userID uuid.UUID) (*domain.LocationPoint, error) {
+	query := `
+		SELECT location_lat, location_lng, created_at
+		FROM stories
+		WHERE user_id = $1 AND location_lat IS NOT NULL AND location_lng IS NOT NULL
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+	var p domain.LocationPoint
+	err := r.db.QueryRow(ctx, query, userID).Scan(&p.Lat, &p.Lng, &p.At)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
 }
 
 func (r *PostgresRepository) GetActiveStories(ctx context.Context, limit, offset int) ([]*domain.Story, error) {
 	query := `
-		SELECT s.id, s.user_id, s.media_url, s.media_type, s.caption, s.location_lat, s.location_lng, s.expires_at, s.created_at,
-		       u.id, u.email, u.phone, u.name, u.avatar_url, u.bio, u.gender, u.date_of_birth, u.visibility, u.google_id, u.email_verified, u.phone_verified, u.is_active, u.created_at, u.updated_at
+		SELECT s.id, s.user_id, s.media_url, s.media_type, s.caption, s.location_lat, s.location_lng, s.location_fuzzed, s.expires_at, s.created_at,
+		       u.id, u.email, u.phone, u.name, u.username, u.avatar_url, u.bio, u.gender, u.date_of_birth, u.visibility, u.country_code, u.google_id, u.email_verified, u.phone_verified, u.is_active, u.verified_at, u.created_at, u.updated_at
 		FROM stories s
 		JOIN users u ON s.user_id = u.id
-		WHERE s.expires_at > NOW()
+		WHERE s.expires_at > NOW() AND s.hidden_at IS NULL
 		ORDER BY s.created_at DESC
 		LIMIT $1 OFFSET $2
 	`
@@ -551,68 +951,120 @@ func (r *PostgresRepository) GetActiveStories(ctx context.Context, limit, offset
 	return stories, nil
 }
 
+// nearbyStoriesQuery selects the active stories within $3 meters of ($1, $2),
+// live ones first, for the nearby feed. It uses the earthdistance extension
+// (migration 004): earth_box narrows the search to a bounding box before the
+// exact distance is checked. $4 and $5 are the live session cutoffs.
+var nearbyStoriesQuery = `
+	FROM stories s
+	JOIN users u ON s.user_id = u.id
+	WHERE s.expires_at > NOW() AND s.hidden_at IS NULL
+	AND s.location_lat IS NOT NULL AND s.location_lng IS NOT NULL
+	AND earth_box(ll_to_earth($1, $2), $3) @> ll_to_earth(s.location_lat, s.location_lng)
+	AND earth_distance(ll_to_earth($1, $2), ll_to_earth(s.location_lat, s.location_lng)) < $3
+	ORDER BY ` + storyDownranked + `,
+		EXISTS (SELECT 1 FROM live_sessions ls WHERE ls.id = s.live_session_id AND ` + liveSessionActive("ls", "$4", "$5") + `) DESC,
+		s.created_at DESC
+`
+
 func (r *PostgresRepository) GetStoriesByLocation(ctx context.Context, lat, lng, radius float64, limit, offset int) ([]*domain.Story, error) {
-	// Radius logic: we use earth_distance extension if available.
-	// Since migration 004 adds it, we use it.
-	// earth_box(ll_to_earth(lat, lng), radius) creates a bounding box.
-	// radius is in meters.
+	query := `SELECT ` + storyWithUserColumns + nearbyStoriesQuery + ` LIMIT $6 OFFSET $7`
+	idleSince, startedSince := domain.LiveCutoffs(time.Now())
+	return r.queryStories(ctx, query, lat, lng, radius, idleSince, startedSince, limit, offset)
+}
+
+func (r *PostgresRepository) DeleteExpiredStories(ctx context.Context) (int64, error) {
+	query := `DELETE FROM stories WHERE expires_at < NOW() AND ` + notOnLegalHold("stories.user_id")
+	tag, err := r.db.Exec(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ArchiveExpiredStories deletes expired stories and copies them into story_archive
+// in a single statement, so a story is never lost between the two steps. Stories
+// of users under legal hold are left in place.
+func (r *PostgresRepository) ArchiveExpiredStories(ctx context.Context) (int64, error) {
 	query := `
-		SELECT s.id, s.user_id, s.media_url, s.media_type, s.caption, s.location_lat, s.location_lng, s.expires_at, s.created_at,
-		       u.id, u.email, u.phone, u.name, u.avatar_url, u.bio, u.gender, u.date_of_birth, u.visibility, u.google_id, u.email_verified, u.phone_verified, u.is_active, u.created_at, u.updated_at
-		FROM stories s
-		JOIN users u ON s.user_id = u.id
-		WHERE s.expires_at > NOW()
-		AND s.location_lat IS NOT NULL AND s.location_lng IS NOT NULL
-		AND earth_box(ll_to_earth($1, $2), $3) @> ll_to_earth(s.location_lat, s.location_lng)
-		AND earth_distance(ll_to_earth($1, $2), ll_to_earth(s.location_lat, s.location_lng)) < $3
-		ORDER BY s.created_at DESC
-		LIMIT $4 OFFSET $5
+		WITH expired AS (
+			DELETE FROM stories WHERE expires_at < NOW() AND ` + notOnLegalHold("stories.user_id") + `
+			RETURNING id, user_id, media_url, media_type, caption, location_lat, location_lng, location_fuzzed, expires_at, created_at
+		)
+		INSERT INTO story_archive (id, user_id, media_url, media_type, caption, location_lat, location_lng, location_fuzzed, expires_at, created_at)
+		SELECT id, user_id, media_url, media_type, caption, location_lat, location_lng, location_fuzzed, expires_at, created_at FROM expired
+		ON CONFLICT (id) DO NOTHING
+	`
+	tag, err := r.db.Exec(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// PurgeStoryArchive deletes archived stories older than the retention cutoff,
+// except those of users under legal hold
+func (r *PostgresRepository) PurgeStoryArchive(ctx context.Context, archivedBefore time.Time) (int64, error) {
+	query := `DELETE FROM story_archive WHERE archived_at < $1 AND ` + notOnLegalHold("story_archive.user_id")
+	tag, err := r.db.Exec(ctx, query, archivedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+const archivedStoryColumns = `id, user_id, media_url, media_type, caption, location_lat, location_lng, location_fuzzed, expires_at, created_at, archived_at`
+
+// GetArchivedStory retrieves an archived story by its original ID
+func (r *PostgresRepository) GetArchivedStory(ctx context.Context, storyID uuid.UUID) (*domain.ArchivedStory, error) {
+	query := `SELECT ` + archivedStoryColumns + ` FROM story_archive WHERE id = $1`
+	return scanArchivedStory(r.db.QueryRow(ctx, query, storyID))
+}
+
+// GetArchivedStoriesByUser lists a user's archived stories, newest first
+func (r *PostgresRepository) GetArchivedStoriesByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*domain.ArchivedStory, error) {
+	query := `
+		SELECT ` + archivedStoryColumns + `
+		FROM story_archive
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
 	`
-	rows, err := r.db.Query(ctx, query, lat, lng, radius, limit, offset)
+	rows, err := r.db.Query(ctx, query, userID, limit, offset)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var stories []*domain.Story
+	var stories []*domain.ArchivedStory
 	for rows.Next() {
-		story, err := scanStoryWithUser(rows)
+		story, err := scanArchivedStory(rows)
 		if err != nil {
 			return nil, err
 		}
 		stories = append(stories, story)
 	}
-	return stories, nil
+	return stories, rows.Err()
 }
 
-func (r *PostgresRepository) DeleteExpiredStories(ctx context.Context) (int64, error) {
-	query := `DELETE FROM stories WHERE expires_at < NOW()`
-	tag, err := r.db.Exec(ctx, query)
+func scanArchivedStory(row pgx.Row) (*domain.ArchivedStory, error) {
+	var s domain.ArchivedStory
+	err := row.Scan(&s.ID, &s.UserID, &s.MediaURL, &s.MediaType, &s.Caption, &s.LocationLat, &s.LocationLng, &s.LocationFuzzed, &s.ExpiresAt, &s.CreatedAt, &s.ArchivedAt)
 	if err != nil {
-		return 0, err
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrStoryNotFound
+		}
+		return nil, err
 	}
-	return tag.RowsAffected(), nil
+	return &s, nil
 }
 
 // Chat methods
 
+// CreateChat returns the 1:1 chat between two users, creating it if needed.
+// The unique (direct_user_low, direct_user_high) index makes this safe under
+// concurrency: a losing insert falls through to reading the winner's chat.
 func (r *PostgresRepository) CreateChat(ctx context.Context, user1ID, user2ID uuid.UUID) (*domain.Chat, error) {
-	// Check if chat exists
-	// This query finds a chat where both users are participants and there are exactly 2 participants
-	queryCheck := `
-		SELECT cp1.chat_id
-		FROM chat_participants cp1
-		JOIN chat_participants cp2 ON cp1.chat_id = cp2.chat_id
-		WHERE cp1.user_id = $1 AND cp2.user_id = $2
-		GROUP BY cp1.chat_id
-	`
-	var existingChatID uuid.UUID
-	err := r.db.QueryRow(ctx, queryCheck, user1ID, user2ID).Scan(&existingChatID)
-	if err == nil {
-		return r.GetChatByID(ctx, existingChatID)
-	}
-
-	// Create new chat
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return nil, err
@@ -620,64 +1072,155 @@ func (r *PostgresRepository) CreateChat(ctx context.Context, user1ID, user2ID uu
 	defer tx.Rollback(ctx)
 
 	var chatID uuid.UUID
-	var createdAt, updatedAt time.Time
-	err = tx.QueryRow(ctx, "INSERT INTO chats DEFAULT VALUES RETURNING id, created_at, updated_at").Scan(&chatID, &createdAt, &updatedAt)
-	if err != nil {
+	err = tx.QueryRow(ctx, `
+		INSERT INTO chats (direct_user_low, direct_user_high)
+		VALUES (LEAST($1::uuid, $2::uuid), GREATEST($1::uuid, $2::uuid))
+		ON CONFLICT (direct_user_low, direct_user_high) WHERE direct_user_low IS NOT NULL DO NOTHING
+		RETURNING id
+	`, user1ID, user2ID).Scan(&chatID)
+
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		// Chat already exists for this pair
+		err = tx.QueryRow(ctx, `
+			SELECT id FROM chats
+			WHERE direct_user_low = LEAST($1::uuid, $2::uuid) AND direct_user_high = GREATEST($1::uuid, $2::uuid)
+		`, user1ID, user2ID).Scan(&chatID)
+		if err != nil {
+			return nil, err
+		}
+	case err != nil:
 		return nil, err
+	default:
+		// Add participants
+		_, err = tx.Exec(ctx, "INSERT INTO chat_participants (chat_id, user_id) VALUES ($1, $2), ($1, $3)", chatID, user1ID, user2ID)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	// Add participants
-	_, err = tx.Exec(ctx, "INSERT INTO chat_participants (chat_id, user_id) VALUES ($1, $2), ($1, $3)", chatID, user1ID, user2ID)
-	if err != nil {
-		return nil, err
-	}
-
-	if err := tx.Commit(ctx); err != nil {
+	if err := tx.Commit(ctx); err != nil {
 		return nil, err
 	}
 
 	return r.GetChatByID(ctx, chatID)
 }
 
+// IsChatParticipant reports whether the user is in the chat
+func (r *PostgresRepository) IsChatParticipant(ctx context.Context, chatID, userID uuid.UUID) (bool, error) {
+	var exists, participant bool
+	err := r.db.QueryRow(ctx, `
+		SELECT TRUE, EXISTS (SELECT 1 FROM chat_participants WHERE chat_id = c.id AND user_id = $2)
+		FROM chats c WHERE c.id = $1
+	`, chatID, userID).Scan(&exists, &participant)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, domain.ErrChatNotFound
+	}
+	if err != nil {
+		return false, err
+	}
+	return participant, nil
+}
+
 func (r *PostgresRepository) GetChatByID(ctx context.Context, chatID uuid.UUID) (*domain.Chat, error) {
-	queryChat := `SELECT id, created_at, updated_at FROM chats WHERE id = $1`
+	queryChat := `SELECT id, is_group, name, avatar_url, frozen_at, message_retention_seconds, created_at, updated_at FROM chats WHERE id = $1`
 	var chat domain.Chat
-	err := r.db.QueryRow(ctx, queryChat, chatID).Scan(&chat.ID, &chat.CreatedAt, &chat.UpdatedAt)
+	err := r.db.QueryRow(ctx, queryChat, chatID).Scan(&chat.ID, &chat.IsGroup, &chat.Name, &chat.AvatarURL, &chat.FrozenAt, &chat.MessageRetentionSeconds, &chat.CreatedAt, &chat.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrChatNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	// Get participants
+	if err := r.loadChatParticipants(ctx, &chat); err != nil {
+		return nil, err
+	}
+	return &chat, nil
+}
+
+// loadChatParticipants fills in a chat's users and admins, in the order they joined
+func (r *PostgresRepository) loadChatParticipants(ctx context.Context, chat *domain.Chat) error {
 	queryParticipants := `
-		SELECT u.id, u.email, u.phone, u.name, u.avatar_url
+		SELECT u.id, COALESCE(u.email, ''), COALESCE(u.phone, ''), u.name, COALESCE(u.username, ''), COALESCE(u.avatar_url, ''), cp.role
 		FROM chat_participants cp
 		JOIN users u ON cp.user_id = u.id
 		WHERE cp.chat_id = $1
+		ORDER BY cp.joined_at, u.id
 	`
-	rows, err := r.db.Query(ctx, queryParticipants, chatID)
+	rows, err := r.db.Query(ctx, queryParticipants, chat.ID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		var u domain.UserResponse
-		if err := rows.Scan(&u.ID, &u.Email, &u.Phone, &u.Name, &u.AvatarURL); err != nil {
-			return nil, err
+		var role domain.ChatRole
+		if err := rows.Scan(&u.ID, &u.Email, &u.Phone, &u.Name, &u.Username, &u.AvatarURL, &role); err != nil {
+			return err
 		}
 		chat.Users = append(chat.Users, &u)
+		if chat.IsGroup && role == domain.ChatRoleAdmin {
+			chat.AdminIDs = append(chat.AdminIDs, u.ID)
+		}
 	}
+	return rows.Err()
+}
 
-	return &chat, nil
+// chatParticipantsJSON aggregates a chat's users, in the order they joined,
+// for the chat list; loadChatParticipants reads the same for a single chat
+const chatParticipantsJSON = `
+	SELECT COALESCE(jsonb_agg(jsonb_build_object(
+		'id', u.id,
+		'email', COALESCE(u.email, ''),
+		'phone', COALESCE(u.phone, ''),
+		'name', u.name,
+		'username', COALESCE(u.username, ''),
+		'avatar_url', COALESCE(u.avatar_url, ''),
+		'role', p.role
+	) ORDER BY p.joined_at, u.id), '[]')
+	FROM chat_participants p
+	JOIN users u ON u.id = p.user_id
+	WHERE p.chat_id = c.id`
+
+// chatParticipant is an element of chatParticipantsJSON
+type chatParticipant struct {
+	domain.UserResponse
+	Role domain.ChatRole `json:"role"`
 }
 
+// GetChatsByUserID lists the user's chats, pinned ones first and then the
+// most recently active, with the user's preferences and how many messages
+// from others in each they haven't read. Participants and the last message
+// are joined in, so the list is one query however many chats there are.
+// Messages from before the user cleared a chat are neither counted nor shown.
 func (r *PostgresRepository) GetChatsByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.Chat, error) {
 	query := `
-		SELECT c.id, c.created_at, c.updated_at
+		SELECT c.id, c.is_group, c.name, c.avatar_url, c.frozen_at, c.message_retention_seconds, c.created_at, c.updated_at, COALESCE(unread.count, 0),
+			` + chatPreferenceColumns + `,
+			cp.draft, cp.draft_ciphertext, cp.draft_key_version, cp.draft_updated_at,
+			(` + chatParticipantsJSON + `),
+			last.*
 		FROM chats c
 		JOIN chat_participants cp ON c.id = cp.chat_id
+		LEFT JOIN (
+			SELECT m.chat_id, COUNT(*) AS count
+			FROM messages m
+			JOIN chat_participants p ON p.chat_id = m.chat_id AND p.user_id = $1
+			WHERE m.read_at IS NULL AND m.sender_id <> $1 AND m.deleted_at IS NULL
+				AND (p.cleared_at IS NULL OR m.created_at > p.cleared_at)
+			GROUP BY m.chat_id
+		) unread ON unread.chat_id = c.id
+		LEFT JOIN LATERAL (
+			SELECT ` + messageColumns + `
+			FROM messages
+			WHERE chat_id = c.id AND (cp.cleared_at IS NULL OR created_at > cp.cleared_at)
+			ORDER BY created_at DESC, id DESC
+			LIMIT 1
+		) last ON TRUE
 		WHERE cp.user_id = $1
-		ORDER BY c.updated_at DESC
+		ORDER BY cp.pinned DESC, c.updated_at DESC
 	`
 	rows, err := r.db.Query(ctx, query, userID)
 	if err != nil {
@@ -685,40 +1228,66 @@ func (r *PostgresRepository) GetChatsByUserID(ctx context.Context, userID uuid.U
 	}
 	defer rows.Close()
 
+	type storedDraft struct {
+		content    *string
+		ciphertext []byte
+		keyVersion *int
+		updatedAt  *time.Time
+	}
+
 	var chats []*domain.Chat
+	var drafts []storedDraft
+	var lastMessages []*storedMessage
 	for rows.Next() {
 		var chat domain.Chat
-		if err := rows.Scan(&chat.ID, &chat.CreatedAt, &chat.UpdatedAt); err != nil {
+		var prefs domain.ChatPreferences
+		var draft storedDraft
+		var participants []byte
+		var last nullableMessage
+		dest := []interface{}{&chat.ID, &chat.IsGroup, &chat.Name, &chat.AvatarURL, &chat.FrozenAt, &chat.MessageRetentionSeconds, &chat.CreatedAt, &chat.UpdatedAt, &chat.UnreadCount,
+			&prefs.Muted, &prefs.MutedUntil, &prefs.Archived, &prefs.Pinned,
+			&draft.content, &draft.ciphertext, &draft.keyVersion, &draft.updatedAt,
+			&participants}
+		if err := rows.Scan(append(dest, last.dest()...)...); err != nil {
 			return nil, err
 		}
-		chats = append(chats, &chat)
-	}
+		chat.Preferences = &prefs
 
-	// For each chat, get participants (Optimization: could use array_agg but this is simpler for now)
-	for _, chat := range chats {
-		// Re-use logic or fetch query
-		queryParticipants := `
-			SELECT u.id, u.email, u.phone, u.name, u.avatar_url
-			FROM chat_participants cp
-			JOIN users u ON cp.user_id = u.id
-			WHERE cp.chat_id = $1
-		`
-		pRows, err := r.db.Query(ctx, queryParticipants, chat.ID)
-		if err != nil {
-			continue // skip error for fetch list
+		var users []chatParticipant
+		if err := json.Unmarshal(participants, &users); err != nil {
+			return nil, err
 		}
-		for pRows.Next() {
-			var u domain.UserResponse
-			_ = pRows.Scan(&u.ID, &u.Email, &u.Phone, &u.Name, &u.AvatarURL)
-			chat.Users = append(chat.Users, &u)
+		for i := range users {
+			chat.Users = append(chat.Users, &users[i].UserResponse)
+			if chat.IsGroup && users[i].Role == domain.ChatRoleAdmin {
+				chat.AdminIDs = append(chat.AdminIDs, users[i].ID)
+			}
 		}
-		pRows.Close()
 
-		// Get last message
-		queryMsg := `SELECT id, chat_id, sender_id, content, read_at, created_at FROM messages WHERE chat_id = $1 ORDER BY created_at DESC LIMIT 1`
-		var msg domain.Message
-		if err := r.db.QueryRow(ctx, queryMsg, chat.ID).Scan(&msg.ID, &msg.ChatID, &msg.SenderID, &msg.Content, &msg.ReadAt, &msg.CreatedAt); err == nil {
-			chat.LastMessage = &msg
+		chats = append(chats, &chat)
+		drafts = append(drafts, draft)
+		lastMessages = append(lastMessages, last.stored())
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Encrypted text is opened once the rows are read, as it may need to
+	// load chat keys
+	for i, chat := range chats {
+		if draft := drafts[i]; draft.content != nil || draft.ciphertext != nil {
+			content, err := r.openChatText(ctx, chat.ID, draft.content, draft.ciphertext, draft.keyVersion)
+			if err != nil {
+				return nil, err
+			}
+			chat.Draft = &domain.ChatDraft{ChatID: chat.ID, Content: content, UpdatedAt: *draft.updatedAt}
+		}
+		if lastMessages[i] != nil {
+			msg, err := r.openStoredMessage(ctx, lastMessages[i])
+			if err != nil {
+				return nil, err
+			}
+			chat.LastMessage = msg
 		}
 	}
 
@@ -726,23 +1295,96 @@ func (r *PostgresRepository) GetChatsByUserID(ctx context.Context, userID uuid.U
 }
 
 func (r *PostgresRepository) CreateMessage(ctx context.Context, chatID, senderID uuid.UUID, content string) (*domain.Message, error) {
+	return r.createMessage(ctx, chatID, senderID, content, nil, false, nil)
+}
+
+// CreateStoryShare stores a message sharing a story, with its snapshot
+func (r *PostgresRepository) CreateStoryShare(ctx context.Context, chatID, senderID uuid.UUID, content string, story *domain.SharedStory) (*domain.Message, error) {
+	return r.createMessage(ctx, chatID, senderID, content, story, false, nil)
+}
+
+// CreateStoryReply stores a reply to a story, with the snapshot of the story
+func (r *PostgresRepository) CreateStoryReply(ctx context.Context, chatID, senderID uuid.UUID, content string, story *domain.SharedStory) (*domain.Message, error) {
+	return r.createMessage(ctx, chatID, senderID, content, story, true, nil)
+}
+
+// CreateVoiceNote stores a voice message, which has no text
+func (r *PostgresRepository) CreateVoiceNote(ctx context.Context, chatID, senderID uuid.UUID, media *domain.MessageMedia) (*domain.Message, error) {
+	return r.createMessage(ctx, chatID, senderID, "", nil, false, media)
+}
+
+func (r *PostgresRepository) createMessage(ctx context.Context, chatID, senderID uuid.UUID, content string, story *domain.SharedStory, storyReply bool, media *domain.MessageMedia) (*domain.Message, error) {
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback(ctx)
 
+	// With encryption enabled only the ciphertext is stored
+	plaintext := &content
+	var ciphertext []byte
+	var keyVersion *int
+	if r.messageKeys != nil {
+		sealed, version, err := r.sealMessage(ctx, tx, chatID, content)
+		if err != nil {
+			return nil, err
+		}
+		plaintext, ciphertext, keyVersion = nil, sealed, &version
+	}
+
+	var sharedStoryID *uuid.UUID
+	var snapshot []byte
+	if story != nil {
+		sharedStoryID = &story.StoryID
+		if snapshot, err = json.Marshal(story); err != nil {
+			return nil, err
+		}
+	}
+
+	var mediaURL, mediaType *string
+	var mediaDurationMs *int
+	var mediaSizeBytes *int64
+	if media != nil {
+		mediaURL, mediaType, mediaDurationMs, mediaSizeBytes = &media.URL, &media.MediaType, &media.DurationMs, &media.SizeBytes
+	}
+
 	query := `
-		INSERT INTO messages (chat_id, sender_id, content)
-		VALUES ($1, $2, $3)
+		INSERT INTO messages (chat_id, sender_id, content, content_ciphertext, key_version, shared_story_id, shared_story,
+			story_reply, media_url, media_type, media_duration_ms, media_size_bytes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING id, created_at
 	`
 	var msg domain.Message
 	msg.ChatID = chatID
 	msg.SenderID = senderID
 	msg.Content = content
+	msg.Kind = domain.MessageKindText
+	if story != nil {
+		msg.Kind = domain.MessageKindStoryShare
+		if storyReply {
+			msg.Kind = domain.MessageKindStoryReply
+		}
+		msg.SharedStory = story
+	}
+	if media != nil {
+		msg.Kind = domain.MessageKindVoice
+		msg.Media = media
+	}
+
+	msg.Status = domain.MessageStatusSent
+
+	err = tx.QueryRow(ctx, query, chatID, senderID, plaintext, ciphertext, keyVersion, sharedStoryID, snapshot,
+		storyReply, mediaURL, mediaType, mediaDurationMs, mediaSizeBytes).Scan(&msg.ID, &msg.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
 
-	err = tx.QueryRow(ctx, query, chatID, senderID, content).Scan(&msg.ID, &msg.CreatedAt)
+	// One receipt for everyone else in the chat, tracking delivery and reads
+	_, err = tx.Exec(ctx, `
+		INSERT INTO message_receipts (message_id, chat_id, sender_id, user_id, created_at)
+		SELECT $1, $2, $3, user_id, $4 FROM chat_participants
+		WHERE chat_id = $2 AND user_id <> $3
+	`, msg.ID, chatID, senderID, msg.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -760,15 +1402,61 @@ func (r *PostgresRepository) CreateMessage(ctx context.Context, chatID, senderID
 	return &msg, nil
 }
 
-func (r *PostgresRepository) GetMessages(ctx context.Context, chatID uuid.UUID, limit, offset int) ([]*domain.Message, error) {
-	query := `
-		SELECT id, chat_id, sender_id, content, read_at, created_at
-		FROM messages
-		WHERE chat_id = $1
-		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3
-	`
-	rows, err := r.db.Query(ctx, query, chatID, limit, offset)
+// GetMessages returns a page of a chat's messages, newest first. Cursors are
+// compared by (created_at, id), so messages sharing a timestamp are neither
+// skipped nor repeated. Deleted messages are kept in as tombstones.
+func (r *PostgresRepository) GetMessages(ctx context.Context, chatID uuid.UUID, page domain.MessagePage) ([]*domain.Message, error) {
+	cursor := page.Before
+	if page.After != nil {
+		cursor = page.After
+	}
+
+	var query string
+	var args []interface{}
+	if cursor == nil {
+		query = `
+			SELECT ` + messageColumns + `
+			FROM messages
+			WHERE chat_id = $1 AND ($4::timestamptz IS NULL OR created_at > $4)
+			ORDER BY created_at DESC, id DESC
+			LIMIT $2 OFFSET $3
+		`
+		args = []interface{}{chatID, page.Limit, page.Offset, page.Since}
+	} else {
+		var cursorAt time.Time
+		err := r.db.QueryRow(ctx, `SELECT created_at FROM messages WHERE id = $1 AND chat_id = $2`, *cursor, chatID).Scan(&cursorAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrInvalidMessageCursor
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if page.After != nil {
+			// Take the oldest newer messages, then flip them to newest first
+			query = `
+				SELECT * FROM (
+					SELECT ` + messageColumns + `
+					FROM messages
+					WHERE chat_id = $1 AND (created_at, id) > ($2, $3) AND ($5::timestamptz IS NULL OR created_at > $5)
+					ORDER BY created_at, id
+					LIMIT $4
+				) newer
+				ORDER BY created_at DESC, id DESC
+			`
+		} else {
+			query = `
+				SELECT ` + messageColumns + `
+				FROM messages
+				WHERE chat_id = $1 AND (created_at, id) < ($2, $3) AND ($5::timestamptz IS NULL OR created_at > $5)
+				ORDER BY created_at DESC, id DESC
+				LIMIT $4
+			`
+		}
+		args = []interface{}{chatID, cursorAt, *cursor, page.Limit, page.Since}
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -776,52 +1464,255 @@ func (r *PostgresRepository) GetMessages(ctx context.Context, chatID uuid.UUID,
 
 	var messages []*domain.Message
 	for rows.Next() {
-		var msg domain.Message
-		if err := rows.Scan(&msg.ID, &msg.ChatID, &msg.SenderID, &msg.Content, &msg.ReadAt, &msg.CreatedAt); err != nil {
+		msg, err := r.scanMessage(ctx, rows)
+		if err != nil {
 			return nil, err
 		}
-		messages = append(messages, &msg)
+		messages = append(messages, msg)
 	}
 	return messages, nil
 }
 
-// Connection methods
+// MarkMessagesRead marks the chat's unread messages from others as read and
+// groups them into one receipt per sender. The reader's message receipts are
+// marked read (and delivered, if they weren't yet); messages.read_at is still
+// set on the first read, as the unread counts and messages sent before
+// receipts existed rely on it.
+func (r *PostgresRepository) MarkMessagesRead(ctx context.Context, chatID, readerID uuid.UUID) ([]*domain.ReadReceipt, error) {
+	// Both updates see the same NOW(), so UNION drops messages found by both
+	rows, err := r.db.Query(ctx, `
+		WITH receipts AS (
+			UPDATE message_receipts SET read_at = NOW(), delivered_at = COALESCE(delivered_at, NOW())
+			WHERE chat_id = $1 AND user_id = $2 AND read_at IS NULL
+			RETURNING message_id, sender_id, read_at
+		), legacy AS (
+			UPDATE messages SET read_at = NOW()
+			WHERE chat_id = $1 AND sender_id <> $2 AND read_at IS NULL
+			RETURNING id, sender_id, read_at
+		)
+		SELECT message_id, sender_id, read_at FROM receipts
+		UNION
+		SELECT id, sender_id, read_at FROM legacy
+	`, chatID, readerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-func (r *PostgresRepository) CreateConnectionRequest(ctx context.Context, requesterID, receiverID uuid.UUID) (*domain.Connection, error) {
-	// Check if reverse connection exists
-	queryCheck := `SELECT id, status FROM connections WHERE requester_id = $1 AND receiver_id = $2`
-	var existingID uuid.UUID
-	var status domain.ConnectionStatus
-	err := r.db.QueryRow(ctx, queryCheck, receiverID, requesterID).Scan(&existingID, &status)
-	if err == nil {
-		// If reverse exists and is pending, we could auto-accept.
-		// For now simple implementation: just error or let unique constraint fail if direct dupe.
-		// If explicit logic needed:
-		if status == domain.ConnectionStatusPending {
-			// Auto accept logic could go here, but let's stick to standard flow:
-			// User B requested User A. User A requesting User B should probably just accept User B's request.
-			// Implementing auto-accept:
-			return r.UpdateConnectionStatus(ctx, existingID, domain.ConnectionStatusAccepted)
+	var receipts []*domain.ReadReceipt
+	bySender := make(map[uuid.UUID]*domain.ReadReceipt)
+	for rows.Next() {
+		var id, senderID uuid.UUID
+		var readAt time.Time
+		if err := rows.Scan(&id, &senderID, &readAt); err != nil {
+			return nil, err
 		}
+		receipt, ok := bySender[senderID]
+		if !ok {
+			receipt = &domain.ReadReceipt{ChatID: chatID, ReaderID: readerID, SenderID: senderID, ReadAt: readAt}
+			bySender[senderID] = receipt
+			receipts = append(receipts, receipt)
+		}
+		receipt.MessageIDs = append(receipt.MessageIDs, id)
 	}
+	return receipts, rows.Err()
+}
 
-	query := `
-		INSERT INTO connections (requester_id, receiver_id, status)
-		VALUES ($1, $2, 'pending')
-		ON CONFLICT (requester_id, receiver_id) DO UPDATE SET updated_at = NOW() -- prevent duplicate error, maybe return existing
-		RETURNING id, requester_id, receiver_id, status, created_at, updated_at
-	`
-	// Note: On conflict we might want to check status. If rejected, maybe allow re-request?
-	// For MVP, just return the inserted/updated row.
+// messageColumns assumes a query over messages without an alias. Near the
+// end are whether a shared story can still be shown and the message's
+// status, taken from its receipts: read or delivered once it is for every
+// recipient. Messages from before receipts fall back to read_at.
+const messageColumns = `id, chat_id, sender_id, content, content_ciphertext, key_version, read_at, created_at, shared_story,
+	media_url, media_type, media_duration_ms, media_size_bytes,
+	EXISTS (
+		SELECT 1 FROM stories st
+		WHERE st.id = messages.shared_story_id AND st.expires_at > NOW() AND st.hidden_at IS NULL
+	),
+	COALESCE((
+		SELECT CASE
+			WHEN COUNT(*) = 0 THEN NULL
+			WHEN BOOL_AND(mr.read_at IS NOT NULL) THEN 'read'
+			WHEN BOOL_AND(mr.delivered_at IS NOT NULL) THEN 'delivered'
+			ELSE 'sent'
+		END
+		FROM message_receipts mr WHERE mr.message_id = messages.id
+	), CASE WHEN messages.read_at IS NOT NULL THEN 'read' ELSE 'sent' END),
+	deleted_at, story_reply`
+
+// storedMessage is a row of messageColumns before its content is opened
+type storedMessage struct {
+	msg             domain.Message
+	content         *string
+	ciphertext      []byte
+	keyVersion      *int
+	snapshot        []byte
+	mediaURL        *string
+	mediaType       *string
+	mediaDurationMs *int
+	mediaSizeBytes  *int64
+	storyAvailable  bool
+	storyReply      bool
+}
 
-	var conn domain.Connection
-	err = r.db.QueryRow(ctx, query, requesterID, receiverID).Scan(
-		&conn.ID, &conn.RequesterID, &conn.ReceiverID, &conn.Status, &conn.CreatedAt, &conn.UpdatedAt,
-	)
+func (m *storedMessage) dest() []interface{} {
+	return []interface{}{&m.msg.ID, &m.msg.ChatID, &m.msg.SenderID, &m.content, &m.ciphertext, &m.keyVersion,
+		&m.msg.ReadAt, &m.msg.CreatedAt, &m.snapshot, &m.mediaURL, &m.mediaType, &m.mediaDurationMs, &m.mediaSizeBytes,
+		&m.storyAvailable, &m.msg.Status, &m.msg.DeletedAt, &m.storyReply}
+}
+
+// nullableMessage scans messageColumns through an outer join, where a chat
+// without messages reads as all NULL
+type nullableMessage struct {
+	id, chatID, senderID *uuid.UUID
+	createdAt            *time.Time
+	storyAvailable       *bool
+	status               *string
+	storyReply           *bool
+	storedMessage
+}
+
+func (m *nullableMessage) dest() []interface{} {
+	dest := m.storedMessage.dest()
+	dest[0], dest[1], dest[2], dest[7], dest[13], dest[14], dest[16] = &m.id, &m.chatID, &m.senderID, &m.createdAt, &m.storyAvailable, &m.status, &m.storyReply
+	return dest
+}
+
+// stored returns the scanned message, or nil if there was none
+func (m *nullableMessage) stored() *storedMessage {
+	if m.id == nil {
+		return nil
+	}
+	s := m.storedMessage
+	s.msg.ID, s.msg.ChatID, s.msg.SenderID, s.msg.CreatedAt = *m.id, *m.chatID, *m.senderID, *m.createdAt
+	s.storyAvailable, s.msg.Status, s.storyReply = *m.storyAvailable, *m.status, *m.storyReply
+	return &s
+}
+
+// scanMessage scans messageColumns, decrypting the content if it is encrypted
+func (r *PostgresRepository) scanMessage(ctx context.Context, row pgx.Row) (*domain.Message, error) {
+	var stored storedMessage
+	if err := row.Scan(stored.dest()...); err != nil {
+		return nil, err
+	}
+	return r.openStoredMessage(ctx, &stored)
+}
+
+func (r *PostgresRepository) openStoredMessage(ctx context.Context, stored *storedMessage) (*domain.Message, error) {
+	msg := stored.msg
+	// A tombstone shows nothing, even if its content waits on a legal hold
+	if msg.DeletedAt != nil {
+		msg.Kind = domain.MessageKindDeleted
+		return &msg, nil
+	}
+	msg.Kind = domain.MessageKindText
+	if stored.snapshot != nil {
+		var story domain.SharedStory
+		if err := json.Unmarshal(stored.snapshot, &story); err != nil {
+			return nil, err
+		}
+		// Expired or taken-down stories leave a dead link
+		story.Available = stored.storyAvailable
+		if !stored.storyAvailable {
+			story.MediaURL = ""
+		}
+		msg.Kind = domain.MessageKindStoryShare
+		if stored.storyReply {
+			msg.Kind = domain.MessageKindStoryReply
+		}
+		msg.SharedStory = &story
+	}
+	if stored.mediaURL != nil {
+		msg.Kind = domain.MessageKindVoice
+		msg.Media = &domain.MessageMedia{URL: *stored.mediaURL}
+		if stored.mediaType != nil {
+			msg.Media.MediaType = *stored.mediaType
+		}
+		if stored.mediaDurationMs != nil {
+			msg.Media.DurationMs = *stored.mediaDurationMs
+		}
+		if stored.mediaSizeBytes != nil {
+			msg.Media.SizeBytes = *stored.mediaSizeBytes
+		}
+	}
+	if err := r.openMessage(ctx, &msg, stored.content, stored.ciphertext, stored.keyVersion); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// Connection methods
+
+const connectionColumns = `id, requester_id, receiver_id, status, created_at, updated_at`
+
+// pairCondition matches the single connections row for an unordered user pair (uses idx_connections_pair)
+const pairCondition = `LEAST(requester_id, receiver_id) = LEAST($1::uuid, $2::uuid) AND GREATEST(requester_id, receiver_id) = GREATEST($1::uuid, $2::uuid)`
+
+// TransitionConnection serializes changes to a user pair with an advisory lock,
+// lets decide inspect the current row, and applies the resulting update
+func (r *PostgresRepository) TransitionConnection(ctx context.Context, userA, userB uuid.UUID, decide func(current *domain.Connection) (*domain.ConnectionUpdate, error)) (*domain.Connection, error) {
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return &conn, nil
+	defer tx.Rollback(ctx)
+
+	// Lock the pair even when no row exists yet, so concurrent A->B and B->A requests serialize
+	_, err = tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtextextended(LEAST($1::uuid, $2::uuid)::text || GREATEST($1::uuid, $2::uuid)::text, 0))`, userA, userB)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := scanConnection(tx.QueryRow(ctx, `SELECT `+connectionColumns+` FROM connections WHERE `+pairCondition, userA, userB))
+	if err != nil && !errors.Is(err, domain.ErrConnectionNotFound) {
+		return nil, err
+	}
+
+	update, err := decide(current)
+	if err != nil {
+		return nil, err
+	}
+	if update == nil {
+		return current, nil
+	}
+
+	var result *domain.Connection
+	switch {
+	case update.Delete:
+		if current != nil {
+			if _, err := tx.Exec(ctx, "DELETE FROM connections WHERE id = $1", current.ID); err != nil {
+				return nil, err
+			}
+		}
+	case current == nil:
+		result, err = scanConnection(tx.QueryRow(ctx, `
+			INSERT INTO connections (requester_id, receiver_id, status)
+			VALUES ($1, $2, $3)
+			RETURNING `+connectionColumns,
+			update.RequesterID, update.ReceiverID, update.Status,
+		))
+	default:
+		result, err = scanConnection(tx.QueryRow(ctx, `
+			UPDATE connections
+			SET requester_id = $2, receiver_id = $3, status = $4, updated_at = NOW()
+			WHERE id = $1
+			RETURNING `+connectionColumns,
+			current.ID, update.RequesterID, update.ReceiverID, update.Status,
+		))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetConnectionBetween returns the relationship row for a user pair regardless of direction
+func (r *PostgresRepository) GetConnectionBetween(ctx context.Context, userA, userB uuid.UUID) (*domain.Connection, error) {
+	query := `SELECT ` + connectionColumns + ` FROM connections WHERE ` + pairCondition
+	return scanConnection(r.db.QueryRow(ctx, query, userA, userB))
 }
 
 func (r *PostgresRepository) UpdateConnectionStatus(ctx context.Context, connectionID uuid.UUID, status domain.ConnectionStatus) (*domain.Connection, error) {
@@ -829,25 +1720,22 @@ func (r *PostgresRepository) UpdateConnectionStatus(ctx context.Context, connect
 		UPDATE connections
 		SET status = $2, updated_at = NOW()
 		WHERE id = $1
-		RETURNING id, requester_id, receiver_id, status, created_at, updated_at
-	`
-	var conn domain.Connection
-	err := r.db.QueryRow(ctx, query, connectionID, status).Scan(
-		&conn.ID, &conn.RequesterID, &conn.ReceiverID, &conn.Status, &conn.CreatedAt, &conn.UpdatedAt,
-	)
-	if err != nil {
-		return nil, err
-	}
-	return &conn, nil
+		RETURNING ` + connectionColumns
+	return scanConnection(r.db.QueryRow(ctx, query, connectionID, status))
 }
 
 func (r *PostgresRepository) GetConnectionByID(ctx context.Context, connectionID uuid.UUID) (*domain.Connection, error) {
-	query := `SELECT id, requester_id, receiver_id, status, created_at, updated_at FROM connections WHERE id = $1`
+	query := `SELECT ` + connectionColumns + ` FROM connections WHERE id = $1`
+	return scanConnection(r.db.QueryRow(ctx, query, connectionID))
+}
+
+func scanConnection(row pgx.Row) (*domain.Connection, error) {
 	var conn domain.Connection
-	err := r.db.QueryRow(ctx, query, connectionID).Scan(
-		&conn.ID, &conn.RequesterID, &conn.ReceiverID, &conn.Status, &conn.CreatedAt, &conn.UpdatedAt,
-	)
+	err := row.Scan(&conn.ID, &conn.RequesterID, &conn.ReceiverID, &conn.Status, &conn.CreatedAt, &conn.UpdatedAt)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrConnectionNotFound
+		}
 		return nil, err
 	}
 	return &conn, nil
@@ -867,7 +1755,7 @@ func (r *PostgresRepository) GetConnections(ctx context.Context, userID uuid.UUI
 	case domain.ConnectionStatusAccepted:
 		query = `
 			SELECT c.id, c.requester_id, c.receiver_id, c.status, c.created_at, c.updated_at,
-			       u.id, u.email, u.phone, u.name, u.avatar_url
+			       u.id, u.email, u.phone, u.name, u.username, u.avatar_url
 			FROM connections c
 			JOIN users u ON (CASE WHEN c.requester_id = $1 THEN c.receiver_id ELSE c.requester_id END) = u.id
 			WHERE (c.requester_id = $1 OR c.receiver_id = $1)
@@ -880,7 +1768,7 @@ func (r *PostgresRepository) GetConnections(ctx context.Context, userID uuid.UUI
 		// Default to requests RECEIVED by user (to accept)
 		query = `
 			SELECT c.id, c.requester_id, c.receiver_id, c.status, c.created_at, c.updated_at,
-			       u.id, u.email, u.phone, u.name, u.avatar_url
+			       u.id, u.email, u.phone, u.name, u.username, u.avatar_url
 			FROM connections c
 			JOIN users u ON c.requester_id = u.id
 			WHERE c.receiver_id = $1
@@ -905,7 +1793,7 @@ func (r *PostgresRepository) GetConnections(ctx context.Context, userID uuid.UUI
 		// We join to get the "other" user details
 		err := rows.Scan(
 			&conn.ID, &conn.RequesterID, &conn.ReceiverID, &conn.Status, &conn.CreatedAt, &conn.UpdatedAt,
-			&u.ID, &u.Email, &u.Phone, &u.Name, &u.AvatarURL,
+			&u.ID, &u.Email, &u.Phone, &u.Name, &u.Username, &u.AvatarURL,
 		)
 		if err != nil {
 			return nil, err
@@ -916,6 +1804,60 @@ func (r *PostgresRepository) GetConnections(ctx context.Context, userID uuid.UUI
 	return connections, nil
 }
 
+// GetConnectedUserIDs returns the IDs of everyone userID is connected with
+func (r *PostgresRepository) GetConnectedUserIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		SELECT CASE WHEN requester_id = $1 THEN receiver_id ELSE requester_id END
+		FROM connections
+		WHERE (requester_id = $1 OR receiver_id = $1) AND status = 'accepted'
+	`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// GetConnectedAmong checks a batch of users against userID's accepted
+// connections in one query
+func (r *PostgresRepository) GetConnectedAmong(ctx context.Context, userID uuid.UUID, candidateIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	connected := make(map[uuid.UUID]bool)
+	if len(candidateIDs) == 0 {
+		return connected, nil
+	}
+
+	query := `
+		SELECT CASE WHEN requester_id = $1 THEN receiver_id ELSE requester_id END
+		FROM connections
+		WHERE status = 'accepted'
+		AND ((requester_id = $1 AND receiver_id = ANY($2)) OR (receiver_id = $1 AND requester_id = ANY($2)))
+	`
+	rows, err := r.db.Query(ctx, query, userID, candidateIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		connected[id] = true
+	}
+	return connected, rows.Err()
+}
+
 func (r *PostgresRepository) DeleteConnection(ctx context.Context, connectionID uuid.UUID) error {
 	_, err := r.db.Exec(ctx, "DELETE FROM connections WHERE id = $1", connectionID)
 	return err
@@ -923,23 +1865,52 @@ func (r *PostgresRepository) DeleteConnection(ctx context.Context, connectionID
 
 // Notification methods
 
-func (r *PostgresRepository) CreateNotification(ctx context.Context, userID uuid.UUID, typeStr, title, body string, data map[string]interface{}) error {
+func (r *PostgresRepository) CreateNotification(ctx context.Context, userID uuid.UUID, typeStr, title, body string, data map[string]interface{}) (uuid.UUID, error) {
 	dataJSON, err := json.Marshal(data)
 	if err != nil {
-		return err
+		return uuid.Nil, err
 	}
 
 	query := `
 		INSERT INTO notifications (user_id, type, title, body, data)
 		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
 	`
-	_, err = r.db.Exec(ctx, query, userID, typeStr, title, body, dataJSON)
-	return err
+	var id uuid.UUID
+	err = r.db.QueryRow(ctx, query, userID, typeStr, title, body, dataJSON).Scan(&id)
+	return id, err
+}
+
+// CreateNotifications bulk inserts the same notification for every user using COPY.
+// The copy is atomic, so a single invalid user fails the whole call. IDs are
+// generated here since COPY can't return them.
+func (r *PostgresRepository) CreateNotifications(ctx context.Context, userIDs []uuid.UUID, typeStr, title, body string, data map[string]interface{}) ([]uuid.UUID, error) {
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, len(userIDs))
+	for i := range ids {
+		ids[i] = uuid.New()
+	}
+
+	_, err = r.db.CopyFrom(ctx,
+		pgx.Identifier{"notifications"},
+		[]string{"id", "user_id", "type", "title", "body", "data"},
+		pgx.CopyFromSlice(len(userIDs), func(i int) ([]any, error) {
+			return []any{ids[i], userIDs[i], typeStr, title, body, dataJSON}, nil
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
 }
 
 func (r *PostgresRepository) GetNotifications(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*domain.Notification, error) {
 	query := `
-		SELECT id, user_id, type, title, body, data, is_read, created_at
+		SELECT id, user_id, type, title, body, data, is_read, opened_at, created_at
 		FROM notifications
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -955,7 +1926,7 @@ func (r *PostgresRepository) GetNotifications(ctx context.Context, userID uuid.U
 	for rows.Next() {
 		var n domain.Notification
 		var dataJSON []byte
-		if err := rows.Scan(&n.ID, &n.UserID, &n.Type, &n.Title, &n.Body, &dataJSON, &n.IsRead, &n.CreatedAt); err != nil {
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Type, &n.Title, &n.Body, &dataJSON, &n.IsRead, &n.OpenedAt, &n.CreatedAt); err != nil {
 			return nil, err
 		}
 		if len(dataJSON) > 0 {
@@ -994,3 +1965,150 @@ func (r *PostgresRepository) GetFCMTokens(ctx context.Context, userID uuid.UUID)
 	}
 	return tokens, nil
 }
+
+// GetFCMTokensForUsers returns active device tokens grouped by user
+func (r *PostgresRepository) GetFCMTokensForUsers(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	query := `
+		SELECT DISTINCT user_id, fcm_token
+		FROM sessions
+		WHERE user_id = ANY($1) AND is_active = TRUE AND fcm_token IS NOT NULL AND fcm_token != ''
+	`
+	rows, err := r.db.Query(ctx, query, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := make(map[uuid.UUID][]string)
+	for rows.Next() {
+		var userID uuid.UUID
+		var token string
+		if err := rows.Scan(&userID, &token); err != nil {
+			return nil, err
+		}
+		tokens[userID] = append(tokens[userID], token)
+	}
+	return tokens, rows.Err()
+}
+
+const emailVerificationTokenColumns = `id, user_id, email, token_hash, expires_at, used_at, created_at`
+
+func scanEmailVerificationToken(row pgx.Row) (*domain.EmailVerificationToken, error) {
+	var t domain.EmailVerificationToken
+	if err := row.Scan(&t.ID, &t.UserID, &t.Email, &t.TokenHash, &t.ExpiresAt, &t.UsedAt, &t.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// CreateEmailVerificationToken stores a verification token for an email address
+func (r *PostgresRepository) CreateEmailVerificationToken(ctx context.Context, userID uuid.UUID, email, tokenHash string, expiresAt time.Time) error {
+	query := `
+		INSERT INTO email_verification_tokens (user_id, email, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`
+	_, err := r.db.Exec(ctx, query, userID, email, tokenHash, expiresAt)
+	return err
+}
+
+// GetEmailVerificationToken retrieves a verification token by hash
+func (r *PostgresRepository) GetEmailVerificationToken(ctx context.Context, tokenHash string) (*domain.EmailVerificationToken, error) {
+	query := `SELECT ` + emailVerificationTokenColumns + ` FROM email_verification_tokens WHERE token_hash = $1`
+	token, err := scanEmailVerificationToken(r.db.QueryRow(ctx, query, tokenHash))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrInvalidToken
+	}
+	return token, err
+}
+
+// GetLatestEmailVerificationToken returns the user's most recent verification token, or nil
+func (r *PostgresRepository) GetLatestEmailVerificationToken(ctx context.Context, userID uuid.UUID) (*domain.EmailVerificationToken, error) {
+	query := `SELECT ` + emailVerificationTokenColumns + ` FROM email_verification_tokens WHERE user_id = $1 ORDER BY created_at DESC LIMIT 1`
+	token, err := scanEmailVerificationToken(r.db.QueryRow(ctx, query, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return token, err
+}
+
+// MarkEmailVerified uses up the user's outstanding tokens and verifies their
+// email, provided it is still the address the token was issued for
+func (r *PostgresRepository) MarkEmailVerified(ctx context.Context, token *domain.EmailVerificationToken) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `UPDATE users SET email_verified = TRUE WHERE id = $1 AND email = $2`, token.UserID, token.Email)
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE email_verification_tokens SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL`, token.UserID)
+	if err != nil {
+		return false, err
+	}
+	return true, tx.Commit(ctx)
+}
+
+// CreatePhoneOTP stores the hash of a code sent to phone
+func (r *PostgresRepository) CreatePhoneOTP(ctx context.Context, phone, codeHash string, expiresAt time.Time) error {
+	query := `INSERT INTO phone_otps (phone, code_hash, expires_at) VALUES ($1, $2, $3)`
+	_, err := r.db.Exec(ctx, query, phone, codeHash, expiresAt)
+	return err
+}
+
+// GetLatestPhoneOTP returns the most recent code sent to phone, or nil
+func (r *PostgresRepository) GetLatestPhoneOTP(ctx context.Context, phone string) (*domain.PhoneOTP, error) {
+	query := `
+		SELECT id, phone, code_hash, attempts, expires_at, consumed_at, created_at
+		FROM phone_otps WHERE phone = $1
+		ORDER BY created_at DESC LIMIT 1
+	`
+	var otp domain.PhoneOTP
+	err := r.db.QueryRow(ctx, query, phone).Scan(&otp.ID, &otp.Phone, &otp.CodeHash, &otp.Attempts, &otp.ExpiresAt, &otp.ConsumedAt, &otp.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &otp, nil
+}
+
+// CountPhoneOTPsSince counts codes sent to phone since the given time
+func (r *PostgresRepository) CountPhoneOTPsSince(ctx context.Context, phone string, since time.Time) (int, error) {
+	var n int
+	err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM phone_otps WHERE phone = $1 AND created_at >= $2`, phone, since).Scan(&n)
+	return n, err
+}
+
+// IncrementPhoneOTPAttempts records a wrong guess at a code
+func (r *PostgresRepository) IncrementPhoneOTPAttempts(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `UPDATE phone_otps SET attempts = attempts + 1 WHERE id = $1`, id)
+	return err
+}
+
+// ConsumePhoneOTP marks a code used, reporting false if it already was
+func (r *PostgresRepository) ConsumePhoneOTP(ctx context.Context, id uuid.UUID) (bool, error) {
+	tag, err := r.db.Exec(ctx, `UPDATE phone_otps SET consumed_at = NOW() WHERE id = $1 AND consumed_at IS NULL`, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// SetUserPhoneVerified sets a user's phone and marks it verified
+func (r *PostgresRepository) SetUserPhoneVerified(ctx context.Context, userID uuid.UUID, phone string) (*domain.User, error) {
+	query := `
+		UPDATE users SET phone = $2, phone_verified = TRUE
+		WHERE id = $1 AND is_active = TRUE
+		RETURNING id, email, phone, name, username, avatar_url, bio, gender, date_of_birth, visibility, country_code, google_id, email_verified, phone_verified, is_active, verified_at, created_at, updated_at
+	`
+	user, err := scanUser(r.db.QueryRow(ctx, query, userID, phone))
+	return user, mapUserError(err)
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/locolive/backend/internal/domain"
+)
+
+// BenchmarkGetChatsByUserID loads the chat list of a user with 50 chats of 20
+// messages each. It needs a migrated database in DATABASE_URL, and removes
+// the users and chats it creates.
+func BenchmarkGetChatsByUserID(b *testing.B) {
+	dsn := os.Getenv("DATABASE_URL")
+	if dsn == "" {
+		b.Skip("DATABASE_URL not set")
+	}
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		b.Fatalf("connect: %v", err)
+	}
+	b.Cleanup(pool.Close)
+	repo := NewPostgresRepository(pool)
+
+	var userIDs, chatIDs []uuid.UUID
+	b.Cleanup(func() {
+		pool.Exec(ctx, "DELETE FROM chats WHERE id = ANY($1)", chatIDs)
+		pool.Exec(ctx, "DELETE FROM users WHERE id = ANY($1)", userIDs)
+	})
+	newUser := func(name string) *domain.User {
+		email := fmt.Sprintf("bench-%s@example.com", uuid.NewString())
+		user, err := repo.CreateUser(ctx, domain.CreateUserParams{Email: &email, Name: name})
+		if err != nil {
+			b.Fatalf("create user: %v", err)
+		}
+		userIDs = append(userIDs, user.ID)
+		return user
+	}
+
+	viewer := newUser("Viewer")
+	for i := 0; i < 50; i++ {
+		other := newUser(fmt.Sprintf("Friend %d", i))
+		chat, err := repo.CreateChat(ctx, viewer.ID, other.ID)
+		if err != nil {
+			b.Fatalf("create chat: %v", err)
+		}
+		chatIDs = append(chatIDs, chat.ID)
+		for j := 0; j < 20; j++ {
+			sender := viewer.ID
+			if j%2 == 0 {
+				sender = other.ID
+			}
+			if _, err := repo.CreateMessage(ctx, chat.ID, sender, "hello"); err != nil {
+				b.Fatalf("create message: %v", err)
+			}
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		chats, err := repo.GetChatsByUserID(ctx, viewer.ID)
+		if err != nil {
+			b.Fatalf("get chats: %v", err)
+		}
+		if len(chats) != 50 {
+			b.Fatalf("got %d chats, want 50", len(chats))
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/locolive/backend/internal/domain"
+)
+
+// RectifyUserPII corrects a user's PII and records the audit entry in the same
+// transaction. A changed email or phone is no longer verified.
+func (r *PostgresRepository) RectifyUserPII(ctx context.Context, userID uuid.UUID, params domain.RectifyUserParams, audit domain.AuditEntry) (*domain.User, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	user, err := scanUser(tx.QueryRow(ctx, `
+		UPDATE users
+		SET name = COALESCE($2, name),
+			email_verified = CASE WHEN $3::text IS NOT NULL AND $3 IS DISTINCT FROM email THEN FALSE ELSE email_verified END,
+			email = COALESCE($3, email),
+			phone_verified = CASE WHEN $4::text IS NOT NULL AND $4 IS DISTINCT FROM phone THEN FALSE ELSE phone_verified END,
+			phone = COALESCE($4, phone),
+			bio = COALESCE($5, bio),
+			gender = COALESCE($6, gender),
+			date_of_birth = COALESCE($7, date_of_birth)
+		WHERE id = $1
+		RETURNING id, email, phone, name, username, avatar_url, bio, gender, date_of_birth, visibility, country_code, google_id, email_verified, phone_verified, is_active, verified_at, created_at, updated_at
+	`, userID, params.Name, params.Email, params.Phone, params.Bio, params.Gender, params.DateOfBirth))
+	if err != nil {
+		return nil, mapUserError(err)
+	}
+
+	if err := insertAudit(ctx, tx, audit); err != nil {
+		return nil, err
+	}
+	return user, tx.Commit(ctx)
+}
+
+// PseudonymizeUser replaces the user's PII with a random pseudonym, scrubs
+// device and network details from their sessions, revokes their tokens and
+// redacts their former identifiers from the audit log, all in one transaction.
+// Preservation snapshots are left untouched.
+func (r *PostgresRepository) PseudonymizeUser(ctx context.Context, userID uuid.UUID, audit domain.AuditEntry) (*domain.PseudonymizationResult, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var email, phone *string
+	var name string
+	var held bool
+	err = tx.QueryRow(ctx, `
+		SELECT email, phone, name, EXISTS (
+			SELECT 1 FROM legal_holds WHERE user_id = $1 AND released_at IS NULL
+		)
+		FROM users WHERE id = $1
+		FOR UPDATE
+	`, userID).Scan(&email, &phone, &name, &held)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if held {
+		return nil, domain.ErrUserOnLegalHold
+	}
+
+	result := &domain.PseudonymizationResult{UserID: userID}
+	err = tx.QueryRow(ctx, `
+		UPDATE users
+		SET name = 'user_' || substr(md5(random()::text), 1, 10),
+			username = NULL, email = NULL, phone = NULL, google_id = NULL, apple_id = NULL, password_hash = NULL,
+			avatar_url = NULL, bio = NULL, gender = NULL, date_of_birth = NULL,
+			email_verified = FALSE, phone_verified = FALSE, verified_at = NULL, is_active = FALSE
+		WHERE id = $1
+		RETURNING name
+	`, userID).Scan(&result.Pseudonym)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE sessions
+		SET device_info = NULL, ip_address = NULL, user_agent = NULL, region = NULL, fcm_token = NULL, is_active = FALSE
+		WHERE user_id = $1
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	result.SessionsScrubbed = tag.RowsAffected()
+
+	tag, err = tx.Exec(ctx, `
+		UPDATE refresh_tokens SET revoked = TRUE, revoked_at = NOW()
+		WHERE user_id = $1 AND revoked = FALSE
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	result.TokensRevoked = tag.RowsAffected()
+
+	// Login attempts hold the email and IPs the user signed in with
+	if _, err := tx.Exec(ctx, `DELETE FROM login_attempts WHERE user_id = $1 OR email = $2`, userID, email); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE security_events SET ip_address = NULL, user_agent = NULL WHERE user_id = $1`, userID); err != nil {
+		return nil, err
+	}
+
+	identifiers := []string{name}
+	if email != nil {
+		identifiers = append(identifiers, *email)
+	}
+	if phone != nil {
+		identifiers = append(identifiers, *phone)
+	}
+
+	// The audit log trigger only allows this rewrite while pii_redaction is on
+	if _, err := tx.Exec(ctx, `SELECT set_config('locolive.pii_redaction', 'on', true)`); err != nil {
+		return nil, err
+	}
+	tag, err = tx.Exec(ctx, `
+		UPDATE admin_audit_log
+		SET details = (
+			SELECT jsonb_object_agg(key, CASE WHEN value #>> '{}' = ANY($1) THEN to_jsonb('[redacted]'::text) ELSE value END)
+			FROM jsonb_each(details)
+		)
+		WHERE jsonb_typeof(details) = 'object'
+		AND EXISTS (SELECT 1 FROM jsonb_each(details) WHERE value #>> '{}' = ANY($1))
+	`, identifiers)
+	if err != nil {
+		return nil, err
+	}
+	result.AuditEntriesRedacted = tag.RowsAffected()
+
+	audit.Details = map[string]interface{}{
+		"sessions_scrubbed":      result.SessionsScrubbed,
+		"tokens_revoked":         result.TokensRevoked,
+		"audit_entries_redacted": result.AuditEntriesRedacted,
+	}
+	if err := insertAudit(ctx, tx, audit); err != nil {
+		return nil, err
+	}
+	return result, tx.Commit(ctx)
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/locolive/backend/internal/domain"
+)
+
+// GetRecapCandidates returns active users who posted or connected in [from, to)
+// and have no recap for weekStart yet
+func (r *PostgresRepository) GetRecapCandidates(ctx context.Context, weekStart, from, to time.Time, after uuid.UUID, limit int) ([]uuid.UUID, error) {
+	query := `
+		SELECT u.id
+		FROM users u
+		WHERE u.is_active = TRUE AND u.id > $4
+		AND (
+			EXISTS (
+				SELECT 1 FROM user_activity_daily a
+				WHERE a.user_id = u.id AND a.day >= ($2::timestamptz AT TIME ZONE 'UTC')::date
+				AND a.day <= ($3::timestamptz AT TIME ZONE 'UTC')::date
+			)
+			OR EXISTS (
+				SELECT 1 FROM connections c
+				WHERE (c.requester_id = u.id OR c.receiver_id = u.id)
+				AND c.status = 'accepted' AND c.updated_at >= $2::timestamptz AND c.updated_at < $3::timestamptz
+			)
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM weekly_recaps w WHERE w.user_id = u.id AND w.week_start = $1::date
+		)
+		ORDER BY u.id
+		LIMIT $5
+	`
+	rows, err := r.db.Query(ctx, query, weekStart, from, to, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// GetWeeklyActivity reads a user's rolled-up activity between from and to
+func (r *PostgresRepository) GetWeeklyActivity(ctx context.Context, userID uuid.UUID, from, to time.Time) (*domain.WeeklyActivity, error) {
+	query := `
+		SELECT
+			(SELECT COALESCE(SUM(stories_posted), 0) FROM user_activity_daily
+			 WHERE user_id = $1
+			 AND day >= ($2::timestamptz AT TIME ZONE 'UTC')::date
+			 AND day <= ($3::timestamptz AT TIME ZONE 'UTC')::date),
+			(SELECT COUNT(*) FROM connections
+			 WHERE (requester_id = $1 OR receiver_id = $1)
+			 AND status = 'accepted' AND updated_at >= $2::timestamptz AND updated_at < $3::timestamptz)
+	`
+	var a domain.WeeklyActivity
+	if err := r.db.QueryRow(ctx, query, userID, from, to).Scan(&a.StoriesPosted, &a.NewConnections); err != nil {
+		return nil, err
+	}
+
+	// The last known location may predate the week
+	err := r.db.QueryRow(ctx, `
+		SELECT last_lat, last_lng FROM user_activity_daily
+		WHERE user_id = $1 AND last_lat IS NOT NULL
+		AND day <= ($2::timestamptz AT TIME ZONE 'UTC')::date
+		ORDER BY day DESC
+		LIMIT 1
+	`, userID, to).Scan(&a.LastLat, &a.LastLng)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// GetNearbyMoments counts other users' active stories near a point and returns the most recent ones
+func (r *PostgresRepository) GetNearbyMoments(ctx context.Context, userID uuid.UUID, lat, lng, radius float64, limit int) (int, []domain.RecapMoment, error) {
+	query := `
+		SELECT id, user_id, media_url, caption, created_at, COUNT(*) OVER ()
+		FROM stories
+		WHERE user_id <> $1 AND expires_at > NOW() AND hidden_at IS NULL
+		AND location_lat IS NOT NULL AND location_lng IS NOT NULL
+		AND earth_box(ll_to_earth($2, $3), $4) @> ll_to_earth(location_lat, location_lng)
+		AND earth_distance(ll_to_earth($2, $3), ll_to_earth(location_lat, location_lng)) < $4
+		ORDER BY cardinality(location_flags) > 0, created_at DESC
+		LIMIT $5
+	`
+	rows, err := r.db.Query(ctx, query, userID, lat, lng, radius, limit)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	total := 0
+	moments := []domain.RecapMoment{}
+	for rows.Next() {
+		var m domain.RecapMoment
+		if err := rows.Scan(&m.StoryID, &m.UserID, &m.MediaURL, &m.Caption, &m.CreatedAt, &total); err != nil {
+			return 0, nil, err
+		}
+		moments = append(moments, m)
+	}
+	return total, moments, rows.Err()
+}
+
+// SaveWeeklyRecap stores a recap, reporting false if the user already has one for the week
+func (r *PostgresRepository) SaveWeeklyRecap(ctx context.Context, recap *domain.WeeklyRecap) (bool, error) {
+	payload, err := json.Marshal(recap)
+	if err != nil {
+		return false, err
+	}
+
+	tag, err := r.db.Exec(ctx, `
+		INSERT INTO weekly_recaps (user_id, week_start, payload, created_at)
+		VALUES ($1, $2::date, $3, $4)
+		ON CONFLICT (user_id, week_start) DO NOTHING
+	`, recap.UserID, recap.WeekStart, payload, recap.CreatedAt)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// GetLatestWeeklyRecap returns the user's most recent recap
+func (r *PostgresRepository) GetLatestWeeklyRecap(ctx context.Context, userID uuid.UUID) (*domain.WeeklyRecap, error) {
+	var payload []byte
+	err := r.db.QueryRow(ctx, `
+		SELECT payload FROM weekly_recaps WHERE user_id = $1 ORDER BY week_start DESC LIMIT 1
+	`, userID).Scan(&payload)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrRecapNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var recap domain.WeeklyRecap
+	if err := json.Unmarshal(payload, &recap); err != nil {
+		return nil, err
+	}
+	return &recap, nil
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/locolive/backend/internal/domain"
+)
+
+const remoteConfigColumns = `key, platform, value::text, description, version, updated_by, updated_at`
+
+func scanRemoteConfig(row pgx.Row) (*domain.RemoteConfigEntry, error) {
+	var e domain.RemoteConfigEntry
+	var value string
+	err := row.Scan(&e.Key, &e.Platform, &value, &e.Description, &e.Version, &e.UpdatedBy, &e.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrRemoteConfigNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	e.Value = json.RawMessage(value)
+	return &e, nil
+}
+
+// GetRemoteConfig returns every entry and the current config version, the id
+// of the latest change
+func (r *PostgresRepository) GetRemoteConfig(ctx context.Context) ([]*domain.RemoteConfigEntry, int64, error) {
+	var version int64
+	if err := r.db.QueryRow(ctx, `SELECT COALESCE(MAX(id), 0) FROM remote_config_history`).Scan(&version); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.Query(ctx, `SELECT `+remoteConfigColumns+` FROM remote_config ORDER BY key, platform`)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var entries []*domain.RemoteConfigEntry
+	for rows.Next() {
+		e, err := scanRemoteConfig(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, version, rows.Err()
+}
+
+// SetRemoteConfig upserts an entry and records the change in its history
+func (r *PostgresRepository) SetRemoteConfig(ctx context.Context, entry *domain.RemoteConfigEntry) (*domain.RemoteConfigEntry, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var version int64
+	err = tx.QueryRow(ctx, `
+		INSERT INTO remote_config_history (key, platform, value, changed_by)
+		VALUES ($1, $2, $3::jsonb, $4)
+		RETURNING id
+	`, entry.Key, entry.Platform, string(entry.Value), entry.UpdatedBy).Scan(&version)
+	if err != nil {
+		return nil, err
+	}
+
+	saved, err := scanRemoteConfig(tx.QueryRow(ctx, `
+		INSERT INTO remote_config (key, platform, value, description, version, updated_by)
+		VALUES ($1, $2, $3::jsonb, $4, $5, $6)
+		ON CONFLICT (key, platform) DO UPDATE
+		SET value = EXCLUDED.value,
+			description = COALESCE(EXCLUDED.description, remote_config.description),
+			version = EXCLUDED.version,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = NOW()
+		RETURNING `+remoteConfigColumns,
+		entry.Key, entry.Platform, string(entry.Value), entry.Description, version, entry.UpdatedBy,
+	))
+	if err != nil {
+		return nil, err
+	}
+	return saved, tx.Commit(ctx)
+}
+
+// DeleteRemoteConfig removes an entry and records the deletion in its history
+func (r *PostgresRepository) DeleteRemoteConfig(ctx context.Context, key string, platform domain.Platform, deletedBy uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `DELETE FROM remote_config WHERE key = $1 AND platform = $2`, key, platform)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrRemoteConfigNotFound
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO remote_config_history (key, platform, deleted, changed_by)
+		VALUES ($1, $2, TRUE, $3)
+	`, key, platform, deletedBy)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// GetRemoteConfigHistory lists changes newest first; key "" means all keys
+func (r *PostgresRepository) GetRemoteConfigHistory(ctx context.Context, key string, limit, offset int) ([]*domain.RemoteConfigChange, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, key, platform, value::text, deleted, changed_by, changed_at
+		FROM remote_config_history
+		WHERE $1 = '' OR key = $1
+		ORDER BY id DESC
+		LIMIT $2 OFFSET $3
+	`, key, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []*domain.RemoteConfigChange
+	for rows.Next() {
+		var c domain.RemoteConfigChange
+		var value *string
+		if err := rows.Scan(&c.Version, &c.Key, &c.Platform, &value, &c.Deleted, &c.ChangedBy, &c.ChangedAt); err != nil {
+			return nil, err
+		}
+		if value != nil {
+			c.Value = json.RawMessage(*value)
+		}
+		changes = append(changes, &c)
+	}
+	return changes, rows.Err()
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/domain"
+)
+
+// SaveStory bookmarks a story for the user; saving it again is a no-op
+func (r *PostgresRepository) SaveStory(ctx context.Context, userID, storyID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO saved_stories (user_id, story_id) VALUES ($1, $2)
+		ON CONFLICT (user_id, story_id) DO NOTHING
+	`, userID, storyID)
+	return err
+}
+
+// UnsaveStory removes a bookmark, if there is one
+func (r *PostgresRepository) UnsaveStory(ctx context.Context, userID, storyID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM saved_stories WHERE user_id = $1 AND story_id = $2`, userID, storyID)
+	return err
+}
+
+// GetSavedStories lists the user's saved stories they can still see, most
+// recently saved first
+func (r *PostgresRepository) GetSavedStories(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*domain.Story, error) {
+	query := `
+		SELECT ` + storyWithUserColumns + `
+		FROM saved_stories ss
+		JOIN stories s ON s.id = ss.story_id
+		JOIN users u ON s.user_id = u.id
+		WHERE ss.user_id = $1 AND ` + storyVisibleTo("$1") + `
+		ORDER BY ss.saved_at DESC, s.id
+		LIMIT $2 OFFSET $3
+	`
+	return r.queryStories(ctx, query, userID, limit, offset)
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/domain"
+)
+
+// RecordSecurityEvent stores a security event
+func (r *PostgresRepository) RecordSecurityEvent(ctx context.Context, event domain.SecurityEvent) error {
+	if event.Details == nil {
+		event.Details = map[string]interface{}{}
+	}
+	details, err := json.Marshal(event.Details)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, `
+		INSERT INTO security_events (user_id, event, ip_address, user_agent, details)
+		VALUES ($1, $2, $3, $4, $5)
+	`, event.UserID, event.Event, event.IPAddress, event.UserAgent, details)
+	return err
+}
+
+// GetSecurityEvents lists a user's security events, newest first
+func (r *PostgresRepository) GetSecurityEvents(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*domain.SecurityEvent, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, user_id, event, ip_address, user_agent, details, created_at
+		FROM security_events
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*domain.SecurityEvent
+	for rows.Next() {
+		var e domain.SecurityEvent
+		if err := rows.Scan(&e.ID, &e.UserID, &e.Event, &e.IPAddress, &e.UserAgent, &e.Details, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, &e)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// CountActiveStories counts unexpired stories
+func (r *PostgresRepository) CountActiveStories(ctx context.Context) (int, error) {
+	var n int
+	err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM stories WHERE expires_at > NOW() AND hidden_at IS NULL`).Scan(&n)
+	return n, err
+}
+
+// CountActiveStoriesNear counts unexpired stories within radius meters of a point
+func (r *PostgresRepository) CountActiveStoriesNear(ctx context.Context, lat, lng, radius float64) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM stories
+		WHERE expires_at > NOW() AND hidden_at IS NULL
+		AND location_lat IS NOT NULL AND location_lng IS NOT NULL
+		AND earth_box(ll_to_earth($1, $2), $3) @> ll_to_earth(location_lat, location_lng)
+		AND earth_distance(ll_to_earth($1, $2), ll_to_earth(location_lat, location_lng)) < $3
+	`
+	var n int
+	err := r.db.QueryRow(ctx, query, lat, lng, radius).Scan(&n)
+	return n, err
+}
+
+// CountWeeklyActiveUsers counts users with session activity since the given time
+func (r *PostgresRepository) CountWeeklyActiveUsers(ctx context.Context, since time.Time) (int, error) {
+	var n int
+	err := r.db.QueryRow(ctx, `SELECT COUNT(DISTINCT user_id) FROM sessions WHERE last_activity_at >= $1`, since).Scan(&n)
+	return n, err
+}
+
+// CountPostersNear counts users whose daily rollup location is within radius meters of a point since the given time
+func (r *PostgresRepository) CountPostersNear(ctx context.Context, lat, lng, radius float64, since time.Time) (int, error) {
+	query := `
+		SELECT COUNT(DISTINCT user_id)
+		FROM user_activity_daily
+		WHERE day >= ($4::timestamptz AT TIME ZONE 'UTC')::date
+		AND last_lat IS NOT NULL AND last_lng IS NOT NULL
+		AND earth_box(ll_to_earth($1, $2), $3) @> ll_to_earth(last_lat, last_lng)
+		AND earth_distance(ll_to_earth($1, $2), ll_to_earth(last_lat, last_lng)) < $3
+	`
+	var n int
+	err := r.db.QueryRow(ctx, query, lat, lng, radius, since).Scan(&n)
+	return n, err
+}
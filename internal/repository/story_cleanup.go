@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/locolive/backend/internal/config"
+	"github.com/locolive/backend/internal/domain"
+	"go.uber.org/zap"
+)
+
+// CleanupExpiredStories removes expired stories, archiving them first when enabled,
+// and purges archived stories past their retention. It returns the stories
+// removed and every step that failed.
+func (r *PostgresRepository) CleanupExpiredStories(ctx context.Context, cfg config.StoryCleanupConfig, logger *zap.Logger) (int64, error) {
+	if !cfg.ArchiveEnabled {
+		deleted, err := r.DeleteExpiredStories(ctx)
+		if err != nil {
+			logger.Error("failed to delete expired stories", zap.Error(err))
+		} else if deleted > 0 {
+			logger.Info("deleted expired stories", zap.Int64("count", deleted))
+		}
+		return deleted, err
+	}
+
+	archived, archiveErr := r.ArchiveExpiredStories(ctx)
+	if archiveErr != nil {
+		logger.Error("failed to archive expired stories", zap.Error(archiveErr))
+	} else if archived > 0 {
+		logger.Info("archived expired stories", zap.Int64("count", archived))
+	}
+
+	if cfg.ArchiveRetention <= 0 {
+		return archived, archiveErr
+	}
+	purged, purgeErr := r.PurgeStoryArchive(ctx, time.Now().Add(-cfg.ArchiveRetention))
+	if purgeErr != nil {
+		logger.Error("failed to purge story archive", zap.Error(purgeErr))
+	} else if purged > 0 {
+		logger.Info("purged story archive", zap.Int64("count", purged))
+	}
+	return archived + purged, errors.Join(archiveErr, purgeErr)
+}
+
+// StartStoryCleanupWorker runs CleanupExpiredStories on every interval
+func (r *PostgresRepository) StartStoryCleanupWorker(ctx context.Context, cfg config.StoryCleanupConfig, jobs *domain.JobTracker, logger *zap.Logger) {
+	go func() {
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				jobs.Track(ctx, domain.JobStoryCleanup, func(ctx context.Context) (int64, error) {
+					return r.CleanupExpiredStories(ctx, cfg, logger)
+				})
+			}
+		}
+	}()
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/locolive/backend/internal/domain"
+)
+
+// SetStoryReaction upserts the user's reaction. Reacting again with the same
+// emoji updates nothing, which tells it apart from a new or changed one.
+func (r *PostgresRepository) SetStoryReaction(ctx context.Context, reaction *domain.StoryReaction) (bool, error) {
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO story_reactions (story_id, user_id, emoji)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (story_id, user_id) DO UPDATE SET emoji = EXCLUDED.emoji, updated_at = NOW()
+		WHERE story_reactions.emoji <> EXCLUDED.emoji
+		RETURNING created_at, updated_at
+	`, reaction.StoryID, reaction.UserID, reaction.Emoji).Scan(&reaction.CreatedAt, &reaction.UpdatedAt)
+	if err == nil {
+		return true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return false, err
+	}
+
+	err = r.db.QueryRow(ctx, `
+		SELECT created_at, updated_at FROM story_reactions WHERE story_id = $1 AND user_id = $2
+	`, reaction.StoryID, reaction.UserID).Scan(&reaction.CreatedAt, &reaction.UpdatedAt)
+	return false, err
+}
+
+func (r *PostgresRepository) DeleteStoryReaction(ctx context.Context, storyID, userID uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM story_reactions WHERE story_id = $1 AND user_id = $2`, storyID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrReactionNotFound
+	}
+	return nil
+}
+
+// GetStoryReactions lists a story's reactions with who reacted, latest first
+func (r *PostgresRepository) GetStoryReactions(ctx context.Context, storyID uuid.UUID, limit, offset int) ([]*domain.StoryReaction, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT sr.story_id, sr.user_id, sr.emoji, sr.created_at, sr.updated_at,
+		       u.id, u.name, COALESCE(u.username, ''), COALESCE(u.avatar_url, ''), u.verified_at IS NOT NULL, u.created_at
+		FROM story_reactions sr
+		JOIN users u ON u.id = sr.user_id
+		WHERE sr.story_id = $1 AND u.is_active = TRUE
+		ORDER BY sr.updated_at DESC, sr.user_id
+		LIMIT $2 OFFSET $3
+	`, storyID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reactions []*domain.StoryReaction
+	for rows.Next() {
+		var sr domain.StoryReaction
+		var u domain.UserResponse
+		err := rows.Scan(&sr.StoryID, &sr.UserID, &sr.Emoji, &sr.CreatedAt, &sr.UpdatedAt,
+			&u.ID, &u.Name, &u.Username, &u.AvatarURL, &u.Verified, &u.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		sr.User = &u
+		reactions = append(reactions, &sr)
+	}
+	return reactions, rows.Err()
+}
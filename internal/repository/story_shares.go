@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/locolive/backend/internal/domain"
+)
+
+// GetStorySnapshot returns what a chat share of an active, visible story shows
+func (r *PostgresRepository) GetStorySnapshot(ctx context.Context, storyID uuid.UUID) (*domain.SharedStory, error) {
+	story := domain.SharedStory{Available: true}
+	err := r.db.QueryRow(ctx, `
+		SELECT s.id, s.media_url, s.media_type, s.expires_at,
+		       u.id, u.name, COALESCE(u.username, ''), COALESCE(u.avatar_url, '')
+		FROM stories s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.id = $1 AND s.expires_at > NOW() AND s.hidden_at IS NULL
+	`, storyID).Scan(&story.StoryID, &story.MediaURL, &story.MediaType, &story.ExpiresAt,
+		&story.Author.ID, &story.Author.Name, &story.Author.Username, &story.Author.AvatarURL)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrStoryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &story, nil
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/locolive/backend/internal/domain"
+)
+
+const supportGrantColumns = `id, user_id, expires_at, revoked_at, created_at`
+
+func scanSupportGrant(row pgx.Row) (*domain.SupportGrant, error) {
+	var g domain.SupportGrant
+	if err := row.Scan(&g.ID, &g.UserID, &g.ExpiresAt, &g.RevokedAt, &g.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// CreateSupportGrant revokes the user's active grants and creates a new one
+func (r *PostgresRepository) CreateSupportGrant(ctx context.Context, userID uuid.UUID, expiresAt time.Time) (*domain.SupportGrant, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE support_access_grants SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	grant, err := scanSupportGrant(tx.QueryRow(ctx, `
+		INSERT INTO support_access_grants (user_id, expires_at)
+		VALUES ($1, $2)
+		RETURNING `+supportGrantColumns, userID, expiresAt))
+	if err != nil {
+		return nil, err
+	}
+	return grant, tx.Commit(ctx)
+}
+
+// GetActiveSupportGrant returns the user's unexpired, unrevoked grant
+func (r *PostgresRepository) GetActiveSupportGrant(ctx context.Context, userID uuid.UUID) (*domain.SupportGrant, error) {
+	grant, err := scanSupportGrant(r.db.QueryRow(ctx, `
+		SELECT `+supportGrantColumns+` FROM support_access_grants
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrSupportConsentRequired
+	}
+	return grant, err
+}
+
+// RevokeSupportGrants revokes all of the user's active grants
+func (r *PostgresRepository) RevokeSupportGrants(ctx context.Context, userID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE support_access_grants SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`, userID)
+	return err
+}
+
+// IsSupportGrantActive reports whether a grant is unexpired and unrevoked
+func (r *PostgresRepository) IsSupportGrantActive(ctx context.Context, grantID uuid.UUID) (bool, error) {
+	var active bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM support_access_grants
+			WHERE id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		)
+	`, grantID).Scan(&active)
+	return active, err
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/locolive/backend/internal/metrics"
+	"go.uber.org/zap"
+)
+
+var (
+	whitespaceRegex     = regexp.MustCompile(`\s+`)
+	stringLiteralRegex  = regexp.MustCompile(`'(?:[^']|'')*'`)
+	numberLiteralRegex  = regexp.MustCompile(`(^|[^$\w])\d+(?:\.\d+)?\b`)
+	tableReferenceRegex = regexp.MustCompile(`(?i)\b(?:FROM|INTO|UPDATE|JOIN)\s+([a-z_][a-z0-9_]*)`)
+)
+
+// QueryTracer is a pgx tracer that applies a per-statement timeout and
+// logs and counts queries that exceed the slow-query threshold. Queries cut
+// short because the request was cancelled are counted separately, as they
+// aren't the database's fault.
+type QueryTracer struct {
+	timeout       time.Duration
+	slowThreshold time.Duration
+	logger        *zap.Logger
+
+	duration    *metrics.HistogramVec
+	slowQueries *metrics.CounterVec
+	timeouts    *metrics.CounterVec
+	cancelled   *metrics.CounterVec
+}
+
+type queryTraceKey struct{}
+
+type queryTrace struct {
+	sql    string
+	start  time.Time
+	cancel context.CancelFunc
+}
+
+// NewQueryTracer creates a tracer. A zero timeout disables the statement timeout.
+func NewQueryTracer(timeout, slowThreshold time.Duration, registry *metrics.Registry, logger *zap.Logger) *QueryTracer {
+	return &QueryTracer{
+		timeout:       timeout,
+		slowThreshold: slowThreshold,
+		logger:        logger,
+		duration:      registry.Histogram("db_query_duration_seconds", "Database query latency", nil, "operation", "table"),
+		slowQueries:   registry.Counter("db_slow_queries_total", "Queries slower than the slow-query threshold", "operation", "table"),
+		timeouts:      registry.Counter("db_query_timeouts_total", "Queries cancelled by the statement timeout", "operation", "table"),
+		cancelled:     registry.Counter("db_queries_cancelled_total", "Queries abandoned because their request was cancelled", "operation", "table"),
+	}
+}
+
+// TraceQueryStart implements pgx.QueryTracer
+func (t *QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	trace := &queryTrace{sql: data.SQL, start: time.Now()}
+
+	// Only tighten the deadline; callers with a shorter one keep theirs
+	if t.timeout > 0 {
+		if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) > t.timeout {
+			ctx, trace.cancel = context.WithTimeout(ctx, t.timeout)
+		}
+	}
+
+	return context.WithValue(ctx, queryTraceKey{}, trace)
+}
+
+// TraceQueryEnd implements pgx.QueryTracer
+func (t *QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	trace, ok := ctx.Value(queryTraceKey{}).(*queryTrace)
+	if !ok {
+		return
+	}
+	if trace.cancel != nil {
+		defer trace.cancel()
+	}
+
+	elapsed := time.Since(trace.start)
+	operation, table := describeQuery(trace.sql)
+	t.duration.Observe(elapsed.Seconds(), operation, table)
+
+	if data.Err != nil {
+		switch {
+		case errors.Is(ctx.Err(), context.DeadlineExceeded), ctx.Err() == nil && isQueryCanceled(data.Err):
+			// The client-side timeout, or Postgres' own statement_timeout
+			t.timeouts.Inc(operation, table)
+			t.logger.Error("query timed out",
+				zap.String("sql", NormalizeSQL(trace.sql)),
+				zap.Duration("duration", elapsed),
+			)
+			return
+		case errors.Is(ctx.Err(), context.Canceled):
+			t.cancelled.Inc(operation, table)
+			t.logger.Debug("query cancelled",
+				zap.String("sql", NormalizeSQL(trace.sql)),
+				zap.Duration("duration", elapsed),
+			)
+			return
+		}
+	}
+
+	if t.slowThreshold > 0 && elapsed >= t.slowThreshold {
+		t.slowQueries.Inc(operation, table)
+		t.logger.Warn("slow query",
+			zap.String("sql", NormalizeSQL(trace.sql)),
+			zap.Duration("duration", elapsed),
+			zap.Duration("threshold", t.slowThreshold),
+		)
+	}
+}
+
+// isQueryCanceled reports whether Postgres cancelled the statement
+func isQueryCanceled(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == queryCanceled
+}
+
+// NormalizeSQL collapses whitespace and replaces literals with placeholders so
+// that logged statements group together and never leak inline values
+func NormalizeSQL(sql string) string {
+	sql = stringLiteralRegex.ReplaceAllString(sql, "?")
+	sql = numberLiteralRegex.ReplaceAllString(sql, "${1}?")
+	sql = whitespaceRegex.ReplaceAllString(sql, " ")
+	return strings.TrimSpace(sql)
+}
+
+// describeQuery returns the statement verb and first referenced table for metric labels
+func describeQuery(sql string) (string, string) {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "unknown", "unknown"
+	}
+	operation := strings.ToUpper(fields[0])
+
+	table := "unknown"
+	if m := tableReferenceRegex.FindStringSubmatch(sql); m != nil {
+		table = strings.ToLower(m[1])
+	}
+	return operation, table
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"github.com/locolive/backend/internal/domain"
+)
+
+// likeEscaper escapes LIKE wildcards so a query matches literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchUsers matches names and handles by substring or trigram similarity and
+// bios by word. Non-public users only show up for accepted connections, and
+// blocks either way hide the user. Exact handles rank first, then similarity,
+// with verified users lifted a little over near-equal matches.
+func (r *PostgresRepository) SearchUsers(ctx context.Context, params domain.UserSearchParams) ([]*domain.User, error) {
+	var country *string
+	if params.CountryCode != "" {
+		country = &params.CountryCode
+	}
+	query := `
+		SELECT u.id, u.email, u.phone, u.name, u.username, u.avatar_url, u.bio, u.gender, u.date_of_birth, u.visibility, u.country_code, u.google_id, u.email_verified, u.phone_verified, u.is_active, u.verified_at, u.created_at, u.updated_at
+		FROM users u
+		WHERE u.is_active = TRUE AND u.id <> $1
+		AND (
+			u.name ILIKE '%' || $3::text || '%' OR u.username ILIKE '%' || $3::text || '%'
+			OR u.name % $2::text OR u.username % $2
+			OR to_tsvector('simple', COALESCE(u.bio, '')) @@ plainto_tsquery('simple', $2)
+		)
+		AND ($4::text IS NULL OR u.country_code = $4)
+		AND (u.visibility = 'public' OR EXISTS (
+			SELECT 1 FROM connections c
+			WHERE c.status = 'accepted'
+			AND ((c.requester_id = $1 AND c.receiver_id = u.id) OR (c.receiver_id = $1 AND c.requester_id = u.id))
+		))
+		AND NOT EXISTS (
+			SELECT 1 FROM connections c
+			WHERE c.status = 'blocked'
+			AND ((c.requester_id = $1 AND c.receiver_id = u.id) OR (c.receiver_id = $1 AND c.requester_id = u.id))
+		)
+		ORDER BY (u.username = lower($2)) IS TRUE DESC,
+			GREATEST(similarity(u.name, $2), similarity(COALESCE(u.username, ''), $2))
+				+ CASE WHEN u.verified_at IS NOT NULL THEN 0.2 ELSE 0 END DESC,
+			u.created_at DESC, u.id
+		LIMIT $5 OFFSET $6
+	`
+	rows, err := r.db.Query(ctx, query, params.ViewerID, params.Query, likeEscaper.Replace(params.Query), country, params.Limit, params.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*domain.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/domain"
+)
+
+// userTimelineQuery merges the user's rows from each source, newest first.
+// Every branch is cut at $3, the offset plus the page size, so no source is
+// read further than the page needs.
+const userTimelineQuery = `
+	SELECT entry_type, id, occurred_at, details FROM (
+		(SELECT 'security' AS entry_type, id, created_at AS occurred_at,
+			jsonb_build_object('event', event, 'ip_address', ip_address, 'user_agent', user_agent) AS details
+		FROM security_events WHERE user_id = $1
+		ORDER BY created_at DESC LIMIT $3)
+		UNION ALL
+		(SELECT 'login_failed', id, created_at, jsonb_build_object('ip_address', ip_address)
+		FROM login_attempts WHERE user_id = $1 AND NOT succeeded
+		ORDER BY created_at DESC LIMIT $3)
+		UNION ALL
+		(SELECT 'story', id, created_at,
+			jsonb_build_object('media_type', media_type, 'archived', archived, 'lat', location_lat, 'lng', location_lng)
+		FROM (
+			(SELECT id, media_type, location_lat, location_lng, created_at, FALSE AS archived
+			FROM stories WHERE user_id = $1 ORDER BY created_at DESC LIMIT $3)
+			UNION ALL
+			(SELECT id, media_type, location_lat, location_lng, created_at, TRUE
+			FROM story_archive WHERE user_id = $1 ORDER BY created_at DESC LIMIT $3)
+		) s
+		ORDER BY created_at DESC LIMIT $3)
+		UNION ALL
+		(SELECT 'message', id, created_at, jsonb_build_object('chat_id', chat_id, 'shared_story_id', shared_story_id)
+		FROM messages WHERE sender_id = $1
+		ORDER BY created_at DESC LIMIT $3)
+		UNION ALL
+		(SELECT 'comment', id, created_at, jsonb_build_object('story_id', story_id, 'hidden', hidden_at IS NOT NULL)
+		FROM story_comments WHERE user_id = $1
+		ORDER BY created_at DESC LIMIT $3)
+		UNION ALL
+		(SELECT 'report_filed', id, created_at,
+			jsonb_build_object('target_type', target_type, 'target_id', target_id, 'reason', reason)
+		FROM reports WHERE reporter_id = $1
+		ORDER BY created_at DESC LIMIT $3)
+		UNION ALL
+		(SELECT 'report_received', r.id, r.created_at,
+			jsonb_build_object('target_type', r.target_type, 'target_id', r.target_id, 'reason', r.reason, 'reporter_id', r.reporter_id)
+		FROM reports r
+		WHERE (r.target_type = 'story' AND r.target_id IN (
+				SELECT id FROM stories WHERE user_id = $1
+				UNION ALL
+				SELECT id FROM story_archive WHERE user_id = $1
+			))
+			OR (r.target_type = 'comment' AND r.target_id IN (SELECT id FROM story_comments WHERE user_id = $1))
+		ORDER BY r.created_at DESC LIMIT $3)
+		UNION ALL
+		(SELECT 'takedown', id, created_at,
+			jsonb_build_object('target_type', target_type, 'target_id', target_id, 'report_count', report_count, 'status', status, 'reviewed_by', reviewed_by)
+		FROM moderation_actions WHERE owner_id = $1
+		ORDER BY created_at DESC LIMIT $3)
+		UNION ALL
+		(SELECT 'strike', id, created_at, jsonb_build_object('kind', kind, 'claim_id', claim_id, 'revoked_at', revoked_at)
+		FROM user_strikes WHERE user_id = $1
+		ORDER BY created_at DESC LIMIT $3)
+		UNION ALL
+		(SELECT 'admin_action', id, created_at, jsonb_build_object('action', action, 'actor_id', actor_id, 'details', details)
+		FROM admin_audit_log WHERE target_user_id = $1 AND action = ANY($2)
+		ORDER BY created_at DESC LIMIT $3)
+	) timeline
+	ORDER BY occurred_at DESC, id DESC
+	LIMIT $4 OFFSET $5
+`
+
+// GetUserTimeline lists the user's activity and enforcement history, newest first
+func (r *PostgresRepository) GetUserTimeline(ctx context.Context, userID uuid.UUID, adminActions []domain.AuditAction, limit, offset int) ([]*domain.TimelineEntry, error) {
+	actions := make([]string, len(adminActions))
+	for i, action := range adminActions {
+		actions[i] = string(action)
+	}
+
+	rows, err := r.db.Query(ctx, userTimelineQuery, userID, actions, limit+offset, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*domain.TimelineEntry
+	for rows.Next() {
+		var e domain.TimelineEntry
+		if err := rows.Scan(&e.Type, &e.ID, &e.At, &e.Details); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/locolive/backend/internal/domain"
+)
+
+const verificationRequestColumns = `id, user_id, category, details, status, review_note, reviewed_by, reviewed_at, created_at`
+
+func scanVerificationRequest(row pgx.Row) (*domain.VerificationRequest, error) {
+	var v domain.VerificationRequest
+	err := row.Scan(&v.ID, &v.UserID, &v.Category, &v.Details, &v.Status, &v.ReviewNote, &v.ReviewedBy, &v.ReviewedAt, &v.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// CreateVerificationRequest records a request; the partial unique index
+// allows one pending request per user
+func (r *PostgresRepository) CreateVerificationRequest(ctx context.Context, req *domain.VerificationRequest) error {
+	created, err := scanVerificationRequest(r.db.QueryRow(ctx, `
+		INSERT INTO verification_requests (user_id, category, details)
+		VALUES ($1, $2, $3)
+		RETURNING `+verificationRequestColumns,
+		req.UserID, req.Category, req.Details,
+	))
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return domain.ErrVerificationRequestPending
+	}
+	if err != nil {
+		return err
+	}
+	*req = *created
+	return nil
+}
+
+// GetLatestVerificationRequest returns the user's most recent request
+func (r *PostgresRepository) GetLatestVerificationRequest(ctx context.Context, userID uuid.UUID) (*domain.VerificationRequest, error) {
+	req, err := scanVerificationRequest(r.db.QueryRow(ctx, `
+		SELECT `+verificationRequestColumns+` FROM verification_requests
+		WHERE user_id = $1 ORDER BY created_at DESC LIMIT 1
+	`, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrVerificationRequestNotFound
+	}
+	return req, err
+}
+
+// GetVerificationRequests lists requests with the given status, oldest first
+func (r *PostgresRepository) GetVerificationRequests(ctx context.Context, status domain.VerificationStatus, limit, offset int) ([]*domain.VerificationRequest, error) {
+	query := `SELECT ` + verificationRequestColumns + ` FROM verification_requests WHERE status = $1 ORDER BY created_at LIMIT $2 OFFSET $3`
+	rows, err := r.db.Query(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var requests []*domain.VerificationRequest
+	for rows.Next() {
+		v, err := scanVerificationRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, v)
+	}
+	return requests, rows.Err()
+}
+
+// ResolveVerificationRequest reviews a pending request. Approving sets the
+// user's badge. The audit entry targets the applicant.
+func (r *PostgresRepository) ResolveVerificationRequest(ctx context.Context, requestID, reviewerID uuid.UUID, status domain.VerificationStatus, note *string, audit domain.AuditEntry) (*domain.VerificationRequest, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	req, err := scanVerificationRequest(tx.QueryRow(ctx, `
+		UPDATE verification_requests
+		SET status = $2, review_note = $3, reviewed_by = $4, reviewed_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING `+verificationRequestColumns,
+		requestID, status, note, reviewerID,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		var exists bool
+		if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM verification_requests WHERE id = $1)`, requestID).Scan(&exists); err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, domain.ErrVerificationRequestResolved
+		}
+		return nil, domain.ErrVerificationRequestNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if status == domain.VerificationApproved {
+		_, err := tx.Exec(ctx, `
+			UPDATE users SET verified_at = NOW(), updated_at = NOW()
+			WHERE id = $1 AND verified_at IS NULL
+		`, req.UserID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	audit.TargetUserID = &req.UserID
+	if err := insertAudit(ctx, tx, audit); err != nil {
+		return nil, err
+	}
+	return req, tx.Commit(ctx)
+}
+
+// RevokeVerification clears a user's badge and records the audit entry in the same transaction
+func (r *PostgresRepository) RevokeVerification(ctx context.Context, userID uuid.UUID, audit domain.AuditEntry) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE users SET verified_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND verified_at IS NOT NULL
+	`, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		var exists bool
+		if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)`, userID).Scan(&exists); err != nil {
+			return err
+		}
+		if exists {
+			return domain.ErrNotVerified
+		}
+		return domain.ErrUserNotFound
+	}
+
+	if err := insertAudit(ctx, tx, audit); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// IsUserVerified reports whether a user has the verified badge
+func (r *PostgresRepository) IsUserVerified(ctx context.Context, userID uuid.UUID) (bool, error) {
+	var verified bool
+	err := r.db.QueryRow(ctx, `SELECT verified_at IS NOT NULL FROM users WHERE id = $1`, userID).Scan(&verified)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, domain.ErrUserNotFound
+	}
+	return verified, err
+}
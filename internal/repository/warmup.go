@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/locolive/backend/internal/domain"
+)
+
+// Every authenticated request looks up its session and user, so these are
+// prepared on each pooled connection before the instance takes traffic
+const (
+	getUserByIDQuery = `
+		SELECT id, email, phone, name, username, avatar_url, bio, gender, date_of_birth, visibility, country_code, google_id, email_verified, phone_verified, is_active, verified_at, created_at, updated_at
+		FROM users WHERE id = $1 AND is_active = TRUE
+	`
+	getSessionByIDQuery = `
+		SELECT id, user_id, device_info, ip_address, user_agent, region, platform, app_version, is_active, created_at, expires_at, last_activity_at
+		FROM sessions WHERE id = $1 AND is_active = TRUE
+	`
+)
+
+var hotStatements = []struct {
+	name string
+	sql  string
+}{
+	{"user by id", getUserByIDQuery},
+	{"session by id", getSessionByIDQuery},
+}
+
+// PrepareHotStatements runs the hot lookups with a nil ID on every idle pooled
+// connection, which prepares and caches them through the connection's
+// statement cache. A failure means the queries don't match the schema.
+func (r *PostgresRepository) PrepareHotStatements(ctx context.Context) error {
+	conns := r.db.AcquireAllIdle(ctx)
+	defer func() {
+		for _, conn := range conns {
+			conn.Release()
+		}
+	}()
+
+	for _, conn := range conns {
+		for _, stmt := range hotStatements {
+			if _, err := conn.Exec(ctx, stmt.sql, uuid.Nil); err != nil {
+				return fmt.Errorf("preparing %s: %w", stmt.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// GetBusiestStoryAreas returns the tenth-of-a-degree cells (about 11km) with
+// the most active stories, busiest first
+func (r *PostgresRepository) GetBusiestStoryAreas(ctx context.Context, limit int) ([]domain.StoryArea, error) {
+	query := `
+		SELECT round(location_lat::numeric, 1)::float8, round(location_lng::numeric, 1)::float8, COUNT(*)
+		FROM stories
+		WHERE expires_at > NOW() AND hidden_at IS NULL
+		AND location_lat IS NOT NULL AND location_lng IS NOT NULL
+		GROUP BY 1, 2
+		ORDER BY 3 DESC
+		LIMIT $1
+	`
+	rows, err := r.db.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var areas []domain.StoryArea
+	for rows.Next() {
+		var a domain.StoryArea
+		if err := rows.Scan(&a.Lat, &a.Lng, &a.Stories); err != nil {
+			return nil, err
+		}
+		areas = append(areas, a)
+	}
+	return areas, rows.Err()
+}
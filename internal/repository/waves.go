@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/locolive/backend/internal/domain"
+)
+
+// CreateWave records a wave, one per sender, receiver and UTC day
+func (r *PostgresRepository) CreateWave(ctx context.Context, senderID, receiverID uuid.UUID) (*domain.Wave, error) {
+	query := `
+		INSERT INTO waves (sender_id, receiver_id)
+		VALUES ($1, $2)
+		ON CONFLICT (sender_id, receiver_id, day) DO NOTHING
+		RETURNING id, sender_id, receiver_id, created_at
+	`
+	var w domain.Wave
+	err := r.db.QueryRow(ctx, query, senderID, receiverID).Scan(&w.ID, &w.SenderID, &w.ReceiverID, &w.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrWaveTooSoon
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// HasWaved reports whether senderID waved at receiverID since the given time
+func (r *PostgresRepository) HasWaved(ctx context.Context, senderID, receiverID uuid.UUID, since time.Time) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM waves WHERE sender_id = $1 AND receiver_id = $2 AND created_at >= $3)`
+	var waved bool
+	err := r.db.QueryRow(ctx, query, senderID, receiverID, since).Scan(&waved)
+	return waved, err
+}
+
+// GetReceivedWaves lists waves to the user since the given time, newest first.
+// Waves from deactivated senders and from either side of a block are left out.
+func (r *PostgresRepository) GetReceivedWaves(ctx context.Context, userID uuid.UUID, since time.Time, limit, offset int) ([]*domain.Wave, error) {
+	query := `
+		SELECT w.id, w.sender_id, w.receiver_id, w.created_at,
+		       u.id, u.name, COALESCE(u.username, ''), COALESCE(u.avatar_url, ''), u.verified_at IS NOT NULL, u.created_at
+		FROM waves w
+		JOIN users u ON u.id = w.sender_id
+		WHERE w.receiver_id = $1 AND w.created_at >= $2 AND u.is_active = TRUE
+		AND NOT EXISTS (
+			SELECT 1 FROM connections c
+			WHERE c.status = 'blocked'
+			AND ((c.requester_id = $1 AND c.receiver_id = u.id) OR (c.receiver_id = $1 AND c.requester_id = u.id))
+		)
+		ORDER BY w.created_at DESC
+		LIMIT $3 OFFSET $4
+	`
+	rows, err := r.db.Query(ctx, query, userID, since, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var waves []*domain.Wave
+	for rows.Next() {
+		var w domain.Wave
+		var u domain.UserResponse
+		if err := rows.Scan(&w.ID, &w.SenderID, &w.ReceiverID, &w.CreatedAt, &u.ID, &u.Name, &u.Username, &u.AvatarURL, &u.Verified, &u.CreatedAt); err != nil {
+			return nil, err
+		}
+		w.User = &u
+		waves = append(waves, &w)
+	}
+	return waves, rows.Err()
+}
@@ -0,0 +1,109 @@
+package slo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/locolive/backend/internal/config"
+)
+
+const pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// Alerter delivers an alert for a route group whose error budget is burning
+type Alerter interface {
+	Alert(ctx context.Context, status Status) error
+}
+
+// NewAlerter builds an alerter from config, or returns nil if no destination is configured
+func NewAlerter(cfg config.SLOConfig) Alerter {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	var alerters multiAlerter
+	if cfg.AlertWebhookURL != "" {
+		alerters = append(alerters, &WebhookAlerter{url: cfg.AlertWebhookURL, client: client})
+	}
+	if cfg.PagerDutyRoutingKey != "" {
+		alerters = append(alerters, &PagerDutyAlerter{routingKey: cfg.PagerDutyRoutingKey, client: client})
+	}
+
+	if len(alerters) == 0 {
+		return nil
+	}
+	return alerters
+}
+
+type multiAlerter []Alerter
+
+func (m multiAlerter) Alert(ctx context.Context, status Status) error {
+	var errs []error
+	for _, a := range m {
+		if err := a.Alert(ctx, status); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WebhookAlerter posts the SLO status as JSON to a generic webhook
+type WebhookAlerter struct {
+	url    string
+	client *http.Client
+}
+
+// Alert implements Alerter
+func (a *WebhookAlerter) Alert(ctx context.Context, status Status) error {
+	return postJSON(ctx, a.client, a.url, map[string]interface{}{
+		"event":  "slo_burn",
+		"status": status,
+	})
+}
+
+// PagerDutyAlerter triggers an incident through the PagerDuty Events API v2
+type PagerDutyAlerter struct {
+	routingKey string
+	client     *http.Client
+}
+
+// Alert implements Alerter
+func (a *PagerDutyAlerter) Alert(ctx context.Context, status Status) error {
+	return postJSON(ctx, a.client, pagerDutyEventsURL, map[string]interface{}{
+		"routing_key":  a.routingKey,
+		"event_action": "trigger",
+		"dedup_key":    "locolive-slo-" + status.Group,
+		"payload": map[string]interface{}{
+			"summary":        fmt.Sprintf("LocoLive %s SLO burning at %.1fx budget", status.Group, status.BurnRate),
+			"source":         "locolive-api",
+			"severity":       "critical",
+			"custom_details": status,
+		},
+	})
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, body interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("alert endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
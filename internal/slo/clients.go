@@ -0,0 +1,113 @@
+package slo
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	// maxClientSeries bounds how many builds are tracked; later ones are
+	// pooled per platform as "other"
+	maxClientSeries = 200
+	// elevatedMinRequests keeps builds with too few requests from being flagged
+	elevatedMinRequests = 20
+	// elevatedFactor is how many times the overall error rate flags a build
+	elevatedFactor = 2
+)
+
+type clientSeries struct {
+	platform   string
+	appVersion string
+	buckets    [bucketsPerWindow]bucket
+}
+
+// ClientStatus is one client build's server error rate over the window
+type ClientStatus struct {
+	Platform   string  `json:"platform"`
+	AppVersion string  `json:"app_version"`
+	Requests   uint64  `json:"requests"`
+	Errors     uint64  `json:"errors"`
+	ErrorRate  float64 `json:"error_rate"`
+	// Elevated flags builds erroring well above the overall rate, the usual
+	// sign of a bad release
+	Elevated bool `json:"elevated"`
+}
+
+// ClientBreakdown is the error rate per client build, busiest first
+type ClientBreakdown struct {
+	Window    string         `json:"window"`
+	Requests  uint64         `json:"requests"`
+	Errors    uint64         `json:"errors"`
+	ErrorRate float64        `json:"error_rate"`
+	Clients   []ClientStatus `json:"clients"`
+}
+
+// ObserveClient records a request outcome for a client build (implements
+// middleware.ClientObserver). Only 5xx responses count as errors.
+func (t *Tracker) ObserveClient(platform, appVersion string, status int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := platform + "/" + appVersion
+	s, ok := t.clients[key]
+	if !ok {
+		if len(t.clients) >= maxClientSeries {
+			appVersion = "other"
+			key = platform + "/" + appVersion
+		}
+		if s, ok = t.clients[key]; !ok {
+			s = &clientSeries{platform: platform, appVersion: appVersion}
+			t.clients[key] = s
+		}
+	}
+
+	slot := time.Now().UnixNano() / int64(t.bucketSize)
+	b := &s.buckets[slot%bucketsPerWindow]
+	if b.start != slot {
+		*b = bucket{start: slot}
+	}
+
+	b.total++
+	if status >= 500 {
+		b.bad++
+	}
+}
+
+// ClientBreakdown returns the error rate of every build seen in the window
+func (t *Tracker) ClientBreakdown() ClientBreakdown {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now().UnixNano() / int64(t.bucketSize)
+	breakdown := ClientBreakdown{Window: t.window.String(), Clients: []ClientStatus{}}
+	for _, s := range t.clients {
+		st := ClientStatus{Platform: s.platform, AppVersion: s.appVersion}
+		for _, b := range s.buckets {
+			if age := now - b.start; age < 0 || age >= bucketsPerWindow {
+				continue
+			}
+			st.Requests += b.total
+			st.Errors += b.bad
+		}
+		if st.Requests == 0 {
+			continue
+		}
+		st.ErrorRate = float64(st.Errors) / float64(st.Requests)
+		breakdown.Requests += st.Requests
+		breakdown.Errors += st.Errors
+		breakdown.Clients = append(breakdown.Clients, st)
+	}
+
+	if breakdown.Requests > 0 {
+		breakdown.ErrorRate = float64(breakdown.Errors) / float64(breakdown.Requests)
+	}
+	for i := range breakdown.Clients {
+		c := &breakdown.Clients[i]
+		c.Elevated = c.Errors > 0 && c.Requests >= elevatedMinRequests && c.ErrorRate > elevatedFactor*breakdown.ErrorRate
+	}
+
+	sort.Slice(breakdown.Clients, func(i, j int) bool {
+		return breakdown.Clients[i].Requests > breakdown.Clients[j].Requests
+	})
+	return breakdown
+}
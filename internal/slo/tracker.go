@@ -0,0 +1,207 @@
+package slo
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/locolive/backend/internal/config"
+	"go.uber.org/zap"
+)
+
+// bucketsPerWindow controls the resolution of the sliding window
+const bucketsPerWindow = 60
+
+// shortWindowBuckets is the size of the fast-burn window (1/12 of the long window,
+// e.g. 5m for a 1h window), used to confirm the budget is still burning right now
+const shortWindowBuckets = 5
+
+// Status is a point-in-time view of a route group's SLO
+type Status struct {
+	Group                string  `json:"group"`
+	Objective            float64 `json:"objective"`
+	LatencyThresholdMs   int64   `json:"latency_threshold_ms"`
+	Window               string  `json:"window"`
+	Requests             uint64  `json:"requests"`
+	BadRequests          uint64  `json:"bad_requests"`
+	ErrorRate            float64 `json:"error_rate"`
+	ErrorBudgetRemaining float64 `json:"error_budget_remaining"`
+	BurnRate             float64 `json:"burn_rate"`
+	ShortBurnRate        float64 `json:"short_burn_rate"`
+	Burning              bool    `json:"burning"`
+}
+
+type bucket struct {
+	start int64
+	total uint64
+	bad   uint64
+}
+
+type series struct {
+	target  config.SLOTarget
+	buckets [bucketsPerWindow]bucket
+}
+
+// Tracker computes error budget burn rates per route group from request outcomes
+type Tracker struct {
+	window        time.Duration
+	bucketSize    time.Duration
+	burnThreshold float64
+	alerter       Alerter
+	logger        *zap.Logger
+
+	mu        sync.Mutex
+	series    map[string]*series
+	clients   map[string]*clientSeries
+	lastAlert map[string]time.Time
+}
+
+// NewTracker creates a new SLO tracker. alerter may be nil to disable alerting.
+func NewTracker(cfg config.SLOConfig, alerter Alerter, logger *zap.Logger) *Tracker {
+	bucketSize := cfg.Window / bucketsPerWindow
+	if bucketSize < time.Second {
+		bucketSize = time.Second
+	}
+
+	t := &Tracker{
+		window:        cfg.Window,
+		bucketSize:    bucketSize,
+		burnThreshold: cfg.BurnRateThreshold,
+		alerter:       alerter,
+		logger:        logger,
+		series:        make(map[string]*series),
+		clients:       make(map[string]*clientSeries),
+		lastAlert:     make(map[string]time.Time),
+	}
+	for _, target := range cfg.Targets {
+		t.series[target.Group] = &series{target: target}
+	}
+	return t
+}
+
+// ObserveRequest records a request outcome (implements middleware.RequestObserver)
+func (t *Tracker) ObserveRequest(group string, status int, duration time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.series[group]
+	if !ok {
+		return
+	}
+
+	slot := time.Now().UnixNano() / int64(t.bucketSize)
+	b := &s.buckets[slot%bucketsPerWindow]
+	if b.start != slot {
+		*b = bucket{start: slot}
+	}
+
+	b.total++
+	if status >= 500 || duration > s.target.LatencyThreshold {
+		b.bad++
+	}
+}
+
+// Statuses returns the current SLO status for every configured group
+func (t *Tracker) Statuses() []Status {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now().UnixNano() / int64(t.bucketSize)
+	statuses := make([]Status, 0, len(t.series))
+	for group, s := range t.series {
+		statuses = append(statuses, t.status(group, s, now))
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Group < statuses[j].Group })
+	return statuses
+}
+
+func (t *Tracker) status(group string, s *series, now int64) Status {
+	var total, bad, shortTotal, shortBad uint64
+	for _, b := range s.buckets {
+		age := now - b.start
+		if age < 0 || age >= bucketsPerWindow {
+			continue
+		}
+		total += b.total
+		bad += b.bad
+		if age < shortWindowBuckets {
+			shortTotal += b.total
+			shortBad += b.bad
+		}
+	}
+
+	allowed := 1 - s.target.Objective
+	st := Status{
+		Group:                group,
+		Objective:            s.target.Objective,
+		LatencyThresholdMs:   s.target.LatencyThreshold.Milliseconds(),
+		Window:               t.window.String(),
+		Requests:             total,
+		BadRequests:          bad,
+		ErrorBudgetRemaining: 1,
+	}
+
+	if total > 0 {
+		st.ErrorRate = float64(bad) / float64(total)
+		st.BurnRate = st.ErrorRate / allowed
+		st.ErrorBudgetRemaining = 1 - st.BurnRate
+	}
+	if shortTotal > 0 {
+		st.ShortBurnRate = (float64(shortBad) / float64(shortTotal)) / allowed
+	}
+
+	// Multi-window check: only flag when both the long and short windows burn fast,
+	// so a single slow spike that has already recovered doesn't page anyone
+	st.Burning = st.BurnRate >= t.burnThreshold && st.ShortBurnRate >= t.burnThreshold
+	return st
+}
+
+// Run periodically evaluates burn rates and fires alerts until ctx is cancelled
+func (t *Tracker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.evaluate(ctx)
+		}
+	}
+}
+
+func (t *Tracker) evaluate(ctx context.Context) {
+	for _, st := range t.Statuses() {
+		if !st.Burning {
+			continue
+		}
+
+		t.logger.Warn("SLO error budget burning",
+			zap.String("group", st.Group),
+			zap.Float64("burn_rate", st.BurnRate),
+			zap.Float64("short_burn_rate", st.ShortBurnRate),
+		)
+
+		if t.alerter == nil || !t.shouldAlert(st.Group) {
+			continue
+		}
+		if err := t.alerter.Alert(ctx, st); err != nil {
+			t.logger.Error("failed to send SLO alert", zap.String("group", st.Group), zap.Error(err))
+		}
+	}
+}
+
+// shouldAlert rate-limits alerts to one per group per window
+func (t *Tracker) shouldAlert(group string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if last, ok := t.lastAlert[group]; ok && time.Since(last) < t.window {
+		return false
+	}
+	t.lastAlert[group] = time.Now()
+	return true
+}
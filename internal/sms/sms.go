@@ -0,0 +1,81 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Provider delivers text messages to E.164 phone numbers
+type Provider interface {
+	Send(ctx context.Context, to, body string) error
+}
+
+// LogProvider logs messages instead of sending them. For development only.
+type LogProvider struct {
+	logger *zap.Logger
+}
+
+// NewLogProvider creates a provider that writes messages to the log
+func NewLogProvider(logger *zap.Logger) *LogProvider {
+	return &LogProvider{logger: logger}
+}
+
+// Send logs the message
+func (p *LogProvider) Send(ctx context.Context, to, body string) error {
+	p.logger.Info("sms not sent (log provider)", zap.String("to", to), zap.String("body", body))
+	return nil
+}
+
+// TwilioProvider sends messages through the Twilio Messages API
+type TwilioProvider struct {
+	accountSID string
+	authToken  string
+	from       string
+	client     *http.Client
+}
+
+// NewTwilioProvider creates a Twilio provider sending from the given number
+func NewTwilioProvider(accountSID, authToken, from string) *TwilioProvider {
+	return &TwilioProvider{
+		accountSID: accountSID,
+		authToken:  authToken,
+		from:       from,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Send delivers one message
+func (p *TwilioProvider) Send(ctx context.Context, to, body string) error {
+	endpoint := "https://api.twilio.com/2010-04-01/Accounts/" + url.PathEscape(p.accountSID) + "/Messages.json"
+	form := url.Values{
+		"To":   {to},
+		"From": {p.from},
+		"Body": {body},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(p.accountSID, p.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("twilio returned %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"path"
+	"regexp"
+)
+
+// ImmutableCacheControl is sent with stored media. Every upload gets a new
+// random name and is never rewritten, so its URL can be cached for good.
+const ImmutableCacheControl = "public, max-age=31536000, immutable"
+
+// storedName matches the names SaveFile gives uploads: a UUID, prefixed
+// with the upload date on local disk, and the original extension
+var storedName = regexp.MustCompile(`^(\d{8}_)?[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}(\.[A-Za-z0-9+-]+)?$`)
+
+// IsImmutable reports whether a file path or URL names an upload from
+// SaveFile, whose content never changes
+func IsImmutable(name string) bool {
+	return storedName.MatchString(path.Base(name))
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// MaxMirrorSize caps the size of remote images copied into storage
+const MaxMirrorSize = 5 << 20 // 5MB
+
+var (
+	ErrMirrorNotImage = errors.New("remote file is not an image")
+	ErrMirrorTooLarge = errors.New("remote file is too large")
+)
+
+var mirrorClient = &http.Client{Timeout: 10 * time.Second}
+
+// MirrorImage downloads the image at sourceURL and saves it to store, returning
+// the stored file's public URL
+func MirrorImage(ctx context.Context, store FileStorage, sourceURL, filename string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := mirrorClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch remote file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch remote file: status %d", resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if !strings.HasPrefix(contentType, "image/") {
+		return "", ErrMirrorNotImage
+	}
+	// Drop parameters such as "; charset=..." so extension guessing works
+	contentType = strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])
+
+	// Buffer so a truncated or oversized download never reaches storage
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, io.LimitReader(resp.Body, MaxMirrorSize+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read remote file: %w", err)
+	}
+	if n > MaxMirrorSize {
+		return "", ErrMirrorTooLarge
+	}
+
+	return store.SaveFile(ctx, &buf, filename, contentType)
+}
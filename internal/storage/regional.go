@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// RegionBucket is a store pinned to one region and the countries it serves
+type RegionBucket struct {
+	Name      string
+	Store     FileStorage
+	PublicURL string
+	Countries []string
+}
+
+// RegionalStorage saves uploads to the bucket nearest the caller, falling
+// back to a default store for countries no region claims
+type RegionalStorage struct {
+	fallback  FileStorage
+	buckets   []RegionBucket
+	byCountry map[string]*RegionBucket
+	regionOf  func(ctx context.Context) string
+}
+
+// NewRegionalStorage creates a regional store. regionOf returns the caller's
+// country code from the request context, or "" if it's unknown.
+func NewRegionalStorage(fallback FileStorage, buckets []RegionBucket, regionOf func(ctx context.Context) string) *RegionalStorage {
+	s := &RegionalStorage{
+		fallback:  fallback,
+		buckets:   buckets,
+		byCountry: make(map[string]*RegionBucket),
+		regionOf:  regionOf,
+	}
+	for i := range s.buckets {
+		for _, country := range s.buckets[i].Countries {
+			s.byCountry[country] = &s.buckets[i]
+		}
+	}
+	return s
+}
+
+// SaveFile saves a file to the caller's regional bucket
+func (s *RegionalStorage) SaveFile(ctx context.Context, file io.Reader, filename string, contentType string) (string, error) {
+	return s.forContext(ctx).SaveFile(ctx, file, filename, contentType)
+}
+
+// DeleteFile deletes a file from whichever bucket its URL points at, which
+// needn't be the caller's current region
+func (s *RegionalStorage) DeleteFile(ctx context.Context, fileURL string) error {
+	return s.forURL(fileURL).DeleteFile(ctx, fileURL)
+}
+
+// SignedURL signs a URL to a file in whichever bucket its URL points at
+func (s *RegionalStorage) SignedURL(ctx context.Context, fileURL string, ttl time.Duration) (string, error) {
+	store := s.forURL(fileURL)
+	signer, ok := store.(URLSigner)
+	if !ok {
+		return "", errors.New("storage can't sign URLs")
+	}
+	return signer.SignedURL(ctx, fileURL, ttl)
+}
+
+func (s *RegionalStorage) forURL(fileURL string) FileStorage {
+	for _, bucket := range s.buckets {
+		if strings.HasPrefix(fileURL, bucket.PublicURL+"/") {
+			return bucket.Store
+		}
+	}
+	return s.fallback
+}
+
+func (s *RegionalStorage) forContext(ctx context.Context) FileStorage {
+	if bucket, ok := s.byCountry[s.regionOf(ctx)]; ok {
+		return bucket.Store
+	}
+	return s.fallback
+}
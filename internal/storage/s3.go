@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -43,7 +45,7 @@ func NewS3Storage(ctx context.Context, cfg internalConfig.StorageConfig) (*S3Sto
 	return &S3Storage{
 		client:    client,
 		bucket:    cfg.Bucket,
-		publicURL: cfg.PublicURL,
+		publicURL: strings.TrimRight(cfg.PublicURL, "/"),
 	}, nil
 }
 
@@ -57,10 +59,11 @@ func (s *S3Storage) SaveFile(ctx context.Context, file io.Reader, filename strin
 	key := fmt.Sprintf("uploads/%s", uniqueName)
 
 	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
-		Bucket:      aws.String(s.bucket),
-		Key:         aws.String(key),
-		Body:        file,
-		ContentType: aws.String(contentType),
+		Bucket:       aws.String(s.bucket),
+		Key:          aws.String(key),
+		Body:         file,
+		ContentType:  aws.String(contentType),
+		CacheControl: aws.String(ImmutableCacheControl),
 	})
 	if err != nil {
 		return "", fmt.Errorf("failed to upload file to S3: %w", err)
@@ -80,20 +83,33 @@ func (s *S3Storage) SaveFile(ctx context.Context, file io.Reader, filename strin
 
 // DeleteFile deletes a file from S3
 func (s *S3Storage) DeleteFile(ctx context.Context, fileURL string) error {
-	// Simple extraction of key from URL.
-	// This assumes fileURL contains the key at the end.
-	// A better approach depends on exact URL structure.
-	// For now, let's assume valid key is passed or extracted manually if URL is full.
-
-	// TODO: Robust key extraction
-	key := fileURL
-
 	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
 		Bucket: aws.String(s.bucket),
-		Key:    aws.String(key),
+		Key:    aws.String(s.keyOf(fileURL)),
 	})
 	if err != nil {
 		return fmt.Errorf("failed to delete file from S3: %w", err)
 	}
 	return nil
 }
+
+// SignedURL presigns a GET of the file, for buckets that aren't public
+func (s *S3Storage) SignedURL(ctx context.Context, fileURL string, ttl time.Duration) (string, error) {
+	req, err := s3.NewPresignClient(s.client).PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.keyOf(fileURL)),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign file URL: %w", err)
+	}
+	return req.URL, nil
+}
+
+// keyOf returns the object key of a URL from SaveFile, which returns the key
+// itself when no public URL is configured
+func (s *S3Storage) keyOf(fileURL string) string {
+	if s.publicURL == "" {
+		return fileURL
+	}
+	return strings.TrimPrefix(fileURL, s.publicURL+"/")
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"io"
+	"time"
 )
 
 // FileStorage defines the interface for file storage operations
@@ -12,3 +13,10 @@ type FileStorage interface {
 	// DeleteFile deletes a file by its URL
 	DeleteFile(ctx context.Context, fileURL string) error
 }
+
+// URLSigner is implemented by stores whose files can be kept private and
+// handed out through short-lived signed URLs
+type URLSigner interface {
+	// SignedURL returns a URL to the file that stops working after ttl
+	SignedURL(ctx context.Context, fileURL string, ttl time.Duration) (string, error)
+}
@@ -0,0 +1,273 @@
+// Package audio reads the format and duration of uploaded audio from its
+// container, without decoding it
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"time"
+)
+
+// Formats Probe recognizes, with the content types they are stored under
+const (
+	FormatM4A = "m4a"
+	FormatOgg = "ogg"
+)
+
+var contentTypes = map[string]string{
+	FormatM4A: "audio/mp4",
+	FormatOgg: "audio/ogg",
+}
+
+var (
+	ErrUnsupportedFormat = errors.New("audio is not m4a or ogg")
+	ErrInvalidAudio      = errors.New("audio file is malformed")
+)
+
+// oggTailSize is how much of the end of an Ogg file is searched for its last
+// page, which is at most 65307 bytes long
+const oggTailSize = 65536
+
+// Info describes an audio file
+type Info struct {
+	Format   string
+	Duration time.Duration
+}
+
+// ContentType returns the MIME type of the format
+func (i Info) ContentType() string {
+	return contentTypes[i.Format]
+}
+
+// Probe identifies an m4a (MP4 audio) or Ogg (Opus or Vorbis) file of the
+// given size and reads its duration from the container headers
+func Probe(r io.ReaderAt, size int64) (*Info, error) {
+	head := make([]byte, 12)
+	if _, err := r.ReadAt(head, 0); err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+
+	switch {
+	case bytes.Equal(head[4:8], []byte("ftyp")):
+		d, err := mp4Duration(r, size)
+		if err != nil {
+			return nil, err
+		}
+		return &Info{Format: FormatM4A, Duration: d}, nil
+	case bytes.Equal(head[:4], []byte("OggS")):
+		d, err := oggDuration(r, size)
+		if err != nil {
+			return nil, err
+		}
+		return &Info{Format: FormatOgg, Duration: d}, nil
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+// mp4Box is a box header: its type and where its contents lie
+type mp4Box struct {
+	kind       string
+	start, end int64
+}
+
+// mp4Boxes lists the boxes between start and end
+func mp4Boxes(r io.ReaderAt, start, end int64) ([]mp4Box, error) {
+	var boxes []mp4Box
+	for start+8 <= end {
+		var hdr [16]byte
+		if _, err := r.ReadAt(hdr[:8], start); err != nil {
+			return nil, ErrInvalidAudio
+		}
+		size := int64(binary.BigEndian.Uint32(hdr[:4]))
+		headerLen := int64(8)
+		switch size {
+		case 0:
+			size = end - start
+		case 1:
+			if _, err := r.ReadAt(hdr[8:16], start+8); err != nil {
+				return nil, ErrInvalidAudio
+			}
+			size = int64(binary.BigEndian.Uint64(hdr[8:16]))
+			headerLen = 16
+		}
+		if size < headerLen || start+size > end {
+			return nil, ErrInvalidAudio
+		}
+		boxes = append(boxes, mp4Box{kind: string(hdr[4:8]), start: start + headerLen, end: start + size})
+		start += size
+	}
+	return boxes, nil
+}
+
+func findMP4Box(boxes []mp4Box, kind string) (mp4Box, bool) {
+	for _, b := range boxes {
+		if b.kind == kind {
+			return b, true
+		}
+	}
+	return mp4Box{}, false
+}
+
+// mp4Duration reads the movie header (moov/mvhd), after checking that the
+// file holds sound and no video
+func mp4Duration(r io.ReaderAt, size int64) (time.Duration, error) {
+	top, err := mp4Boxes(r, 0, size)
+	if err != nil {
+		return 0, err
+	}
+	moov, ok := findMP4Box(top, "moov")
+	if !ok {
+		return 0, ErrInvalidAudio
+	}
+	boxes, err := mp4Boxes(r, moov.start, moov.end)
+	if err != nil {
+		return 0, err
+	}
+
+	sound := false
+	for _, trak := range boxes {
+		if trak.kind != "trak" {
+			continue
+		}
+		handler, err := mp4TrackHandler(r, trak)
+		if err != nil {
+			return 0, err
+		}
+		switch handler {
+		case "soun":
+			sound = true
+		case "vide":
+			return 0, ErrUnsupportedFormat
+		}
+	}
+	if !sound {
+		return 0, ErrUnsupportedFormat
+	}
+
+	mvhd, ok := findMP4Box(boxes, "mvhd")
+	if !ok || mvhd.end-mvhd.start < 20 {
+		return 0, ErrInvalidAudio
+	}
+	var buf [32]byte
+	n := mvhd.end - mvhd.start
+	if n > int64(len(buf)) {
+		n = int64(len(buf))
+	}
+	if _, err := r.ReadAt(buf[:n], mvhd.start); err != nil {
+		return 0, ErrInvalidAudio
+	}
+
+	var timescale uint32
+	var duration uint64
+	if buf[0] == 1 {
+		// Version 1 has 64-bit creation, modification and duration fields
+		if n < 32 {
+			return 0, ErrInvalidAudio
+		}
+		timescale = binary.BigEndian.Uint32(buf[20:24])
+		duration = binary.BigEndian.Uint64(buf[24:32])
+	} else {
+		timescale = binary.BigEndian.Uint32(buf[12:16])
+		duration = uint64(binary.BigEndian.Uint32(buf[16:20]))
+	}
+	if timescale == 0 {
+		return 0, ErrInvalidAudio
+	}
+	return scaleDuration(duration, uint64(timescale))
+}
+
+// mp4TrackHandler returns the handler type of a track (trak/mdia/hdlr)
+func mp4TrackHandler(r io.ReaderAt, trak mp4Box) (string, error) {
+	boxes, err := mp4Boxes(r, trak.start, trak.end)
+	if err != nil {
+		return "", err
+	}
+	mdia, ok := findMP4Box(boxes, "mdia")
+	if !ok {
+		return "", nil
+	}
+	if boxes, err = mp4Boxes(r, mdia.start, mdia.end); err != nil {
+		return "", err
+	}
+	hdlr, ok := findMP4Box(boxes, "hdlr")
+	if !ok || hdlr.end-hdlr.start < 12 {
+		return "", nil
+	}
+	// Version and flags, then pre_defined, then the handler type
+	var kind [4]byte
+	if _, err := r.ReadAt(kind[:], hdlr.start+8); err != nil {
+		return "", ErrInvalidAudio
+	}
+	return string(kind[:]), nil
+}
+
+// oggDuration divides the granule position of the stream's last page by its
+// sample rate. The first page carries the codec's identification header.
+func oggDuration(r io.ReaderAt, size int64) (time.Duration, error) {
+	first := make([]byte, 27+255+30)
+	n, err := r.ReadAt(first, 0)
+	if n < 28 && err != nil {
+		return 0, ErrInvalidAudio
+	}
+	first = first[:n]
+	serial := binary.LittleEndian.Uint32(first[14:18])
+	segments := int(first[26])
+	if len(first) < 27+segments+19 {
+		return 0, ErrInvalidAudio
+	}
+	packet := first[27+segments:]
+
+	var rate, preSkip uint64
+	switch {
+	case bytes.HasPrefix(packet, []byte("OpusHead")):
+		// Opus always counts granules at 48 kHz
+		rate = 48000
+		preSkip = uint64(binary.LittleEndian.Uint16(packet[10:12]))
+	case bytes.HasPrefix(packet, []byte("\x01vorbis")):
+		rate = uint64(binary.LittleEndian.Uint32(packet[12:16]))
+	default:
+		return 0, ErrUnsupportedFormat
+	}
+	if rate == 0 {
+		return 0, ErrInvalidAudio
+	}
+
+	tailStart := size - oggTailSize
+	if tailStart < 0 {
+		tailStart = 0
+	}
+	tail := make([]byte, size-tailStart)
+	if n, err := r.ReadAt(tail, tailStart); n < len(tail) && err != nil {
+		return 0, ErrInvalidAudio
+	}
+
+	for i := bytes.LastIndex(tail, []byte("OggS")); i >= 0; i = bytes.LastIndex(tail[:i], []byte("OggS")) {
+		page := tail[i:]
+		if len(page) < 27 || binary.LittleEndian.Uint32(page[14:18]) != serial {
+			continue
+		}
+		granule := binary.LittleEndian.Uint64(page[6:14])
+		// -1 marks a page on which no packet ends
+		if granule == ^uint64(0) {
+			continue
+		}
+		if granule < preSkip {
+			return 0, ErrInvalidAudio
+		}
+		return scaleDuration(granule-preSkip, rate)
+	}
+	return 0, ErrInvalidAudio
+}
+
+// scaleDuration converts a count of units at rate per second to a duration,
+// refusing ones too long to be real
+func scaleDuration(units, rate uint64) (time.Duration, error) {
+	secs := units / rate
+	if secs > uint64(24*time.Hour/time.Second) {
+		return 0, ErrInvalidAudio
+	}
+	frac := units % rate
+	return time.Duration(secs)*time.Second + time.Duration(frac*uint64(time.Second)/rate), nil
+}
@@ -0,0 +1,122 @@
+// Package geohash encodes coordinates as geohashes, base-32 strings naming
+// a grid cell; each extra character narrows the cell about 32 times, and
+// nearby points share a prefix
+package geohash
+
+import (
+	"errors"
+	"strings"
+)
+
+const alphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// MaxPrecision is the longest hash handled; its cells are a few centimeters
+const MaxPrecision = 12
+
+var ErrInvalidHash = errors.New("invalid geohash")
+
+// Cell is the area a geohash covers
+type Cell struct {
+	MinLat, MinLng, MaxLat, MaxLng float64
+}
+
+// Center returns the middle of the cell
+func (c Cell) Center() (lat, lng float64) {
+	return (c.MinLat + c.MaxLat) / 2, (c.MinLng + c.MaxLng) / 2
+}
+
+// Encode returns the geohash of the cell holding the point, precision
+// characters long. Coordinates outside the valid range are clamped.
+func Encode(lat, lng float64, precision int) string {
+	if precision < 1 {
+		precision = 1
+	}
+	if precision > MaxPrecision {
+		precision = MaxPrecision
+	}
+	lat = clamp(lat, -90, 90)
+	lng = clamp(lng, -180, 180)
+
+	minLat, maxLat := -90.0, 90.0
+	minLng, maxLng := -180.0, 180.0
+	var sb strings.Builder
+	sb.Grow(precision)
+
+	// Bits alternate between longitude and latitude, longitude first
+	even := true
+	bits, ch := 0, 0
+	for sb.Len() < precision {
+		if even {
+			mid := (minLng + maxLng) / 2
+			if lng >= mid {
+				ch = ch<<1 | 1
+				minLng = mid
+			} else {
+				ch <<= 1
+				maxLng = mid
+			}
+		} else {
+			mid := (minLat + maxLat) / 2
+			if lat >= mid {
+				ch = ch<<1 | 1
+				minLat = mid
+			} else {
+				ch <<= 1
+				maxLat = mid
+			}
+		}
+		even = !even
+
+		if bits++; bits == 5 {
+			sb.WriteByte(alphabet[ch])
+			bits, ch = 0, 0
+		}
+	}
+	return sb.String()
+}
+
+// Decode returns the cell a geohash covers
+func Decode(hash string) (Cell, error) {
+	if hash == "" || len(hash) > MaxPrecision {
+		return Cell{}, ErrInvalidHash
+	}
+
+	cell := Cell{MinLat: -90, MaxLat: 90, MinLng: -180, MaxLng: 180}
+	even := true
+	for i := 0; i < len(hash); i++ {
+		v := strings.IndexByte(alphabet, hash[i])
+		if v < 0 {
+			return Cell{}, ErrInvalidHash
+		}
+		for bit := 4; bit >= 0; bit-- {
+			set := v>>bit&1 == 1
+			if even {
+				mid := (cell.MinLng + cell.MaxLng) / 2
+				if set {
+					cell.MinLng = mid
+				} else {
+					cell.MaxLng = mid
+				}
+			} else {
+				mid := (cell.MinLat + cell.MaxLat) / 2
+				if set {
+					cell.MinLat = mid
+				} else {
+					cell.MaxLat = mid
+				}
+			}
+			even = !even
+		}
+	}
+	return cell, nil
+}
+
+func clamp(v, lo, hi float64) float64 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
@@ -0,0 +1,82 @@
+// Package imagehash computes perceptual hashes of images, which stay close
+// when an image is re-encoded, resized or lightly edited
+package imagehash
+
+import (
+	"bytes"
+	"errors"
+	"image"
+
+	// Decoders for the formats the app uploads
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// maxPixels refuses images that would take too much memory to decode
+const maxPixels = 50_000_000
+
+// samplesPerCell bounds the pixels averaged per grid cell, so hashing a
+// large photo costs about the same as a small one
+const samplesPerCell = 8
+
+var ErrImageTooLarge = errors.New("image too large to hash")
+
+// Difference returns the 64-bit difference hash (dHash) of an encoded GIF,
+// JPEG or PNG image. The image is shrunk to a 9x8 grayscale grid and each bit
+// records whether a cell is brighter than its right-hand neighbour. Hashes of
+// the same picture differ in a few bits; unrelated pictures in about 32.
+func Difference(data []byte) (uint64, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return 0, ErrImageTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+	return differenceHash(img), nil
+}
+
+func differenceHash(img image.Image) uint64 {
+	const cols, rows = 9, 8
+	var grid [rows][cols]float64
+
+	bounds := img.Bounds()
+	cellW := float64(bounds.Dx()) / cols
+	cellH := float64(bounds.Dy()) / rows
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			grid[y][x] = cellLuma(img, bounds.Min.X+int(float64(x)*cellW), bounds.Min.Y+int(float64(y)*cellH), cellW, cellH)
+		}
+	}
+
+	var hash uint64
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols-1; x++ {
+			hash <<= 1
+			if grid[y][x] > grid[y][x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// cellLuma averages the brightness of up to samplesPerCell x samplesPerCell
+// pixels spread evenly over the cell
+func cellLuma(img image.Image, x0, y0 int, w, h float64) float64 {
+	nx, ny := min(samplesPerCell, max(1, int(w))), min(samplesPerCell, max(1, int(h)))
+	var sum float64
+	for j := 0; j < ny; j++ {
+		for i := 0; i < nx; i++ {
+			r, g, b, _ := img.At(x0+int((float64(i)+0.5)*w/float64(nx)), y0+int((float64(j)+0.5)*h/float64(ny))).RGBA()
+			sum += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+		}
+	}
+	return sum / float64(nx*ny)
+}
@@ -10,12 +10,36 @@ type Response struct {
 	Success bool        `json:"success"`
 	Data    interface{} `json:"data,omitempty"`
 	Error   *ErrorInfo  `json:"error,omitempty"`
+	Meta    *Meta       `json:"meta,omitempty"`
+}
+
+// Meta reports the paging a list response was served with, after the server
+// applied its defaults and caps
+type Meta struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+	// NextCursor is where the next page starts, for lists paged by cursor
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // ErrorInfo contains error details
 type ErrorInfo struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// Page is the paging metadata in a paginated response
+type Page struct {
+	Number  int  `json:"number"`
+	Limit   int  `json:"limit"`
+	HasMore bool `json:"has_more"`
+}
+
+// PaginatedData wraps a page of items with its paging metadata
+type PaginatedData struct {
+	Items interface{} `json:"items"`
+	Page  Page        `json:"page"`
 }
 
 // JSON sends a JSON response
@@ -31,8 +55,27 @@ func JSON(w http.ResponseWriter, status int, data interface{}) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// List sends a 200 response with a list and the limit and offset it was read with
+func List(w http.ResponseWriter, items interface{}, meta Meta) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	response := Response{
+		Success: true,
+		Data:    items,
+		Meta:    &meta,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
 // Error sends an error response
 func Error(w http.ResponseWriter, status int, code, message string) {
+	ErrorWithDetails(w, status, code, message, nil)
+}
+
+// ErrorWithDetails sends an error response with machine-readable details
+func ErrorWithDetails(w http.ResponseWriter, status int, code, message string, details interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 
@@ -41,6 +84,7 @@ func Error(w http.ResponseWriter, status int, code, message string) {
 		Error: &ErrorInfo{
 			Code:    code,
 			Message: message,
+			Details: details,
 		},
 	}
 
@@ -72,6 +116,11 @@ func Conflict(w http.ResponseWriter, message string) {
 	Error(w, http.StatusConflict, "CONFLICT", message)
 }
 
+// TooManyRequests sends a 429 response
+func TooManyRequests(w http.ResponseWriter, message string) {
+	Error(w, http.StatusTooManyRequests, "RATE_LIMITED", message)
+}
+
 // InternalError sends a 500 response
 func InternalError(w http.ResponseWriter, message string) {
 	Error(w, http.StatusInternalServerError, "INTERNAL_ERROR", message)
@@ -87,6 +136,11 @@ func OK(w http.ResponseWriter, data interface{}) {
 	JSON(w, http.StatusOK, data)
 }
 
+// Paginated sends a 200 response with a page of items and its paging metadata
+func Paginated(w http.ResponseWriter, items interface{}, page Page) {
+	JSON(w, http.StatusOK, PaginatedData{Items: items, Page: page})
+}
+
 // NoContent sends a 204 response
 func NoContent(w http.ResponseWriter) {
 	w.WriteHeader(http.StatusNoContent)
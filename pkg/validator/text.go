@@ -0,0 +1,117 @@
+package validator
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// maxCombiningMarks caps the combining marks stacked on one character. Real
+// scripts need at most a few; "zalgo" text stacks dozens to spill over
+// neighbouring lines.
+const maxCombiningMarks = 4
+
+const (
+	zwnj = '\u200c'
+	zwj  = '\u200d'
+	// blackFlag starts the emoji tag sequences used for subdivision flags
+	blackFlag = '\U0001F3F4'
+)
+
+var lineBreaks = strings.NewReplacer("\r\n", "\n", "\r", "\n", "\u2028", "\n", "\u2029", "\n", "\u0085", "\n")
+
+// SanitizeText cleans single-line text such as display names: see
+// SanitizeMultiline, except that line breaks become spaces too
+func SanitizeText(s string) string {
+	return sanitizeText(s, false)
+}
+
+// SanitizeMultiline cleans user-written text such as bios, captions and
+// messages. It NFC-normalizes the text, drops control characters and invisible
+// formatting characters, collapses runs of spaces, of joiners and of
+// direction marks, caps stacked combining marks and trims the ends. Line
+// breaks become \n, with at most one blank line in a row. Characters that
+// scripts and emoji depend on are kept: ZWJ and ZWNJ after a visible
+// character, the LRM, RLM and ALM direction marks, variation selectors and
+// the tags of subdivision flags.
+func SanitizeMultiline(s string) string {
+	return sanitizeText(s, true)
+}
+
+func sanitizeText(s string, multiline bool) string {
+	s = lineBreaks.Replace(norm.NFC.String(strings.ToValidUTF8(s, "")))
+
+	out := make([]rune, 0, utf8.RuneCountInString(s))
+	last := func() rune {
+		if len(out) == 0 {
+			return 0
+		}
+		return out[len(out)-1]
+	}
+	space, marks := false, 0
+
+	for _, r := range s {
+		switch {
+		case r == '\n' && multiline:
+			space, marks = false, 0
+			if n := len(out); n == 0 || (n >= 2 && out[n-1] == '\n' && out[n-2] == '\n') {
+				continue
+			}
+			out = append(out, r)
+			continue
+		case unicode.IsSpace(r):
+			space = true
+			continue
+		case unicode.Is(unicode.Cc, r):
+			continue
+		case r == zwj || r == zwnj:
+			// Only between visible characters, one at a time
+			if l := last(); space || l == 0 || l == '\n' || l == zwj || l == zwnj {
+				continue
+			}
+			out = append(out, r)
+			continue
+		case isDirectionMark(r):
+			if isDirectionMark(last()) && !space {
+				continue
+			}
+		case isFlagTag(r):
+			if l := last(); l != blackFlag && !isFlagTag(l) {
+				continue
+			}
+		case unicode.Is(unicode.Cf, r):
+			// Zero-width spaces, bidi overrides and isolates, soft hyphens and the like
+			continue
+		case unicode.In(r, unicode.Mn, unicode.Me):
+			if marks++; marks > maxCombiningMarks {
+				continue
+			}
+		default:
+			marks = 0
+		}
+
+		if space && len(out) > 0 && last() != '\n' {
+			out = append(out, ' ')
+		}
+		space = false
+		out = append(out, r)
+	}
+
+	// Dropping characters can leave composable sequences next to each other
+	return norm.NFC.String(strings.TrimRightFunc(string(out), func(r rune) bool {
+		return r == '\n' || r == zwj || r == zwnj
+	}))
+}
+
+// isDirectionMark reports the invisible marks that set the direction of
+// neutral characters in mixed left-to-right and right-to-left text
+func isDirectionMark(r rune) bool {
+	return r == '\u200e' || r == '\u200f' || r == '\u061c'
+}
+
+// isFlagTag reports the tag characters of emoji subdivision flags
+func isFlagTag(r rune) bool {
+	return r >= '\U000E0020' && r <= '\U000E007F'
+}
@@ -0,0 +1,90 @@
+package validator
+
+import "testing"
+
+func TestSanitize(t *testing.T) {
+	// Single-line cases, cleaned the same by both functions
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "  hello   world ", "hello world"},
+		{"control characters", "a\x00b\x07c", "abc"},
+
+		// ZWJ emoji sequences
+		{"zwj family", "👨\u200d👩\u200d👧", "👨\u200d👩\u200d👧"},
+		{"zwj profession", "🧑\u200d💻", "🧑\u200d💻"},
+		{"doubled zwj", "👨\u200d\u200d👩", "👨\u200d👩"},
+		{"leading zwj", "\u200d👍", "👍"},
+		{"trailing zwj", "👍\u200d", "👍"},
+		{"zwj after space", "hi \u200d👍", "hi 👍"},
+
+		// Skin-tone modifiers
+		{"skin tone", "👋\U0001f3fd", "👋\U0001f3fd"},
+		{"skin tones in a sequence", "👩\U0001f3fd\u200d🤝\u200d👨\U0001f3ff", "👩\U0001f3fd\u200d🤝\u200d👨\U0001f3ff"},
+		{"variation selector", "❤\ufe0f", "❤\ufe0f"},
+		{"subdivision flag", "🏴\U000E0067\U000E0062\U000E0065\U000E006E\U000E0067\U000E007F", "🏴\U000E0067\U000E0062\U000E0065\U000E006E\U000E0067\U000E007F"},
+		{"stray flag tag", "a\U000E0067b", "ab"},
+
+		// Direction overrides and isolates
+		{"rlo", "photo\u202egpj.exe", "photogpj.exe"},
+		{"lro and pdf", "a\u202db\u202cc", "abc"},
+		{"lre and rle", "a\u202ab\u202bc", "abc"},
+		{"isolates", "a\u2066b\u2067c\u2068d\u2069e", "abcde"},
+		{"rlm kept", "\u200fשלום world", "\u200fשלום world"},
+		{"alm kept", "\u061cمرحبا", "\u061cمرحبا"},
+		{"repeated marks", "a\u200e\u200e\u200fb", "a\u200eb"},
+
+		// Combining marks
+		{"composes", "e\u0301", "\u00e9"},
+		{"devanagari conjunct", "क\u094dषत\u094dरिय", "क\u094dषत\u094dरिय"},
+		{"devanagari half form", "क\u094d\u200dष", "क\u094d\u200dष"},
+		{"zwnj in persian", "می\u200cخواهم", "می\u200cخواهم"},
+		{"zalgo capped", "x\u0301\u0302\u0303\u0304\u0305\u0306", "x\u0301\u0302\u0303\u0304"},
+		{"cap per character", "x\u0301\u0302\u0303\u0304\u0305y\u0301", "x\u0301\u0302\u0303\u0304\u00fd"},
+		{"enclosing marks", "1\u20e3", "1\u20e3"},
+
+		{"zero width space", "a\u200bb", "ab"},
+		{"soft hyphen", "co\u00adop", "coop"},
+		{"invalid utf-8", "a\xffb", "ab"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeText(tt.in); got != tt.want {
+				t.Errorf("SanitizeText(%+q) = %+q, want %+q", tt.in, got, tt.want)
+			}
+			if got := SanitizeMultiline(tt.in); got != tt.want {
+				t.Errorf("SanitizeMultiline(%+q) = %+q, want %+q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeLineBreaks(t *testing.T) {
+	tests := []struct {
+		name      string
+		in        string
+		text      string
+		multiline string
+	}{
+		{"newline", "a\nb", "a b", "a\nb"},
+		{"crlf and cr", "a\r\nb\rc", "a b c", "a\nb\nc"},
+		{"unicode separators", "a\u2028b\u2029c\u0085d", "a b c d", "a\nb\nc\nd"},
+		{"blank lines", "a\n\n\n\nb", "a b", "a\n\nb"},
+		{"leading and trailing", "\n\na\n\n", "a", "a"},
+		{"spaces around", "a  \n  b", "a b", "a\nb"},
+		{"zwj at line start", "a\n\u200d👍", "a 👍", "a\n👍"},
+		{"marks reset per line", "x\u0301\u0302\u0303\u0304\nx\u0301", "x\u0301\u0302\u0303\u0304 x\u0301", "x\u0301\u0302\u0303\u0304\nx\u0301"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeText(tt.in); got != tt.text {
+				t.Errorf("SanitizeText(%+q) = %+q, want %+q", tt.in, got, tt.text)
+			}
+			if got := SanitizeMultiline(tt.in); got != tt.multiline {
+				t.Errorf("SanitizeMultiline(%+q) = %+q, want %+q", tt.in, got, tt.multiline)
+			}
+		})
+	}
+}